@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	pb "upf/pkg/proto"
+)
+
+// renderAggregateStats clears the screen and draws the groups of a roll-up
+func renderAggregateStats(resp *pb.AggregateStatsReply) {
+	fmt.Print("\033[2J\033[H")
+	measured := "never"
+	if resp.GetMeasuredUnixNano() != 0 {
+		measured = time.Unix(0, resp.GetMeasuredUnixNano()).Format("15:04:05")
+	}
+	fmt.Printf("Grouped by %s, measured %s\n", resp.GetGroupBy(), measured)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Group", "Sessions", "Rx Packets", "Tx Packets", "Rx Bytes", "Tx Bytes", "Rx Speed", "Tx Speed", "Rx Rate", "Tx Rate"})
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	speed, rate := resp.GetSpeedUnit(), resp.GetPacketRateUnit()
+	for _, g := range resp.GetGroups() {
+		table.Append([]string{
+			g.GetKey(),
+			strconv.FormatUint(uint64(g.GetSessions()), 10),
+			strconv.FormatUint(g.GetRxPackets(), 10),
+			strconv.FormatUint(g.GetTxPackets(), 10),
+			strconv.FormatUint(g.GetRxBytes(), 10),
+			strconv.FormatUint(g.GetTxBytes(), 10),
+			formatRate(g.GetRxSpeed(), speed),
+			formatRate(g.GetTxSpeed(), speed),
+			formatRate(g.GetRxPacketRate(), rate),
+			formatRate(g.GetTxPacketRate(), rate),
+		})
+	}
+	table.Render()
+	if len(resp.GetGroups()) == 0 {
+		fmt.Println("No groups match")
+	}
+	fmt.Println("Press ENTER to stop streaming and return to menu...")
+}
+
+// showAggregateStats streams the flow counters rolled up per IMSI, DNN, slice,
+// the whole UPF or per flow
+func showAggregateStats(reader *bufio.Reader) {
+	req := &pb.AggregateStatsRequest{
+		GroupBy: prompt(reader, "Group by imsi, dnn, slice, upf or flow (press Enter for upf): "),
+		Filter:  splitList(prompt(reader, "Groups to show, comma separated (press Enter for all): ")),
+	}
+	if req.GroupBy == "" {
+		req.GroupBy = "upf"
+	}
+
+	conn, err := dialPFCPAgent()
+	if err != nil {
+		fmt.Printf("Failed to connect: %v\n", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := pb.NewRequestClient(conn).StreamAggregateStats(ctx, req)
+	if err != nil {
+		fmt.Printf("Could not stream aggregate statistics: %v\n", err)
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		reader.ReadString('\n')
+		cancel()
+		close(done)
+	}()
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			// Errors other than the user stopping the stream are shown until ENTER
+			if ctx.Err() == nil {
+				fmt.Printf("Could not stream aggregate statistics: %v\n", err)
+				fmt.Print("\nPress ENTER to return to menu...")
+				<-done
+			}
+			return
+		}
+		renderAggregateStats(resp)
+	}
+}
@@ -20,14 +20,18 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/olekukonko/tablewriter"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	pb "upf/pkg/proto"
 )
 
 // Color configuration for terminal output
 var (
-	cyan  = color.New(color.FgCyan).SprintFunc()
-	green = color.New(color.FgGreen).SprintFunc()
+	cyan   = color.New(color.FgCyan).SprintFunc()
+	green  = color.New(color.FgGreen).SprintFunc()
+	red    = color.New(color.FgRed).SprintFunc()
+	yellow = color.New(color.FgYellow).SprintFunc()
 )
 
 // RequestData represents the structure of incoming validation requests
@@ -60,10 +64,18 @@ func printMenu() {
 	table.Append([]string{green("3."), "Get IMSI"})
 	table.Append([]string{green("4."), "Get Rule"})
 	table.Append([]string{green("5."), "Validate Rules"})
-	table.Append([]string{green("6."), "Exit"})
+	table.Append([]string{green("6."), "Session Snapshots"})
+	table.Append([]string{green("7."), "PFCP Peers"})
+	table.Append([]string{green("8."), "Usage Reports"})
+	table.Append([]string{green("9."), "Flow History"})
+	table.Append([]string{green("10."), "Aggregate Stats"})
+	table.Append([]string{green("11."), "Top Sessions"})
+	table.Append([]string{green("12."), "Record Flows"})
+	table.Append([]string{green("13."), "Slice Rate Limits"})
+	table.Append([]string{green("14."), "Exit"})
 	table.Render()
 	fmt.Printf("%s\n", cyan("└────────────────────────────────────────────────────────────────────────────┘"))
-	fmt.Print(green("Select an option [1-6]: "))
+	fmt.Print(green("Select an option [1-14]: "))
 }
 
 // printValidationMenu displays the validation server menu interface
@@ -313,13 +325,11 @@ func main() {
 			defer conn.Close()
 
 			client := pb.NewRequestClient(conn)
-			fmt.Print("Enter FSEID to get flow data (press Enter to skip): ")
-			fseid, _ := reader.ReadString('\n')
-			fseid = strings.TrimSpace(fseid)
+			flowReq := readFlowRequest(reader)
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
-			stream, err := client.PutRequest(ctx, &pb.FlowRequest{Fseid: fseid})
+			stream, err := client.PutRequest(ctx, flowReq)
 			if err != nil {
 				log.Printf("Error starting stream: %v", err)
 				continue
@@ -332,24 +342,41 @@ func main() {
 			}()
 
 			log.Println("Streaming flow data (table updates)...")
+			latest := make(map[string]*pb.Reply)
+			notice := ""
 			for {
 				resp, err := stream.Recv()
 				if err != nil {
-					log.Printf("Stream ended: %v", err)
-					break
+					// Resume the subscription where it left off unless the user stopped it
+					if ctx.Err() != nil || flowReq.SubscriptionId == 0 || status.Code(err) == codes.InvalidArgument {
+						log.Printf("Stream ended: %v", err)
+						break
+					}
+					log.Printf("Stream dropped (%v), resuming after update %d...", err, flowReq.ResumeFrom)
+					time.Sleep(time.Second)
+					if stream, err = client.PutRequest(ctx, flowReq); err != nil {
+						log.Printf("Error resuming stream: %v", err)
+						break
+					}
+					continue
 				}
 
-				// Clear the screen and move cursor to top-left
-				fmt.Print("\033[2J\033[H")
-
-				// Render table
-				fmt.Println("+-------------+-------------+-------------+-------------+--------------+---------------+")
-				fmt.Println("| Rx Packet   | Tx Packet   | Rx Speed    | Tx Speed    | Total Packet | Total Speed   |")
-				fmt.Println("+-------------+-------------+-------------+-------------+--------------+---------------+")
-				fmt.Printf("| %-11d | %-11d | %-11d | %-11d | %-12d | %-13d |\n",
-					resp.Rx_Packet, resp.Tx_Packet, resp.Rx_Speed, resp.Tx_Speed, resp.Total_Packets, resp.Total_Speed)
-				fmt.Println("+-------------+-------------+-------------+-------------+--------------+---------------+")
-				fmt.Printf("All IMSI: %v   Updates: %d\n", resp.All_IMSI, resp.Count)
+				flowReq.SubscriptionId = resp.SubscriptionId
+				if resp.Gap {
+					if resp.GapTo == 0 {
+						notice = fmt.Sprintf("Subscription expired, updates after %d were lost", resp.GapFrom-1)
+						flowReq.ResumeFrom = 0
+					} else {
+						notice = fmt.Sprintf("Updates %d-%d may be missing", resp.GapFrom, resp.GapTo)
+					}
+					continue
+				}
+				flowReq.ResumeFrom = resp.Count
+				latest[resp.Fseid] = resp
+				renderFlows(latest)
+				if notice != "" {
+					fmt.Println(red(notice))
+				}
 			}
 
 		case "2":
@@ -401,6 +428,9 @@ func main() {
 			}
 			table.Append([]string{"Enable P4RT", fmt.Sprintf("%v", cfg.GetEnableP4Rt())})
 			table.Append([]string{"Enable HB Timer", fmt.Sprintf("%v", cfg.GetEnableHbTimer())})
+			if cfg.GetHeartBeatInterval() != "" {
+				table.Append([]string{"Heartbeat Interval", cfg.GetHeartBeatInterval()})
+			}
 			if cfg.GetCpiface() != nil {
 				table.Append([]string{"CP DNN", cfg.GetCpiface().GetDnn()})
 				table.Append([]string{"CP Peers", fmt.Sprintf("%v", cfg.GetCpiface().GetPeers())})
@@ -515,7 +545,7 @@ func main() {
 				ruleTable.Append([]string{"QER ID", ruleResp.Session.Qer.QerId})
 			}
 			if ruleResp.Session.Urr != nil {
-				ruleTable.Append([]string{"URR ID", ruleResp.Session.Urr.UrrId})
+				ruleTable.AppendBulk(urrRows(ruleResp.Session.Urr))
 			}
 
 			ruleTable.Render()
@@ -566,6 +596,30 @@ func main() {
 			}
 
 		case "6":
+			snapshotMenu(reader)
+
+		case "7":
+			showPFCPPeers(reader)
+
+		case "8":
+			showUsageReports(reader)
+
+		case "9":
+			showFlowHistory(reader)
+
+		case "10":
+			showAggregateStats(reader)
+
+		case "11":
+			showTopSessions(reader)
+
+		case "12":
+			recordFlows(reader)
+
+		case "13":
+			showSlicePolicers(reader)
+
+		case "14":
 			cleanup()
 			return
 		default:
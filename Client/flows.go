@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	pb "upf/pkg/proto"
+)
+
+// readFlowRequest asks which flows to stream and how often
+func readFlowRequest(reader *bufio.Reader) *pb.FlowRequest {
+	ask := func(prompt string) string {
+		fmt.Print(prompt)
+		v, _ := reader.ReadString('\n')
+		return strings.TrimSpace(v)
+	}
+
+	req := &pb.FlowRequest{}
+	for _, fseid := range strings.Split(ask("Enter FSEIDs to get flow data, comma separated (press Enter to skip): "), ",") {
+		if fseid = strings.TrimSpace(fseid); fseid != "" {
+			req.Fseids = append(req.Fseids, fseid)
+		}
+	}
+	req.Imsi = ask("Enter IMSI to watch all its sessions (press Enter to skip): ")
+	req.Dnn = ask("Enter DNN to watch all its sessions (press Enter to skip): ")
+	if v := ask("Update interval in ms, 100-60000 (press Enter for 2000): "); v != "" {
+		if ms, err := strconv.ParseUint(v, 10, 32); err == nil {
+			req.IntervalMs = uint32(ms)
+		} else {
+			fmt.Println("Invalid interval, using the default")
+		}
+	}
+	req.OnChange = strings.EqualFold(ask("Only show flows that changed? [y/N]: "), "y")
+	return req
+}
+
+// renderFlows draws the latest update of every flow in the stream: the full
+// counters for a single flow, a summary table for several
+func renderFlows(latest map[string]*pb.Reply) {
+	if len(latest) == 1 {
+		for _, resp := range latest {
+			renderFlow(resp)
+		}
+		return
+	}
+
+	fseids := make([]string, 0, len(latest))
+	for fseid := range latest {
+		fseids = append(fseids, fseid)
+	}
+	sort.Strings(fseids)
+
+	fmt.Print("\033[2J\033[H")
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"F-SEID", "IMSI", "Rx Packets", "Tx Packets", "Rx Speed", "Tx Speed", "Measured"})
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	var count uint64
+	for _, fseid := range fseids {
+		resp := latest[fseid]
+		table.Append([]string{
+			fseid,
+			resp.Imsi,
+			strconv.FormatUint(resp.Rx_Packet, 10),
+			strconv.FormatUint(resp.Tx_Packet, 10),
+			formatRate(float64(resp.Rx_Speed), resp.Speed_Unit),
+			formatRate(float64(resp.Tx_Speed), resp.Speed_Unit),
+			time.Unix(0, resp.Measured_Unix_Nano).Format("15:04:05.000"),
+		})
+		if resp.Count > count {
+			count = resp.Count
+		}
+	}
+	table.Render()
+	fmt.Printf("Flows: %d   Updates: %d\n", len(latest), count)
+}
+
+// formatRate scales a rate to k, M or G and appends its unit
+func formatRate(v float64, unit string) string {
+	switch {
+	case v >= 1e9:
+		return fmt.Sprintf("%.2f G%s", v/1e9, unit)
+	case v >= 1e6:
+		return fmt.Sprintf("%.2f M%s", v/1e6, unit)
+	case v >= 1e3:
+		return fmt.Sprintf("%.2f k%s", v/1e3, unit)
+	}
+	return fmt.Sprintf("%.0f %s", v, unit)
+}
+
+// renderFlow clears the screen and draws the counters and rates of a flow update
+func renderFlow(resp *pb.Reply) {
+	// Clear the screen and move cursor to top-left
+	fmt.Print("\033[2J\033[H")
+
+	// Render table
+	fmt.Println("+-----------+--------------+----------------+------------------+------------------+------------------+------------------+")
+	fmt.Println("| Direction | Packets      | Bytes          | Speed            | Speed (avg)      | Packet Rate      | Packet Rate (avg)|")
+	fmt.Println("+-----------+--------------+----------------+------------------+------------------+------------------+------------------+")
+	row := func(dir string, packets, bytes uint64, speed, speedAvg, rate, rateAvg float64) {
+		fmt.Printf("| %-9s | %-12d | %-14d | %-16s | %-16s | %-16s | %-16s |\n", dir, packets, bytes,
+			formatRate(speed, resp.Speed_Unit), formatRate(speedAvg, resp.Speed_Unit),
+			formatRate(rate, resp.Packet_Rate_Unit), formatRate(rateAvg, resp.Packet_Rate_Unit))
+	}
+	row("Rx", resp.Rx_Packet, resp.Rx_Bytes, float64(resp.Rx_Speed), resp.Rx_Speed_Avg, resp.Rx_Packet_Rate, resp.Rx_Packet_Rate_Avg)
+	row("Tx", resp.Tx_Packet, resp.Tx_Bytes, float64(resp.Tx_Speed), resp.Tx_Speed_Avg, resp.Tx_Packet_Rate, resp.Tx_Packet_Rate_Avg)
+	row("Total", resp.Total_Packets, resp.Rx_Bytes+resp.Tx_Bytes, float64(resp.Total_Speed),
+		resp.Rx_Speed_Avg+resp.Tx_Speed_Avg, resp.Rx_Packet_Rate+resp.Tx_Packet_Rate, resp.Rx_Packet_Rate_Avg+resp.Tx_Packet_Rate_Avg)
+	fmt.Println("+-----------+--------------+----------------+------------------+------------------+------------------+------------------+")
+	if resp.Rx_QoS != nil || resp.Tx_QoS != nil {
+		colors := func(q *pb.QosCounters) string {
+			return fmt.Sprintf("%s %d  %s %d  %s %d", green("green"), q.GetGreenPackets(),
+				yellow("yellow"), q.GetYellowPackets(), red("red"), q.GetRedPackets())
+		}
+		fmt.Printf("QoS packets   Rx: %s   Tx: %s\n", colors(resp.Rx_QoS), colors(resp.Tx_QoS))
+	}
+
+	measured := time.Unix(0, resp.Measured_Unix_Nano).Format("15:04:05.000")
+	interval := (time.Duration(resp.Interval_Ms) * time.Millisecond).String()
+	fmt.Printf("F-SEID: %s   IMSI: %s\n", resp.Fseid, resp.Imsi)
+	fmt.Printf("Measured: %s over %s   All IMSI: %v   Updates: %d\n", measured, interval, resp.All_IMSI, resp.Count)
+}
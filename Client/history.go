@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	pb "upf/pkg/proto"
+)
+
+// sparkWidth is the number of columns a sparkline spans at most
+const sparkWidth = 60
+
+// sparkBlocks are the glyphs of a sparkline from lowest to highest
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as a line of blocks scaled to their maximum. With more
+// values than columns, each column shows the highest value it covers.
+func sparkline(values []float64) string {
+	if len(values) > sparkWidth {
+		cols := make([]float64, sparkWidth)
+		for i, v := range values {
+			c := i * sparkWidth / len(values)
+			cols[c] = max(cols[c], v)
+		}
+		values = cols
+	}
+
+	var top float64
+	for _, v := range values {
+		top = max(top, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if top > 0 {
+			i = int(v / top * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[i])
+	}
+	return b.String()
+}
+
+// summarize returns the lowest, average and highest of values
+func summarize(values []float64) (lo, avg, hi float64) {
+	if len(values) == 0 {
+		return 0, 0, 0
+	}
+	lo = values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+		avg += v
+	}
+	return lo, avg / float64(len(values)), hi
+}
+
+// renderHistorySparklines draws the speeds of the history as sparklines
+func renderHistorySparklines(resp *pb.FlowHistoryReply) {
+	var rx, tx []float64
+	for _, p := range resp.GetPoints() {
+		rx = append(rx, p.GetRxSpeed())
+		tx = append(tx, p.GetTxSpeed())
+	}
+	for _, line := range []struct {
+		dir    string
+		values []float64
+	}{{"Rx", rx}, {"Tx", tx}} {
+		lo, avg, hi := summarize(line.values)
+		fmt.Printf("%s %s\n", green(line.dir), sparkline(line.values))
+		fmt.Printf("   min %s   avg %s   max %s\n\n", formatRate(lo, resp.GetSpeedUnit()),
+			formatRate(avg, resp.GetSpeedUnit()), formatRate(hi, resp.GetSpeedUnit()))
+	}
+}
+
+// renderHistoryTable draws the counters and rates of every point of the history
+func renderHistoryTable(resp *pb.FlowHistoryReply) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Time", "Rx Speed", "Tx Speed", "Rx Peak", "Tx Peak", "Rx Rate", "Tx Rate", "Rx Bytes", "Tx Bytes"})
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	speed, rate := resp.GetSpeedUnit(), resp.GetPacketRateUnit()
+	for _, p := range resp.GetPoints() {
+		table.Append([]string{
+			time.Unix(p.GetUnix(), 0).Format("01-02 15:04:05"),
+			formatRate(p.GetRxSpeed(), speed),
+			formatRate(p.GetTxSpeed(), speed),
+			formatRate(p.GetRxSpeedPeak(), speed),
+			formatRate(p.GetTxSpeedPeak(), speed),
+			formatRate(p.GetRxPacketRate(), rate),
+			formatRate(p.GetTxPacketRate(), rate),
+			strconv.FormatUint(p.GetRxBytes(), 10),
+			strconv.FormatUint(p.GetTxBytes(), 10),
+		})
+	}
+	table.Render()
+}
+
+// showFlowHistory asks for a session and time range and displays its flow
+// history as sparklines or a table
+func showFlowHistory(reader *bufio.Reader) {
+	ask := func(prompt string) string {
+		fmt.Print(prompt)
+		v, _ := reader.ReadString('\n')
+		return strings.TrimSpace(v)
+	}
+	duration := func(prompt string, fallback time.Duration) time.Duration {
+		v := ask(prompt)
+		if v == "" {
+			return fallback
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			fmt.Printf("Invalid duration %q, using %s\n", v, fallback)
+			return fallback
+		}
+		return d
+	}
+
+	req := &pb.FlowHistoryRequest{Fseid: ask("Enter FSEID: ")}
+	span := duration("Time range to show, e.g. 10m or 24h (press Enter for 10m): ", 10*time.Minute)
+	step := duration("Step between points, e.g. 1s or 5m (press Enter for automatic): ", 0)
+	asTable := strings.EqualFold(ask("Show as [s]parklines or [t]able? [s]: "), "t")
+	req.StartUnix = time.Now().Add(-span).Unix()
+	req.StepSeconds = uint32(step / time.Second)
+
+	conn, err := dialPFCPAgent()
+	if err != nil {
+		fmt.Printf("Failed to connect: %v\n", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	resp, err := pb.NewRequestClient(conn).GetFlowHistory(ctx, req)
+	if err != nil {
+		fmt.Printf("Could not get flow history: %v\n", err)
+		fmt.Print("\nPress ENTER to return to menu...")
+		reader.ReadString('\n')
+		return
+	}
+
+	fmt.Print("\033[2J\033[H")
+	fmt.Printf("F-SEID: %s   Last %s in steps of %s (recorded every %s)\n\n", resp.GetFseid(), span,
+		time.Duration(resp.GetStepSeconds())*time.Second, time.Duration(resp.GetResolutionSeconds())*time.Second)
+	switch {
+	case len(resp.GetPoints()) == 0:
+		fmt.Println("No history recorded in this range")
+	case asTable:
+		renderHistoryTable(resp)
+	default:
+		renderHistorySparklines(resp)
+	}
+	fmt.Print("\nPress ENTER to return to menu...")
+	reader.ReadString('\n')
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	pb "upf/pkg/proto"
+)
+
+// dialPFCPAgent connects to the PFCP agent
+func dialPFCPAgent() (*grpc.ClientConn, error) {
+	serverAddr := os.Getenv("SERVER_ADDRESS")
+	if serverAddr == "" {
+		serverAddr = "localhost"
+	}
+	return grpc.Dial(serverAddr+":50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+// ago formats a Unix time stamp as the time elapsed since then
+func ago(unix int64) string {
+	if unix == 0 {
+		return "-"
+	}
+	return time.Since(time.Unix(unix, 0)).Round(time.Second).String() + " ago"
+}
+
+// showPFCPPeers displays the N4 association state of every PFCP peer
+func showPFCPPeers(reader *bufio.Reader) {
+	conn, err := dialPFCPAgent()
+	if err != nil {
+		fmt.Printf("Failed to connect: %v\n", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := pb.NewRequestClient(conn).ListPFCPPeers(ctx, &pb.PFCPPeersRequest{})
+	if err != nil {
+		fmt.Printf("Could not list PFCP peers: %v\n", err)
+		fmt.Print("\nPress ENTER to return to menu...")
+		reader.ReadString('\n')
+		return
+	}
+
+	fmt.Print("\033[2J\033[H")
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Node ID", "Address", "State", "Since", "Last Heartbeat", "Sessions", "Configured"})
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for _, p := range resp.GetPeers() {
+		state := p.GetState()
+		switch state {
+		case "associated":
+			state = green(state)
+		case "lost":
+			state = red(state)
+		}
+		table.Append([]string{
+			p.GetNodeId(),
+			p.GetAddress(),
+			state,
+			ago(p.GetStateSinceUnix()),
+			ago(p.GetLastHeartbeatUnix()),
+			strconv.Itoa(int(p.GetSessions())),
+			strconv.FormatBool(p.GetConfigured()),
+		})
+	}
+	table.Render()
+	if len(resp.GetPeers()) == 0 {
+		fmt.Println("No PFCP peers")
+	}
+	fmt.Print("\nPress ENTER to return to menu...")
+	reader.ReadString('\n')
+}
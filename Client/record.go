@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "upf/pkg/proto"
+	"upf/pkg/record"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recordMeasurement is the measurement, and file prefix, of recorded flow updates
+const recordMeasurement = "upf_flow"
+
+// readRecordOptions asks where and how to record the flow updates
+func readRecordOptions(reader *bufio.Reader) (record.Options, error) {
+	opts := record.Options{Measurement: recordMeasurement, Dir: "records", Format: record.FormatInflux}
+	if v := prompt(reader, "Format: influx or csv (press Enter for influx): "); v != "" {
+		opts.Format = strings.ToLower(v)
+	}
+	if v := prompt(reader, "Directory (press Enter for records): "); v != "" {
+		opts.Dir = v
+	}
+	if v := prompt(reader, "Rotate files at size in MB (press Enter for no limit): "); v != "" {
+		mb, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return opts, fmt.Errorf("invalid size %q", v)
+		}
+		opts.MaxSize = int64(mb) << 20
+	}
+	if v := prompt(reader, "Rotate files at age, such as 1h (press Enter for no limit): "); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return opts, fmt.Errorf("invalid duration %q", v)
+		}
+		opts.MaxAge = d
+	}
+	opts.Gzip = strings.EqualFold(prompt(reader, "Compress rotated files with gzip? [y/N]: "), "y")
+	return opts, nil
+}
+
+// flowPoint converts a flow update to a point of the recorded measurement
+func flowPoint(resp *pb.Reply) record.Point {
+	return record.Point{
+		Time: time.Unix(0, resp.Measured_Unix_Nano),
+		Tags: []record.Tag{{Key: "fseid", Value: resp.Fseid}, {Key: "imsi", Value: resp.Imsi}},
+		Fields: []record.Field{
+			record.UintField("rx_packets", resp.Rx_Packet),
+			record.UintField("tx_packets", resp.Tx_Packet),
+			record.UintField("rx_bytes", resp.Rx_Bytes),
+			record.UintField("tx_bytes", resp.Tx_Bytes),
+			record.FloatField("rx_speed", float64(resp.Rx_Speed)),
+			record.FloatField("tx_speed", float64(resp.Tx_Speed)),
+			record.FloatField("rx_packet_rate", resp.Rx_Packet_Rate),
+			record.FloatField("tx_packet_rate", resp.Tx_Packet_Rate),
+		},
+	}
+}
+
+// recordFlows captures the PutRequest stream of the chosen flows to files
+// until ENTER is pressed, resuming the subscription if the stream drops
+func recordFlows(reader *bufio.Reader) {
+	flowReq := readFlowRequest(reader)
+	opts, err := readRecordOptions(reader)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	w, err := record.Create(opts)
+	if err != nil {
+		fmt.Printf("Could not record: %v\n", err)
+		return
+	}
+	defer func() {
+		if err := w.Close(); err != nil {
+			fmt.Printf("Could not close the recording: %v\n", err)
+		}
+	}()
+
+	conn, err := dialPFCPAgent()
+	if err != nil {
+		fmt.Printf("Failed to connect: %v\n", err)
+		return
+	}
+	defer conn.Close()
+	client := pb.NewRequestClient(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.PutRequest(ctx, flowReq)
+	if err != nil {
+		fmt.Printf("Error starting stream: %v\n", err)
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		reader.ReadString('\n')
+		cancel()
+		close(done)
+	}()
+
+	fmt.Printf("Recording flow updates to %s as %s. Press ENTER to stop and return to menu...\n", opts.Dir, opts.Format)
+	var points uint64
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil || flowReq.SubscriptionId == 0 || status.Code(err) == codes.InvalidArgument {
+				if ctx.Err() == nil {
+					fmt.Printf("\nStream ended: %v\n", err)
+				}
+				break
+			}
+			fmt.Printf("\nStream dropped (%v), resuming after update %d...\n", err, flowReq.ResumeFrom)
+			time.Sleep(time.Second)
+			if stream, err = client.PutRequest(ctx, flowReq); err != nil {
+				fmt.Printf("Error resuming stream: %v\n", err)
+				break
+			}
+			continue
+		}
+
+		flowReq.SubscriptionId = resp.SubscriptionId
+		if resp.Gap {
+			if resp.GapTo == 0 {
+				fmt.Println(red(fmt.Sprintf("\nSubscription expired, updates after %d were lost", resp.GapFrom-1)))
+				flowReq.ResumeFrom = 0
+			} else {
+				fmt.Println(red(fmt.Sprintf("\nUpdates %d-%d may be missing", resp.GapFrom, resp.GapTo)))
+			}
+			continue
+		}
+		flowReq.ResumeFrom = resp.Count
+		if err = w.Write(flowPoint(resp)); err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			fmt.Printf("\nCould not record: %v\n", err)
+			break
+		}
+		points++
+		fmt.Printf("\rRecorded %d updates, latest from %s at %s", points, resp.Fseid, time.Unix(0, resp.Measured_Unix_Nano).Format("15:04:05.000"))
+	}
+
+	fmt.Printf("\nRecorded %d updates to %s\n", points, opts.Dir)
+	if ctx.Err() == nil {
+		fmt.Print("Press ENTER to return to menu...")
+	}
+	<-done
+}
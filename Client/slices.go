@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	pb "upf/pkg/proto"
+)
+
+// dialConfigAgent connects to the config agent
+func dialConfigAgent() (*grpc.ClientConn, error) {
+	serverAddr := os.Getenv("SERVER_ADDRESS")
+	if serverAddr == "" {
+		serverAddr = "localhost"
+	}
+	return grpc.Dial(serverAddr+":3000", grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+// readLimit asks for a rate limit value, keeping current when the answer is empty
+func readLimit(reader *bufio.Reader, question string, current uint64) (int32, error) {
+	v := prompt(reader, fmt.Sprintf("%s (press Enter for %d): ", question, current))
+	if v == "" {
+		return int32(current), nil
+	}
+	n, err := strconv.ParseUint(v, 10, 31)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", v)
+	}
+	return int32(n), nil
+}
+
+// showSlicePolicers displays the slice policers and optionally sets new rate limits
+func showSlicePolicers(reader *bufio.Reader) {
+	conn, err := dialPFCPAgent()
+	if err != nil {
+		fmt.Printf("Failed to connect: %v\n", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := pb.NewRequestClient(conn).GetSlicePolicers(ctx, &pb.SlicePolicersRequest{})
+	if err != nil {
+		fmt.Printf("Could not get the slice policers: %v\n", err)
+		fmt.Print("\nPress ENTER to return to menu...")
+		reader.ReadString('\n')
+		return
+	}
+
+	fmt.Print("\033[2J\033[H")
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Slice", "Direction", "Rate (bit/s)", "Burst (bytes)", "Passed Packets", "Dropped Packets", "Dropped Bytes"})
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	current := make(map[string]*pb.SlicePolicer)
+	for _, p := range resp.GetPolicers() {
+		current[p.GetDirection()] = p
+		rate := strconv.FormatUint(p.GetRateBps(), 10)
+		if p.GetRateBps() == 0 {
+			rate = "unlimited"
+		}
+		dropped := strconv.FormatUint(p.GetDroppedPackets(), 10)
+		if p.GetDroppedPackets() > 0 {
+			dropped = red(dropped)
+		}
+		table.Append([]string{
+			p.GetSlice(),
+			p.GetDirection(),
+			rate,
+			strconv.FormatUint(p.GetBurstBytes(), 10),
+			strconv.FormatUint(p.GetPassedPackets(), 10),
+			dropped,
+			strconv.FormatUint(p.GetDroppedBytes(), 10),
+		})
+	}
+	table.Render()
+
+	if !strings.EqualFold(prompt(reader, "\nChange the rate limits? [y/N]: "), "y") {
+		return
+	}
+	var limit pb.SliceRateLimit
+	for _, f := range []struct {
+		question string
+		current  uint64
+		into     *int32
+	}{
+		{"Uplink (N3) rate in bit/s, 0 for none", current["uplink"].GetRateBps(), &limit.N3Bps},
+		{"Uplink (N3) burst in bytes", current["uplink"].GetBurstBytes(), &limit.N3BurstBytes},
+		{"Downlink (N6) rate in bit/s, 0 for none", current["downlink"].GetRateBps(), &limit.N6Bps},
+		{"Downlink (N6) burst in bytes", current["downlink"].GetBurstBytes(), &limit.N6BurstBytes},
+	} {
+		if *f.into, err = readLimit(reader, f.question, f.current); err != nil {
+			fmt.Println(err)
+			fmt.Print("\nPress ENTER to return to menu...")
+			reader.ReadString('\n')
+			return
+		}
+	}
+
+	cfgConn, err := dialConfigAgent()
+	if err != nil {
+		fmt.Printf("Failed to connect: %v\n", err)
+		return
+	}
+	defer cfgConn.Close()
+	if _, err := pb.NewRequestClient(cfgConn).SetSliceRateLimit(ctx, &limit); err != nil {
+		fmt.Printf("Could not set the rate limits: %v\n", err)
+	} else {
+		fmt.Println(green("Rate limits set; they apply from the next tick"))
+	}
+	fmt.Print("\nPress ENTER to return to menu...")
+	reader.ReadString('\n')
+}
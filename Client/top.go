@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	pb "upf/pkg/proto"
+)
+
+// topRefresh is how often the top view asks for a new ranking
+const topRefresh = 2 * time.Second
+
+// renderTopSessions clears the screen and draws a ranking of sessions
+func renderTopSessions(resp *pb.TopSessionsReply) {
+	fmt.Print("\033[2J\033[H")
+	fmt.Printf("Top %d of %d sessions by %s over %s, ranked %s\n", len(resp.GetSessions()), resp.GetRanked(),
+		resp.GetMetric(), time.Duration(resp.GetWindowSeconds())*time.Second, time.Unix(0, resp.GetMeasuredUnixNano()).Format("15:04:05"))
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"#", "F-SEID", "IMSI", "DNN", "Bytes", "Packets", "Rate"})
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for i, s := range resp.GetSessions() {
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			s.GetFseid(),
+			s.GetImsi(),
+			s.GetDnn(),
+			strconv.FormatUint(s.GetBytes(), 10),
+			strconv.FormatUint(s.GetPackets(), 10),
+			formatRate(s.GetRate(), resp.GetSpeedUnit()),
+		})
+	}
+	table.Render()
+	fmt.Println("Press ENTER to stop and return to menu...")
+}
+
+// showTopSessions keeps a live ranking of the heaviest sessions on screen
+func showTopSessions(reader *bufio.Reader) {
+	req := &pb.TopSessionsRequest{Metric: prompt(reader, "Rank by bytes, packets or rate (press Enter for bytes): ")}
+	if v := prompt(reader, "Sessions to show (press Enter for 10): "); v != "" {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			fmt.Printf("Invalid number %q\n", v)
+			return
+		}
+		req.N = uint32(n)
+	}
+	if v := prompt(reader, "Window, 1s to 5m (press Enter for 1m): "); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			fmt.Printf("Invalid duration %q\n", v)
+			return
+		}
+		req.WindowSeconds = uint32(d / time.Second)
+	}
+
+	conn, err := dialPFCPAgent()
+	if err != nil {
+		fmt.Printf("Failed to connect: %v\n", err)
+		return
+	}
+	defer conn.Close()
+	client := pb.NewRequestClient(conn)
+
+	done := make(chan struct{})
+	go func() {
+		reader.ReadString('\n')
+		close(done)
+	}()
+
+	ticker := time.NewTicker(topRefresh)
+	defer ticker.Stop()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		resp, err := client.TopSessions(ctx, req)
+		cancel()
+		if err != nil {
+			fmt.Printf("Could not get top sessions: %v\n", err)
+			fmt.Print("\nPress ENTER to return to menu...")
+			<-done
+			return
+		}
+		renderTopSessions(resp)
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
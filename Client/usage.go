@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	pb "upf/pkg/proto"
+)
+
+// usageHistory is the number of usage reports kept on screen
+const usageHistory = 20
+
+// urrRows describes the URR of a session for the rule view
+func urrRows(urr *pb.Urrstruct) [][]string {
+	rows := [][]string{{"URR ID", urr.GetUrrId()}}
+	if m := urr.GetMeasurementMethod(); len(m) > 0 {
+		rows = append(rows, []string{"URR Measurement", strings.Join(m, ", ")})
+	}
+	if t := urr.GetReportingTriggers(); len(t) > 0 {
+		rows = append(rows, []string{"URR Triggers", strings.Join(t, ", ")})
+	}
+	if v := urr.GetVolumeThreshold(); v > 0 {
+		rows = append(rows, []string{"URR Volume Threshold", strconv.FormatUint(v, 10) + " bytes"})
+	}
+	if v := urr.GetVolumeQuota(); v > 0 {
+		rows = append(rows, []string{"URR Volume Quota", strconv.FormatUint(v, 10) + " bytes"})
+	}
+	if v := urr.GetTimeThreshold(); v > 0 {
+		rows = append(rows, []string{"URR Time Threshold", (time.Duration(v) * time.Second).String()})
+	}
+	if v := urr.GetTimeQuota(); v > 0 {
+		rows = append(rows, []string{"URR Time Quota", (time.Duration(v) * time.Second).String()})
+	}
+	if v := urr.GetMeasurementPeriod(); v > 0 {
+		rows = append(rows, []string{"URR Period", (time.Duration(v) * time.Second).String()})
+	}
+	return rows
+}
+
+// renderUsageReports draws the latest usage reports, newest first
+func renderUsageReports(reports []*pb.UsageReportEvent) {
+	fmt.Print("\033[2J\033[H")
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"End", "F-SEID", "URR", "Seq", "Triggers", "UL Bytes", "DL Bytes", "Packets", "Duration"})
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for i := len(reports) - 1; i >= 0; i-- {
+		r := reports[i]
+		table.Append([]string{
+			time.Unix(0, r.GetEndUnixNano()).Format("15:04:05"),
+			r.GetFseid(),
+			r.GetUrrId(),
+			strconv.FormatUint(uint64(r.GetSequence()), 10),
+			strings.Join(r.GetTriggers(), ", "),
+			strconv.FormatUint(r.GetUlBytes(), 10),
+			strconv.FormatUint(r.GetDlBytes(), 10),
+			strconv.FormatUint(r.GetTotalPackets(), 10),
+			(time.Duration(r.GetDurationSeconds()) * time.Second).String(),
+		})
+	}
+	table.Render()
+	if len(reports) == 0 {
+		fmt.Println("Waiting for usage reports...")
+	}
+	fmt.Println("Press ENTER to stop streaming and return to menu...")
+}
+
+// showUsageReports streams the usage reports of a session, an IMSI or every session
+func showUsageReports(reader *bufio.Reader) {
+	ask := func(prompt string) string {
+		fmt.Print(prompt)
+		v, _ := reader.ReadString('\n')
+		return strings.TrimSpace(v)
+	}
+	req := &pb.UsageReportsRequest{
+		Fseid: ask("Enter FSEID to watch (press Enter for all sessions): "),
+		Imsi:  ask("Enter IMSI to watch (press Enter for all subscribers): "),
+	}
+
+	conn, err := dialPFCPAgent()
+	if err != nil {
+		fmt.Printf("Failed to connect: %v\n", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := pb.NewRequestClient(conn).StreamUsageReports(ctx, req)
+	if err != nil {
+		fmt.Printf("Could not stream usage reports: %v\n", err)
+		return
+	}
+
+	go func() {
+		reader.ReadString('\n')
+		cancel()
+	}()
+
+	var reports []*pb.UsageReportEvent
+	renderUsageReports(reports)
+	for {
+		r, err := stream.Recv()
+		if err != nil {
+			return
+		}
+		reports = append(reports, r)
+		if len(reports) > usageHistory {
+			reports = reports[len(reports)-usageHistory:]
+		}
+		renderUsageReports(reports)
+	}
+}
@@ -8,10 +8,13 @@ package config
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net"
+	"path/filepath"
 
+	"upf/Server/metrics"
 	pb "upf/pkg/proto"
 
 	"github.com/tidwall/jsonc"
@@ -43,13 +46,20 @@ type UPFConfig struct {
 	MaxReqRetries            int            `json:"max_req_retries"`             // Maximum request retry attempts
 	RespTimeout              string         `json:"resp_timeout"`                // Response timeout duration
 	EnableNTF                bool           `json:"enable_ntf"`                  // Network Token Function enable flag
-	EnableP4RT               bool           `json:"enable_p4rt"`                 // P4 Runtime enable flag
+	EnableP4RT               bool           `json:"enable_p4rt"`                 // P4 Runtime enable flag, needed by CompileP4Rules
 	EnableHBTimer            bool           `json:"enable_hbTimer"`              // Heartbeat timer enable flag
+	HeartBeatInterval        string         `json:"heart_beat_interval"`         // Interval between heartbeats to peers
 	EnableGTPUPathMonitoring bool           `json:"enable_gtpu_path_monitoring"` // GTPU path monitoring flag
 	QCIQoS                   []QoSConfig    `json:"qci_qos_config"`              // QoS configurations per QCI
 	SliceRateLimit           SliceRateLimit `json:"slice_rate_limit_config"`     // Slice rate limiting configuration
 	CPInterface              CPInterface    `json:"cpiface"`                     // Control Plane interface configuration
 	P4RTCInterface           P4RTCInterface `json:"p4rtciface"`                  // P4 Runtime Traffic Control interface
+	FlowHistory              FlowHistory    `json:"flow_history"`                // Flow history kept by the PFCP agent
+	Alerts                   Alerts         `json:"alerts"`                      // Alert rules evaluated by the PFCP agent
+	Metrics                  Metrics        `json:"metrics"`                     // Prometheus metrics endpoint
+	IPFIX                    IPFIX          `json:"ipfix"`                       // Export of flow records to an IPFIX collector
+	Recorder                 Recorder       `json:"recorder"`                    // Files flow statistics are recorded to
+	DataDir                  string         `json:"data_dir"`                    // Directory of the files the agents keep
 }
 
 // TableSizes defines the sizes for various lookup tables used in the UPF
@@ -63,21 +73,24 @@ type TableSizes struct {
 
 // SimConfig contains simulation-specific configuration parameters
 type SimConfig struct {
-	Core        string `json:"core"`          // Core network address
-	MaxSessions int    `json:"max_sessions"`  // Maximum number of simultaneous sessions
-	StartUEIP   string `json:"start_ue_ip"`   // Starting IP address for UE range
-	StartENBIP  string `json:"start_enb_ip"`  // Starting IP address for eNodeB range
-	StartAUPFIP string `json:"start_aupf_ip"` // Starting IP address for AUPF range
-	N6AppIP     string `json:"n6_app_ip"`     // N6 interface application IP
-	N9AppIP     string `json:"n9_app_ip"`     // N9 interface application IP
-	StartN3TEID string `json:"start_n3_teid"` // Starting N3 TEID value
-	StartN9TEID string `json:"start_n9_teid"` // Starting N9 TEID value
-	UplinkMBR   int    `json:"uplink_mbr"`    // Uplink Maximum Bit Rate
-	UplinkGBR   int    `json:"uplink_gbr"`    // Uplink Guaranteed Bit Rate
-	DownlinkMBR int    `json:"downlink_mbr"`  // Downlink Maximum Bit Rate
-	DownlinkGBR int    `json:"downlink_gbr"`  // Downlink Guaranteed Bit Rate
-	PktSize     int    `json:"pkt_size"`      // Packet size for simulation
-	TotalFlows  int    `json:"total_flows"`   // Total number of flows to simulate
+	Core         string `json:"core"`          // Core network address
+	MaxSessions  int    `json:"max_sessions"`  // Maximum number of simultaneous sessions
+	StartUEIP    string `json:"start_ue_ip"`   // Starting IP address for UE range
+	StartENBIP   string `json:"start_enb_ip"`  // Starting IP address for eNodeB range
+	StartAUPFIP  string `json:"start_aupf_ip"` // Starting IP address for AUPF range
+	N6AppIP      string `json:"n6_app_ip"`     // N6 interface application IP
+	N9AppIP      string `json:"n9_app_ip"`     // N9 interface application IP
+	StartN3TEID  string `json:"start_n3_teid"` // Starting N3 TEID value
+	StartN9TEID  string `json:"start_n9_teid"` // Starting N9 TEID value
+	UplinkMBR    int    `json:"uplink_mbr"`    // Uplink Maximum Bit Rate
+	UplinkGBR    int    `json:"uplink_gbr"`    // Uplink Guaranteed Bit Rate
+	DownlinkMBR  int    `json:"downlink_mbr"`  // Downlink Maximum Bit Rate
+	DownlinkGBR  int    `json:"downlink_gbr"`  // Downlink Guaranteed Bit Rate
+	PktSize      int    `json:"pkt_size"`      // Packet size for simulation
+	TotalFlows   int    `json:"total_flows"`   // Total number of flows to simulate
+	Seed         int64  `json:"seed"`          // Seed of the traffic simulator
+	UplinkAMBR   int    `json:"uplink_ambr"`   // Uplink session Aggregate Maximum Bit Rate, 0 for none
+	DownlinkAMBR int    `json:"downlink_ambr"` // Downlink session Aggregate Maximum Bit Rate, 0 for none
 }
 
 // Interface defines network interface configuration
@@ -97,9 +110,9 @@ type QoSConfig struct {
 
 // SliceRateLimit defines rate limiting parameters for network slices
 type SliceRateLimit struct {
-	N6Bps        int `json:"n6_bps"`         // N6 interface rate limit in bps
+	N6Bps        int `json:"n6_bps"`         // N6 (downlink) interface rate limit in bps, 0 for none
 	N6BurstBytes int `json:"n6_burst_bytes"` // N6 interface burst size in bytes
-	N3Bps        int `json:"n3_bps"`         // N3 interface rate limit in bps
+	N3Bps        int `json:"n3_bps"`         // N3 (uplink) interface rate limit in bps, 0 for none
 	N3BurstBytes int `json:"n3_burst_bytes"` // N3 interface burst size in bytes
 }
 
@@ -122,21 +135,93 @@ type P4RTCInterface struct {
 	ClearStateOnRestart bool   `json:"clear_state_on_restart"` // Clear state on restart flag
 }
 
-func (s *server) GetConfig(ctx context.Context, req *pb.ConfigRequest) (*pb.ConfigReply, error) {
-	data, err := ioutil.ReadFile("upf.jsonc")
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to read config: %v", err)
+// FlowHistory configures the time series of flow counters kept per F-SEID
+type FlowHistory struct {
+	MaxFlows    int                 `json:"max_flows"`   // Sessions recorded at most; 0 means 256
+	Resolutions []HistoryResolution `json:"resolutions"` // Resolutions kept, finest first; empty means the defaults
+}
+
+// HistoryResolution is one resolution of the flow history
+type HistoryResolution struct {
+	Step      string `json:"step"`      // Time covered by one point, such as "1s"
+	Retention string `json:"retention"` // How far back points are kept, such as "10m"
+}
+
+// Alerts names the alert rules file and the webhook alerts are posted to
+type Alerts struct {
+	RulesFile string `json:"rules_file"` // JSONC file holding the alert rules; empty disables alerting
+	Webhook   string `json:"webhook"`    // URL firing and resolved alerts are posted to; empty disables it
+}
+
+// Metrics configures the HTTP endpoint serving Prometheus metrics
+type Metrics struct {
+	Port       string `json:"port"`        // Port of the /metrics endpoint; empty means 9090
+	PerSession bool   `json:"per_session"` // Also export series labelled by F-SEID, one set per session
+}
+
+// IPFIX configures the export of flow records to an IPFIX collector
+type IPFIX struct {
+	Collector         string `json:"collector"`          // host:port of the collector; empty disables export
+	Transport         string `json:"transport"`          // udp or tcp; empty means udp
+	ActiveTimeout     string `json:"active_timeout"`     // Interval active sessions are exported at; empty means 60s
+	TemplateRefresh   string `json:"template_refresh"`   // Interval templates are resent at over UDP; empty means 10m
+	EnterpriseNumber  uint32 `json:"enterprise_number"`  // Enterprise number of the IMSI, F-SEID, DNN and TEID elements; 0 means 32473
+	ObservationDomain uint32 `json:"observation_domain"` // Observation domain ID of the records
+}
+
+// Recorder configures the files the PFCP agent records flow statistics to
+type Recorder struct {
+	Dir       string `json:"dir"`         // Directory of the files; empty disables recording
+	Format    string `json:"format"`      // influx or csv; empty means influx
+	Interval  string `json:"interval"`    // Time between points; empty means 10s
+	MaxSizeMB int    `json:"max_size_mb"` // Rotate files at this size; 0 means no limit
+	MaxAge    string `json:"max_age"`     // Rotate files at this age; empty means no limit
+	Gzip      bool   `json:"gzip"`        // Compress rotated files with gzip
+}
+
+// ConfigFile is the path of the UPF configuration file read by the agents
+const ConfigFile = "upf.jsonc"
+
+// DataPath returns the path of the file name in the data directory of cfg.
+// A relative data_dir is resolved against the directory of the configuration
+// file, which is also the data directory when data_dir is empty or cfg is nil.
+func DataPath(cfg *UPFConfig, name string) string {
+	dir := filepath.Dir(ConfigFile)
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if cfg != nil && cfg.DataDir != "" {
+		if filepath.IsAbs(cfg.DataDir) {
+			dir = cfg.DataDir
+		} else {
+			dir = filepath.Join(dir, cfg.DataDir)
+		}
 	}
+	return filepath.Join(dir, name)
+}
 
-	cleanJSON := jsonc.ToJSON(data)
+// LoadConfig reads and parses the JSONC configuration file at path
+func LoadConfig(path string) (*UPFConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
 
 	var config UPFConfig
-	err = json.Unmarshal(cleanJSON, &config)
+	if err := json.Unmarshal(jsonc.ToJSON(data), &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return &config, nil
+}
+
+func (s *server) GetConfig(ctx context.Context, req *pb.ConfigRequest) (*pb.ConfigReply, error) {
+	config, err := LoadConfig(ConfigFile)
 	if err != nil {
-		log.Fatalf("Failed to unmarshal config: %v", err)
+		return nil, status.Errorf(codes.Internal, "%v", err)
 	}
 
 	log.Printf("Loaded config in mode: %s", config.Mode)
+	config.SliceRateLimit = effectiveSliceRateLimit(config.SliceRateLimit)
 
 	return &pb.ConfigReply{
 		Config: &pb.UPFConfig{
@@ -153,6 +238,7 @@ func (s *server) GetConfig(ctx context.Context, req *pb.ConfigRequest) (*pb.Conf
 			EnableNtf:                config.EnableNTF,
 			EnableP4Rt:               config.EnableP4RT,
 			EnableHbTimer:            config.EnableHBTimer,
+			HeartBeatInterval:        config.HeartBeatInterval,
 			EnableGtpuPathMonitoring: config.EnableGTPUPathMonitoring,
 			TableSizes: &pb.TableSizes{
 				PdrLookup:        int32(config.TableSizes.PDRLookup),
@@ -162,21 +248,24 @@ func (s *server) GetConfig(ctx context.Context, req *pb.ConfigRequest) (*pb.Conf
 				FarLookup:        int32(config.TableSizes.FARLookup),
 			},
 			Sim: &pb.SimConfig{
-				Core:        config.Sim.Core,
-				MaxSessions: int32(config.Sim.MaxSessions),
-				StartUeIp:   config.Sim.StartUEIP,
-				StartEnbIp:  config.Sim.StartENBIP,
-				StartAupfIp: config.Sim.StartAUPFIP,
-				N6AppIp:     config.Sim.N6AppIP,
-				N9AppIp:     config.Sim.N9AppIP,
-				StartN3Teid: config.Sim.StartN3TEID,
-				StartN9Teid: config.Sim.StartN9TEID,
-				UplinkMbr:   int32(config.Sim.UplinkMBR),
-				UplinkGbr:   int32(config.Sim.UplinkGBR),
-				DownlinkMbr: int32(config.Sim.DownlinkMBR),
-				DownlinkGbr: int32(config.Sim.DownlinkGBR),
-				PktSize:     int32(config.Sim.PktSize),
-				TotalFlows:  int32(config.Sim.TotalFlows),
+				Core:         config.Sim.Core,
+				MaxSessions:  int32(config.Sim.MaxSessions),
+				StartUeIp:    config.Sim.StartUEIP,
+				StartEnbIp:   config.Sim.StartENBIP,
+				StartAupfIp:  config.Sim.StartAUPFIP,
+				N6AppIp:      config.Sim.N6AppIP,
+				N9AppIp:      config.Sim.N9AppIP,
+				StartN3Teid:  config.Sim.StartN3TEID,
+				StartN9Teid:  config.Sim.StartN9TEID,
+				UplinkMbr:    int32(config.Sim.UplinkMBR),
+				UplinkGbr:    int32(config.Sim.UplinkGBR),
+				DownlinkMbr:  int32(config.Sim.DownlinkMBR),
+				DownlinkGbr:  int32(config.Sim.DownlinkGBR),
+				PktSize:      int32(config.Sim.PktSize),
+				TotalFlows:   int32(config.Sim.TotalFlows),
+				Seed:         config.Sim.Seed,
+				UplinkAmbr:   int32(config.Sim.UplinkAMBR),
+				DownlinkAmbr: int32(config.Sim.DownlinkAMBR),
 			},
 			Access: &pb.Interface{Ifname: config.Access.IfName},
 			Core:   &pb.Interface{Ifname: config.Core.IfName},
@@ -204,6 +293,32 @@ func (s *server) GetConfig(ctx context.Context, req *pb.ConfigRequest) (*pb.Conf
 				P4RtcPort: config.P4RTCInterface.P4RTCPort, SliceId: int32(config.P4RTCInterface.SliceID),
 				DefaultTc: int32(config.P4RTCInterface.DefaultTC), ClearStateOnRestart: config.P4RTCInterface.ClearStateOnRestart,
 			},
+			FlowHistory: func() *pb.FlowHistoryConfig {
+				h := &pb.FlowHistoryConfig{MaxFlows: int32(config.FlowHistory.MaxFlows)}
+				for _, r := range config.FlowHistory.Resolutions {
+					h.Resolutions = append(h.Resolutions, &pb.HistoryResolution{Step: r.Step, Retention: r.Retention})
+				}
+				return h
+			}(),
+			Alerts:  &pb.AlertsConfig{RulesFile: config.Alerts.RulesFile, Webhook: config.Alerts.Webhook},
+			Metrics: &pb.MetricsConfig{Port: config.Metrics.Port, PerSession: config.Metrics.PerSession},
+			Ipfix: &pb.IPFIXConfig{
+				Collector:         config.IPFIX.Collector,
+				Transport:         config.IPFIX.Transport,
+				ActiveTimeout:     config.IPFIX.ActiveTimeout,
+				TemplateRefresh:   config.IPFIX.TemplateRefresh,
+				EnterpriseNumber:  config.IPFIX.EnterpriseNumber,
+				ObservationDomain: config.IPFIX.ObservationDomain,
+			},
+			Recorder: &pb.RecorderConfig{
+				Dir:       config.Recorder.Dir,
+				Format:    config.Recorder.Format,
+				Interval:  config.Recorder.Interval,
+				MaxSizeMb: int32(config.Recorder.MaxSizeMB),
+				MaxAge:    config.Recorder.MaxAge,
+				Gzip:      config.Recorder.Gzip,
+			},
+			DataDir: config.DataDir,
 		},
 	}, nil
 }
@@ -214,7 +329,7 @@ func StartConfigAgent(port string) error {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	s := grpc.NewServer()
+	s := grpc.NewServer(metrics.ServerOptions("config")...)
 	srv := &server{}
 	pb.RegisterRequestServer(s, srv)
 
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	pb "upf/pkg/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sliceRateLimit holds the slice rate limits set at runtime. They take
+// precedence over slice_rate_limit_config of upf.jsonc, which is not
+// rewritten, until the UPF restarts.
+var sliceRateLimit struct {
+	mu       sync.Mutex
+	set      bool // limit was set at runtime
+	limit    SliceRateLimit
+	watchers []func(SliceRateLimit)
+}
+
+// WatchSliceRateLimit calls fn with every slice rate limit set at runtime,
+// starting with the current one if it was already set
+func WatchSliceRateLimit(fn func(SliceRateLimit)) {
+	sliceRateLimit.mu.Lock()
+	defer sliceRateLimit.mu.Unlock()
+	sliceRateLimit.watchers = append(sliceRateLimit.watchers, fn)
+	if sliceRateLimit.set {
+		fn(sliceRateLimit.limit)
+	}
+}
+
+// effectiveSliceRateLimit returns the limit set at runtime, or the one of the file if none was
+func effectiveSliceRateLimit(file SliceRateLimit) SliceRateLimit {
+	sliceRateLimit.mu.Lock()
+	defer sliceRateLimit.mu.Unlock()
+	if sliceRateLimit.set {
+		return sliceRateLimit.limit
+	}
+	return file
+}
+
+// SetSliceRateLimit replaces the slice rate limits and hands them to the
+// watchers, which apply them to the running policers
+func SetSliceRateLimit(l SliceRateLimit) error {
+	if l.N3Bps < 0 || l.N3BurstBytes < 0 || l.N6Bps < 0 || l.N6BurstBytes < 0 {
+		return fmt.Errorf("slice rate limits must not be negative")
+	}
+	sliceRateLimit.mu.Lock()
+	defer sliceRateLimit.mu.Unlock()
+	sliceRateLimit.set, sliceRateLimit.limit = true, l
+	for _, fn := range sliceRateLimit.watchers {
+		fn(l)
+	}
+	return nil
+}
+
+// SetSliceRateLimit changes the slice policers of the running UPF and returns the limits now in force
+func (s *server) SetSliceRateLimit(ctx context.Context, req *pb.SliceRateLimit) (*pb.SliceRateLimit, error) {
+	l := SliceRateLimit{
+		N6Bps:        int(req.N6Bps),
+		N6BurstBytes: int(req.N6BurstBytes),
+		N3Bps:        int(req.N3Bps),
+		N3BurstBytes: int(req.N3BurstBytes),
+	}
+	if err := SetSliceRateLimit(l); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	log.Printf("🚦 Slice rate limits set: N3 %d bit/s (burst %d bytes), N6 %d bit/s (burst %d bytes)",
+		l.N3Bps, l.N3BurstBytes, l.N6Bps, l.N6BurstBytes)
+	return req, nil
+}
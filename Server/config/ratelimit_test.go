@@ -0,0 +1,78 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	pb "upf/pkg/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// resetSliceRateLimit forgets the limits set at runtime and their watchers
+// when the test ends
+func resetSliceRateLimit(t *testing.T) {
+	t.Cleanup(func() {
+		sliceRateLimit.mu.Lock()
+		defer sliceRateLimit.mu.Unlock()
+		sliceRateLimit.set, sliceRateLimit.limit, sliceRateLimit.watchers = false, SliceRateLimit{}, nil
+	})
+}
+
+func TestSetSliceRateLimit(t *testing.T) {
+	resetSliceRateLimit(t)
+	file := SliceRateLimit{N3Bps: 1000, N6Bps: 2000}
+	if got := effectiveSliceRateLimit(file); got != file {
+		t.Errorf("before any was set the limit is %+v, want that of the file", got)
+	}
+
+	var early []SliceRateLimit
+	WatchSliceRateLimit(func(l SliceRateLimit) { early = append(early, l) })
+	limit := SliceRateLimit{N3Bps: 8000, N3BurstBytes: 1500, N6Bps: 16000, N6BurstBytes: 3000}
+	if err := SetSliceRateLimit(limit); err != nil {
+		t.Fatal(err)
+	}
+	if len(early) != 1 || early[0] != limit {
+		t.Errorf("watcher was handed %+v, want %+v", early, limit)
+	}
+	if got := effectiveSliceRateLimit(file); got != limit {
+		t.Errorf("limit in force %+v, want %+v", got, limit)
+	}
+
+	// A watcher added later starts with the limit in force
+	var late []SliceRateLimit
+	WatchSliceRateLimit(func(l SliceRateLimit) { late = append(late, l) })
+	if len(late) != 1 || late[0] != limit {
+		t.Errorf("late watcher was handed %+v, want %+v", late, limit)
+	}
+
+	for _, bad := range []SliceRateLimit{
+		{N3Bps: -1},
+		{N3BurstBytes: -1},
+		{N6Bps: -1},
+		{N6BurstBytes: -1},
+	} {
+		if err := SetSliceRateLimit(bad); err == nil {
+			t.Errorf("%+v accepted", bad)
+		}
+	}
+	if len(early) != 1 || len(late) != 1 || effectiveSliceRateLimit(file) != limit {
+		t.Errorf("rejected limits were applied: %+v and %+v", early, late)
+	}
+}
+
+func TestSetSliceRateLimitRPC(t *testing.T) {
+	resetSliceRateLimit(t)
+	var s server
+	req := &pb.SliceRateLimit{N3Bps: 8000, N3BurstBytes: 1500, N6Bps: 16000, N6BurstBytes: 3000}
+	if reply, err := s.SetSliceRateLimit(context.Background(), req); err != nil || reply.GetN6Bps() != 16000 {
+		t.Fatalf("got %v (%v)", reply, err)
+	}
+	if _, err := s.SetSliceRateLimit(context.Background(), &pb.SliceRateLimit{N6BurstBytes: -1}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("negative burst: %v, want InvalidArgument", err)
+	}
+	if got := effectiveSliceRateLimit(SliceRateLimit{}); got.N3Bps != 8000 || got.N6BurstBytes != 3000 {
+		t.Errorf("limit in force %+v", got)
+	}
+}
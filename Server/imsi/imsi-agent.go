@@ -10,6 +10,7 @@ import (
 	"log"
 	"net"
 
+	"upf/Server/metrics"
 	pb "upf/pkg/proto"
 
 	"google.golang.org/grpc"
@@ -26,14 +27,14 @@ type IMSI struct {
 // imsiServer implements the gRPC Request service for IMSI management
 type imsiServer struct {
 	pb.UnimplementedRequestServer
-	imsi map[string]IMSI // Map of IMSI to service identifiers
+	imsi *Store // Subscriber store shared with the PFCP agent
 }
 
 // GetIMSI handles IMSI information requests by looking up the IMSI in the server's database
 // and returning the associated service information
 func (s *imsiServer) GetIMSI(ctx context.Context, req *pb.IMSIRequest) (*pb.IMSIReply, error) {
 	// Look up the IMSI info in the map
-	imsiInfo, exists := s.imsi[req.Imsi]
+	imsiInfo, exists := s.imsi.Get(req.Imsi)
 	if !exists {
 		return nil, status.Errorf(codes.NotFound, "IMSI not found: %s", req.Imsi)
 	}
@@ -57,18 +58,18 @@ func StartIMSIAgent(port string) error {
 	}
 
 	// Initialize gRPC server
-	s := grpc.NewServer()
+	s := grpc.NewServer(metrics.ServerOptions("imsi")...)
 
 	// Initialize the IMSI server with sample data
 	srv := &imsiServer{
-		imsi: make(map[string]IMSI),
+		imsi: DefaultStore(),
 	}
 
 	// Add sample IMSI data for testing
 	// In production, this would be replaced with real IMSI data
-	srv.imsi["IMSI1"] = IMSI{Inter: "fseid1", Ims: "fseid2"}
-	srv.imsi["IMSI2"] = IMSI{Inter: "fseid3", Ims: "fseid4"}
-	srv.imsi["IMSI3"] = IMSI{Inter: "fseid5", Ims: "fseid6"}
+	srv.imsi.Set("IMSI1", IMSI{Inter: "fseid1", Ims: "fseid2"})
+	srv.imsi.Set("IMSI2", IMSI{Inter: "fseid3", Ims: "fseid4"})
+	srv.imsi.Set("IMSI3", IMSI{Inter: "fseid5", Ims: "fseid6"})
 
 	// Register the IMSI server with gRPC
 	pb.RegisterRequestServer(s, srv)
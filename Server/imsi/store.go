@@ -0,0 +1,73 @@
+package imsi
+
+import "sync"
+
+// Store maps IMSIs to the F-SEIDs of their sessions and is safe for concurrent use
+type Store struct {
+	mu   sync.RWMutex
+	imsi map[string]IMSI // Map of IMSI to service identifiers
+}
+
+// NewStore creates an empty subscriber store
+func NewStore() *Store {
+	return &Store{imsi: make(map[string]IMSI)}
+}
+
+// sharedStore is the subscriber store shared by all agents running in this process
+var sharedStore = NewStore()
+
+// DefaultStore returns the subscriber store shared by all agents running in this process
+func DefaultStore() *Store {
+	return sharedStore
+}
+
+// Get returns the service identifiers of an IMSI
+func (st *Store) Get(imsi string) (IMSI, bool) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	info, ok := st.imsi[imsi]
+	return info, ok
+}
+
+// Set replaces the service identifiers of an IMSI
+func (st *Store) Set(imsi string, info IMSI) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.imsi[imsi] = info
+}
+
+// SetSession records fseid as the IMSI's session on the given DNN.
+// Sessions on the "ims" DNN fill the IMS slot, all others the internet slot.
+func (st *Store) SetSession(imsi, dnn, fseid string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	info := st.imsi[imsi]
+	if dnn == "ims" {
+		info.Ims = fseid
+	} else {
+		info.Inter = fseid
+	}
+	st.imsi[imsi] = info
+}
+
+// RemoveSession clears fseid from the IMSI's service identifiers and forgets
+// the IMSI once it has no session left
+func (st *Store) RemoveSession(imsi, fseid string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	info, ok := st.imsi[imsi]
+	if !ok {
+		return
+	}
+	if info.Inter == fseid {
+		info.Inter = ""
+	}
+	if info.Ims == fseid {
+		info.Ims = ""
+	}
+	if info == (IMSI{}) {
+		delete(st.imsi, imsi)
+		return
+	}
+	st.imsi[imsi] = info
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcRequests = NewCounterVec("grpc_server_requests_total",
+		"gRPC requests handled, by agent, method and status code", "agent", "method", "code")
+	grpcLatency = NewHistogramVec("grpc_server_request_duration_seconds",
+		"Time taken to handle gRPC requests, by agent and method. Streams are timed until they end.",
+		DefaultBuckets, "agent", "method")
+)
+
+// observe records a finished request
+func observe(agent, method string, start time.Time, err error) {
+	grpcRequests.Inc(agent, method, status.Code(err).String())
+	grpcLatency.Observe(time.Since(start).Seconds(), agent, method)
+}
+
+// ServerOptions returns the options counting and timing every request of a
+// gRPC server under the given agent name
+func ServerOptions(agent string) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryInterceptor(agent)),
+		grpc.ChainStreamInterceptor(streamInterceptor(agent)),
+	}
+}
+
+func unaryInterceptor(agent string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observe(agent, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func streamInterceptor(agent string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observe(agent, info.FullMethod, start, err)
+		return err
+	}
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestUnaryInterceptor checks that a failing unary call is counted under its
+// status code and timed, and shows up on /metrics
+func TestUnaryInterceptor(t *testing.T) {
+	const method = "/upf.Test/Fail"
+	count := func() (uint64, float64) {
+		grpcRequests.mu.Lock()
+		defer grpcRequests.mu.Unlock()
+		grpcLatency.mu.Lock()
+		defer grpcLatency.mu.Unlock()
+		var calls float64
+		if s, ok := grpcRequests.values[strings.Join([]string{"test", method, "NotFound"}, "\xff")]; ok {
+			calls = s.value
+		}
+		var timed uint64
+		if s, ok := grpcLatency.values[strings.Join([]string{"test", method}, "\xff")]; ok {
+			timed = s.count
+		}
+		return timed, calls
+	}
+	timedBefore, callsBefore := count()
+
+	fail := status.Error(codes.NotFound, "no such session")
+	_, err := unaryInterceptor("test")(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, req any) (any, error) { return nil, fail })
+	if err != fail {
+		t.Fatalf("interceptor returned %v, want the handler's error", err)
+	}
+	if timed, calls := count(); timed != timedBefore+1 || calls != callsBefore+1 {
+		t.Errorf("counted %g calls and timed %d, want %g and %d", calls, timed, callsBefore+1, timedBefore+1)
+	}
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("served as %s", ct)
+	}
+	if line := `grpc_server_requests_total{agent="test",method="/upf.Test/Fail",code="NotFound"} `; !strings.Contains(string(body), line) {
+		t.Errorf("/metrics lacks %s:\n%s", line, body)
+	}
+}
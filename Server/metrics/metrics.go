@@ -0,0 +1,316 @@
+/*
+Package metrics exposes counters of the UPF agents over HTTP in the Prometheus
+text exposition format. Agents register counters and histograms they update as
+events happen, and collectors that read gauges from their own state on every
+scrape. All of them live in a single registry served at /metrics.
+*/
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Types of metric families
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// DefaultBuckets are the upper bounds of latency histograms, in seconds
+var DefaultBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Emit reports one sample of a collector, with one value per label
+type Emit func(value float64, labels ...string)
+
+// family is a metric exposed by the registry
+type family interface {
+	describe() (name, help, kind string)
+	write(w *bufio.Writer)
+}
+
+// registry holds every metric family by name
+type registry struct {
+	mu       sync.Mutex
+	families map[string]family
+}
+
+var defaultRegistry = &registry{families: make(map[string]family)}
+
+// register adds a family, panicking if its name is taken
+func (r *registry) register(f family) {
+	name, _, _ := f.describe()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.families[name]; ok {
+		panic("metrics: " + name + " registered twice")
+	}
+	r.families[name] = f
+}
+
+// write renders every family, sorted by name
+func (r *registry) write(w *bufio.Writer) {
+	r.mu.Lock()
+	families := make([]family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.Unlock()
+	sort.Slice(families, func(i, j int) bool {
+		a, _, _ := families[i].describe()
+		b, _, _ := families[j].describe()
+		return a < b
+	})
+
+	for _, f := range families {
+		name, help, kind := f.describe()
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, kind)
+		f.write(w)
+	}
+}
+
+// desc names a family and its labels
+type desc struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+func (d *desc) describe() (string, string, string) { return d.name, d.help, d.kind }
+
+// checkLabels panics if a sample does not have one value per label
+func (d *desc) checkLabels(values []string) {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", d.name, len(d.labels), len(values)))
+	}
+}
+
+// CounterVec is a set of counters partitioned by label values
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	values map[string]*counterSeries // By joined label values
+}
+
+// counterSeries is one counter of a CounterVec
+type counterSeries struct {
+	labels []string
+	value  float64
+}
+
+// NewCounterVec registers a counter partitioned by the given labels
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := newCounterVec(name, help, labels...)
+	defaultRegistry.register(c)
+	return c
+}
+
+func newCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{
+		desc:   desc{name: name, help: help, kind: typeCounter, labels: labels},
+		values: make(map[string]*counterSeries),
+	}
+}
+
+// Inc adds one to the counter with the given label values
+func (c *CounterVec) Inc(labels ...string) { c.Add(1, labels...) }
+
+// Add adds v, which must not be negative, to the counter with the given label values
+func (c *CounterVec) Add(v float64, labels ...string) {
+	c.checkLabels(labels)
+	key := strings.Join(labels, "\xff")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.values[key]
+	if !ok {
+		s = &counterSeries{labels: slices.Clone(labels)}
+		c.values[key] = s
+	}
+	s.value += v
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.values) {
+		s := c.values[key]
+		writeSample(w, c.name, c.labels, s.labels, "", "", s.value)
+	}
+}
+
+// HistogramVec is a set of histograms partitioned by label values
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogramSeries // By joined label values
+}
+
+// histogramSeries is one histogram of a HistogramVec
+type histogramSeries struct {
+	labels []string
+	counts []uint64 // Observations per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec registers a histogram with the given bucket upper bounds,
+// partitioned by the given labels
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := newHistogramVec(name, help, buckets, labels...)
+	defaultRegistry.register(h)
+	return h
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return &HistogramVec{
+		desc:    desc{name: name, help: help, kind: typeHistogram, labels: labels},
+		buckets: slices.Sorted(slices.Values(buckets)),
+		values:  make(map[string]*histogramSeries),
+	}
+}
+
+// Observe records v in the histogram with the given label values
+func (h *HistogramVec) Observe(v float64, labels ...string) {
+	h.checkLabels(labels)
+	key := strings.Join(labels, "\xff")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.values[key]
+	if !ok {
+		s = &histogramSeries{labels: slices.Clone(labels), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = s
+	}
+	if i, _ := slices.BinarySearch(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.values) {
+		s := h.values[key]
+		var cumulative uint64
+		for i, le := range h.buckets {
+			cumulative += s.counts[i]
+			writeSample(w, h.name+"_bucket", h.labels, s.labels, "le", formatValue(le), float64(cumulative))
+		}
+		writeSample(w, h.name+"_bucket", h.labels, s.labels, "le", "+Inf", float64(s.count))
+		writeSample(w, h.name+"_sum", h.labels, s.labels, "", "", s.sum)
+		writeSample(w, h.name+"_count", h.labels, s.labels, "", "", float64(s.count))
+	}
+}
+
+// collector is a family whose samples are read on every scrape
+type collector struct {
+	desc
+	collect func(Emit)
+}
+
+// NewGaugeFunc registers a gauge whose samples collect reports on every scrape
+func NewGaugeFunc(name, help string, labels []string, collect func(Emit)) {
+	defaultRegistry.register(&collector{desc: desc{name: name, help: help, kind: typeGauge, labels: labels}, collect: collect})
+}
+
+// NewCounterFunc registers a counter whose samples collect reports on every
+// scrape, for agents that keep cumulative counters of their own
+func NewCounterFunc(name, help string, labels []string, collect func(Emit)) {
+	defaultRegistry.register(&collector{desc: desc{name: name, help: help, kind: typeCounter, labels: labels}, collect: collect})
+}
+
+func (c *collector) write(w *bufio.Writer) {
+	c.collect(func(v float64, labels ...string) {
+		c.checkLabels(labels)
+		writeSample(w, c.name, c.labels, labels, "", "", v)
+	})
+}
+
+// writeSample writes one sample line, with an optional extra label such as le
+func writeSample(w *bufio.Writer, name string, names, values []string, extraName, extraValue string, v float64) {
+	w.WriteString(name)
+	if len(names) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, n := range names {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", n, escapeLabel(values[i]))
+		}
+		if extraName != "" {
+			if len(names) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", extraName, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatValue(v))
+	w.WriteByte('\n')
+}
+
+// formatValue renders a sample value as the exposition format expects
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+func escapeHelp(s string) string { return helpEscaper.Replace(s) }
+
+// sortedKeys returns the keys of a map in order, so scrapes list series stably
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Handler serves the registered metrics in the Prometheus text format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		bw := bufio.NewWriter(w)
+		defaultRegistry.write(bw)
+		if err := bw.Flush(); err != nil {
+			log.Printf("⚠️ Failed to write metrics to %s: %v", r.RemoteAddr, err)
+		}
+	})
+}
+
+// StartMetricsServer serves /metrics over HTTP on the given port
+func StartMetricsServer(port string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+
+	log.Printf("Metrics endpoint listening on port %s...", port)
+	return http.ListenAndServe(":"+port, mux)
+}
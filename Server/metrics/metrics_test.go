@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"bufio"
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestWriteExposition renders a registry with a gauge, a counter and a
+// histogram, registered out of order
+func TestWriteExposition(t *testing.T) {
+	r := &registry{families: make(map[string]family)}
+
+	hist := newHistogramVec("c_seconds", "Histogram", []float64{1, 0.125}, "method")
+	for _, v := range []float64{0.0625, 0.125, 0.5, 4} {
+		hist.Observe(v, "get")
+	}
+	r.register(hist)
+
+	counter := newCounterVec("b_total", "Counter", "path")
+	counter.Inc("plain")
+	counter.Add(2, "a\"b\\c\nd")
+	r.register(counter)
+
+	r.register(&collector{
+		desc:    desc{name: "a_gauge", help: "Gauge\\with a backslash\nand a newline", kind: typeGauge},
+		collect: func(emit Emit) { emit(1.5) },
+	})
+
+	var b strings.Builder
+	w := bufio.NewWriter(&b)
+	r.write(w)
+	w.Flush()
+
+	want := `# HELP a_gauge Gauge\\with a backslash\nand a newline
+# TYPE a_gauge gauge
+a_gauge 1.5
+# HELP b_total Counter
+# TYPE b_total counter
+b_total{path="a\"b\\c\nd"} 2
+b_total{path="plain"} 1
+# HELP c_seconds Histogram
+# TYPE c_seconds histogram
+c_seconds_bucket{method="get",le="0.125"} 2
+c_seconds_bucket{method="get",le="1"} 3
+c_seconds_bucket{method="get",le="+Inf"} 4
+c_seconds_sum{method="get"} 4.6875
+c_seconds_count{method="get"} 4
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	for _, tc := range []struct {
+		v    float64
+		want string
+	}{
+		{0, "0"},
+		{1e21, "1e+21"},
+		{0.0005, "0.0005"},
+		{math.Inf(1), "+Inf"},
+		{math.Inf(-1), "-Inf"},
+		{math.NaN(), "NaN"},
+	} {
+		if got := formatValue(tc.v); got != tc.want {
+			t.Errorf("formatValue(%g) = %s, want %s", tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	r := &registry{families: make(map[string]family)}
+	r.register(newCounterVec("twice_total", "Counter"))
+	defer func() {
+		if recover() == nil {
+			t.Error("registered twice_total twice")
+		}
+	}()
+	r.register(newCounterVec("twice_total", "Counter"))
+}
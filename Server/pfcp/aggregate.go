@@ -0,0 +1,308 @@
+package pfcp
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"upf/Server/rule"
+	pb "upf/pkg/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	aggregateRefresh = time.Second // How often the members of the groups follow the rule store
+	aggregateWindow  = time.Second // Interval the rates of the groups are derived over
+)
+
+// Groupings of GetAggregateStats
+const (
+	groupIMSI  = "imsi"
+	groupDNN   = "dnn"
+	groupSlice = "slice"
+	groupUPF   = "upf"
+	groupFlow  = "flow"
+)
+
+// aggCounters are the cumulative counters of a flow or group
+type aggCounters struct {
+	rxPackets uint64
+	txPackets uint64
+	rxBytes   uint64
+	txBytes   uint64
+}
+
+// countersOf returns the cumulative counters of a flow measurement
+func countersOf(d flowmeasuredata) aggCounters {
+	return aggCounters{rxPackets: d.Rx_Packet, txPackets: d.Tx_Packet, rxBytes: d.Rx_Bytes, txBytes: d.Tx_Bytes}
+}
+
+// since returns what the counters gained after prev. A counter that went
+// backwards was reset, and all of it is new.
+func (c aggCounters) since(prev aggCounters) aggCounters {
+	delta := func(cur, old uint64) uint64 {
+		if cur < old {
+			return cur
+		}
+		return cur - old
+	}
+	return aggCounters{
+		rxPackets: delta(c.rxPackets, prev.rxPackets),
+		txPackets: delta(c.txPackets, prev.txPackets),
+		rxBytes:   delta(c.rxBytes, prev.rxBytes),
+		txBytes:   delta(c.txBytes, prev.txBytes),
+	}
+}
+
+// add adds d to the counters
+func (c *aggCounters) add(d aggCounters) {
+	c.rxPackets += d.rxPackets
+	c.txPackets += d.txPackets
+	c.rxBytes += d.rxBytes
+	c.txBytes += d.txBytes
+}
+
+// aggMember is a session counted in the aggregates
+type aggMember struct {
+	imsi string
+	dnn  string
+	last aggCounters // Counters last added to the groups
+	seen bool        // The counters were observed at least once
+}
+
+// aggGroup holds the rolled-up counters of one group and its latest rates
+type aggGroup struct {
+	sessions     int         // Sessions currently in the group
+	total        aggCounters // Counters gained by its sessions since the group was created
+	prev         aggCounters // Total at the start of the current rate window
+	rxSpeed      float64     // bit/s
+	txSpeed      float64     // bit/s
+	rxPacketRate float64     // packet/s
+	txPacketRate float64     // packet/s
+}
+
+// aggregator rolls the flow counters of the sessions in the rule store up per
+// IMSI, DNN, slice and for the whole UPF. Groups keep counting what their
+// sessions gained while they were members, so the totals do not drop when a
+// session leaves. All sessions of this agent belong to its configured slice.
+type aggregator struct {
+	rules   *rule.Store
+	slice   string // Key of the slice group, the configured slice ID
+	upf     bool   // Keep the UPF-wide group (measure_upf)
+	perFlow bool   // Keep a group per F-SEID (measure_flow)
+
+	mu        sync.Mutex
+	members   map[string]*aggMember
+	groups    map[string]map[string]*aggGroup // By grouping, then key
+	watch     []string                        // F-SEIDs of members, for the flow engine
+	refreshed time.Time
+	rated     time.Time // Start of the current rate window
+	measured  time.Time // When counters were last added
+}
+
+// newAggregator creates an aggregator for the sessions of a slice
+func newAggregator(rules *rule.Store, sliceID int, upf, perFlow bool) *aggregator {
+	a := &aggregator{
+		rules:   rules,
+		slice:   strconv.Itoa(sliceID),
+		upf:     upf,
+		perFlow: perFlow,
+		members: make(map[string]*aggMember),
+		groups:  make(map[string]map[string]*aggGroup),
+	}
+	for _, by := range []string{groupIMSI, groupDNN, groupSlice, groupUPF, groupFlow} {
+		a.groups[by] = make(map[string]*aggGroup)
+	}
+	// The slice and UPF groups exist even while no session does
+	a.groups[groupSlice][a.slice] = &aggGroup{}
+	if upf {
+		a.groups[groupUPF][groupUPF] = &aggGroup{}
+	}
+	return a
+}
+
+// groupsLocked returns the groups a session counts towards. Callers hold a.mu.
+func (a *aggregator) groupsLocked(fseid string, m *aggMember) []*aggGroup {
+	var out []*aggGroup
+	add := func(by, key string) {
+		g, ok := a.groups[by][key]
+		if !ok {
+			g = &aggGroup{}
+			a.groups[by][key] = g
+		}
+		out = append(out, g)
+	}
+	if m.imsi != "" {
+		add(groupIMSI, m.imsi)
+	}
+	if m.dnn != "" {
+		add(groupDNN, m.dnn)
+	}
+	add(groupSlice, a.slice)
+	if a.upf {
+		add(groupUPF, groupUPF)
+	}
+	if a.perFlow {
+		add(groupFlow, fseid)
+	}
+	return out
+}
+
+// watching returns the flows of the sessions counted in the aggregates
+func (a *aggregator) watching() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.watch
+}
+
+// observe adds what every member gained since the last tick to its groups,
+// and derives the rates of the groups once per window
+func (a *aggregator) observe(now time.Time, counters map[string]flowmeasuredata) {
+	if now.Sub(a.refreshed) >= aggregateRefresh {
+		a.refresh(now)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for fseid, d := range counters {
+		m, ok := a.members[fseid]
+		if !ok {
+			continue
+		}
+		// A session counts from its first observation on
+		cur := countersOf(d)
+		if !m.seen {
+			m.last, m.seen = cur, true
+			continue
+		}
+		delta := cur.since(m.last)
+		m.last = cur
+		for _, g := range a.groupsLocked(fseid, m) {
+			g.total.add(delta)
+		}
+	}
+	a.measured = now
+
+	if a.rated.IsZero() {
+		a.rated = now
+	}
+	elapsed := now.Sub(a.rated)
+	if elapsed < aggregateWindow {
+		return
+	}
+	secs := elapsed.Seconds()
+	for _, groups := range a.groups {
+		for _, g := range groups {
+			gained := g.total.since(g.prev)
+			g.rxSpeed = float64(gained.rxBytes) * 8 / secs
+			g.txSpeed = float64(gained.txBytes) * 8 / secs
+			g.rxPacketRate = float64(gained.rxPackets) / secs
+			g.txPacketRate = float64(gained.txPackets) / secs
+			g.prev = g.total
+		}
+	}
+	a.rated = now
+}
+
+// refresh follows the sessions of the rule store, moving sessions whose IMSI
+// or DNN changed and dropping the groups left without sessions
+func (a *aggregator) refresh(now time.Time) {
+	a.refreshed = now
+	current := make(map[string]aggMember)
+	a.rules.Range(func(s rule.Sessions) bool {
+		current[s.FSEID()] = aggMember{imsi: s.IMSI(), dnn: s.DNN()}
+		return true
+	})
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for fseid := range a.members {
+		if _, ok := current[fseid]; !ok {
+			delete(a.members, fseid)
+		}
+	}
+	for fseid, s := range current {
+		if m, ok := a.members[fseid]; ok {
+			m.imsi, m.dnn = s.imsi, s.dnn
+			continue
+		}
+		m := s
+		a.members[fseid] = &m
+	}
+
+	// Count the sessions of every group again
+	for _, groups := range a.groups {
+		for _, g := range groups {
+			g.sessions = 0
+		}
+	}
+	a.watch = make([]string, 0, len(a.members))
+	for fseid, m := range a.members {
+		for _, g := range a.groupsLocked(fseid, m) {
+			g.sessions++
+		}
+		a.watch = append(a.watch, fseid)
+	}
+	for _, by := range []string{groupIMSI, groupDNN, groupFlow} {
+		for key, g := range a.groups[by] {
+			if g.sessions == 0 {
+				delete(a.groups[by], key)
+			}
+		}
+	}
+}
+
+// snapshot returns the groups of a grouping, only those named in filter if it
+// is not empty
+func (a *aggregator) snapshot(by string, filter []string) (*pb.AggregateStatsReply, error) {
+	switch by {
+	case groupIMSI, groupDNN, groupSlice:
+	case groupUPF:
+		if !a.upf {
+			return nil, status.Error(codes.FailedPrecondition, "UPF-wide statistics are not kept, measure_upf is off")
+		}
+	case groupFlow:
+		if !a.perFlow {
+			return nil, status.Error(codes.FailedPrecondition, "per-flow statistics are not kept, measure_flow is off")
+		}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown group_by %q, want imsi, dnn, slice, upf or flow", by)
+	}
+	wanted := make(map[string]bool, len(filter))
+	for _, key := range filter {
+		wanted[key] = true
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	reply := &pb.AggregateStatsReply{
+		GroupBy:        by,
+		SpeedUnit:      speedUnit,
+		PacketRateUnit: packetRateUnit,
+	}
+	if !a.measured.IsZero() {
+		reply.MeasuredUnixNano = a.measured.UnixNano()
+	}
+	for key, g := range a.groups[by] {
+		if len(wanted) > 0 && !wanted[key] {
+			continue
+		}
+		reply.Groups = append(reply.Groups, &pb.AggregateGroup{
+			Key:          key,
+			Sessions:     uint32(g.sessions),
+			RxPackets:    g.total.rxPackets,
+			TxPackets:    g.total.txPackets,
+			RxBytes:      g.total.rxBytes,
+			TxBytes:      g.total.txBytes,
+			RxSpeed:      g.rxSpeed,
+			TxSpeed:      g.txSpeed,
+			RxPacketRate: g.rxPacketRate,
+			TxPacketRate: g.txPacketRate,
+		})
+	}
+	sort.Slice(reply.Groups, func(i, j int) bool { return reply.Groups[i].Key < reply.Groups[j].Key })
+	return reply, nil
+}
@@ -0,0 +1,184 @@
+package pfcp
+
+import (
+	"testing"
+	"time"
+
+	"upf/Server/rule"
+	pb "upf/pkg/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// aggGroupOf returns the group key of a grouping, nil when there is none
+func aggGroupOf(t *testing.T, a *aggregator, by, key string) *pb.AggregateGroup {
+	t.Helper()
+	reply, err := a.snapshot(by, []string{key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.GetGroups()) == 0 {
+		return nil
+	}
+	return reply.GetGroups()[0]
+}
+
+// rxBytes returns the counters of a flow that received n bytes in packets of 100
+func rxBytes(n uint64) flowmeasuredata {
+	return flowmeasuredata{Rx_Bytes: n, Rx_Packet: n / 100}
+}
+
+// TestAggregatorTotals checks that sessions count from their first
+// observation on, that the groups keep what a session gained after it left,
+// and that a session whose IMSI and DNN changed counts towards its new groups
+func TestAggregatorTotals(t *testing.T) {
+	rules := rule.NewStore(4)
+	putSession(t, rules, "0x1", "IMSI1", "internet")
+	putSession(t, rules, "0x2", "IMSI2", "internet")
+	a := newAggregator(rules, 1, true, true)
+	t0 := time.Unix(1_700_000_000, 0)
+
+	for _, step := range []struct {
+		name     string
+		at       time.Duration
+		change   func()
+		counters map[string]flowmeasuredata
+		groups   map[[2]string][2]uint64 // Sessions and received bytes by grouping and key, {0, 0} for a dropped group
+	}{
+		{
+			// What the sessions moved before they were observed is not counted
+			name:     "first observation",
+			counters: map[string]flowmeasuredata{"0x1": rxBytes(1000), "0x2": rxBytes(500)},
+			groups: map[[2]string][2]uint64{
+				{groupDNN, "internet"}: {2, 0},
+				{groupIMSI, "IMSI1"}:   {1, 0},
+				{groupSlice, "1"}:      {2, 0},
+			},
+		},
+		{
+			name:     "gained",
+			at:       100 * time.Millisecond,
+			counters: map[string]flowmeasuredata{"0x1": rxBytes(1500), "0x2": rxBytes(700)},
+			groups: map[[2]string][2]uint64{
+				{groupDNN, "internet"}: {2, 700},
+				{groupIMSI, "IMSI1"}:   {1, 500},
+				{groupIMSI, "IMSI2"}:   {1, 200},
+				{groupUPF, groupUPF}:   {2, 700},
+				{groupFlow, "0x2"}:     {1, 200},
+			},
+		},
+		{
+			// The groups of 0x2 alone go, the totals of the others stay
+			name:     "session left",
+			at:       time.Second,
+			change:   func() { rules.Delete("0x2", rule.Change{Caller: "test", Operation: "delete"}) },
+			counters: map[string]flowmeasuredata{"0x1": rxBytes(1600), "0x2": rxBytes(900)},
+			groups: map[[2]string][2]uint64{
+				{groupDNN, "internet"}: {1, 800},
+				{groupIMSI, "IMSI2"}:   {0, 0},
+				{groupFlow, "0x2"}:     {0, 0},
+				{groupSlice, "1"}:      {1, 800},
+			},
+		},
+		{
+			// 0x1 moves to IMSI3 and ims with what it gained from here on
+			name:     "regrouped",
+			at:       2 * time.Second,
+			change:   func() { putSession(t, rules, "0x1", "IMSI3", "ims") },
+			counters: map[string]flowmeasuredata{"0x1": rxBytes(1900)},
+			groups: map[[2]string][2]uint64{
+				{groupIMSI, "IMSI1"}:   {0, 0},
+				{groupDNN, "internet"}: {0, 0},
+				{groupIMSI, "IMSI3"}:   {1, 300},
+				{groupDNN, "ims"}:      {1, 300},
+				{groupFlow, "0x1"}:     {1, 900},
+				{groupSlice, "1"}:      {1, 1100},
+			},
+		},
+	} {
+		if step.change != nil {
+			step.change()
+		}
+		a.observe(t0.Add(step.at), step.counters)
+		for k, want := range step.groups {
+			g := aggGroupOf(t, a, k[0], k[1])
+			if want == [2]uint64{} {
+				if g != nil {
+					t.Errorf("%s: %s %s kept with %d sessions", step.name, k[0], k[1], g.GetSessions())
+				}
+				continue
+			}
+			if g == nil {
+				t.Errorf("%s: no %s %s", step.name, k[0], k[1])
+				continue
+			}
+			if uint64(g.GetSessions()) != want[0] || g.GetRxBytes() != want[1] || g.GetRxPackets() != want[1]/100 {
+				t.Errorf("%s: %s %s has %d sessions and %d bytes in %d packets, want %d and %d in %d", step.name, k[0], k[1],
+					g.GetSessions(), g.GetRxBytes(), g.GetRxPackets(), want[0], want[1], want[1]/100)
+			}
+		}
+	}
+	if w := a.watching(); len(w) != 1 || w[0] != "0x1" {
+		t.Errorf("watching %v, want [0x1]", w)
+	}
+}
+
+// TestAggregatorRates checks that the rates are derived over windows of at
+// least aggregateWindow and hold between them
+func TestAggregatorRates(t *testing.T) {
+	rules := rule.NewStore(4)
+	putSession(t, rules, "0x1", "IMSI1", "internet")
+	a := newAggregator(rules, 1, false, false)
+	t0 := time.Unix(1_700_000_000, 0)
+
+	for _, step := range []struct {
+		at      time.Duration
+		rxBytes uint64
+		speed   float64 // bit/s
+	}{
+		{0, 1000, 0},
+		{500 * time.Millisecond, 2000, 0}, // Within the first window
+		{time.Second, 3000, 2000 * 8},     // 2000 bytes over 1 s
+		{1500 * time.Millisecond, 9000, 2000 * 8},
+		{3 * time.Second, 11000, 4000 * 8}, // 8000 bytes over 2 s
+	} {
+		now := t0.Add(step.at)
+		a.observe(now, map[string]flowmeasuredata{"0x1": rxBytes(step.rxBytes)})
+		g := aggGroupOf(t, a, groupSlice, "1")
+		if g.GetRxSpeed() != step.speed || g.GetRxPacketRate() != step.speed/8/100 {
+			t.Errorf("at %v: %g bit/s and %g packet/s, want %g and %g", step.at, g.GetRxSpeed(), g.GetRxPacketRate(), step.speed, step.speed/8/100)
+		}
+		if reply, _ := a.snapshot(groupSlice, nil); reply.GetMeasuredUnixNano() != now.UnixNano() {
+			t.Errorf("at %v: measured at %d", step.at, reply.GetMeasuredUnixNano())
+		}
+	}
+}
+
+// TestAggregatorSnapshot checks the groupings a snapshot is refused for
+func TestAggregatorSnapshot(t *testing.T) {
+	rules := rule.NewStore(4)
+	off, on := newAggregator(rules, 1, false, false), newAggregator(rules, 1, true, true)
+	for _, tc := range []struct {
+		a    *aggregator
+		by   string
+		code codes.Code
+	}{
+		{off, groupUPF, codes.FailedPrecondition},
+		{off, groupFlow, codes.FailedPrecondition},
+		{off, "apn", codes.InvalidArgument},
+		{off, groupSlice, codes.OK},
+		{on, groupUPF, codes.OK},
+		{on, groupFlow, codes.OK},
+	} {
+		if _, err := tc.a.snapshot(tc.by, nil); status.Code(err) != tc.code {
+			t.Errorf("%s with measure_upf and measure_flow %v: %v, want %s", tc.by, tc.a.upf, err, tc.code)
+		}
+	}
+
+	// Without sessions the slice and UPF groups are there, unmeasured
+	reply, _ := on.snapshot(groupUPF, nil)
+	if len(reply.GetGroups()) != 1 || reply.GetGroups()[0].GetKey() != groupUPF || reply.GetMeasuredUnixNano() != 0 {
+		t.Errorf("UPF-wide snapshot without sessions: %v", reply)
+	}
+}
@@ -0,0 +1,530 @@
+package pfcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"upf/Server/rule"
+	pb "upf/pkg/proto"
+
+	"github.com/tidwall/jsonc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	alertEval       = time.Second     // How often the alert rules are evaluated
+	alertBacklog    = 256             // Events buffered per StreamAlerts stream and for the webhook
+	alertWebhookTTL = 5 * time.Second // Timeout of a webhook post
+	alertTargetUPF  = "upf"           // Target of alerts on UPF metrics
+)
+
+// States of an alert. A pending alert that is dropped before it fires ends
+// inactive: streams learn it is gone, the webhook never hears of it.
+const (
+	alertPending  = "pending"
+	alertFiring   = "firing"
+	alertResolved = "resolved"
+	alertInactive = "inactive"
+)
+
+// alertMetrics maps the metrics alert rules may watch to how a session's
+// value is read. UPF metrics are read from the rule store and map to nil.
+var alertMetrics = map[string]func(d flowmeasuredata) float64{
+	"rx_speed":       func(d flowmeasuredata) float64 { return float64(d.Rx_Speed) },
+	"tx_speed":       func(d flowmeasuredata) float64 { return float64(d.Tx_Speed) },
+	"rx_packet_rate": func(d flowmeasuredata) float64 { return d.Rx_Packet_Rate },
+	"tx_packet_rate": func(d flowmeasuredata) float64 { return d.Tx_Packet_Rate },
+	"session_count":  nil,
+	"session_usage":  nil,
+}
+
+// alertRuleConfig is an alert rule as written in the rules file
+type alertRuleConfig struct {
+	Name      string   `json:"name"`      // Unique name of the rule
+	Metric    string   `json:"metric"`    // Metric watched, one of alertMetrics
+	DNN       string   `json:"dnn"`       // Only sessions of this DNN
+	IMSI      string   `json:"imsi"`      // Only sessions of this IMSI
+	FSEID     string   `json:"fseid"`     // Only this session
+	Op        string   `json:"op"`        // ">" or "<"; empty means ">"
+	Threshold float64  `json:"threshold"` // Value the metric is compared against
+	Clear     *float64 `json:"clear"`     // Value a firing alert resolves past; defaults to threshold
+	For       string   `json:"for"`       // How long the threshold stays crossed before firing
+	Severity  string   `json:"severity"`  // Free-form severity, such as "warning"
+	Summary   string   `json:"summary"`   // Description carried by the alerts
+}
+
+// alertRule is a validated alert rule
+type alertRule struct {
+	alertRuleConfig
+	below   bool // Fires when the metric drops below the threshold
+	clear   float64
+	forDur  time.Duration
+	session func(d flowmeasuredata) float64 // Reads session metrics, nil for UPF metrics
+}
+
+// breached reports whether v crosses the threshold
+func (r *alertRule) breached(v float64) bool {
+	if r.below {
+		return v < r.Threshold
+	}
+	return v > r.Threshold
+}
+
+// cleared reports whether v is back past the clear level
+func (r *alertRule) cleared(v float64) bool {
+	if r.below {
+		return v >= r.clear
+	}
+	return v <= r.clear
+}
+
+// matches reports whether the rule watches a session
+func (r *alertRule) matches(fseid string, s *alertSession) bool {
+	return (r.FSEID == "" || r.FSEID == fseid) && (r.IMSI == "" || r.IMSI == s.imsi) && (r.DNN == "" || r.DNN == s.dnn)
+}
+
+// loadAlertRules reads and validates the rules file. Rules on session_usage
+// need max_sessions to be set.
+func loadAlertRules(path string, maxSessions int) ([]alertRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert rules: %w", err)
+	}
+	var file struct {
+		Rules []alertRuleConfig `json:"rules"`
+	}
+	if err := json.Unmarshal(jsonc.ToJSON(data), &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal alert rules: %w", err)
+	}
+
+	names := make(map[string]bool)
+	var rules []alertRule
+	for i, c := range file.Rules {
+		r := alertRule{alertRuleConfig: c, clear: c.Threshold}
+		where := fmt.Sprintf("alert rule %d (%s)", i, c.Name)
+		read, ok := alertMetrics[c.Metric]
+		switch {
+		case c.Name == "":
+			return nil, fmt.Errorf("alert rule %d has no name", i)
+		case names[c.Name]:
+			return nil, fmt.Errorf("%s: duplicate name", where)
+		case !ok:
+			return nil, fmt.Errorf("%s: unknown metric %q", where, c.Metric)
+		case read == nil && (c.DNN != "" || c.IMSI != "" || c.FSEID != ""):
+			return nil, fmt.Errorf("%s: %s is not a session metric and takes no filters", where, c.Metric)
+		case c.Metric == "session_usage" && maxSessions <= 0:
+			return nil, fmt.Errorf("%s: session_usage needs sim.max_sessions", where)
+		}
+		names[c.Name] = true
+		r.session = read
+
+		switch c.Op {
+		case "", ">":
+		case "<":
+			r.below = true
+		default:
+			return nil, fmt.Errorf("%s: op must be > or <", where)
+		}
+		if c.Clear != nil {
+			r.clear = *c.Clear
+			if r.below && r.clear < c.Threshold || !r.below && r.clear > c.Threshold {
+				return nil, fmt.Errorf("%s: clear must not be past the threshold", where)
+			}
+		}
+		if c.For != "" {
+			if r.forDur, err = time.ParseDuration(c.For); err != nil {
+				return nil, fmt.Errorf("%s: for: %w", where, err)
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// alertSession holds the rates of a session the alert rules watch
+type alertSession struct {
+	imsi   string
+	dnn    string
+	latest flowmeasuredata // Counters as of the last tick
+	seen   bool            // latest holds observed counters
+	rater  flowRater
+	rated  bool            // rates hold at least one interval
+	rates  flowmeasuredata // Rates over the last evaluation interval
+}
+
+// alertKey identifies an alert: a rule and the session or UPF it is about
+type alertKey struct {
+	rule   string
+	target string
+}
+
+// alertState is an alert that is pending or firing
+type alertState struct {
+	state   string
+	since   time.Time // When the threshold was crossed
+	changed time.Time // When the alert entered its state
+	value   float64
+	imsi    string
+	dnn     string
+}
+
+// alertSubscriber is a StreamAlerts stream. Empty fields match every alert.
+type alertSubscriber struct {
+	rule   string
+	fseid  string
+	events chan *pb.AlertEvent
+}
+
+// matches reports whether the stream selected an event
+func (sub *alertSubscriber) matches(ev *pb.AlertEvent) bool {
+	return (sub.rule == "" || sub.rule == ev.Rule) && (sub.fseid == "" || sub.fseid == ev.Target)
+}
+
+// alertEngine evaluates the alert rules against the session rates measured by
+// the flow engine and the sessions of the rule store. Alerts go from pending
+// to firing once their threshold stays crossed for the rule's duration, and
+// resolve once the metric is back past the rule's clear level.
+type alertEngine struct {
+	rules       []alertRule
+	store       *rule.Store
+	maxSessions int
+	webhook     *alertWebhook // nil without a webhook
+
+	mu        sync.Mutex
+	sessions  map[string]*alertSession
+	watch     []string // F-SEIDs of sessions some rule watches, for the flow engine
+	count     int      // Sessions in the rule store
+	active    map[alertKey]*alertState
+	subs      map[*alertSubscriber]struct{}
+	evaluated time.Time
+}
+
+// newAlertEngine creates an alert engine, posting to webhook unless it is empty
+func newAlertEngine(store *rule.Store, rules []alertRule, maxSessions int, webhook string) *alertEngine {
+	e := &alertEngine{
+		rules:       rules,
+		store:       store,
+		maxSessions: maxSessions,
+		sessions:    make(map[string]*alertSession),
+		active:      make(map[alertKey]*alertState),
+		subs:        make(map[*alertSubscriber]struct{}),
+	}
+	if webhook != "" {
+		e.webhook = newAlertWebhook(webhook)
+	}
+	return e
+}
+
+// watching returns the flows of the sessions some rule watches
+func (e *alertEngine) watching() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.watch
+}
+
+// observe keeps the latest counters and evaluates the rules every alertEval
+func (e *alertEngine) observe(now time.Time, counters map[string]flowmeasuredata) {
+	e.mu.Lock()
+	for fseid, d := range counters {
+		if s, ok := e.sessions[fseid]; ok {
+			s.latest, s.seen = d, true
+		}
+	}
+	due := now.Sub(e.evaluated) >= alertEval
+	e.mu.Unlock()
+
+	if due {
+		e.refresh()
+		e.publish(e.evaluate(now))
+	}
+}
+
+// refresh follows the sessions of the rule store
+func (e *alertEngine) refresh() {
+	type found struct{ imsi, dnn string }
+	current := make(map[string]found)
+	e.store.Range(func(s rule.Sessions) bool {
+		current[s.FSEID()] = found{imsi: s.IMSI(), dnn: s.DNN()}
+		return true
+	})
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.count = len(current)
+	for fseid := range e.sessions {
+		if _, ok := current[fseid]; !ok {
+			delete(e.sessions, fseid)
+		}
+	}
+	e.watch = make([]string, 0, len(e.sessions))
+	for fseid, f := range current {
+		s, ok := e.sessions[fseid]
+		if !ok {
+			s = &alertSession{}
+		}
+		s.imsi, s.dnn = f.imsi, f.dnn
+		watched := false
+		for i := range e.rules {
+			if e.rules[i].session != nil && e.rules[i].matches(fseid, s) {
+				watched = true
+				break
+			}
+		}
+		if !watched {
+			delete(e.sessions, fseid)
+			continue
+		}
+		e.sessions[fseid] = s
+		e.watch = append(e.watch, fseid)
+	}
+}
+
+// evaluate derives the rates of the watched sessions and steps every alert,
+// returning the events of the alerts that changed state
+func (e *alertEngine) evaluate(now time.Time) []*pb.AlertEvent {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.evaluated = now
+
+	for _, s := range e.sessions {
+		if !s.seen {
+			continue
+		}
+		d := s.latest
+		if !s.rated && s.rater.prev.Measured.IsZero() {
+			s.rater.reset(d, d.Measured)
+			continue
+		}
+		s.rater.measure(&d)
+		s.rates, s.rated = d, true
+	}
+
+	var events []*pb.AlertEvent
+	seen := make(map[alertKey]bool)
+	for i := range e.rules {
+		r := &e.rules[i]
+		if r.session == nil {
+			v := float64(e.count)
+			if r.Metric == "session_usage" {
+				v /= float64(e.maxSessions)
+			}
+			key := alertKey{rule: r.Name, target: alertTargetUPF}
+			seen[key] = true
+			events = append(events, e.stepLocked(r, key, v, "", "", now)...)
+			continue
+		}
+		for fseid, s := range e.sessions {
+			if !s.rated || !r.matches(fseid, s) {
+				continue
+			}
+			key := alertKey{rule: r.Name, target: fseid}
+			seen[key] = true
+			events = append(events, e.stepLocked(r, key, r.session(s.rates), s.imsi, s.dnn, now)...)
+		}
+	}
+
+	// Alerts of sessions that are gone, or no longer match, end
+	for key, st := range e.active {
+		if !seen[key] {
+			events = append(events, e.endLocked(key, st, now))
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Rule != events[j].Rule {
+			return events[i].Rule < events[j].Rule
+		}
+		return events[i].Target < events[j].Target
+	})
+	return events
+}
+
+// stepLocked moves an alert on with the latest value of its metric. Callers hold e.mu.
+func (e *alertEngine) stepLocked(r *alertRule, key alertKey, v float64, imsi, dnn string, now time.Time) []*pb.AlertEvent {
+	var events []*pb.AlertEvent
+	st, ok := e.active[key]
+	if !ok {
+		if !r.breached(v) {
+			return nil
+		}
+		st = &alertState{state: alertPending, since: now, changed: now, value: v, imsi: imsi, dnn: dnn}
+		e.active[key] = st
+		events = append(events, e.eventLocked(key, st))
+	}
+	st.value, st.imsi, st.dnn = v, imsi, dnn
+
+	switch st.state {
+	case alertPending:
+		// A pending alert is dropped as soon as the threshold is no longer crossed
+		if !r.breached(v) {
+			return append(events, e.endLocked(key, st, now))
+		}
+		if now.Sub(st.since) >= r.forDur {
+			st.state, st.changed = alertFiring, now
+			events = append(events, e.eventLocked(key, st))
+		}
+	case alertFiring:
+		if r.cleared(v) {
+			events = append(events, e.endLocked(key, st, now))
+		}
+	}
+	return events
+}
+
+// endLocked removes an alert: a firing alert resolves, a pending one goes
+// inactive. Callers hold e.mu.
+func (e *alertEngine) endLocked(key alertKey, st *alertState, now time.Time) *pb.AlertEvent {
+	delete(e.active, key)
+	if st.state == alertFiring {
+		st.state = alertResolved
+	} else {
+		st.state = alertInactive
+	}
+	st.changed = now
+	return e.eventLocked(key, st)
+}
+
+// eventLocked describes an alert in its current state. Callers hold e.mu.
+func (e *alertEngine) eventLocked(key alertKey, st *alertState) *pb.AlertEvent {
+	var r *alertRule
+	for i := range e.rules {
+		if e.rules[i].Name == key.rule {
+			r = &e.rules[i]
+			break
+		}
+	}
+	summary := r.Summary
+	if summary == "" {
+		op := ">"
+		if r.below {
+			op = "<"
+		}
+		summary = fmt.Sprintf("%s of %s %s %g", r.Metric, key.target, op, r.Threshold)
+	}
+	return &pb.AlertEvent{
+		Rule:            r.Name,
+		Severity:        r.Severity,
+		State:           st.state,
+		Target:          key.target,
+		Imsi:            st.imsi,
+		Dnn:             st.dnn,
+		Metric:          r.Metric,
+		Value:           st.value,
+		Threshold:       r.Threshold,
+		Summary:         summary,
+		StartedUnixNano: st.since.UnixNano(),
+		AtUnixNano:      st.changed.UnixNano(),
+	}
+}
+
+// subscribe starts a StreamAlerts stream. The alerts already pending or
+// firing are queued first.
+func (e *alertEngine) subscribe(ruleName, fseid string) *alertSubscriber {
+	sub := &alertSubscriber{rule: ruleName, fseid: fseid, events: make(chan *pb.AlertEvent, alertBacklog)}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var current []*pb.AlertEvent
+	for key, st := range e.active {
+		if ev := e.eventLocked(key, st); sub.matches(ev) {
+			current = append(current, ev)
+		}
+	}
+	sort.Slice(current, func(i, j int) bool { return current[i].StartedUnixNano < current[j].StartedUnixNano })
+	for _, ev := range current[:min(len(current), alertBacklog)] {
+		sub.events <- ev
+	}
+	e.subs[sub] = struct{}{}
+	return sub
+}
+
+// unsubscribe ends a StreamAlerts stream
+func (e *alertEngine) unsubscribe(sub *alertSubscriber) {
+	e.mu.Lock()
+	delete(e.subs, sub)
+	e.mu.Unlock()
+}
+
+// publish hands events to the streams that selected them, dropping them for
+// streams too slow to keep up, and firing and resolved alerts to the webhook
+func (e *alertEngine) publish(events []*pb.AlertEvent) {
+	for _, ev := range events {
+		log.Printf("🚨 Alert %s of %s is %s: %s (%g)", ev.Rule, ev.Target, ev.State, ev.Summary, ev.Value)
+	}
+	e.mu.Lock()
+	for _, ev := range events {
+		for sub := range e.subs {
+			if !sub.matches(ev) {
+				continue
+			}
+			select {
+			case sub.events <- ev:
+			default:
+				log.Printf("⚠️ Alert %s of %s dropped for a slow stream", ev.Rule, ev.Target)
+			}
+		}
+	}
+	e.mu.Unlock()
+
+	if e.webhook == nil {
+		return
+	}
+	for _, ev := range events {
+		if ev.State == alertFiring || ev.State == alertResolved {
+			e.webhook.post(ev)
+		}
+	}
+}
+
+// alertWebhook posts alerts as JSON to an HTTP endpoint, one request per
+// alert, from a queue so a slow endpoint does not hold up evaluation
+type alertWebhook struct {
+	url    string
+	client *http.Client
+	queue  chan *pb.AlertEvent
+}
+
+// newAlertWebhook starts posting to url
+func newAlertWebhook(url string) *alertWebhook {
+	w := &alertWebhook{
+		url:    url,
+		client: &http.Client{Timeout: alertWebhookTTL},
+		queue:  make(chan *pb.AlertEvent, alertBacklog),
+	}
+	go w.run()
+	return w
+}
+
+// post queues an alert, dropping it if the queue is full
+func (w *alertWebhook) post(ev *pb.AlertEvent) {
+	select {
+	case w.queue <- ev:
+	default:
+		log.Printf("⚠️ Alert %s of %s not posted, the webhook queue is full", ev.Rule, ev.Target)
+	}
+}
+
+// run posts the queued alerts
+func (w *alertWebhook) run() {
+	for ev := range w.queue {
+		body, err := protojson.Marshal(ev)
+		if err != nil {
+			log.Printf("❌ Failed to encode alert %s: %v", ev.Rule, err)
+			continue
+		}
+		resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Printf("❌ Failed to post alert %s to %s: %v", ev.Rule, w.url, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			log.Printf("❌ Webhook %s rejected alert %s: %s", w.url, ev.Rule, resp.Status)
+		}
+	}
+}
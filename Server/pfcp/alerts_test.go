@@ -0,0 +1,275 @@
+package pfcp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"upf/Server/rule"
+	pb "upf/pkg/proto"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// TestAlertPendingNeverPosted checks that a pending alert dropped before it
+// fires goes inactive and is not posted, while a firing one resolves
+func TestAlertPendingNeverPosted(t *testing.T) {
+	posted := make(chan string, 8)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var ev pb.AlertEvent
+		if err := protojson.Unmarshal(body, &ev); err != nil {
+			t.Errorf("webhook body %q: %v", body, err)
+		}
+		if ev.Rule != "many" || ev.Target != alertTargetUPF || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("webhook posted %v as %s", &ev, r.Header.Get("Content-Type"))
+		}
+		posted <- ev.State
+	}))
+	defer hook.Close()
+
+	rules := rule.NewStore(4)
+	putSession(t, rules, "first", "001010000000001", "internet")
+	many := alertRule{
+		alertRuleConfig: alertRuleConfig{Name: "many", Metric: "session_count", Threshold: 1, For: "2s"},
+		clear:           1,
+		forDur:          2 * time.Second,
+	}
+	e := newAlertEngine(rules, []alertRule{many}, 0, hook.URL)
+	sub := e.subscribe("", "")
+	defer e.unsubscribe(sub)
+
+	start := time.Unix(1_700_000_000, 0)
+	step := func(s int) {
+		e.refresh()
+		e.publish(e.evaluate(start.Add(time.Duration(s) * time.Second)))
+	}
+	remove := func() {
+		if _, ok := rules.Delete("second", rule.Change{Caller: "test"}); !ok {
+			t.Fatal("second session not found")
+		}
+	}
+
+	putSession(t, rules, "second", "001010000000002", "internet")
+	step(0) // pending
+	remove()
+	step(1) // inactive, never fired
+	putSession(t, rules, "second", "001010000000002", "internet")
+	step(2) // pending
+	step(4) // firing
+	remove()
+	step(5) // resolved
+
+	var streamed []string
+	for len(sub.events) > 0 {
+		streamed = append(streamed, (<-sub.events).State)
+	}
+	want := []string{alertPending, alertInactive, alertPending, alertFiring, alertResolved}
+	if len(streamed) != len(want) {
+		t.Fatalf("streamed %v, want %v", streamed, want)
+	}
+	for i := range want {
+		if streamed[i] != want[i] {
+			t.Fatalf("streamed %v, want %v", streamed, want)
+		}
+	}
+
+	for _, state := range []string{alertFiring, alertResolved} {
+		select {
+		case got := <-posted:
+			if got != state {
+				t.Errorf("posted %s, want %s", got, state)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s alert not posted", state)
+		}
+	}
+	select {
+	case got := <-posted:
+		t.Errorf("posted an extra %s alert", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// writeAlertRules writes a rules file holding the given rules
+func writeAlertRules(t *testing.T, rules ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "alerts.jsonc")
+	body := "{\n\t// Written by the test\n\t\"rules\": [" + strings.Join(rules, ",") + "]\n}"
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadAlertRules(t *testing.T) {
+	rules, err := loadAlertRules(writeAlertRules(t,
+		`{"name": "slow", "metric": "rx_speed", "dnn": "internet", "op": "<", "threshold": 1000, "clear": 2000, "for": "30s"}`,
+		`{"name": "busy", "metric": "session_usage", "threshold": 0.9}`,
+	), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("loaded %d rules, want 2", len(rules))
+	}
+	if slow := rules[0]; !slow.below || slow.clear != 2000 || slow.forDur != 30*time.Second || slow.session == nil {
+		t.Errorf("slow: below %v, clear %g, for %v, session metric %v", slow.below, slow.clear, slow.forDur, slow.session != nil)
+	}
+	if busy := rules[1]; busy.below || busy.clear != 0.9 || busy.forDur != 0 || busy.session != nil {
+		t.Errorf("busy: below %v, clear %g, for %v, session metric %v", busy.below, busy.clear, busy.forDur, busy.session != nil)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		rules       []string
+		maxSessions int
+	}{
+		{"no name", []string{`{"metric": "rx_speed"}`}, 0},
+		{"duplicate name", []string{`{"name": "a", "metric": "rx_speed"}`, `{"name": "a", "metric": "tx_speed"}`}, 0},
+		{"unknown metric", []string{`{"name": "a", "metric": "rx_bytes"}`}, 0},
+		{"filter on a UPF metric", []string{`{"name": "a", "metric": "session_count", "imsi": "001010000000001"}`}, 0},
+		{"session_usage without max_sessions", []string{`{"name": "a", "metric": "session_usage", "threshold": 0.9}`}, 0},
+		{"unknown op", []string{`{"name": "a", "metric": "rx_speed", "op": ">="}`}, 0},
+		{"clear above a > threshold", []string{`{"name": "a", "metric": "rx_speed", "threshold": 10, "clear": 20}`}, 0},
+		{"clear below a < threshold", []string{`{"name": "a", "metric": "rx_speed", "op": "<", "threshold": 10, "clear": 5}`}, 0},
+		{"bad for", []string{`{"name": "a", "metric": "rx_speed", "for": "soon"}`}, 0},
+		{"not JSON", []string{`{"name": }`}, 0},
+	} {
+		if rules, err := loadAlertRules(writeAlertRules(t, tc.rules...), tc.maxSessions); err == nil {
+			t.Errorf("%s: loaded %d rules, want an error", tc.name, len(rules))
+		}
+	}
+	if _, err := loadAlertRules(filepath.Join(t.TempDir(), "missing.jsonc"), 0); err == nil {
+		t.Error("loaded a missing file")
+	}
+}
+
+// drainAlerts returns the states of the events queued for a stream
+func drainAlerts(sub *alertSubscriber) []string {
+	var states []string
+	for len(sub.events) > 0 {
+		states = append(states, (<-sub.events).State)
+	}
+	return states
+}
+
+// TestAlertSessionRate steps a rule on the packet rate of a session through
+// its states, one evaluation a second
+func TestAlertSessionRate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		rule   string
+		rates  []uint64   // Packets a second, one evaluation each
+		events [][]string // States the alert went through at each evaluation
+	}{
+		{
+			name:   "fires after for",
+			rule:   `{"name": "r", "metric": "rx_packet_rate", "threshold": 100, "for": "2s"}`,
+			rates:  []uint64{200, 200, 200, 50, 50},
+			events: [][]string{{alertPending}, nil, {alertFiring}, {alertResolved}, nil},
+		},
+		{
+			name:   "dropped while pending",
+			rule:   `{"name": "r", "metric": "rx_packet_rate", "threshold": 100, "for": "5s"}`,
+			rates:  []uint64{200, 200, 100},
+			events: [][]string{{alertPending}, nil, {alertInactive}},
+		},
+		{
+			// Without a for the alert fires at once, and only resolves at the clear level
+			name:   "hysteresis",
+			rule:   `{"name": "r", "metric": "rx_packet_rate", "threshold": 100, "clear": 50}`,
+			rates:  []uint64{200, 80, 51, 50, 200},
+			events: [][]string{{alertPending, alertFiring}, nil, nil, {alertResolved}, {alertPending, alertFiring}},
+		},
+		{
+			name:   "below",
+			rule:   `{"name": "r", "metric": "rx_packet_rate", "op": "<", "threshold": 50, "clear": 80}`,
+			rates:  []uint64{100, 50, 40, 79, 80},
+			events: [][]string{nil, nil, {alertPending, alertFiring}, nil, {alertResolved}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			alertRules, err := loadAlertRules(writeAlertRules(t, tc.rule), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			store := rule.NewStore(4)
+			putSession(t, store, "0x1", "001010000000001", "internet")
+			e := newAlertEngine(store, alertRules, 0, "")
+			sub := e.subscribe("", "")
+			defer e.unsubscribe(sub)
+
+			// The first evaluation finds the session, the second starts
+			// its rates, each later one rates the second before it
+			start := time.Unix(1_700_000_000, 0)
+			var d flowmeasuredata
+			observe := func(s int) {
+				d.Measured = start.Add(time.Duration(s) * time.Second)
+				e.observe(d.Measured, map[string]flowmeasuredata{"0x1": d})
+			}
+			observe(0)
+			observe(1)
+			for i, rate := range tc.rates {
+				d.Rx_Packet += rate
+				observe(2 + i)
+				got := drainAlerts(sub)
+				if strings.Join(got, ",") != strings.Join(tc.events[i], ",") {
+					t.Errorf("at %d packet/s: %v, want %v", rate, got, tc.events[i])
+				}
+			}
+		})
+	}
+}
+
+// TestAlertSessionUsage checks that session_usage is the share of
+// max_sessions in the rule store, alerting on the UPF
+func TestAlertSessionUsage(t *testing.T) {
+	alertRules, err := loadAlertRules(writeAlertRules(t,
+		`{"name": "usage", "metric": "session_usage", "threshold": 0.5, "clear": 0.25}`), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := rule.NewStore(4)
+	e := newAlertEngine(store, alertRules, 4, "")
+	sub := e.subscribe("", alertTargetUPF)
+	defer e.unsubscribe(sub)
+
+	start := time.Unix(1_700_000_000, 0)
+	for s, tc := range []struct {
+		sessions int
+		value    float64
+		state    string // State the alert entered, empty for none
+	}{
+		{1, 0.25, ""},
+		{3, 0.75, alertFiring},
+		{2, 0.5, ""},
+		{1, 0.25, alertResolved},
+	} {
+		for store.Len() < tc.sessions {
+			putSession(t, store, fmt.Sprintf("0x%x", store.Len()+1), "", "internet")
+		}
+		for store.Len() > tc.sessions {
+			store.Delete(fmt.Sprintf("0x%x", store.Len()), rule.Change{Caller: "test"})
+		}
+		e.refresh()
+		e.publish(e.evaluate(start.Add(time.Duration(s) * time.Second)))
+
+		var last *pb.AlertEvent
+		for len(sub.events) > 0 {
+			last = <-sub.events
+		}
+		switch {
+		case tc.state == "" && last != nil:
+			t.Errorf("%d sessions: alert went %s", tc.sessions, last.State)
+		case tc.state != "" && (last == nil || last.State != tc.state || last.Target != alertTargetUPF || last.Value != tc.value):
+			t.Errorf("%d sessions: got %v, want %s of the UPF at %g", tc.sessions, last, tc.state, tc.value)
+		}
+	}
+}
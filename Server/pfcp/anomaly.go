@@ -0,0 +1,371 @@
+package pfcp
+
+import (
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"upf/Server/rule"
+	pb "upf/pkg/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Anomaly detection parameters. Steps are counted in observations of a
+// session rather than wall-clock time, so the same counters always yield the
+// same anomalies, as they do under the seeded simulator.
+const (
+	anomalyStepTicks  = 10          // Observations per step, one second at flowTick
+	anomalyAlpha      = 0.1         // Weight of the newest step in the baselines
+	anomalyWarmup     = 30          // Steps a baseline learns before anomalies are flagged
+	anomalyScore      = 4.0         // Deviation in standard deviations that is anomalous
+	anomalySpikeRatio = 3.0         // A spike is also at least this multiple of the baseline
+	anomalyStallRatio = 0.1         // A stall is also at most this fraction of the baseline
+	anomalyMinRate    = 1000.0      // Baseline in bytes/s below which a direction counts as idle
+	anomalySkew       = 4.0         // An asymmetry also shifts the UL/DL ratio by this factor
+	anomalyRelearn    = 60          // Steps an anomaly lasts before it becomes the new baseline
+	anomalyRefresh    = time.Second // How often the watched sessions follow the rule store
+	anomalyBacklog    = 256         // Events buffered per StreamAnomalies stream
+)
+
+// Kinds, directions and states of anomalies
+const (
+	anomalySpike     = "spike"
+	anomalyStall     = "stall"
+	anomalyAsymmetry = "asymmetry"
+	anomalyStarted   = "started"
+	anomalyEnded     = "ended"
+	dirUplink        = "ul"
+	dirDownlink      = "dl"
+	skewUnit         = "ln(ul/dl)" // Unit of asymmetries, the natural log of the UL/DL ratio
+)
+
+// ewmaStat is an exponentially weighted mean and variance
+type ewmaStat struct {
+	mean     float64
+	variance float64
+	n        int // Samples learned
+}
+
+// update learns a sample
+func (s *ewmaStat) update(x float64) {
+	if s.n == 0 {
+		s.mean, s.variance = x, 0
+	} else {
+		diff := x - s.mean
+		incr := anomalyAlpha * diff
+		s.mean += incr
+		s.variance = (1 - anomalyAlpha) * (s.variance + diff*incr)
+	}
+	s.n++
+}
+
+// stddev returns the standard deviation, at least floor and a tenth of the
+// mean so a very steady baseline does not turn every wobble into an anomaly
+func (s *ewmaStat) stddev(floor float64) float64 {
+	return max(math.Sqrt(s.variance), floor, math.Abs(s.mean)/10)
+}
+
+// score returns how many standard deviations x lies from the mean
+func (s *ewmaStat) score(x, floor float64) float64 {
+	return (x - s.mean) / s.stddev(floor)
+}
+
+// anomalyKey names an anomaly of a session
+type anomalyKey struct {
+	kind      string
+	direction string
+}
+
+// openAnomaly is an anomaly that has started and not ended
+type openAnomaly struct {
+	started *pb.AnomalyEvent // Event of its start
+	peak    float64          // Highest score so far
+	steps   int              // Steps it has lasted
+}
+
+// end returns the event of the anomaly ending, scored by its peak
+func (o *openAnomaly) end(now time.Time) *pb.AnomalyEvent {
+	ended := proto.Clone(o.started).(*pb.AnomalyEvent)
+	ended.State, ended.Score, ended.AtUnixNano = anomalyEnded, o.peak, now.UnixNano()
+	return ended
+}
+
+// anomalySession holds the baselines of one session
+type anomalySession struct {
+	imsi   string
+	dnn    string
+	ticks  int         // Observations within the current step
+	from   aggCounters // Counters at the start of the current step
+	seen   bool        // from holds observed counters
+	ul, dl ewmaStat    // Bytes per second of each direction
+	skew   ewmaStat    // Log of the UL/DL ratio
+	open   map[anomalyKey]*openAnomaly
+}
+
+// anomalySubscriber is a StreamAnomalies stream
+type anomalySubscriber struct {
+	fseid    string
+	imsi     string
+	minScore float64
+	events   chan *pb.AnomalyEvent
+}
+
+// matches reports whether the stream selected an event
+func (sub *anomalySubscriber) matches(ev *pb.AnomalyEvent) bool {
+	return (sub.fseid == "" || sub.fseid == ev.Fseid) && (sub.imsi == "" || sub.imsi == ev.Imsi) && ev.Score >= sub.minScore
+}
+
+// anomalyDetector keeps an EWMA baseline of the traffic of every session, per
+// direction and of the balance between them, and flags steps that deviate
+// from it: spikes, stalls, and asymmetry between uplink and downlink. Steps
+// that are anomalous are kept out of the baselines until an anomaly lasts
+// anomalyRelearn steps, after which the session learns its new level afresh.
+type anomalyDetector struct {
+	rules *rule.Store
+
+	mu        sync.Mutex
+	sessions  map[string]*anomalySession
+	watch     []string // F-SEIDs of sessions, for the flow engine
+	refreshed time.Time
+	subs      map[*anomalySubscriber]struct{}
+}
+
+// newAnomalyDetector creates a detector for the sessions of the rule store
+func newAnomalyDetector(rules *rule.Store) *anomalyDetector {
+	return &anomalyDetector{
+		rules:    rules,
+		sessions: make(map[string]*anomalySession),
+		subs:     make(map[*anomalySubscriber]struct{}),
+	}
+}
+
+// watching returns the flows of the sessions under watch
+func (a *anomalyDetector) watching() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.watch
+}
+
+// observe counts an observation of every session and evaluates the sessions
+// that completed a step
+func (a *anomalyDetector) observe(now time.Time, counters map[string]flowmeasuredata) {
+	var events []*pb.AnomalyEvent
+	if now.Sub(a.refreshed) >= anomalyRefresh {
+		events = a.refresh(now)
+	}
+
+	a.mu.Lock()
+	for fseid, d := range counters {
+		s, ok := a.sessions[fseid]
+		if !ok {
+			continue
+		}
+		cur := countersOf(d)
+		if !s.seen {
+			s.from, s.seen = cur, true
+			continue
+		}
+		if s.ticks++; s.ticks < anomalyStepTicks {
+			continue
+		}
+		step := cur.since(s.from)
+		s.from, s.ticks = cur, 0
+		secs := (anomalyStepTicks * flowTick).Seconds()
+		events = append(events, s.evaluate(fseid, float64(step.rxBytes)/secs, float64(step.txBytes)/secs, now)...)
+	}
+	a.mu.Unlock()
+
+	sort.Slice(events, func(i, j int) bool {
+		x, y := events[i], events[j]
+		if x.Fseid != y.Fseid {
+			return x.Fseid < y.Fseid
+		}
+		if x.Kind != y.Kind {
+			return x.Kind < y.Kind
+		}
+		return x.Direction < y.Direction
+	})
+	a.publish(events)
+}
+
+// refresh follows the sessions of the rule store, returning the end of the
+// anomalies still open on sessions that are gone
+func (a *anomalyDetector) refresh(now time.Time) []*pb.AnomalyEvent {
+	a.refreshed = now
+	type found struct{ imsi, dnn string }
+	current := make(map[string]found)
+	a.rules.Range(func(s rule.Sessions) bool {
+		current[s.FSEID()] = found{imsi: s.IMSI(), dnn: s.DNN()}
+		return true
+	})
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var events []*pb.AnomalyEvent
+	for fseid, s := range a.sessions {
+		if _, ok := current[fseid]; !ok {
+			for key, o := range s.open {
+				delete(s.open, key)
+				events = append(events, o.end(now))
+			}
+			delete(a.sessions, fseid)
+		}
+	}
+	a.watch = make([]string, 0, len(current))
+	for fseid, f := range current {
+		s, ok := a.sessions[fseid]
+		if !ok {
+			s = &anomalySession{open: make(map[anomalyKey]*openAnomaly)}
+			a.sessions[fseid] = s
+		}
+		s.imsi, s.dnn = f.imsi, f.dnn
+		a.watch = append(a.watch, fseid)
+	}
+	return events
+}
+
+// evaluate checks one step of ul and dl bytes per second against the
+// baselines and learns it if it is normal. It returns the anomalies that
+// started or ended.
+func (s *anomalySession) evaluate(fseid string, ul, dl float64, now time.Time) []*pb.AnomalyEvent {
+	found := make(map[anomalyKey]*pb.AnomalyEvent)
+	flag := func(key anomalyKey, score, value float64, base *ewmaStat, floor float64, unit string, scale float64) {
+		found[key] = &pb.AnomalyEvent{
+			Fseid:          fseid,
+			Imsi:           s.imsi,
+			Dnn:            s.dnn,
+			Kind:           key.kind,
+			Direction:      key.direction,
+			State:          anomalyStarted,
+			Score:          math.Abs(score),
+			Value:          value * scale,
+			BaselineMean:   base.mean * scale,
+			BaselineStddev: base.stddev(floor) * scale,
+			Unit:           unit,
+		}
+	}
+
+	// Spikes and stalls of each direction
+	for _, d := range []struct {
+		dir   string
+		value float64
+		base  *ewmaStat
+	}{{dirUplink, ul, &s.ul}, {dirDownlink, dl, &s.dl}} {
+		if d.base.n < anomalyWarmup {
+			continue
+		}
+		z := d.base.score(d.value, anomalyMinRate/10)
+		switch {
+		case z >= anomalyScore && d.value >= anomalySpikeRatio*d.base.mean:
+			flag(anomalyKey{anomalySpike, d.dir}, z, d.value, d.base, anomalyMinRate/10, speedUnit, 8)
+		case z <= -anomalyScore && d.base.mean >= anomalyMinRate && d.value <= anomalyStallRatio*d.base.mean:
+			flag(anomalyKey{anomalyStall, d.dir}, z, d.value, d.base, anomalyMinRate/10, speedUnit, 8)
+		}
+	}
+
+	// A shift in the balance of the directions that neither explains
+	skew := math.Log((ul + anomalyMinRate) / (dl + anomalyMinRate))
+	if len(found) == 0 && s.skew.n >= anomalyWarmup {
+		z := s.skew.score(skew, 0.1)
+		if math.Abs(z) >= anomalyScore && math.Abs(skew-s.skew.mean) >= math.Log(anomalySkew) {
+			flag(anomalyKey{kind: anomalyAsymmetry}, z, skew, &s.skew, 0.1, skewUnit, 1)
+		}
+	}
+
+	// Open the anomalies that are new and close those that are over
+	var events []*pb.AnomalyEvent
+	for key, ev := range found {
+		o, ok := s.open[key]
+		if !ok {
+			ev.StartedUnixNano, ev.AtUnixNano = now.UnixNano(), now.UnixNano()
+			s.open[key] = &openAnomaly{started: ev, peak: ev.Score, steps: 1}
+			events = append(events, ev)
+			continue
+		}
+		o.peak, o.steps = max(o.peak, ev.Score), o.steps+1
+	}
+	// An anomaly that outlasted anomalyRelearn is the new normal: every
+	// anomaly ends and the baselines are learned afresh
+	relearn := false
+	for _, o := range s.open {
+		relearn = relearn || o.steps >= anomalyRelearn
+	}
+	for key, o := range s.open {
+		if _, ongoing := found[key]; ongoing && !relearn {
+			continue
+		}
+		delete(s.open, key)
+		events = append(events, o.end(now))
+	}
+
+	// Learn normal steps only
+	switch {
+	case relearn:
+		s.ul, s.dl, s.skew = ewmaStat{}, ewmaStat{}, ewmaStat{}
+	case len(found) == 0:
+		s.ul.update(ul)
+		s.dl.update(dl)
+		s.skew.update(skew)
+	}
+	return events
+}
+
+// subscribe starts a StreamAnomalies stream. The anomalies still open are
+// queued first.
+func (a *anomalyDetector) subscribe(fseid, imsi string, minScore float64) *anomalySubscriber {
+	sub := &anomalySubscriber{fseid: fseid, imsi: imsi, minScore: minScore, events: make(chan *pb.AnomalyEvent, anomalyBacklog)}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var current []*pb.AnomalyEvent
+	for _, s := range a.sessions {
+		for _, o := range s.open {
+			if sub.matches(o.started) {
+				current = append(current, o.started)
+			}
+		}
+	}
+	sort.Slice(current, func(i, j int) bool { return current[i].StartedUnixNano < current[j].StartedUnixNano })
+	for _, ev := range current[:min(len(current), anomalyBacklog)] {
+		sub.events <- ev
+	}
+	a.subs[sub] = struct{}{}
+	return sub
+}
+
+// unsubscribe ends a StreamAnomalies stream
+func (a *anomalyDetector) unsubscribe(sub *anomalySubscriber) {
+	a.mu.Lock()
+	delete(a.subs, sub)
+	a.mu.Unlock()
+}
+
+// publish hands events to the streams that selected them, dropping them for
+// streams too slow to keep up
+func (a *anomalyDetector) publish(events []*pb.AnomalyEvent) {
+	if len(events) == 0 {
+		return
+	}
+	for _, ev := range events {
+		if ev.State == anomalyStarted {
+			log.Printf("🔎 %s %s %s on %s: %.4g %s against %.4g ± %.4g (score %.1f)", ev.Kind, ev.Direction, ev.State,
+				ev.Fseid, ev.Value, ev.Unit, ev.BaselineMean, ev.BaselineStddev, ev.Score)
+		}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, ev := range events {
+		for sub := range a.subs {
+			if !sub.matches(ev) {
+				continue
+			}
+			select {
+			case sub.events <- ev:
+			default:
+				log.Printf("⚠️ Anomaly of %s dropped for a slow stream", ev.Fseid)
+			}
+		}
+	}
+}
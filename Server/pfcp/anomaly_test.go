@@ -0,0 +1,152 @@
+package pfcp
+
+import (
+	"testing"
+	"time"
+
+	"upf/Server/config"
+	"upf/Server/rule"
+	pb "upf/pkg/proto"
+)
+
+// anomalyRate is one step of a session's traffic in bytes per second
+type anomalyRate struct {
+	ul, dl float64
+}
+
+// simRates runs the seeded simulator with one session and returns n steps
+// of its traffic, as the detector sees them
+func simRates(t *testing.T, n int) []anomalyRate {
+	t.Helper()
+	sim, err := newSimulator(config.SimConfig{
+		Core:        "n3",
+		MaxSessions: 1,
+		StartUEIP:   "10.60.0.1",
+		StartENBIP:  "192.168.10.1",
+		StartN3TEID: "0x100",
+		StartN9TEID: "0x200",
+		UplinkGBR:   800,
+		UplinkMBR:   1200,
+		DownlinkGBR: 4000,
+		DownlinkMBR: 6000,
+		PktSize:     500,
+		TotalFlows:  2,
+		Seed:        42,
+	}, nil, "internet", flowTick)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secs := (anomalyStepTicks * flowTick).Seconds()
+	s := &sim.sessions[0]
+	rates := make([]anomalyRate, n)
+	for i := range rates {
+		ul, dl := s.ul.bytes, s.dl.bytes
+		for range anomalyStepTicks {
+			sim.tick()
+		}
+		rates[i] = anomalyRate{ul: float64(s.ul.bytes-ul) / secs, dl: float64(s.dl.bytes-dl) / secs}
+	}
+	return rates
+}
+
+// TestAnomalyEvaluate drives the baselines with simulated traffic, distorts a
+// few steps and checks that exactly one anomaly starts and then ends
+func TestAnomalyEvaluate(t *testing.T) {
+	const (
+		normal    = anomalyWarmup + 10 // Steps learned before the distortion
+		distorted = 5
+	)
+	for _, tc := range []struct {
+		kind, direction string
+		distort         func(anomalyRate) anomalyRate
+	}{
+		{anomalySpike, dirUplink, func(r anomalyRate) anomalyRate { return anomalyRate{ul: r.ul * 10, dl: r.dl} }},
+		{anomalySpike, dirDownlink, func(r anomalyRate) anomalyRate { return anomalyRate{ul: r.ul, dl: r.dl * 10} }},
+		{anomalyStall, dirDownlink, func(r anomalyRate) anomalyRate { return anomalyRate{ul: r.ul, dl: r.dl / 20} }},
+		{anomalyStall, dirUplink, func(r anomalyRate) anomalyRate { return anomalyRate{ul: 0, dl: r.dl} }},
+		// Neither direction moves enough for a spike or stall, their balance does
+		{anomalyAsymmetry, "", func(r anomalyRate) anomalyRate { return anomalyRate{ul: r.ul * 2.5, dl: r.dl / 2.5} }},
+	} {
+		t.Run(tc.kind+"_"+tc.direction, func(t *testing.T) {
+			rates := simRates(t, normal+distorted+normal)
+			s := &anomalySession{imsi: "001010000000001", dnn: "internet", open: make(map[anomalyKey]*openAnomaly)}
+			start := time.Unix(1_700_000_000, 0)
+
+			var started, ended []*pb.AnomalyEvent
+			var startStep, endStep int
+			for i, r := range rates {
+				if i >= normal && i < normal+distorted {
+					r = tc.distort(r)
+				}
+				for _, ev := range s.evaluate("0x1", r.ul, r.dl, start.Add(time.Duration(i)*time.Second)) {
+					switch ev.State {
+					case anomalyStarted:
+						started, startStep = append(started, ev), i
+					case anomalyEnded:
+						ended, endStep = append(ended, ev), i
+					}
+				}
+			}
+
+			if len(started) != 1 || len(ended) != 1 {
+				t.Fatalf("%d anomalies started and %d ended, want 1 each: %v %v", len(started), len(ended), started, ended)
+			}
+			if startStep != normal || endStep != normal+distorted {
+				t.Errorf("started at step %d and ended at %d, want %d and %d", startStep, endStep, normal, normal+distorted)
+			}
+			for _, ev := range []*pb.AnomalyEvent{started[0], ended[0]} {
+				if ev.Kind != tc.kind || ev.Direction != tc.direction || ev.Fseid != "0x1" || ev.Imsi != "001010000000001" {
+					t.Errorf("%s event of %s %q on %s (%s), want %s %q on 0x1", ev.State, ev.Kind, ev.Direction, ev.Fseid, ev.Imsi, tc.kind, tc.direction)
+				}
+				if ev.Score < anomalyScore {
+					t.Errorf("%s event scored %.1f, want at least %g", ev.State, ev.Score, anomalyScore)
+				}
+			}
+			if ended[0].StartedUnixNano != started[0].StartedUnixNano || ended[0].Score < started[0].Score {
+				t.Errorf("ended event started at %d scored %.1f, want the start %d and at least %.1f",
+					ended[0].StartedUnixNano, ended[0].Score, started[0].StartedUnixNano, started[0].Score)
+			}
+		})
+	}
+}
+
+// TestAnomalyEndsWithSession checks that anomalies still open when their
+// session leaves the rule store end on the streams
+func TestAnomalyEndsWithSession(t *testing.T) {
+	rules := rule.NewStore(4)
+	putSession(t, rules, "0x1", "001010000000001", "internet")
+	a := newAnomalyDetector(rules)
+	start := time.Unix(1_700_000_000, 0)
+	a.observe(start, nil)
+
+	s := a.sessions["0x1"]
+	for i, r := range simRates(t, anomalyWarmup+1) {
+		if i == anomalyWarmup {
+			r.ul *= 10
+		}
+		s.evaluate("0x1", r.ul, r.dl, start)
+	}
+	if len(s.open) != 1 {
+		t.Fatalf("%d anomalies open, want 1", len(s.open))
+	}
+
+	sub := a.subscribe("", "", 0)
+	defer a.unsubscribe(sub)
+	if ev := <-sub.events; ev.State != anomalyStarted {
+		t.Fatalf("queued a %s event, want the open anomaly", ev.State)
+	}
+	rules.Delete("0x1", rule.Change{Caller: "test"})
+	a.observe(start.Add(anomalyRefresh), nil)
+
+	select {
+	case ev := <-sub.events:
+		if ev.State != anomalyEnded || ev.Kind != anomalySpike || ev.Direction != dirUplink || ev.Fseid != "0x1" {
+			t.Errorf("got %s %s %q of %s, want the uplink spike of 0x1 ended", ev.Kind, ev.State, ev.Direction, ev.Fseid)
+		}
+	default:
+		t.Fatal("no event when the session left")
+	}
+	if len(a.sessions) != 0 {
+		t.Errorf("%d sessions still watched", len(a.sessions))
+	}
+}
@@ -0,0 +1,702 @@
+package pfcp
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"upf/Server/rule"
+	pb "upf/pkg/proto"
+)
+
+// Flow update intervals. The engine ticks at the shortest interval a stream
+// may ask for and serves every stream on the tick its interval comes due.
+const (
+	flowTick        = 100 * time.Millisecond // Engine tick, also the shortest interval
+	flowInterval    = 2 * time.Second        // Interval of streams that do not ask for one
+	maxFlowInterval = 60 * time.Second       // Longest interval a stream may ask for
+)
+
+// Replay limits of resumable subscriptions
+const (
+	flowReplayDepth = 64              // Updates kept per F-SEID of a subscription
+	flowRetention   = 5 * time.Minute // How long a subscription outlives its stream
+)
+
+// Units of the speeds and packet rates carried in flow updates
+const (
+	speedUnit      = "bit/s"
+	packetRateUnit = "packet/s"
+)
+
+// flowEWMAAlpha is the weight of the newest interval in the smoothed rates
+const flowEWMAAlpha = 0.3
+
+// flowFields selects the counters a stream receives
+type flowFields uint8
+
+// Counter groups of a flow update
+const (
+	fieldPackets flowFields = 1 << iota
+	fieldBytes
+	fieldSpeed
+	fieldSpeedAvg
+	fieldPacketRate
+	fieldPacketRateAvg
+	fieldQoS
+
+	allFlowFields = fieldPackets | fieldBytes | fieldSpeed | fieldSpeedAvg | fieldPacketRate | fieldPacketRateAvg | fieldQoS
+)
+
+// flowFieldNames maps the field names of a FlowRequest to counter groups
+var flowFieldNames = map[string]flowFields{
+	"packets":         fieldPackets,
+	"bytes":           fieldBytes,
+	"speed":           fieldSpeed,
+	"speed_avg":       fieldSpeedAvg,
+	"packet_rate":     fieldPacketRate,
+	"packet_rate_avg": fieldPacketRateAvg,
+	"qos":             fieldQoS,
+}
+
+// flowSelector names the flows a stream watches: a list of F-SEIDs and the
+// sessions of an IMSI or DNN, which are looked up again on every update
+type flowSelector struct {
+	fseids []string
+	imsi   string
+	dnn    string
+}
+
+// flowSubscription is a validated FlowRequest
+type flowSubscription struct {
+	selector flowSelector
+	interval time.Duration
+	onChange bool
+	fields   flowFields
+}
+
+// parseFlowRequest validates a FlowRequest. A request naming no flow at all
+// watches its (possibly empty) fseid, as streams always did.
+func parseFlowRequest(req *pb.FlowRequest) (flowSubscription, error) {
+	sub := flowSubscription{
+		selector: flowSelector{imsi: req.Imsi, dnn: req.Dnn},
+		onChange: req.OnChange,
+		fields:   allFlowFields,
+	}
+
+	// Collect the F-SEIDs, dropping duplicates
+	seen := make(map[string]bool)
+	for _, fseid := range append([]string{req.Fseid}, req.Fseids...) {
+		if fseid != "" && !seen[fseid] {
+			seen[fseid] = true
+			sub.selector.fseids = append(sub.selector.fseids, fseid)
+		}
+	}
+	if len(sub.selector.fseids) == 0 && req.Imsi == "" && req.Dnn == "" {
+		sub.selector.fseids = []string{req.Fseid}
+	}
+
+	interval, err := parseInterval(req.IntervalMs)
+	if err != nil {
+		return flowSubscription{}, err
+	}
+	sub.interval = interval
+
+	if len(req.Fields) > 0 {
+		sub.fields = 0
+		for _, name := range req.Fields {
+			f, ok := flowFieldNames[name]
+			if !ok {
+				return flowSubscription{}, fmt.Errorf("unknown field %q", name)
+			}
+			sub.fields |= f
+		}
+	}
+	return sub, nil
+}
+
+// parseInterval validates the interval_ms of a stream, 0 meaning flowInterval
+func parseInterval(ms uint32) (time.Duration, error) {
+	if ms == 0 {
+		return flowInterval, nil
+	}
+	interval := time.Duration(ms) * time.Millisecond
+	if interval < flowTick || interval > maxFlowInterval {
+		return 0, fmt.Errorf("interval_ms must be between %d and %d",
+			flowTick.Milliseconds(), maxFlowInterval.Milliseconds())
+	}
+	return interval, nil
+}
+
+// flowRater derives speeds and packet rates from the counter deltas between
+// consecutive measurements of a flow
+type flowRater struct {
+	prev     flowmeasuredata // Previous measurement
+	smoothed bool            // The EWMA rates hold at least one interval
+}
+
+// reset starts a new interval at now from the counters of d without
+// reporting rates for the time before it. The averages are left as they are.
+func (r *flowRater) reset(d flowmeasuredata, now time.Time) {
+	r.prev.Rx_Packet, r.prev.Tx_Packet = d.Rx_Packet, d.Tx_Packet
+	r.prev.Rx_Bytes, r.prev.Tx_Bytes = d.Rx_Bytes, d.Tx_Bytes
+	r.prev.Measured = now
+}
+
+// idle ends an interval in which the counters did not move: it starts the
+// next one at now and blends a rate of zero into the averages
+func (r *flowRater) idle(now time.Time) {
+	r.prev.Measured = now
+	if !r.smoothed {
+		return
+	}
+	r.prev.Rx_Speed_Avg *= 1 - flowEWMAAlpha
+	r.prev.Tx_Speed_Avg *= 1 - flowEWMAAlpha
+	r.prev.Rx_Packet_Rate_Avg *= 1 - flowEWMAAlpha
+	r.prev.Tx_Packet_Rate_Avg *= 1 - flowEWMAAlpha
+}
+
+// moved reports whether the counters of d differ from the previous measurement
+func (r *flowRater) moved(d flowmeasuredata) bool {
+	return d.Rx_Packet != r.prev.Rx_Packet || d.Tx_Packet != r.prev.Tx_Packet ||
+		d.Rx_Bytes != r.prev.Rx_Bytes || d.Tx_Bytes != r.prev.Tx_Bytes
+}
+
+// measure fills in the rates of d from the counters it gained since the
+// previous measurement, and makes d the new previous measurement. A counter
+// that went backwards was reset, and counts from zero.
+func (r *flowRater) measure(d *flowmeasuredata) {
+	elapsed := d.Measured.Sub(r.prev.Measured)
+	if elapsed <= 0 {
+		return
+	}
+	delta := func(cur, prev uint64) float64 {
+		if cur < prev {
+			return float64(cur)
+		}
+		return float64(cur - prev)
+	}
+	secs := elapsed.Seconds()
+	d.Interval = elapsed
+	d.Rx_Speed = uint64(delta(d.Rx_Bytes, r.prev.Rx_Bytes) * 8 / secs)
+	d.Tx_Speed = uint64(delta(d.Tx_Bytes, r.prev.Tx_Bytes) * 8 / secs)
+	d.Total_Speed = d.Rx_Speed + d.Tx_Speed
+	d.Rx_Packet_Rate = delta(d.Rx_Packet, r.prev.Rx_Packet) / secs
+	d.Tx_Packet_Rate = delta(d.Tx_Packet, r.prev.Tx_Packet) / secs
+
+	// The first interval seeds the averages, later ones are blended in
+	ewma := func(avg, v float64) float64 {
+		if !r.smoothed {
+			return v
+		}
+		return avg + flowEWMAAlpha*(v-avg)
+	}
+	d.Rx_Speed_Avg = ewma(r.prev.Rx_Speed_Avg, float64(d.Rx_Speed))
+	d.Tx_Speed_Avg = ewma(r.prev.Tx_Speed_Avg, float64(d.Tx_Speed))
+	d.Rx_Packet_Rate_Avg = ewma(r.prev.Rx_Packet_Rate_Avg, d.Rx_Packet_Rate)
+	d.Tx_Packet_Rate_Avg = ewma(r.prev.Tx_Packet_Rate_Avg, d.Tx_Packet_Rate)
+	r.smoothed = true
+	r.prev = *d
+}
+
+// flowUpdate is one measurement of a flow as delivered to a stream
+type flowUpdate struct {
+	fseid string          // F-SEID of the measured flow
+	imsi  string          // IMSI owning the session, if known
+	data  flowmeasuredata // Counters and rates at the time of the measurement
+	count uint64          // Sequence number within the subscription
+}
+
+// flowTarget is a flow selected by a subscriber
+type flowTarget struct {
+	fseid string
+	imsi  string
+}
+
+// flowReplay keeps the latest updates of one F-SEID of a subscription
+type flowReplay struct {
+	updates []flowUpdate // Oldest first, at most flowReplayDepth
+}
+
+// flowSubscriber holds the state of one subscription. Its updates are numbered
+// and kept in per-F-SEID replay buffers, which the attached stream reads by
+// cursor; a stream that falls behind, or a client that reconnects, gets what
+// the buffers still hold and a gap marker for what aged out.
+type flowSubscriber struct {
+	flowSubscription
+	id uint64
+
+	// Owned by the engine, under its lock
+	targets []flowTarget          // Flows currently selected
+	raters  map[string]*flowRater // Rates over this stream's interval, per F-SEID
+	next    time.Time             // When the next update is due
+
+	mu       sync.Mutex
+	seq      uint64                 // Last sequence number assigned
+	replay   map[string]*flowReplay // Recent updates per F-SEID
+	lost     uint64                 // Highest sequence number no longer held
+	ready    chan struct{}          // Signalled when updates are added
+	stream   chan struct{}          // Closed to take the subscription from its stream
+	detached time.Time              // When the last stream left, zero while one is attached
+}
+
+// offer numbers updates and adds them to the replay buffers without blocking.
+// A buffer that is full loses its oldest update.
+func (sub *flowSubscriber) offer(updates []flowUpdate) {
+	if len(updates) == 0 {
+		return
+	}
+	sub.mu.Lock()
+	for _, u := range updates {
+		sub.seq++
+		u.count = sub.seq
+		r, ok := sub.replay[u.fseid]
+		if !ok {
+			r = &flowReplay{}
+			sub.replay[u.fseid] = r
+		}
+		if len(r.updates) == flowReplayDepth {
+			sub.lost = max(sub.lost, r.updates[0].count)
+			r.updates = r.updates[1:]
+		}
+		r.updates = append(r.updates, u)
+	}
+	sub.mu.Unlock()
+
+	select {
+	case sub.ready <- struct{}{}:
+	default:
+	}
+}
+
+// retire forgets the buffers of flows that left the selection once a full
+// buffer's worth of updates has been numbered after their last one
+func (sub *flowSubscriber) retire(current []flowTarget) {
+	keep := make(map[string]bool, len(current))
+	for _, t := range current {
+		keep[t.fseid] = true
+	}
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	for fseid, r := range sub.replay {
+		if keep[fseid] {
+			continue
+		}
+		last := r.updates[len(r.updates)-1].count
+		if sub.seq-last >= flowReplayDepth {
+			sub.lost = max(sub.lost, last)
+			delete(sub.replay, fseid)
+		}
+	}
+}
+
+// since returns the held updates numbered after cursor in order, and the
+// highest sequence number after cursor that is no longer held, if any
+func (sub *flowSubscriber) since(cursor uint64) ([]flowUpdate, uint64) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	var out []flowUpdate
+	for _, r := range sub.replay {
+		for _, u := range r.updates {
+			if u.count > cursor {
+				out = append(out, u)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].count < out[j].count })
+
+	var gap uint64
+	if sub.lost > cursor {
+		gap = sub.lost
+	}
+	return out, gap
+}
+
+// attach hands the subscription to a new stream. A stream already attached
+// sees the returned channel of its own attachment closed.
+func (sub *flowSubscriber) attach() chan struct{} {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.stream != nil {
+		close(sub.stream)
+	}
+	sub.stream = make(chan struct{})
+	sub.detached = time.Time{}
+	return sub.stream
+}
+
+// detach releases the subscription from a stream, unless another stream took it over
+func (sub *flowSubscriber) detach(stream chan struct{}) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.stream == stream {
+		sub.stream = nil
+		sub.detached = time.Now()
+	}
+}
+
+// expired reports whether the subscription went without a stream for longer than flowRetention
+func (sub *flowSubscriber) expired(now time.Time) bool {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	return sub.stream == nil && !sub.detached.IsZero() && now.Sub(sub.detached) > flowRetention
+}
+
+// flowEntry holds the cumulative counters of one F-SEID
+type flowEntry struct {
+	data    flowmeasuredata // Counters as of data.Measured
+	watched bool            // Some stream selected the flow at the last tick
+}
+
+// flowEngine owns the flow counters of all F-SEIDs. It measures every watched
+// flow once per tick and serves each stream whose interval has come due, so
+// the number of streams does not change how fast counters move.
+type flowEngine struct {
+	imsi  []string    // IMSIs reported with every flow
+	rules *rule.Store // Sessions, to resolve IMSI and DNN selections
+
+	mu     sync.Mutex
+	flows  map[string]*flowEntry
+	subs   map[uint64]*flowSubscriber // Subscriptions by ID, attached or awaiting resumption
+	lastID uint64
+	source trafficSource // Where the counters of watched flows come from
+	meters []flowMeter   // Consumers of counters besides the streams
+}
+
+// newFlowEngine creates a flow engine reporting the given IMSIs, fed with random traffic
+func newFlowEngine(imsi []string, rules *rule.Store) *flowEngine {
+	return &flowEngine{
+		imsi:   imsi,
+		rules:  rules,
+		flows:  make(map[string]*flowEntry),
+		subs:   make(map[uint64]*flowSubscriber),
+		source: newRandomTraffic(time.Now().UnixNano()),
+	}
+}
+
+// setSource replaces the source of the flow counters
+func (e *flowEngine) setSource(src trafficSource) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.source = src
+}
+
+// addMeter has the flows a meter watches measured every tick and handed to it
+func (e *flowEngine) addMeter(m flowMeter) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.meters = append(e.meters, m)
+}
+
+// seed sets the initial counters of a flow
+func (e *flowEngine) seed(fseid string, data flowmeasuredata) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	f := e.entryLocked(fseid)
+	data.Measured = time.Now()
+	f.data = data
+}
+
+// entryLocked returns the entry of a flow, creating it if needed. Callers hold e.mu.
+func (e *flowEngine) entryLocked(fseid string) *flowEntry {
+	f, ok := e.flows[fseid]
+	if !ok {
+		f = &flowEntry{data: flowmeasuredata{All_IMSI: e.imsi, Measured: time.Now()}}
+		e.flows[fseid] = f
+	}
+	return f
+}
+
+// watchLocked marks a flow as watched. A flow nobody watched did not carry
+// traffic, so its counters resume from now. Callers hold e.mu.
+func (e *flowEngine) watchLocked(fseid string, now time.Time) *flowEntry {
+	f := e.entryLocked(fseid)
+	if !f.watched {
+		f.watched = true
+		f.data.Measured = now
+		e.measureLocked(fseid, f, now)
+	}
+	return f
+}
+
+// measureLocked brings the counters of a flow up to now. Callers hold e.mu.
+func (e *flowEngine) measureLocked(fseid string, f *flowEntry, now time.Time) {
+	e.source.measure(fseid, &f.data, now)
+	f.data.Total_Packets = f.data.Rx_Packet + f.data.Tx_Packet
+	f.data.Measured = now
+}
+
+// resolveLocked returns the flows a selector names right now. Callers hold e.mu.
+func (e *flowEngine) resolveLocked(sel flowSelector) []flowTarget {
+	var out []flowTarget
+	seen := make(map[string]bool)
+	add := func(fseid, imsi string) {
+		if !seen[fseid] {
+			seen[fseid] = true
+			out = append(out, flowTarget{fseid: fseid, imsi: imsi})
+		}
+	}
+
+	for _, fseid := range sel.fseids {
+		var imsi string
+		if s, ok := e.rules.Get(fseid); ok {
+			imsi = s.IMSI()
+		}
+		add(fseid, imsi)
+	}
+	if sel.imsi != "" {
+		for _, s := range e.rules.ByIMSI(sel.imsi) {
+			add(s.FSEID(), s.IMSI())
+		}
+	}
+	if sel.dnn != "" {
+		for _, s := range e.rules.ByDNN(sel.dnn) {
+			add(s.FSEID(), s.IMSI())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].fseid < out[j].fseid })
+	return out
+}
+
+// subscribe starts a subscription. The current counters of the selected flows
+// are queued right away so the stream does not wait a full interval for them.
+func (e *flowEngine) subscribe(s flowSubscription) *flowSubscriber {
+	sub := &flowSubscriber{
+		flowSubscription: s,
+		raters:           make(map[string]*flowRater),
+		replay:           make(map[string]*flowReplay),
+		ready:            make(chan struct{}, 1),
+		detached:         time.Now(),
+	}
+
+	now := time.Now()
+	var first []flowUpdate
+	e.mu.Lock()
+	e.lastID++
+	sub.id = e.lastID
+	sub.targets = e.resolveLocked(s.selector)
+	for _, t := range sub.targets {
+		f := e.watchLocked(t.fseid, now)
+		r := &flowRater{}
+		r.reset(f.data, now)
+		sub.raters[t.fseid] = r
+		first = append(first, flowUpdate{fseid: t.fseid, imsi: t.imsi, data: f.data})
+	}
+	sub.next = now.Add(s.interval)
+	e.subs[sub.id] = sub
+	e.mu.Unlock()
+
+	sub.offer(first)
+	return sub
+}
+
+// lookup returns a subscription that has not expired yet
+func (e *flowEngine) lookup(id uint64) (*flowSubscriber, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	sub, ok := e.subs[id]
+	return sub, ok
+}
+
+// run measures flows and serves streams every tick until stop is closed
+func (e *flowEngine) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(flowTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			e.tick(now)
+		}
+	}
+}
+
+// tick advances every watched flow and delivers updates to the streams due at now
+func (e *flowEngine) tick(now time.Time) {
+	type delivery struct {
+		sub     *flowSubscriber
+		updates []flowUpdate
+	}
+	var out []delivery
+
+	e.mu.Lock()
+
+	// Streams due now look up their flows again, so IMSI and DNN
+	// selections follow sessions as they come and go. Subscriptions
+	// nobody resumed in time are dropped.
+	var due []*flowSubscriber
+	for id, sub := range e.subs {
+		if sub.expired(now) {
+			delete(e.subs, id)
+			continue
+		}
+		if sub.next.Sub(now) < flowTick/2 {
+			sub.targets = e.resolveLocked(sub.selector)
+			due = append(due, sub)
+		}
+	}
+
+	// Measure every flow some subscription or meter watches, and only those
+	watched := make(map[string]bool)
+	for _, sub := range e.subs {
+		for _, t := range sub.targets {
+			watched[t.fseid] = true
+		}
+	}
+	meters := e.meters
+	metered := make([][]string, len(meters))
+	for i, m := range meters {
+		metered[i] = m.watching()
+		for _, fseid := range metered[i] {
+			watched[fseid] = true
+		}
+	}
+	// A flow nobody watches any more keeps its counters only while its
+	// session exists; those of deleted or never stored sessions are dropped
+	for fseid, f := range e.flows {
+		if f.watched && !watched[fseid] {
+			f.watched = false
+			if _, ok := e.rules.Get(fseid); !ok {
+				delete(e.flows, fseid)
+			}
+		}
+	}
+	e.source.tick()
+	for fseid := range watched {
+		f := e.entryLocked(fseid)
+		if !f.watched {
+			e.watchLocked(fseid, now)
+			continue
+		}
+		e.measureLocked(fseid, f, now)
+	}
+	counters := make([]map[string]flowmeasuredata, len(meters))
+	for i, fseids := range metered {
+		counters[i] = make(map[string]flowmeasuredata, len(fseids))
+		for _, fseid := range fseids {
+			counters[i][fseid] = e.flows[fseid].data
+		}
+	}
+
+	// Derive each due stream's rates over its own interval
+	for _, sub := range due {
+		d := delivery{sub: sub}
+		rates := make(map[string]*flowRater, len(sub.targets))
+		for _, t := range sub.targets {
+			data := e.flows[t.fseid].data
+			r, ok := sub.raters[t.fseid]
+			if !ok {
+				r = &flowRater{}
+				r.reset(data, now)
+			}
+			rates[t.fseid] = r
+
+			if sub.onChange && !r.moved(data) {
+				r.idle(now)
+				continue
+			}
+			r.measure(&data)
+			d.updates = append(d.updates, flowUpdate{fseid: t.fseid, imsi: t.imsi, data: data})
+		}
+		sub.raters = rates
+		sub.retire(sub.targets)
+
+		sub.next = sub.next.Add(sub.interval)
+		if sub.next.Before(now) {
+			sub.next = now.Add(sub.interval)
+		}
+		out = append(out, d)
+	}
+	e.mu.Unlock()
+
+	// Fan out without holding the lock; offer never blocks on a slow stream
+	for _, d := range out {
+		d.sub.offer(d.updates)
+	}
+	for i, m := range meters {
+		m.observe(now, counters[i])
+	}
+}
+
+// flowMeter consumes the counters of the flows it watches once per tick. The
+// engine calls watching under its lock and observe after releasing it.
+type flowMeter interface {
+	watching() []string
+	observe(now time.Time, counters map[string]flowmeasuredata)
+}
+
+// trafficSource produces the cumulative counters of flows. The engine calls
+// tick once per tick and measure for every watched flow, under its lock.
+type trafficSource interface {
+	tick()
+	measure(fseid string, d *flowmeasuredata, now time.Time)
+}
+
+// randomTraffic adds random traffic to flows, for running without the simulator
+type randomTraffic struct {
+	rng *rand.Rand
+}
+
+// newRandomTraffic creates a random traffic source
+func newRandomTraffic(seed int64) *randomTraffic {
+	return &randomTraffic{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (t *randomTraffic) tick() {}
+
+// measure draws up to 25 packets per second of 64 to 1500 bytes each, per
+// direction, over the time since d was last measured
+func (t *randomTraffic) measure(fseid string, d *flowmeasuredata, now time.Time) {
+	seconds := now.Sub(d.Measured).Seconds()
+	draw := func(packets, bytes *uint64) {
+		n := uint64(t.rng.Intn(int(25*seconds) + 1))
+		*packets += n
+		for i := uint64(0); i < n; i++ {
+			*bytes += uint64(64 + t.rng.Intn(1437))
+		}
+	}
+	draw(&d.Rx_Packet, &d.Rx_Bytes)
+	draw(&d.Tx_Packet, &d.Tx_Bytes)
+}
+
+// flowReply builds the Reply of an update carrying only the selected counters
+func flowReply(id uint64, u flowUpdate, fields flowFields) *pb.Reply {
+	data := u.data
+	r := &pb.Reply{
+		SubscriptionId:     id,
+		Fseid:              u.fseid,
+		Imsi:               u.imsi,
+		All_IMSI:           data.All_IMSI,
+		Count:              u.count,
+		Speed_Unit:         speedUnit,
+		Packet_Rate_Unit:   packetRateUnit,
+		Measured_Unix_Nano: data.Measured.UnixNano(),
+		Interval_Ms:        data.Interval.Milliseconds(),
+	}
+	if fields&fieldPackets != 0 {
+		r.Total_Packets, r.Rx_Packet, r.Tx_Packet = data.Total_Packets, data.Rx_Packet, data.Tx_Packet
+	}
+	if fields&fieldBytes != 0 {
+		r.Rx_Bytes, r.Tx_Bytes = data.Rx_Bytes, data.Tx_Bytes
+	}
+	if fields&fieldSpeed != 0 {
+		r.Rx_Speed, r.Tx_Speed, r.Total_Speed = data.Rx_Speed, data.Tx_Speed, data.Total_Speed
+	}
+	if fields&fieldSpeedAvg != 0 {
+		r.Rx_Speed_Avg, r.Tx_Speed_Avg = data.Rx_Speed_Avg, data.Tx_Speed_Avg
+	}
+	if fields&fieldPacketRate != 0 {
+		r.Rx_Packet_Rate, r.Tx_Packet_Rate = data.Rx_Packet_Rate, data.Tx_Packet_Rate
+	}
+	if fields&fieldPacketRateAvg != 0 {
+		r.Rx_Packet_Rate_Avg, r.Tx_Packet_Rate_Avg = data.Rx_Packet_Rate_Avg, data.Tx_Packet_Rate_Avg
+	}
+	// Only simulated sessions are policed
+	if fields&fieldQoS != 0 && (data.Rx_QoS != (qosCounters{}) || data.Tx_QoS != (qosCounters{})) {
+		r.Rx_QoS, r.Tx_QoS = data.Rx_QoS.proto(), data.Tx_QoS.proto()
+	}
+	return r
+}
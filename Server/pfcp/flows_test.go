@@ -0,0 +1,400 @@
+package pfcp
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"upf/Server/rule"
+	pb "upf/pkg/proto"
+)
+
+// putSession stores a minimal session under fseid
+func putSession(t testing.TB, rules *rule.Store, fseid, imsi, dnn string) {
+	t.Helper()
+	err := rules.PutRules(&pb.Rulestruct{
+		Pdr:  &pb.Pdrstruct{Fsied: fseid, PdrId: []string{"pdr-" + fseid}},
+		Far:  &pb.Farstruct{Fsied: fseid, FarId: "far-" + fseid, ApplyAction: "forward"},
+		Qer:  &pb.Qerstruct{Fsied: fseid},
+		Urr:  &pb.Urrstruct{Fsied: fseid},
+		Imsi: imsi,
+		Dnn:  dnn,
+	}, rule.Change{Caller: "test", Operation: "putSession"})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestFlowEngineConcurrentStreams runs hundreds of streams that subscribe,
+// take each other's subscriptions over and read their replay buffers while
+// the engine ticks. Run it with -race.
+func TestFlowEngineConcurrentStreams(t *testing.T) {
+	rules := rule.NewStore(8)
+	for i := 0; i < 20; i++ {
+		putSession(t, rules, fmt.Sprintf("0x%x", i+1), fmt.Sprintf("IMSI%d", i%4), "internet")
+	}
+	e := newFlowEngine([]string{"IMSI0"}, rules)
+	stop := make(chan struct{})
+	defer close(stop)
+	go e.run(stop)
+
+	const streams = 300
+	deadline := time.Now().Add(time.Second)
+	ids := make(chan uint64, streams)
+	errs := make(chan error, streams)
+	var wg sync.WaitGroup
+	for i := 0; i < streams; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(i)))
+
+			var sel flowSelector
+			switch i % 3 {
+			case 0:
+				sel.fseids = []string{fmt.Sprintf("0x%x", i%20+1), fmt.Sprintf("0x%x", (i+7)%20+1)}
+			case 1:
+				sel.imsi = fmt.Sprintf("IMSI%d", i%4)
+			default:
+				sel.dnn = "internet"
+			}
+			sub := e.subscribe(flowSubscription{selector: sel, interval: flowTick, fields: allFlowFields})
+			ids <- sub.id
+
+			stream := sub.attach()
+			var cursor uint64
+			last := make(map[string]uint64) // Rx packets last seen per F-SEID
+			for time.Now().Before(deadline) {
+				select {
+				case <-stream:
+					// Another stream took the subscription; resume it under a new attachment
+					stream = sub.attach()
+				case <-sub.ready:
+				case <-time.After(10 * time.Millisecond):
+				}
+				updates, _ := sub.since(cursor)
+				for _, u := range updates {
+					if u.count <= cursor {
+						errs <- fmt.Errorf("stream %d: update %d after cursor %d", i, u.count, cursor)
+						return
+					}
+					cursor = u.count
+					if u.data.Rx_Packet < last[u.fseid] {
+						errs <- fmt.Errorf("stream %d: %s went back from %d to %d packets", i, u.fseid, last[u.fseid], u.data.Rx_Packet)
+						return
+					}
+					last[u.fseid] = u.data.Rx_Packet
+				}
+
+				// Now and then take over another stream's subscription briefly
+				if rng.Intn(20) == 0 {
+					select {
+					case id := <-ids:
+						if other, ok := e.lookup(id); ok {
+							s := other.attach()
+							other.since(0)
+							other.detach(s)
+						}
+						ids <- id
+					default:
+					}
+				}
+			}
+			sub.detach(stream)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestFlowEngineEvictsUnwatchedFlows(t *testing.T) {
+	rules := rule.NewStore(4)
+	putSession(t, rules, "0x1", "IMSI1", "internet")
+	e := newFlowEngine(nil, rules)
+
+	sub := e.subscribe(flowSubscription{
+		selector: flowSelector{fseids: []string{"0x1", "0x2"}},
+		interval: flowTick,
+		fields:   allFlowFields,
+	})
+	stream := sub.attach()
+	now := time.Now()
+	e.tick(now)
+	if len(e.flows) != 2 {
+		t.Fatalf("%d flows while watched, want 2", len(e.flows))
+	}
+
+	// Detached subscriptions keep their flows until they can no longer be resumed
+	sub.detach(stream)
+	e.tick(now.Add(flowTick))
+	if len(e.flows) != 2 {
+		t.Fatalf("%d flows while the subscription can be resumed, want 2", len(e.flows))
+	}
+	e.tick(now.Add(flowRetention + time.Second))
+	if _, ok := e.lookup(sub.id); ok {
+		t.Fatal("subscription not expired")
+	}
+	if _, ok := e.flows["0x2"]; ok {
+		t.Fatal("flow of an unknown session kept after its last subscriber left")
+	}
+	if _, ok := e.flows["0x1"]; !ok {
+		t.Fatal("flow of a stored session dropped")
+	}
+
+	// A stored session's flow goes once the session does
+	again := e.subscribe(flowSubscription{selector: flowSelector{fseids: []string{"0x1"}}, interval: flowTick})
+	rules.Delete("0x1", rule.Change{Caller: "test", Operation: "delete"})
+	again.detach(again.attach())
+	e.tick(now.Add(2*flowRetention + 2*time.Second))
+	if len(e.flows) != 0 {
+		t.Fatalf("flows %v left, want none", e.flows)
+	}
+}
+
+// TestFlowRater checks the rates and averages of a flow over moving, idle
+// and reset intervals
+func TestFlowRater(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	var r flowRater
+	r.reset(flowmeasuredata{Rx_Bytes: 1000, Rx_Packet: 10, Rx_Speed_Avg: 1e9}, start)
+	for _, tc := range []struct {
+		name         string
+		at           time.Duration // Since start
+		bytes, pkts  uint64        // Rx counters, ignored when idle
+		idle         bool          // The counters did not move
+		speed        uint64
+		avg, pktRate float64
+	}{
+		{"first interval seeds the average", time.Second, 2000, 20, false, 8000, 8000, 10},
+		{"blended in", 2 * time.Second, 4000, 40, false, 16000, 10400, 20},
+		{"idle decays the average", 3 * time.Second, 0, 0, true, 0, 7280, 0},
+		{"rated from the idle interval on", 5 * time.Second, 8000, 80, false, 16000, 9896, 20},
+		{"counters reset", 6 * time.Second, 500, 5, false, 4000, 8127.2, 5},
+	} {
+		if tc.idle {
+			r.idle(start.Add(tc.at))
+			if math.Abs(r.prev.Rx_Speed_Avg-tc.avg) > 1e-6 {
+				t.Errorf("%s: average %g, want %g", tc.name, r.prev.Rx_Speed_Avg, tc.avg)
+			}
+			continue
+		}
+		d := flowmeasuredata{Rx_Bytes: tc.bytes, Rx_Packet: tc.pkts, Measured: start.Add(tc.at)}
+		r.measure(&d)
+		if d.Rx_Speed != tc.speed || math.Abs(d.Rx_Speed_Avg-tc.avg) > 1e-6 || d.Rx_Packet_Rate != tc.pktRate {
+			t.Errorf("%s: %d bit/s averaging %g at %g packet/s, want %d bit/s averaging %g at %g packet/s",
+				tc.name, d.Rx_Speed, d.Rx_Speed_Avg, d.Rx_Packet_Rate, tc.speed, tc.avg, tc.pktRate)
+		}
+	}
+
+	// A measurement that is not later than the previous one is not rated
+	d := flowmeasuredata{Rx_Bytes: 1e6, Measured: start.Add(6 * time.Second)}
+	r.measure(&d)
+	if d.Rx_Speed != 0 || r.prev.Rx_Bytes != 500 {
+		t.Errorf("rated an empty interval at %d bit/s", d.Rx_Speed)
+	}
+
+	// A reset keeps the averages and moves only the counters
+	r.reset(flowmeasuredata{Rx_Bytes: 10}, start.Add(7*time.Second))
+	if r.moved(flowmeasuredata{Rx_Bytes: 10}) || math.Abs(r.prev.Rx_Speed_Avg-8127.2) > 1e-6 {
+		t.Errorf("after a reset: counters %d, average %g", r.prev.Rx_Bytes, r.prev.Rx_Speed_Avg)
+	}
+}
+
+func TestParseFlowRequest(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		req      *pb.FlowRequest
+		fseids   []string
+		interval time.Duration
+		fields   flowFields
+		err      bool
+	}{
+		{"no flow named", &pb.FlowRequest{}, []string{""}, flowInterval, allFlowFields, false},
+		{"fseid", &pb.FlowRequest{Fseid: "0x1"}, []string{"0x1"}, flowInterval, allFlowFields, false},
+		{"fseids without duplicates", &pb.FlowRequest{Fseid: "0x1", Fseids: []string{"0x2", "0x1", "", "0x2"}},
+			[]string{"0x1", "0x2"}, flowInterval, allFlowFields, false},
+		{"imsi only", &pb.FlowRequest{Imsi: "IMSI1"}, nil, flowInterval, allFlowFields, false},
+		{"dnn only", &pb.FlowRequest{Dnn: "internet"}, nil, flowInterval, allFlowFields, false},
+		{"shortest interval", &pb.FlowRequest{Fseid: "0x1", IntervalMs: 100}, []string{"0x1"}, flowTick, allFlowFields, false},
+		{"longest interval", &pb.FlowRequest{Fseid: "0x1", IntervalMs: 60000}, []string{"0x1"}, maxFlowInterval, allFlowFields, false},
+		{"interval too short", &pb.FlowRequest{Fseid: "0x1", IntervalMs: 99}, nil, 0, 0, true},
+		{"interval too long", &pb.FlowRequest{Fseid: "0x1", IntervalMs: 60001}, nil, 0, 0, true},
+		{"fields", &pb.FlowRequest{Fseid: "0x1", Fields: []string{"bytes", "speed_avg", "bytes"}},
+			[]string{"0x1"}, flowInterval, fieldBytes | fieldSpeedAvg, false},
+		{"unknown field", &pb.FlowRequest{Fseid: "0x1", Fields: []string{"bytes", "jitter"}}, nil, 0, 0, true},
+	} {
+		sub, err := parseFlowRequest(tc.req)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%s: accepted as %+v", tc.name, sub)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if fmt.Sprint(sub.selector.fseids) != fmt.Sprint(tc.fseids) || sub.selector.imsi != tc.req.Imsi || sub.selector.dnn != tc.req.Dnn {
+			t.Errorf("%s: selected %+v", tc.name, sub.selector)
+		}
+		if sub.interval != tc.interval || sub.fields != tc.fields {
+			t.Errorf("%s: every %v with fields %b, want every %v with %b", tc.name, sub.interval, sub.fields, tc.interval, tc.fields)
+		}
+	}
+	if sub, _ := parseFlowRequest(&pb.FlowRequest{Fseid: "0x1", OnChange: true}); !sub.onChange {
+		t.Error("on_change dropped")
+	}
+}
+
+// stillTraffic is a traffic source whose flows only move while marked moving
+type stillTraffic struct {
+	moving map[string]bool
+}
+
+func (s *stillTraffic) tick() {}
+
+func (s *stillTraffic) measure(fseid string, d *flowmeasuredata, now time.Time) {
+	if s.moving[fseid] {
+		d.Rx_Packet += 10
+		d.Rx_Bytes += 1000
+	}
+}
+
+// updatedFSEIDs returns the F-SEIDs of the updates after cursor and the new cursor
+func updatedFSEIDs(sub *flowSubscriber, cursor uint64) ([]string, uint64) {
+	updates, _ := sub.since(cursor)
+	var out []string
+	for _, u := range updates {
+		out = append(out, u.fseid)
+		cursor = u.count
+	}
+	return out, cursor
+}
+
+// TestFlowEngineSelection checks that IMSI and DNN selections follow the
+// sessions of the rule store from one interval to the next
+func TestFlowEngineSelection(t *testing.T) {
+	rules := rule.NewStore(4)
+	putSession(t, rules, "0x1", "IMSI1", "internet")
+	putSession(t, rules, "0x2", "IMSI1", "ims")
+	putSession(t, rules, "0x3", "IMSI2", "ims")
+	e := newFlowEngine(nil, rules)
+	e.setSource(&stillTraffic{})
+
+	for _, tc := range []struct {
+		name string
+		sel  flowSelector
+		want string
+	}{
+		{"imsi", flowSelector{imsi: "IMSI1"}, "[0x1 0x2]"},
+		{"dnn", flowSelector{dnn: "ims"}, "[0x2 0x3]"},
+		{"fseids and imsi", flowSelector{fseids: []string{"0x9", "0x1"}, imsi: "IMSI2"}, "[0x1 0x3 0x9]"},
+		{"unknown imsi", flowSelector{imsi: "IMSI9"}, "[]"},
+	} {
+		sub := e.subscribe(flowSubscription{selector: tc.sel, interval: flowTick, fields: allFlowFields})
+		if got, _ := updatedFSEIDs(sub, 0); fmt.Sprint(got) != tc.want {
+			t.Errorf("%s: first updates for %v, want %s", tc.name, got, tc.want)
+		}
+		for _, target := range sub.targets {
+			if s, ok := rules.Get(target.fseid); ok && target.imsi != s.IMSI() {
+				t.Errorf("%s: %s reported for %q, want %q", tc.name, target.fseid, target.imsi, s.IMSI())
+			}
+		}
+	}
+
+	sub := e.subscribe(flowSubscription{selector: flowSelector{imsi: "IMSI1"}, interval: flowTick, fields: allFlowFields})
+	_, cursor := updatedFSEIDs(sub, 0)
+	putSession(t, rules, "0x4", "IMSI1", "internet")
+	rules.Delete("0x1", rule.Change{Caller: "test", Operation: "delete"})
+	e.tick(time.Now().Add(flowTick))
+	if got, _ := updatedFSEIDs(sub, cursor); fmt.Sprint(got) != "[0x2 0x4]" {
+		t.Errorf("after the sessions changed, updates for %v, want [0x2 0x4]", got)
+	}
+}
+
+// TestFlowEngineOnChange checks that on-change streams are only sent the
+// flows whose counters moved, rated over the interval they moved in
+func TestFlowEngineOnChange(t *testing.T) {
+	rules := rule.NewStore(4)
+	src := &stillTraffic{moving: map[string]bool{"0x1": true}}
+	e := newFlowEngine(nil, rules)
+	e.setSource(src)
+
+	sel := flowSelector{fseids: []string{"0x1", "0x2"}}
+	changes := e.subscribe(flowSubscription{selector: sel, interval: flowTick, onChange: true, fields: allFlowFields})
+	every := e.subscribe(flowSubscription{selector: sel, interval: flowTick, fields: allFlowFields})
+	_, changed := updatedFSEIDs(changes, 0)
+	_, all := updatedFSEIDs(every, 0)
+
+	start := time.Now()
+	for i, tc := range []struct {
+		moving  []string
+		changes string
+	}{
+		{[]string{"0x1"}, "[0x1]"},
+		{nil, "[]"},
+		{nil, "[]"},
+		{[]string{"0x1", "0x2"}, "[0x1 0x2]"},
+	} {
+		src.moving = make(map[string]bool)
+		for _, fseid := range tc.moving {
+			src.moving[fseid] = true
+		}
+		e.tick(start.Add(time.Duration(i+1) * flowTick))
+
+		var got []string
+		updates, _ := changes.since(changed)
+		for _, u := range updates {
+			got = append(got, u.fseid)
+			changed = u.count
+			// The first interval started at the subscription, later ones at a tick
+			if i > 0 && (u.data.Interval != flowTick || u.data.Rx_Speed != 1000*8*10) {
+				t.Errorf("tick %d: %s rated %d bit/s over %v, want %d over %v", i, u.fseid, u.data.Rx_Speed, u.data.Interval, 1000*8*10, flowTick)
+			}
+		}
+		if fmt.Sprint(got) != tc.changes {
+			t.Errorf("tick %d: on-change updates for %v, want %s", i, got, tc.changes)
+		}
+		var sent []string
+		sent, all = updatedFSEIDs(every, all)
+		if len(sent) != 2 {
+			t.Errorf("tick %d: %d updates without on_change, want 2", i, len(sent))
+		}
+	}
+}
+
+// TestFlowReplyFields checks that replies carry only the selected counters
+func TestFlowReplyFields(t *testing.T) {
+	u := flowUpdate{fseid: "0x1", imsi: "IMSI1", count: 7, data: flowmeasuredata{
+		Total_Packets: 3, Rx_Packet: 1, Tx_Packet: 2, Rx_Bytes: 100, Tx_Bytes: 200,
+		Rx_Speed: 800, Tx_Speed: 1600, Total_Speed: 2400, Rx_Speed_Avg: 700, Tx_Speed_Avg: 1500,
+		Rx_Packet_Rate: 1, Tx_Packet_Rate: 2, Rx_Packet_Rate_Avg: 0.5, Tx_Packet_Rate_Avg: 1.5,
+		Interval: time.Second,
+	}}
+
+	r := flowReply(9, u, fieldBytes|fieldPacketRateAvg)
+	if r.GetSubscriptionId() != 9 || r.GetCount() != 7 || r.GetFseid() != "0x1" || r.GetImsi() != "IMSI1" || r.GetInterval_Ms() != 1000 {
+		t.Errorf("header fields %v", r)
+	}
+	if r.GetRx_Bytes() != 100 || r.GetTx_Bytes() != 200 || r.GetRx_Packet_Rate_Avg() != 0.5 || r.GetTx_Packet_Rate_Avg() != 1.5 {
+		t.Errorf("selected counters missing: %v", r)
+	}
+	if r.GetTotal_Packets() != 0 || r.GetRx_Speed() != 0 || r.GetRx_Speed_Avg() != 0 || r.GetRx_Packet_Rate() != 0 || r.GetRx_QoS() != nil {
+		t.Errorf("counters sent that were not selected: %v", r)
+	}
+
+	r = flowReply(9, u, allFlowFields)
+	if r.GetTotal_Packets() != 3 || r.GetTotal_Speed() != 2400 || r.GetTx_Speed_Avg() != 1500 || r.GetTx_Packet_Rate() != 2 {
+		t.Errorf("all fields: %v", r)
+	}
+	// Counters of sessions nobody polices carry no colors
+	if r.GetRx_QoS() != nil || r.GetTx_QoS() != nil {
+		t.Errorf("QoS colors of an unpoliced flow: %v %v", r.GetRx_QoS(), r.GetTx_QoS())
+	}
+}
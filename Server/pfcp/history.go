@@ -0,0 +1,359 @@
+package pfcp
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"upf/Server/config"
+	"upf/Server/rule"
+	pb "upf/pkg/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	historyMaxFlows     = 256              // Sessions recorded when flow_history.max_flows is not set
+	historyMaxPoints    = 10000            // Points one GetFlowHistory reply may hold
+	historyDefaultRange = 10 * time.Minute // Range of a GetFlowHistory request that gives no start
+	historyRefresh      = time.Second      // How often the recorded sessions follow the rule store
+)
+
+// historyDefaults are the resolutions kept when flow_history lists none
+var historyDefaults = []config.HistoryResolution{
+	{Step: "1s", Retention: "10m"},
+	{Step: "1m", Retention: "24h"},
+	{Step: "1h", Retention: "720h"},
+}
+
+// historyResolution is one resolution of the flow history
+type historyResolution struct {
+	step      time.Duration // Time covered by one point
+	retention time.Duration // How far back points are kept
+}
+
+// parseHistoryConfig reads the flow_history block. Steps are whole seconds,
+// and each is a multiple of the one before so coarse points line up with fine ones.
+func parseHistoryConfig(cfg config.FlowHistory) (int, []historyResolution, error) {
+	maxFlows := cfg.MaxFlows
+	if maxFlows == 0 {
+		maxFlows = historyMaxFlows
+	}
+	if maxFlows < 0 {
+		return 0, nil, fmt.Errorf("flow_history.max_flows must not be negative")
+	}
+
+	entries := cfg.Resolutions
+	if len(entries) == 0 {
+		entries = historyDefaults
+	}
+	var out []historyResolution
+	for i, e := range entries {
+		step, err := time.ParseDuration(e.Step)
+		if err != nil {
+			return 0, nil, fmt.Errorf("flow_history.resolutions[%d].step: %w", i, err)
+		}
+		retention, err := time.ParseDuration(e.Retention)
+		if err != nil {
+			return 0, nil, fmt.Errorf("flow_history.resolutions[%d].retention: %w", i, err)
+		}
+		switch {
+		case step < time.Second || step%time.Second != 0:
+			return 0, nil, fmt.Errorf("flow_history.resolutions[%d].step must be whole seconds", i)
+		case retention < step:
+			return 0, nil, fmt.Errorf("flow_history.resolutions[%d].retention must be at least one step", i)
+		case i > 0 && step%out[i-1].step != 0:
+			return 0, nil, fmt.Errorf("flow_history.resolutions[%d].step must be a multiple of the step before", i)
+		}
+		out = append(out, historyResolution{step: step, retention: retention})
+	}
+	return maxFlows, out, nil
+}
+
+// historyPoint holds the cumulative counters of a flow at the end of a step
+type historyPoint struct {
+	end       int64 // End of the step, in seconds since the epoch
+	rxBytes   uint64
+	txBytes   uint64
+	rxPackets uint64
+	txPackets uint64
+	rxPeak    float32 // Highest receive speed of a finest step within the step, bit/s
+	txPeak    float32 // Highest transmit speed of a finest step within the step, bit/s
+}
+
+// historyRing keeps the points of one resolution, overwriting the oldest
+// once retention/step points are held
+type historyRing struct {
+	historyResolution
+	points []historyPoint
+	head   int     // Oldest point once the ring is full
+	rxPeak float32 // Highest finest receive speed since the last point
+	txPeak float32 // Highest finest transmit speed since the last point
+}
+
+// push adds the newest point
+func (r *historyRing) push(p historyPoint) {
+	if n := int(r.retention / r.step); len(r.points) < n {
+		r.points = append(r.points, p)
+		return
+	}
+	r.points[r.head] = p
+	r.head = (r.head + 1) % len(r.points)
+}
+
+// last returns the newest point
+func (r *historyRing) last() (historyPoint, bool) {
+	if len(r.points) == 0 {
+		return historyPoint{}, false
+	}
+	return r.points[(r.head+len(r.points)-1)%len(r.points)], true
+}
+
+// each calls fn for every point from the oldest to the newest
+func (r *historyRing) each(fn func(historyPoint)) {
+	for i := range r.points {
+		fn(r.points[(r.head+i)%len(r.points)])
+	}
+}
+
+// perSecond returns the rate of a counter that moved from prev to cur over
+// secs seconds. A counter that went backwards was reset and has no rate.
+func perSecond(cur, prev uint64, secs float64) float64 {
+	if cur < prev || secs <= 0 {
+		return 0
+	}
+	return float64(cur-prev) / secs
+}
+
+// flowHistory is the history of one F-SEID, one ring per resolution
+type flowHistory struct {
+	rings []historyRing
+}
+
+// newFlowHistory creates an empty history with the given resolutions
+func newFlowHistory(resolutions []historyResolution) *flowHistory {
+	h := &flowHistory{rings: make([]historyRing, len(resolutions))}
+	for i, res := range resolutions {
+		h.rings[i].historyResolution = res
+	}
+	return h
+}
+
+// record adds a point to every resolution whose step ended since its last
+// point. The finest resolution measures the peak speeds the coarser ones keep.
+func (h *flowHistory) record(now time.Time, d flowmeasuredata) {
+	for i := range h.rings {
+		r := &h.rings[i]
+		end := now.Truncate(r.step).Unix()
+		last, ok := r.last()
+		if ok && end <= last.end {
+			continue
+		}
+		p := historyPoint{
+			end:       end,
+			rxBytes:   d.Rx_Bytes,
+			txBytes:   d.Tx_Bytes,
+			rxPackets: d.Rx_Packet,
+			txPackets: d.Tx_Packet,
+		}
+		if i == 0 {
+			if ok {
+				secs := float64(end - last.end)
+				p.rxPeak = float32(perSecond(p.rxBytes, last.rxBytes, secs) * 8)
+				p.txPeak = float32(perSecond(p.txBytes, last.txBytes, secs) * 8)
+			}
+			for j := 1; j < len(h.rings); j++ {
+				h.rings[j].rxPeak = max(h.rings[j].rxPeak, p.rxPeak)
+				h.rings[j].txPeak = max(h.rings[j].txPeak, p.txPeak)
+			}
+		} else {
+			p.rxPeak, p.txPeak = r.rxPeak, r.txPeak
+			r.rxPeak, r.txPeak = 0, 0
+		}
+		r.push(p)
+	}
+}
+
+// historyRecorder keeps the flow history of the sessions in the rule store,
+// up to maxFlows of them. Sessions leave the history when they leave the store.
+type historyRecorder struct {
+	rules       *rule.Store
+	maxFlows    int
+	resolutions []historyResolution
+
+	mu        sync.Mutex
+	flows     map[string]*flowHistory
+	watch     []string // F-SEIDs of flows, for the flow engine
+	refreshed time.Time
+	capped    bool // Whether sessions beyond maxFlows were reported
+}
+
+// newHistoryRecorder creates a recorder configured by the flow_history block
+func newHistoryRecorder(rules *rule.Store, cfg config.FlowHistory) (*historyRecorder, error) {
+	maxFlows, resolutions, err := parseHistoryConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &historyRecorder{
+		rules:       rules,
+		maxFlows:    maxFlows,
+		resolutions: resolutions,
+		flows:       make(map[string]*flowHistory),
+	}, nil
+}
+
+// watching returns the flows whose counters are recorded
+func (h *historyRecorder) watching() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.watch
+}
+
+// observe records the counters of every recorded flow
+func (h *historyRecorder) observe(now time.Time, counters map[string]flowmeasuredata) {
+	if now.Sub(h.refreshed) >= historyRefresh {
+		h.refresh(now)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for fseid, d := range counters {
+		if fh, ok := h.flows[fseid]; ok {
+			fh.record(now, d)
+		}
+	}
+}
+
+// refresh follows the sessions of the rule store. New sessions are recorded
+// in F-SEID order while fewer than maxFlows are.
+func (h *historyRecorder) refresh(now time.Time) {
+	h.refreshed = now
+	current := make(map[string]bool)
+	h.rules.Range(func(s rule.Sessions) bool {
+		current[s.FSEID()] = true
+		return true
+	})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for fseid := range h.flows {
+		if !current[fseid] {
+			delete(h.flows, fseid)
+		}
+	}
+	var added []string
+	for fseid := range current {
+		if _, ok := h.flows[fseid]; !ok {
+			added = append(added, fseid)
+		}
+	}
+	sort.Strings(added)
+	for i, fseid := range added {
+		if len(h.flows) >= h.maxFlows {
+			if !h.capped {
+				log.Printf("⚠️ Flow history holds %d sessions, %d more are not recorded", h.maxFlows, len(added)-i)
+				h.capped = true
+			}
+			break
+		}
+		h.flows[fseid] = newFlowHistory(h.resolutions)
+	}
+
+	h.watch = make([]string, 0, len(h.flows))
+	for fseid := range h.flows {
+		h.watch = append(h.watch, fseid)
+	}
+}
+
+// query returns the history of a flow between start and end in steps of
+// step. It reads the finest resolution that still holds start at now and is
+// no coarser than step; step is rounded up to a multiple of that resolution.
+func (h *historyRecorder) query(fseid string, start, end time.Time, step time.Duration, now time.Time) (*pb.FlowHistoryReply, error) {
+	if !start.Before(end) {
+		return nil, status.Error(codes.InvalidArgument, "start must be before end")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fh, ok := h.flows[fseid]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no flow history for F-SEID %s", fseid)
+	}
+
+	ring := &fh.rings[0]
+	for i := range fh.rings {
+		r := &fh.rings[i]
+		if step != 0 && r.step > step && i > 0 {
+			break
+		}
+		ring = r
+		if !start.Before(now.Add(-r.retention)) {
+			break
+		}
+	}
+	if step < ring.step {
+		step = ring.step
+	}
+	step = (step + ring.step - 1) / ring.step * ring.step
+	if n := end.Sub(start) / step; n > historyMaxPoints {
+		return nil, status.Errorf(codes.InvalidArgument, "range holds %d steps, at most %d are returned", n, historyMaxPoints)
+	}
+
+	// Group the points into steps, keeping the last counters of each step
+	// and its highest peaks. A step still in progress ends at its last
+	// point. The last point before start is the baseline the rates of the
+	// first step are derived from.
+	secs := int64(step / time.Second)
+	bucket := func(end int64) int64 { return (end + secs - 1) / secs }
+	var baseline *historyPoint
+	var steps []historyPoint
+	ring.each(func(p historyPoint) {
+		if p.end <= start.Unix() {
+			baseline = &p
+			return
+		}
+		if p.end > end.Unix() {
+			return
+		}
+		if n := len(steps); n > 0 && bucket(steps[n-1].end) == bucket(p.end) {
+			p.rxPeak, p.txPeak = max(steps[n-1].rxPeak, p.rxPeak), max(steps[n-1].txPeak, p.txPeak)
+			steps[n-1] = p
+			return
+		}
+		steps = append(steps, p)
+	})
+
+	reply := &pb.FlowHistoryReply{
+		Fseid:             fseid,
+		StepSeconds:       uint32(secs),
+		ResolutionSeconds: uint32(ring.step / time.Second),
+		SpeedUnit:         speedUnit,
+		PacketRateUnit:    packetRateUnit,
+	}
+	prev := baseline
+	for i := range steps {
+		p := &steps[i]
+		point := &pb.FlowHistoryPoint{
+			Unix:        p.end,
+			RxBytes:     p.rxBytes,
+			TxBytes:     p.txBytes,
+			RxPackets:   p.rxPackets,
+			TxPackets:   p.txPackets,
+			RxSpeedPeak: float64(p.rxPeak),
+			TxSpeedPeak: float64(p.txPeak),
+		}
+		if prev != nil {
+			elapsed := float64(p.end - prev.end)
+			point.RxSpeed = perSecond(p.rxBytes, prev.rxBytes, elapsed) * 8
+			point.TxSpeed = perSecond(p.txBytes, prev.txBytes, elapsed) * 8
+			point.RxPacketRate = perSecond(p.rxPackets, prev.rxPackets, elapsed)
+			point.TxPacketRate = perSecond(p.txPackets, prev.txPackets, elapsed)
+		}
+		reply.Points = append(reply.Points, point)
+		prev = p
+	}
+	return reply, nil
+}
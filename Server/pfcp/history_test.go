@@ -0,0 +1,219 @@
+package pfcp
+
+import (
+	"testing"
+	"time"
+
+	"upf/Server/config"
+	"upf/Server/rule"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseHistoryConfig(t *testing.T) {
+	res := func(pairs ...string) []config.HistoryResolution {
+		var out []config.HistoryResolution
+		for i := 0; i < len(pairs); i += 2 {
+			out = append(out, config.HistoryResolution{Step: pairs[i], Retention: pairs[i+1]})
+		}
+		return out
+	}
+	for _, tc := range []struct {
+		name     string
+		cfg      config.FlowHistory
+		maxFlows int
+		steps    []time.Duration // nil when rejected
+	}{
+		{"defaults", config.FlowHistory{}, historyMaxFlows, []time.Duration{time.Second, time.Minute, time.Hour}},
+		{"custom", config.FlowHistory{MaxFlows: 10, Resolutions: res("5s", "5s", "15s", "1h")}, 10, []time.Duration{5 * time.Second, 15 * time.Second}},
+		{"negative max_flows", config.FlowHistory{MaxFlows: -1}, 0, nil},
+		{"bad step", config.FlowHistory{Resolutions: res("1x", "1m")}, 0, nil},
+		{"bad retention", config.FlowHistory{Resolutions: res("1s", "")}, 0, nil},
+		{"step below a second", config.FlowHistory{Resolutions: res("500ms", "1m")}, 0, nil},
+		{"fractional step", config.FlowHistory{Resolutions: res("1500ms", "1m")}, 0, nil},
+		{"retention below the step", config.FlowHistory{Resolutions: res("1m", "30s")}, 0, nil},
+		{"step not a multiple", config.FlowHistory{Resolutions: res("2s", "1m", "3s", "1h")}, 0, nil},
+	} {
+		maxFlows, resolutions, err := parseHistoryConfig(tc.cfg)
+		if tc.steps == nil {
+			if err == nil {
+				t.Errorf("%s: accepted as %v", tc.name, resolutions)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if maxFlows != tc.maxFlows || len(resolutions) != len(tc.steps) {
+			t.Errorf("%s: %d flows at %v, want %d at %v", tc.name, maxFlows, resolutions, tc.maxFlows, tc.steps)
+			continue
+		}
+		for i, step := range tc.steps {
+			if resolutions[i].step != step {
+				t.Errorf("%s: resolution %d steps %v, want %v", tc.name, i, resolutions[i].step, step)
+			}
+		}
+	}
+}
+
+func TestHistoryRingWraparound(t *testing.T) {
+	r := historyRing{historyResolution: historyResolution{step: time.Second, retention: 3 * time.Second}}
+	if _, ok := r.last(); ok {
+		t.Fatal("empty ring has a last point")
+	}
+	for end := int64(1); end <= 5; end++ {
+		r.push(historyPoint{end: end})
+		if last, _ := r.last(); last.end != end {
+			t.Fatalf("last point ends at %d, want %d", last.end, end)
+		}
+	}
+	var ends []int64
+	r.each(func(p historyPoint) { ends = append(ends, p.end) })
+	if len(ends) != 3 || ends[0] != 3 || ends[1] != 4 || ends[2] != 5 {
+		t.Errorf("ring holds %v, want [3 4 5]", ends)
+	}
+}
+
+// historyStart is the synthetic time the recorded traffic starts at, a
+// multiple of every step of the tests
+var historyStart = time.Unix(1_700_000_040, 0)
+
+// recordTraffic records a flow that moves rate(s) bytes and a tenth as many
+// packets in its s-th second, from historyStart on for secs seconds
+func recordTraffic(fh *flowHistory, secs int, rate func(s int) uint64) {
+	var d flowmeasuredata
+	for s := 0; s <= secs; s++ {
+		if s > 0 {
+			d.Rx_Bytes += rate(s)
+			d.Rx_Packet += rate(s) / 10
+		}
+		fh.record(historyStart.Add(time.Duration(s)*time.Second), d)
+	}
+}
+
+// TestFlowHistoryPeaks checks that the coarse resolutions keep the highest
+// speed of the fine steps they cover, and start over at every point
+func TestFlowHistoryPeaks(t *testing.T) {
+	fh := newFlowHistory([]historyResolution{
+		{step: time.Second, retention: time.Minute},
+		{step: 10 * time.Second, retention: time.Hour},
+	})
+	recordTraffic(fh, 30, func(s int) uint64 {
+		if s == 5 {
+			return 5000
+		}
+		return 1000
+	})
+	// Measuring again within a step adds no point
+	fh.record(historyStart.Add(30*time.Second+500*time.Millisecond), flowmeasuredata{Rx_Bytes: 1 << 40})
+
+	var fine []historyPoint
+	fh.rings[0].each(func(p historyPoint) { fine = append(fine, p) })
+	if len(fine) != 31 || fine[5].rxPeak != 5000*8 || fine[6].rxPeak != 1000*8 || fine[0].rxPeak != 0 {
+		t.Errorf("%d fine points, peaks %g, %g and %g; want 31, 40000, 8000 and 0", len(fine), fine[5].rxPeak, fine[6].rxPeak, fine[0].rxPeak)
+	}
+	var coarse []historyPoint
+	fh.rings[1].each(func(p historyPoint) { coarse = append(coarse, p) })
+	want := []float32{0, 5000 * 8, 1000 * 8, 1000 * 8}
+	if len(coarse) != len(want) {
+		t.Fatalf("%d coarse points, want %d", len(coarse), len(want))
+	}
+	for i, p := range coarse {
+		if p.rxPeak != want[i] || p.end != historyStart.Unix()+int64(10*i) {
+			t.Errorf("coarse point %d: peak %g at %d, want %g at %d", i, p.rxPeak, p.end, want[i], historyStart.Unix()+int64(10*i))
+		}
+	}
+	if last := coarse[len(coarse)-1]; last.rxBytes != 30000+4000 || last.rxPackets != 3400 {
+		t.Errorf("last coarse point holds %d bytes and %d packets", last.rxBytes, last.rxPackets)
+	}
+}
+
+// TestHistoryQuery checks the resolution and step a query is answered at and
+// the rates of its points
+func TestHistoryQuery(t *testing.T) {
+	h, err := newHistoryRecorder(rule.NewStore(4), config.FlowHistory{Resolutions: []config.HistoryResolution{
+		{Step: "1s", Retention: "1m"},
+		{Step: "10s", Retention: "1h"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fh := newFlowHistory(h.resolutions)
+	h.flows["0x1"] = fh
+	// 1000 bytes a second, and 4000 in the 591st second
+	recordTraffic(fh, 600, func(s int) uint64 {
+		if s == 591 {
+			return 4000
+		}
+		return 1000
+	})
+	now := historyStart.Add(600 * time.Second)
+	ago := func(secs int) time.Time { return now.Add(-time.Duration(secs) * time.Second) }
+
+	for _, tc := range []struct {
+		name              string
+		start             time.Time
+		step              time.Duration
+		resolution, steps uint32 // Seconds of the resolution and of the steps
+		points            int
+		firstEnd          time.Time
+		firstRate         float64 // Packet rate of the first step
+	}{
+		{"fine ring holds start", ago(30), 0, 1, 1, 30, ago(29), 100},
+		{"step of the fine ring", ago(30), 3 * time.Second, 1, 3, 10, ago(27), 100},
+		{"step rounded up", ago(30), 2500 * time.Millisecond, 1, 3, 10, ago(27), 100},
+		{"coarse ring holds start", ago(300), 0, 10, 10, 30, ago(290), 100},
+		{"step rounded to the coarse ring", ago(300), 15 * time.Second, 10, 20, 15, ago(280), 100},
+		// A finer step than the coarse ring stays on the fine ring, which
+		// holds the last minute only and no baseline for its first step
+		{"no coarser than step", ago(300), time.Second, 1, 1, 60, ago(59), 0},
+	} {
+		reply, err := h.query("0x1", tc.start, now, tc.step, now)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if reply.GetResolutionSeconds() != tc.resolution || reply.GetStepSeconds() != tc.steps || len(reply.GetPoints()) != tc.points {
+			t.Errorf("%s: %d points of %d s from the %d s resolution, want %d of %d s from %d s", tc.name,
+				len(reply.GetPoints()), reply.GetStepSeconds(), reply.GetResolutionSeconds(), tc.points, tc.steps, tc.resolution)
+			continue
+		}
+		first := reply.GetPoints()[0]
+		if first.GetUnix() != tc.firstEnd.Unix() {
+			t.Errorf("%s: first point ends at %d, want %d", tc.name, first.GetUnix(), tc.firstEnd.Unix())
+		}
+		// The first step is rated against the last point before start
+		if first.GetRxPacketRate() != tc.firstRate {
+			t.Errorf("%s: first step at %g packet/s, want %g", tc.name, first.GetRxPacketRate(), tc.firstRate)
+		}
+	}
+
+	// The burst raises the rate of its 3 s step by 1 kB/s and its peak to 4 kB/s
+	reply, _ := h.query("0x1", ago(12), now, 3*time.Second, now)
+	burst := reply.GetPoints()[0]
+	if burst.GetUnix() != ago(9).Unix() || burst.GetRxSpeed() != 2000*8 || burst.GetRxSpeedPeak() != 4000*8 {
+		t.Errorf("step ending at %d: %g bit/s peaking at %g, want %d: %d peaking at %d",
+			burst.GetUnix(), burst.GetRxSpeed(), burst.GetRxSpeedPeak(), ago(9).Unix(), 2000*8, 4000*8)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		fseid      string
+		start, end time.Time
+		step       time.Duration
+		code       codes.Code
+	}{
+		{"empty range", "0x1", now, now, 0, codes.InvalidArgument},
+		{"too many points", "0x1", ago(historyMaxPoints + 1), now, time.Second, codes.InvalidArgument},
+		{"unknown flow", "0x2", ago(30), now, 0, codes.NotFound},
+	} {
+		if _, err := h.query(tc.fseid, tc.start, tc.end, tc.step, now); status.Code(err) != tc.code {
+			t.Errorf("%s: %v, want %s", tc.name, err, tc.code)
+		}
+	}
+	if _, err := h.query("0x1", ago(historyMaxPoints), now, time.Second, now); err != nil {
+		t.Errorf("%d points: %v", historyMaxPoints, err)
+	}
+}
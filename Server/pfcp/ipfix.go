@@ -0,0 +1,347 @@
+package pfcp
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"upf/Server/config"
+	"upf/Server/rule"
+	"upf/pkg/ipfix"
+)
+
+const (
+	ipfixRefresh         = time.Second      // How often the exported sessions follow the rule store
+	ipfixActiveTimeout   = time.Minute      // Default interval active sessions are exported at
+	ipfixTemplateRefresh = 10 * time.Minute // Default interval templates are resent at over UDP
+	ipfixDialTimeout     = 5 * time.Second  // Timeout of connecting to and writing to the collector
+	ipfixBacklog         = 64               // Exports queued for the collector
+	ipfixUDPSize         = 1400             // Largest UDP message, to stay below a 1500 octet MTU
+)
+
+// Enterprise-specific elements, numbered under the configured enterprise number
+const (
+	ipfixIMSI  uint16 = 1 // string
+	ipfixFSEID uint16 = 2 // string
+	ipfixDNN   uint16 = 3 // string
+	ipfixTEID  uint16 = 4 // unsigned32, N3 TEID of the uplink PDRs
+)
+
+// Templates of the uplink and downlink records of a session. The UE address
+// is the source of uplink traffic and the destination of downlink traffic.
+const (
+	ipfixUplinkTemplate   = 256
+	ipfixDownlinkTemplate = 257
+)
+
+// ipfixTemplates returns the uplink and downlink templates, in that order
+func ipfixTemplates(enterprise uint32) []ipfix.Template {
+	fields := func(ueAddress uint16) []ipfix.Field {
+		return []ipfix.Field{
+			{ID: ipfix.IEFlowStartMilliseconds, Length: 8},
+			{ID: ipfix.IEFlowEndMilliseconds, Length: 8},
+			{ID: ipfix.IEOctetDeltaCount, Length: 8},
+			{ID: ipfix.IEPacketDeltaCount, Length: 8},
+			{ID: ueAddress, Length: 4},
+			{ID: ipfix.IEFlowDirection, Length: 1},
+			{ID: ipfix.IEFlowEndReason, Length: 1},
+			{ID: ipfixIMSI, Length: ipfix.VariableLength, Enterprise: enterprise},
+			{ID: ipfixFSEID, Length: ipfix.VariableLength, Enterprise: enterprise},
+			{ID: ipfixDNN, Length: ipfix.VariableLength, Enterprise: enterprise},
+			{ID: ipfixTEID, Length: 4, Enterprise: enterprise},
+		}
+	}
+	return []ipfix.Template{
+		{ID: ipfixUplinkTemplate, Fields: fields(ipfix.IESourceIPv4Address)},
+		{ID: ipfixDownlinkTemplate, Fields: fields(ipfix.IEDestinationIPv4Address)},
+	}
+}
+
+// ipfixSettings is the parsed ipfix block of the configuration
+type ipfixSettings struct {
+	network         string // udp or tcp
+	collector       string // host:port
+	activeTimeout   time.Duration
+	templateRefresh time.Duration
+	enterprise      uint32
+	domain          uint32
+}
+
+// parseIPFIXConfig checks the ipfix block and fills in its defaults
+func parseIPFIXConfig(cfg config.IPFIX) (ipfixSettings, error) {
+	s := ipfixSettings{
+		network:         cfg.Transport,
+		collector:       cfg.Collector,
+		activeTimeout:   ipfixActiveTimeout,
+		templateRefresh: ipfixTemplateRefresh,
+		enterprise:      cfg.EnterpriseNumber,
+		domain:          cfg.ObservationDomain,
+	}
+	if s.network == "" {
+		s.network = "udp"
+	}
+	if s.network != "udp" && s.network != "tcp" {
+		return s, fmt.Errorf("unknown transport %q, want udp or tcp", cfg.Transport)
+	}
+	if _, _, err := net.SplitHostPort(s.collector); err != nil {
+		return s, fmt.Errorf("invalid collector %q: %v", cfg.Collector, err)
+	}
+	for _, d := range []struct {
+		name  string
+		value string
+		into  *time.Duration
+	}{
+		{"active_timeout", cfg.ActiveTimeout, &s.activeTimeout},
+		{"template_refresh", cfg.TemplateRefresh, &s.templateRefresh},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil || v < time.Second {
+			return s, fmt.Errorf("invalid %s %q, want a duration of at least 1s", d.name, d.value)
+		}
+		*d.into = v
+	}
+	if s.enterprise == 0 {
+		s.enterprise = ipfix.ExampleEnterprise
+	}
+	return s, nil
+}
+
+// ipfixFlow is a session whose traffic is exported
+type ipfixFlow struct {
+	imsi     string
+	dnn      string
+	ueIP     net.IP
+	teid     uint32
+	seen     bool        // cur holds observed counters
+	cur      aggCounters // Latest counters
+	exported aggCounters // Counters covered by the records sent so far
+	from     time.Time   // Start of the traffic not yet exported
+}
+
+// records returns the uplink and downlink records of the traffic a flow
+// moved since its last export
+func (f *ipfixFlow) records(fseid string, end time.Time, reason uint8) (ul, dl ipfix.Record) {
+	d := f.cur.since(f.exported)
+	ul = ipfix.Record{f.from, end, d.rxBytes, d.rxPackets, f.ueIP, ipfix.DirectionIngress, reason, f.imsi, fseid, f.dnn, f.teid}
+	dl = ipfix.Record{f.from, end, d.txBytes, d.txPackets, f.ueIP, ipfix.DirectionEgress, reason, f.imsi, fseid, f.dnn, f.teid}
+	return ul, dl
+}
+
+// ipfixExporter exports the traffic of every session in the rule store as
+// IPFIX flow records: the deltas of active sessions every active timeout and
+// a last pair of records when a session is deleted
+type ipfixExporter struct {
+	rules   *rule.Store
+	timeout time.Duration
+	sender  *ipfixSender
+
+	mu        sync.Mutex
+	flows     map[string]*ipfixFlow
+	watch     []string // F-SEIDs of sessions, for the flow engine
+	refreshed time.Time
+	exported  time.Time
+}
+
+// newIPFIXExporter starts exporting the sessions of the rule store to a collector
+func newIPFIXExporter(rules *rule.Store, s ipfixSettings) *ipfixExporter {
+	return &ipfixExporter{
+		rules:   rules,
+		timeout: s.activeTimeout,
+		sender:  newIPFIXSender(s),
+		flows:   make(map[string]*ipfixFlow),
+	}
+}
+
+// watching returns the flows of the exported sessions
+func (e *ipfixExporter) watching() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.watch
+}
+
+// observe takes the latest counters and queues the records due for export
+func (e *ipfixExporter) observe(now time.Time, counters map[string]flowmeasuredata) {
+	var ul, dl []ipfix.Record
+	if now.Sub(e.refreshed) >= ipfixRefresh {
+		ul, dl = e.refresh(now)
+	}
+
+	e.mu.Lock()
+	for fseid, d := range counters {
+		f, ok := e.flows[fseid]
+		if !ok {
+			continue
+		}
+		f.cur = countersOf(d)
+		if !f.seen {
+			f.seen, f.from = true, now
+		}
+	}
+
+	if e.exported.IsZero() {
+		e.exported = now
+	}
+	if now.Sub(e.exported) >= e.timeout {
+		e.exported = now
+		for fseid, f := range e.flows {
+			// Idle sessions are left for a later record covering their idle time
+			if !f.seen || f.cur.since(f.exported) == (aggCounters{}) {
+				continue
+			}
+			u, d := f.records(fseid, now, ipfix.EndActiveTimeout)
+			ul, dl = append(ul, u), append(dl, d)
+			f.exported, f.from = f.cur, now
+		}
+	}
+	e.mu.Unlock()
+
+	if len(ul) > 0 {
+		e.sender.send(ipfixExport{at: now, records: [][]ipfix.Record{ul, dl}})
+	}
+}
+
+// refresh follows the sessions of the rule store and returns the last
+// records of the sessions that were deleted
+func (e *ipfixExporter) refresh(now time.Time) (ul, dl []ipfix.Record) {
+	e.refreshed = now
+	current := make(map[string]*ipfixFlow)
+	e.rules.Range(func(s rule.Sessions) bool {
+		f := &ipfixFlow{imsi: s.IMSI(), dnn: s.DNN(), ueIP: net.IPv4zero.To4()}
+		if ip := net.ParseIP(s.UEIP()).To4(); ip != nil {
+			f.ueIP = ip
+		}
+		if teid, err := strconv.ParseUint(s.TEID(), 0, 32); err == nil {
+			f.teid = uint32(teid)
+		}
+		current[s.FSEID()] = f
+		return true
+	})
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for fseid, f := range e.flows {
+		if _, ok := current[fseid]; ok {
+			continue
+		}
+		if f.seen {
+			u, d := f.records(fseid, now, ipfix.EndOfFlow)
+			ul, dl = append(ul, u), append(dl, d)
+		}
+		delete(e.flows, fseid)
+	}
+	e.watch = make([]string, 0, len(current))
+	for fseid, found := range current {
+		f, ok := e.flows[fseid]
+		if !ok {
+			f = found
+			e.flows[fseid] = f
+		}
+		f.imsi, f.dnn, f.ueIP, f.teid = found.imsi, found.dnn, found.ueIP, found.teid
+		e.watch = append(e.watch, fseid)
+	}
+	return ul, dl
+}
+
+// ipfixExport holds records due for export, one slice per template
+type ipfixExport struct {
+	at      time.Time
+	records [][]ipfix.Record
+}
+
+// ipfixSender sends exports to the collector from a queue, so a slow or
+// unreachable collector does not hold up the flow engine. It connects on the
+// first export and again after a failure, announcing the templates on every
+// new connection and, over UDP, every template refresh.
+type ipfixSender struct {
+	network   string
+	collector string
+	refresh   time.Duration
+	templates []ipfix.Template
+	encoder   ipfix.Encoder
+	queue     chan ipfixExport
+	conn      net.Conn
+	announced time.Time // When the templates were last sent over conn
+}
+
+// newIPFIXSender starts sending to the collector
+func newIPFIXSender(s ipfixSettings) *ipfixSender {
+	sender := &ipfixSender{
+		network:   s.network,
+		collector: s.collector,
+		refresh:   s.templateRefresh,
+		templates: ipfixTemplates(s.enterprise),
+		encoder:   ipfix.Encoder{Domain: s.domain},
+		queue:     make(chan ipfixExport, ipfixBacklog),
+	}
+	if s.network == "udp" {
+		sender.encoder.MaxSize = ipfixUDPSize
+	}
+	go sender.run()
+	return sender
+}
+
+// send queues an export, dropping it if the queue is full
+func (s *ipfixSender) send(ex ipfixExport) {
+	select {
+	case s.queue <- ex:
+	default:
+		log.Printf("⚠️ %d IPFIX records dropped, the export queue is full", len(ex.records[0])+len(ex.records[1]))
+	}
+}
+
+// run sends the queued exports, reconnecting after a failure
+func (s *ipfixSender) run() {
+	for ex := range s.queue {
+		if err := s.export(ex); err != nil {
+			log.Printf("❌ IPFIX export to %s failed: %v", s.collector, err)
+			if s.conn != nil {
+				s.conn.Close()
+				s.conn = nil
+			}
+		}
+	}
+}
+
+// export sends the records of one export, preceded by the templates when due
+func (s *ipfixSender) export(ex ipfixExport) error {
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.collector, ipfixDialTimeout)
+		if err != nil {
+			return err
+		}
+		s.conn, s.announced = conn, time.Time{}
+		s.encoder.Reset()
+	}
+	if err := s.conn.SetWriteDeadline(time.Now().Add(ipfixDialTimeout)); err != nil {
+		return err
+	}
+
+	if s.announced.IsZero() || (s.network == "udp" && ex.at.Sub(s.announced) >= s.refresh) {
+		msg, err := s.encoder.Templates(ex.at, s.templates...)
+		if err != nil {
+			return err
+		}
+		if _, err := s.conn.Write(msg); err != nil {
+			return err
+		}
+		s.announced = ex.at
+	}
+	for i, t := range s.templates {
+		msgs, err := s.encoder.Data(ex.at, t, ex.records[i])
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if _, err := s.conn.Write(msg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package pfcp
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"upf/Server/rule"
+	"upf/pkg/ipfix"
+	pb "upf/pkg/proto"
+)
+
+// ipfixMessage is an IPFIX message as a collector decodes it
+type ipfixMessage struct {
+	exported  uint32 // Export time in seconds
+	sequence  uint32
+	domain    uint32
+	templates []ipfix.Template
+	setID     uint16          // Set ID of the data set, 0 for template messages
+	records   []ipfixReceived // Records of the data set
+}
+
+// ipfixElement names an Information Element
+type ipfixElement struct {
+	id         uint16
+	enterprise uint32
+}
+
+// ipfixReceived holds the raw values of a data record by element
+type ipfixReceived map[ipfixElement][]byte
+
+// uint reads an unsigned value of any length
+func (r ipfixReceived) uint(id uint16, enterprise uint32) uint64 {
+	var n uint64
+	for _, b := range r[ipfixElement{id, enterprise}] {
+		n = n<<8 | uint64(b)
+	}
+	return n
+}
+
+// ipfixCollector receives the messages of one exporter over UDP or TCP and
+// decodes them with the templates announced so far
+type ipfixCollector struct {
+	t         *testing.T
+	addr      string
+	raw       chan []byte
+	templates map[uint16]ipfix.Template
+}
+
+// newIPFIXCollector listens on a loopback port
+func newIPFIXCollector(t *testing.T, network string) *ipfixCollector {
+	c := &ipfixCollector{t: t, raw: make(chan []byte, 64), templates: make(map[uint16]ipfix.Template)}
+	switch network {
+	case "udp":
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		c.addr = conn.LocalAddr().String()
+		go func() {
+			buf := make([]byte, 0xffff)
+			for {
+				n, _, err := conn.ReadFrom(buf)
+				if err != nil {
+					return
+				}
+				c.raw <- append([]byte(nil), buf[:n]...)
+			}
+		}()
+	case "tcp":
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { ln.Close() })
+		c.addr = ln.Addr().String()
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			// Messages follow each other on the stream, delimited by their length
+			for {
+				head := make([]byte, 4)
+				if _, err := io.ReadFull(conn, head); err != nil {
+					return
+				}
+				msg := make([]byte, binary.BigEndian.Uint16(head[2:]))
+				copy(msg, head)
+				if _, err := io.ReadFull(conn, msg[4:]); err != nil {
+					return
+				}
+				c.raw <- msg
+			}
+		}()
+	}
+	return c
+}
+
+// next decodes the next message
+func (c *ipfixCollector) next() ipfixMessage {
+	c.t.Helper()
+	var b []byte
+	select {
+	case b = <-c.raw:
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("no IPFIX message received")
+	}
+	if len(b) < 16 || binary.BigEndian.Uint16(b) != ipfix.Version || int(binary.BigEndian.Uint16(b[2:])) != len(b) {
+		c.t.Fatalf("malformed message header % x", b[:min(len(b), 16)])
+	}
+	m := ipfixMessage{
+		exported: binary.BigEndian.Uint32(b[4:]),
+		sequence: binary.BigEndian.Uint32(b[8:]),
+		domain:   binary.BigEndian.Uint32(b[12:]),
+	}
+	for rest := b[16:]; len(rest) > 0; {
+		id, n := binary.BigEndian.Uint16(rest), int(binary.BigEndian.Uint16(rest[2:]))
+		if n < 4 || n > len(rest) {
+			c.t.Fatalf("set %d of %d octets in %d", id, n, len(rest))
+		}
+		set := rest[4:n]
+		rest = rest[n:]
+		if id == 2 {
+			for len(set) > 0 {
+				tmpl := ipfix.Template{ID: binary.BigEndian.Uint16(set)}
+				count := int(binary.BigEndian.Uint16(set[2:]))
+				set = set[4:]
+				for range count {
+					f := ipfix.Field{ID: binary.BigEndian.Uint16(set), Length: binary.BigEndian.Uint16(set[2:])}
+					set = set[4:]
+					if f.ID&0x8000 != 0 {
+						f.ID &^= 0x8000
+						f.Enterprise = binary.BigEndian.Uint32(set)
+						set = set[4:]
+					}
+					tmpl.Fields = append(tmpl.Fields, f)
+				}
+				c.templates[tmpl.ID] = tmpl
+				m.templates = append(m.templates, tmpl)
+			}
+			continue
+		}
+		tmpl, ok := c.templates[id]
+		if !ok {
+			c.t.Fatalf("data set %d before its template", id)
+		}
+		m.setID = id
+		for len(set) > 0 {
+			r := make(ipfixReceived)
+			for _, f := range tmpl.Fields {
+				n := int(f.Length)
+				if f.Length == ipfix.VariableLength {
+					n, set = int(set[0]), set[1:]
+					if n == 255 {
+						n, set = int(binary.BigEndian.Uint16(set)), set[2:]
+					}
+				}
+				r[ipfixElement{f.ID, f.Enterprise}], set = set[:n], set[n:]
+			}
+			m.records = append(m.records, r)
+		}
+	}
+	return m
+}
+
+// TestIPFIXExport exports a session through its life to a loopback collector
+// over each transport and checks every message the collector decodes
+func TestIPFIXExport(t *testing.T) {
+	const (
+		fseid      = "0x77"
+		imsi       = "001010000000077"
+		dnn        = "internet"
+		enterprise = ipfix.ExampleEnterprise
+		domain     = 7
+	)
+	start := time.Unix(1_700_000_000, 0)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	// Deltas of the exports: a full active timeout, one with uplink traffic
+	// only, and the last records when the session is deleted
+	exports := []struct {
+		from, to time.Time
+		ul, dl   [2]uint64 // Octets and packets
+		reason   uint8
+	}{
+		{at(0), at(10 * time.Second), [2]uint64{3000, 30}, [2]uint64{9000, 40}, ipfix.EndActiveTimeout},
+		{at(10 * time.Second), at(20 * time.Second), [2]uint64{500, 5}, [2]uint64{0, 0}, ipfix.EndActiveTimeout},
+		{at(20 * time.Second), at(21500 * time.Millisecond), [2]uint64{500, 5}, [2]uint64{0, 0}, ipfix.EndOfFlow},
+	}
+
+	for _, network := range []string{"udp", "tcp"} {
+		t.Run(network, func(t *testing.T) {
+			collector := newIPFIXCollector(t, network)
+			rules := rule.NewStore(4)
+			err := rules.PutRules(&pb.Rulestruct{
+				Pdr:  &pb.Pdrstruct{Fsied: fseid, PdrId: []string{"pdr1"}, UeIp: "10.60.0.7", Teid: "0x1234"},
+				Far:  &pb.Farstruct{Fsied: fseid, FarId: "far1", ApplyAction: "forward"},
+				Qer:  &pb.Qerstruct{Fsied: fseid},
+				Urr:  &pb.Urrstruct{Fsied: fseid},
+				Imsi: imsi,
+				Dnn:  dnn,
+			}, rule.Change{Caller: "test"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := newIPFIXExporter(rules, ipfixSettings{
+				network:         network,
+				collector:       collector.addr,
+				activeTimeout:   10 * time.Second,
+				templateRefresh: 10 * time.Second,
+				enterprise:      enterprise,
+				domain:          domain,
+			})
+
+			counters := func(rx, rxPackets, tx, txPackets uint64) map[string]flowmeasuredata {
+				return map[string]flowmeasuredata{fseid: {Rx_Bytes: rx, Rx_Packet: rxPackets, Tx_Bytes: tx, Tx_Packet: txPackets}}
+			}
+			e.observe(at(0), counters(0, 0, 0, 0))
+			e.observe(at(10*time.Second), counters(3000, 30, 9000, 40))
+			e.observe(at(20*time.Second), counters(3500, 35, 9000, 40))
+			e.observe(at(20500*time.Millisecond), counters(4000, 40, 9000, 40))
+			rules.Delete(fseid, rule.Change{Caller: "test"})
+			e.observe(at(21500*time.Millisecond), nil)
+
+			var sequence uint32
+			for i, ex := range exports {
+				// Templates open the session, and over UDP are resent every template refresh
+				if i == 0 || network == "udp" && i == 1 {
+					m := collector.next()
+					if !reflect.DeepEqual(m.templates, ipfixTemplates(enterprise)) {
+						t.Fatalf("export %d: templates %+v, want %+v", i, m.templates, ipfixTemplates(enterprise))
+					}
+					if m.sequence != sequence || m.domain != domain {
+						t.Errorf("export %d: template message sequence %d of domain %d, want %d of %d", i, m.sequence, m.domain, sequence, domain)
+					}
+				}
+				for _, dir := range []struct {
+					template uint16
+					ue       uint16
+					counts   [2]uint64
+					flowDir  uint8
+				}{
+					{ipfixUplinkTemplate, ipfix.IESourceIPv4Address, ex.ul, ipfix.DirectionIngress},
+					{ipfixDownlinkTemplate, ipfix.IEDestinationIPv4Address, ex.dl, ipfix.DirectionEgress},
+				} {
+					m := collector.next()
+					if m.setID != dir.template || len(m.records) != 1 {
+						t.Fatalf("export %d: %d records of set %d, want 1 of %d", i, len(m.records), m.setID, dir.template)
+					}
+					if m.sequence != sequence || m.domain != domain || m.exported != uint32(ex.to.Unix()) {
+						t.Errorf("export %d set %d: sequence %d, domain %d, exported %d, want %d, %d, %d",
+							i, dir.template, m.sequence, m.domain, m.exported, sequence, domain, ex.to.Unix())
+					}
+					sequence++
+
+					r := m.records[0]
+					for _, check := range []struct {
+						name      string
+						got, want uint64
+					}{
+						{"start", r.uint(ipfix.IEFlowStartMilliseconds, 0), uint64(ex.from.UnixMilli())},
+						{"end", r.uint(ipfix.IEFlowEndMilliseconds, 0), uint64(ex.to.UnixMilli())},
+						{"octets", r.uint(ipfix.IEOctetDeltaCount, 0), dir.counts[0]},
+						{"packets", r.uint(ipfix.IEPacketDeltaCount, 0), dir.counts[1]},
+						{"direction", r.uint(ipfix.IEFlowDirection, 0), uint64(dir.flowDir)},
+						{"end reason", r.uint(ipfix.IEFlowEndReason, 0), uint64(ex.reason)},
+						{"TEID", r.uint(ipfixTEID, enterprise), 0x1234},
+					} {
+						if check.got != check.want {
+							t.Errorf("export %d set %d: %s %d, want %d", i, dir.template, check.name, check.got, check.want)
+						}
+					}
+					if ue := net.IP(r[ipfixElement{dir.ue, 0}]); !ue.Equal(net.IPv4(10, 60, 0, 7)) {
+						t.Errorf("export %d set %d: UE address %v, want 10.60.0.7", i, dir.template, ue)
+					}
+					for _, s := range []struct {
+						id   uint16
+						want string
+					}{{ipfixIMSI, imsi}, {ipfixFSEID, fseid}, {ipfixDNN, dnn}} {
+						if got := string(r[ipfixElement{s.id, enterprise}]); got != s.want {
+							t.Errorf("export %d set %d: element %d is %q, want %q", i, dir.template, s.id, got, s.want)
+						}
+					}
+				}
+			}
+			select {
+			case b := <-collector.raw:
+				t.Errorf("unexpected message % x", b)
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package pfcp
+
+import (
+	"upf/Server/metrics"
+	"upf/Server/rule"
+	wire "upf/pkg/pfcp"
+	pb "upf/pkg/proto"
+)
+
+// Directions of PFCP messages as seen by the UPF
+const (
+	directionReceived = "received"
+	directionSent     = "sent"
+)
+
+var (
+	pfcpMessages = metrics.NewCounterVec("upf_pfcp_messages_total",
+		"PFCP messages exchanged on N4, by direction and message type", "direction", "type")
+	pfcpCauses = metrics.NewCounterVec("upf_pfcp_responses_total",
+		"PFCP responses carrying a Cause IE, by direction, message type and cause", "direction", "type", "cause")
+)
+
+// countMessage counts a PFCP message and, for responses, its cause
+func countMessage(direction string, m *wire.Message) {
+	pfcpMessages.Inc(direction, m.Type.String())
+	if m.Type.IsRequest() {
+		return
+	}
+	if cause, err := m.Find(wire.IECause).Cause(); err == nil {
+		pfcpCauses.Inc(direction, m.Type.String(), cause.String())
+	}
+}
+
+// registerMetrics exposes the traffic of the aggregates, the sessions of the
+// rule store and its table usage. Per-session series are only exported when
+// asked for, since they add series for every F-SEID.
+func registerMetrics(aggregates *aggregator, rules *rule.Store, perSession bool) {
+	metrics.NewCounterFunc("upf_dnn_bytes_total", "Bytes moved by the sessions of each DNN, by direction",
+		[]string{"dnn", "direction"}, trafficCollector(aggregates, groupDNN, true))
+	metrics.NewCounterFunc("upf_dnn_packets_total", "Packets moved by the sessions of each DNN, by direction",
+		[]string{"dnn", "direction"}, trafficCollector(aggregates, groupDNN, false))
+	metrics.NewCounterFunc("upf_slice_bytes_total", "Bytes moved by the sessions of each slice, by direction",
+		[]string{"slice", "direction"}, trafficCollector(aggregates, groupSlice, true))
+	metrics.NewCounterFunc("upf_slice_packets_total", "Packets moved by the sessions of each slice, by direction",
+		[]string{"slice", "direction"}, trafficCollector(aggregates, groupSlice, false))
+	if perSession {
+		metrics.NewCounterFunc("upf_session_bytes_total", "Bytes moved by each session, by direction",
+			[]string{"fseid", "direction"}, trafficCollector(aggregates, groupFlow, true))
+		metrics.NewCounterFunc("upf_session_packets_total", "Packets moved by each session, by direction",
+			[]string{"fseid", "direction"}, trafficCollector(aggregates, groupFlow, false))
+	}
+
+	metrics.NewGaugeFunc("upf_sessions", "Sessions in the rule store, by DNN and the action of their FAR (forward, buffer or drop)",
+		[]string{"dnn", "state"}, func(emit metrics.Emit) {
+			type key struct{ dnn, state string }
+			counts := make(map[key]int)
+			rules.Range(func(s rule.Sessions) bool {
+				counts[key{dnn: s.DNN(), state: s.ApplyAction()}]++
+				return true
+			})
+			for k, n := range counts {
+				emit(float64(n), k.dnn, k.state)
+			}
+		})
+	metrics.NewGaugeFunc("upf_table_entries", "Entries in use in each lookup table",
+		[]string{"table"}, func(emit metrics.Emit) {
+			for _, u := range rules.TableUsage() {
+				emit(float64(u.Used), u.Table)
+			}
+		})
+	metrics.NewGaugeFunc("upf_table_capacity", "Configured size of each lookup table from table_sizes, 0 if unlimited",
+		[]string{"table"}, func(emit metrics.Emit) {
+			for _, u := range rules.TableUsage() {
+				emit(float64(u.Capacity), u.Table)
+			}
+		})
+}
+
+// trafficCollector reports the bytes or packets of every group of one kind,
+// received counters as uplink and transmitted ones as downlink
+func trafficCollector(aggregates *aggregator, by string, bytes bool) func(metrics.Emit) {
+	return func(emit metrics.Emit) {
+		reply, err := aggregates.snapshot(by, nil)
+		if err != nil {
+			return
+		}
+		for _, g := range reply.GetGroups() {
+			ul, dl := g.GetRxPackets(), g.GetTxPackets()
+			if bytes {
+				ul, dl = g.GetRxBytes(), g.GetTxBytes()
+			}
+			emit(float64(ul), g.GetKey(), "uplink")
+			emit(float64(dl), g.GetKey(), "downlink")
+		}
+	}
+}
+
+// registerSliceMetrics exposes the rate limits and counters of the slice policers
+func registerSliceMetrics(slices *slicePolicers) {
+	collect := func(value func(*pb.SlicePolicer) uint64) func(metrics.Emit) {
+		return func(emit metrics.Emit) {
+			for _, p := range slices.list() {
+				emit(float64(value(p)), p.Slice, p.Direction)
+			}
+		}
+	}
+	metrics.NewGaugeFunc("upf_slice_rate_limit_bps", "Rate limit of each slice policer in bit/s, by direction, 0 if unlimited",
+		[]string{"slice", "direction"}, collect((*pb.SlicePolicer).GetRateBps))
+	metrics.NewCounterFunc("upf_slice_dropped_packets_total", "Packets the policer of each slice dropped, by direction",
+		[]string{"slice", "direction"}, collect((*pb.SlicePolicer).GetDroppedPackets))
+	metrics.NewCounterFunc("upf_slice_dropped_bytes_total", "Bytes the policer of each slice dropped, by direction",
+		[]string{"slice", "direction"}, collect((*pb.SlicePolicer).GetDroppedBytes))
+}
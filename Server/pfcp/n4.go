@@ -0,0 +1,1049 @@
+package pfcp
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"upf/Server/config"
+	"upf/Server/imsi"
+	"upf/Server/rule"
+	wire "upf/pkg/pfcp"
+	pb "upf/pkg/proto"
+)
+
+// N4Address is the UDP address the N4 endpoint listens on
+var N4Address = fmt.Sprintf(":%d", wire.Port)
+
+// n4PDR is a Packet Detection Rule received over N4
+type n4PDR struct {
+	id              uint16
+	precedence      uint32
+	source          uint8  // Source interface of the PDI
+	teid            uint32 // Local F-TEID, if hasTEID
+	hasTEID         bool
+	ueIP            net.IP
+	networkInstance string
+	sdfFilters      []wire.SDFFilter         // SDF filters of the PDI
+	ohr             *wire.OuterHeaderRemoval // Outer header to strip, if any
+	farID           uint32
+	qerIDs          []uint32
+	urrIDs          []uint32
+}
+
+// n4FAR is a Forwarding Action Rule received over N4
+type n4FAR struct {
+	id              uint32
+	action          uint8 // Apply Action flags
+	destination     uint8 // Destination interface of the forwarding parameters
+	networkInstance string
+	ohc             *wire.OuterHeaderCreation
+}
+
+// n4QER is a QoS Enforcement Rule received over N4
+type n4QER struct {
+	id  uint32
+	qfi uint8
+	mbr wire.BitRate
+	gbr wire.BitRate
+}
+
+// n4URR is a Usage Reporting Rule received over N4
+type n4URR struct {
+	id            uint32
+	method        uint8        // Measurement Method flags
+	triggers      uint32       // Reporting Triggers flags
+	volThreshold  *wire.Volume // Volume Threshold, if any
+	volQuota      *wire.Volume // Volume Quota, if any
+	timeThreshold time.Duration
+	timeQuota     time.Duration
+	period        time.Duration // Measurement Period of periodic reporting
+}
+
+// n4Session is the N4 view of a PFCP session. Its rules are flattened into
+// the shared rule store under the F-SEID formed from the local SEID.
+type n4Session struct {
+	localSEID  uint64 // SEID allocated by the UPF
+	remoteSEID uint64 // SEID of the CP function
+	nodeID     string // Node ID of the owning association
+	imsi       string
+	dnn        string
+	pdrs       map[uint16]n4PDR
+	fars       map[uint32]n4FAR
+	qers       map[uint32]n4QER
+	urrs       map[uint32]n4URR
+}
+
+// fseid returns the key of the session in the shared stores
+func (s *n4Session) fseid() string {
+	return fmt.Sprintf("0x%x", s.localSEID)
+}
+
+// clone returns a copy that can be modified without affecting s
+func (s *n4Session) clone() *n4Session {
+	c := *s
+	c.pdrs = make(map[uint16]n4PDR, len(s.pdrs))
+	for k, v := range s.pdrs {
+		c.pdrs[k] = v
+	}
+	c.fars = make(map[uint32]n4FAR, len(s.fars))
+	for k, v := range s.fars {
+		c.fars[k] = v
+	}
+	c.qers = make(map[uint32]n4QER, len(s.qers))
+	for k, v := range s.qers {
+		c.qers[k] = v
+	}
+	c.urrs = make(map[uint32]n4URR, len(s.urrs))
+	for k, v := range s.urrs {
+		c.urrs[k] = v
+	}
+	return &c
+}
+
+// causeError rejects a request with a PFCP cause and, optionally, the offending IE
+type causeError struct {
+	cause wire.Cause
+	ie    wire.IEType
+	msg   string
+}
+
+func (e *causeError) Error() string {
+	return e.msg
+}
+
+// missing reports a mandatory IE that is absent
+func missing(t wire.IEType, where string) error {
+	return &causeError{cause: wire.CauseMandatoryIEMissing, ie: t, msg: fmt.Sprintf("%s: IE %d missing", where, t)}
+}
+
+// incorrect reports a mandatory IE that could not be decoded
+func incorrect(t wire.IEType, where string, err error) error {
+	return &causeError{cause: wire.CauseMandatoryIEIncorrect, ie: t, msg: fmt.Sprintf("%s: IE %d: %v", where, t, err)}
+}
+
+// ieError reports a mandatory IE that failed to decode, telling absent IEs
+// apart from malformed ones
+func ieError(t wire.IEType, where string, err error) error {
+	if errors.Is(err, wire.ErrIEMissing) {
+		return missing(t, where)
+	}
+	return incorrect(t, where, err)
+}
+
+// n4Server answers PFCP requests from SMFs and provisions the shared
+// session and subscriber stores from the sessions they establish
+type n4Server struct {
+	transport   *wire.Transport
+	nodeID      string    // Node ID announced to peers
+	n3Addr      net.IP    // Address of UPF allocated F-TEIDs
+	defaultDNN  string    // DNN of sessions that do not name one
+	recovery    time.Time // Start time, announced as Recovery Time Stamp
+	rules       *rule.Store
+	subscribers *imsi.Store
+	usage       *usageMeter // Usage measurement of the URRs, nil if not metered
+
+	heartbeats        bool          // Send periodic heartbeats (enable_hbTimer)
+	heartbeatInterval time.Duration // Period of heartbeats and association retries
+	done              chan struct{} // Closed when the server stops
+
+	mu       sync.Mutex
+	peers    map[string]*peer      // Node ID -> peer
+	sessions map[uint64]*n4Session // Local SEID -> session
+	lastSEID uint64
+	lastTEID uint32
+}
+
+// newN4Server creates an N4 server configured from the UPF configuration
+func newN4Server(cfg *config.UPFConfig) *n4Server {
+	n := &n4Server{
+		defaultDNN:        cfg.CPInterface.DNN,
+		recovery:          time.Now(),
+		rules:             rule.DefaultStore(),
+		subscribers:       imsi.DefaultStore(),
+		heartbeats:        cfg.EnableHBTimer,
+		heartbeatInterval: heartbeatSettings(cfg),
+		done:              make(chan struct{}),
+		peers:             make(map[string]*peer),
+		sessions:          make(map[uint64]*n4Session),
+	}
+
+	// The N3 address doubles as Node ID; fall back to the host name
+	if ip := net.ParseIP(strings.Split(cfg.P4RTCInterface.AccessIP, "/")[0]); ip != nil {
+		n.n3Addr = ip.To4()
+		n.nodeID = ip.String()
+	} else if host, err := os.Hostname(); err == nil {
+		n.nodeID = host
+	} else {
+		n.nodeID = "upf"
+	}
+
+	// Peers listed in cpiface.peers are associated by the UPF itself
+	for _, entry := range cfg.CPInterface.Peers {
+		if err := n.addConfiguredPeer(entry); err != nil {
+			log.Printf("⚠️ Ignoring PFCP peer %q: %v", entry, err)
+		}
+	}
+	return n
+}
+
+// transportSettings reads the retransmission settings of the UPF configuration
+func transportSettings(cfg *config.UPFConfig) (int, time.Duration) {
+	retries, timeout := wire.DefaultRetries, wire.DefaultTimeout
+	if cfg.MaxReqRetries > 0 {
+		retries = cfg.MaxReqRetries
+	}
+	if cfg.RespTimeout != "" {
+		d, err := time.ParseDuration(cfg.RespTimeout)
+		if err != nil || d <= 0 {
+			log.Printf("⚠️ Invalid resp_timeout %q, using %s", cfg.RespTimeout, timeout)
+		} else {
+			timeout = d
+		}
+	}
+	return retries, timeout
+}
+
+// heartbeatSettings reads the heartbeat interval of the UPF configuration
+func heartbeatSettings(cfg *config.UPFConfig) time.Duration {
+	if cfg.HeartBeatInterval == "" {
+		return defaultHeartbeatInterval
+	}
+	d, err := time.ParseDuration(cfg.HeartBeatInterval)
+	if err != nil || d <= 0 {
+		log.Printf("⚠️ Invalid heart_beat_interval %q, using %s", cfg.HeartBeatInterval, defaultHeartbeatInterval)
+		return defaultHeartbeatInterval
+	}
+	return d
+}
+
+// handle counts a message and the response it is answered with
+func (n *n4Server) handle(m *wire.Message, addr *net.UDPAddr) *wire.Message {
+	countMessage(directionReceived, m)
+	resp := n.dispatch(m, addr)
+	if resp != nil {
+		countMessage(directionSent, resp)
+	}
+	return resp
+}
+
+// request sends a request to a peer and counts it and its response
+func (n *n4Server) request(addr *net.UDPAddr, m *wire.Message) (*wire.Message, error) {
+	countMessage(directionSent, m)
+	resp, err := n.transport.Request(addr, m)
+	if err == nil {
+		countMessage(directionReceived, resp)
+	}
+	return resp, err
+}
+
+// dispatch dispatches a request and returns its response, or nil if none is due
+func (n *n4Server) dispatch(m *wire.Message, addr *net.UDPAddr) *wire.Message {
+	if !m.Type.IsRequest() {
+		return nil
+	}
+	log.Printf("📥 %s from %s (seq %d)", m.Type, addr, m.Sequence)
+
+	switch m.Type {
+	case wire.MsgHeartbeatRequest:
+		return n.heartbeatRequest(m, addr)
+	case wire.MsgAssociationSetupRequest:
+		return n.associationSetup(m, addr)
+	case wire.MsgAssociationUpdateRequest:
+		return n.associationUpdate(m)
+	case wire.MsgAssociationReleaseRequest:
+		return n.associationRelease(m)
+	case wire.MsgSessionEstablishmentRequest:
+		return n.sessionEstablishment(m)
+	case wire.MsgSessionModificationRequest:
+		return n.sessionModification(m)
+	case wire.MsgSessionDeletionRequest:
+		return n.sessionDeletion(m)
+	}
+
+	log.Printf("⚠️ Unsupported PFCP message %s", m.Type)
+	return nil
+}
+
+// rejection builds the Cause IE and, if known, the Offending IE for err
+func rejection(err error) []*wire.IE {
+	var ce *causeError
+	if !errors.As(err, &ce) {
+		return []*wire.IE{wire.NewCause(wire.CauseRequestRejected)}
+	}
+	ies := []*wire.IE{wire.NewCause(ce.cause)}
+	if ce.ie != 0 {
+		ies = append(ies, wire.NewOffendingIE(ce.ie))
+	}
+	return ies
+}
+
+// peerNodeID decodes the mandatory Node ID of a node related request
+func peerNodeID(m *wire.Message) (string, error) {
+	ie := m.Find(wire.IENodeID)
+	if ie == nil {
+		return "", missing(wire.IENodeID, m.Type.String())
+	}
+	id, err := ie.NodeID()
+	if err != nil {
+		return "", incorrect(wire.IENodeID, m.Type.String(), err)
+	}
+	return id.String(), nil
+}
+
+// sessionEstablishment creates a session from its Create PDR/FAR/QER/URR IEs
+func (n *n4Server) sessionEstablishment(m *wire.Message) *wire.Message {
+	cpSEID := uint64(0)
+	reply := func(ies ...*wire.IE) *wire.Message {
+		return wire.NewMessage(wire.MsgSessionEstablishmentResponse, cpSEID, m.Sequence, ies...)
+	}
+	upNode := wire.NewNodeID(n.nodeID)
+
+	nodeID, err := peerNodeID(m)
+	if err != nil {
+		return reply(append([]*wire.IE{upNode}, rejection(err)...)...)
+	}
+	fseidIE := m.Find(wire.IEFSEID)
+	if fseidIE == nil {
+		return reply(append([]*wire.IE{upNode}, rejection(missing(wire.IEFSEID, m.Type.String()))...)...)
+	}
+	cp, err := fseidIE.FSEID()
+	if err != nil {
+		return reply(append([]*wire.IE{upNode}, rejection(incorrect(wire.IEFSEID, m.Type.String(), err))...)...)
+	}
+	cpSEID = cp.SEID
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if p, ok := n.peers[nodeID]; !ok || p.state != peerAssociated {
+		return reply(upNode, wire.NewCause(wire.CauseNoEstablishedAssociation))
+	}
+
+	n.lastSEID++
+	s := &n4Session{
+		localSEID:  n.lastSEID,
+		remoteSEID: cp.SEID,
+		nodeID:     nodeID,
+		pdrs:       make(map[uint16]n4PDR),
+		fars:       make(map[uint32]n4FAR),
+		qers:       make(map[uint32]n4QER),
+		urrs:       make(map[uint32]n4URR),
+	}
+	if imsiValue, err := m.Find(wire.IEUserID).UserIDIMSI(); err == nil {
+		s.imsi = imsiValue
+	}
+	if dnn, err := m.Find(wire.IEAPNDNN).APNDNN(); err == nil {
+		s.dnn = dnn
+	}
+
+	created, err := n.apply(s, m)
+	if err == nil {
+		err = n.provision(nil, s, "SessionEstablishment")
+	}
+	if err != nil {
+		log.Printf("❌ Session establishment from %s rejected: %v", nodeID, err)
+		return reply(append([]*wire.IE{upNode}, rejection(err)...)...)
+	}
+	n.sessions[s.localSEID] = s
+
+	log.Printf("✅ Established session %s for %s (%d PDRs, %d FARs)", s.fseid(), nodeID, len(s.pdrs), len(s.fars))
+	ies := []*wire.IE{upNode, wire.NewCause(wire.CauseRequestAccepted), wire.NewFSEID(s.localSEID, n.n3Addr)}
+	return reply(append(ies, created...)...)
+}
+
+// sessionModification applies new rules to an existing session
+func (n *n4Server) sessionModification(m *wire.Message) *wire.Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	current, ok := n.sessions[m.SEID]
+	if !ok {
+		return wire.NewMessage(wire.MsgSessionModificationResponse, 0, m.Sequence,
+			wire.NewCause(wire.CauseSessionContextNotFound))
+	}
+	reply := func(ies ...*wire.IE) *wire.Message {
+		return wire.NewMessage(wire.MsgSessionModificationResponse, current.remoteSEID, m.Sequence, ies...)
+	}
+
+	s := current.clone()
+	if ie := m.Find(wire.IEFSEID); ie != nil {
+		cp, err := ie.FSEID()
+		if err != nil {
+			return reply(rejection(incorrect(wire.IEFSEID, m.Type.String(), err))...)
+		}
+		s.remoteSEID = cp.SEID
+	}
+
+	created, err := n.apply(s, m)
+	if err == nil {
+		err = n.provision(current, s, "SessionModification")
+	}
+	if err != nil {
+		log.Printf("❌ Modification of session %s rejected: %v", s.fseid(), err)
+		return reply(rejection(err)...)
+	}
+	n.sessions[s.localSEID] = s
+
+	log.Printf("✏️ Modified session %s", s.fseid())
+	return reply(append([]*wire.IE{wire.NewCause(wire.CauseRequestAccepted)}, created...)...)
+}
+
+// sessionDeletion removes a session from the N4 state and the shared stores
+// and answers with the final usage report of its URR
+func (n *n4Server) sessionDeletion(m *wire.Message) *wire.Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	s, ok := n.sessions[m.SEID]
+	if !ok {
+		return wire.NewMessage(wire.MsgSessionDeletionResponse, 0, m.Sequence,
+			wire.NewCause(wire.CauseSessionContextNotFound))
+	}
+	ies := []*wire.IE{wire.NewCause(wire.CauseRequestAccepted)}
+	if n.usage != nil {
+		// Close the measurement of the URR with a final report
+		if r, ok := n.usage.terminate(s.fseid()); ok {
+			ies = append(ies, wire.NewUsageReport(wire.IEUsageReportDeletion, r))
+		}
+	}
+	n.unprovision(s, "SessionDeletion")
+	delete(n.sessions, m.SEID)
+
+	log.Printf("🗑️ Deleted session %s", s.fseid())
+	return wire.NewMessage(wire.MsgSessionDeletionResponse, s.remoteSEID, m.Sequence, ies...)
+}
+
+// reportUsage sends a usage report to the CP function owning the session in
+// a Session Report Request. Sessions that did not come over N4, or whose
+// peer is not associated, are not reported.
+func (n *n4Server) reportUsage(fseid string, r wire.UsageReport) {
+	seid, err := strconv.ParseUint(strings.TrimPrefix(fseid, "0x"), 16, 64)
+	if err != nil {
+		return
+	}
+	n.mu.Lock()
+	s, ok := n.sessions[seid]
+	if !ok || s.fseid() != fseid {
+		n.mu.Unlock()
+		return
+	}
+	p, ok := n.peers[s.nodeID]
+	if !ok || p.state != peerAssociated {
+		n.mu.Unlock()
+		return
+	}
+	addr, nodeID, remoteSEID := p.addr, p.nodeID, s.remoteSEID
+	n.mu.Unlock()
+
+	go func() {
+		resp, err := n.request(addr, wire.NewMessage(wire.MsgSessionReportRequest, remoteSEID, 0,
+			wire.NewReportType(wire.ReportTypeUsage), wire.NewUsageReport(wire.IEUsageReportReport, r)))
+		if err == nil {
+			var cause wire.Cause
+			if cause, err = resp.Find(wire.IECause).Cause(); err == nil && cause != wire.CauseRequestAccepted {
+				err = errors.New("rejected with cause " + strconv.Itoa(int(cause)))
+			}
+		}
+		if err != nil {
+			log.Printf("⚠️ Usage report %d of session %s to %s failed: %v", r.Sequence, fseid, nodeID, err)
+			return
+		}
+		log.Printf("📊 Reported usage %d of session %s to %s", r.Sequence, fseid, nodeID)
+	}()
+}
+
+// apply removes, creates and updates the rules named by a session request and
+// returns the Created PDR IEs reporting F-TEIDs allocated by the UPF.
+// Callers hold n.mu.
+func (n *n4Server) apply(s *n4Session, m *wire.Message) ([]*wire.IE, error) {
+	if err := removeRules(s, m); err != nil {
+		return nil, err
+	}
+
+	for _, ie := range m.FindAll(wire.IECreateFAR) {
+		id, err := ie.Find(wire.IEFARID).Uint32()
+		if err != nil {
+			return nil, ieError(wire.IEFARID, "Create FAR", err)
+		}
+		far, err := parseFAR(ie, n4FAR{id: id}, true)
+		if err != nil {
+			return nil, err
+		}
+		s.fars[id] = far
+	}
+	for _, ie := range m.FindAll(wire.IECreateQER) {
+		id, err := ie.Find(wire.IEQERID).Uint32()
+		if err != nil {
+			return nil, ieError(wire.IEQERID, "Create QER", err)
+		}
+		qer, err := parseQER(ie, n4QER{id: id}, "Create QER")
+		if err != nil {
+			return nil, err
+		}
+		s.qers[id] = qer
+	}
+	for _, ie := range m.FindAll(wire.IECreateURR) {
+		id, err := ie.Find(wire.IEURRID).Uint32()
+		if err != nil {
+			return nil, ieError(wire.IEURRID, "Create URR", err)
+		}
+		urr, err := parseURR(ie, n4URR{id: id}, "Create URR")
+		if err != nil {
+			return nil, err
+		}
+		s.urrs[id] = urr
+	}
+
+	var created []*wire.IE
+	chosen := make(map[uint8]uint32) // CHOOSE ID -> allocated TEID
+	allocate := func(pdr *n4PDR, choose *wire.FTEID) {
+		teid, ok := chosen[choose.ChooseID]
+		if !ok || !choose.HasID {
+			n.lastTEID++
+			teid = n.lastTEID
+			if choose.HasID {
+				chosen[choose.ChooseID] = teid
+			}
+		}
+		pdr.teid, pdr.hasTEID = teid, true
+		created = append(created, wire.NewGrouped(wire.IECreatedPDR,
+			wire.NewPDRID(pdr.id), wire.NewFTEID(teid, n.n3Addr)))
+	}
+	for _, ie := range m.FindAll(wire.IECreatePDR) {
+		id, err := ie.Find(wire.IEPDRID).PDRID()
+		if err != nil {
+			return nil, ieError(wire.IEPDRID, "Create PDR", err)
+		}
+		pdr, choose, err := parsePDR(ie, n4PDR{id: id}, true)
+		if err != nil {
+			return nil, err
+		}
+		if choose != nil {
+			allocate(&pdr, choose)
+		}
+		s.pdrs[id] = pdr
+	}
+
+	if err := updateRules(s, m, allocate); err != nil {
+		return nil, err
+	}
+
+	// Every PDR must forward through a FAR of the session
+	for _, pdr := range s.pdrs {
+		if _, ok := s.fars[pdr.farID]; !ok {
+			return nil, &causeError{
+				cause: wire.CauseRuleCreationModificationFailed,
+				ie:    wire.IECreatePDR,
+				msg:   fmt.Sprintf("PDR %d references unknown FAR %d", pdr.id, pdr.farID),
+			}
+		}
+	}
+	return created, nil
+}
+
+// unknownRule rejects an update or removal of a rule the session does not have
+func unknownRule(t wire.IEType, kind string, id uint32) error {
+	return &causeError{
+		cause: wire.CauseRuleCreationModificationFailed,
+		ie:    t,
+		msg:   fmt.Sprintf("unknown %s %d", kind, id),
+	}
+}
+
+// removeRules deletes the rules named by Remove PDR/FAR/QER/URR IEs
+func removeRules(s *n4Session, m *wire.Message) error {
+	for _, ie := range m.FindAll(wire.IERemovePDR) {
+		id, err := ie.Find(wire.IEPDRID).PDRID()
+		if err != nil {
+			return ieError(wire.IEPDRID, "Remove PDR", err)
+		}
+		if _, ok := s.pdrs[id]; !ok {
+			return unknownRule(wire.IERemovePDR, "PDR", uint32(id))
+		}
+		delete(s.pdrs, id)
+	}
+	for _, ie := range m.FindAll(wire.IERemoveFAR) {
+		id, err := ie.Find(wire.IEFARID).Uint32()
+		if err != nil {
+			return ieError(wire.IEFARID, "Remove FAR", err)
+		}
+		if _, ok := s.fars[id]; !ok {
+			return unknownRule(wire.IERemoveFAR, "FAR", id)
+		}
+		delete(s.fars, id)
+	}
+	for _, ie := range m.FindAll(wire.IERemoveQER) {
+		id, err := ie.Find(wire.IEQERID).Uint32()
+		if err != nil {
+			return ieError(wire.IEQERID, "Remove QER", err)
+		}
+		if _, ok := s.qers[id]; !ok {
+			return unknownRule(wire.IERemoveQER, "QER", id)
+		}
+		delete(s.qers, id)
+	}
+	for _, ie := range m.FindAll(wire.IERemoveURR) {
+		id, err := ie.Find(wire.IEURRID).Uint32()
+		if err != nil {
+			return ieError(wire.IEURRID, "Remove URR", err)
+		}
+		if _, ok := s.urrs[id]; !ok {
+			return unknownRule(wire.IERemoveURR, "URR", id)
+		}
+		delete(s.urrs, id)
+	}
+	return nil
+}
+
+// updateRules applies Update PDR/FAR/QER/URR IEs to existing rules. PDRs
+// whose new PDI asks for an F-TEID are passed to allocate.
+func updateRules(s *n4Session, m *wire.Message, allocate func(*n4PDR, *wire.FTEID)) error {
+	for _, ie := range m.FindAll(wire.IEUpdatePDR) {
+		id, err := ie.Find(wire.IEPDRID).PDRID()
+		if err != nil {
+			return ieError(wire.IEPDRID, "Update PDR", err)
+		}
+		prev, ok := s.pdrs[id]
+		if !ok {
+			return unknownRule(wire.IEUpdatePDR, "PDR", uint32(id))
+		}
+		pdr, choose, err := parsePDR(ie, prev, false)
+		if err != nil {
+			return err
+		}
+		if choose != nil {
+			allocate(&pdr, choose)
+		}
+		s.pdrs[id] = pdr
+	}
+	for _, ie := range m.FindAll(wire.IEUpdateFAR) {
+		id, err := ie.Find(wire.IEFARID).Uint32()
+		if err != nil {
+			return ieError(wire.IEFARID, "Update FAR", err)
+		}
+		prev, ok := s.fars[id]
+		if !ok {
+			return unknownRule(wire.IEUpdateFAR, "FAR", id)
+		}
+		far, err := parseFAR(ie, prev, false)
+		if err != nil {
+			return err
+		}
+		s.fars[id] = far
+	}
+	for _, ie := range m.FindAll(wire.IEUpdateQER) {
+		id, err := ie.Find(wire.IEQERID).Uint32()
+		if err != nil {
+			return ieError(wire.IEQERID, "Update QER", err)
+		}
+		prev, ok := s.qers[id]
+		if !ok {
+			return unknownRule(wire.IEUpdateQER, "QER", id)
+		}
+		qer, err := parseQER(ie, prev, "Update QER")
+		if err != nil {
+			return err
+		}
+		s.qers[id] = qer
+	}
+	for _, ie := range m.FindAll(wire.IEUpdateURR) {
+		id, err := ie.Find(wire.IEURRID).Uint32()
+		if err != nil {
+			return ieError(wire.IEURRID, "Update URR", err)
+		}
+		prev, ok := s.urrs[id]
+		if !ok {
+			return unknownRule(wire.IEUpdateURR, "URR", id)
+		}
+		urr, err := parseURR(ie, prev, "Update URR")
+		if err != nil {
+			return err
+		}
+		s.urrs[id] = urr
+	}
+	return nil
+}
+
+// parsePDR applies a Create or Update PDR IE to pdr. If the PDR asks the UPF
+// to choose its F-TEID, the request is returned for the caller to allocate.
+func parsePDR(ie *wire.IE, pdr n4PDR, create bool) (n4PDR, *wire.FTEID, error) {
+	where := "Update PDR"
+	if create {
+		where = "Create PDR"
+	}
+	var err error
+
+	if p := ie.Find(wire.IEPrecedence); p != nil {
+		if pdr.precedence, err = p.Uint32(); err != nil {
+			return pdr, nil, incorrect(wire.IEPrecedence, where, err)
+		}
+	}
+	if o := ie.Find(wire.IEOuterHeaderRemoval); o != nil {
+		ohr, err := o.OuterHeaderRemoval()
+		if err != nil {
+			return pdr, nil, incorrect(wire.IEOuterHeaderRemoval, where, err)
+		}
+		pdr.ohr = &ohr
+	}
+	if f := ie.Find(wire.IEFARID); f != nil {
+		if pdr.farID, err = f.Uint32(); err != nil {
+			return pdr, nil, incorrect(wire.IEFARID, where, err)
+		}
+	}
+	if qers := ie.FindAll(wire.IEQERID); create || len(qers) > 0 {
+		pdr.qerIDs = nil
+		for _, q := range qers {
+			id, err := q.Uint32()
+			if err != nil {
+				return pdr, nil, incorrect(wire.IEQERID, where, err)
+			}
+			pdr.qerIDs = append(pdr.qerIDs, id)
+		}
+	}
+	if urrs := ie.FindAll(wire.IEURRID); create || len(urrs) > 0 {
+		pdr.urrIDs = nil
+		for _, u := range urrs {
+			id, err := u.Uint32()
+			if err != nil {
+				return pdr, nil, incorrect(wire.IEURRID, where, err)
+			}
+			pdr.urrIDs = append(pdr.urrIDs, id)
+		}
+	}
+
+	// A PDI is mandatory on creation and replaces the previous one on update
+	pdi := ie.Find(wire.IEPDI)
+	if pdi == nil {
+		if create {
+			return pdr, nil, missing(wire.IEPDI, where)
+		}
+		return pdr, nil, nil
+	}
+	pdr.teid, pdr.hasTEID, pdr.ueIP, pdr.networkInstance, pdr.sdfFilters = 0, false, nil, "", nil
+	if pdr.source, err = pdi.Find(wire.IESourceInterface).Interface(); err != nil {
+		return pdr, nil, ieError(wire.IESourceInterface, where, err)
+	}
+	if ni := pdi.Find(wire.IENetworkInstance); ni != nil {
+		pdr.networkInstance, _ = ni.NetworkInstance()
+	}
+	if ue := pdi.Find(wire.IEUEIPAddress); ue != nil {
+		addr, err := ue.UEIPAddress()
+		if err != nil {
+			return pdr, nil, incorrect(wire.IEUEIPAddress, where, err)
+		}
+		pdr.ueIP = addr.IPv4
+	}
+	for _, f := range pdi.FindAll(wire.IESDFFilter) {
+		sdf, err := f.SDFFilter()
+		if err != nil {
+			return pdr, nil, incorrect(wire.IESDFFilter, where, err)
+		}
+		pdr.sdfFilters = append(pdr.sdfFilters, sdf)
+	}
+
+	var choose *wire.FTEID
+	if f := pdi.Find(wire.IEFTEID); f != nil {
+		fteid, err := f.FTEID()
+		if err != nil {
+			return pdr, nil, incorrect(wire.IEFTEID, where, err)
+		}
+		if fteid.Choose {
+			choose = &fteid
+		} else {
+			pdr.teid, pdr.hasTEID = fteid.TEID, true
+		}
+	}
+	return pdr, choose, nil
+}
+
+// parseFAR applies a Create or Update FAR IE to far
+func parseFAR(ie *wire.IE, far n4FAR, create bool) (n4FAR, error) {
+	where, params := "Update FAR", wire.IEUpdateForwardingParameters
+	if create {
+		where, params = "Create FAR", wire.IEForwardingParameters
+	}
+	var err error
+
+	if a := ie.Find(wire.IEApplyAction); a != nil || create {
+		if far.action, err = a.ApplyAction(); err != nil {
+			return far, ieError(wire.IEApplyAction, where, err)
+		}
+	}
+	if fp := ie.Find(params); fp != nil {
+		if d := fp.Find(wire.IEDestinationInterface); d != nil || create {
+			if far.destination, err = d.Interface(); err != nil {
+				return far, ieError(wire.IEDestinationInterface, where, err)
+			}
+		}
+		if ni := fp.Find(wire.IENetworkInstance); ni != nil {
+			far.networkInstance, _ = ni.NetworkInstance()
+		}
+		if o := fp.Find(wire.IEOuterHeaderCreation); o != nil {
+			ohc, err := o.OuterHeaderCreation()
+			if err != nil {
+				return far, incorrect(wire.IEOuterHeaderCreation, where, err)
+			}
+			far.ohc = &ohc
+		}
+	}
+	return far, nil
+}
+
+// parseQER applies a Create or Update QER IE to qer
+func parseQER(ie *wire.IE, qer n4QER, where string) (n4QER, error) {
+	var err error
+	if q := ie.Find(wire.IEQFI); q != nil {
+		if qer.qfi, err = q.QFI(); err != nil {
+			return qer, incorrect(wire.IEQFI, where, err)
+		}
+	}
+	if r := ie.Find(wire.IEMBR); r != nil {
+		if qer.mbr, err = r.BitRate(); err != nil {
+			return qer, incorrect(wire.IEMBR, where, err)
+		}
+	}
+	if r := ie.Find(wire.IEGBR); r != nil {
+		if qer.gbr, err = r.BitRate(); err != nil {
+			return qer, incorrect(wire.IEGBR, where, err)
+		}
+	}
+	return qer, nil
+}
+
+// parseURR applies a Create or Update URR IE to urr
+func parseURR(ie *wire.IE, urr n4URR, where string) (n4URR, error) {
+	var err error
+	if m := ie.Find(wire.IEMeasurementMethod); m != nil {
+		if urr.method, err = m.MeasurementMethod(); err != nil {
+			return urr, incorrect(wire.IEMeasurementMethod, where, err)
+		}
+	}
+	if t := ie.Find(wire.IEReportingTriggers); t != nil {
+		if urr.triggers, err = t.Flags(); err != nil {
+			return urr, incorrect(wire.IEReportingTriggers, where, err)
+		}
+	}
+	volumes := []struct {
+		t   wire.IEType
+		dst **wire.Volume
+	}{
+		{wire.IEVolumeThreshold, &urr.volThreshold},
+		{wire.IEVolumeQuota, &urr.volQuota},
+	}
+	for _, v := range volumes {
+		if m := ie.Find(v.t); m != nil {
+			vol, err := m.Volume()
+			if err != nil {
+				return urr, incorrect(v.t, where, err)
+			}
+			*v.dst = &vol
+		}
+	}
+	durations := []struct {
+		t   wire.IEType
+		dst *time.Duration
+	}{
+		{wire.IETimeThreshold, &urr.timeThreshold},
+		{wire.IETimeQuota, &urr.timeQuota},
+		{wire.IEMeasurementPeriod, &urr.period},
+	}
+	for _, d := range durations {
+		if m := ie.Find(d.t); m != nil {
+			if *d.dst, err = m.Seconds(); err != nil {
+				return urr, incorrect(d.t, where, err)
+			}
+		}
+	}
+	return urr, nil
+}
+
+// volumeOctets returns the total octets of a Volume Threshold or Quota,
+// adding up the directions when no total is given
+func volumeOctets(v *wire.Volume) uint64 {
+	switch {
+	case v == nil:
+		return 0
+	case v.Flags&wire.VolumeTotal != 0:
+		return v.Total
+	default:
+		return v.Uplink + v.Downlink
+	}
+}
+
+// sortedKeys returns the keys of a rule map in ascending order
+func sortedKeys[K uint16 | uint32, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// flatten converts the session into the single PDR/FAR/QER/URR shape of the
+// rule store. The FAR with outer header creation (the downlink tunnel) and
+// the lowest numbered QER and URR stand for the session.
+func (n *n4Server) flatten(s *n4Session) *pb.Rulestruct {
+	fseid := s.fseid()
+	r := &pb.Rulestruct{
+		Pdr:  &pb.Pdrstruct{Fsied: fseid},
+		Far:  &pb.Farstruct{Fsied: fseid},
+		Qer:  &pb.Qerstruct{Fsied: fseid},
+		Urr:  &pb.Urrstruct{Fsied: fseid},
+		Imsi: s.imsi,
+		Dnn:  s.dnn,
+	}
+
+	for _, id := range sortedKeys(s.pdrs) {
+		pdr := s.pdrs[id]
+		r.Pdr.PdrId = append(r.Pdr.PdrId, fmt.Sprintf("pdr%d", id))
+		if r.Pdr.UeIp == "" && pdr.ueIP != nil {
+			r.Pdr.UeIp = pdr.ueIP.String()
+		}
+		if r.Pdr.Teid == "" && pdr.hasTEID && pdr.source == wire.InterfaceAccess {
+			r.Pdr.Teid = fmt.Sprintf("0x%08x", pdr.teid)
+		}
+		if r.Dnn == "" && pdr.source == wire.InterfaceCore {
+			r.Dnn = pdr.networkInstance
+		}
+	}
+
+	farIDs := sortedKeys(s.fars)
+	if len(farIDs) > 0 {
+		far := s.fars[farIDs[0]]
+		for _, id := range farIDs {
+			if s.fars[id].ohc != nil {
+				far = s.fars[id]
+				break
+			}
+		}
+		r.Far.FarId = fmt.Sprintf("far%d", far.id)
+		switch {
+		case far.action&wire.ApplyDrop != 0:
+			r.Far.ApplyAction = "drop"
+		case far.action&wire.ApplyBuffer != 0:
+			r.Far.ApplyAction = "buffer"
+		default:
+			r.Far.ApplyAction = "forward"
+		}
+		if far.ohc != nil && far.ohc.IPv4 != nil {
+			r.Far.OuterIp = far.ohc.IPv4.String()
+			r.Far.OuterTeid = fmt.Sprintf("0x%08x", far.ohc.TEID)
+		}
+	}
+
+	if qerIDs := sortedKeys(s.qers); len(qerIDs) > 0 {
+		qer := s.qers[qerIDs[0]]
+		r.Qer.QerId = fmt.Sprintf("qer%d", qer.id)
+		r.Qer.Qfi = uint32(qer.qfi)
+		r.Qer.UlMbr, r.Qer.DlMbr = qer.mbr.UL*1000, qer.mbr.DL*1000
+		r.Qer.UlGbr, r.Qer.DlGbr = qer.gbr.UL*1000, qer.gbr.DL*1000
+	}
+	if urrIDs := sortedKeys(s.urrs); len(urrIDs) > 0 {
+		urr := s.urrs[urrIDs[0]]
+		r.Urr.UrrId = fmt.Sprintf("urr%d", urr.id)
+		if urr.method&wire.MeasureVolume != 0 {
+			r.Urr.MeasurementMethod = append(r.Urr.MeasurementMethod, rule.MeasureVolume)
+		}
+		if urr.method&wire.MeasureDuration != 0 {
+			r.Urr.MeasurementMethod = append(r.Urr.MeasurementMethod, rule.MeasureDuration)
+		}
+		for _, t := range urrTriggers {
+			if urr.triggers&t.trigger != 0 {
+				r.Urr.ReportingTriggers = append(r.Urr.ReportingTriggers, t.name)
+			}
+		}
+		r.Urr.VolumeThreshold, r.Urr.VolumeQuota = volumeOctets(urr.volThreshold), volumeOctets(urr.volQuota)
+		r.Urr.TimeThreshold = uint32(urr.timeThreshold / time.Second)
+		r.Urr.TimeQuota = uint32(urr.timeQuota / time.Second)
+		r.Urr.MeasurementPeriod = uint32(urr.period / time.Second)
+	}
+
+	if r.Dnn == "" {
+		r.Dnn = n.defaultDNN
+	}
+	return r
+}
+
+// provision writes the session into the shared rule and subscriber stores,
+// replacing prev. Store rejections are mapped onto PFCP causes.
+func (n *n4Server) provision(prev, s *n4Session, op string) error {
+	r := n.flatten(s)
+	change := rule.Change{Caller: "pfcp/" + s.nodeID, Operation: op}
+	if err := n.rules.PutRules(r, change); err != nil {
+		var capErr *rule.CapacityError
+		if errors.As(err, &capErr) {
+			return &causeError{cause: wire.CauseNoResourcesAvailable, msg: err.Error()}
+		}
+		return &causeError{cause: wire.CauseRuleCreationModificationFailed, msg: err.Error()}
+	}
+
+	if prev != nil && prev.imsi != "" {
+		n.subscribers.RemoveSession(prev.imsi, prev.fseid())
+	}
+	if s.imsi != "" {
+		n.subscribers.SetSession(s.imsi, r.Dnn, s.fseid())
+	}
+	return nil
+}
+
+// unprovision removes the session from the shared rule and subscriber stores
+func (n *n4Server) unprovision(s *n4Session, op string) {
+	n.rules.Delete(s.fseid(), rule.Change{Caller: "pfcp/" + s.nodeID, Operation: op})
+	if s.imsi != "" {
+		n.subscribers.RemoveSession(s.imsi, s.fseid())
+	}
+}
+
+// listenN4 creates an N4 server listening for PFCP on the given UDP address
+func listenN4(addr string) (*n4Server, error) {
+	cfg, err := config.LoadConfig(config.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create UDP listener
+	laddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	n := newN4Server(cfg)
+	n.transport = wire.NewTransport(conn, n.handle)
+	n.transport.Retries, n.transport.Timeout = transportSettings(cfg)
+	n.transport.OnMalformed = func(from *net.UDPAddr, err error) {
+		log.Printf("⚠️ Malformed PFCP datagram from %s: %v", from, err)
+	}
+
+	log.Printf("PFCP N4 endpoint listening on udp %s (Node ID %s, %d retries every %s)...",
+		addr, n.nodeID, n.transport.Retries, n.transport.Timeout)
+	return n, nil
+}
+
+// serve answers SMF requests and maintains the peer associations until the socket fails
+func (n *n4Server) serve() error {
+	defer close(n.done)
+	go n.maintainPeers()
+	return n.transport.Serve()
+}
+
+// StartN4Endpoint listens for PFCP on the given UDP address and serves
+// SMF requests until the socket fails
+func StartN4Endpoint(addr string) error {
+	n, err := listenN4(addr)
+	if err != nil {
+		return err
+	}
+	return n.serve()
+}
@@ -0,0 +1,166 @@
+package pfcp
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"upf/Server/config"
+	"upf/Server/imsi"
+	"upf/Server/rule"
+	wire "upf/pkg/pfcp"
+)
+
+// startN4 serves N4 on a loopback port with its own rule and subscriber
+// stores and returns a client associated with nothing yet. Peers are not
+// maintained in the background, so tests drive associations themselves.
+func startN4(t *testing.T, cfg *config.UPFConfig) (*n4Server, *wire.Client) {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := newN4Server(cfg)
+	n.rules, n.subscribers = rule.NewStore(4), imsi.NewStore()
+	n.transport = wire.NewTransport(conn, n.handle)
+	go n.transport.Serve()
+	t.Cleanup(func() { n.transport.Close() })
+
+	c, err := wire.Dial(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Transport().Timeout = 200 * time.Millisecond
+	t.Cleanup(func() { c.Close() })
+	return n, c
+}
+
+// n4Config is the configuration of the loopback N4 server
+func n4Config() *config.UPFConfig {
+	cfg := &config.UPFConfig{}
+	cfg.P4RTCInterface.AccessIP = "127.0.0.1/32"
+	cfg.CPInterface.DNN = "internet"
+	return cfg
+}
+
+// request sends m and fails the test unless the response has the wanted cause
+func request(t *testing.T, c *wire.Client, m *wire.Message, want wire.MessageType, cause wire.Cause) *wire.Message {
+	t.Helper()
+	resp, err := c.Request(m)
+	if err != nil {
+		t.Fatalf("%s: %v", m.Type, err)
+	}
+	if resp.Type != want {
+		t.Fatalf("%s answered with %s, want %s", m.Type, resp.Type, want)
+	}
+	got, err := resp.Find(wire.IECause).Cause()
+	if err != nil {
+		t.Fatalf("%s: %v", resp.Type, err)
+	}
+	if got != cause {
+		t.Fatalf("%s: cause %s, want %s", resp.Type, got, cause)
+	}
+	return resp
+}
+
+func TestN4SessionLifecycle(t *testing.T) {
+	n, c := startN4(t, n4Config())
+	const smf, cpSEID, imsiValue = "smf.test", 0x100, "001010000000001"
+	smfIP := net.IPv4(127, 0, 0, 2)
+	userID, err := wire.NewUserIDIMSI(imsiValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	establish := wire.NewMessage(wire.MsgSessionEstablishmentRequest, 0, 0,
+		wire.NewNodeID(smf),
+		wire.NewFSEID(cpSEID, smfIP),
+		userID,
+		wire.NewAPNDNN("internet"),
+		wire.NewGrouped(wire.IECreatePDR,
+			wire.NewPDRID(1), wire.NewPrecedence(100),
+			wire.NewGrouped(wire.IEPDI, wire.NewSourceInterface(wire.InterfaceAccess), wire.NewFTEIDChoose(true)),
+			wire.NewOuterHeaderRemoval(wire.OHRGTPUUDPIPv4),
+			wire.NewFARID(1), wire.NewQERID(1)),
+		wire.NewGrouped(wire.IECreatePDR,
+			wire.NewPDRID(2), wire.NewPrecedence(100),
+			wire.NewGrouped(wire.IEPDI, wire.NewSourceInterface(wire.InterfaceCore),
+				wire.NewUEIPAddress(net.IPv4(10, 45, 0, 1), true)),
+			wire.NewFARID(2), wire.NewQERID(1)),
+		wire.NewGrouped(wire.IECreateFAR, wire.NewFARID(1), wire.NewApplyAction(wire.ApplyForward),
+			wire.NewGrouped(wire.IEForwardingParameters, wire.NewDestinationInterface(wire.InterfaceCore))),
+		wire.NewGrouped(wire.IECreateFAR, wire.NewFARID(2), wire.NewApplyAction(wire.ApplyForward),
+			wire.NewGrouped(wire.IEForwardingParameters, wire.NewDestinationInterface(wire.InterfaceAccess),
+				wire.NewOuterHeaderCreationGTPU(0x900, smfIP))),
+		wire.NewGrouped(wire.IECreateQER, wire.NewQERID(1), wire.NewQFI(9),
+			wire.NewMBR(wire.BitRate{UL: 1000, DL: 2000})),
+	)
+
+	// Sessions need an association
+	request(t, c, establish, wire.MsgSessionEstablishmentResponse, wire.CauseNoEstablishedAssociation)
+
+	resp := request(t, c, wire.NewMessage(wire.MsgAssociationSetupRequest, 0, 0,
+		wire.NewNodeID(smf), wire.NewRecoveryTimeStamp(time.Now())),
+		wire.MsgAssociationSetupResponse, wire.CauseRequestAccepted)
+	if id, err := resp.Find(wire.IENodeID).NodeID(); err != nil || id.String() != "127.0.0.1" {
+		t.Fatalf("UPF Node ID %v (%v), want 127.0.0.1", id, err)
+	}
+	if p := n.listPeers(); len(p) != 1 || p[0].NodeId != smf || p[0].State != "associated" {
+		t.Fatalf("peers after association: %v", p)
+	}
+
+	// Establishment allocates the SEID and the uplink F-TEID
+	resp = request(t, c, establish, wire.MsgSessionEstablishmentResponse, wire.CauseRequestAccepted)
+	if resp.SEID != cpSEID {
+		t.Fatalf("response SEID %#x, want the CP SEID %#x", resp.SEID, cpSEID)
+	}
+	up, err := resp.Find(wire.IEFSEID).FSEID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := resp.Find(wire.IECreatedPDR)
+	if id, _ := created.Find(wire.IEPDRID).PDRID(); id != 1 {
+		t.Fatalf("Created PDR %d, want 1", id)
+	}
+	fteid, err := created.Find(wire.IEFTEID).FTEID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fseid := (&n4Session{localSEID: up.SEID}).fseid()
+	r, ok := n.rules.Rules(fseid)
+	if !ok {
+		t.Fatalf("session %s not in the rule store", fseid)
+	}
+	if r.Imsi != imsiValue || r.Dnn != "internet" || r.Pdr.UeIp != "10.45.0.1" ||
+		r.Pdr.Teid != fmt.Sprintf("0x%08x", fteid.TEID) || r.Far.OuterTeid != "0x00000900" || r.Qer.UlMbr != 1000000 {
+		t.Fatalf("stored rules %v", r)
+	}
+	if info, _ := n.subscribers.Get(imsiValue); info.Inter != fseid {
+		t.Fatalf("subscriber %s: %+v, want internet session %s", imsiValue, info, fseid)
+	}
+
+	// Modification moves the downlink tunnel
+	request(t, c, wire.NewMessage(wire.MsgSessionModificationRequest, up.SEID, 0,
+		wire.NewGrouped(wire.IEUpdateFAR, wire.NewFARID(2),
+			wire.NewGrouped(wire.IEUpdateForwardingParameters, wire.NewOuterHeaderCreationGTPU(0x901, smfIP)))),
+		wire.MsgSessionModificationResponse, wire.CauseRequestAccepted)
+	if r, _ := n.rules.Rules(fseid); r.Far.OuterTeid != "0x00000901" {
+		t.Fatalf("outer TEID after modification %s, want 0x00000901", r.Far.OuterTeid)
+	}
+	request(t, c, wire.NewMessage(wire.MsgSessionModificationRequest, up.SEID, 0, wire.NewRemoveFAR(7)),
+		wire.MsgSessionModificationResponse, wire.CauseRuleCreationModificationFailed)
+
+	// Deletion clears both stores
+	request(t, c, wire.NewMessage(wire.MsgSessionDeletionRequest, up.SEID, 0),
+		wire.MsgSessionDeletionResponse, wire.CauseRequestAccepted)
+	if _, ok := n.rules.Rules(fseid); ok {
+		t.Fatalf("session %s still in the rule store", fseid)
+	}
+	if _, ok := n.subscribers.Get(imsiValue); ok {
+		t.Fatalf("subscriber %s still has sessions", imsiValue)
+	}
+	request(t, c, wire.NewMessage(wire.MsgSessionDeletionRequest, up.SEID, 0),
+		wire.MsgSessionDeletionResponse, wire.CauseSessionContextNotFound)
+}
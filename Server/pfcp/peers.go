@@ -0,0 +1,393 @@
+package pfcp
+
+import (
+	"errors"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	wire "upf/pkg/pfcp"
+	pb "upf/pkg/proto"
+)
+
+// defaultHeartbeatInterval is how often associated peers are sent heartbeats
+// and configured peers without an association are retried, unless
+// heart_beat_interval says otherwise
+const defaultHeartbeatInterval = 5 * time.Second
+
+// peerState is the state of the association with a PFCP peer
+type peerState int
+
+// Peer states. Configured peers start idle and are associated by the UPF;
+// an associated peer that stops answering heartbeats is lost until it
+// answers again or re-associates.
+const (
+	peerIdle peerState = iota
+	peerAssociating
+	peerAssociated
+	peerLost
+)
+
+func (s peerState) String() string {
+	switch s {
+	case peerIdle:
+		return "idle"
+	case peerAssociating:
+		return "associating"
+	case peerAssociated:
+		return "associated"
+	case peerLost:
+		return "lost"
+	}
+	return "unknown"
+}
+
+// peer is a control plane node the UPF has, or wants, an association with
+type peer struct {
+	nodeID        string       // Node ID of the CP function
+	addr          *net.UDPAddr // Address requests are sent to
+	configured    bool         // Listed in cpiface.peers
+	state         peerState    // Association state
+	since         time.Time    // When the peer entered its state
+	recovery      time.Time    // Recovery time stamp announced by the peer, zero if unknown
+	lastHeartbeat time.Time    // Last heartbeat exchanged with the peer
+	busy          bool         // An association or heartbeat exchange is in flight
+}
+
+// addConfiguredPeer registers a cpiface peer given as "host" or "host:port"
+func (n *n4Server) addConfiguredPeer(entry string) error {
+	host, port, err := net.SplitHostPort(entry)
+	if err != nil {
+		host, port = entry, strconv.Itoa(wire.Port)
+	}
+	addr, err := net.ResolveUDPAddr("udp", net.JoinHostPort(host, port))
+	if err != nil {
+		return err
+	}
+	n.peers[host] = &peer{nodeID: host, addr: addr, configured: true, state: peerIdle, since: time.Now()}
+	return nil
+}
+
+// setStateLocked moves a peer to a new state. Callers hold n.mu.
+func (n *n4Server) setStateLocked(p *peer, state peerState) {
+	if p.state == state {
+		return
+	}
+	log.Printf("🔗 PFCP peer %s: %s → %s", p.nodeID, p.state, state)
+	p.state, p.since = state, time.Now()
+}
+
+// renameLocked re-keys a peer under the Node ID it announced. A peer already
+// known under that Node ID, such as an SMF that associated by itself from
+// another address, is merged into p; its sessions are kept. Callers hold n.mu.
+func (n *n4Server) renameLocked(p *peer, nodeID string) {
+	if p.nodeID == nodeID {
+		return
+	}
+	if q, ok := n.peers[nodeID]; ok && q != p {
+		log.Printf("🔗 PFCP peer %s merged into %s", p.nodeID, nodeID)
+		p.configured = p.configured || q.configured
+		if p.recovery.IsZero() {
+			p.recovery = q.recovery
+		}
+		if q.lastHeartbeat.After(p.lastHeartbeat) {
+			p.lastHeartbeat = q.lastHeartbeat
+		}
+	}
+	delete(n.peers, p.nodeID)
+	for _, s := range n.sessions {
+		if s.nodeID == p.nodeID {
+			s.nodeID = nodeID
+		}
+	}
+	p.nodeID = nodeID
+	n.peers[nodeID] = p
+}
+
+// peerLocked finds the peer behind a request: by the Node ID it names, by
+// its exact source address, or as a configured peer on the same host that
+// has no association yet. Requests without a Node ID, such as heartbeats,
+// pass "" and only match by address. Callers hold n.mu.
+func (n *n4Server) peerLocked(nodeID string, addr *net.UDPAddr) *peer {
+	if p, ok := n.peers[nodeID]; ok && nodeID != "" {
+		return p
+	}
+	for _, p := range n.peers {
+		if p.addr != nil && p.addr.String() == addr.String() {
+			return p
+		}
+	}
+	if nodeID == "" {
+		return nil
+	}
+	for _, p := range n.peers {
+		if p.configured && p.state != peerAssociated && p.addr.IP.Equal(addr.IP) {
+			return p
+		}
+	}
+	return nil
+}
+
+// restartedLocked records the recovery time stamp of a peer and reports
+// whether it changed, which means the peer restarted and lost its sessions.
+// The sessions are purged here. Callers hold n.mu.
+func (n *n4Server) restartedLocked(p *peer, recovery time.Time) bool {
+	restarted := !p.recovery.IsZero() && !p.recovery.Equal(recovery)
+	p.recovery = recovery
+	if restarted {
+		log.Printf("🔄 PFCP peer %s restarted, purging its sessions", p.nodeID)
+		n.purgeLocked(p.nodeID, "PeerRestart")
+	}
+	return restarted
+}
+
+// purgeLocked removes every session owned by a node. Callers hold n.mu.
+func (n *n4Server) purgeLocked(nodeID, op string) {
+	for seid, s := range n.sessions {
+		if s.nodeID == nodeID {
+			n.unprovision(s, op)
+			delete(n.sessions, seid)
+		}
+	}
+}
+
+// heartbeatRequest answers a peer heartbeat, noting peer restarts
+func (n *n4Server) heartbeatRequest(m *wire.Message, addr *net.UDPAddr) *wire.Message {
+	n.mu.Lock()
+	if p := n.peerLocked("", addr); p != nil {
+		p.lastHeartbeat = time.Now()
+		if recovery, err := m.Find(wire.IERecoveryTimeStamp).RecoveryTimeStamp(); err == nil {
+			if n.restartedLocked(p, recovery) {
+				n.setStateLocked(p, peerIdle)
+			} else if p.state == peerLost {
+				n.setStateLocked(p, peerAssociated)
+			}
+		}
+	}
+	n.mu.Unlock()
+	return wire.NewMessage(wire.MsgHeartbeatResponse, 0, m.Sequence, wire.NewRecoveryTimeStamp(n.recovery))
+}
+
+// associationSetup establishes or refreshes an association requested by a
+// peer. A new recovery time stamp from a known peer means it restarted, so
+// its sessions are purged.
+func (n *n4Server) associationSetup(m *wire.Message, addr *net.UDPAddr) *wire.Message {
+	reply := func(ies ...*wire.IE) *wire.Message {
+		ies = append([]*wire.IE{wire.NewNodeID(n.nodeID)}, ies...)
+		return wire.NewMessage(wire.MsgAssociationSetupResponse, 0, m.Sequence, ies...)
+	}
+
+	nodeID, err := peerNodeID(m)
+	if err != nil {
+		return reply(rejection(err)...)
+	}
+	recovery, err := m.Find(wire.IERecoveryTimeStamp).RecoveryTimeStamp()
+	if err != nil {
+		return reply(rejection(ieError(wire.IERecoveryTimeStamp, m.Type.String(), err))...)
+	}
+
+	n.mu.Lock()
+	p := n.peerLocked(nodeID, addr)
+	if p == nil {
+		p = &peer{nodeID: nodeID, state: peerIdle, since: time.Now()}
+		n.peers[nodeID] = p
+	}
+	n.renameLocked(p, nodeID)
+	p.addr = addr
+	n.restartedLocked(p, recovery)
+	n.setStateLocked(p, peerAssociated)
+	n.mu.Unlock()
+
+	log.Printf("🤝 Association established with %s", nodeID)
+	return reply(
+		wire.NewCause(wire.CauseRequestAccepted),
+		wire.NewRecoveryTimeStamp(n.recovery),
+		upFunctionFeatures(),
+	)
+}
+
+// upFunctionFeatures announces FTUP: the UPF allocates F-TEIDs
+func upFunctionFeatures() *wire.IE {
+	return wire.NewUPFunctionFeatures(0x01, 0x00)
+}
+
+// associationUpdate acknowledges an update of an established association
+func (n *n4Server) associationUpdate(m *wire.Message) *wire.Message {
+	reply := func(ies ...*wire.IE) *wire.Message {
+		ies = append([]*wire.IE{wire.NewNodeID(n.nodeID)}, ies...)
+		return wire.NewMessage(wire.MsgAssociationUpdateResponse, 0, m.Sequence, ies...)
+	}
+
+	nodeID, err := peerNodeID(m)
+	if err != nil {
+		return reply(rejection(err)...)
+	}
+	n.mu.Lock()
+	p, ok := n.peers[nodeID]
+	associated := ok && p.state == peerAssociated
+	n.mu.Unlock()
+	if !associated {
+		return reply(wire.NewCause(wire.CauseNoEstablishedAssociation))
+	}
+	return reply(wire.NewCause(wire.CauseRequestAccepted))
+}
+
+// associationRelease tears down an association and every session it owns.
+// Configured peers go back to idle and are associated again later.
+func (n *n4Server) associationRelease(m *wire.Message) *wire.Message {
+	reply := func(ies ...*wire.IE) *wire.Message {
+		ies = append([]*wire.IE{wire.NewNodeID(n.nodeID)}, ies...)
+		return wire.NewMessage(wire.MsgAssociationReleaseResponse, 0, m.Sequence, ies...)
+	}
+
+	nodeID, err := peerNodeID(m)
+	if err != nil {
+		return reply(rejection(err)...)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	p, ok := n.peers[nodeID]
+	if !ok || p.state == peerIdle {
+		return reply(wire.NewCause(wire.CauseNoEstablishedAssociation))
+	}
+	n.purgeLocked(nodeID, "AssociationRelease")
+	if p.configured {
+		n.setStateLocked(p, peerIdle)
+	} else {
+		delete(n.peers, nodeID)
+	}
+
+	log.Printf("👋 Association with %s released", nodeID)
+	return reply(wire.NewCause(wire.CauseRequestAccepted))
+}
+
+// maintainPeers associates configured peers and, when enable_hbTimer is set,
+// sends heartbeats to associated ones until the server stops
+func (n *n4Server) maintainPeers() {
+	ticker := time.NewTicker(n.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		n.mu.Lock()
+		for _, p := range n.peers {
+			if p.busy {
+				continue
+			}
+			switch {
+			case p.configured && (p.state == peerIdle || p.state == peerLost):
+				p.busy = true
+				go n.associate(p)
+			case p.state == peerAssociated && n.heartbeats:
+				p.busy = true
+				go n.heartbeat(p)
+			}
+		}
+		n.mu.Unlock()
+
+		select {
+		case <-ticker.C:
+		case <-n.done:
+			return
+		}
+	}
+}
+
+// associate sends an Association Setup Request to a peer
+func (n *n4Server) associate(p *peer) {
+	n.mu.Lock()
+	addr := p.addr
+	n.setStateLocked(p, peerAssociating)
+	n.mu.Unlock()
+
+	resp, err := n.request(addr, wire.NewMessage(wire.MsgAssociationSetupRequest, 0, 0,
+		wire.NewNodeID(n.nodeID), wire.NewRecoveryTimeStamp(n.recovery), upFunctionFeatures()))
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	p.busy = false
+	if p.state != peerAssociating {
+		return // the peer associated by itself in the meantime
+	}
+	if err == nil {
+		var cause wire.Cause
+		if cause, err = resp.Find(wire.IECause).Cause(); err == nil && cause != wire.CauseRequestAccepted {
+			err = errors.New("rejected with cause " + strconv.Itoa(int(cause)))
+		}
+	}
+	if err != nil {
+		log.Printf("⚠️ Association setup with %s failed: %v", p.nodeID, err)
+		n.setStateLocked(p, peerIdle)
+		return
+	}
+
+	if id, err := resp.Find(wire.IENodeID).NodeID(); err == nil {
+		n.renameLocked(p, id.String())
+	}
+	if recovery, err := resp.Find(wire.IERecoveryTimeStamp).RecoveryTimeStamp(); err == nil {
+		n.restartedLocked(p, recovery)
+	}
+	n.setStateLocked(p, peerAssociated)
+}
+
+// heartbeat exchanges a heartbeat with an associated peer. A peer that does
+// not answer is lost; one that answers with a new recovery time stamp has
+// restarted and must associate again.
+func (n *n4Server) heartbeat(p *peer) {
+	n.mu.Lock()
+	addr := p.addr
+	n.mu.Unlock()
+
+	resp, err := n.request(addr, wire.NewMessage(wire.MsgHeartbeatRequest, 0, 0,
+		wire.NewRecoveryTimeStamp(n.recovery)))
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	p.busy = false
+	if err != nil {
+		if p.state == peerAssociated {
+			n.setStateLocked(p, peerLost)
+		}
+		return
+	}
+	p.lastHeartbeat = time.Now()
+	if recovery, err := resp.Find(wire.IERecoveryTimeStamp).RecoveryTimeStamp(); err == nil && n.restartedLocked(p, recovery) {
+		n.setStateLocked(p, peerIdle)
+	}
+}
+
+// listPeers describes every peer, ordered by Node ID
+func (n *n4Server) listPeers() []*pb.PFCPPeer {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	sessions := make(map[string]int32)
+	for _, s := range n.sessions {
+		sessions[s.nodeID]++
+	}
+	unix := func(t time.Time) int64 {
+		if t.IsZero() {
+			return 0
+		}
+		return t.Unix()
+	}
+
+	peers := make([]*pb.PFCPPeer, 0, len(n.peers))
+	for _, p := range n.peers {
+		info := &pb.PFCPPeer{
+			NodeId:            p.nodeID,
+			State:             p.state.String(),
+			Configured:        p.configured,
+			StateSinceUnix:    unix(p.since),
+			LastHeartbeatUnix: unix(p.lastHeartbeat),
+			RecoveryUnix:      unix(p.recovery),
+			Sessions:          sessions[p.nodeID],
+		}
+		if p.addr != nil {
+			info.Address = p.addr.String()
+		}
+		peers = append(peers, info)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].NodeId < peers[j].NodeId })
+	return peers
+}
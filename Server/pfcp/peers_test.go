@@ -0,0 +1,105 @@
+package pfcp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	wire "upf/pkg/pfcp"
+)
+
+func TestHeartbeatInterval(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", defaultHeartbeatInterval},
+		{"750ms", 750 * time.Millisecond},
+		{"30s", 30 * time.Second},
+		{"soon", defaultHeartbeatInterval},
+		{"-1s", defaultHeartbeatInterval},
+	} {
+		cfg := n4Config()
+		cfg.HeartBeatInterval = tc.value
+		if got := newN4Server(cfg).heartbeatInterval; got != tc.want {
+			t.Errorf("heart_beat_interval %q: %s, want %s", tc.value, got, tc.want)
+		}
+	}
+}
+
+// fakeSMF answers Association Setup Requests on a loopback address as nodeID
+// started at recovery
+func fakeSMF(t *testing.T, ip net.IP, nodeID string, recovery time.Time) *net.UDPAddr {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: ip})
+	if err != nil {
+		t.Skipf("cannot listen on %s: %v", ip, err)
+	}
+	tr := wire.NewTransport(conn, func(m *wire.Message, from *net.UDPAddr) *wire.Message {
+		if m.Type != wire.MsgAssociationSetupRequest {
+			return nil
+		}
+		return wire.NewMessage(wire.MsgAssociationSetupResponse, 0, m.Sequence,
+			wire.NewNodeID(nodeID), wire.NewCause(wire.CauseRequestAccepted), wire.NewRecoveryTimeStamp(recovery))
+	})
+	go tr.Serve()
+	t.Cleanup(func() { tr.Close() })
+	return tr.LocalAddr()
+}
+
+func TestRenameMergesKnownPeer(t *testing.T) {
+	const smf = "smf.test"
+	started := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, tc := range []struct {
+		name     string
+		recovery time.Time // Recovery announced to the association of the configured peer
+		sessions int32     // Sessions left afterwards
+	}{
+		{"same SMF", started, 1},
+		{"restarted SMF", started.Add(time.Minute), 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			configured := fakeSMF(t, net.IPv4(127, 0, 0, 2), smf, tc.recovery)
+			cfg := n4Config()
+			cfg.CPInterface.Peers = []string{configured.String()}
+			n, c := startN4(t, cfg)
+
+			// The SMF associates by itself from another address and opens a session
+			request(t, c, wire.NewMessage(wire.MsgAssociationSetupRequest, 0, 0,
+				wire.NewNodeID(smf), wire.NewRecoveryTimeStamp(started)),
+				wire.MsgAssociationSetupResponse, wire.CauseRequestAccepted)
+			request(t, c, wire.NewMessage(wire.MsgSessionEstablishmentRequest, 0, 0,
+				wire.NewNodeID(smf), wire.NewFSEID(1, net.IPv4(127, 0, 0, 1)),
+				wire.NewGrouped(wire.IECreatePDR, wire.NewPDRID(1),
+					wire.NewGrouped(wire.IEPDI, wire.NewSourceInterface(wire.InterfaceAccess), wire.NewFTEIDChoose(false)),
+					wire.NewFARID(1)),
+				wire.NewGrouped(wire.IECreateFAR, wire.NewFARID(1), wire.NewApplyAction(wire.ApplyForward))),
+				wire.MsgSessionEstablishmentResponse, wire.CauseRequestAccepted)
+			if peers := n.listPeers(); len(peers) != 2 {
+				t.Fatalf("%d peers before the configured one associates, want 2", len(peers))
+			}
+
+			// The configured peer turns out to be the same SMF; it takes over the
+			// association, including the recovery time stamp that tells restarts
+			n.mu.Lock()
+			p := n.peers[configured.IP.String()]
+			n.mu.Unlock()
+			if p == nil {
+				t.Fatalf("configured peer %s missing", configured)
+			}
+			n.associate(p)
+
+			peers := n.listPeers()
+			if len(peers) != 1 {
+				t.Fatalf("peers after the merge: %v, want one", peers)
+			}
+			got := peers[0]
+			if got.NodeId != smf || !got.Configured || got.State != "associated" || got.Address != configured.String() {
+				t.Fatalf("merged peer %v", got)
+			}
+			if got.Sessions != tc.sessions || int32(n.rules.Len()) != tc.sessions {
+				t.Fatalf("%d sessions (%d stored), want %d", got.Sessions, n.rules.Len(), tc.sessions)
+			}
+		})
+	}
+}
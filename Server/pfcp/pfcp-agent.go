@@ -1,91 +1,300 @@
 /*
 Package pfcp implements the PFCP (Packet Forwarding Control Protocol) agent for the UPF service.
-It provides gRPC endpoints for streaming flow measurement data and simulates packet forwarding
-statistics for testing and demonstration purposes.
+It serves the N4 interface over UDP 8805, provisioning the shared session and subscriber
+stores from the sessions an SMF establishes, and provides gRPC endpoints for streaming
+flow measurement data simulated for testing and demonstration purposes.
 */
 package pfcp
 
 import (
+	"context"
 	"log"
-	"math/rand"
 	"net"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"upf/Server/config"
+	"upf/Server/imsi"
+	"upf/Server/metrics"
+	"upf/Server/rule"
 	pb "upf/pkg/proto"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // flowmeasuredata holds packet flow statistics and related IMSI information
 type flowmeasuredata struct {
-	Total_Packets uint64   // Total number of packets (Rx + Tx)
-	Rx_Packet     uint64   // Number of received packets
-	Tx_Packet     uint64   // Number of transmitted packets
-	Rx_Speed      uint64   // Current receive speed
-	Tx_Speed      uint64   // Current transmit speed
-	Total_Speed   uint64   // Total speed (Rx + Tx)
-	All_IMSI      []string // List of all IMSIs associated with the flow
+	Total_Packets      uint64        // Total number of packets (Rx + Tx)
+	Rx_Packet          uint64        // Number of received packets
+	Tx_Packet          uint64        // Number of transmitted packets
+	Rx_Bytes           uint64        // Number of received bytes
+	Tx_Bytes           uint64        // Number of transmitted bytes
+	Rx_Speed           uint64        // Receive speed over the last interval, bit/s
+	Tx_Speed           uint64        // Transmit speed over the last interval, bit/s
+	Total_Speed        uint64        // Total speed (Rx + Tx), bit/s
+	Rx_Packet_Rate     float64       // Receive packet rate over the last interval, packet/s
+	Tx_Packet_Rate     float64       // Transmit packet rate over the last interval, packet/s
+	Rx_Speed_Avg       float64       // EWMA-smoothed receive speed, bit/s
+	Tx_Speed_Avg       float64       // EWMA-smoothed transmit speed, bit/s
+	Rx_Packet_Rate_Avg float64       // EWMA-smoothed receive packet rate, packet/s
+	Tx_Packet_Rate_Avg float64       // EWMA-smoothed transmit packet rate, packet/s
+	Rx_QoS             qosCounters   // Received packets by the color the policers gave them
+	Tx_QoS             qosCounters   // Transmitted packets by the color the policers gave them
+	Measured           time.Time     // When the counters were measured
+	Interval           time.Duration // Interval the rates were derived over
+	All_IMSI           []string      // List of all IMSIs associated with the flow
 }
 
 // pfcpserver implements the gRPC Request service for PFCP management
 type pfcpserver struct {
 	pb.UnimplementedRequestServer
-	flowData map[string]flowmeasuredata // Map of FSEID to flow measurement data
-	imsi     []string                   // List of available IMSIs
-	count    uint64                     // Counter for updates sent
+	flows      *flowEngine      // Flow measurement shared by all streams
+	usage      *usageMeter      // URR usage measurement and reporting
+	aggregates *aggregator      // Flow counters rolled up per IMSI, DNN, slice and UPF
+	history    *historyRecorder // Flow history, nil without measure_flow or if flow_history is invalid
+	top        *topTalkers      // Sessions ranked by traffic, nil without measure_flow
+	alerts     *alertEngine     // Alert rules, nil unless alerts.rules_file loaded
+	anomalies  *anomalyDetector // Traffic baselines of the sessions, nil without measure_flow
+	n4         *n4Server        // N4 endpoint, nil if it failed to start
+	slices     *slicePolicers   // Slice policers of the simulator, nil outside sim mode
 }
 
 // Now a **server-streaming** method
-// PutRequest implements a server-streaming RPC that continuously sends
-// flow measurement updates to the client for a specific FSEID
+// PutRequest implements a server-streaming RPC that continuously sends flow
+// measurement updates to the client for the F-SEIDs, IMSI or DNN it names.
+// A client that lost its stream resumes the subscription from the last count
+// it received and is sent the updates it missed, or a gap marker for those
+// that are no longer held.
 func (s *pfcpserver) PutRequest(req *pb.FlowRequest, stream pb.Request_PutRequestServer) error {
+	var sub *flowSubscriber
+	var cursor uint64
+	if req.SubscriptionId != 0 {
+		if found, ok := s.flows.lookup(req.SubscriptionId); ok {
+			sub, cursor = found, req.ResumeFrom
+		} else {
+			// The subscription expired; say so before starting a new one
+			err := stream.Send(&pb.Reply{SubscriptionId: req.SubscriptionId, Gap: true, GapFrom: req.ResumeFrom + 1})
+			if err != nil {
+				return err
+			}
+			log.Printf("⚠️ Flow subscription %d expired, starting over", req.SubscriptionId)
+		}
+	}
+	if sub == nil {
+		subscription, err := parseFlowRequest(req)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		// Subscribe to the flows; the engine measures each once for all its streams
+		sub = s.flows.subscribe(subscription)
+	}
 
+	attached := sub.attach()
+	defer sub.detach(attached)
+
+	reported := cursor // Highest lost count a gap marker was sent for
 	for {
-		s.count++
-		// get & initialize flow data
-		data, ok := s.flowData[req.Fseid]
-		if !ok {
-			// Initialize new flow data if none exists
-			data = flowmeasuredata{
-				Total_Packets: 0,
-				Rx_Packet:     0,
-				Tx_Packet:     0,
-				Rx_Speed:      0,
-				Tx_Speed:      0,
-				Total_Speed:   0,
-				All_IMSI:      s.imsi,
+		updates, lost := sub.since(cursor)
+		if lost > reported {
+			err := stream.Send(&pb.Reply{SubscriptionId: sub.id, Gap: true, GapFrom: cursor + 1, GapTo: lost})
+			if err != nil {
+				log.Printf("❌ Error sending stream: %v", err)
+				return err
+			}
+			log.Printf("⚠️ Flow subscription %d lost updates %d-%d", sub.id, cursor+1, lost)
+			reported = lost
+		}
+
+		for _, u := range updates {
+			// Prepare and send the flow statistics update
+			if err := stream.Send(flowReply(sub.id, u, sub.fields)); err != nil {
+				log.Printf("❌ Error sending stream: %v", err)
+				return err
 			}
+			cursor = u.count
+
+			log.Printf("📤 Sent update %d for %s: Rx=%d Tx=%d Total=%d",
+				u.count, u.fseid, u.data.Rx_Packet, u.data.Tx_Packet, u.data.Total_Packets)
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-attached:
+			return status.Errorf(codes.Aborted, "flow subscription %d was resumed by another stream", sub.id)
+		case <-sub.ready:
 		}
+	}
+}
+
+// startSimulator creates the simulated sessions of the sim block and writes
+// them into the shared session and subscriber stores
+func startSimulator(cfg *config.UPFConfig) (*simulator, error) {
+	sim, err := newSimulator(cfg.Sim, cfg.QCIQoS, cfg.CPInterface.DNN, flowTick)
+	if err != nil {
+		return nil, err
+	}
+	// Police the slice at slice_rate_limit_config, following the limits set at runtime
+	sim.slice = newSlicePolicers(cfg.P4RTCInterface.SliceID, cfg.SliceRateLimit, cfg.Sim.PktSize)
+	config.WatchSliceRateLimit(sim.slice.set)
+	// Provision once the rule agent applied the table sizes and seeded its samples
+	select {
+	case <-rule.DefaultStore().Ready():
+	case <-time.After(simReadyWait):
+		log.Printf("⚠️ Rule store not set up after %v, provisioning without its table sizes", simReadyWait)
+	}
+	n, err := sim.provision(rule.DefaultStore(), imsi.DefaultStore())
+	if err != nil {
+		log.Printf("⚠️ Provisioned %d of %d simulated sessions: %v", n, cfg.Sim.MaxSessions, err)
+	}
+	log.Printf("🧪 Simulating %d flows over %d sessions (seed %d)", cfg.Sim.TotalFlows, n, sim.seed)
+	return sim, nil
+}
 
-		// simulate dynamic updates
-		data.Rx_Packet += uint64(rand.Intn(50))  // Random RX packet increment
-		data.Tx_Packet += uint64(rand.Intn(50))  // Random TX packet increment
-		data.Rx_Speed += uint64(rand.Intn(1000)) // Random RX speed change
-		data.Tx_Speed += uint64(rand.Intn(1000)) // Random TX speed change
-		// ✅ store the updated struct back into the map!
-		s.flowData[req.Fseid] = data
-
-		// Prepare and send the flow statistics update
-		err := stream.Send(&pb.Reply{
-			Total_Packets: data.Rx_Packet + data.Tx_Packet,
-			Rx_Packet:     data.Rx_Packet,
-			Tx_Packet:     data.Tx_Packet,
-			Rx_Speed:      data.Rx_Speed,
-			Tx_Speed:      data.Tx_Speed,
-			Total_Speed:   data.Rx_Speed + data.Tx_Speed,
-			All_IMSI:      data.All_IMSI,
-			Count:         s.count,
-		})
+// GetSlicePolicers reports the rate limits and drop counters of the slice
+// policers the simulated traffic passes
+func (s *pfcpserver) GetSlicePolicers(ctx context.Context, req *pb.SlicePolicersRequest) (*pb.SlicePolicersReply, error) {
+	if s.slices == nil {
+		return nil, status.Error(codes.Unavailable, "slices are only policed in sim mode")
+	}
+	return &pb.SlicePolicersReply{Policers: s.slices.list()}, nil
+}
+
+// ListPFCPPeers reports the association state, last heartbeat and session
+// count of every PFCP peer of the N4 endpoint
+func (s *pfcpserver) ListPFCPPeers(ctx context.Context, req *pb.PFCPPeersRequest) (*pb.PFCPPeersReply, error) {
+	if s.n4 == nil {
+		return nil, status.Error(codes.Unavailable, "PFCP N4 endpoint is not running")
+	}
+	return &pb.PFCPPeersReply{Peers: s.n4.listPeers()}, nil
+}
+
+// StreamUsageReports streams the usage reports of the URRs of the sessions
+// the request selects as their reporting triggers fire
+func (s *pfcpserver) StreamUsageReports(req *pb.UsageReportsRequest, stream pb.Request_StreamUsageReportsServer) error {
+	sub := s.usage.subscribe(req.Fseid, req.Imsi)
+	defer s.usage.unsubscribe(sub)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case r := <-sub.reports:
+			if err := stream.Send(usageEvent(r)); err != nil {
+				log.Printf("❌ Error sending stream: %v", err)
+				return err
+			}
+			log.Printf("📤 Sent usage report %d of %s %s: %v", r.Sequence, r.fseid, r.urrID, triggerNames(r.Trigger))
+		}
+	}
+}
+
+// GetFlowHistory returns the recorded counters of a session over a time
+// range, with the rates over every step
+func (s *pfcpserver) GetFlowHistory(ctx context.Context, req *pb.FlowHistoryRequest) (*pb.FlowHistoryReply, error) {
+	if s.history == nil {
+		return nil, status.Error(codes.Unavailable, "flow history is not recorded")
+	}
+	now := time.Now()
+	end := now
+	if req.EndUnix != 0 {
+		end = time.Unix(req.EndUnix, 0)
+	}
+	start := end.Add(-historyDefaultRange)
+	if req.StartUnix != 0 {
+		start = time.Unix(req.StartUnix, 0)
+	}
+	return s.history.query(req.Fseid, start, end, time.Duration(req.StepSeconds)*time.Second, now)
+}
+
+// GetAggregateStats returns the flow counters of the sessions rolled up by
+// the requested grouping
+func (s *pfcpserver) GetAggregateStats(ctx context.Context, req *pb.AggregateStatsRequest) (*pb.AggregateStatsReply, error) {
+	return s.aggregates.snapshot(req.GroupBy, req.Filter)
+}
+
+// StreamAggregateStats sends the aggregates of the requested grouping at
+// the requested interval
+func (s *pfcpserver) StreamAggregateStats(req *pb.AggregateStatsRequest, stream pb.Request_StreamAggregateStatsServer) error {
+	interval, err := parseInterval(req.IntervalMs)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		reply, err := s.aggregates.snapshot(req.GroupBy, req.Filter)
 		if err != nil {
+			return err
+		}
+		if err := stream.Send(reply); err != nil {
 			log.Printf("❌ Error sending stream: %v", err)
 			return err
 		}
 
-		log.Printf("📤 Sent update: Rx=%d Tx=%d Total=%d",
-			data.Rx_Packet, data.Tx_Packet, data.Rx_Packet+data.Tx_Packet)
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// TopSessions returns the sessions that moved the most traffic over the
+// requested window, heaviest first
+func (s *pfcpserver) TopSessions(ctx context.Context, req *pb.TopSessionsRequest) (*pb.TopSessionsReply, error) {
+	if s.top == nil {
+		return nil, status.Error(codes.Unavailable, "sessions are not ranked, measure_flow is off")
+	}
+	return s.top.top(req.Metric, int(req.N), time.Duration(req.WindowSeconds)*time.Second)
+}
+
+// StreamAlerts streams the alerts the request selects, starting with those
+// already pending or firing, as they change state
+func (s *pfcpserver) StreamAlerts(req *pb.AlertsRequest, stream pb.Request_StreamAlertsServer) error {
+	if s.alerts == nil {
+		return status.Error(codes.Unavailable, "alerting is not configured")
+	}
+	sub := s.alerts.subscribe(req.Rule, req.Fseid)
+	defer s.alerts.unsubscribe(sub)
 
-		// Wait before sending next update
-		time.Sleep(2 * time.Second)
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case ev := <-sub.events:
+			if err := stream.Send(ev); err != nil {
+				log.Printf("❌ Error sending stream: %v", err)
+				return err
+			}
+		}
+	}
+}
+
+// StreamAnomalies streams the anomalies the request selects, starting with
+// those still open, as they start and end
+func (s *pfcpserver) StreamAnomalies(req *pb.AnomaliesRequest, stream pb.Request_StreamAnomaliesServer) error {
+	if s.anomalies == nil {
+		return status.Error(codes.Unavailable, "anomalies are not detected, measure_flow is off")
+	}
+	sub := s.anomalies.subscribe(req.Fseid, req.Imsi, req.MinScore)
+	defer s.anomalies.unsubscribe(sub)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case ev := <-sub.events:
+			if err := stream.Send(ev); err != nil {
+				log.Printf("❌ Error sending stream: %v", err)
+				return err
+			}
+		}
 	}
 }
 
@@ -99,28 +308,149 @@ func StartPFCPAgent(port string) error {
 	}
 
 	// Initialize gRPC server
-	s := grpc.NewServer()
+	s := grpc.NewServer(metrics.ServerOptions("pfcp")...)
 
 	// Define sample IMSI list
 	imsiList := []string{"IMSI1", "IMSI2", "IMSI3"}
 
 	// Initialize the PFCP server with sample data
-	srv := &pfcpserver{
-		imsi: imsiList,
-		flowData: map[string]flowmeasuredata{
-			"exampleFSEID": {
-				Rx_Packet: 100,  // Initial RX packet count
-				Tx_Packet: 200,  // Initial TX packet count
-				Rx_Speed:  3000, // Initial RX speed
-				Tx_Speed:  4000, // Initial TX speed
-				All_IMSI:  imsiList,
-			},
-		},
+	flows := newFlowEngine(imsiList, rule.DefaultStore())
+	flows.seed("exampleFSEID", flowmeasuredata{
+		Total_Packets: 300,    // Initial total packet count
+		Rx_Packet:     100,    // Initial RX packet count
+		Tx_Packet:     200,    // Initial TX packet count
+		Rx_Bytes:      64000,  // Initial RX byte count
+		Tx_Bytes:      128000, // Initial TX byte count
+		All_IMSI:      imsiList,
+	})
+	// Feed the flows from the traffic simulator in sim mode
+	var slices *slicePolicers
+	cfg, err := config.LoadConfig(config.ConfigFile)
+	if err != nil {
+		log.Printf("❌ Failed to load config, flows carry random traffic: %v", err)
+		cfg = &config.UPFConfig{}
+	} else if cfg.Mode == "sim" {
+		if sim, err := startSimulator(cfg); err != nil {
+			log.Printf("❌ Traffic simulator failed: %v", err)
+		} else {
+			flows.setSource(sim)
+			slices = sim.slice
+		}
+	}
+	// Measure the URRs of every session against the flow counters
+	usage := newUsageMeter(rule.DefaultStore())
+	flows.addMeter(usage)
+	// Roll the flow counters up per IMSI, DNN, slice and, with measure_upf, the whole UPF
+	aggregates := newAggregator(rule.DefaultStore(), cfg.P4RTCInterface.SliceID, cfg.MeasureUPF, cfg.MeasureFlow)
+	flows.addMeter(aggregates)
+	srv := &pfcpserver{flows: flows, usage: usage, aggregates: aggregates, slices: slices}
+
+	// Expose the aggregates, sessions and table usage at /metrics; per-session
+	// series come from the per-flow aggregates kept with measure_flow
+	perSession := cfg.Metrics.PerSession
+	if perSession && !cfg.MeasureFlow {
+		log.Printf("⚠️ metrics.per_session needs measure_flow, exporting aggregates only")
+		perSession = false
+	}
+	registerMetrics(aggregates, rule.DefaultStore(), perSession)
+	if slices != nil {
+		registerSliceMetrics(slices)
+	}
+
+	// Record the history of the sessions' flows for GetFlowHistory, rank them
+	// for TopSessions and learn their baselines for StreamAnomalies; without
+	// measure_flow only the aggregates are kept
+	if !cfg.MeasureFlow {
+		log.Printf("📉 measure_flow is off: no flow history, session ranking or anomaly detection")
+	} else {
+		srv.top = newTopTalkers(rule.DefaultStore())
+		flows.addMeter(srv.top)
+		srv.anomalies = newAnomalyDetector(rule.DefaultStore())
+		flows.addMeter(srv.anomalies)
+		if history, err := newHistoryRecorder(rule.DefaultStore(), cfg.FlowHistory); err != nil {
+			log.Printf("❌ Flow history disabled: %v", err)
+		} else {
+			flows.addMeter(history)
+			srv.history = history
+		}
+	}
+
+	// Evaluate the alert rules against the flows and sessions
+	if cfg.Alerts.RulesFile != "" {
+		if rules, err := loadAlertRules(cfg.Alerts.RulesFile, cfg.Sim.MaxSessions); err != nil {
+			log.Printf("❌ Alerting disabled: %v", err)
+		} else {
+			srv.alerts = newAlertEngine(rule.DefaultStore(), rules, cfg.Sim.MaxSessions, cfg.Alerts.Webhook)
+			flows.addMeter(srv.alerts)
+			log.Printf("🚨 Loaded %d alert rules from %s", len(rules), cfg.Alerts.RulesFile)
+		}
+	}
+
+	// Export flow records of every session to the IPFIX collector
+	if cfg.IPFIX.Collector != "" {
+		if settings, err := parseIPFIXConfig(cfg.IPFIX); err != nil {
+			log.Printf("❌ IPFIX export disabled: %v", err)
+		} else {
+			flows.addMeter(newIPFIXExporter(rule.DefaultStore(), settings))
+			log.Printf("📤 Exporting IPFIX records to %s over %s every %s", settings.collector, settings.network, settings.activeTimeout)
+		}
 	}
 
+	// Record the aggregates and, with measure_flow, every session to files
+	var recorder *flowRecorder
+	if cfg.Recorder.Dir != "" {
+		settings, err := parseRecorderConfig(cfg.Recorder)
+		if err == nil {
+			recorder, err = newFlowRecorder(rule.DefaultStore(), aggregates, cfg.MeasureFlow, settings)
+		}
+		if err != nil {
+			log.Printf("❌ Flow recorder disabled: %v", err)
+			recorder = nil
+		} else {
+			flows.addMeter(recorder)
+			log.Printf("💾 Recording flow statistics to %s as %s every %s", cfg.Recorder.Dir, settings.options.Format, settings.interval)
+		}
+	}
+
+	// Start the N4 endpoint speaking PFCP to the SMF, which is sent the usage reports
+	if n4, err := listenN4(N4Address); err != nil {
+		log.Printf("❌ PFCP N4 endpoint failed: %v", err)
+	} else {
+		srv.n4 = n4
+		n4.usage, usage.onReport = usage, n4.reportUsage
+		go func() {
+			if err := n4.serve(); err != nil {
+				log.Printf("❌ PFCP N4 endpoint failed: %v", err)
+			}
+		}()
+	}
+
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		flows.run(stop)
+		close(stopped)
+	}()
+
+	// Stop on SIGINT or SIGTERM, so the recorded files are closed
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		<-quit
+		log.Println("Shutting down PFCP Agent...")
+		s.Stop()
+	}()
+
 	// Register the PFCP server with gRPC
 	pb.RegisterRequestServer(s, srv)
 
 	log.Printf("PFCP Agent listening on port %s...", port)
-	return s.Serve(lis)
+	err = s.Serve(lis)
+
+	// The flow engine feeds the recorder, stop it first
+	close(stop)
+	<-stopped
+	if recorder != nil {
+		recorder.close()
+	}
+	return err
 }
@@ -0,0 +1,210 @@
+package pfcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"upf/Server/rule"
+	pb "upf/pkg/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// replyStream is the server side of a PutRequest stream, handing the replies
+// sent on it to the test
+type replyStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	replies chan *pb.Reply
+}
+
+func (s *replyStream) Context() context.Context { return s.ctx }
+
+func (s *replyStream) Send(r *pb.Reply) error {
+	s.replies <- r
+	return nil
+}
+
+// flowStream is a PutRequest running on a replyStream
+type flowStream struct {
+	*replyStream
+	cancel context.CancelFunc
+	done   chan struct{} // Closed when PutRequest returned
+	err    error         // What PutRequest returned
+}
+
+// putRequest runs PutRequest for req until the test ends or the stream is cancelled
+func putRequest(t *testing.T, s *pfcpserver, req *pb.FlowRequest) *flowStream {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	fs := &flowStream{
+		replyStream: &replyStream{ctx: ctx, replies: make(chan *pb.Reply, 4*flowReplayDepth)},
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+	go func() {
+		fs.err = s.PutRequest(req, fs.replyStream)
+		close(fs.done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-fs.done
+	})
+	return fs
+}
+
+// recv returns the next reply of the stream
+func (fs *flowStream) recv(t *testing.T) *pb.Reply {
+	t.Helper()
+	select {
+	case r := <-fs.replies:
+		return r
+	case <-fs.done:
+		t.Fatalf("stream ended: %v", fs.err)
+	case <-time.After(time.Second):
+		t.Fatal("no reply within a second")
+	}
+	return nil
+}
+
+// recvCounts reads n flow updates and checks that they carry the counts from first on
+func (fs *flowStream) recvCounts(t *testing.T, first uint64, n int) {
+	t.Helper()
+	for i := range n {
+		r := fs.recv(t)
+		if r.GetGap() || r.GetCount() != first+uint64(i) {
+			t.Fatalf("got update %d (gap %v), want update %d", r.GetCount(), r.GetGap(), first+uint64(i))
+		}
+	}
+}
+
+// wait waits for PutRequest to return and returns its error
+func (fs *flowStream) wait(t *testing.T) error {
+	t.Helper()
+	select {
+	case <-fs.done:
+		return fs.err
+	case <-time.After(time.Second):
+		t.Fatal("stream did not end")
+	}
+	return nil
+}
+
+// flowServer returns a server whose engine is ticked by the test, with one
+// stored session 0x1 that moves on every tick. The ticks start a second
+// ahead, so every stream subscribed by then is due at the first one.
+func flowServer(t *testing.T) (*pfcpserver, func(n int)) {
+	t.Helper()
+	rules := rule.NewStore(4)
+	putSession(t, rules, "0x1", "IMSI1", "internet")
+	e := newFlowEngine(nil, rules)
+	e.setSource(&stillTraffic{moving: map[string]bool{"0x1": true}})
+
+	now := time.Now().Add(time.Second)
+	tick := func(n int) {
+		for range n {
+			now = now.Add(flowTick)
+			e.tick(now)
+		}
+	}
+	return &pfcpserver{flows: e}, tick
+}
+
+// TestPutRequestResume checks that a stream resuming a subscription is sent
+// the updates it missed, with a gap marker for those that rolled out of the
+// replay buffer
+func TestPutRequestResume(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		detached int    // Ticks without a stream
+		gapTo    uint64 // Last update lost, 0 for none
+	}{
+		{"replayed", 5, 0},
+		{"rolled over", flowReplayDepth + 6, 9},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, tick := flowServer(t)
+			first := putRequest(t, s, &pb.FlowRequest{Fseid: "0x1", IntervalMs: 100})
+			id := first.recv(t).GetSubscriptionId()
+			tick(2)
+			first.recvCounts(t, 2, 2)
+			first.cancel()
+			first.wait(t)
+
+			tick(tc.detached)
+			resumed := putRequest(t, s, &pb.FlowRequest{SubscriptionId: id, ResumeFrom: 3})
+			from := uint64(4)
+			if tc.gapTo != 0 {
+				r := resumed.recv(t)
+				if !r.GetGap() || r.GetSubscriptionId() != id || r.GetGapFrom() != 4 || r.GetGapTo() != tc.gapTo {
+					t.Fatalf("got %v, want a gap marker for updates 4 to %d", r, tc.gapTo)
+				}
+				from = tc.gapTo + 1
+			}
+			resumed.recvCounts(t, from, int(3+uint64(tc.detached)-from+1))
+
+			// The resumed stream goes on with new updates
+			tick(1)
+			resumed.recvCounts(t, 4+uint64(tc.detached), 1)
+		})
+	}
+}
+
+// TestPutRequestExpired checks that resuming a subscription that is gone
+// reports the gap and starts a new subscription for the request's flows
+func TestPutRequestExpired(t *testing.T) {
+	s, _ := flowServer(t)
+	fs := putRequest(t, s, &pb.FlowRequest{SubscriptionId: 99, ResumeFrom: 5, Fseid: "0x1"})
+	r := fs.recv(t)
+	if !r.GetGap() || r.GetSubscriptionId() != 99 || r.GetGapFrom() != 6 || r.GetGapTo() != 0 {
+		t.Fatalf("got %v, want an open gap marker from update 6 of subscription 99", r)
+	}
+	r = fs.recv(t)
+	if r.GetGap() || r.GetSubscriptionId() == 99 || r.GetFseid() != "0x1" || r.GetCount() != 1 {
+		t.Fatalf("got %v, want the first update of a new subscription", r)
+	}
+}
+
+// TestPutRequestTakeover checks that a stream resuming a subscription ends
+// the stream that held it with Aborted
+func TestPutRequestTakeover(t *testing.T) {
+	s, tick := flowServer(t)
+	first := putRequest(t, s, &pb.FlowRequest{Fseid: "0x1", IntervalMs: 100})
+	id := first.recv(t).GetSubscriptionId()
+
+	second := putRequest(t, s, &pb.FlowRequest{SubscriptionId: id, ResumeFrom: 1})
+	if err := first.wait(t); status.Code(err) != codes.Aborted {
+		t.Fatalf("first stream ended with %v, want Aborted", err)
+	}
+	tick(1)
+	second.recvCounts(t, 2, 1)
+}
+
+// TestFlowSubscriberRetire checks that the buffer of a flow that left the
+// selection is kept until a full buffer of later updates was numbered, and
+// then reported lost
+func TestFlowSubscriberRetire(t *testing.T) {
+	sub := &flowSubscriber{replay: make(map[string]*flowReplay), ready: make(chan struct{}, 1)}
+	sub.offer([]flowUpdate{{fseid: "0x1"}, {fseid: "0x2"}})
+	current := []flowTarget{{fseid: "0x1"}}
+	for i := range flowReplayDepth {
+		sub.offer([]flowUpdate{{fseid: "0x1"}})
+		sub.retire(current)
+		if _, ok := sub.replay["0x2"]; ok != (i < flowReplayDepth-1) {
+			t.Fatalf("after %d later updates the buffer of 0x2 is kept: %v", i+1, ok)
+		}
+	}
+
+	// 0x1 rolled over update 1 and 0x2 was retired with update 2
+	updates, lost := sub.since(0)
+	if lost != 2 || len(updates) != flowReplayDepth || updates[0].count != 3 {
+		t.Fatalf("%d updates from %d, lost up to %d; want %d from 3, lost up to 2",
+			len(updates), updates[0].count, lost, flowReplayDepth)
+	}
+	if _, lost := sub.since(2); lost != 0 {
+		t.Errorf("gap reported after cursor 2 up to %d", lost)
+	}
+}
@@ -0,0 +1,328 @@
+package pfcp
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"sync"
+	"time"
+
+	"upf/Server/config"
+	pb "upf/pkg/proto"
+)
+
+const (
+	qosRefresh      = time.Second           // How often the simulated meters follow the QERs of the rule store
+	qosDefaultBurst = 10 * time.Millisecond // Burst of a rate whose QCI has no entry in qci_qos_config
+	qosDefaultQCI   = 0                     // Entry of qci_qos_config used for QCIs not listed
+)
+
+// trTCM is a two rate three color marker (RFC 2698). Rates are in bytes per
+// second and bucket sizes in bytes. The simulator offers the packets of a
+// whole tick at once, so they are metered as if spread evenly over the tick
+// rather than one by one.
+type trTCM struct {
+	cir, pir float64 // Committed and peak information rates
+	cbs, pbs float64 // Committed and peak burst sizes
+	tc, tp   float64 // Tokens in the committed and peak buckets
+}
+
+// newTrTCM returns a meter whose buckets start full
+func newTrTCM(cir, pir, cbs, pbs float64) *trTCM {
+	m := &trTCM{}
+	m.set(cir, pir, cbs, pbs)
+	m.tc, m.tp = m.cbs, m.pbs
+	return m
+}
+
+// set changes the rates and burst sizes, keeping the tokens that still fit.
+// A committed rate above the peak rate is lowered to it.
+func (m *trTCM) set(cir, pir, cbs, pbs float64) {
+	m.cir, m.pir, m.cbs, m.pbs = min(cir, pir), pir, cbs, pbs
+	m.tc, m.tp = min(m.tc, m.cbs), min(m.tp, m.pbs)
+}
+
+// mark meters the packets of size bytes that arrived over dt, colored green
+// or yellow by an earlier meter (color-aware mode; color-blind metering
+// passes every packet as green). It returns how many leave green and yellow;
+// the rest are red. A packet never leaves with a better color than it came
+// with, and when the peak bucket runs short yellow packets are dropped
+// before green ones.
+func (m *trTCM) mark(green, yellow uint64, size int, dt time.Duration) (uint64, uint64) {
+	b := float64(size)
+	tp := m.tp + m.pir*dt.Seconds()
+	tc := m.tc + m.cir*dt.Seconds()
+
+	nonRed := min(green+yellow, uint64(tp/b))
+	g := min(green, nonRed)
+	y := nonRed - g
+	if committed := uint64(tc / b); committed < g {
+		y += g - committed
+		g = committed
+	}
+	m.tp = min(m.pbs, tp-float64(nonRed)*b)
+	m.tc = min(m.cbs, tc-float64(g)*b)
+	return g, y
+}
+
+// qosCounters count the packets and bytes of one direction of a session by
+// the color the policers gave them. Green and yellow packets are forwarded,
+// yellow ones marked as exceeding the committed rate; red ones are dropped.
+type qosCounters struct {
+	green  simCounters
+	yellow simCounters
+	red    simCounters
+}
+
+// add counts packets of size bytes of each color
+func (q *qosCounters) add(green, yellow, red uint64, size int) {
+	for _, c := range []struct {
+		into    *simCounters
+		packets uint64
+	}{{&q.green, green}, {&q.yellow, yellow}, {&q.red, red}} {
+		c.into.packets += c.packets
+		c.into.bytes += c.packets * uint64(size)
+	}
+}
+
+// proto returns the counters in their protobuf form
+func (q qosCounters) proto() *pb.QosCounters {
+	return &pb.QosCounters{
+		GreenPackets:  q.green.packets,
+		GreenBytes:    q.green.bytes,
+		YellowPackets: q.yellow.packets,
+		YellowBytes:   q.yellow.bytes,
+		RedPackets:    q.red.packets,
+		RedBytes:      q.red.bytes,
+	}
+}
+
+// qosProfiles holds the entries of qci_qos_config by QCI
+type qosProfiles map[int]config.QoSConfig
+
+// newQoSProfiles checks the entries of qci_qos_config
+func newQoSProfiles(entries []config.QoSConfig) (qosProfiles, error) {
+	p := make(qosProfiles, len(entries))
+	for _, q := range entries {
+		if q.CBS < 0 || q.PBS < 0 || q.BurstDurationMS < 0 {
+			return nil, fmt.Errorf("qci_qos_config of QCI %d has a negative burst size", q.QCI)
+		}
+		if _, dup := p[q.QCI]; dup {
+			return nil, fmt.Errorf("qci_qos_config lists QCI %d twice", q.QCI)
+		}
+		p[q.QCI] = q
+	}
+	return p, nil
+}
+
+// bursts returns the committed and peak burst sizes of a QFI, in bytes, for
+// rates in bytes per second. QFIs not listed take the entry of QCI 0.
+// burst_duration_ms, when set, sizes the buckets to hold that much traffic
+// at their rates instead of cbs and pbs. ebs only applies to the single rate
+// marker and is not used. Buckets hold at least one packet.
+func (p qosProfiles) bursts(qfi uint32, cir, pir float64, size int) (cbs, pbs float64) {
+	q, ok := p[int(qfi)]
+	if !ok {
+		q, ok = p[qosDefaultQCI]
+	}
+	switch {
+	case !ok:
+		cbs, pbs = cir*qosDefaultBurst.Seconds(), pir*qosDefaultBurst.Seconds()
+	case q.BurstDurationMS > 0:
+		cbs, pbs = cir*float64(q.BurstDurationMS)/1000, pir*float64(q.BurstDurationMS)/1000
+	default:
+		cbs, pbs = float64(q.CBS), float64(q.PBS)
+	}
+	return max(cbs, float64(size)), max(pbs, float64(size))
+}
+
+// simQoS polices one direction of a simulated session: the QER meter colors
+// the offered packets against the GBR and MBR of the QER, then the session
+// AMBR meter drops what exceeds the AMBR. What they pass goes on to the
+// policer of the slice.
+type simQoS struct {
+	qer    *trTCM // nil when the QER sets no MBR
+	ambr   *trTCM // nil without a session AMBR
+	green  uint64 // Packets of the current tick passed green
+	yellow uint64 // Packets of the current tick passed yellow
+	colors qosCounters
+}
+
+// police meters packets of size bytes offered over dt, counting those the
+// session policers drop and keeping the rest for the slice policer
+func (q *simQoS) police(packets uint64, size int, dt time.Duration) {
+	q.green, q.yellow = packets, 0
+	if q.qer != nil {
+		q.green, q.yellow = q.qer.mark(q.green, q.yellow, size, dt)
+	}
+	if q.ambr != nil {
+		q.green, q.yellow = q.ambr.mark(q.green, q.yellow, size, dt)
+	}
+	q.colors.add(0, 0, packets-q.green-q.yellow, size)
+}
+
+// forward counts the packets of the current tick that every policer passed and returns how many there are
+func (q *simQoS) forward(size int) uint64 {
+	q.colors.add(q.green, q.yellow, 0, size)
+	return q.green + q.yellow
+}
+
+// setQER follows the rates of a QER given in bit/s; an MBR of 0 means no limit
+func (q *simQoS) setQER(profiles qosProfiles, qfi uint32, gbr, mbr uint64, size int) {
+	if mbr == 0 {
+		q.qer = nil
+		return
+	}
+	cir, pir := float64(gbr)/8, float64(mbr)/8
+	cbs, pbs := profiles.bursts(qfi, min(cir, pir), pir, size)
+	if q.qer == nil {
+		q.qer = newTrTCM(cir, pir, cbs, pbs)
+		return
+	}
+	q.qer.set(cir, pir, cbs, pbs)
+}
+
+// newAMBR returns the meter of a session AMBR of kbps, nil for no limit. Its
+// committed and peak rates are both the AMBR, with the bursts of QCI 0.
+func newAMBR(profiles qosProfiles, kbps int, size int) *trTCM {
+	if kbps <= 0 {
+		return nil
+	}
+	rate := float64(kbps) * 1000 / 8
+	cbs, pbs := profiles.bursts(qosDefaultQCI, rate, rate, size)
+	return newTrTCM(rate, rate, cbs, pbs)
+}
+
+// Directions of the slice policers
+const (
+	directionUplink   = "uplink"
+	directionDownlink = "downlink"
+)
+
+// slicePolicer polices one direction of the traffic of every simulated
+// session of a slice. Its committed and peak rates are both the rate limit
+// and its buckets the same size, so it drops packets but never recolors them.
+type slicePolicer struct {
+	bps     int    // Rate limit in bit/s, 0 for none
+	burst   int    // Burst size in bytes
+	meter   *trTCM // nil without a rate limit
+	passed  simCounters
+	dropped simCounters
+}
+
+// set changes the rate limit, keeping the tokens that still fit
+func (p *slicePolicer) set(bps, burst, size int) {
+	p.bps, p.burst = bps, burst
+	if bps <= 0 {
+		p.meter = nil
+		return
+	}
+	rate, bucket := float64(bps)/8, float64(max(burst, size))
+	if p.meter == nil {
+		p.meter = newTrTCM(rate, rate, bucket, bucket)
+		return
+	}
+	p.meter.set(rate, rate, bucket, bucket)
+}
+
+// police meters the packets the sessions' own policers passed over dt as one
+// aggregate and shares what the meter keeps among the sessions in proportion
+// to what each offered, counting the rest as dropped by the sessions
+func (p *slicePolicer) police(sessions []*simQoS, size int, dt time.Duration) {
+	var green, yellow uint64
+	for _, q := range sessions {
+		green, yellow = green+q.green, yellow+q.yellow
+	}
+	g, y := green, yellow
+	if p.meter != nil {
+		g, y = p.meter.mark(green, yellow, size, dt)
+	}
+	p.passed.packets += g + y
+	p.passed.bytes += (g + y) * uint64(size)
+	p.dropped.packets += green + yellow - g - y
+	p.dropped.bytes += (green + yellow - g - y) * uint64(size)
+	if g == green && y == yellow {
+		return
+	}
+
+	var prevGreen, prevYellow uint64 // Packets offered by the sessions before q
+	for _, q := range sessions {
+		keptGreen := share(prevGreen, q.green, green, g)
+		keptYellow := share(prevYellow, q.yellow, yellow, y)
+		prevGreen, prevYellow = prevGreen+q.green, prevYellow+q.yellow
+		q.colors.add(0, 0, q.green+q.yellow-keptGreen-keptYellow, size)
+		q.green, q.yellow = keptGreen, keptYellow
+	}
+}
+
+// share returns the part of kept out of total that falls to n packets
+// following prev others, rounded so that the parts of all packets add up to kept
+func share(prev, n, total, kept uint64) uint64 {
+	if total == 0 {
+		return 0
+	}
+	scale := func(x uint64) uint64 {
+		hi, lo := bits.Mul64(x, kept)
+		q, _ := bits.Div64(hi, lo, total)
+		return q
+	}
+	return scale(prev+n) - scale(prev)
+}
+
+// slicePolicers polices the uplink (N3) and downlink (N6) traffic of the
+// simulated sessions of a slice. The rate limits can change while traffic
+// flows; the new limits apply from the next tick.
+type slicePolicers struct {
+	slice string // Slice ID
+	size  int    // Packet size of the simulated traffic
+
+	mu       sync.Mutex
+	uplink   slicePolicer
+	downlink slicePolicer
+}
+
+// newSlicePolicers creates the policers of a slice with the limits of slice_rate_limit_config
+func newSlicePolicers(sliceID int, limit config.SliceRateLimit, size int) *slicePolicers {
+	p := &slicePolicers{slice: strconv.Itoa(sliceID), size: size}
+	p.set(limit)
+	return p
+}
+
+// set changes the rate limits of both directions
+func (p *slicePolicers) set(limit config.SliceRateLimit) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.uplink.set(limit.N3Bps, limit.N3BurstBytes, p.size)
+	p.downlink.set(limit.N6Bps, limit.N6BurstBytes, p.size)
+}
+
+// police passes one tick of the sessions' uplink and downlink traffic through the policers
+func (p *slicePolicers) police(uplink, downlink []*simQoS, dt time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.uplink.police(uplink, p.size, dt)
+	p.downlink.police(downlink, p.size, dt)
+}
+
+// list reports the limits and counters of both directions
+func (p *slicePolicers) list() []*pb.SlicePolicer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out []*pb.SlicePolicer
+	for _, d := range []struct {
+		direction string
+		policer   *slicePolicer
+	}{{directionUplink, &p.uplink}, {directionDownlink, &p.downlink}} {
+		out = append(out, &pb.SlicePolicer{
+			Slice:          p.slice,
+			Direction:      d.direction,
+			RateBps:        uint64(d.policer.bps),
+			BurstBytes:     uint64(d.policer.burst),
+			PassedPackets:  d.policer.passed.packets,
+			PassedBytes:    d.policer.passed.bytes,
+			DroppedPackets: d.policer.dropped.packets,
+			DroppedBytes:   d.policer.dropped.bytes,
+		})
+	}
+	return out
+}
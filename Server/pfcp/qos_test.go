@@ -0,0 +1,289 @@
+package pfcp
+
+import (
+	"testing"
+	"time"
+
+	"upf/Server/config"
+)
+
+// Meter of the tests: 10 packets of 100 bytes per second committed, 20 at
+// peak, with buckets of 30 and 50 packets
+const (
+	testCIR  = 1000
+	testPIR  = 2000
+	testCBS  = 3000
+	testPBS  = 5000
+	testSize = 100
+)
+
+// TestTrTCMMarking meters bursts around the edges of the buckets of a full
+// meter, in both color-blind and color-aware mode
+func TestTrTCMMarking(t *testing.T) {
+	for _, tc := range []struct {
+		name                  string
+		green, yellow         uint64 // Offered
+		wantGreen, wantYellow uint64
+	}{
+		{"below CBS", 29, 0, 29, 0},
+		{"at CBS", 30, 0, 30, 0},
+		{"past CBS", 31, 0, 30, 1},
+		{"at PBS", 50, 0, 30, 20},
+		{"past PBS", 51, 0, 30, 20},
+		{"far past PBS", 500, 0, 30, 20},
+		{"yellow stays yellow", 0, 10, 0, 10},
+		{"yellow at PBS", 0, 50, 0, 50},
+		{"yellow past PBS", 0, 51, 0, 50},
+		// The peak bucket runs short: the 10 packets over it are all yellow ones
+		{"yellow dropped first", 40, 20, 30, 20},
+		{"green past PBS", 60, 10, 30, 20},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTrTCM(testCIR, testPIR, testCBS, testPBS)
+			g, y := m.mark(tc.green, tc.yellow, testSize, 0)
+			if g != tc.wantGreen || y != tc.wantYellow {
+				t.Errorf("%d green and %d yellow left %d green and %d yellow, want %d and %d",
+					tc.green, tc.yellow, g, y, tc.wantGreen, tc.wantYellow)
+			}
+		})
+	}
+}
+
+// TestTrTCMRefill checks that drained buckets refill at their rates up to
+// their sizes
+func TestTrTCMRefill(t *testing.T) {
+	m := newTrTCM(testCIR, testPIR, testCBS, testPBS)
+	if g, y := m.mark(50, 0, testSize, 0); g != 30 || y != 20 {
+		t.Fatalf("draining burst left %d green and %d yellow, want 30 and 20", g, y)
+	}
+	for _, tc := range []struct {
+		dt                    time.Duration
+		offered               uint64
+		wantGreen, wantYellow uint64
+	}{
+		{0, 1, 0, 0},                      // Both buckets empty
+		{time.Second, 100, 10, 10},        // One second of each rate
+		{500 * time.Millisecond, 4, 4, 0}, // Committed tokens for 5 packets
+		{time.Hour, 0, 0, 0},              // Idle: the buckets fill up
+		{0, 100, 30, 20},                  // They held no more than CBS and PBS
+	} {
+		g, y := m.mark(tc.offered, 0, testSize, tc.dt)
+		if g != tc.wantGreen || y != tc.wantYellow {
+			t.Errorf("after %v, %d packets left %d green and %d yellow, want %d and %d",
+				tc.dt, tc.offered, g, y, tc.wantGreen, tc.wantYellow)
+		}
+	}
+}
+
+// TestTrTCMSet checks that new rates keep the tokens that still fit
+func TestTrTCMSet(t *testing.T) {
+	m := newTrTCM(testCIR, testPIR, testCBS, testPBS)
+	m.set(testCIR, testPIR, 1000, 2000)
+	if g, y := m.mark(50, 0, testSize, 0); g != 10 || y != 10 {
+		t.Errorf("shrunk buckets left %d green and %d yellow, want 10 and 10", g, y)
+	}
+	// A committed rate above the peak rate is lowered to it
+	m.set(5000, testPIR, testCBS, testPBS)
+	if m.cir != testPIR {
+		t.Errorf("committed rate %g above a peak rate of %d", m.cir, testPIR)
+	}
+}
+
+// TestQoSBursts checks the bucket sizes taken from qci_qos_config
+func TestQoSBursts(t *testing.T) {
+	profiles, err := newQoSProfiles([]config.QoSConfig{
+		{QCI: 0, CBS: 3000, EBS: 100000, PBS: 5000},
+		{QCI: 5, CBS: 3000, EBS: 100000, PBS: 5000, BurstDurationMS: 100},
+		{QCI: 9, CBS: 10, PBS: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name     string
+		qfi      uint32
+		cbs, pbs float64
+	}{
+		{"listed, EBS unused", 0, 3000, 5000},
+		{"not listed takes QCI 0", 7, 3000, 5000},
+		{"burst duration", 5, testCIR / 10, testPIR / 10},
+		{"at least a packet", 9, testSize, testSize},
+	} {
+		cbs, pbs := profiles.bursts(tc.qfi, testCIR, testPIR, testSize)
+		if cbs != tc.cbs || pbs != tc.pbs {
+			t.Errorf("%s: QFI %d got buckets of %g and %g, want %g and %g", tc.name, tc.qfi, cbs, pbs, tc.cbs, tc.pbs)
+		}
+	}
+
+	cbs, pbs := qosProfiles{}.bursts(9, 100000, 200000, testSize)
+	if want := qosDefaultBurst.Seconds(); cbs != 100000*want || pbs != 200000*want {
+		t.Errorf("without qci_qos_config got buckets of %g and %g, want %v of traffic", cbs, pbs, qosDefaultBurst)
+	}
+}
+
+// TestSimQoSAMBRAfterQER checks that the AMBR meters what the QER passed, in
+// the colors the QER gave it, so the QER spends its tokens on packets the AMBR
+// then drops and its yellow packets are dropped first
+func TestSimQoSAMBRAfterQER(t *testing.T) {
+	q := simQoS{
+		qer:  newTrTCM(testCIR, testPIR, testCBS, testPBS),
+		ambr: newTrTCM(1500, 1500, 2000, 2000),
+	}
+	for i, tc := range []struct {
+		dt                    time.Duration
+		offered               uint64
+		wantGreen, wantYellow uint64
+	}{
+		// The QER passes 30 green and 20 yellow; the AMBR keeps 20, all green
+		{0, 60, 20, 0},
+		// The QER refilled 10 green and 10 yellow, the AMBR 15: 5 yellow go.
+		// Had the AMBR run first the QER would have had tokens left, and
+		// passed all 15 green.
+		{time.Second, 60, 10, 5},
+	} {
+		q.police(tc.offered, testSize, tc.dt)
+		if q.forward(testSize) != tc.wantGreen+tc.wantYellow || q.green != tc.wantGreen || q.yellow != tc.wantYellow {
+			t.Errorf("tick %d: forwarded %d green and %d yellow, want %d and %d", i, q.green, q.yellow, tc.wantGreen, tc.wantYellow)
+		}
+	}
+	want := qosCounters{
+		green:  simCounters{packets: 30, bytes: 30 * testSize},
+		yellow: simCounters{packets: 5, bytes: 5 * testSize},
+		red:    simCounters{packets: 85, bytes: 85 * testSize},
+	}
+	if q.colors != want {
+		t.Errorf("counted %+v, want %+v", q.colors, want)
+	}
+
+	// Without a QER or AMBR every packet passes green
+	var open simQoS
+	open.police(60, testSize, 0)
+	if open.forward(testSize) != 60 || open.green != 60 {
+		t.Errorf("unpoliced session forwarded %d green and %d yellow, want 60 green", open.green, open.yellow)
+	}
+	if newAMBR(nil, 0, testSize) != nil {
+		t.Error("an AMBR of 0 is metered")
+	}
+}
+
+// TestShare checks that the parts of the sessions add up to what was kept,
+// in proportion to what each offered
+func TestShare(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		offered []uint64
+		kept    uint64
+		want    []uint64
+	}{
+		{"all kept", []uint64{3, 2, 1}, 6, []uint64{3, 2, 1}},
+		{"none kept", []uint64{3, 2, 1}, 0, []uint64{0, 0, 0}},
+		{"halved", []uint64{4, 2, 6}, 6, []uint64{2, 1, 3}},
+		{"rounded", []uint64{1, 1, 1}, 2, []uint64{0, 1, 1}},
+		{"nothing offered", []uint64{0, 0}, 0, []uint64{0, 0}},
+		// The products of offered and kept overflow 64 bits
+		{"large", []uint64{1 << 62, 1 << 62, 1 << 62}, 1<<62 + 1, []uint64{(1<<62 - 1) / 3, (1<<62-1)/3 + 1, (1<<62-1)/3 + 1}},
+	} {
+		var total uint64
+		for _, n := range tc.offered {
+			total += n
+		}
+		var prev, sum uint64
+		for i, n := range tc.offered {
+			part := share(prev, n, total, tc.kept)
+			prev, sum = prev+n, sum+part
+			if part != tc.want[i] {
+				t.Errorf("%s: session %d offering %d keeps %d, want %d", tc.name, i, n, part, tc.want[i])
+			}
+		}
+		if sum != tc.kept {
+			t.Errorf("%s: parts add up to %d, want %d", tc.name, sum, tc.kept)
+		}
+	}
+}
+
+// TestSlicePolicerOverload checks that an overloaded slice policer drops the
+// yellow packets first, shares the drops among the sessions in proportion to
+// what they offered and counts them for the slice and the sessions
+func TestSlicePolicerOverload(t *testing.T) {
+	// Room for 10 packets, all used by the first tick
+	var p slicePolicer
+	p.set(testCIR*8, 10*testSize, testSize)
+
+	offered := [][2]uint64{{3, 0}, {2, 4}, {1, 2}} // Green and yellow of each session
+	sessions := make([]*simQoS, len(offered))
+	tick := func() {
+		for i, o := range offered {
+			sessions[i] = &simQoS{green: o[0], yellow: o[1]}
+		}
+		p.police(sessions, testSize, 0)
+	}
+
+	// 6 green and 4 of the 6 yellow are kept, split 0, 2 and 2
+	tick()
+	want := [][3]uint64{{3, 0, 0}, {2, 2, 2}, {1, 2, 0}} // Green, yellow and dropped of each session
+	for i, q := range sessions {
+		if q.green != want[i][0] || q.yellow != want[i][1] || q.colors.red != (simCounters{packets: want[i][2], bytes: want[i][2] * testSize}) {
+			t.Errorf("session %d kept %d green and %d yellow, dropped %+v; want %v", i, q.green, q.yellow, q.colors.red, want[i])
+		}
+	}
+	if p.passed != (simCounters{packets: 10, bytes: 10 * testSize}) || p.dropped != (simCounters{packets: 2, bytes: 2 * testSize}) {
+		t.Errorf("passed %+v and dropped %+v, want 10 and 2 packets", p.passed, p.dropped)
+	}
+
+	// The empty buckets drop the whole next tick
+	tick()
+	for i, q := range sessions {
+		if q.green != 0 || q.yellow != 0 || q.colors.red.packets != offered[i][0]+offered[i][1] {
+			t.Errorf("session %d kept %d green and %d yellow, dropped %d", i, q.green, q.yellow, q.colors.red.packets)
+		}
+	}
+	if p.passed.packets != 10 || p.dropped.packets != 14 {
+		t.Errorf("passed %d and dropped %d packets, want 10 and 14", p.passed.packets, p.dropped.packets)
+	}
+}
+
+// TestSlicePolicersSet checks that rate limits set at runtime, as handed over
+// by config.WatchSliceRateLimit, apply to the running policers from their next
+// tick, keeping only the tokens that fit the new burst
+func TestSlicePolicersSet(t *testing.T) {
+	p := newSlicePolicers(1, config.SliceRateLimit{N3Bps: testCIR * 8, N3BurstBytes: 10 * testSize}, testSize)
+	passed := func(packets uint64, dt time.Duration) uint64 {
+		q := &simQoS{green: packets}
+		p.police([]*simQoS{q}, nil, dt)
+		return q.green
+	}
+
+	for _, step := range []struct {
+		name    string
+		limit   *config.SliceRateLimit // Set before the tick
+		dt      time.Duration
+		offered uint64
+		want    uint64 // Packets passed
+	}{
+		{"full bucket", nil, 0, 40, 10},
+		{"refilled", nil, 500 * time.Millisecond, 40, 5},
+		{"idle", nil, time.Hour, 0, 0},
+		// 100 packets a second, but a bucket of 5: the 10 tokens left are cut to 5
+		{"smaller bucket", &config.SliceRateLimit{N3Bps: 10 * testCIR * 8, N3BurstBytes: 5 * testSize}, 0, 40, 5},
+		{"faster rate", nil, 20 * time.Millisecond, 40, 2},
+		{"limit removed", &config.SliceRateLimit{}, 0, 40, 40},
+		// A new meter starts with full buckets
+		{"limit set again", &config.SliceRateLimit{N3Bps: testCIR * 8, N3BurstBytes: 10 * testSize}, 0, 40, 10},
+	} {
+		if step.limit != nil {
+			p.set(*step.limit)
+		}
+		if got := passed(step.offered, step.dt); got != step.want {
+			t.Errorf("%s: passed %d of %d packets, want %d", step.name, got, step.offered, step.want)
+		}
+	}
+
+	list := p.list()
+	if up := list[0]; up.GetDirection() != directionUplink || up.GetRateBps() != testCIR*8 || up.GetBurstBytes() != 10*testSize ||
+		up.GetPassedPackets() != 72 || up.GetDroppedPackets() != 6*40-72 {
+		t.Errorf("uplink policer %v", up)
+	}
+	if down := list[1]; down.GetRateBps() != 0 || down.GetPassedPackets() != 0 {
+		t.Errorf("downlink policer %v", down)
+	}
+}
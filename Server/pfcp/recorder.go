@@ -0,0 +1,259 @@
+package pfcp
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"upf/Server/config"
+	"upf/Server/rule"
+	"upf/pkg/record"
+)
+
+const (
+	recorderInterval = 10 * time.Second // Default time between points
+	recorderRefresh  = time.Second      // How often the recorded sessions follow the rule store
+	recorderBacklog  = 16               // Batches of points queued for the files
+)
+
+// Measurements written by the recorder, also the prefixes of their files
+const (
+	measurementSession   = "upf_session"
+	measurementAggregate = "upf_aggregate"
+)
+
+// recorderSettings is the parsed recorder block of the configuration
+type recorderSettings struct {
+	interval time.Duration
+	options  record.Options // Options of the files, without a measurement
+}
+
+// parseRecorderConfig checks the recorder block and fills in its defaults
+func parseRecorderConfig(cfg config.Recorder) (recorderSettings, error) {
+	s := recorderSettings{
+		interval: recorderInterval,
+		options: record.Options{
+			Dir:     cfg.Dir,
+			Format:  cfg.Format,
+			MaxSize: int64(cfg.MaxSizeMB) << 20,
+			Gzip:    cfg.Gzip,
+		},
+	}
+	if s.options.Format == "" {
+		s.options.Format = record.FormatInflux
+	}
+	if s.options.Format != record.FormatInflux && s.options.Format != record.FormatCSV {
+		return s, fmt.Errorf("unknown format %q, want influx or csv", cfg.Format)
+	}
+	if cfg.MaxSizeMB < 0 {
+		return s, fmt.Errorf("invalid max_size_mb %d", cfg.MaxSizeMB)
+	}
+	if cfg.Interval != "" {
+		d, err := time.ParseDuration(cfg.Interval)
+		if err != nil || d < flowTick {
+			return s, fmt.Errorf("invalid interval %q, want a duration of at least %s", cfg.Interval, flowTick)
+		}
+		s.interval = d
+	}
+	if cfg.MaxAge != "" {
+		d, err := time.ParseDuration(cfg.MaxAge)
+		if err != nil || d <= 0 {
+			return s, fmt.Errorf("invalid max_age %q", cfg.MaxAge)
+		}
+		s.options.MaxAge = d
+	}
+	return s, nil
+}
+
+// recordedSession is a session whose counters are recorded
+type recordedSession struct {
+	imsi string
+	dnn  string
+}
+
+// recordBatch holds the points of one interval, per measurement
+type recordBatch map[string][]record.Point
+
+// flowRecorder writes the counters of the aggregates and, unless it only
+// keeps aggregates, of every session to time-series files every interval.
+// The files are written from a queue so slow storage does not hold up the
+// flow engine.
+type flowRecorder struct {
+	rules      *rule.Store
+	aggregates *aggregator
+	perSession bool
+	interval   time.Duration
+
+	mu        sync.Mutex
+	sessions  map[string]recordedSession
+	watch     []string // F-SEIDs of sessions, for the flow engine
+	refreshed time.Time
+	recorded  time.Time
+
+	queue   chan recordBatch
+	written chan struct{}             // Closed once write has returned
+	writers map[string]*record.Writer // By measurement, used by write until it returns
+}
+
+// newFlowRecorder creates the files' writers and starts writing to them
+func newFlowRecorder(rules *rule.Store, aggregates *aggregator, perSession bool, s recorderSettings) (*flowRecorder, error) {
+	r := &flowRecorder{
+		rules:      rules,
+		aggregates: aggregates,
+		perSession: perSession,
+		interval:   s.interval,
+		sessions:   make(map[string]recordedSession),
+		queue:      make(chan recordBatch, recorderBacklog),
+		written:    make(chan struct{}),
+		writers:    make(map[string]*record.Writer),
+	}
+	measurements := []string{measurementAggregate}
+	if perSession {
+		measurements = append(measurements, measurementSession)
+	}
+	for _, m := range measurements {
+		opts := s.options
+		opts.Measurement = m
+		w, err := record.Create(opts)
+		if err != nil {
+			return nil, err
+		}
+		r.writers[m] = w
+	}
+	go r.write()
+	return r, nil
+}
+
+// watching returns the flows of the recorded sessions
+func (r *flowRecorder) watching() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.watch
+}
+
+// observe queues the points of the sessions and aggregates every interval
+func (r *flowRecorder) observe(now time.Time, counters map[string]flowmeasuredata) {
+	if r.perSession && now.Sub(r.refreshed) >= recorderRefresh {
+		r.refresh(now)
+	}
+	if now.Sub(r.recorded) < r.interval {
+		return
+	}
+	r.recorded = now
+
+	batch := recordBatch{measurementAggregate: r.aggregatePoints(now)}
+	if r.perSession {
+		r.mu.Lock()
+		points := make([]record.Point, 0, len(counters))
+		for fseid, d := range counters {
+			s, ok := r.sessions[fseid]
+			if !ok {
+				continue
+			}
+			points = append(points, record.Point{
+				Time: now,
+				Tags: []record.Tag{{Key: "fseid", Value: fseid}, {Key: "imsi", Value: s.imsi}, {Key: "dnn", Value: s.dnn}},
+				Fields: []record.Field{
+					record.UintField("rx_packets", d.Rx_Packet),
+					record.UintField("tx_packets", d.Tx_Packet),
+					record.UintField("rx_bytes", d.Rx_Bytes),
+					record.UintField("tx_bytes", d.Tx_Bytes),
+				},
+			})
+		}
+		r.mu.Unlock()
+		sort.Slice(points, func(i, j int) bool { return points[i].Tags[0].Value < points[j].Tags[0].Value })
+		batch[measurementSession] = points
+	}
+
+	select {
+	case r.queue <- batch:
+	default:
+		log.Printf("⚠️ Flow statistics of %s not recorded, the recorder queue is full", now.Format(time.RFC3339))
+	}
+}
+
+// aggregatePoints returns the counters and rates of every aggregate
+func (r *flowRecorder) aggregatePoints(now time.Time) []record.Point {
+	groups := []string{groupIMSI, groupDNN, groupSlice}
+	if r.aggregates.upf {
+		groups = append(groups, groupUPF)
+	}
+	var points []record.Point
+	for _, by := range groups {
+		reply, err := r.aggregates.snapshot(by, nil)
+		if err != nil {
+			continue
+		}
+		sort.Slice(reply.Groups, func(i, j int) bool { return reply.Groups[i].GetKey() < reply.Groups[j].GetKey() })
+		for _, g := range reply.GetGroups() {
+			points = append(points, record.Point{
+				Time: now,
+				Tags: []record.Tag{{Key: "group", Value: by}, {Key: "key", Value: g.GetKey()}},
+				Fields: []record.Field{
+					record.UintField("sessions", uint64(g.GetSessions())),
+					record.UintField("rx_packets", g.GetRxPackets()),
+					record.UintField("tx_packets", g.GetTxPackets()),
+					record.UintField("rx_bytes", g.GetRxBytes()),
+					record.UintField("tx_bytes", g.GetTxBytes()),
+					record.FloatField("rx_speed", g.GetRxSpeed()),
+					record.FloatField("tx_speed", g.GetTxSpeed()),
+					record.FloatField("rx_packet_rate", g.GetRxPacketRate()),
+					record.FloatField("tx_packet_rate", g.GetTxPacketRate()),
+				},
+			})
+		}
+	}
+	return points
+}
+
+// refresh follows the sessions of the rule store
+func (r *flowRecorder) refresh(now time.Time) {
+	r.refreshed = now
+	current := make(map[string]recordedSession)
+	r.rules.Range(func(s rule.Sessions) bool {
+		current[s.FSEID()] = recordedSession{imsi: s.IMSI(), dnn: s.DNN()}
+		return true
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions = current
+	r.watch = make([]string, 0, len(current))
+	for fseid := range current {
+		r.watch = append(r.watch, fseid)
+	}
+}
+
+// write appends the queued points to the files, flushing after every batch
+func (r *flowRecorder) write() {
+	defer close(r.written)
+	for batch := range r.queue {
+		for m, points := range batch {
+			w := r.writers[m]
+			for _, p := range points {
+				if err := w.Write(p); err != nil {
+					log.Printf("❌ Failed to record %s: %v", m, err)
+					break
+				}
+			}
+			if err := w.Flush(); err != nil {
+				log.Printf("❌ Failed to record %s: %v", m, err)
+			}
+		}
+	}
+}
+
+// close writes the points still queued and closes the files, compressing
+// them like rotated ones. The recorder must no longer be observed.
+func (r *flowRecorder) close() {
+	close(r.queue)
+	<-r.written
+	for m, w := range r.writers {
+		if err := w.Close(); err != nil {
+			log.Printf("❌ Failed to close the %s records: %v", m, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package pfcp
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"upf/Server/config"
+	"upf/Server/rule"
+	"upf/pkg/record"
+)
+
+func TestParseRecorderConfig(t *testing.T) {
+	s, err := parseRecorderConfig(config.Recorder{Dir: "records", Format: "csv", Interval: "1s", MaxSizeMB: 2, MaxAge: "1h", Gzip: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := record.Options{Dir: "records", Format: record.FormatCSV, MaxSize: 2 << 20, MaxAge: time.Hour, Gzip: true}
+	if s.interval != time.Second || s.options != want {
+		t.Errorf("got every %v %+v, want every 1s %+v", s.interval, s.options, want)
+	}
+	if s, err := parseRecorderConfig(config.Recorder{Dir: "records"}); err != nil || s.interval != recorderInterval || s.options.Format != record.FormatInflux {
+		t.Errorf("defaults: every %v as %s (%v)", s.interval, s.options.Format, err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		cfg  config.Recorder
+	}{
+		{"format", config.Recorder{Format: "json"}},
+		{"max_size_mb", config.Recorder{MaxSizeMB: -1}},
+		{"interval", config.Recorder{Interval: "soon"}},
+		{"interval below a tick", config.Recorder{Interval: "10ms"}},
+		{"max_age", config.Recorder{MaxAge: "0s"}},
+	} {
+		if _, err := parseRecorderConfig(tc.cfg); err == nil {
+			t.Errorf("%s: accepted", tc.name)
+		}
+	}
+}
+
+// TestFlowRecorderClose checks that closing the recorder writes the points
+// still queued and compresses the files it leaves
+func TestFlowRecorderClose(t *testing.T) {
+	rules := rule.NewStore(4)
+	putSession(t, rules, "0x1", "IMSI1", "internet")
+	aggregates := newAggregator(rules, 1, false, false)
+	dir := t.TempDir()
+	r, err := newFlowRecorder(rules, aggregates, true, recorderSettings{
+		interval: time.Second,
+		options:  record.Options{Dir: dir, Format: record.FormatInflux, Gzip: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Unix(1_700_000_000, 0)
+	for s := range 3 {
+		now := start.Add(time.Duration(s) * 500 * time.Millisecond)
+		counters := map[string]flowmeasuredata{"0x1": {Rx_Packet: uint64(s), Rx_Bytes: 100 * uint64(s)}}
+		aggregates.observe(now, counters)
+		r.observe(now, counters)
+	}
+	r.close()
+
+	files := make(map[string]string)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		f, err := os.Open(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("%s: %v", e.Name(), err)
+		}
+		data, _ := io.ReadAll(zr)
+		f.Close()
+		files[strings.SplitN(e.Name(), "-", 2)[0]] = string(data)
+	}
+	if len(files) != 2 {
+		t.Fatalf("files %v, want one per measurement, compressed", files)
+	}
+
+	// Points at 0 and 1 s, not at 0.5 s
+	session := "upf_session,fseid=0x1,imsi=IMSI1,dnn=internet rx_packets=0u,tx_packets=0u,rx_bytes=0u,tx_bytes=0u 1700000000000000000\n" +
+		"upf_session,fseid=0x1,imsi=IMSI1,dnn=internet rx_packets=2u,tx_packets=0u,rx_bytes=200u,tx_bytes=0u 1700000001000000000\n"
+	if files[measurementSession] != session {
+		t.Errorf("sessions recorded as\n%s\nwant\n%s", files[measurementSession], session)
+	}
+	if lines := strings.Split(strings.TrimSpace(files[measurementAggregate]), "\n"); len(lines) != 6 ||
+		!strings.HasPrefix(lines[5], "upf_aggregate,group=slice,key=1 sessions=1u,rx_packets=2u,") {
+		t.Errorf("aggregates recorded as\n%s", files[measurementAggregate])
+	}
+}
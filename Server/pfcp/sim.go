@@ -0,0 +1,323 @@
+package pfcp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"strconv"
+	"time"
+
+	"upf/Server/config"
+	"upf/Server/imsi"
+	"upf/Server/rule"
+	pb "upf/pkg/proto"
+)
+
+// simSEIDBase offsets the SEIDs of simulated sessions away from those the N4 endpoint allocates
+const simSEIDBase = 1 << 48
+
+// simReadyWait bounds how long provisioning waits for the rule agent to set up the store
+const simReadyWait = 10 * time.Second
+
+// simCounters are the cumulative counters of one direction of a simulated session
+type simCounters struct {
+	packets uint64
+	bytes   uint64
+}
+
+// simSession is a synthetic session created by the simulator
+type simSession struct {
+	fseid   string
+	imsi    string
+	ueIP    net.IP
+	peerIP  net.IP // eNB for N3/N6 traffic, A-UPF for N3/N9 traffic
+	n3TEID  uint32
+	n9TEID  uint32
+	flows   int         // Flows the session carries
+	ul, dl  simCounters // Uplink counts as received (Rx), downlink as transmitted (Tx)
+	ulCarry float64     // Bytes owed to the uplink from earlier ticks
+	dlCarry float64     // Bytes owed to the downlink from earlier ticks
+	ulQoS   simQoS      // Policers of the uplink
+	dlQoS   simQoS      // Policers of the downlink
+}
+
+// simulator generates traffic for synthetic sessions as described by the sim
+// block of upf.jsonc. Every flow sends at a rate drawn between its GBR and MBR
+// on each tick, so the counters depend only on the seed and the number of
+// ticks, not on when the ticks happen. The offered traffic then passes the
+// policers of the session's QER and AMBR and those of its slice, and only
+// what they forward is counted as traffic.
+type simulator struct {
+	cfg      config.SimConfig
+	dnn      string
+	seed     int64
+	rng      *rand.Rand
+	step     time.Duration // Traffic time simulated per tick
+	sessions []simSession
+	byFSEID  map[string]*simSession
+	profiles qosProfiles    // Burst sizes per QCI
+	store    *rule.Store    // Store the QERs of the sessions are read from, nil before provision
+	ticks    int64          // Ticks simulated so far
+	policed  [2][]*simQoS   // Uplink and downlink policers of the sessions carrying flows
+	slice    *slicePolicers // Policers of the slice, nil for none
+
+	// First UE and peer addresses and TEIDs, handed out in sequence
+	ueIP, peerIP   net.IP
+	n3TEID, n9TEID uint32
+}
+
+// parseTEID reads a TEID given in decimal or 0x-prefixed hexadecimal
+func parseTEID(s string) (uint32, error) {
+	v, err := strconv.ParseUint(s, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid TEID %q: %w", s, err)
+	}
+	return uint32(v), nil
+}
+
+// addIP returns the IPv4 address n addresses after ip
+func addIP(ip net.IP, n int) net.IP {
+	out := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(out, binary.BigEndian.Uint32(ip.To4())+uint32(n))
+	return out
+}
+
+// newSimulator creates max_sessions sessions with sequential UE and peer
+// addresses and TEIDs, and spreads total_flows flows over them. The burst
+// sizes of their policers come from qci_qos_config.
+func newSimulator(cfg config.SimConfig, qos []config.QoSConfig, dnn string, step time.Duration) (*simulator, error) {
+	if cfg.MaxSessions <= 0 {
+		return nil, fmt.Errorf("sim.max_sessions must be positive")
+	}
+	if cfg.PktSize <= 0 {
+		return nil, fmt.Errorf("sim.pkt_size must be positive")
+	}
+	if cfg.UplinkGBR > cfg.UplinkMBR || cfg.DownlinkGBR > cfg.DownlinkMBR {
+		return nil, fmt.Errorf("sim GBR must not exceed MBR")
+	}
+	if cfg.UplinkAMBR < 0 || cfg.DownlinkAMBR < 0 {
+		return nil, fmt.Errorf("sim AMBR must not be negative")
+	}
+	profiles, err := newQoSProfiles(qos)
+	if err != nil {
+		return nil, err
+	}
+
+	ueIP := net.ParseIP(cfg.StartUEIP).To4()
+	if ueIP == nil {
+		return nil, fmt.Errorf("invalid sim.start_ue_ip %q", cfg.StartUEIP)
+	}
+	peer := cfg.StartENBIP
+	if cfg.Core == "n9" {
+		peer = cfg.StartAUPFIP
+	}
+	peerIP := net.ParseIP(peer).To4()
+	if peerIP == nil {
+		return nil, fmt.Errorf("invalid sim peer address %q", peer)
+	}
+	n3TEID, err := parseTEID(cfg.StartN3TEID)
+	if err != nil {
+		return nil, err
+	}
+	n9TEID, err := parseTEID(cfg.StartN9TEID)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = 1
+	}
+	sim := &simulator{
+		cfg:      cfg,
+		dnn:      dnn,
+		seed:     seed,
+		rng:      rand.New(rand.NewSource(seed)),
+		step:     step,
+		sessions: make([]simSession, cfg.MaxSessions),
+		byFSEID:  make(map[string]*simSession, cfg.MaxSessions),
+		profiles: profiles,
+		ueIP:     ueIP,
+		peerIP:   peerIP,
+		n3TEID:   n3TEID,
+		n9TEID:   n9TEID,
+	}
+	for i := range sim.sessions {
+		s := &sim.sessions[i]
+		s.fseid = fmt.Sprintf("0x%x", uint64(simSEIDBase+i+1))
+		s.imsi = fmt.Sprintf("001010%09d", i+1)
+		sim.place(s, i)
+		s.ulQoS.ambr = newAMBR(profiles, cfg.UplinkAMBR, cfg.PktSize)
+		s.dlQoS.ambr = newAMBR(profiles, cfg.DownlinkAMBR, cfg.PktSize)
+		sim.byFSEID[s.fseid] = s
+	}
+	for f := 0; f < cfg.TotalFlows; f++ {
+		sim.sessions[f%cfg.MaxSessions].flows++
+	}
+	for i := range sim.sessions {
+		if s := &sim.sessions[i]; s.flows > 0 {
+			sim.policed[0] = append(sim.policed[0], &s.ulQoS)
+			sim.policed[1] = append(sim.policed[1], &s.dlQoS)
+		}
+	}
+	return sim, nil
+}
+
+// place gives a session the UE and peer addresses and TEIDs n steps after the first ones
+func (sim *simulator) place(s *simSession, n int) {
+	s.ueIP = addIP(sim.ueIP, n)
+	s.peerIP = addIP(sim.peerIP, n)
+	s.n3TEID = sim.n3TEID + uint32(n)
+	s.n9TEID = sim.n9TEID + uint32(n)
+}
+
+// taken reports whether a stored session uses the UE address or N3 TEID n
+// steps after the first ones
+func (sim *simulator) taken(rules *rule.Store, n int) bool {
+	return len(rules.ByUEIP(addIP(sim.ueIP, n).String())) > 0 ||
+		len(rules.ByTEID(fmt.Sprintf("0x%08x", sim.n3TEID+uint32(n)))) > 0
+}
+
+// rules returns the session rules of a simulated session
+func (sim *simulator) rules(s *simSession) *pb.Rulestruct {
+	farTEID := s.n3TEID
+	if sim.cfg.Core == "n9" {
+		farTEID = s.n9TEID
+	}
+	return &pb.Rulestruct{
+		Pdr: &pb.Pdrstruct{
+			PdrId: []string{"pdr1", "pdr2"},
+			Fsied: s.fseid,
+			UeIp:  s.ueIP.String(),
+			Teid:  fmt.Sprintf("0x%08x", s.n3TEID),
+		},
+		Far: &pb.Farstruct{
+			FarId:       "far1",
+			Fsied:       s.fseid,
+			ApplyAction: "forward",
+			OuterIp:     s.peerIP.String(),
+			OuterTeid:   fmt.Sprintf("0x%08x", farTEID),
+		},
+		Qer: &pb.Qerstruct{
+			QerId: "qer1",
+			Fsied: s.fseid,
+			Qfi:   9,
+			UlMbr: uint64(sim.cfg.UplinkMBR) * 1000,
+			DlMbr: uint64(sim.cfg.DownlinkMBR) * 1000,
+			UlGbr: uint64(sim.cfg.UplinkGBR) * 1000,
+			DlGbr: uint64(sim.cfg.DownlinkGBR) * 1000,
+		},
+		Urr:  &pb.Urrstruct{UrrId: "urr1", Fsied: s.fseid},
+		Imsi: s.imsi,
+		Dnn:  sim.dnn,
+	}
+}
+
+// provision writes the simulated sessions into the rule and subscriber stores.
+// Sessions take the next UE address and TEID no stored session uses, so the
+// simulated ones never share them with the sessions already there. It stops at
+// the first session the rule store rejects and returns how many it wrote. From
+// then on the policers follow the QERs of the rule store.
+func (sim *simulator) provision(rules *rule.Store, subscribers *imsi.Store) (int, error) {
+	sim.store = rules
+	ch := rule.Change{Caller: "pfcp/sim", Operation: "SimulatedSession"}
+	next, skipped := 0, 0
+	for i := range sim.sessions {
+		s := &sim.sessions[i]
+		for sim.taken(rules, next) {
+			next++
+			skipped++
+		}
+		sim.place(s, next)
+		next++
+		if err := rules.PutRules(sim.rules(s), ch); err != nil {
+			return i, err
+		}
+		subscribers.SetSession(s.imsi, sim.dnn, s.fseid)
+	}
+	if skipped > 0 {
+		log.Printf("🧪 Skipped %d UE addresses and TEIDs of stored sessions", skipped)
+	}
+	return len(sim.sessions), nil
+}
+
+// offer draws one tick of traffic for flows flows at a rate between gbr and
+// mbr kbps and returns the whole packets offered, carrying partial packets
+// over to the next tick
+func (sim *simulator) offer(carry *float64, flows int, gbr, mbr int) uint64 {
+	bytes := *carry
+	for f := 0; f < flows; f++ {
+		kbps := gbr
+		if mbr > gbr {
+			kbps += sim.rng.Intn(mbr - gbr + 1)
+		}
+		bytes += float64(kbps) * 1000 / 8 * sim.step.Seconds()
+	}
+	packets := uint64(bytes) / uint64(sim.cfg.PktSize)
+	*carry = bytes - float64(packets*uint64(sim.cfg.PktSize))
+	return packets
+}
+
+// forward counts the traffic of one tick that every policer passed
+func (sim *simulator) forward(c *simCounters, q *simQoS) {
+	forwarded := q.forward(sim.cfg.PktSize)
+	c.packets += forwarded
+	c.bytes += forwarded * uint64(sim.cfg.PktSize)
+}
+
+// refresh sets the policers of every session to the rates of its QER in the
+// rule store. Sessions no longer in the store keep their last rates.
+func (sim *simulator) refresh() {
+	for i := range sim.sessions {
+		s := &sim.sessions[i]
+		if s.flows == 0 {
+			continue
+		}
+		stored, ok := sim.store.Get(s.fseid)
+		if !ok {
+			continue
+		}
+		qer := stored.QER()
+		s.ulQoS.setQER(sim.profiles, qer.Qfi, qer.UlGbr, qer.UlMbr, sim.cfg.PktSize)
+		s.dlQoS.setQER(sim.profiles, qer.Qfi, qer.DlGbr, qer.DlMbr, sim.cfg.PktSize)
+	}
+}
+
+// tick advances every simulated session by one step
+func (sim *simulator) tick() {
+	if sim.store != nil && sim.ticks%max(1, int64(qosRefresh/sim.step)) == 0 {
+		sim.refresh()
+	}
+	sim.ticks++
+	for i := range sim.sessions {
+		s := &sim.sessions[i]
+		if s.flows == 0 {
+			continue
+		}
+		s.ulQoS.police(sim.offer(&s.ulCarry, s.flows, sim.cfg.UplinkGBR, sim.cfg.UplinkMBR), sim.cfg.PktSize, sim.step)
+		s.dlQoS.police(sim.offer(&s.dlCarry, s.flows, sim.cfg.DownlinkGBR, sim.cfg.DownlinkMBR), sim.cfg.PktSize, sim.step)
+	}
+	if sim.slice != nil {
+		sim.slice.police(sim.policed[0], sim.policed[1], sim.step)
+	}
+	for i := range sim.sessions {
+		if s := &sim.sessions[i]; s.flows > 0 {
+			sim.forward(&s.ul, &s.ulQoS)
+			sim.forward(&s.dl, &s.dlQoS)
+		}
+	}
+}
+
+// measure copies the counters of a simulated session. Flows that are not
+// simulated sessions carry no traffic.
+func (sim *simulator) measure(fseid string, d *flowmeasuredata, now time.Time) {
+	s, ok := sim.byFSEID[fseid]
+	if !ok {
+		return
+	}
+	d.Rx_Packet, d.Rx_Bytes = s.ul.packets, s.ul.bytes
+	d.Tx_Packet, d.Tx_Bytes = s.dl.packets, s.dl.bytes
+	d.Rx_QoS, d.Tx_QoS = s.ulQoS.colors, s.dlQoS.colors
+}
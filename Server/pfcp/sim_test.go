@@ -0,0 +1,138 @@
+package pfcp
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"upf/Server/config"
+	"upf/Server/imsi"
+	"upf/Server/rule"
+	pb "upf/pkg/proto"
+)
+
+// testSimConfig describes n sessions carrying 2n+1 flows from UE 10.60.0.1
+// and TEID 0x100 on
+func testSimConfig(n int, seed int64) config.SimConfig {
+	return config.SimConfig{
+		Core:        "n3",
+		MaxSessions: n,
+		StartUEIP:   "10.60.0.1",
+		StartENBIP:  "192.168.10.1",
+		StartN3TEID: "0x100",
+		StartN9TEID: "0x200",
+		UplinkGBR:   800,
+		UplinkMBR:   1200,
+		DownlinkGBR: 4000,
+		DownlinkMBR: 6000,
+		PktSize:     500,
+		TotalFlows:  2*n + 1,
+		Seed:        seed,
+	}
+}
+
+// TestSimulatorSeed checks that the same seed gives the same counters and
+// another seed other counters
+func TestSimulatorSeed(t *testing.T) {
+	run := func(seed int64) []simSession {
+		sim, err := newSimulator(testSimConfig(3, seed), nil, "internet", flowTick)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for range 50 {
+			sim.tick()
+		}
+		return sim.sessions
+	}
+	a, b, other := run(7), run(7), run(8)
+	differs := false
+	for i := range a {
+		if a[i].ul != b[i].ul || a[i].dl != b[i].dl {
+			t.Errorf("session %d: seed 7 gave %+v %+v, then %+v %+v", i, a[i].ul, a[i].dl, b[i].ul, b[i].dl)
+		}
+		if a[i].ul.packets == 0 || a[i].dl.packets == 0 {
+			t.Errorf("session %d carried no traffic", i)
+		}
+		differs = differs || a[i].ul != other[i].ul || a[i].dl != other[i].dl
+	}
+	if !differs {
+		t.Error("seeds 7 and 8 gave the same counters")
+	}
+}
+
+// storeSession puts a session with a UE address and TEID
+func storeSession(t *testing.T, rules *rule.Store, fseid, ueIP, teid string) {
+	t.Helper()
+	err := rules.PutRules(&pb.Rulestruct{
+		Pdr: &pb.Pdrstruct{Fsied: fseid, PdrId: []string{"pdr1", "pdr2"}, UeIp: ueIP, Teid: teid},
+		Far: &pb.Farstruct{Fsied: fseid, FarId: "far1", ApplyAction: "forward"},
+	}, rule.Change{Caller: "test", Operation: "storeSession"})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSimulatorProvision checks that the sessions take sequential F-SEIDs,
+// UE addresses and TEIDs, skipping those of the stored sessions
+func TestSimulatorProvision(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		stored [][2]string // UE address and TEID of the stored sessions
+		steps  []int       // Address and TEID step of each simulated session
+	}{
+		{"empty store", nil, []int{0, 1, 2, 3}},
+		{"taken", [][2]string{{"10.60.0.1", "0x00000900"}, {"10.60.0.9", "0x00000102"}}, []int{1, 3, 4, 5}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rules, subscribers := rule.NewStore(4), imsi.NewStore()
+			for i, s := range tc.stored {
+				storeSession(t, rules, fmt.Sprintf("stored%d", i), s[0], s[1])
+			}
+			sim, err := newSimulator(testSimConfig(len(tc.steps), 1), nil, "internet", flowTick)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n, err := sim.provision(rules, subscribers); err != nil || n != len(tc.steps) {
+				t.Fatalf("provisioned %d sessions (%v), want %d", n, err, len(tc.steps))
+			}
+
+			for i, step := range tc.steps {
+				fseid := fmt.Sprintf("0x%x", uint64(simSEIDBase+i+1))
+				stored, ok := rules.Get(fseid)
+				if !ok {
+					t.Fatalf("session %d not stored as %s", i, fseid)
+				}
+				ueIP, teid := fmt.Sprintf("10.60.0.%d", 1+step), fmt.Sprintf("0x%08x", 0x100+step)
+				if stored.UEIP() != ueIP || stored.TEID() != teid {
+					t.Errorf("%s: UE %s and TEID %s, want %s and %s", fseid, stored.UEIP(), stored.TEID(), ueIP, teid)
+				}
+				if peer := fmt.Sprintf("192.168.10.%d", 1+step); sim.sessions[i].peerIP.String() != peer {
+					t.Errorf("%s: peer %s, want %s", fseid, sim.sessions[i].peerIP, peer)
+				}
+				if info, _ := subscribers.Get(sim.sessions[i].imsi); info.Inter != fseid {
+					t.Errorf("%s: subscriber %s has session %q", fseid, sim.sessions[i].imsi, info.Inter)
+				}
+			}
+			if got, want := rules.Len(), len(tc.stored)+len(tc.steps); got != want {
+				t.Errorf("%d sessions stored, want %d", got, want)
+			}
+		})
+	}
+}
+
+// TestSimulatorProvisionFull checks that provisioning stops at the first
+// session the table sizes leave no room for
+func TestSimulatorProvisionFull(t *testing.T) {
+	rules := rule.NewStore(4)
+	storeSession(t, rules, "stored", "10.0.0.1", "0x00000001")
+	rules.SetTableSizes(config.TableSizes{PDRLookup: 3})
+	sim, err := newSimulator(testSimConfig(4, 1), nil, "internet", flowTick)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := sim.provision(rules, imsi.NewStore())
+	var full *rule.CapacityError
+	if n != 2 || !errors.As(err, &full) || full.Table != rule.TablePDRLookup {
+		t.Fatalf("provisioned %d sessions (%v), want 2 and a full %s table", n, err, rule.TablePDRLookup)
+	}
+}
@@ -0,0 +1,346 @@
+package pfcp
+
+import (
+	"container/heap"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"upf/Server/rule"
+	pb "upf/pkg/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	topSample   = 5 * time.Second // Interval the counters of every session are sampled at
+	topSamples  = 61              // Samples kept per session, covering the longest window and one more
+	topRank     = time.Second     // How often the rankings are recomputed
+	topRefresh  = time.Second     // How often the ranked sessions follow the rule store
+	topMaxN     = 100             // Sessions kept per ranking
+	topDefaultN = 10              // Sessions returned when a request asks for none
+)
+
+// Metrics of TopSessions. Rate is bytes over the time a session's counters
+// cover, which is shorter than the window for sessions that started within it.
+const (
+	metricBytes   = "bytes"
+	metricPackets = "packets"
+	metricRate    = "rate"
+)
+
+// topMetrics are the keys the sessions are ranked by, per metric
+var topMetrics = map[string]func(topEntry) uint64{
+	metricBytes:   func(e topEntry) uint64 { return e.bytes },
+	metricPackets: func(e topEntry) uint64 { return e.packets },
+	metricRate:    func(e topEntry) uint64 { return uint64(e.rate()) },
+}
+
+// topWindows are the windows rankings are kept for, each a multiple of
+// topSample. Other windows up to topMaxWindow are ranked when asked for.
+var topWindows = []time.Duration{10 * time.Second, time.Minute, 5 * time.Minute}
+
+// topMaxWindow is the longest window the samples cover
+const topMaxWindow = (topSamples - 1) * topSample
+
+// topDefaultWindow is the window of requests that name none
+const topDefaultWindow = time.Minute
+
+// topCounters are the cumulative traffic counters of a session, both directions summed
+type topCounters struct {
+	bytes   uint64
+	packets uint64
+}
+
+// topSession holds the samples of one session. Samples are taken for every
+// session at the same instants, so slot i of every ring was sampled at times[i].
+type topSession struct {
+	imsi    string
+	dnn     string
+	cur     topCounters // Latest counters
+	seen    bool        // cur holds observed counters
+	base    topCounters // Counters when the session was first observed
+	baseAt  time.Time
+	samples [topSamples]topCounters
+	sampled int // Samples held, up to topSamples
+}
+
+// topEntry is a session's traffic over a window
+type topEntry struct {
+	fseid   string
+	imsi    string
+	dnn     string
+	bytes   uint64
+	packets uint64
+	elapsed time.Duration
+}
+
+// rate returns the average speed of the entry in bit/s
+func (e topEntry) rate() float64 {
+	if e.elapsed <= 0 {
+		return 0
+	}
+	return float64(e.bytes) * 8 / e.elapsed.Seconds()
+}
+
+// topHeap is a min-heap of entries by one counter, holding the heaviest
+// entries seen so far; ties keep the lower F-SEID
+type topHeap struct {
+	entries []topEntry
+	key     func(topEntry) uint64
+}
+
+func (h *topHeap) Len() int { return len(h.entries) }
+
+func (h *topHeap) Less(i, j int) bool {
+	a, b := h.key(h.entries[i]), h.key(h.entries[j])
+	if a != b {
+		return a < b
+	}
+	return h.entries[i].fseid > h.entries[j].fseid
+}
+
+func (h *topHeap) Swap(i, j int) { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+
+func (h *topHeap) Push(x any) { h.entries = append(h.entries, x.(topEntry)) }
+
+func (h *topHeap) Pop() any {
+	last := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	return last
+}
+
+// offer adds an entry if it is among the n heaviest so far
+func (h *topHeap) offer(e topEntry, n int) {
+	if h.Len() < n {
+		heap.Push(h, e)
+		return
+	}
+	// Compare against the lightest entry kept, using the spare slot past the end
+	h.entries = append(h.entries, e)
+	heavier := h.Less(0, n)
+	h.entries = h.entries[:n]
+	if heavier {
+		h.entries[0] = e
+		heap.Fix(h, 0)
+	}
+}
+
+// sorted returns the entries heaviest first
+func (h *topHeap) sorted() []topEntry {
+	out := h.entries
+	sort.Slice(out, func(i, j int) bool { return h.Less(j, i) })
+	return out
+}
+
+// topRanking is the ranking of one window by one counter
+type topRanking struct {
+	window time.Duration
+	metric string // One of topMetrics
+}
+
+// topTalkers keeps the sessions of the rule store ranked by the traffic they
+// moved over each of topWindows. A bounded heap per ranking is rebuilt every
+// topRank, so a request costs only as much as the sessions it returns.
+type topTalkers struct {
+	rules *rule.Store
+
+	mu        sync.Mutex
+	sessions  map[string]*topSession
+	watch     []string // F-SEIDs of sessions, for the flow engine
+	times     [topSamples]time.Time
+	head      int // Slot of the next sample
+	sampledAt time.Time
+	refreshed time.Time
+	ranked    time.Time
+	rankings  map[topRanking][]topEntry
+}
+
+// newTopTalkers creates an empty ranking of the sessions of the rule store
+func newTopTalkers(rules *rule.Store) *topTalkers {
+	return &topTalkers{
+		rules:    rules,
+		sessions: make(map[string]*topSession),
+		rankings: make(map[topRanking][]topEntry),
+	}
+}
+
+// watching returns the flows of the ranked sessions
+func (t *topTalkers) watching() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.watch
+}
+
+// observe takes the latest counters, samples them every topSample and
+// ranks the sessions every topRank
+func (t *topTalkers) observe(now time.Time, counters map[string]flowmeasuredata) {
+	if now.Sub(t.refreshed) >= topRefresh {
+		t.refresh(now)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for fseid, d := range counters {
+		s, ok := t.sessions[fseid]
+		if !ok {
+			continue
+		}
+		s.cur = topCounters{bytes: d.Rx_Bytes + d.Tx_Bytes, packets: d.Rx_Packet + d.Tx_Packet}
+		if !s.seen {
+			s.seen, s.base, s.baseAt = true, s.cur, now
+		}
+	}
+
+	if now.Sub(t.sampledAt) >= topSample {
+		t.sampledAt = now
+		t.times[t.head] = now
+		for _, s := range t.sessions {
+			if s.seen {
+				s.samples[t.head] = s.cur
+				s.sampled = min(s.sampled+1, topSamples)
+			}
+		}
+		t.head = (t.head + 1) % topSamples
+	}
+
+	if now.Sub(t.ranked) >= topRank {
+		t.rankLocked(now)
+	}
+}
+
+// refresh follows the sessions of the rule store
+func (t *topTalkers) refresh(now time.Time) {
+	t.refreshed = now
+	type found struct{ imsi, dnn string }
+	current := make(map[string]found)
+	t.rules.Range(func(s rule.Sessions) bool {
+		current[s.FSEID()] = found{imsi: s.IMSI(), dnn: s.DNN()}
+		return true
+	})
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for fseid := range t.sessions {
+		if _, ok := current[fseid]; !ok {
+			delete(t.sessions, fseid)
+		}
+	}
+	t.watch = make([]string, 0, len(current))
+	for fseid, f := range current {
+		s, ok := t.sessions[fseid]
+		if !ok {
+			s = &topSession{}
+			t.sessions[fseid] = s
+		}
+		s.imsi, s.dnn = f.imsi, f.dnn
+		t.watch = append(t.watch, fseid)
+	}
+}
+
+// entryLocked returns the traffic of a session over a window: from the
+// latest sample at least window ago, the oldest sample it has, or its first
+// observation, whichever is latest. Callers hold t.mu.
+func (t *topTalkers) entryLocked(fseid string, s *topSession, window time.Duration, now time.Time) topEntry {
+	from, at := s.base, s.baseAt
+	if k := min(int(window/topSample)+1, s.sampled); k > 0 {
+		slot := (t.head - k + topSamples) % topSamples
+		from, at = s.samples[slot], t.times[slot]
+	}
+	e := topEntry{fseid: fseid, imsi: s.imsi, dnn: s.dnn, elapsed: now.Sub(at)}
+	if s.cur.bytes >= from.bytes {
+		e.bytes = s.cur.bytes - from.bytes
+	}
+	if s.cur.packets >= from.packets {
+		e.packets = s.cur.packets - from.packets
+	}
+	return e
+}
+
+// rankLocked rebuilds every ranking. Callers hold t.mu.
+func (t *topTalkers) rankLocked(now time.Time) {
+	t.ranked = now
+	for _, w := range topWindows {
+		for metric, ranking := range t.rankWindowLocked(w, topMaxN, now) {
+			t.rankings[topRanking{window: w, metric: metric}] = ranking
+		}
+	}
+}
+
+// rankWindowLocked ranks the sessions over a window by every metric, keeping
+// the n heaviest of each. Callers hold t.mu.
+func (t *topTalkers) rankWindowLocked(window time.Duration, n int, now time.Time) map[string][]topEntry {
+	heaps := make(map[string]*topHeap, len(topMetrics))
+	for metric, key := range topMetrics {
+		heaps[metric] = &topHeap{key: key}
+	}
+	for fseid, s := range t.sessions {
+		if !s.seen {
+			continue
+		}
+		e := t.entryLocked(fseid, s, window, now)
+		for _, h := range heaps {
+			h.offer(e, n)
+		}
+	}
+	out := make(map[string][]topEntry, len(heaps))
+	for metric, h := range heaps {
+		out[metric] = h.sorted()
+	}
+	return out
+}
+
+// top returns the n heaviest sessions by metric over the window. The windows
+// of topWindows are served from the rankings kept; any other window is
+// ranked on request, from the samples at its start rounded to topSample.
+func (t *topTalkers) top(metric string, n int, window time.Duration) (*pb.TopSessionsReply, error) {
+	if metric == "" {
+		metric = metricBytes
+	}
+	if _, ok := topMetrics[metric]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown metric %q, want bytes, packets or rate", metric)
+	}
+	if n == 0 {
+		n = topDefaultN
+	}
+	if n < 0 || n > topMaxN {
+		return nil, status.Errorf(codes.InvalidArgument, "n must be between 1 and %d", topMaxN)
+	}
+	if window == 0 {
+		window = topDefaultWindow
+	}
+	if window < time.Second || window > topMaxWindow {
+		return nil, status.Errorf(codes.InvalidArgument, "window_seconds must be between 1 and %d", int(topMaxWindow/time.Second))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ranking := t.rankings[topRanking{window: window, metric: metric}]
+	if !slices.Contains(topWindows, window) && !t.ranked.IsZero() {
+		ranking = t.rankWindowLocked(window, n, t.ranked)[metric]
+	}
+	reply := &pb.TopSessionsReply{
+		Metric:        metric,
+		WindowSeconds: uint32(window / time.Second),
+		Ranked:        uint32(len(t.sessions)),
+		SpeedUnit:     speedUnit,
+	}
+	if !t.ranked.IsZero() {
+		reply.MeasuredUnixNano = t.ranked.UnixNano()
+	}
+	for _, e := range ranking[:min(n, len(ranking))] {
+		s := &pb.TopSession{
+			Fseid:   e.fseid,
+			Imsi:    e.imsi,
+			Dnn:     e.dnn,
+			Bytes:   e.bytes,
+			Packets: e.packets,
+			Seconds: e.elapsed.Seconds(),
+			Rate:    e.rate(),
+		}
+		reply.Sessions = append(reply.Sessions, s)
+	}
+	return reply, nil
+}
@@ -0,0 +1,114 @@
+package pfcp
+
+import (
+	"testing"
+	"time"
+
+	"upf/Server/rule"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// topFixture runs a minute of traffic: "steady" moves 1 MB/s from the start,
+// "late" 2 MB/s from the 50th second on
+func topFixture(t *testing.T) *topTalkers {
+	t.Helper()
+	rules := rule.NewStore(4)
+	putSession(t, rules, "steady", "001010000000001", "internet")
+	putSession(t, rules, "late", "001010000000002", "internet")
+	top := newTopTalkers(rules)
+
+	start := time.Unix(1_700_000_000, 0)
+	for s := 0; s <= 60; s++ {
+		counters := map[string]flowmeasuredata{
+			"steady": {Rx_Bytes: uint64(s) * 1e6, Rx_Packet: uint64(s) * 1000},
+		}
+		if s >= 50 {
+			counters["late"] = flowmeasuredata{Rx_Bytes: uint64(s-50) * 2e6, Rx_Packet: uint64(s-50) * 100}
+		}
+		top.observe(start.Add(time.Duration(s)*time.Second), counters)
+	}
+	return top
+}
+
+// TestTopRanksRateByElapsed checks that the rate ranking divides every
+// session's bytes by the time its own counters cover
+func TestTopRanksRateByElapsed(t *testing.T) {
+	top := topFixture(t)
+	for _, tc := range []struct {
+		metric string
+		order  []string
+	}{
+		{metricBytes, []string{"steady", "late"}},
+		{metricPackets, []string{"steady", "late"}},
+		{metricRate, []string{"late", "steady"}},
+	} {
+		reply, err := top.top(tc.metric, 0, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.metric, err)
+		}
+		if reply.GetWindowSeconds() != 60 {
+			t.Errorf("%s: window %d s, want the default of 60 s", tc.metric, reply.GetWindowSeconds())
+		}
+		var got []string
+		for _, s := range reply.GetSessions() {
+			got = append(got, s.GetFseid())
+		}
+		if len(got) != len(tc.order) || got[0] != tc.order[0] || got[1] != tc.order[1] {
+			t.Errorf("%s: ranked %v, want %v", tc.metric, got, tc.order)
+		}
+	}
+
+	reply, _ := top.top(metricRate, 0, time.Minute)
+	want := map[string]struct {
+		bytes   uint64
+		seconds float64
+		rate    float64
+	}{
+		"late":   {20e6, 10, 16e6},
+		"steady": {60e6, 60, 8e6},
+	}
+	for _, s := range reply.GetSessions() {
+		w := want[s.GetFseid()]
+		if s.GetBytes() != w.bytes || s.GetSeconds() != w.seconds || s.GetRate() != w.rate {
+			t.Errorf("%s: %d bytes over %g s at %g bit/s, want %d bytes over %g s at %g bit/s",
+				s.GetFseid(), s.GetBytes(), s.GetSeconds(), s.GetRate(), w.bytes, w.seconds, w.rate)
+		}
+	}
+}
+
+// TestTopWindows checks windows other than the ranked ones and the bounds
+func TestTopWindows(t *testing.T) {
+	top := topFixture(t)
+	for _, tc := range []struct {
+		window  time.Duration
+		bytes   uint64 // Of "steady"
+		seconds float64
+	}{
+		{10 * time.Second, 10e6, 10},
+		{30 * time.Second, 30e6, 30},
+		{7 * time.Second, 5e6, 5}, // Counted on the sample grid
+		{5 * time.Minute, 60e6, 60},
+	} {
+		reply, err := top.top(metricBytes, 2, tc.window)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.window, err)
+		}
+		if len(reply.GetSessions()) != 2 {
+			t.Fatalf("%v: %d sessions, want 2", tc.window, len(reply.GetSessions()))
+		}
+		for _, s := range reply.GetSessions() {
+			if s.GetFseid() == "steady" && (s.GetBytes() != tc.bytes || s.GetSeconds() != tc.seconds) {
+				t.Errorf("%v: steady moved %d bytes over %g s, want %d bytes over %g s",
+					tc.window, s.GetBytes(), s.GetSeconds(), tc.bytes, tc.seconds)
+			}
+		}
+	}
+
+	for _, window := range []time.Duration{500 * time.Millisecond, 301 * time.Second} {
+		if _, err := top.top(metricBytes, 1, window); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%v: got %v, want InvalidArgument", window, err)
+		}
+	}
+}
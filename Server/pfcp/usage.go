@@ -0,0 +1,508 @@
+package pfcp
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"upf/Server/rule"
+	wire "upf/pkg/pfcp"
+	pb "upf/pkg/proto"
+)
+
+const (
+	usageRefresh     = time.Second   // How often URR configurations are read from the rule store
+	usageBacklog     = 256           // Reports buffered per StreamUsageReports stream
+	usageTermination = "termination" // Trigger name of the final report of a removed session
+)
+
+// urrTriggers pairs the reporting triggers of the rule store with their PFCP
+// Reporting Triggers and Usage Report Trigger flags
+var urrTriggers = []struct {
+	name    string
+	trigger uint32 // wire.Trigger* flag
+	report  uint32 // wire.Report* flag
+}{
+	{rule.TriggerPeriodic, wire.TriggerPeriodic, wire.ReportPeriodic},
+	{rule.TriggerVolumeThreshold, wire.TriggerVolumeThreshold, wire.ReportVolumeThreshold},
+	{rule.TriggerTimeThreshold, wire.TriggerTimeThreshold, wire.ReportTimeThreshold},
+	{rule.TriggerVolumeQuota, wire.TriggerVolumeQuota, wire.ReportVolumeQuota},
+	{rule.TriggerTimeQuota, wire.TriggerTimeQuota, wire.ReportTimeQuota},
+	{rule.TriggerStartOfTraffic, wire.TriggerStartOfTraffic, wire.ReportStartOfTraffic},
+}
+
+// triggerNames names the Usage Report Trigger flags of a report
+func triggerNames(report uint32) []string {
+	var names []string
+	for _, t := range urrTriggers {
+		if report&t.report != 0 {
+			names = append(names, t.name)
+		}
+	}
+	if report&wire.ReportTermination != 0 {
+		names = append(names, usageTermination)
+	}
+	return names
+}
+
+// usageConfig is the part of a URR that decides what is measured and when it is reported
+type usageConfig struct {
+	urrID         string
+	volume        bool   // Measure volume
+	duration      bool   // Measure duration
+	triggers      uint32 // wire.Trigger* flags
+	volThreshold  uint64
+	volQuota      uint64
+	timeThreshold time.Duration
+	timeQuota     time.Duration
+	period        time.Duration
+}
+
+// parseUsageConfig reads a URR as stored in the rule store, which has
+// validated its names and values already
+func parseUsageConfig(u *pb.Urrstruct) usageConfig {
+	c := usageConfig{
+		urrID:         u.GetUrrId(),
+		volThreshold:  u.GetVolumeThreshold(),
+		volQuota:      u.GetVolumeQuota(),
+		timeThreshold: time.Duration(u.GetTimeThreshold()) * time.Second,
+		timeQuota:     time.Duration(u.GetTimeQuota()) * time.Second,
+		period:        time.Duration(u.GetMeasurementPeriod()) * time.Second,
+	}
+	for _, m := range u.GetMeasurementMethod() {
+		c.volume = c.volume || m == rule.MeasureVolume
+		c.duration = c.duration || m == rule.MeasureDuration
+	}
+	if len(u.GetMeasurementMethod()) == 0 {
+		c.volume, c.duration = true, true
+	}
+	for _, name := range u.GetReportingTriggers() {
+		for _, t := range urrTriggers {
+			if t.name == name {
+				c.triggers |= t.trigger
+			}
+		}
+	}
+	return c
+}
+
+// usageCounters are octet and packet counts per direction
+type usageCounters struct {
+	ulBytes, dlBytes     uint64
+	ulPackets, dlPackets uint64
+}
+
+// bytes returns the octets of both directions
+func (c usageCounters) bytes() uint64 {
+	return c.ulBytes + c.dlBytes
+}
+
+// since returns the traffic between the cumulative counters prev and c. A
+// counter that went backwards was reset and counts from zero.
+func (c usageCounters) since(prev usageCounters) usageCounters {
+	delta := func(now, before uint64) uint64 {
+		if now < before {
+			return now
+		}
+		return now - before
+	}
+	return usageCounters{
+		ulBytes:   delta(c.ulBytes, prev.ulBytes),
+		dlBytes:   delta(c.dlBytes, prev.dlBytes),
+		ulPackets: delta(c.ulPackets, prev.ulPackets),
+		dlPackets: delta(c.dlPackets, prev.dlPackets),
+	}
+}
+
+// add accumulates d into c
+func (c *usageCounters) add(d usageCounters) {
+	c.ulBytes += d.ulBytes
+	c.dlBytes += d.dlBytes
+	c.ulPackets += d.ulPackets
+	c.dlPackets += d.dlPackets
+}
+
+// urrState measures the usage of one URR. Uplink is what the flow received
+// (Rx), downlink what it transmitted (Tx). Duration counts the time between
+// observations in which traffic moved.
+type urrState struct {
+	usageConfig
+	fseid string
+	imsi  string
+	seq   uint32 // UR-SEQN of the next report
+
+	primed   bool          // Whether last holds counters
+	last     usageCounters // Cumulative counters at the last observation
+	observed time.Time     // When last was taken
+
+	start       time.Time     // Start of the current measurement
+	measured    usageCounters // Traffic since start
+	active      time.Duration // Time with traffic since start
+	firstPacket time.Time     // First observation with traffic since start
+	lastPacket  time.Time     // Last observation with traffic since start
+	nextPeriod  time.Time     // When the next periodic report is due
+
+	usedBytes      uint64        // Octets since the URR was installed, against the volume quota
+	usedTime       time.Duration // Time with traffic since the URR was installed, against the time quota
+	started        bool          // Traffic was seen since the URR was installed
+	volQuotaSpent  bool          // The volume quota was reported exhausted
+	timeQuotaSpent bool          // The time quota was reported exhausted
+}
+
+// has reports whether the URR reports on the given wire.Trigger* flag
+func (st *urrState) has(trigger uint32) bool {
+	return st.triggers&trigger != 0
+}
+
+// reconfigure applies an updated URR. The measurement carries on; a changed
+// quota is a new grant and starts being used up from zero.
+func (st *urrState) reconfigure(c usageConfig, now time.Time) {
+	if c.volQuota != st.volQuota {
+		st.usedBytes, st.volQuotaSpent = 0, false
+	}
+	if c.timeQuota != st.timeQuota {
+		st.usedTime, st.timeQuotaSpent = 0, false
+	}
+	if c.period != st.period && st.primed {
+		st.nextPeriod = now.Add(c.period)
+	}
+	st.usageConfig = c
+}
+
+// observe takes the cumulative counters of the flow at now and returns the
+// report of the triggers that fired, if any
+func (st *urrState) observe(now time.Time, c usageCounters) (wire.UsageReport, bool) {
+	if !st.primed {
+		// Traffic before the URR was installed is not counted
+		st.primed, st.last, st.observed = true, c, now
+		st.start = now
+		st.nextPeriod = now.Add(st.period)
+		return wire.UsageReport{}, false
+	}
+
+	delta := c.since(st.last)
+	elapsed := now.Sub(st.observed)
+	st.last, st.observed = c, now
+
+	var fired uint32
+	if delta.bytes() > 0 || delta.ulPackets+delta.dlPackets > 0 {
+		st.measured.add(delta)
+		st.active += elapsed
+		st.usedBytes += delta.bytes()
+		st.usedTime += elapsed
+		if st.firstPacket.IsZero() {
+			st.firstPacket = now
+		}
+		st.lastPacket = now
+		if !st.started {
+			st.started = true
+			if st.has(wire.TriggerStartOfTraffic) {
+				fired |= wire.ReportStartOfTraffic
+			}
+		}
+	}
+
+	if st.has(wire.TriggerVolumeThreshold) && st.measured.bytes() >= st.volThreshold {
+		fired |= wire.ReportVolumeThreshold
+	}
+	if st.has(wire.TriggerTimeThreshold) && st.active >= st.timeThreshold {
+		fired |= wire.ReportTimeThreshold
+	}
+	if st.has(wire.TriggerVolumeQuota) && !st.volQuotaSpent && st.usedBytes >= st.volQuota {
+		st.volQuotaSpent = true
+		fired |= wire.ReportVolumeQuota
+	}
+	if st.has(wire.TriggerTimeQuota) && !st.timeQuotaSpent && st.usedTime >= st.timeQuota {
+		st.timeQuotaSpent = true
+		fired |= wire.ReportTimeQuota
+	}
+	if st.has(wire.TriggerPeriodic) && !now.Before(st.nextPeriod) {
+		fired |= wire.ReportPeriodic
+		for !now.Before(st.nextPeriod) {
+			st.nextPeriod = st.nextPeriod.Add(st.period)
+		}
+	}
+
+	if fired == 0 {
+		return wire.UsageReport{}, false
+	}
+	return st.report(now, fired), true
+}
+
+// report closes the current measurement with a report for the given
+// wire.Report* flags and starts the next one
+func (st *urrState) report(now time.Time, trigger uint32) wire.UsageReport {
+	id, _ := strconv.ParseUint(strings.TrimPrefix(st.urrID, "urr"), 10, 32)
+	r := wire.UsageReport{
+		URRID:       uint32(id),
+		Sequence:    st.seq,
+		Trigger:     trigger,
+		Start:       st.start,
+		End:         now,
+		FirstPacket: st.firstPacket,
+		LastPacket:  st.lastPacket,
+	}
+	if st.volume {
+		r.Volume = &wire.Volume{
+			Flags: wire.VolumeTotal | wire.VolumeUplink | wire.VolumeDownlink |
+				wire.VolumeTotalPackets | wire.VolumeUplinkPackets | wire.VolumeDownlinkPackets,
+			Total:           st.measured.bytes(),
+			Uplink:          st.measured.ulBytes,
+			Downlink:        st.measured.dlBytes,
+			TotalPackets:    st.measured.ulPackets + st.measured.dlPackets,
+			UplinkPackets:   st.measured.ulPackets,
+			DownlinkPackets: st.measured.dlPackets,
+		}
+	}
+	if st.duration {
+		r.Duration, r.HasDuration = st.active, true
+	}
+
+	st.seq++
+	st.start = now
+	st.measured, st.active = usageCounters{}, 0
+	st.firstPacket, st.lastPacket = time.Time{}, time.Time{}
+	return r
+}
+
+// usageReport is a usage report of a URR together with the session it belongs to
+type usageReport struct {
+	wire.UsageReport
+	fseid string
+	imsi  string
+	urrID string
+}
+
+// usageSubscriber is a StreamUsageReports stream. Empty fields match every session.
+type usageSubscriber struct {
+	fseid   string
+	imsi    string
+	reports chan usageReport
+}
+
+// matches reports whether the stream selected the report
+func (sub *usageSubscriber) matches(r usageReport) bool {
+	return (sub.fseid == "" || sub.fseid == r.fseid) && (sub.imsi == "" || sub.imsi == r.imsi)
+}
+
+// usageMeter measures the URRs of the rule store against the flow counters
+// and emits usage reports when their reporting triggers fire. Only URRs with
+// reporting triggers are measured. Reports go to the StreamUsageReports
+// streams and to onReport, which sends them to the N4 peer owning the session.
+type usageMeter struct {
+	rules    *rule.Store
+	onReport func(fseid string, r wire.UsageReport) // Called outside the meter lock, may be nil
+
+	mu         sync.Mutex
+	urrs       map[string]*urrState // F-SEID -> measured URR
+	watch      []string             // F-SEIDs of urrs, for the flow engine
+	refreshed  time.Time
+	terminated map[string]bool // Sessions terminated while a refresh reads the rule store
+	subs       map[*usageSubscriber]struct{}
+}
+
+// newUsageMeter creates a usage meter for the URRs of the given rule store
+func newUsageMeter(rules *rule.Store) *usageMeter {
+	return &usageMeter{
+		rules: rules,
+		urrs:  make(map[string]*urrState),
+		subs:  make(map[*usageSubscriber]struct{}),
+	}
+}
+
+// watching returns the flows whose counters the meter needs
+func (m *usageMeter) watching() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.watch
+}
+
+// observe measures every URR against the counters of its flow
+func (m *usageMeter) observe(now time.Time, counters map[string]flowmeasuredata) {
+	var out, removed []usageReport
+	if now.Sub(m.refreshed) >= usageRefresh {
+		removed = m.refresh(now)
+	}
+
+	m.mu.Lock()
+	for fseid, st := range m.urrs {
+		d, ok := counters[fseid]
+		if !ok {
+			continue
+		}
+		c := usageCounters{ulBytes: d.Rx_Bytes, dlBytes: d.Tx_Bytes, ulPackets: d.Rx_Packet, dlPackets: d.Tx_Packet}
+		if r, ok := st.observe(now, c); ok {
+			out = append(out, usageReport{UsageReport: r, fseid: fseid, imsi: st.imsi, urrID: st.urrID})
+		}
+	}
+	m.mu.Unlock()
+
+	m.publish(removed, false)
+	m.publish(out, true)
+}
+
+// refresh reads the URRs from the rule store. URRs that are new or changed
+// are (re)configured and URRs that lost their triggers are dropped; the final
+// reports of sessions that are gone are returned.
+// The store is read without the meter lock, so sessions terminated meanwhile
+// are remembered and not brought back.
+func (m *usageMeter) refresh(now time.Time) []usageReport {
+	m.mu.Lock()
+	m.refreshed = now
+	m.terminated = make(map[string]bool)
+	m.mu.Unlock()
+
+	type found struct {
+		cfg  usageConfig
+		imsi string
+	}
+	current := make(map[string]found)
+	m.rules.Range(func(s rule.Sessions) bool {
+		if u := s.URR(); len(u.GetReportingTriggers()) > 0 {
+			current[s.FSEID()] = found{cfg: parseUsageConfig(u), imsi: s.IMSI()}
+		}
+		return true
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var removed []usageReport
+	for fseid, st := range m.urrs {
+		if _, ok := current[fseid]; ok {
+			continue
+		}
+		delete(m.urrs, fseid)
+		if _, exists := m.rules.Get(fseid); !exists {
+			removed = append(removed, usageReport{
+				UsageReport: st.report(now, wire.ReportTermination),
+				fseid:       fseid, imsi: st.imsi, urrID: st.urrID,
+			})
+		}
+	}
+	for fseid, f := range current {
+		if m.terminated[fseid] {
+			continue
+		}
+		st, ok := m.urrs[fseid]
+		switch {
+		case !ok:
+			m.urrs[fseid] = &urrState{usageConfig: f.cfg, fseid: fseid, imsi: f.imsi}
+		case st.urrID != f.cfg.urrID:
+			// A different URR took over the session; it measures from scratch
+			m.urrs[fseid] = &urrState{usageConfig: f.cfg, fseid: fseid, imsi: f.imsi}
+		default:
+			st.imsi = f.imsi
+			st.reconfigure(f.cfg, now)
+		}
+	}
+	m.terminated = nil
+
+	m.watch = make([]string, 0, len(m.urrs))
+	for fseid := range m.urrs {
+		m.watch = append(m.watch, fseid)
+	}
+	return removed
+}
+
+// terminate stops measuring the URR of a session that is being deleted and
+// returns its final report. The report also goes to the streams, but not to
+// onReport: the caller answers the deletion with it.
+func (m *usageMeter) terminate(fseid string) (wire.UsageReport, bool) {
+	m.mu.Lock()
+	if m.terminated != nil {
+		m.terminated[fseid] = true
+	}
+	st, ok := m.urrs[fseid]
+	if !ok {
+		m.mu.Unlock()
+		return wire.UsageReport{}, false
+	}
+	delete(m.urrs, fseid)
+	// The measurement ends with the counters last observed
+	end := st.observed
+	if end.IsZero() {
+		end = time.Now()
+	}
+	r := st.report(end, wire.ReportTermination)
+	m.mu.Unlock()
+
+	m.publish([]usageReport{{UsageReport: r, fseid: fseid, imsi: st.imsi, urrID: st.urrID}}, false)
+	return r, true
+}
+
+// subscribe starts a StreamUsageReports stream
+func (m *usageMeter) subscribe(fseid, imsi string) *usageSubscriber {
+	sub := &usageSubscriber{fseid: fseid, imsi: imsi, reports: make(chan usageReport, usageBacklog)}
+	m.mu.Lock()
+	m.subs[sub] = struct{}{}
+	m.mu.Unlock()
+	return sub
+}
+
+// unsubscribe ends a StreamUsageReports stream
+func (m *usageMeter) unsubscribe(sub *usageSubscriber) {
+	m.mu.Lock()
+	delete(m.subs, sub)
+	m.mu.Unlock()
+}
+
+// publish hands reports to the streams that selected them, dropping them for
+// streams too slow to keep up, and to onReport if toN4 is set
+func (m *usageMeter) publish(reports []usageReport, toN4 bool) {
+	if len(reports) == 0 {
+		return
+	}
+	m.mu.Lock()
+	for _, r := range reports {
+		for sub := range m.subs {
+			if !sub.matches(r) {
+				continue
+			}
+			select {
+			case sub.reports <- r:
+			default:
+				log.Printf("⚠️ Usage report %d of %s dropped for a slow stream", r.Sequence, r.fseid)
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	if toN4 && m.onReport != nil {
+		for _, r := range reports {
+			m.onReport(r.fseid, r.UsageReport)
+		}
+	}
+}
+
+// unixNano returns t in nanoseconds since the epoch, or 0 for the zero time
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// usageEvent builds the StreamUsageReports message of a report
+func usageEvent(r usageReport) *pb.UsageReportEvent {
+	ev := &pb.UsageReportEvent{
+		Fseid:               r.fseid,
+		Imsi:                r.imsi,
+		UrrId:               r.urrID,
+		Sequence:            r.Sequence,
+		Triggers:            triggerNames(r.Trigger),
+		StartUnixNano:       unixNano(r.Start),
+		EndUnixNano:         unixNano(r.End),
+		FirstPacketUnixNano: unixNano(r.FirstPacket),
+		LastPacketUnixNano:  unixNano(r.LastPacket),
+	}
+	if v := r.Volume; v != nil {
+		ev.UlBytes, ev.DlBytes, ev.TotalBytes = v.Uplink, v.Downlink, v.Total
+		ev.UlPackets, ev.DlPackets, ev.TotalPackets = v.UplinkPackets, v.DownlinkPackets, v.TotalPackets
+	}
+	if r.HasDuration {
+		ev.DurationSeconds = uint32(r.Duration / time.Second)
+	}
+	return ev
+}
@@ -0,0 +1,183 @@
+package pfcp
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"upf/Server/rule"
+	wire "upf/pkg/pfcp"
+	pb "upf/pkg/proto"
+)
+
+// usageHarness feeds a usage meter the counters of one session, a second at a time
+type usageHarness struct {
+	t      *testing.T
+	rules  *rule.Store
+	meter  *usageMeter
+	sub    *usageSubscriber
+	toN4   []wire.UsageReport // Reports handed to onReport
+	now    time.Time
+	rx, tx uint64 // Cumulative uplink and downlink octets
+}
+
+const usageFSEID = "0x1"
+
+// newUsageHarness stores a session with the given URR and primes the meter
+func newUsageHarness(t *testing.T, urr *pb.Urrstruct) *usageHarness {
+	h := &usageHarness{t: t, rules: rule.NewStore(4), now: time.Unix(1700000000, 0)}
+	h.setURR(urr)
+	h.meter = newUsageMeter(h.rules)
+	h.meter.onReport = func(fseid string, r wire.UsageReport) { h.toN4 = append(h.toN4, r) }
+	h.sub = h.meter.subscribe("", "")
+	if got := h.step(0, 0); len(got) != 0 {
+		t.Fatalf("reports when priming: %v", got)
+	}
+	return h
+}
+
+// setURR replaces the URR of the session
+func (h *usageHarness) setURR(urr *pb.Urrstruct) {
+	h.t.Helper()
+	urr.UrrId, urr.Fsied = "urr1", usageFSEID
+	err := h.rules.PutRules(&pb.Rulestruct{
+		Pdr:  &pb.Pdrstruct{Fsied: usageFSEID, PdrId: []string{"pdr1"}},
+		Far:  &pb.Farstruct{Fsied: usageFSEID, FarId: "far1", ApplyAction: "forward"},
+		Qer:  &pb.Qerstruct{Fsied: usageFSEID},
+		Urr:  urr,
+		Imsi: "IMSI1",
+		Dnn:  "internet",
+	}, rule.Change{Caller: "test", Operation: "setURR"})
+	if err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+// step advances a second in which the session moves ul and dl octets, in
+// packets of 100 octets, and returns the reports streamed meanwhile
+func (h *usageHarness) step(ul, dl uint64) []usageReport {
+	h.now = h.now.Add(time.Second)
+	h.rx, h.tx = h.rx+ul, h.tx+dl
+	h.meter.observe(h.now, map[string]flowmeasuredata{usageFSEID: {
+		Rx_Bytes: h.rx, Tx_Bytes: h.tx, Rx_Packet: h.rx / 100, Tx_Packet: h.tx / 100,
+	}})
+	var out []usageReport
+	for {
+		select {
+		case r := <-h.sub.reports:
+			out = append(out, r)
+		default:
+			return out
+		}
+	}
+}
+
+// wantReport checks the single report of a step
+func (h *usageHarness) wantReport(got []usageReport, seq uint32, triggers []string, ul, dl uint64) usageReport {
+	h.t.Helper()
+	if len(got) != 1 {
+		h.t.Fatalf("%d reports, want 1 (%v)", len(got), got)
+	}
+	r := got[0]
+	if r.Sequence != seq || !reflect.DeepEqual(triggerNames(r.Trigger), triggers) {
+		h.t.Fatalf("report %d %v, want %d %v", r.Sequence, triggerNames(r.Trigger), seq, triggers)
+	}
+	if r.Volume == nil || r.Volume.Uplink != ul || r.Volume.Downlink != dl || r.Volume.Total != ul+dl {
+		h.t.Fatalf("report %d volume %+v, want %d uplink and %d downlink octets", seq, r.Volume, ul, dl)
+	}
+	if r.fseid != usageFSEID || r.imsi != "IMSI1" || r.urrID != "urr1" {
+		h.t.Fatalf("report of %s/%s/%s", r.fseid, r.imsi, r.urrID)
+	}
+	return r
+}
+
+func TestUsageVolumeThreshold(t *testing.T) {
+	h := newUsageHarness(t, &pb.Urrstruct{
+		ReportingTriggers: []string{rule.TriggerVolumeThreshold},
+		VolumeThreshold:   1000,
+	})
+
+	if got := h.step(600, 0); len(got) != 0 {
+		t.Fatalf("report below the threshold: %v", got)
+	}
+	h.wantReport(h.step(300, 100), 0, []string{rule.TriggerVolumeThreshold}, 900, 100)
+
+	// Each report restarts the measurement
+	if got := h.step(400, 0); len(got) != 0 {
+		t.Fatalf("report below the threshold: %v", got)
+	}
+	r := h.wantReport(h.step(0, 700), 1, []string{rule.TriggerVolumeThreshold}, 400, 700)
+	if !r.End.Equal(h.now) || !r.Start.Equal(h.now.Add(-2*time.Second)) {
+		t.Fatalf("report 1 covers %s to %s", r.Start, r.End)
+	}
+	if len(h.toN4) != 2 || h.toN4[1].Sequence != 1 {
+		t.Fatalf("reports sent over N4: %v", h.toN4)
+	}
+}
+
+func TestUsageTimeThreshold(t *testing.T) {
+	h := newUsageHarness(t, &pb.Urrstruct{
+		ReportingTriggers: []string{rule.TriggerTimeThreshold},
+		TimeThreshold:     3,
+	})
+
+	// Only seconds with traffic count towards the threshold
+	for _, ul := range []uint64{100, 0, 100} {
+		if got := h.step(ul, 0); len(got) != 0 {
+			t.Fatalf("report after %s of traffic: %v", h.now, got)
+		}
+	}
+	r := h.wantReport(h.step(100, 100), 0, []string{rule.TriggerTimeThreshold}, 300, 100)
+	if !r.HasDuration || r.Duration != 3*time.Second {
+		t.Fatalf("duration %s, want 3s", r.Duration)
+	}
+	for i := 0; i < 2; i++ {
+		if got := h.step(100, 0); len(got) != 0 {
+			t.Fatalf("report before the threshold: %v", got)
+		}
+	}
+	h.wantReport(h.step(100, 0), 1, []string{rule.TriggerTimeThreshold}, 300, 0)
+}
+
+func TestUsageVolumeQuota(t *testing.T) {
+	h := newUsageHarness(t, &pb.Urrstruct{
+		ReportingTriggers: []string{rule.TriggerVolumeQuota, rule.TriggerStartOfTraffic},
+		VolumeQuota:       2000,
+	})
+
+	h.wantReport(h.step(500, 500), 0, []string{rule.TriggerStartOfTraffic}, 500, 500)
+	h.wantReport(h.step(500, 500), 1, []string{rule.TriggerVolumeQuota}, 500, 500)
+
+	// An exhausted quota is reported once
+	for i := 0; i < 3; i++ {
+		if got := h.step(1000, 0); len(got) != 0 {
+			t.Fatalf("quota reported again: %v", got)
+		}
+	}
+
+	// A new grant is used up from zero, measured since the last report
+	h.setURR(&pb.Urrstruct{ReportingTriggers: []string{rule.TriggerVolumeQuota}, VolumeQuota: 1500})
+	if got := h.step(1000, 0); len(got) != 0 {
+		t.Fatalf("report within the new quota: %v", got)
+	}
+	h.wantReport(h.step(0, 500), 2, []string{rule.TriggerVolumeQuota}, 4000, 500)
+}
+
+func TestUsageTermination(t *testing.T) {
+	h := newUsageHarness(t, &pb.Urrstruct{
+		ReportingTriggers: []string{rule.TriggerVolumeThreshold},
+		VolumeThreshold:   1000,
+	})
+	h.step(200, 300)
+
+	// A deleted session gets a final report on the streams but not over N4,
+	// whose deletion response carries it
+	h.rules.Delete(usageFSEID, rule.Change{Caller: "test", Operation: "delete"})
+	h.wantReport(h.step(0, 0), 0, []string{usageTermination}, 200, 300)
+	if len(h.toN4) != 0 {
+		t.Fatalf("termination sent over N4: %v", h.toN4)
+	}
+	if w := h.meter.watching(); len(w) != 0 {
+		t.Fatalf("still watching %v", w)
+	}
+}
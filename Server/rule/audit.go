@@ -0,0 +1,332 @@
+package rule
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	pb "upf/pkg/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// AuditFile is the name of the append-only audit log, kept in the data
+// directory of the configuration (see config.DataPath)
+const AuditFile = "rule-audit.jsonl"
+
+// auditWindow is how many of the latest events are kept in memory. Older
+// events are read back from the file of the log when a query reaches them.
+const auditWindow = 100_000
+
+// callerMetadataKey is the gRPC metadata key clients use to identify themselves
+const callerMetadataKey = "x-caller-id"
+
+// Audit actions
+const (
+	actionCreate = "create"
+	actionUpdate = "update"
+	actionDelete = "delete"
+)
+
+// Change describes who made a store mutation and through which operation
+type Change struct {
+	Caller    string // Identity of the caller
+	Operation string // RPC or procedure making the change
+}
+
+// AuditLog is an append-only record of every session mutation. The latest
+// events are kept in memory for queries and, when opened from a file, every
+// event is appended to it as JSON lines; queries reaching past the events in
+// memory read the older ones from the file. An in-memory log forgets them.
+type AuditLog struct {
+	mu      sync.RWMutex
+	window  int                       // Events kept in memory
+	events  []*pb.AuditEvent          // Latest events in log order
+	dropped int                       // Events dropped from memory, the position of events[0] in the log
+	byFSEID map[string][]int          // Positions in the log of the events of each session still in memory
+	last    map[string]*pb.Rulestruct // Rules of each session after its last event, for sessions not deleted last
+	seq     uint64                    // Sequence number of the last event
+	path    string                    // Backing file, empty for an in-memory log
+	file    *os.File
+}
+
+// NewAuditLog creates an empty in-memory audit log
+func NewAuditLog() *AuditLog {
+	return &AuditLog{
+		window:  auditWindow,
+		byFSEID: make(map[string][]int),
+		last:    make(map[string]*pb.Rulestruct),
+	}
+}
+
+// OpenAuditLog loads the audit log stored at path and appends new events to it
+func OpenAuditLog(path string) (*AuditLog, error) {
+	a := NewAuditLog()
+
+	if f, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			ev := &pb.AuditEvent{}
+			if err := protojson.Unmarshal(scanner.Bytes(), ev); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+			}
+			a.append(ev)
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	a.path, a.file = path, f
+	return a, nil
+}
+
+// append adds an event to the in-memory log, dropping the oldest events once
+// a quarter more than the window is held. Callers must hold the write lock.
+func (a *AuditLog) append(ev *pb.AuditEvent) {
+	a.byFSEID[ev.Fseid] = append(a.byFSEID[ev.Fseid], a.dropped+len(a.events))
+	a.events = append(a.events, ev)
+	a.seq = ev.Seq
+	if ev.After != nil {
+		a.last[ev.Fseid] = ev.After
+	} else {
+		delete(a.last, ev.Fseid)
+	}
+
+	if len(a.events) <= a.window+a.window/4 {
+		return
+	}
+	n := len(a.events) - a.window
+	a.events = append([]*pb.AuditEvent(nil), a.events[n:]...)
+	a.dropped += n
+	for fseid, positions := range a.byFSEID {
+		i := 0
+		for i < len(positions) && positions[i] < a.dropped {
+			i++
+		}
+		if i == len(positions) {
+			delete(a.byFSEID, fseid)
+		} else if i > 0 {
+			a.byFSEID[fseid] = append([]int(nil), positions[i:]...)
+		}
+	}
+}
+
+// at returns the event at a position of the log still held in memory.
+// Callers hold the lock.
+func (a *AuditLog) at(position int) *pb.AuditEvent {
+	return a.events[position-a.dropped]
+}
+
+// scanFile calls fn with the first n events of the backing file, in log
+// order, until fn returns false
+func (a *AuditLog) scanFile(n int, fn func(*pb.AuditEvent) bool) error {
+	f, err := os.Open(a.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for i := 0; i < n && scanner.Scan(); i++ {
+		ev := &pb.AuditEvent{}
+		if err := protojson.Unmarshal(scanner.Bytes(), ev); err != nil {
+			return fmt.Errorf("%s:%d: %w", a.path, i+1, err)
+		}
+		if !fn(ev) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// record appends the change of a session from prev to next to the log
+func (a *AuditLog) record(ch Change, fseid string, prev, next *Sessions) {
+	ev := &pb.AuditEvent{
+		TimestampUnixNano: time.Now().UnixNano(),
+		Caller:            ch.Caller,
+		Operation:         ch.Operation,
+		Fseid:             fseid,
+	}
+	switch {
+	case prev == nil:
+		ev.Action = actionCreate
+	case next == nil:
+		ev.Action = actionDelete
+	default:
+		ev.Action = actionUpdate
+	}
+	if prev != nil {
+		ev.Before = toProto(*prev)
+	}
+	if next != nil {
+		ev.After = toProto(*next)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ev.Seq = a.seq + 1
+	a.append(ev)
+	if a.file != nil {
+		line, err := protojson.Marshal(ev)
+		if err == nil {
+			_, err = a.file.Write(append(line, '\n'))
+		}
+		if err != nil {
+			log.Printf("❌ Failed to persist audit event %d: %v", ev.Seq, err)
+		}
+	}
+}
+
+// latest returns the rules of a session after its last audited change, nil
+// if it was never audited or was deleted last
+func (a *AuditLog) latest(fseid string) *pb.Rulestruct {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.last[fseid]
+}
+
+// History returns the events of a single session in log order
+func (a *AuditLog) History(fseid string) []*pb.AuditEvent {
+	return a.List(&pb.AuditFilter{Fseid: fseid}, 0, 0, 0)
+}
+
+// matches reports whether an event passes the filter and time range
+func matches(ev *pb.AuditEvent, f *pb.AuditFilter, start, end int64) bool {
+	if start != 0 && ev.TimestampUnixNano < start {
+		return false
+	}
+	if end != 0 && ev.TimestampUnixNano >= end {
+		return false
+	}
+	if f.GetFseid() != "" && ev.Fseid != f.GetFseid() {
+		return false
+	}
+	if f.GetCaller() != "" && ev.Caller != f.GetCaller() {
+		return false
+	}
+	if f.GetOperation() != "" && ev.Operation != f.GetOperation() {
+		return false
+	}
+	if f.GetAction() != "" && ev.Action != f.GetAction() {
+		return false
+	}
+	if imsi := f.GetImsi(); imsi != "" && ev.GetBefore().GetImsi() != imsi && ev.GetAfter().GetImsi() != imsi {
+		return false
+	}
+	return true
+}
+
+// List returns up to limit events matching the filter within [start, end),
+// oldest first. Zero bounds and limit mean unbounded.
+func (a *AuditLog) List(f *pb.AuditFilter, start, end int64, limit int) []*pb.AuditEvent {
+	a.mu.RLock()
+	candidates := a.events
+	if fseid := f.GetFseid(); fseid != "" {
+		candidates = make([]*pb.AuditEvent, 0, len(a.byFSEID[fseid]))
+		for _, i := range a.byFSEID[fseid] {
+			candidates = append(candidates, a.at(i))
+		}
+	}
+	// Events held in memory are never modified, so the slice stays valid
+	// once unlocked; the events dropped before it are in the file
+	dropped, path := a.dropped, a.path
+	older := dropped > 0 && path != "" && (start == 0 || len(a.events) == 0 || start < a.events[0].TimestampUnixNano)
+	a.mu.RUnlock()
+
+	var events []*pb.AuditEvent
+	if older {
+		err := a.scanFile(dropped, func(ev *pb.AuditEvent) bool {
+			if matches(ev, f, start, end) {
+				events = append(events, ev)
+			}
+			return limit <= 0 || len(events) < limit
+		})
+		if err != nil {
+			log.Printf("❌ Failed to read older audit events from %s: %v", path, err)
+		}
+		if limit > 0 && len(events) >= limit {
+			return events
+		}
+	}
+	for _, ev := range candidates {
+		if !matches(ev, f, start, end) {
+			continue
+		}
+		events = append(events, ev)
+		if limit > 0 && len(events) == limit {
+			break
+		}
+	}
+	return events
+}
+
+// Close closes the backing file of the log
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+// callerIdentity returns the identity of the gRPC caller: the x-caller-id
+// metadata if set, otherwise the user agent and peer address
+func callerIdentity(ctx context.Context, operation string) Change {
+	ch := Change{Caller: "unknown", Operation: operation}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(callerMetadataKey); len(ids) > 0 && ids[0] != "" {
+			ch.Caller = ids[0]
+			return ch
+		}
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			ch.Caller = ua[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ch.Caller += "@" + p.Addr.String()
+	}
+	return ch
+}
+
+// GetSessionHistory returns every recorded change of a session
+func (s *ruleServer) GetSessionHistory(ctx context.Context, req *pb.SessionHistoryRequest) (*pb.AuditEventsReply, error) {
+	events := s.session.Audit().History(req.GetFseid())
+	if len(events) == 0 {
+		return nil, status.Errorf(codes.NotFound, "No history for F-SEID: %s", req.GetFseid())
+	}
+	return &pb.AuditEventsReply{Events: events}, nil
+}
+
+// ListAuditEvents returns the audit events matching a filter and time range
+func (s *ruleServer) ListAuditEvents(ctx context.Context, req *pb.ListAuditEventsRequest) (*pb.AuditEventsReply, error) {
+	events := s.session.Audit().List(req.GetFilter(), req.GetStartUnixNano(), req.GetEndUnixNano(), int(req.GetLimit()))
+	return &pb.AuditEventsReply{Events: events}, nil
+}
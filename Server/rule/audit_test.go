@@ -0,0 +1,149 @@
+package rule
+
+import (
+	"path/filepath"
+	"testing"
+
+	"upf/Server/config"
+)
+
+func TestSeedAuditsOnlyChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", AuditFile)
+	ch := Change{Caller: "system", Operation: "StartRuleAgent"}
+	sample := testSession(1)
+
+	// start seeds the sample into a fresh store, as StartRuleAgent does at every start
+	start := func(s Sessions) int {
+		t.Helper()
+		audit, err := OpenAuditLog(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer audit.file.Close()
+		st := NewStore(defaultShards)
+		st.SetAuditLog(audit)
+		if err := st.Seed(s, ch); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := st.Get(s.fseid); !ok {
+			t.Fatalf("seeded session %s not stored", s.fseid)
+		}
+		return len(audit.History(s.fseid))
+	}
+
+	if n := start(sample); n != 1 {
+		t.Fatalf("first start: %d events, want 1", n)
+	}
+	if n := start(sample); n != 1 {
+		t.Fatalf("restart with the same sample: %d events, want 1", n)
+	}
+	sample.qer.ul_mbr++
+	if n := start(sample); n != 2 {
+		t.Fatalf("restart with a changed sample: %d events, want 2", n)
+	}
+}
+
+func TestDataPath(t *testing.T) {
+	base, err := filepath.Abs(filepath.Dir(config.ConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs := filepath.Join(t.TempDir(), "upf")
+	for _, tc := range []struct {
+		name string
+		cfg  *config.UPFConfig
+		want string
+	}{
+		{"no config", nil, filepath.Join(base, AuditFile)},
+		{"no data_dir", &config.UPFConfig{}, filepath.Join(base, AuditFile)},
+		{"relative data_dir", &config.UPFConfig{DataDir: "data"}, filepath.Join(base, "data", AuditFile)},
+		{"absolute data_dir", &config.UPFConfig{DataDir: abs}, filepath.Join(abs, AuditFile)},
+	} {
+		if got := config.DataPath(tc.cfg, AuditFile); got != tc.want {
+			t.Errorf("%s: DataPath = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestAuditWindow(t *testing.T) {
+	ch := Change{Caller: "test", Operation: "TestAuditWindow"}
+	for _, tc := range []struct {
+		name string
+		file bool
+	}{{"file", true}, {"memory", false}} {
+		t.Run(tc.name, func(t *testing.T) {
+			audit := NewAuditLog()
+			if tc.file {
+				var err error
+				if audit, err = OpenAuditLog(filepath.Join(t.TempDir(), AuditFile)); err != nil {
+					t.Fatal(err)
+				}
+				defer audit.Close()
+			}
+			audit.window = 4
+			st := NewStore(defaultShards)
+			st.SetAuditLog(audit)
+
+			// 20 events: sessions 0-4 created, updated twice and deleted
+			for round := 0; round < 4; round++ {
+				for i := 0; i < 5; i++ {
+					s := testSession(i)
+					s.qer.ul_mbr = uint64(round)
+					var err error
+					if round == 3 {
+						st.Delete(s.fseid, ch)
+					} else {
+						err = st.Put(s, ch)
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if len(audit.events) > audit.window+audit.window/4 {
+				t.Fatalf("%d events in memory, want at most %d", len(audit.events), audit.window+audit.window/4)
+			}
+
+			all := audit.List(nil, 0, 0, 0)
+			history := audit.History(testSession(0).fseid)
+			if !tc.file {
+				// An in-memory log only answers from the events it kept
+				if len(all) != len(audit.events) || all[len(all)-1].Seq != 20 {
+					t.Fatalf("listed %d events ending at %d, want the %d kept ending at 20", len(all), all[len(all)-1].Seq, len(audit.events))
+				}
+				return
+			}
+			if len(all) != 20 {
+				t.Fatalf("listed %d events, want 20", len(all))
+			}
+			for i, ev := range all {
+				if ev.Seq != uint64(i+1) {
+					t.Fatalf("event %d has sequence number %d", i, ev.Seq)
+				}
+			}
+			if len(history) != 4 || history[0].Action != actionCreate || history[3].Action != actionDelete {
+				t.Fatalf("history of session 0 has %d events, want create, 2 updates and delete", len(history))
+			}
+			if limited := audit.List(nil, 0, 0, 3); len(limited) != 3 || limited[2].Seq != 3 {
+				t.Fatalf("limit 3 listed %d events", len(limited))
+			}
+			if since := audit.List(nil, all[17].TimestampUnixNano, 0, 0); len(since) == 0 || since[len(since)-1].Seq != 20 {
+				t.Fatalf("listing from event 18 returned %d events", len(since))
+			}
+			// Sequence numbers continue after a restart
+			audit.Close()
+			reopened, err := OpenAuditLog(audit.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reopened.Close()
+			st.SetAuditLog(reopened)
+			if err := st.Put(testSession(9), ch); err != nil {
+				t.Fatal(err)
+			}
+			if evs := reopened.History(testSession(9).fseid); len(evs) != 1 || evs[0].Seq != 21 {
+				t.Fatalf("event after restart: %v", evs)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package rule
+
+import (
+	"fmt"
+	"sync"
+
+	"upf/Server/config"
+)
+
+// Table names as they appear in the table_sizes block of upf.jsonc
+const (
+	TablePDRLookup        = "pdrLookup"
+	TableFARLookup        = "farLookup"
+	TableAppQERLookup     = "appQERLookup"
+	TableSessionQERLookup = "sessionQERLookup"
+	TableFlowMeasure      = "flowMeasure"
+)
+
+// tableOrder lists the tables in the order they are reported
+var tableOrder = []string{TablePDRLookup, TableFARLookup, TableAppQERLookup, TableSessionQERLookup, TableFlowMeasure}
+
+// Entry costs as described by the table_sizes comments in upf.jsonc, so that
+// the example sizes there hold exactly 50K sessions
+const (
+	pdrPatterns          = 4 // PDRs of a session are spread over 4 unique tuple patterns
+	appQERsPerSession    = 2 // The QER of a session is installed as an uplink and a downlink application QER
+	entriesPerAppQER     = 2 // Each application QER takes 2 entries
+	entriesPerSessionQER = 2 // The single session QER takes 2 entries
+	flowEntriesPerPDR    = 1 // Flow measurement keeps one entry per PDR
+	farsPerSession       = 3 // The FAR of a session is installed as uplink, downlink and downlink buffering FARs
+	entriesPerFAR        = 1 // Each FAR takes a single entry
+)
+
+// tableCost maps a table name to the number of entries a session occupies in it
+type tableCost map[string]int64
+
+// CapacityError reports a session write that would overflow a lookup table
+type CapacityError struct {
+	Table    string // Table that would overflow
+	Used     int64  // Entries in use before the write
+	Needed   int64  // Additional entries the write needs
+	Capacity int64  // Configured table size
+}
+
+func (e *CapacityError) Error() string {
+	return fmt.Sprintf("%s table full: %d of %d entries used, %d more needed",
+		e.Table, e.Used, e.Capacity, e.Needed)
+}
+
+// TableUsage reports the occupancy of a single lookup table
+type TableUsage struct {
+	Table    string // Table name
+	Capacity int64  // Configured size, 0 if unlimited
+	Used     int64  // Entries in use
+}
+
+// Free returns the number of entries still available, or 0 for unlimited tables
+func (u TableUsage) Free() int64 {
+	if u.Capacity == 0 || u.Used >= u.Capacity {
+		return 0
+	}
+	return u.Capacity - u.Used
+}
+
+// capacity tracks how many entries of each lookup table are in use
+type capacity struct {
+	mu     sync.Mutex
+	limits map[string]int64 // Configured size per table, 0 or missing means unlimited
+	used   map[string]int64 // Entries in use per table
+}
+
+// newCapacity creates an accounting with no limits
+func newCapacity() *capacity {
+	return &capacity{
+		limits: make(map[string]int64),
+		used:   make(map[string]int64),
+	}
+}
+
+// costOf returns the table entries a session occupies. A nil session costs nothing.
+func costOf(s *Sessions) tableCost {
+	cost := tableCost{}
+	if s == nil {
+		return cost
+	}
+	pdrs := int64(len(s.pdr.pdr_id))
+	cost[TablePDRLookup] = (pdrs + pdrPatterns - 1) / pdrPatterns
+	cost[TableFlowMeasure] = pdrs * flowEntriesPerPDR
+	if s.far.far_id != "" {
+		cost[TableFARLookup] = farsPerSession * entriesPerFAR
+	}
+	if s.qer.qer_id != "" {
+		cost[TableAppQERLookup] = appQERsPerSession * entriesPerAppQER
+		cost[TableSessionQERLookup] = entriesPerSessionQER
+	}
+	return cost
+}
+
+// reserve applies the usage change of replacing prev with next, or returns a
+// CapacityError without changing anything if a table would overflow
+func (c *capacity) reserve(prev, next *Sessions) error {
+	before, after := costOf(prev), costOf(next)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, table := range tableOrder {
+		delta := after[table] - before[table]
+		limit := c.limits[table]
+		if delta > 0 && limit > 0 && c.used[table]+delta > limit {
+			return &CapacityError{Table: table, Used: c.used[table], Needed: delta, Capacity: limit}
+		}
+	}
+	for _, table := range tableOrder {
+		c.used[table] += after[table] - before[table]
+	}
+	return nil
+}
+
+// fits returns a CapacityError if the sessions would overflow a table of an
+// otherwise empty store. Sessions sharing an F-SEID count once.
+func (c *capacity) fits(sessions []Sessions) error {
+	total := tableCost{}
+	seen := make(map[string]bool, len(sessions))
+	for i := range sessions {
+		if seen[sessions[i].fseid] {
+			continue
+		}
+		seen[sessions[i].fseid] = true
+		for table, n := range costOf(&sessions[i]) {
+			total[table] += n
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, table := range tableOrder {
+		if limit := c.limits[table]; limit > 0 && total[table] > limit {
+			return &CapacityError{Table: table, Needed: total[table], Capacity: limit}
+		}
+	}
+	return nil
+}
+
+// SetTableSizes sets the table capacities enforced on session writes.
+// Sessions already stored are kept even if they exceed the new sizes.
+func (st *Store) SetTableSizes(sizes config.TableSizes) {
+	st.capacity.mu.Lock()
+	defer st.capacity.mu.Unlock()
+
+	st.capacity.limits[TablePDRLookup] = int64(sizes.PDRLookup)
+	st.capacity.limits[TableFARLookup] = int64(sizes.FARLookup)
+	st.capacity.limits[TableAppQERLookup] = int64(sizes.AppQERLookup)
+	st.capacity.limits[TableSessionQERLookup] = int64(sizes.SessionQERLookup)
+	st.capacity.limits[TableFlowMeasure] = int64(sizes.FlowMeasure)
+}
+
+// TableUsage returns the occupancy of every lookup table
+func (st *Store) TableUsage() []TableUsage {
+	st.capacity.mu.Lock()
+	defer st.capacity.mu.Unlock()
+
+	usage := make([]TableUsage, 0, len(tableOrder))
+	for _, table := range tableOrder {
+		usage = append(usage, TableUsage{
+			Table:    table,
+			Capacity: st.capacity.limits[table],
+			Used:     st.capacity.used[table],
+		})
+	}
+	return usage
+}
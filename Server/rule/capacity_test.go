@@ -0,0 +1,48 @@
+package rule
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"upf/Server/config"
+)
+
+// The example table_sizes of upf.jsonc are documented to hold 50K sessions of
+// 4 PDRs, a FAR and a QER; the accounting must agree with them
+func TestCapacityMatchesDocumentedSizes(t *testing.T) {
+	cfg, err := config.LoadConfig("../upf.jsonc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const sessions = 50000
+	st := NewStore(defaultShards)
+	st.SetTableSizes(cfg.TableSizes)
+	session := func(i int) Sessions {
+		s := testSession(i)
+		s.pdr.pdr_id = append(s.pdr.pdr_id, fmt.Sprintf("pdr-%d-3", i), fmt.Sprintf("pdr-%d-4", i))
+		return s
+	}
+	for i := 0; i < sessions; i++ {
+		if err := st.Put(session(i), Change{}); err != nil {
+			t.Fatalf("session %d of %d rejected: %v", i+1, sessions, err)
+		}
+	}
+	for _, u := range st.TableUsage() {
+		if u.Used != u.Capacity {
+			t.Errorf("%s: %d of %d entries used, want the table exactly full", u.Table, u.Used, u.Capacity)
+		}
+	}
+
+	var capErr *CapacityError
+	if err := st.Put(session(sessions), Change{}); !errors.As(err, &capErr) {
+		t.Fatalf("session %d accepted beyond the documented sizes: %v", sessions+1, err)
+	}
+	if st.Len() != sessions {
+		t.Errorf("store holds %d sessions after a rejected write, want %d", st.Len(), sessions)
+	}
+	// Replacing a stored session costs only the difference
+	if err := st.Put(session(0), Change{}); err != nil {
+		t.Errorf("replacing a stored session in a full store: %v", err)
+	}
+}
@@ -0,0 +1,400 @@
+package rule
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"upf/Server/config"
+	pb "upf/pkg/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// UP4 pipeline table and action names used by the SD-Fabric P4Info
+const (
+	tableInterfaces           = "PreQosPipe.interfaces"
+	tableSessionsUplink       = "PreQosPipe.sessions_uplink"
+	tableSessionsDownlink     = "PreQosPipe.sessions_downlink"
+	tableTerminationsUplink   = "PreQosPipe.terminations_uplink"
+	tableTerminationsDownlink = "PreQosPipe.terminations_downlink"
+	tableTunnelPeers          = "PreQosPipe.tunnel_peers"
+
+	actionSetSourceIface         = "PreQosPipe.set_source_iface"
+	actionSetSessionUplink       = "PreQosPipe.set_session_uplink"
+	actionSetSessionUplinkDrop   = "PreQosPipe.set_session_uplink_drop"
+	actionSetSessionDownlink     = "PreQosPipe.set_session_downlink"
+	actionSetSessionDownlinkBuff = "PreQosPipe.set_session_downlink_buff"
+	actionSetSessionDownlinkDrop = "PreQosPipe.set_session_downlink_drop"
+	actionUplinkTermFwd          = "PreQosPipe.uplink_term_fwd"
+	actionUplinkTermDrop         = "PreQosPipe.uplink_term_drop"
+	actionDownlinkTermFwd        = "PreQosPipe.downlink_term_fwd"
+	actionDownlinkTermDrop       = "PreQosPipe.downlink_term_drop"
+	actionLoadTunnelParam        = "PreQosPipe.load_tunnel_param"
+)
+
+// UP4 interface and direction constants
+const (
+	srcIfaceAccess    = 1
+	srcIfaceCore      = 2
+	directionUplink   = 1
+	directionDownlink = 2
+	gtpuPort          = 2152
+	defaultAppID      = 0
+	defaultAppMeter   = 0
+)
+
+// P4Config holds the p4rtciface settings the compiler needs
+type P4Config struct {
+	AccessIP  string // N3 address of the UPF, with or without prefix length
+	UEPool    string // UE address pool routed to the core interface
+	SliceID   int    // UP4 slice identifier
+	DefaultTC int    // Traffic class applied to terminations
+}
+
+// P4ConfigFrom extracts the compiler settings from the UPF configuration
+func P4ConfigFrom(cfg *config.UPFConfig) P4Config {
+	return P4Config{
+		AccessIP:  cfg.P4RTCInterface.AccessIP,
+		UEPool:    cfg.CPInterface.UEIPPool,
+		SliceID:   cfg.P4RTCInterface.SliceID,
+		DefaultTC: cfg.P4RTCInterface.DefaultTC,
+	}
+}
+
+// p4Compiler turns sessions into UP4 table entries. Counter, meter and tunnel
+// peer indexes are handed out in the order sessions are compiled, only to
+// entries that are written, so the same sessions always compile to the same
+// entries. The entries name their tables, fields and actions as the UP4 P4Info
+// does rather than by P4Info ID: they are a preview of the writes, to be
+// resolved against the P4Info of the switch before being sent to it.
+type p4Compiler struct {
+	cfg         P4Config
+	n3Addr      net.IP         // N3 address parsed from AccessIP
+	updates     []*pb.P4Update // Generated updates
+	nextCounter uint64         // Next free PDR counter index
+	nextMeter   uint64         // Next free session meter index
+	tunnelPeers map[string]uint64
+}
+
+// encodeUint returns the canonical P4Runtime binary string of v: big-endian
+// with leading zero bytes removed, and a single zero byte for zero
+func encodeUint(v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	i := 0
+	for i < len(b)-1 && b[i] == 0 {
+		i++
+	}
+	return append([]byte(nil), b[i:]...)
+}
+
+// encodeIPv4 returns the 4-byte representation of an IPv4 address
+func encodeIPv4(ip net.IP) []byte {
+	return []byte(ip.To4())
+}
+
+// parseIPv4 parses an IPv4 address optionally followed by a prefix length
+func parseIPv4(s string) (net.IP, int, error) {
+	if strings.Contains(s, "/") {
+		ip, ipnet, err := net.ParseCIDR(s)
+		if err != nil || ip.To4() == nil {
+			return nil, 0, fmt.Errorf("invalid IPv4 prefix %q", s)
+		}
+		ones, _ := ipnet.Mask.Size()
+		return ipnet.IP.To4(), ones, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil || ip.To4() == nil {
+		return nil, 0, fmt.Errorf("invalid IPv4 address %q", s)
+	}
+	return ip.To4(), 32, nil
+}
+
+// parseTEID parses a TEID written in decimal or 0x-prefixed hexadecimal
+func parseTEID(s string) (uint64, error) {
+	teid, err := strconv.ParseUint(s, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid TEID %q", s)
+	}
+	return teid, nil
+}
+
+// exact builds an exact match field
+func exact(field string, value []byte) *pb.P4FieldMatch {
+	return &pb.P4FieldMatch{Field: field, Exact: value}
+}
+
+// param builds an action parameter
+func param(name string, value []byte) *pb.P4ActionParam {
+	return &pb.P4ActionParam{Param: name, Value: value}
+}
+
+// insert appends an INSERT update for a table entry
+func (c *p4Compiler) insert(table string, match []*pb.P4FieldMatch, action string, params ...*pb.P4ActionParam) {
+	c.updates = append(c.updates, &pb.P4Update{
+		Type: "INSERT",
+		TableEntry: &pb.P4TableEntry{
+			Table:  table,
+			Match:  match,
+			Action: &pb.P4Action{Action: action, Params: params},
+		},
+	})
+}
+
+// compileInterfaces emits the interfaces entries classifying N3 and UE pool
+// traffic into the configured slice
+func (c *p4Compiler) compileInterfaces() error {
+	slice := encodeUint(uint64(c.cfg.SliceID))
+
+	c.insert(tableInterfaces,
+		[]*pb.P4FieldMatch{{Field: "ipv4_dst_prefix", LpmValue: encodeIPv4(c.n3Addr), LpmPrefixLen: 32}},
+		actionSetSourceIface,
+		param("src_iface", encodeUint(srcIfaceAccess)),
+		param("direction", encodeUint(directionUplink)),
+		param("slice_id", slice))
+
+	if c.cfg.UEPool == "" {
+		return nil
+	}
+	pool, prefix, err := parseIPv4(c.cfg.UEPool)
+	if err != nil {
+		return err
+	}
+	c.insert(tableInterfaces,
+		[]*pb.P4FieldMatch{{Field: "ipv4_dst_prefix", LpmValue: encodeIPv4(pool), LpmPrefixLen: int32(prefix)}},
+		actionSetSourceIface,
+		param("src_iface", encodeUint(srcIfaceCore)),
+		param("direction", encodeUint(directionDownlink)),
+		param("slice_id", slice))
+	return nil
+}
+
+// tunnelPeer returns the tunnel peer ID for a downlink peer, emitting its
+// tunnel_peers entry the first time the peer is seen
+func (c *p4Compiler) tunnelPeer(peer net.IP) uint64 {
+	key := peer.String()
+	if id, ok := c.tunnelPeers[key]; ok {
+		return id
+	}
+	// Tunnel peer 0 is reserved by UP4 for "no tunnel"
+	id := uint64(len(c.tunnelPeers) + 1)
+	c.tunnelPeers[key] = id
+	c.insert(tableTunnelPeers,
+		[]*pb.P4FieldMatch{exact("tunnel_peer_id", encodeUint(id))},
+		actionLoadTunnelParam,
+		param("src_addr", encodeIPv4(c.n3Addr)),
+		param("dst_addr", encodeIPv4(peer)),
+		param("sport", encodeUint(gtpuPort)))
+	return id
+}
+
+// compileSession emits the session and termination entries of one session
+func (c *p4Compiler) compileSession(s Sessions) error {
+	tc := encodeUint(uint64(c.cfg.DefaultTC))
+	appID := encodeUint(defaultAppID)
+	appMeter := encodeUint(defaultAppMeter)
+	// The uplink and downlink entries of a session share its meter
+	var meter []byte
+	sessionMeter := func() []byte {
+		if meter == nil {
+			meter = encodeUint(c.nextMeter)
+			c.nextMeter++
+		}
+		return meter
+	}
+	counter := func() []byte {
+		ctr := encodeUint(c.nextCounter)
+		c.nextCounter++
+		return ctr
+	}
+
+	action := s.far.apply_action
+	if action == "" {
+		action = "forward"
+	}
+
+	// Uplink: GTP-U packets to the N3 address carrying the session's TEID
+	if s.pdr.teid != "" {
+		teid, err := parseTEID(s.pdr.teid)
+		if err != nil {
+			return err
+		}
+		match := []*pb.P4FieldMatch{exact("n3_address", encodeIPv4(c.n3Addr)), exact("teid", encodeUint(teid))}
+		if action == "drop" {
+			c.insert(tableSessionsUplink, match, actionSetSessionUplinkDrop)
+		} else {
+			c.insert(tableSessionsUplink, match, actionSetSessionUplink, param("session_meter_idx", sessionMeter()))
+		}
+	}
+
+	if s.pdr.ue_ip == "" {
+		return nil
+	}
+	ueIP, _, err := parseIPv4(s.pdr.ue_ip)
+	if err != nil {
+		return err
+	}
+	termMatch := []*pb.P4FieldMatch{exact("ue_address", encodeIPv4(ueIP)), exact("app_id", appID)}
+
+	if s.pdr.teid != "" {
+		if action == "drop" {
+			c.insert(tableTerminationsUplink, termMatch, actionUplinkTermDrop, param("ctr_idx", counter()))
+		} else {
+			c.insert(tableTerminationsUplink, termMatch, actionUplinkTermFwd,
+				param("ctr_idx", counter()), param("tc", tc), param("app_meter_idx", appMeter))
+		}
+	}
+
+	// Downlink: packets to the UE address, tunnelled to the access peer. A
+	// forwarding FAR without a peer to tunnel to has no downlink entries.
+	sessMatch := []*pb.P4FieldMatch{exact("ue_address", encodeIPv4(ueIP))}
+	switch {
+	case action == "drop":
+		c.insert(tableSessionsDownlink, sessMatch, actionSetSessionDownlinkDrop)
+		c.insert(tableTerminationsDownlink, termMatch, actionDownlinkTermDrop, param("ctr_idx", counter()))
+	case action == "buffer":
+		c.insert(tableSessionsDownlink, sessMatch, actionSetSessionDownlinkBuff, param("session_meter_idx", sessionMeter()))
+		c.insert(tableTerminationsDownlink, termMatch, actionDownlinkTermDrop, param("ctr_idx", counter()))
+	case s.far.outer_ip != "":
+		peer, _, err := parseIPv4(s.far.outer_ip)
+		if err != nil {
+			return err
+		}
+		teid, err := parseTEID(s.far.outer_teid)
+		if err != nil {
+			return err
+		}
+		peerID := c.tunnelPeer(peer)
+		c.insert(tableSessionsDownlink, sessMatch, actionSetSessionDownlink,
+			param("tunnel_peer_id", encodeUint(peerID)), param("session_meter_idx", sessionMeter()))
+		c.insert(tableTerminationsDownlink, termMatch, actionDownlinkTermFwd,
+			param("ctr_idx", counter()), param("teid", encodeUint(teid)), param("qfi", encodeUint(uint64(s.qer.qfi))),
+			param("tc", tc), param("app_meter_idx", appMeter))
+	}
+	return nil
+}
+
+// CompileP4 translates sessions into UP4 table entry writes. Sessions are
+// compiled in F-SEID order so the output does not depend on store layout.
+func CompileP4(cfg P4Config, sessions []Sessions) (*pb.P4WriteRequest, error) {
+	n3, _, err := parseIPv4(cfg.AccessIP)
+	if err != nil {
+		return nil, fmt.Errorf("p4rtciface.access_ip: %w", err)
+	}
+	c := &p4Compiler{cfg: cfg, n3Addr: n3, tunnelPeers: make(map[string]uint64)}
+
+	if err := c.compileInterfaces(); err != nil {
+		return nil, fmt.Errorf("cpiface.ue_ip_pool: %w", err)
+	}
+
+	sorted := append([]Sessions(nil), sessions...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].fseid < sorted[j].fseid })
+	for _, s := range sorted {
+		if err := c.compileSession(s); err != nil {
+			return nil, fmt.Errorf("session %s: %w", s.fseid, err)
+		}
+	}
+	return &pb.P4WriteRequest{Updates: c.updates}, nil
+}
+
+// quoteBytes renders bytes as a text format string literal with octal escapes
+func quoteBytes(b []byte) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, c := range b {
+		switch {
+		case c == '"' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c >= 0x20 && c < 0x7f:
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "\\%03o", c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// writeText renders a message in protobuf text format. Unlike prototext, the
+// output is byte-for-byte stable across builds, so it can be diffed in tests.
+func writeText(sb *strings.Builder, m protoreflect.Message, indent string) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !m.Has(fd) {
+			continue
+		}
+		v := m.Get(fd)
+		values := []protoreflect.Value{v}
+		if fd.IsList() {
+			values = values[:0]
+			for j := 0; j < v.List().Len(); j++ {
+				values = append(values, v.List().Get(j))
+			}
+		}
+		for _, item := range values {
+			switch fd.Kind() {
+			case protoreflect.MessageKind:
+				fmt.Fprintf(sb, "%s%s {\n", indent, fd.Name())
+				writeText(sb, item.Message(), indent+"  ")
+				fmt.Fprintf(sb, "%s}\n", indent)
+			case protoreflect.StringKind:
+				fmt.Fprintf(sb, "%s%s: %s\n", indent, fd.Name(), strconv.Quote(item.String()))
+			case protoreflect.BytesKind:
+				fmt.Fprintf(sb, "%s%s: %s\n", indent, fd.Name(), quoteBytes(item.Bytes()))
+			default:
+				fmt.Fprintf(sb, "%s%s: %v\n", indent, fd.Name(), item.Interface())
+			}
+		}
+	}
+}
+
+// FormatText renders a write request in deterministic protobuf text format
+func FormatText(w *pb.P4WriteRequest) string {
+	var sb strings.Builder
+	writeText(&sb, w.ProtoReflect(), "")
+	return sb.String()
+}
+
+// CompileP4Rules compiles the stored sessions into UP4 table entries and
+// returns them without contacting a switch. It needs enable_p4rt: without
+// it the UPF has no P4Runtime dataplane to compile for.
+func (s *ruleServer) CompileP4Rules(ctx context.Context, req *pb.CompileP4Request) (*pb.CompileP4Reply, error) {
+	cfg, err := config.LoadConfig(config.ConfigFile)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+	if !cfg.EnableP4RT {
+		return nil, status.Error(codes.FailedPrecondition, "P4Runtime is disabled, set enable_p4rt to compile UP4 entries")
+	}
+
+	var sessions []Sessions
+	if len(req.GetFseid()) == 0 {
+		s.session.Range(func(sessionInfo Sessions) bool {
+			sessions = append(sessions, sessionInfo)
+			return true
+		})
+	} else {
+		for _, fseid := range req.GetFseid() {
+			sessionInfo, ok := s.session.Get(fseid)
+			if !ok {
+				return nil, status.Errorf(codes.NotFound, "Session not found for F-SEID: %s", fseid)
+			}
+			sessions = append(sessions, sessionInfo)
+		}
+	}
+
+	write, err := CompileP4(P4ConfigFrom(cfg), sessions)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return &pb.CompileP4Reply{Write: write, Prototext: FormatText(write)}, nil
+}
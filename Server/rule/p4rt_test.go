@@ -0,0 +1,134 @@
+package rule
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata")
+
+// p4TestConfig is the p4rtciface of the golden files
+var p4TestConfig = P4Config{AccessIP: "172.17.0.1/32", UEPool: "10.60.0.0/16", SliceID: 1, DefaultTC: 3}
+
+// p4Session returns a session with an uplink TEID, a UE address and a FAR
+func p4Session(fseid, teid, ueIP, action, outerIP, outerTEID string) Sessions {
+	return Sessions{
+		fseid: fseid,
+		pdr:   Pdrstruct{pdr_id: []string{"pdr1", "pdr2"}, fsied: fseid, ue_ip: ueIP, teid: teid},
+		far:   Farstruct{far_id: "far1", fsied: fseid, apply_action: action, outer_ip: outerIP, outer_teid: outerTEID},
+		qer:   Qerstruct{qer_id: "qer1", fsied: fseid, qfi: 9},
+	}
+}
+
+var (
+	p4Forward       = p4Session("0x1", "0x100", "10.60.0.1", "forward", "192.168.1.10", "0x200")
+	p4ForwardNoPeer = p4Session("0x2", "0x101", "10.60.0.2", "", "", "")
+	p4Buffer        = p4Session("0x3", "0x102", "10.60.0.3", "buffer", "", "")
+	p4Drop          = p4Session("0x4", "0x103", "10.60.0.4", "drop", "", "")
+)
+
+// TestCompileP4Golden compares the dry-run prototext of each FAR action with
+// the golden files of testdata/p4. Run with -update to rewrite them.
+func TestCompileP4Golden(t *testing.T) {
+	for _, tc := range []struct {
+		golden   string
+		sessions []Sessions
+	}{
+		{"forward.prototext", []Sessions{p4Forward}},
+		{"forward_no_outer_ip.prototext", []Sessions{p4ForwardNoPeer}},
+		{"buffer.prototext", []Sessions{p4Buffer}},
+		{"drop.prototext", []Sessions{p4Drop}},
+		// Given out of order, compiled by F-SEID
+		{"all.prototext", []Sessions{p4Drop, p4Buffer, p4ForwardNoPeer, p4Forward}},
+	} {
+		t.Run(tc.golden, func(t *testing.T) {
+			write, err := CompileP4(p4TestConfig, tc.sessions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := FormatText(write)
+			path := filepath.Join("testdata", "p4", tc.golden)
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("prototext differs from %s:\n%s", path, got)
+			}
+		})
+	}
+}
+
+// TestCompileP4Indexes checks that counter and meter indexes are only taken
+// by entries that are written, so they run without gaps
+func TestCompileP4Indexes(t *testing.T) {
+	write, err := CompileP4(p4TestConfig, []Sessions{p4ForwardNoPeer, p4Drop, p4Buffer, p4Forward})
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexes := map[string][]uint64{}
+	for _, u := range write.GetUpdates() {
+		for _, p := range u.GetTableEntry().GetAction().GetParams() {
+			if p.GetParam() != "ctr_idx" && p.GetParam() != "session_meter_idx" {
+				continue
+			}
+			var v uint64
+			for _, b := range p.GetValue() {
+				v = v<<8 | uint64(b)
+			}
+			indexes[p.GetParam()] = append(indexes[p.GetParam()], v)
+		}
+	}
+
+	// Counters: one per termination written. Forward without an outer_ip
+	// writes only the uplink one, drop and buffer both.
+	counters := indexes["ctr_idx"]
+	if len(counters) != 7 {
+		t.Fatalf("%d counters, want 7: %v", len(counters), counters)
+	}
+	for i, v := range counters {
+		if v != uint64(i) {
+			t.Fatalf("counter indexes %v, want 0 to 6", counters)
+		}
+	}
+	// Meters: one per session with a metered entry, shared by its uplink
+	// and downlink. The drop session has none.
+	want := []uint64{0, 0, 1, 2, 2}
+	meters := indexes["session_meter_idx"]
+	if len(meters) != len(want) {
+		t.Fatalf("meter indexes %v, want %v", meters, want)
+	}
+	for i := range want {
+		if meters[i] != want[i] {
+			t.Fatalf("meter indexes %v, want %v", meters, want)
+		}
+	}
+}
+
+func TestCompileP4Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		cfg      P4Config
+		sessions []Sessions
+	}{
+		{"access_ip", P4Config{AccessIP: "n3"}, nil},
+		{"ue_ip_pool", P4Config{AccessIP: "172.17.0.1", UEPool: "10.60.0.0/40"}, nil},
+		{"teid", p4TestConfig, []Sessions{p4Session("0x1", "teid", "10.60.0.1", "", "", "")}},
+		{"ue_ip", p4TestConfig, []Sessions{p4Session("0x1", "0x100", "10.60.0", "", "", "")}},
+		{"outer_teid", p4TestConfig, []Sessions{p4Session("0x1", "0x100", "10.60.0.1", "", "192.168.1.10", "")}},
+	} {
+		if w, err := CompileP4(tc.cfg, tc.sessions); err == nil {
+			t.Errorf("%s: compiled %d updates, want an error", tc.name, len(w.GetUpdates()))
+		}
+	}
+}
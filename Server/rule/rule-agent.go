@@ -7,9 +7,12 @@ package rule
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net"
 
+	"upf/Server/config"
+	"upf/Server/metrics"
 	pb "upf/pkg/proto"
 
 	"google.golang.org/grpc"
@@ -19,40 +22,125 @@ import (
 
 // Sessions represents a complete set of rules for a UPF session
 type Sessions struct {
-	pdr Pdrstruct // Packet Detection Rules
-	far Farstruct // Forwarding Action Rules
-	qer Qerstruct // QoS Enforcement Rules
-	urr Urrstruct // Usage Reporting Rules
+	fseid string    // F-SEID identifying the session
+	imsi  string    // IMSI of the subscriber owning the session
+	dnn   string    // Data Network Name the session belongs to
+	pdr   Pdrstruct // Packet Detection Rules
+	far   Farstruct // Forwarding Action Rules
+	qer   Qerstruct // QoS Enforcement Rules
+	urr   Urrstruct // Usage Reporting Rules
 }
 
 // Pdrstruct defines the structure for Packet Detection Rules
 type Pdrstruct struct {
 	pdr_id []string // List of PDR identifiers
 	fsied  string   // Associated F-SEID
+	ue_ip  string   // UE IP address matched by the PDRs
+	teid   string   // N3 TEID matched by the uplink PDRs
 }
 
 // Farstruct defines the structure for Forwarding Action Rules
 type Farstruct struct {
-	far_id string // FAR identifier
-	fsied  string // Associated F-SEID
+	far_id       string // FAR identifier
+	fsied        string // Associated F-SEID
+	apply_action string // forward, drop or buffer; empty means forward
+	outer_ip     string // Downlink outer header creation peer address
+	outer_teid   string // Downlink outer header creation TEID
 }
 
 // Qerstruct defines the structure for QoS Enforcement Rules
 type Qerstruct struct {
 	qer_id string // QER identifier
 	fsied  string // Associated F-SEID
+	qfi    uint32 // QoS Flow Identifier
+	ul_mbr uint64 // Uplink maximum bit rate in bps
+	dl_mbr uint64 // Downlink maximum bit rate in bps
+	ul_gbr uint64 // Uplink guaranteed bit rate in bps
+	dl_gbr uint64 // Downlink guaranteed bit rate in bps
 }
 
 // Urrstruct defines the structure for Usage Reporting Rules
 type Urrstruct struct {
-	urr_id string // URR identifier
-	fsied  string // Associated F-SEID
+	urr_id             string   // URR identifier
+	fsied              string   // Associated F-SEID
+	measurement_method []string // Measure* names; empty means volume and duration
+	reporting_triggers []string // Trigger* names; empty means the URR never reports on its own
+	volume_threshold   uint64   // Total octets after which a report is sent, 0 if none
+	volume_quota       uint64   // Total octets granted to the session, 0 if none
+	time_threshold     uint32   // Seconds of traffic after which a report is sent, 0 if none
+	time_quota         uint32   // Seconds of traffic granted to the session, 0 if none
+	measurement_period uint32   // Seconds between periodic reports, 0 if none
+}
+
+// Measurement methods of a URR
+const (
+	MeasureVolume   = "volume"
+	MeasureDuration = "duration"
+)
+
+// Reporting triggers of a URR
+const (
+	TriggerPeriodic        = "periodic"
+	TriggerVolumeThreshold = "volume_threshold"
+	TriggerTimeThreshold   = "time_threshold"
+	TriggerVolumeQuota     = "volume_quota"
+	TriggerTimeQuota       = "time_quota"
+	TriggerStartOfTraffic  = "start_of_traffic"
+)
+
+// validMeasurementMethods and validReportingTriggers list the names a URR accepts
+var (
+	validMeasurementMethods = map[string]bool{MeasureVolume: true, MeasureDuration: true}
+	validReportingTriggers  = map[string]bool{
+		TriggerPeriodic:        true,
+		TriggerVolumeThreshold: true,
+		TriggerTimeThreshold:   true,
+		TriggerVolumeQuota:     true,
+		TriggerTimeQuota:       true,
+		TriggerStartOfTraffic:  true,
+	}
+)
+
+// checkURR rejects URRs naming unknown measurement methods or reporting
+// triggers, and triggers whose threshold, quota or period is missing
+func checkURR(u *pb.Urrstruct) error {
+	for _, m := range u.GetMeasurementMethod() {
+		if !validMeasurementMethods[m] {
+			return status.Errorf(codes.InvalidArgument, "unknown URR measurement method %q", m)
+		}
+	}
+	for _, t := range u.GetReportingTriggers() {
+		if !validReportingTriggers[t] {
+			return status.Errorf(codes.InvalidArgument, "unknown URR reporting trigger %q", t)
+		}
+	}
+	required := map[string]bool{
+		TriggerPeriodic:        u.GetMeasurementPeriod() > 0,
+		TriggerVolumeThreshold: u.GetVolumeThreshold() > 0,
+		TriggerTimeThreshold:   u.GetTimeThreshold() > 0,
+		TriggerVolumeQuota:     u.GetVolumeQuota() > 0,
+		TriggerTimeQuota:       u.GetTimeQuota() > 0,
+	}
+	for _, t := range u.GetReportingTriggers() {
+		if set, ok := required[t]; ok && !set {
+			return status.Errorf(codes.InvalidArgument, "URR reporting trigger %q needs a non-zero value", t)
+		}
+	}
+	return nil
 }
 
 // ruleServer implements the gRPC Request service for rule management
 type ruleServer struct {
 	pb.UnimplementedRequestServer
-	session map[string]Sessions // Map of F-SEID to session rules
+	session *Store // Indexed store of session rules
+}
+
+// sharedStore is the session store shared by all agents running in this process
+var sharedStore = NewStore(defaultShards)
+
+// DefaultStore returns the session store shared by all agents running in this process
+func DefaultStore() *Store {
+	return sharedStore
 }
 
 // ValidatePDR validates if a PDR is valid for a given IMSI and DNN
@@ -64,22 +152,14 @@ func (s *ruleServer) ValidatePDR(ctx context.Context, req *pb.ValidatePDRRequest
 		}, nil
 	}
 
-	// In a real implementation, you would:
-	// 1. Look up the IMSI to get the associated F-SEID
-	// 2. Find the session using the F-SEID
-	// 3. Check if the PDR ID exists in the session's PDRs
-	// 4. Validate the DNN against the session's allowed DNNs
-
-	// For this example, we'll do a simple validation
+	// Look up the sessions holding the PDR through the PDR index, then make sure
+	// one of them belongs to the IMSI and DNN being validated. Sessions that do
+	// not record an IMSI or DNN match any value.
+	candidates := s.session.ByPDR(req.PdrId)
 	found := false
-	for _, session := range s.session {
-		for _, pdrID := range session.pdr.pdr_id {
-			if pdrID == req.PdrId {
-				found = true
-				break
-			}
-		}
-		if found {
+	for _, session := range candidates {
+		if (session.imsi == "" || session.imsi == req.Imsi) && (session.dnn == "" || session.dnn == req.Dnn) {
+			found = true
 			break
 		}
 	}
@@ -91,46 +171,201 @@ func (s *ruleServer) ValidatePDR(ctx context.Context, req *pb.ValidatePDRRequest
 		}, nil
 	}
 
-	// In a real implementation, you would also validate:
-	// 1. If the DNN is allowed for this IMSI
-	// 2. If the PDR is active and not expired
-	// 3. Any other business rules specific to your use case
-
 	return &pb.ValidatePDRReply{
 		Valid:   true,
 		Message: "PDR validation successful",
 	}, nil
 }
 
+// toProto converts a stored session into its protobuf representation
+func toProto(sessionInfo Sessions) *pb.Rulestruct {
+	return &pb.Rulestruct{
+		Pdr: &pb.Pdrstruct{
+			PdrId: sessionInfo.pdr.pdr_id,
+			Fsied: sessionInfo.pdr.fsied,
+			UeIp:  sessionInfo.pdr.ue_ip,
+			Teid:  sessionInfo.pdr.teid,
+		},
+		Far: &pb.Farstruct{
+			FarId:       sessionInfo.far.far_id,
+			Fsied:       sessionInfo.far.fsied,
+			ApplyAction: sessionInfo.far.apply_action,
+			OuterIp:     sessionInfo.far.outer_ip,
+			OuterTeid:   sessionInfo.far.outer_teid,
+		},
+		Qer: &pb.Qerstruct{
+			QerId: sessionInfo.qer.qer_id,
+			Fsied: sessionInfo.qer.fsied,
+			Qfi:   sessionInfo.qer.qfi,
+			UlMbr: sessionInfo.qer.ul_mbr,
+			DlMbr: sessionInfo.qer.dl_mbr,
+			UlGbr: sessionInfo.qer.ul_gbr,
+			DlGbr: sessionInfo.qer.dl_gbr,
+		},
+		Urr:  sessionInfo.URR(),
+		Imsi: sessionInfo.imsi,
+		Dnn:  sessionInfo.dnn,
+	}
+}
+
+// fromProto converts a protobuf session into the stored representation.
+// The session is keyed by the F-SEID of its PDRs, which every rule inherits.
+func fromProto(r *pb.Rulestruct) (Sessions, error) {
+	fseid := r.GetPdr().GetFsied()
+	if fseid == "" {
+		return Sessions{}, status.Error(codes.InvalidArgument, "session.pdr.fsied is required")
+	}
+	if err := checkURR(r.GetUrr()); err != nil {
+		return Sessions{}, err
+	}
+
+	return Sessions{
+		fseid: fseid,
+		imsi:  r.GetImsi(),
+		dnn:   r.GetDnn(),
+		pdr: Pdrstruct{
+			pdr_id: r.GetPdr().GetPdrId(),
+			fsied:  fseid,
+			ue_ip:  r.GetPdr().GetUeIp(),
+			teid:   r.GetPdr().GetTeid(),
+		},
+		far: Farstruct{
+			far_id:       r.GetFar().GetFarId(),
+			fsied:        fseid,
+			apply_action: r.GetFar().GetApplyAction(),
+			outer_ip:     r.GetFar().GetOuterIp(),
+			outer_teid:   r.GetFar().GetOuterTeid(),
+		},
+		qer: Qerstruct{
+			qer_id: r.GetQer().GetQerId(),
+			fsied:  fseid,
+			qfi:    r.GetQer().GetQfi(),
+			ul_mbr: r.GetQer().GetUlMbr(),
+			dl_mbr: r.GetQer().GetDlMbr(),
+			ul_gbr: r.GetQer().GetUlGbr(),
+			dl_gbr: r.GetQer().GetDlGbr(),
+		},
+		urr: Urrstruct{
+			urr_id:             r.GetUrr().GetUrrId(),
+			fsied:              fseid,
+			measurement_method: r.GetUrr().GetMeasurementMethod(),
+			reporting_triggers: r.GetUrr().GetReportingTriggers(),
+			volume_threshold:   r.GetUrr().GetVolumeThreshold(),
+			volume_quota:       r.GetUrr().GetVolumeQuota(),
+			time_threshold:     r.GetUrr().GetTimeThreshold(),
+			time_quota:         r.GetUrr().GetTimeQuota(),
+			measurement_period: r.GetUrr().GetMeasurementPeriod(),
+		},
+	}, nil
+}
+
+// PutRules stores session rules given in their protobuf form, for agents
+// outside this package that provision sessions (for example over N4)
+func (st *Store) PutRules(r *pb.Rulestruct, ch Change) error {
+	sessionInfo, err := fromProto(r)
+	if err != nil {
+		return err
+	}
+	return st.Put(sessionInfo, ch)
+}
+
+// Rules returns the rules stored under fseid in their protobuf form
+func (st *Store) Rules(fseid string) (*pb.Rulestruct, bool) {
+	sessionInfo, ok := st.Get(fseid)
+	if !ok {
+		return nil, false
+	}
+	return toProto(sessionInfo), true
+}
+
+// URR returns the Usage Reporting Rule of the session in its protobuf form
+func (s Sessions) URR() *pb.Urrstruct {
+	return &pb.Urrstruct{
+		UrrId:             s.urr.urr_id,
+		Fsied:             s.urr.fsied,
+		MeasurementMethod: s.urr.measurement_method,
+		ReportingTriggers: s.urr.reporting_triggers,
+		VolumeThreshold:   s.urr.volume_threshold,
+		VolumeQuota:       s.urr.volume_quota,
+		TimeThreshold:     s.urr.time_threshold,
+		TimeQuota:         s.urr.time_quota,
+		MeasurementPeriod: s.urr.measurement_period,
+	}
+}
+
+// QER returns the QoS Enforcement Rule of the session in its protobuf form
+func (s Sessions) QER() *pb.Qerstruct {
+	return &pb.Qerstruct{
+		QerId: s.qer.qer_id,
+		Fsied: s.qer.fsied,
+		Qfi:   s.qer.qfi,
+		UlMbr: s.qer.ul_mbr,
+		DlMbr: s.qer.dl_mbr,
+		UlGbr: s.qer.ul_gbr,
+		DlGbr: s.qer.dl_gbr,
+	}
+}
+
+// putError maps a store write error onto a gRPC status
+func putError(err error) error {
+	var capErr *CapacityError
+	if errors.As(err, &capErr) {
+		return status.Errorf(codes.ResourceExhausted, "%v", capErr)
+	}
+	return status.Errorf(codes.Internal, "failed to store session: %v", err)
+}
+
 // GetRule handles requests for retrieving session rules by F-SEID
 func (s *ruleServer) GetRule(ctx context.Context, req *pb.RuleRequest) (*pb.RuleReply, error) {
 	// Look up session information by F-SEID
-	sessionInfo, exists := s.session[req.Fsied]
+	sessionInfo, exists := s.session.Get(req.Fsied)
 	if !exists {
 		return nil, status.Errorf(codes.NotFound, "Session not found for F-SEID: %s", req.Fsied)
 	}
 
 	// Create and return the response with all rule information
-	return &pb.RuleReply{
-		Session: &pb.Rulestruct{
-			Pdr: &pb.Pdrstruct{
-				PdrId: sessionInfo.pdr.pdr_id,
-				Fsied: sessionInfo.pdr.fsied,
-			},
-			Far: &pb.Farstruct{
-				FarId: sessionInfo.far.far_id,
-				Fsied: sessionInfo.far.fsied,
-			},
-			Qer: &pb.Qerstruct{
-				QerId: sessionInfo.qer.qer_id,
-				Fsied: sessionInfo.qer.fsied,
-			},
-			Urr: &pb.Urrstruct{
-				UrrId: sessionInfo.urr.urr_id,
-				Fsied: sessionInfo.urr.fsied,
-			},
-		},
-	}, nil
+	return &pb.RuleReply{Session: toProto(sessionInfo)}, nil
+}
+
+// PutRule creates or replaces the rules of a session. Writes that would overflow
+// one of the configured lookup tables are rejected with ResourceExhausted.
+func (s *ruleServer) PutRule(ctx context.Context, req *pb.PutRuleRequest) (*pb.RuleReply, error) {
+	sessionInfo, err := fromProto(req.GetSession())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.session.Put(sessionInfo, callerIdentity(ctx, "PutRule")); err != nil {
+		return nil, putError(err)
+	}
+
+	log.Printf("Stored rules for F-SEID %s", sessionInfo.fseid)
+	return &pb.RuleReply{Session: toProto(sessionInfo)}, nil
+}
+
+// DeleteRule removes the rules of a session and returns what was removed
+func (s *ruleServer) DeleteRule(ctx context.Context, req *pb.RuleRequest) (*pb.RuleReply, error) {
+	sessionInfo, exists := s.session.Delete(req.Fsied, callerIdentity(ctx, "DeleteRule"))
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "Session not found for F-SEID: %s", req.Fsied)
+	}
+
+	log.Printf("Deleted rules for F-SEID %s", req.Fsied)
+	return &pb.RuleReply{Session: toProto(sessionInfo)}, nil
+}
+
+// GetTableUsage reports used and free entries of every lookup table
+func (s *ruleServer) GetTableUsage(ctx context.Context, req *pb.TableUsageRequest) (*pb.TableUsageReply, error) {
+	reply := &pb.TableUsageReply{}
+	for _, u := range s.session.TableUsage() {
+		reply.Tables = append(reply.Tables, &pb.TableUsage{
+			Table:    u.Table,
+			Capacity: u.Capacity,
+			Used:     u.Used,
+			Free:     u.Free(),
+		})
+	}
+	return reply, nil
 }
 
 // StartRuleAgent initializes and starts the rule management gRPC server
@@ -143,27 +378,54 @@ func StartRuleAgent(port string) error {
 	}
 
 	// Initialize gRPC server
-	s := grpc.NewServer()
+	s := grpc.NewServer(metrics.ServerOptions("rule")...)
 
-	// Initialize the rule server with an empty session map
+	// Initialize the rule server with the shared session store
 	srv := &ruleServer{
-		session: make(map[string]Sessions),
+		session: DefaultStore(),
+	}
+
+	// Enforce the lookup table sizes from the configuration file
+	cfg, err := config.LoadConfig(config.ConfigFile)
+	if err != nil {
+		log.Printf("⚠️ Table sizes not enforced: %v", err)
+	} else {
+		srv.session.SetTableSizes(cfg.TableSizes)
+	}
+
+	// Record every mutation in the audit log persisted in the data directory
+	if audit, err := OpenAuditLog(config.DataPath(cfg, AuditFile)); err != nil {
+		log.Printf("⚠️ Audit log not persisted: %v", err)
+	} else {
+		srv.session.SetAuditLog(audit)
 	}
 
 	// Add sample session rules for testing
 	// In production, these would be loaded from a persistent store
-	srv.session["fseid1"] = Sessions{
-		pdr: Pdrstruct{pdr_id: []string{"pdr1", "pdr2"}, fsied: "fseid1"},
-		far: Farstruct{far_id: "far1", fsied: "fseid1"},
-		qer: Qerstruct{qer_id: "qer1", fsied: "fseid1"},
-		urr: Urrstruct{urr_id: "urr1", fsied: "fseid1"},
-	}
-	srv.session["fseid2"] = Sessions{
-		pdr: Pdrstruct{pdr_id: []string{"pdr3", "pdr4"}, fsied: "fseid2"},
-		far: Farstruct{far_id: "far2", fsied: "fseid2"},
-		qer: Qerstruct{qer_id: "qer2", fsied: "fseid2"},
+	samples := []Sessions{{
+		fseid: "fseid1", imsi: "IMSI1", dnn: "internet",
+		pdr: Pdrstruct{pdr_id: []string{"pdr1", "pdr2"}, fsied: "fseid1", ue_ip: "16.0.0.1", teid: "0x30000000"},
+		far: Farstruct{far_id: "far1", fsied: "fseid1", apply_action: "forward", outer_ip: "11.1.1.129", outer_teid: "0x90000000"},
+		qer: Qerstruct{qer_id: "qer1", fsied: "fseid1", qfi: 9, ul_mbr: 500000, dl_mbr: 1000000, ul_gbr: 50000, dl_gbr: 100000},
+		urr: Urrstruct{
+			urr_id: "urr1", fsied: "fseid1",
+			reporting_triggers: []string{TriggerPeriodic, TriggerVolumeThreshold},
+			volume_threshold:   1000000, measurement_period: 10,
+		},
+	}, {
+		fseid: "fseid2", imsi: "IMSI1", dnn: "ims",
+		pdr: Pdrstruct{pdr_id: []string{"pdr3", "pdr4"}, fsied: "fseid2", ue_ip: "16.0.0.2", teid: "0x30000001"},
+		far: Farstruct{far_id: "far2", fsied: "fseid2", apply_action: "forward", outer_ip: "11.1.1.129", outer_teid: "0x90000001"},
+		qer: Qerstruct{qer_id: "qer2", fsied: "fseid2", qfi: 5, ul_mbr: 500000, dl_mbr: 1000000, ul_gbr: 50000, dl_gbr: 100000},
 		urr: Urrstruct{urr_id: "urr2", fsied: "fseid2"},
+	}}
+	for _, sample := range samples {
+		if err := srv.session.Seed(sample, Change{Caller: "system", Operation: "StartRuleAgent"}); err != nil {
+			log.Printf("❌ Failed to add sample session %s: %v", sample.fseid, err)
+		}
 	}
+	// Writers waiting for the table sizes and samples may go ahead
+	srv.session.MarkReady()
 
 	// Register the rule server with gRPC
 	pb.RegisterRequestServer(s, srv)
@@ -0,0 +1,172 @@
+package rule
+
+import (
+	"context"
+	"log"
+	"time"
+
+	pb "upf/pkg/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// matchesFilter reports whether a session is selected by an export filter.
+// Each non-empty list of the filter must contain the session's value.
+func matchesFilter(s Sessions, f *pb.SessionFilter) bool {
+	in := func(list []string, v string) bool {
+		if len(list) == 0 {
+			return true
+		}
+		for _, item := range list {
+			if item == v {
+				return true
+			}
+		}
+		return false
+	}
+	return in(f.GetFseid(), s.fseid) && in(f.GetImsi(), s.imsi) && in(f.GetDnn(), s.dnn)
+}
+
+// encodeSnapshot serializes a snapshot in the requested format
+func encodeSnapshot(snap *pb.SessionSnapshot, format pb.SnapshotFormat) ([]byte, error) {
+	switch format {
+	case pb.SnapshotFormat_SNAPSHOT_JSON:
+		return protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(snap)
+	case pb.SnapshotFormat_SNAPSHOT_BINARY:
+		return proto.Marshal(snap)
+	}
+	return nil, status.Errorf(codes.InvalidArgument, "unknown snapshot format: %v", format)
+}
+
+// decodeSnapshot parses a snapshot in the given format
+func decodeSnapshot(data []byte, format pb.SnapshotFormat) (*pb.SessionSnapshot, error) {
+	snap := &pb.SessionSnapshot{}
+	var err error
+	switch format {
+	case pb.SnapshotFormat_SNAPSHOT_JSON:
+		err = protojson.Unmarshal(data, snap)
+	case pb.SnapshotFormat_SNAPSHOT_BINARY:
+		err = proto.Unmarshal(data, snap)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown snapshot format: %v", format)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid snapshot: %v", err)
+	}
+	return snap, nil
+}
+
+// ExportSessions serializes all sessions matching the filter into a snapshot
+func (s *ruleServer) ExportSessions(ctx context.Context, req *pb.ExportSessionsRequest) (*pb.ExportSessionsReply, error) {
+	snap := &pb.SessionSnapshot{CreatedUnix: time.Now().Unix()}
+	s.session.Range(func(sessionInfo Sessions) bool {
+		if matchesFilter(sessionInfo, req.GetFilter()) {
+			snap.Sessions = append(snap.Sessions, toProto(sessionInfo))
+		}
+		return true
+	})
+
+	data, err := encodeSnapshot(snap, req.GetFormat())
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Exported %d sessions", len(snap.Sessions))
+	return &pb.ExportSessionsReply{
+		Snapshot: data,
+		Format:   req.GetFormat(),
+		Count:    int32(len(snap.Sessions)),
+	}, nil
+}
+
+// ImportSessions loads the sessions of a snapshot into the store.
+// In merge mode stored sessions win and differing ones are reported as conflicts,
+// as are sessions rejected by the store, for example because a table is full.
+// Replace mode is all or nothing: the store is emptied first, a snapshot that
+// does not fit the tables is refused before anything is removed, and if a
+// session is still rejected the removed sessions are restored.
+func (s *ruleServer) ImportSessions(ctx context.Context, req *pb.ImportSessionsRequest) (*pb.ImportSessionsReply, error) {
+	snap, err := decodeSnapshot(req.GetSnapshot(), req.GetFormat())
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the whole snapshot before touching the store
+	sessions := make([]Sessions, 0, len(snap.GetSessions()))
+	for i, r := range snap.GetSessions() {
+		sessionInfo, err := fromProto(r)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "session %d: %v", i, status.Convert(err).Message())
+		}
+		sessions = append(sessions, sessionInfo)
+	}
+
+	change := callerIdentity(ctx, "ImportSessions")
+	reply := &pb.ImportSessionsReply{}
+	replace := req.GetMode() == pb.ImportMode_IMPORT_REPLACE
+	var removed, imported []Sessions
+	if replace {
+		if err := s.session.capacity.fits(sessions); err != nil {
+			return nil, putError(err)
+		}
+		s.session.Range(func(sessionInfo Sessions) bool {
+			removed = append(removed, sessionInfo)
+			return true
+		})
+		for _, sessionInfo := range removed {
+			if _, ok := s.session.Delete(sessionInfo.fseid, change); ok {
+				reply.Removed++
+			}
+		}
+	}
+
+	for _, sessionInfo := range sessions {
+		if existing, ok := s.session.Get(sessionInfo.fseid); ok {
+			if proto.Equal(toProto(existing), toProto(sessionInfo)) {
+				reply.Unchanged++
+			} else {
+				reply.Conflicts = append(reply.Conflicts, &pb.ImportConflict{
+					Fseid:  sessionInfo.fseid,
+					Reason: "session already exists with different rules",
+				})
+			}
+			continue
+		}
+
+		if err := s.session.Put(sessionInfo, change); err != nil {
+			if replace {
+				s.restore(imported, removed, change)
+				return nil, putError(err)
+			}
+			reply.Conflicts = append(reply.Conflicts, &pb.ImportConflict{
+				Fseid:  sessionInfo.fseid,
+				Reason: err.Error(),
+			})
+			continue
+		}
+		reply.Imported++
+		imported = append(imported, sessionInfo)
+	}
+
+	log.Printf("Imported %d sessions (%d unchanged, %d removed, %d conflicts)",
+		reply.Imported, reply.Unchanged, reply.Removed, len(reply.Conflicts))
+	return reply, nil
+}
+
+// restore undoes a failed replace: it removes the sessions imported so far
+// and puts back the sessions the replace removed
+func (s *ruleServer) restore(imported, removed []Sessions, change Change) {
+	change.Operation += "/rollback"
+	for _, sessionInfo := range imported {
+		s.session.Delete(sessionInfo.fseid, change)
+	}
+	for _, sessionInfo := range removed {
+		if err := s.session.Put(sessionInfo, change); err != nil {
+			log.Printf("❌ Failed to restore session %s after a failed import: %v", sessionInfo.fseid, err)
+		}
+	}
+	log.Printf("⚠️ Import rolled back, restored %d sessions", len(removed))
+}
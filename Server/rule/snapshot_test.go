@@ -0,0 +1,109 @@
+package rule
+
+import (
+	"context"
+	"testing"
+
+	"upf/Server/config"
+	pb "upf/pkg/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// snapshotOf encodes sessions first to first+n-1 as a binary snapshot
+func snapshotOf(t *testing.T, first, n int) []byte {
+	t.Helper()
+	snap := &pb.SessionSnapshot{}
+	for i := first; i < first+n; i++ {
+		snap.Sessions = append(snap.Sessions, toProto(testSession(i)))
+	}
+	data, err := encodeSnapshot(snap, pb.SnapshotFormat_SNAPSHOT_BINARY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// storedFSEIDs returns the F-SEIDs in the store
+func storedFSEIDs(st *Store) map[string]bool {
+	out := make(map[string]bool)
+	st.Range(func(s Sessions) bool {
+		out[s.fseid] = true
+		return true
+	})
+	return out
+}
+
+func TestImportReplace(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name     string
+		sessions int // Sessions in the snapshot, numbered from 100
+		code     codes.Code
+		imported int32
+	}{
+		{name: "fits", sessions: 4, code: codes.OK, imported: 4},
+		{name: "overflows", sessions: 6, code: codes.ResourceExhausted},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			st := filledStore(t, 3)
+			st.SetTableSizes(config.TableSizes{FARLookup: 5 * farsPerSession})
+			before := storedFSEIDs(st)
+			srv := &ruleServer{session: st}
+
+			reply, err := srv.ImportSessions(ctx, &pb.ImportSessionsRequest{
+				Snapshot: snapshotOf(t, 100, tc.sessions),
+				Format:   pb.SnapshotFormat_SNAPSHOT_BINARY,
+				Mode:     pb.ImportMode_IMPORT_REPLACE,
+			})
+			if status.Code(err) != tc.code {
+				t.Fatalf("import returned %v, want %v", err, tc.code)
+			}
+			if err != nil {
+				// Refused before anything was removed
+				after := storedFSEIDs(st)
+				if len(after) != len(before) {
+					t.Fatalf("store holds %d sessions after a refused replace, want %d", len(after), len(before))
+				}
+				for fseid := range before {
+					if !after[fseid] {
+						t.Errorf("session %s lost by a refused replace", fseid)
+					}
+				}
+				return
+			}
+			if reply.Imported != tc.imported || reply.Removed != 3 || st.Len() != tc.sessions {
+				t.Errorf("imported %d, removed %d, stored %d", reply.Imported, reply.Removed, st.Len())
+			}
+		})
+	}
+}
+
+func TestImportRestore(t *testing.T) {
+	st := filledStore(t, 3)
+	srv := &ruleServer{session: st}
+	removed := make([]Sessions, 0, 3)
+	st.Range(func(s Sessions) bool {
+		removed = append(removed, s)
+		return true
+	})
+	for _, s := range removed {
+		st.Delete(s.fseid, Change{})
+	}
+	imported := []Sessions{testSession(100)}
+	if err := st.Put(imported[0], Change{}); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.restore(imported, removed, Change{Operation: "ImportSessions"})
+	got := storedFSEIDs(st)
+	if len(got) != 3 || got[imported[0].fseid] {
+		t.Fatalf("store holds %v after restore, want the 3 removed sessions", got)
+	}
+	for _, u := range st.TableUsage() {
+		if u.Table == TableFARLookup && u.Used != 3*farsPerSession {
+			t.Errorf("FAR table uses %d entries after restore, want %d", u.Used, 3*farsPerSession)
+		}
+	}
+}
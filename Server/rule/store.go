@@ -0,0 +1,378 @@
+package rule
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultShards is the number of lock shards used by the session store and its indexes
+const defaultShards = 64
+
+// sessionShard holds a slice of the session map behind its own lock
+type sessionShard struct {
+	mu       sync.RWMutex
+	sessions map[string]Sessions // Map of F-SEID to session rules
+}
+
+// indexShard holds a slice of a secondary index behind its own lock
+type indexShard struct {
+	mu      sync.RWMutex
+	entries map[string]map[string]struct{} // Map of key to the set of F-SEIDs using it
+}
+
+// index is a sharded multi-map from a rule attribute (PDR ID, TEID, ...) to F-SEIDs
+type index struct {
+	shards []indexShard
+}
+
+// Store is the session rule store. Sessions are sharded by F-SEID and kept
+// reachable through secondary indexes on PDR ID, FAR ID, UE IP, TEID, IMSI and DNN,
+// so lookups cost the same with ten sessions as with a hundred thousand.
+type Store struct {
+	shards []sessionShard
+	byPDR  *index // PDR ID -> F-SEIDs
+	byFAR  *index // FAR ID -> F-SEIDs
+	byUEIP *index // UE IP address -> F-SEIDs
+	byTEID *index // TEID -> F-SEIDs
+	byIMSI *index // IMSI -> F-SEIDs
+	byDNN  *index // DNN -> F-SEIDs
+
+	capacity *capacity // Lookup table accounting against table_sizes
+
+	auditMu sync.RWMutex
+	audit   *AuditLog // Record of every mutation
+
+	ready     chan struct{} // Closed by MarkReady
+	readyOnce sync.Once
+}
+
+// shardFor returns the shard number for a key
+func shardFor(key string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(n))
+}
+
+// newIndex creates an empty index with n shards
+func newIndex(n int) *index {
+	idx := &index{shards: make([]indexShard, n)}
+	for i := range idx.shards {
+		idx.shards[i].entries = make(map[string]map[string]struct{})
+	}
+	return idx
+}
+
+// add records that the session identified by fseid uses key
+func (idx *index) add(key, fseid string) {
+	if key == "" {
+		return
+	}
+	sh := &idx.shards[shardFor(key, len(idx.shards))]
+	sh.mu.Lock()
+	set, ok := sh.entries[key]
+	if !ok {
+		set = make(map[string]struct{})
+		sh.entries[key] = set
+	}
+	set[fseid] = struct{}{}
+	sh.mu.Unlock()
+}
+
+// remove drops the association between key and fseid
+func (idx *index) remove(key, fseid string) {
+	if key == "" {
+		return
+	}
+	sh := &idx.shards[shardFor(key, len(idx.shards))]
+	sh.mu.Lock()
+	if set, ok := sh.entries[key]; ok {
+		delete(set, fseid)
+		if len(set) == 0 {
+			delete(sh.entries, key)
+		}
+	}
+	sh.mu.Unlock()
+}
+
+// lookup returns the F-SEIDs currently associated with key
+func (idx *index) lookup(key string) []string {
+	sh := &idx.shards[shardFor(key, len(idx.shards))]
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	set := sh.entries[key]
+	fseids := make([]string, 0, len(set))
+	for fseid := range set {
+		fseids = append(fseids, fseid)
+	}
+	return fseids
+}
+
+// update moves fseid from the old keys to the new keys, touching only what changed
+func (idx *index) update(fseid string, oldKeys, newKeys []string) {
+	keep := make(map[string]struct{}, len(newKeys))
+	for _, k := range newKeys {
+		keep[k] = struct{}{}
+	}
+	for _, k := range oldKeys {
+		if _, ok := keep[k]; !ok {
+			idx.remove(k, fseid)
+		}
+	}
+	for _, k := range newKeys {
+		idx.add(k, fseid)
+	}
+}
+
+// NewStore creates an empty session store with the given number of lock shards
+func NewStore(shards int) *Store {
+	if shards <= 0 {
+		shards = defaultShards
+	}
+	st := &Store{
+		shards: make([]sessionShard, shards),
+		byPDR:  newIndex(shards),
+		byFAR:  newIndex(shards),
+		byUEIP: newIndex(shards),
+		byTEID: newIndex(shards),
+		byIMSI: newIndex(shards),
+		byDNN:  newIndex(shards),
+
+		capacity: newCapacity(),
+		audit:    NewAuditLog(),
+		ready:    make(chan struct{}),
+	}
+	for i := range st.shards {
+		st.shards[i].sessions = make(map[string]Sessions)
+	}
+	return st
+}
+
+// MarkReady signals that the store is set up: its table sizes are applied and
+// the sessions the UPF starts with are seeded
+func (st *Store) MarkReady() {
+	st.readyOnce.Do(func() { close(st.ready) })
+}
+
+// Ready returns a channel that is closed once MarkReady was called
+func (st *Store) Ready() <-chan struct{} {
+	return st.ready
+}
+
+// shard returns the session shard owning fseid
+func (st *Store) shard(fseid string) *sessionShard {
+	return &st.shards[shardFor(fseid, len(st.shards))]
+}
+
+// reindex updates every secondary index for a session going from prev to next.
+// Callers must hold the write lock of the session's shard so that concurrent
+// writes to the same F-SEID are applied to the indexes in order.
+func (st *Store) reindex(fseid string, prev, next *Sessions) {
+	var o, n Sessions
+	if prev != nil {
+		o = *prev
+	}
+	if next != nil {
+		n = *next
+	}
+	st.byPDR.update(fseid, o.pdr.pdr_id, n.pdr.pdr_id)
+	st.byFAR.update(fseid, []string{o.far.far_id}, []string{n.far.far_id})
+	st.byUEIP.update(fseid, []string{o.pdr.ue_ip}, []string{n.pdr.ue_ip})
+	st.byTEID.update(fseid, []string{o.pdr.teid}, []string{n.pdr.teid})
+	st.byIMSI.update(fseid, []string{o.imsi}, []string{n.imsi})
+	st.byDNN.update(fseid, []string{o.dnn}, []string{n.dnn})
+}
+
+// Audit returns the audit log recording the mutations of the store
+func (st *Store) Audit() *AuditLog {
+	st.auditMu.RLock()
+	defer st.auditMu.RUnlock()
+	return st.audit
+}
+
+// SetAuditLog replaces the audit log recording the mutations of the store
+func (st *Store) SetAuditLog(a *AuditLog) {
+	st.auditMu.Lock()
+	defer st.auditMu.Unlock()
+	st.audit = a
+}
+
+// Put inserts or replaces the session stored under its F-SEID and records
+// the change in the audit log. It returns a *CapacityError and leaves the
+// store unchanged if the write would overflow one of the lookup tables.
+func (st *Store) Put(s Sessions, ch Change) error {
+	return st.put(s, ch, true)
+}
+
+// Seed puts a session the UPF creates at start. The change is audited only
+// when the session differs from its last audited state, so restarts do not
+// append the same create event again.
+func (st *Store) Seed(s Sessions, ch Change) error {
+	return st.put(s, ch, !proto.Equal(st.Audit().latest(s.fseid), toProto(s)))
+}
+
+// put inserts or replaces a session, recording the change when audit is set
+func (st *Store) put(s Sessions, ch Change, audit bool) error {
+	s = s.clone()
+
+	sh := st.shard(s.fseid)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	var old *Sessions
+	if prev, ok := sh.sessions[s.fseid]; ok {
+		old = &prev
+	}
+	if err := st.capacity.reserve(old, &s); err != nil {
+		return err
+	}
+	sh.sessions[s.fseid] = s
+	st.reindex(s.fseid, old, &s)
+	if audit {
+		st.Audit().record(ch, s.fseid, old, &s)
+	}
+	return nil
+}
+
+// Delete removes the session stored under fseid, records the change in the
+// audit log and returns the removed session
+func (st *Store) Delete(fseid string, ch Change) (Sessions, bool) {
+	sh := st.shard(fseid)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	prev, ok := sh.sessions[fseid]
+	if !ok {
+		return Sessions{}, false
+	}
+	delete(sh.sessions, fseid)
+	st.capacity.reserve(&prev, nil)
+	st.reindex(fseid, &prev, nil)
+	st.Audit().record(ch, fseid, &prev, nil)
+	return prev, true
+}
+
+// Get returns a copy of the session stored under fseid
+func (st *Store) Get(fseid string) (Sessions, bool) {
+	sh := st.shard(fseid)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	s, ok := sh.sessions[fseid]
+	if ok {
+		s = s.clone()
+	}
+	return s, ok
+}
+
+// Len returns the number of sessions in the store
+func (st *Store) Len() int {
+	n := 0
+	for i := range st.shards {
+		st.shards[i].mu.RLock()
+		n += len(st.shards[i].sessions)
+		st.shards[i].mu.RUnlock()
+	}
+	return n
+}
+
+// Range calls fn for a copy of every session until fn returns false.
+// Each shard is locked only while it is being visited.
+func (st *Store) Range(fn func(Sessions) bool) {
+	for i := range st.shards {
+		sh := &st.shards[i]
+		sh.mu.RLock()
+		batch := make([]Sessions, 0, len(sh.sessions))
+		for _, s := range sh.sessions {
+			batch = append(batch, s.clone())
+		}
+		sh.mu.RUnlock()
+
+		for _, s := range batch {
+			if !fn(s) {
+				return
+			}
+		}
+	}
+}
+
+// resolve turns F-SEIDs found in an index into sessions, skipping any whose
+// rules no longer match because a concurrent write got there first
+func (st *Store) resolve(fseids []string, match func(Sessions) bool) []Sessions {
+	var out []Sessions
+	for _, fseid := range fseids {
+		if s, ok := st.Get(fseid); ok && match(s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// ByPDR returns the sessions that contain the given PDR ID
+func (st *Store) ByPDR(pdrID string) []Sessions {
+	return st.resolve(st.byPDR.lookup(pdrID), func(s Sessions) bool {
+		for _, id := range s.pdr.pdr_id {
+			if id == pdrID {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ByFAR returns the sessions that use the given FAR ID
+func (st *Store) ByFAR(farID string) []Sessions {
+	return st.resolve(st.byFAR.lookup(farID), func(s Sessions) bool { return s.far.far_id == farID })
+}
+
+// ByUEIP returns the sessions whose PDRs match the given UE IP address
+func (st *Store) ByUEIP(ip string) []Sessions {
+	return st.resolve(st.byUEIP.lookup(ip), func(s Sessions) bool { return s.pdr.ue_ip == ip })
+}
+
+// ByTEID returns the sessions whose uplink PDRs match the given TEID
+func (st *Store) ByTEID(teid string) []Sessions {
+	return st.resolve(st.byTEID.lookup(teid), func(s Sessions) bool { return s.pdr.teid == teid })
+}
+
+// ByIMSI returns all sessions belonging to the given subscriber
+func (st *Store) ByIMSI(imsi string) []Sessions {
+	return st.resolve(st.byIMSI.lookup(imsi), func(s Sessions) bool { return s.imsi == imsi })
+}
+
+// ByDNN returns all sessions on the given Data Network Name
+func (st *Store) ByDNN(dnn string) []Sessions {
+	return st.resolve(st.byDNN.lookup(dnn), func(s Sessions) bool { return s.dnn == dnn })
+}
+
+// clone returns a copy of the session that shares no slices with s
+func (s Sessions) clone() Sessions {
+	s.pdr.pdr_id = append([]string(nil), s.pdr.pdr_id...)
+	s.urr.measurement_method = append([]string(nil), s.urr.measurement_method...)
+	s.urr.reporting_triggers = append([]string(nil), s.urr.reporting_triggers...)
+	return s
+}
+
+// FSEID returns the F-SEID identifying the session
+func (s Sessions) FSEID() string { return s.fseid }
+
+// IMSI returns the IMSI of the subscriber owning the session
+func (s Sessions) IMSI() string { return s.imsi }
+
+// DNN returns the Data Network Name the session belongs to
+func (s Sessions) DNN() string { return s.dnn }
+
+// UEIP returns the UE IP address matched by the session's PDRs
+func (s Sessions) UEIP() string { return s.pdr.ue_ip }
+
+// TEID returns the N3 TEID matched by the session's uplink PDRs
+func (s Sessions) TEID() string { return s.pdr.teid }
+
+// ApplyAction returns the action of the session's FAR: forward, drop or buffer
+func (s Sessions) ApplyAction() string {
+	if s.far.apply_action == "" {
+		return "forward"
+	}
+	return s.far.apply_action
+}
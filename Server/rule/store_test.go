@@ -0,0 +1,137 @@
+package rule
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	pb "upf/pkg/proto"
+)
+
+// sessionSizes are the store sizes the lookup benchmarks run at
+var sessionSizes = []int{10, 1000, 10000, 100000}
+
+// testSession returns session i with its own PDRs, FAR, UE IP, TEID and IMSI
+func testSession(i int) Sessions {
+	fseid := fmt.Sprintf("0x%x", i+1)
+	return Sessions{
+		fseid: fseid,
+		imsi:  fmt.Sprintf("001010%09d", i+1),
+		dnn:   "internet",
+		pdr: Pdrstruct{
+			pdr_id: []string{fmt.Sprintf("pdr-%d-ul", i), fmt.Sprintf("pdr-%d-dl", i)},
+			fsied:  fseid,
+			ue_ip:  fmt.Sprintf("16.%d.%d.%d", i>>16&0xff, i>>8&0xff, i&0xff),
+			teid:   fmt.Sprintf("0x%08x", 0x30000000+i),
+		},
+		far: Farstruct{far_id: fmt.Sprintf("far-%d", i), fsied: fseid},
+		qer: Qerstruct{qer_id: fmt.Sprintf("qer-%d", i), fsied: fseid},
+	}
+}
+
+// filledStore returns a store holding n test sessions
+func filledStore(tb testing.TB, n int) *Store {
+	tb.Helper()
+	st := NewStore(defaultShards)
+	for i := 0; i < n; i++ {
+		if err := st.Put(testSession(i), Change{Caller: "test"}); err != nil {
+			tb.Fatalf("put session %d: %v", i, err)
+		}
+	}
+	return st
+}
+
+func TestStoreIndexes(t *testing.T) {
+	st := filledStore(t, 100)
+	s := testSession(42)
+	lookups := []struct {
+		name string
+		got  []Sessions
+	}{
+		{"pdr", st.ByPDR(s.pdr.pdr_id[1])},
+		{"far", st.ByFAR(s.far.far_id)},
+		{"ue ip", st.ByUEIP(s.pdr.ue_ip)},
+		{"teid", st.ByTEID(s.pdr.teid)},
+		{"imsi", st.ByIMSI(s.imsi)},
+	}
+	for _, l := range lookups {
+		if len(l.got) != 1 || l.got[0].fseid != s.fseid {
+			t.Errorf("lookup by %s found %d sessions, want %s", l.name, len(l.got), s.fseid)
+		}
+	}
+	if got := len(st.ByDNN("internet")); got != 100 {
+		t.Errorf("lookup by dnn found %d sessions, want 100", got)
+	}
+
+	// Replacing a session moves it in the indexes, deleting removes it
+	moved := s
+	moved.pdr.pdr_id = []string{"pdr-moved"}
+	if err := st.Put(moved, Change{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := st.ByPDR(s.pdr.pdr_id[0]); len(got) != 0 {
+		t.Errorf("old PDR still finds %d sessions", len(got))
+	}
+	if got := st.ByPDR("pdr-moved"); len(got) != 1 {
+		t.Errorf("new PDR finds %d sessions, want 1", len(got))
+	}
+	st.Delete(s.fseid, Change{})
+	if got := st.ByPDR("pdr-moved"); len(got) != 0 {
+		t.Errorf("deleted session still found by PDR")
+	}
+}
+
+// BenchmarkStoreByPDR measures a PDR lookup at growing store sizes. Lookups go
+// through the index, so their cost does not grow with the number of sessions
+// beyond the cache misses of a larger heap.
+func BenchmarkStoreByPDR(b *testing.B) {
+	for _, n := range sessionSizes {
+		b.Run(fmt.Sprintf("sessions=%d", n), func(b *testing.B) {
+			st := filledStore(b, n)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if got := st.ByPDR(fmt.Sprintf("pdr-%d-ul", i%n)); len(got) != 1 {
+					b.Fatalf("found %d sessions", len(got))
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkValidatePDR measures ValidatePDR at growing store sizes
+func BenchmarkValidatePDR(b *testing.B) {
+	for _, n := range sessionSizes {
+		b.Run(fmt.Sprintf("sessions=%d", n), func(b *testing.B) {
+			srv := &ruleServer{session: filledStore(b, n)}
+			ctx := context.Background()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s := i % n
+				reply, err := srv.ValidatePDR(ctx, &pb.ValidatePDRRequest{
+					Imsi:  fmt.Sprintf("001010%09d", s+1),
+					PdrId: fmt.Sprintf("pdr-%d-dl", s),
+					Dnn:   "internet",
+				})
+				if err != nil || !reply.Valid {
+					b.Fatalf("session %d not valid: %v %v", s, reply, err)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreReady(t *testing.T) {
+	st := NewStore(4)
+	select {
+	case <-st.Ready():
+		t.Fatal("ready before MarkReady")
+	default:
+	}
+	st.MarkReady()
+	st.MarkReady()
+	select {
+	case <-st.Ready():
+	default:
+		t.Fatal("not ready after MarkReady")
+	}
+}
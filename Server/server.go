@@ -1,6 +1,7 @@
 /*
 Package main implements a multi-agent gRPC server that manages various UPF (User Plane Function)
-services including configuration, IMSI handling, PFCP protocol, rule management and validation.
+services including configuration, IMSI handling, PFCP protocol, rule management and validation,
+and serves their Prometheus metrics.
 */
 package main
 
@@ -10,6 +11,7 @@ import (
 
 	"upf/Server/config"
 	"upf/Server/imsi"
+	"upf/Server/metrics"
 	"upf/Server/pfcp"
 	"upf/Server/rule"
 	"upf/Server/validation"
@@ -20,7 +22,7 @@ func main() {
 	log.Println("🚀 Starting Multi-Agent gRPC Server...")
 
 	var wg sync.WaitGroup
-	wg.Add(5) // We have 5 agents running concurrently
+	wg.Add(6) // We have 5 agents and the metrics endpoint running concurrently
 
 	// Start Config Agent on port 3000
 	go func() {
@@ -62,6 +64,20 @@ func main() {
 		}
 	}()
 
+	// Start the metrics endpoint on the configured port, 9090 by default
+	go func() {
+		defer wg.Done()
+		port := "9090"
+		if cfg, err := config.LoadConfig(config.ConfigFile); err != nil {
+			log.Printf("⚠️ Failed to load config, serving metrics on port %s: %v", port, err)
+		} else if cfg.Metrics.Port != "" {
+			port = cfg.Metrics.Port
+		}
+		if err := metrics.StartMetricsServer(port); err != nil {
+			log.Printf("❌ Metrics endpoint failed: %v", err)
+		}
+	}()
+
 	// Wait for all agents to complete
 	wg.Wait()
 	log.Println("✨ All agents have exited.")
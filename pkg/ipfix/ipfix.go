@@ -0,0 +1,279 @@
+/*
+Package ipfix implements the IPFIX (IP Flow Information Export, RFC 7011) message
+format used to export flow records to a collector. It encodes message headers,
+template sets and data sets, leaving the choice of records to the exporter.
+*/
+package ipfix
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Version is the IPFIX protocol version carried in every header
+const Version = 10
+
+// Port is the well-known port of IPFIX collectors, over UDP and TCP
+const Port = 4739
+
+// VariableLength is the field length of variable-length Information Elements
+const VariableLength = 0xffff
+
+// Sizes of the fixed parts of a message
+const (
+	headerLen    = 16     // Message header
+	setHeaderLen = 4      // Set ID and length
+	maxLen       = 0xffff // Largest message the length field can carry
+)
+
+// Set IDs below 256 are reserved; the lowest ones name template sets
+const (
+	templateSetID = 2
+	minDataSetID  = 256
+)
+
+// Information Elements of the IANA registry
+const (
+	IEOctetDeltaCount        uint16 = 1
+	IEPacketDeltaCount       uint16 = 2
+	IESourceIPv4Address      uint16 = 8
+	IEDestinationIPv4Address uint16 = 12
+	IEFlowDirection          uint16 = 61
+	IEFlowEndReason          uint16 = 136
+	IEFlowStartMilliseconds  uint16 = 152
+	IEFlowEndMilliseconds    uint16 = 153
+)
+
+// ExampleEnterprise is the private enterprise number reserved for documentation (RFC 5612)
+const ExampleEnterprise uint32 = 32473
+
+// enterpriseBit marks field specifiers followed by an enterprise number
+const enterpriseBit uint16 = 0x8000
+
+// Values of flowDirection
+const (
+	DirectionIngress uint8 = 0
+	DirectionEgress  uint8 = 1
+)
+
+// Values of flowEndReason
+const (
+	EndIdleTimeout     uint8 = 1
+	EndActiveTimeout   uint8 = 2
+	EndOfFlow          uint8 = 3
+	EndForced          uint8 = 4
+	EndLackOfResources uint8 = 5
+)
+
+// Encoding errors
+var (
+	ErrTemplateID = errors.New("ipfix: template IDs start at 256")
+	ErrFields     = errors.New("ipfix: record does not match its template")
+	ErrTooLarge   = errors.New("ipfix: record does not fit in a message")
+)
+
+// Field is a field specifier of a template
+type Field struct {
+	ID         uint16 // Information Element identifier, without the enterprise bit
+	Length     uint16 // Encoded length in octets, or VariableLength
+	Enterprise uint32 // Private enterprise number, 0 for IANA elements
+}
+
+// Template describes the fields of the data records sent in its sets
+type Template struct {
+	ID     uint16 // Template ID, also the set ID of its data sets
+	Fields []Field
+}
+
+// encode appends the template record
+func (t Template) encode(b []byte) []byte {
+	b = binary.BigEndian.AppendUint16(b, t.ID)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(t.Fields)))
+	for _, f := range t.Fields {
+		id := f.ID &^ enterpriseBit
+		if f.Enterprise != 0 {
+			id |= enterpriseBit
+		}
+		b = binary.BigEndian.AppendUint16(b, id)
+		b = binary.BigEndian.AppendUint16(b, f.Length)
+		if f.Enterprise != 0 {
+			b = binary.BigEndian.AppendUint32(b, f.Enterprise)
+		}
+	}
+	return b
+}
+
+// Record holds the values of a data record, one per field of its template.
+// Unsigned fields take any unsigned integer, address fields a net.IP,
+// dateTimeMilliseconds fields a time.Time and variable-length fields a string
+// or []byte.
+type Record []any
+
+// encode appends the record as laid out by the template
+func (r Record) encode(b []byte, t Template) ([]byte, error) {
+	if len(r) != len(t.Fields) {
+		return b, fmt.Errorf("%w: %d values for %d fields", ErrFields, len(r), len(t.Fields))
+	}
+	for i, f := range t.Fields {
+		var err error
+		if b, err = encodeValue(b, f, r[i]); err != nil {
+			return b, fmt.Errorf("%w: field %d: %v", ErrFields, f.ID, err)
+		}
+	}
+	return b, nil
+}
+
+// encodeValue appends one value in the encoding of its field
+func encodeValue(b []byte, f Field, v any) ([]byte, error) {
+	if f.Length == VariableLength {
+		var data []byte
+		switch v := v.(type) {
+		case string:
+			data = []byte(v)
+		case []byte:
+			data = v
+		default:
+			return b, fmt.Errorf("%T for a variable-length field", v)
+		}
+		if len(data) > maxLen-3 {
+			return b, fmt.Errorf("%d octets", len(data))
+		}
+		if len(data) < 255 {
+			b = append(b, byte(len(data)))
+		} else {
+			b = append(b, 255)
+			b = binary.BigEndian.AppendUint16(b, uint16(len(data)))
+		}
+		return append(b, data...), nil
+	}
+
+	var n uint64
+	switch v := v.(type) {
+	case uint8:
+		n = uint64(v)
+	case uint16:
+		n = uint64(v)
+	case uint32:
+		n = uint64(v)
+	case uint64:
+		n = v
+	case time.Time:
+		n = uint64(v.UnixMilli())
+	case net.IP:
+		ip := v.To4()
+		if f.Length == net.IPv6len {
+			ip = v.To16()
+		}
+		if len(ip) != int(f.Length) {
+			return b, fmt.Errorf("address %s in %d octets", v, f.Length)
+		}
+		return append(b, ip...), nil
+	default:
+		return b, fmt.Errorf("%T for a %d octet field", v, f.Length)
+	}
+	// Unsigned values may be sent in fewer octets than their type (reduced-size encoding)
+	if f.Length < 8 && n >= 1<<(8*f.Length) {
+		return b, fmt.Errorf("%d does not fit in %d octets", n, f.Length)
+	}
+	for i := int(f.Length) - 1; i >= 0; i-- {
+		b = append(b, byte(n>>(8*i)))
+	}
+	return b, nil
+}
+
+// Encoder builds the messages of one observation domain in one transport
+// session, numbering the data records it sends
+type Encoder struct {
+	Domain   uint32 // Observation domain ID
+	MaxSize  int    // Largest message built, 0 or above 65535 means 65535
+	sequence uint32 // Data records sent so far, modulo 2^32
+}
+
+// maxSize returns the largest message the encoder may build
+func (e *Encoder) maxSize() int {
+	if e.MaxSize <= 0 || e.MaxSize > maxLen {
+		return maxLen
+	}
+	return e.MaxSize
+}
+
+// Reset restarts the sequence numbers, as a new transport session does
+func (e *Encoder) Reset() { e.sequence = 0 }
+
+// header appends a message header with a zero length, set by finish
+func (e *Encoder) header(b []byte, now time.Time) []byte {
+	b = binary.BigEndian.AppendUint16(b, Version)
+	b = binary.BigEndian.AppendUint16(b, 0)
+	b = binary.BigEndian.AppendUint32(b, uint32(now.Unix()))
+	b = binary.BigEndian.AppendUint32(b, e.sequence)
+	return binary.BigEndian.AppendUint32(b, e.Domain)
+}
+
+// finish fills in the length of a message and of the set starting at set
+func finish(b []byte, set int) []byte {
+	binary.BigEndian.PutUint16(b[2:], uint16(len(b)))
+	binary.BigEndian.PutUint16(b[set+2:], uint16(len(b)-set))
+	return b
+}
+
+// Templates builds a message announcing the given templates
+func (e *Encoder) Templates(now time.Time, templates ...Template) ([]byte, error) {
+	b := e.header(make([]byte, 0, 512), now)
+	b = binary.BigEndian.AppendUint16(b, templateSetID)
+	b = binary.BigEndian.AppendUint16(b, 0)
+	for _, t := range templates {
+		if t.ID < minDataSetID {
+			return nil, ErrTemplateID
+		}
+		b = t.encode(b)
+	}
+	if len(b) > e.maxSize() {
+		return nil, ErrTooLarge
+	}
+	return finish(b, headerLen), nil
+}
+
+// Data builds the messages carrying the records of one template, as many
+// records per message as fit
+func (e *Encoder) Data(now time.Time, t Template, records []Record) ([][]byte, error) {
+	if t.ID < minDataSetID {
+		return nil, ErrTemplateID
+	}
+	var (
+		messages [][]byte
+		b        []byte
+		count    uint32 // Records in b
+		record   []byte
+	)
+	flush := func() {
+		if count > 0 {
+			messages = append(messages, finish(b, headerLen))
+			e.sequence += count
+		}
+		b, count = nil, 0
+	}
+	for _, r := range records {
+		var err error
+		if record, err = r.encode(record[:0], t); err != nil {
+			return nil, err
+		}
+		if headerLen+setHeaderLen+len(record) > e.maxSize() {
+			return nil, ErrTooLarge
+		}
+		if count > 0 && len(b)+len(record) > e.maxSize() {
+			flush()
+		}
+		if count == 0 {
+			b = e.header(make([]byte, 0, min(e.maxSize(), 1500)), now)
+			b = binary.BigEndian.AppendUint16(b, t.ID)
+			b = binary.BigEndian.AppendUint16(b, 0)
+		}
+		b = append(b, record...)
+		count++
+	}
+	flush()
+	return messages, nil
+}
@@ -0,0 +1,58 @@
+package pfcp
+
+import (
+	"net"
+	"time"
+)
+
+// Client sends PFCP requests to a single peer and waits for the matching
+// responses, retransmitting as needed. It answers Heartbeat Requests from the
+// peer, which makes it suitable as an SMF stand-in driving the UPF over N4.
+type Client struct {
+	transport *Transport
+	peer      *net.UDPAddr
+	recovery  time.Time // Start time, announced in Heartbeat Responses
+}
+
+// Dial creates a client sending to the PFCP peer at addr ("host:port")
+func Dial(addr string) (*Client, error) {
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{peer: raddr, recovery: time.Now()}
+	c.transport = NewTransport(conn, func(m *Message, from *net.UDPAddr) *Message {
+		if m.Type != MsgHeartbeatRequest {
+			return nil
+		}
+		return NewMessage(MsgHeartbeatResponse, 0, m.Sequence, NewRecoveryTimeStamp(c.recovery))
+	})
+	go c.transport.Serve()
+	return c, nil
+}
+
+// Transport returns the client's transport, to tune retransmission or inject faults
+func (c *Client) Transport() *Transport {
+	return c.transport
+}
+
+// Request assigns the next sequence number to m, sends it and returns the
+// response carrying the same sequence number
+func (c *Client) Request(m *Message) (*Message, error) {
+	return c.transport.Request(c.peer, m)
+}
+
+// LocalAddr returns the local address the client sends from
+func (c *Client) LocalAddr() net.Addr {
+	return c.transport.LocalAddr()
+}
+
+// Close releases the client's socket
+func (c *Client) Close() error {
+	return c.transport.Close()
+}
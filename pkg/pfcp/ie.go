@@ -0,0 +1,251 @@
+package pfcp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// IEType identifies a PFCP Information Element
+type IEType uint16
+
+// Information Element types used on N4
+const (
+	IECreatePDR                  IEType = 1
+	IEPDI                        IEType = 2
+	IECreateFAR                  IEType = 3
+	IEForwardingParameters       IEType = 4
+	IECreateURR                  IEType = 6
+	IECreateQER                  IEType = 7
+	IECreatedPDR                 IEType = 8
+	IEUpdatePDR                  IEType = 9
+	IEUpdateFAR                  IEType = 10
+	IEUpdateForwardingParameters IEType = 11
+	IEUpdateURR                  IEType = 13
+	IEUpdateQER                  IEType = 14
+	IERemovePDR                  IEType = 15
+	IERemoveFAR                  IEType = 16
+	IERemoveURR                  IEType = 17
+	IERemoveQER                  IEType = 18
+	IECause                      IEType = 19
+	IESourceInterface            IEType = 20
+	IEFTEID                      IEType = 21
+	IENetworkInstance            IEType = 22
+	IESDFFilter                  IEType = 23
+	IEGateStatus                 IEType = 25
+	IEMBR                        IEType = 26
+	IEGBR                        IEType = 27
+	IEPrecedence                 IEType = 29
+	IEVolumeThreshold            IEType = 31
+	IETimeThreshold              IEType = 32
+	IEReportingTriggers          IEType = 37
+	IEReportType                 IEType = 39
+	IEOffendingIE                IEType = 40
+	IEDestinationInterface       IEType = 42
+	IEUPFunctionFeatures         IEType = 43
+	IEApplyAction                IEType = 44
+	IEPDRID                      IEType = 56
+	IEFSEID                      IEType = 57
+	IENodeID                     IEType = 60
+	IEMeasurementMethod          IEType = 62
+	IEUsageReportTrigger         IEType = 63
+	IEMeasurementPeriod          IEType = 64
+	IEVolumeMeasurement          IEType = 66
+	IEDurationMeasurement        IEType = 67
+	IETimeOfFirstPacket          IEType = 69
+	IETimeOfLastPacket           IEType = 70
+	IEVolumeQuota                IEType = 73
+	IETimeQuota                  IEType = 74
+	IEStartTime                  IEType = 75
+	IEEndTime                    IEType = 76
+	IEUsageReportModification    IEType = 78
+	IEUsageReportDeletion        IEType = 79
+	IEUsageReportReport          IEType = 80
+	IEURRID                      IEType = 81
+	IEOuterHeaderCreation        IEType = 84
+	IEUEIPAddress                IEType = 93
+	IEOuterHeaderRemoval         IEType = 95
+	IERecoveryTimeStamp          IEType = 96
+	IEURSEQN                     IEType = 104
+	IEFARID                      IEType = 108
+	IEQERID                      IEType = 109
+	IEQFI                        IEType = 124
+	IEUserID                     IEType = 141
+	IEAPNDNN                     IEType = 159
+)
+
+// groupedIEs lists the IE types whose value is a sequence of IEs
+var groupedIEs = map[IEType]bool{
+	IECreatePDR:                  true,
+	IEPDI:                        true,
+	IECreateFAR:                  true,
+	IEForwardingParameters:       true,
+	IECreateURR:                  true,
+	IECreateQER:                  true,
+	IECreatedPDR:                 true,
+	IEUpdatePDR:                  true,
+	IEUpdateFAR:                  true,
+	IEUpdateForwardingParameters: true,
+	IEUpdateURR:                  true,
+	IEUpdateQER:                  true,
+	IERemovePDR:                  true,
+	IERemoveFAR:                  true,
+	IERemoveURR:                  true,
+	IERemoveQER:                  true,
+	IEUsageReportModification:    true,
+	IEUsageReportDeletion:        true,
+	IEUsageReportReport:          true,
+}
+
+// IsGrouped reports whether IEs of this type contain other IEs
+func (t IEType) IsGrouped() bool {
+	return groupedIEs[t]
+}
+
+// enterpriseBit marks vendor specific IE types, which carry an Enterprise ID
+const enterpriseBit = 0x8000
+
+// IE is a PFCP Information Element. Grouped IEs keep their decoded members in
+// Children; all other IEs keep their raw value in Payload.
+type IE struct {
+	Type         IEType // IE type
+	EnterpriseID uint16 // Enterprise ID of vendor specific IEs
+	Payload      []byte // Value of a non-grouped IE
+	Children     []*IE  // Members of a grouped IE
+}
+
+// NewIE builds a non-grouped IE from its raw value
+func NewIE(t IEType, payload []byte) *IE {
+	return &IE{Type: t, Payload: payload}
+}
+
+// NewGrouped builds a grouped IE from its members, skipping nil members
+func NewGrouped(t IEType, children ...*IE) *IE {
+	ie := &IE{Type: t}
+	for _, c := range children {
+		if c != nil {
+			ie.Children = append(ie.Children, c)
+		}
+	}
+	return ie
+}
+
+// IsGrouped reports whether the IE contains other IEs
+func (ie *IE) IsGrouped() bool {
+	return ie.Type.IsGrouped()
+}
+
+// value returns the encoded value of the IE
+func (ie *IE) value() ([]byte, error) {
+	if !ie.IsGrouped() {
+		return ie.Payload, nil
+	}
+	var v []byte
+	for _, c := range ie.Children {
+		b, err := c.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		v = append(v, b...)
+	}
+	return v, nil
+}
+
+// Marshal encodes the IE including its type and length
+func (ie *IE) Marshal() ([]byte, error) {
+	v, err := ie.value()
+	if err != nil {
+		return nil, err
+	}
+
+	hdr := 4
+	if ie.Type&enterpriseBit != 0 {
+		hdr += 2
+	}
+	if hdr-4+len(v) > 0xffff {
+		return nil, fmt.Errorf("pfcp: IE %d too long", ie.Type)
+	}
+
+	b := make([]byte, hdr, hdr+len(v))
+	binary.BigEndian.PutUint16(b[0:2], uint16(ie.Type))
+	binary.BigEndian.PutUint16(b[2:4], uint16(hdr-4+len(v)))
+	if hdr == 6 {
+		binary.BigEndian.PutUint16(b[4:6], ie.EnterpriseID)
+	}
+	return append(b, v...), nil
+}
+
+// parseIEs decodes a sequence of IEs
+func parseIEs(b []byte) ([]*IE, error) {
+	var ies []*IE
+	for len(b) > 0 {
+		if len(b) < 4 {
+			return nil, ErrIELength
+		}
+		ie := &IE{Type: IEType(binary.BigEndian.Uint16(b[0:2]))}
+		length := int(binary.BigEndian.Uint16(b[2:4]))
+		b = b[4:]
+		if length > len(b) {
+			return nil, ErrIELength
+		}
+		v := b[:length]
+		b = b[length:]
+
+		if ie.Type&enterpriseBit != 0 {
+			if len(v) < 2 {
+				return nil, ErrIELength
+			}
+			ie.EnterpriseID = binary.BigEndian.Uint16(v[0:2])
+			v = v[2:]
+		}
+
+		if ie.IsGrouped() {
+			children, err := parseIEs(v)
+			if err != nil {
+				return nil, fmt.Errorf("IE %d: %w", ie.Type, err)
+			}
+			ie.Children = children
+		} else {
+			ie.Payload = append([]byte(nil), v...)
+		}
+		ies = append(ies, ie)
+	}
+	return ies, nil
+}
+
+// find returns the first IE of the given type, or nil
+func find(ies []*IE, t IEType) *IE {
+	for _, ie := range ies {
+		if ie.Type == t {
+			return ie
+		}
+	}
+	return nil
+}
+
+// findAll returns every IE of the given type
+func findAll(ies []*IE, t IEType) []*IE {
+	var out []*IE
+	for _, ie := range ies {
+		if ie.Type == t {
+			out = append(out, ie)
+		}
+	}
+	return out
+}
+
+// Find returns the first member of a grouped IE with the given type, or nil.
+// It is safe to call on a nil IE, so lookups can be chained.
+func (ie *IE) Find(t IEType) *IE {
+	if ie == nil {
+		return nil
+	}
+	return find(ie.Children, t)
+}
+
+// FindAll returns every member of a grouped IE with the given type
+func (ie *IE) FindAll(t IEType) []*IE {
+	if ie == nil {
+		return nil
+	}
+	return findAll(ie.Children, t)
+}
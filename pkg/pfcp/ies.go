@@ -0,0 +1,646 @@
+package pfcp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrIEMissing is returned when reading a value from a nil IE
+var ErrIEMissing = errors.New("pfcp: IE missing")
+
+// check validates that ie is present with at least n octets of payload
+func (ie *IE) check(n int) error {
+	if ie == nil {
+		return ErrIEMissing
+	}
+	if len(ie.Payload) < n {
+		return fmt.Errorf("IE %d: %w", ie.Type, ErrIEContents)
+	}
+	return nil
+}
+
+// Cause is the value of the Cause IE
+type Cause uint8
+
+// Cause values
+const (
+	CauseRequestAccepted                Cause = 1
+	CauseRequestRejected                Cause = 64
+	CauseSessionContextNotFound         Cause = 65
+	CauseMandatoryIEMissing             Cause = 66
+	CauseConditionalIEMissing           Cause = 67
+	CauseInvalidLength                  Cause = 68
+	CauseMandatoryIEIncorrect           Cause = 69
+	CauseNoEstablishedAssociation       Cause = 72
+	CauseRuleCreationModificationFailed Cause = 73
+	CauseNoResourcesAvailable           Cause = 75
+	CauseServiceNotSupported            Cause = 76
+	CauseSystemFailure                  Cause = 77
+)
+
+// causeNames maps cause values to the names used in logs and metrics
+var causeNames = map[Cause]string{
+	CauseRequestAccepted:                "RequestAccepted",
+	CauseRequestRejected:                "RequestRejected",
+	CauseSessionContextNotFound:         "SessionContextNotFound",
+	CauseMandatoryIEMissing:             "MandatoryIEMissing",
+	CauseConditionalIEMissing:           "ConditionalIEMissing",
+	CauseInvalidLength:                  "InvalidLength",
+	CauseMandatoryIEIncorrect:           "MandatoryIEIncorrect",
+	CauseNoEstablishedAssociation:       "NoEstablishedAssociation",
+	CauseRuleCreationModificationFailed: "RuleCreationModificationFailed",
+	CauseNoResourcesAvailable:           "NoResourcesAvailable",
+	CauseServiceNotSupported:            "ServiceNotSupported",
+	CauseSystemFailure:                  "SystemFailure",
+}
+
+func (c Cause) String() string {
+	if name, ok := causeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Cause(%d)", uint8(c))
+}
+
+// NewCause builds a Cause IE
+func NewCause(c Cause) *IE {
+	return NewIE(IECause, []byte{byte(c)})
+}
+
+// Cause returns the value of a Cause IE
+func (ie *IE) Cause() (Cause, error) {
+	if err := ie.check(1); err != nil {
+		return 0, err
+	}
+	return Cause(ie.Payload[0]), nil
+}
+
+// Node ID types
+const (
+	NodeIDIPv4 = 0
+	NodeIDIPv6 = 1
+	NodeIDFQDN = 2
+)
+
+// NodeID identifies a PFCP entity by address or FQDN
+type NodeID struct {
+	Type uint8  // NodeIDIPv4, NodeIDIPv6 or NodeIDFQDN
+	IP   net.IP // Address for IP node IDs
+	FQDN string // Name for FQDN node IDs
+}
+
+func (n NodeID) String() string {
+	if n.Type == NodeIDFQDN {
+		return n.FQDN
+	}
+	return n.IP.String()
+}
+
+// NewNodeID builds a Node ID IE from an IP address or, if s is not an address, an FQDN
+func NewNodeID(s string) *IE {
+	if ip := net.ParseIP(s); ip != nil {
+		if v4 := ip.To4(); v4 != nil {
+			return NewIE(IENodeID, append([]byte{NodeIDIPv4}, v4...))
+		}
+		return NewIE(IENodeID, append([]byte{NodeIDIPv6}, ip.To16()...))
+	}
+	return NewIE(IENodeID, append([]byte{NodeIDFQDN}, encodeLabels(s)...))
+}
+
+// NodeID returns the value of a Node ID IE
+func (ie *IE) NodeID() (NodeID, error) {
+	if err := ie.check(1); err != nil {
+		return NodeID{}, err
+	}
+	n := NodeID{Type: ie.Payload[0] & 0x0f}
+	v := ie.Payload[1:]
+	switch n.Type {
+	case NodeIDIPv4:
+		if len(v) < 4 {
+			return NodeID{}, ErrIEContents
+		}
+		n.IP = net.IP(append([]byte(nil), v[:4]...))
+	case NodeIDIPv6:
+		if len(v) < 16 {
+			return NodeID{}, ErrIEContents
+		}
+		n.IP = net.IP(append([]byte(nil), v[:16]...))
+	case NodeIDFQDN:
+		n.FQDN = decodeLabels(v)
+	default:
+		return NodeID{}, ErrIEContents
+	}
+	return n, nil
+}
+
+// FSEID is the value of the F-SEID IE
+type FSEID struct {
+	SEID uint64 // Session Endpoint Identifier
+	IPv4 net.IP // IPv4 address of the endpoint, if any
+	IPv6 net.IP // IPv6 address of the endpoint, if any
+}
+
+// NewFSEID builds an F-SEID IE
+func NewFSEID(seid uint64, ip net.IP) *IE {
+	b := make([]byte, 9)
+	binary.BigEndian.PutUint64(b[1:], seid)
+	if v4 := ip.To4(); v4 != nil {
+		b[0] |= 0x02
+		b = append(b, v4...)
+	} else if ip != nil {
+		b[0] |= 0x01
+		b = append(b, ip.To16()...)
+	}
+	return NewIE(IEFSEID, b)
+}
+
+// FSEID returns the value of an F-SEID IE
+func (ie *IE) FSEID() (FSEID, error) {
+	if err := ie.check(9); err != nil {
+		return FSEID{}, err
+	}
+	f := FSEID{SEID: binary.BigEndian.Uint64(ie.Payload[1:9])}
+	v := ie.Payload[9:]
+	if ie.Payload[0]&0x02 != 0 {
+		if len(v) < 4 {
+			return FSEID{}, ErrIEContents
+		}
+		f.IPv4, v = net.IP(append([]byte(nil), v[:4]...)), v[4:]
+	}
+	if ie.Payload[0]&0x01 != 0 {
+		if len(v) < 16 {
+			return FSEID{}, ErrIEContents
+		}
+		f.IPv6 = net.IP(append([]byte(nil), v[:16]...))
+	}
+	return f, nil
+}
+
+// FTEID is the value of the F-TEID IE
+type FTEID struct {
+	TEID     uint32 // Tunnel Endpoint Identifier, unset when Choose is set
+	IPv4     net.IP // IPv4 address of the tunnel endpoint
+	IPv6     net.IP // IPv6 address of the tunnel endpoint
+	Choose   bool   // CH: the UP function allocates TEID and address
+	ChooseID uint8  // CHOOSE ID correlating allocations, valid if HasChooseID
+	HasID    bool   // CHID: ChooseID is present
+}
+
+// NewFTEID builds an F-TEID IE with a TEID and address chosen by the sender
+func NewFTEID(teid uint32, ip net.IP) *IE {
+	b := make([]byte, 5)
+	binary.BigEndian.PutUint32(b[1:], teid)
+	if v4 := ip.To4(); v4 != nil {
+		b[0] |= 0x01
+		b = append(b, v4...)
+	} else if ip != nil {
+		b[0] |= 0x02
+		b = append(b, ip.To16()...)
+	}
+	return NewIE(IEFTEID, b)
+}
+
+// NewFTEIDChoose builds an F-TEID IE asking the UP function to allocate the TEID
+func NewFTEIDChoose(v4 bool) *IE {
+	flags := byte(0x04)
+	if v4 {
+		flags |= 0x01
+	}
+	return NewIE(IEFTEID, []byte{flags})
+}
+
+// FTEID returns the value of an F-TEID IE
+func (ie *IE) FTEID() (FTEID, error) {
+	if err := ie.check(1); err != nil {
+		return FTEID{}, err
+	}
+	flags := ie.Payload[0]
+	f := FTEID{Choose: flags&0x04 != 0, HasID: flags&0x08 != 0}
+	v := ie.Payload[1:]
+	if f.Choose {
+		if f.HasID {
+			if len(v) < 1 {
+				return FTEID{}, ErrIEContents
+			}
+			f.ChooseID = v[0]
+		}
+		return f, nil
+	}
+	if len(v) < 4 {
+		return FTEID{}, ErrIEContents
+	}
+	f.TEID, v = binary.BigEndian.Uint32(v[:4]), v[4:]
+	if flags&0x01 != 0 {
+		if len(v) < 4 {
+			return FTEID{}, ErrIEContents
+		}
+		f.IPv4, v = net.IP(append([]byte(nil), v[:4]...)), v[4:]
+	}
+	if flags&0x02 != 0 {
+		if len(v) < 16 {
+			return FTEID{}, ErrIEContents
+		}
+		f.IPv6 = net.IP(append([]byte(nil), v[:16]...))
+	}
+	return f, nil
+}
+
+// UEIPAddress is the value of the UE IP Address IE
+type UEIPAddress struct {
+	IPv4        net.IP // IPv4 address of the UE
+	IPv6        net.IP // IPv6 address of the UE
+	Destination bool   // S/D: the address is a destination address (downlink PDI)
+}
+
+// NewUEIPAddress builds a UE IP Address IE
+func NewUEIPAddress(ip net.IP, destination bool) *IE {
+	b := []byte{0}
+	if destination {
+		b[0] |= 0x04
+	}
+	if v4 := ip.To4(); v4 != nil {
+		b[0] |= 0x02
+		b = append(b, v4...)
+	} else if ip != nil {
+		b[0] |= 0x01
+		b = append(b, ip.To16()...)
+	}
+	return NewIE(IEUEIPAddress, b)
+}
+
+// UEIPAddress returns the value of a UE IP Address IE
+func (ie *IE) UEIPAddress() (UEIPAddress, error) {
+	if err := ie.check(1); err != nil {
+		return UEIPAddress{}, err
+	}
+	flags := ie.Payload[0]
+	u := UEIPAddress{Destination: flags&0x04 != 0}
+	v := ie.Payload[1:]
+	if flags&0x02 != 0 {
+		if len(v) < 4 {
+			return UEIPAddress{}, ErrIEContents
+		}
+		u.IPv4, v = net.IP(append([]byte(nil), v[:4]...)), v[4:]
+	}
+	if flags&0x01 != 0 {
+		if len(v) < 16 {
+			return UEIPAddress{}, ErrIEContents
+		}
+		u.IPv6 = net.IP(append([]byte(nil), v[:16]...))
+	}
+	return u, nil
+}
+
+// Interface values of the Source and Destination Interface IEs
+const (
+	InterfaceAccess     = 0
+	InterfaceCore       = 1
+	InterfaceSGiLAN     = 2
+	InterfaceCPFunction = 3
+)
+
+// NewSourceInterface builds a Source Interface IE
+func NewSourceInterface(iface uint8) *IE {
+	return NewIE(IESourceInterface, []byte{iface & 0x0f})
+}
+
+// NewDestinationInterface builds a Destination Interface IE
+func NewDestinationInterface(iface uint8) *IE {
+	return NewIE(IEDestinationInterface, []byte{iface & 0x0f})
+}
+
+// Interface returns the value of a Source or Destination Interface IE
+func (ie *IE) Interface() (uint8, error) {
+	if err := ie.check(1); err != nil {
+		return 0, err
+	}
+	return ie.Payload[0] & 0x0f, nil
+}
+
+// NewNetworkInstance builds a Network Instance IE
+func NewNetworkInstance(name string) *IE {
+	return NewIE(IENetworkInstance, encodeLabels(name))
+}
+
+// NetworkInstance returns the value of a Network Instance IE
+func (ie *IE) NetworkInstance() (string, error) {
+	if err := ie.check(0); err != nil {
+		return "", err
+	}
+	return decodeLabels(ie.Payload), nil
+}
+
+// NewAPNDNN builds an APN/DNN IE
+func NewAPNDNN(dnn string) *IE {
+	return NewIE(IEAPNDNN, encodeLabels(dnn))
+}
+
+// APNDNN returns the value of an APN/DNN IE
+func (ie *IE) APNDNN() (string, error) {
+	if err := ie.check(0); err != nil {
+		return "", err
+	}
+	return decodeLabels(ie.Payload), nil
+}
+
+// Apply Action flags
+const (
+	ApplyDrop      = 0x01
+	ApplyForward   = 0x02
+	ApplyBuffer    = 0x04
+	ApplyNotifyCP  = 0x08
+	ApplyDuplicate = 0x10
+)
+
+// NewApplyAction builds an Apply Action IE
+func NewApplyAction(flags uint8) *IE {
+	return NewIE(IEApplyAction, []byte{flags})
+}
+
+// ApplyAction returns the flags of an Apply Action IE
+func (ie *IE) ApplyAction() (uint8, error) {
+	if err := ie.check(1); err != nil {
+		return 0, err
+	}
+	return ie.Payload[0], nil
+}
+
+// Outer Header Creation descriptions
+const (
+	OHCGTPUUDPIPv4 = 0x0100
+	OHCGTPUUDPIPv6 = 0x0200
+	OHCUDPIPv4     = 0x0400
+	OHCUDPIPv6     = 0x0800
+)
+
+// OuterHeaderCreation is the value of the Outer Header Creation IE
+type OuterHeaderCreation struct {
+	Description uint16 // OHC* flags
+	TEID        uint32 // TEID for GTP-U headers
+	IPv4        net.IP // Destination IPv4 address
+	IPv6        net.IP // Destination IPv6 address
+	Port        uint16 // Destination port for UDP headers
+}
+
+// NewOuterHeaderCreationGTPU builds an Outer Header Creation IE for a GTP-U/UDP/IPv4 tunnel
+func NewOuterHeaderCreationGTPU(teid uint32, ip net.IP) *IE {
+	b := make([]byte, 6, 10)
+	binary.BigEndian.PutUint16(b[0:2], OHCGTPUUDPIPv4)
+	binary.BigEndian.PutUint32(b[2:6], teid)
+	return NewIE(IEOuterHeaderCreation, append(b, ip.To4()...))
+}
+
+// OuterHeaderCreation returns the value of an Outer Header Creation IE
+func (ie *IE) OuterHeaderCreation() (OuterHeaderCreation, error) {
+	if err := ie.check(2); err != nil {
+		return OuterHeaderCreation{}, err
+	}
+	o := OuterHeaderCreation{Description: binary.BigEndian.Uint16(ie.Payload[0:2])}
+	v := ie.Payload[2:]
+	if o.Description&(OHCGTPUUDPIPv4|OHCGTPUUDPIPv6) != 0 {
+		if len(v) < 4 {
+			return OuterHeaderCreation{}, ErrIEContents
+		}
+		o.TEID, v = binary.BigEndian.Uint32(v[:4]), v[4:]
+	}
+	if o.Description&(OHCGTPUUDPIPv4|OHCUDPIPv4) != 0 {
+		if len(v) < 4 {
+			return OuterHeaderCreation{}, ErrIEContents
+		}
+		o.IPv4, v = net.IP(append([]byte(nil), v[:4]...)), v[4:]
+	}
+	if o.Description&(OHCGTPUUDPIPv6|OHCUDPIPv6) != 0 {
+		if len(v) < 16 {
+			return OuterHeaderCreation{}, ErrIEContents
+		}
+		o.IPv6, v = net.IP(append([]byte(nil), v[:16]...)), v[16:]
+	}
+	if o.Description&(OHCUDPIPv4|OHCUDPIPv6) != 0 {
+		if len(v) < 2 {
+			return OuterHeaderCreation{}, ErrIEContents
+		}
+		o.Port = binary.BigEndian.Uint16(v[:2])
+	}
+	return o, nil
+}
+
+// ntpEpochOffset is the number of seconds between 1900-01-01 and 1970-01-01
+const ntpEpochOffset = 2208988800
+
+// putTime encodes a time as 32-bit NTP seconds
+func putTime(t time.Time) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(t.Unix()+ntpEpochOffset))
+	return b
+}
+
+// getTime decodes 32-bit NTP seconds
+func getTime(b []byte) time.Time {
+	return time.Unix(int64(binary.BigEndian.Uint32(b))-ntpEpochOffset, 0)
+}
+
+// NewRecoveryTimeStamp builds a Recovery Time Stamp IE
+func NewRecoveryTimeStamp(t time.Time) *IE {
+	return NewIE(IERecoveryTimeStamp, putTime(t))
+}
+
+// RecoveryTimeStamp returns the value of a Recovery Time Stamp IE
+func (ie *IE) RecoveryTimeStamp() (time.Time, error) {
+	if err := ie.check(4); err != nil {
+		return time.Time{}, err
+	}
+	return getTime(ie.Payload), nil
+}
+
+// newUint builds an IE holding a big-endian unsigned integer of size octets
+func newUint(t IEType, v uint64, size int) *IE {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return NewIE(t, b[8-size:])
+}
+
+// uint reads a big-endian unsigned integer of size octets
+func (ie *IE) uint(size int) (uint64, error) {
+	if err := ie.check(size); err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range ie.Payload[:size] {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+// NewPDRID builds a PDR ID IE
+func NewPDRID(id uint16) *IE { return newUint(IEPDRID, uint64(id), 2) }
+
+// PDRID returns the value of a PDR ID IE
+func (ie *IE) PDRID() (uint16, error) {
+	v, err := ie.uint(2)
+	return uint16(v), err
+}
+
+// NewFARID builds a FAR ID IE
+func NewFARID(id uint32) *IE { return newUint(IEFARID, uint64(id), 4) }
+
+// NewQERID builds a QER ID IE
+func NewQERID(id uint32) *IE { return newUint(IEQERID, uint64(id), 4) }
+
+// NewURRID builds a URR ID IE
+func NewURRID(id uint32) *IE { return newUint(IEURRID, uint64(id), 4) }
+
+// NewPrecedence builds a Precedence IE
+func NewPrecedence(p uint32) *IE { return newUint(IEPrecedence, uint64(p), 4) }
+
+// Uint32 returns the value of a 4-octet IE such as FAR ID, QER ID, URR ID or Precedence
+func (ie *IE) Uint32() (uint32, error) {
+	v, err := ie.uint(4)
+	return uint32(v), err
+}
+
+// NewQFI builds a QFI IE
+func NewQFI(qfi uint8) *IE { return NewIE(IEQFI, []byte{qfi & 0x3f}) }
+
+// QFI returns the value of a QFI IE
+func (ie *IE) QFI() (uint8, error) {
+	v, err := ie.uint(1)
+	return uint8(v) & 0x3f, err
+}
+
+// BitRate is the value of the MBR and GBR IEs, in kilobits per second
+type BitRate struct {
+	UL uint64 // Uplink rate in kbps
+	DL uint64 // Downlink rate in kbps
+}
+
+// newBitRate encodes two 40-bit rates
+func newBitRate(t IEType, r BitRate) *IE {
+	b := make([]byte, 10)
+	for i := 0; i < 5; i++ {
+		b[i] = byte(r.UL >> (8 * (4 - i)))
+		b[5+i] = byte(r.DL >> (8 * (4 - i)))
+	}
+	return NewIE(t, b)
+}
+
+// NewMBR builds an MBR IE
+func NewMBR(r BitRate) *IE { return newBitRate(IEMBR, r) }
+
+// NewGBR builds a GBR IE
+func NewGBR(r BitRate) *IE { return newBitRate(IEGBR, r) }
+
+// BitRate returns the value of an MBR or GBR IE
+func (ie *IE) BitRate() (BitRate, error) {
+	if err := ie.check(10); err != nil {
+		return BitRate{}, err
+	}
+	var r BitRate
+	for i := 0; i < 5; i++ {
+		r.UL = r.UL<<8 | uint64(ie.Payload[i])
+		r.DL = r.DL<<8 | uint64(ie.Payload[5+i])
+	}
+	return r, nil
+}
+
+// NewUPFunctionFeatures builds a UP Function Features IE from its raw octets
+func NewUPFunctionFeatures(features ...byte) *IE {
+	return NewIE(IEUPFunctionFeatures, features)
+}
+
+// NewUserIDIMSI builds a User ID IE carrying an IMSI of up to 15 digits
+func NewUserIDIMSI(imsi string) (*IE, error) {
+	if len(imsi) == 0 || len(imsi) > 15 {
+		return nil, fmt.Errorf("pfcp: IMSI %q must have 1 to 15 digits", imsi)
+	}
+	tbcd, err := encodeTBCD(imsi)
+	if err != nil {
+		return nil, fmt.Errorf("pfcp: IMSI %q: %w", imsi, err)
+	}
+	return NewIE(IEUserID, append([]byte{0x01, byte(len(tbcd))}, tbcd...)), nil
+}
+
+// UserIDIMSI returns the IMSI of a User ID IE, or "" if it carries none
+func (ie *IE) UserIDIMSI() (string, error) {
+	if err := ie.check(1); err != nil {
+		return "", err
+	}
+	if ie.Payload[0]&0x01 == 0 {
+		return "", nil
+	}
+	v := ie.Payload[1:]
+	if len(v) < 1 || len(v) < 1+int(v[0]) {
+		return "", ErrIEContents
+	}
+	return decodeTBCD(v[1 : 1+int(v[0])])
+}
+
+// encodeTBCD packs a digit string two digits per octet, low nibble first,
+// with a filler nibble after an odd number of digits
+func encodeTBCD(digits string) ([]byte, error) {
+	b := make([]byte, (len(digits)+1)/2)
+	for i := 0; i < len(digits); i++ {
+		d := digits[i]
+		if d < '0' || d > '9' {
+			return nil, fmt.Errorf("%q is not a digit", d)
+		}
+		if i%2 == 0 {
+			b[i/2] = 0xf0 | (d - '0')
+		} else {
+			b[i/2] = b[i/2]&0x0f | (d-'0')<<4
+		}
+	}
+	return b, nil
+}
+
+// decodeTBCD unpacks a TBCD digit string. Only the last nibble may be the
+// filler; any other nibble above 9 is an error.
+func decodeTBCD(b []byte) (string, error) {
+	var sb strings.Builder
+	for i, c := range b {
+		for j, d := range []byte{c & 0x0f, c >> 4} {
+			if d == 0x0f && j == 1 && i == len(b)-1 {
+				break
+			}
+			if d > 9 {
+				return "", ErrIEContents
+			}
+			sb.WriteByte('0' + d)
+		}
+	}
+	return sb.String(), nil
+}
+
+// encodeLabels encodes a dotted name as length-prefixed labels (RFC 1035 without the root)
+func encodeLabels(name string) []byte {
+	var b []byte
+	for _, label := range strings.Split(name, ".") {
+		if label == "" {
+			continue
+		}
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return b
+}
+
+// decodeLabels decodes length-prefixed labels into a dotted name. Values that
+// are not valid label sequences are returned as plain text, which is how some
+// control planes encode the Network Instance.
+func decodeLabels(b []byte) string {
+	var labels []string
+	for i := 0; i < len(b); {
+		n := int(b[i])
+		if n == 0 || i+1+n > len(b) {
+			return string(b)
+		}
+		labels = append(labels, string(b[i+1:i+1+n]))
+		i += 1 + n
+	}
+	return strings.Join(labels, ".")
+}
+
+// NewOffendingIE builds an Offending IE naming the IE that caused a rejection
+func NewOffendingIE(t IEType) *IE { return newUint(IEOffendingIE, uint64(t), 2) }
@@ -0,0 +1,295 @@
+/*
+Package pfcp implements the PFCP (Packet Forwarding Control Protocol, 3GPP TS 29.244)
+wire format used on the N4 interface between the SMF and the UPF. It encodes and
+decodes message headers and Information Elements, leaving message semantics to
+the agents that use it.
+*/
+package pfcp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Version is the PFCP protocol version carried in every header
+const Version = 1
+
+// Port is the well-known UDP port of PFCP
+const Port = 8805
+
+// MessageType identifies a PFCP message
+type MessageType uint8
+
+// Node and session related message types
+const (
+	MsgHeartbeatRequest               MessageType = 1
+	MsgHeartbeatResponse              MessageType = 2
+	MsgPFDManagementRequest           MessageType = 3
+	MsgPFDManagementResponse          MessageType = 4
+	MsgAssociationSetupRequest        MessageType = 5
+	MsgAssociationSetupResponse       MessageType = 6
+	MsgAssociationUpdateRequest       MessageType = 7
+	MsgAssociationUpdateResponse      MessageType = 8
+	MsgAssociationReleaseRequest      MessageType = 9
+	MsgAssociationReleaseResponse     MessageType = 10
+	MsgVersionNotSupportedResponse    MessageType = 11
+	MsgNodeReportRequest              MessageType = 12
+	MsgNodeReportResponse             MessageType = 13
+	MsgSessionSetDeletionRequest      MessageType = 14
+	MsgSessionSetDeletionResponse     MessageType = 15
+	MsgSessionSetModificationRequest  MessageType = 16
+	MsgSessionSetModificationResponse MessageType = 17
+	MsgSessionEstablishmentRequest    MessageType = 50
+	MsgSessionEstablishmentResponse   MessageType = 51
+	MsgSessionModificationRequest     MessageType = 52
+	MsgSessionModificationResponse    MessageType = 53
+	MsgSessionDeletionRequest         MessageType = 54
+	MsgSessionDeletionResponse        MessageType = 55
+	MsgSessionReportRequest           MessageType = 56
+	MsgSessionReportResponse          MessageType = 57
+)
+
+// messageNames maps message types to the names used in logs
+var messageNames = map[MessageType]string{
+	MsgHeartbeatRequest:               "HeartbeatRequest",
+	MsgHeartbeatResponse:              "HeartbeatResponse",
+	MsgPFDManagementRequest:           "PFDManagementRequest",
+	MsgPFDManagementResponse:          "PFDManagementResponse",
+	MsgAssociationSetupRequest:        "AssociationSetupRequest",
+	MsgAssociationSetupResponse:       "AssociationSetupResponse",
+	MsgAssociationUpdateRequest:       "AssociationUpdateRequest",
+	MsgAssociationUpdateResponse:      "AssociationUpdateResponse",
+	MsgAssociationReleaseRequest:      "AssociationReleaseRequest",
+	MsgAssociationReleaseResponse:     "AssociationReleaseResponse",
+	MsgVersionNotSupportedResponse:    "VersionNotSupportedResponse",
+	MsgNodeReportRequest:              "NodeReportRequest",
+	MsgNodeReportResponse:             "NodeReportResponse",
+	MsgSessionSetDeletionRequest:      "SessionSetDeletionRequest",
+	MsgSessionSetDeletionResponse:     "SessionSetDeletionResponse",
+	MsgSessionSetModificationRequest:  "SessionSetModificationRequest",
+	MsgSessionSetModificationResponse: "SessionSetModificationResponse",
+	MsgSessionEstablishmentRequest:    "SessionEstablishmentRequest",
+	MsgSessionEstablishmentResponse:   "SessionEstablishmentResponse",
+	MsgSessionModificationRequest:     "SessionModificationRequest",
+	MsgSessionModificationResponse:    "SessionModificationResponse",
+	MsgSessionDeletionRequest:         "SessionDeletionRequest",
+	MsgSessionDeletionResponse:        "SessionDeletionResponse",
+	MsgSessionReportRequest:           "SessionReportRequest",
+	MsgSessionReportResponse:          "SessionReportResponse",
+}
+
+func (t MessageType) String() string {
+	if name, ok := messageNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("MessageType(%d)", uint8(t))
+}
+
+// responseTypes maps every request type to the type of its response. The
+// numbering does not follow a single parity rule: node related requests up to
+// Association Release are odd, later ones (Node Report, Session Set Deletion
+// and Modification) even, and session related requests are even too.
+var responseTypes = map[MessageType]MessageType{
+	MsgHeartbeatRequest:              MsgHeartbeatResponse,
+	MsgPFDManagementRequest:          MsgPFDManagementResponse,
+	MsgAssociationSetupRequest:       MsgAssociationSetupResponse,
+	MsgAssociationUpdateRequest:      MsgAssociationUpdateResponse,
+	MsgAssociationReleaseRequest:     MsgAssociationReleaseResponse,
+	MsgNodeReportRequest:             MsgNodeReportResponse,
+	MsgSessionSetDeletionRequest:     MsgSessionSetDeletionResponse,
+	MsgSessionSetModificationRequest: MsgSessionSetModificationResponse,
+	MsgSessionEstablishmentRequest:   MsgSessionEstablishmentResponse,
+	MsgSessionModificationRequest:    MsgSessionModificationResponse,
+	MsgSessionDeletionRequest:        MsgSessionDeletionResponse,
+	MsgSessionReportRequest:          MsgSessionReportResponse,
+}
+
+// IsRequest reports whether the message type is a request
+func (t MessageType) IsRequest() bool {
+	_, ok := responseTypes[t]
+	return ok
+}
+
+// ResponseType returns the type of the response to a request of type t, or 0
+// if t is not a request
+func (t MessageType) ResponseType() MessageType {
+	return responseTypes[t]
+}
+
+// HasSEID reports whether messages of this type carry a SEID in their header.
+// Session related messages (type 50 and above) do, node related messages do not.
+func (t MessageType) HasSEID() bool {
+	return t >= MsgSessionEstablishmentRequest
+}
+
+// Header is the PFCP message header
+type Header struct {
+	Type     MessageType // Message type
+	SEID     uint64      // Session Endpoint Identifier, for session messages only
+	Sequence uint32      // 24-bit sequence number matching requests and responses
+	Priority uint8       // Message priority (0-15), sent when MP is set
+	MP       bool        // Whether Priority is present
+}
+
+// Message is a PFCP header followed by its Information Elements
+type Message struct {
+	Header
+	IEs []*IE // Top level Information Elements in wire order
+}
+
+// Header flag bits of the first octet
+const (
+	flagS  = 0x01 // SEID present
+	flagMP = 0x02 // Message priority present
+	flagFO = 0x04 // Follow on: another message follows in the same datagram
+)
+
+// Decoding errors
+var (
+	ErrShort      = errors.New("pfcp: message too short")
+	ErrVersion    = errors.New("pfcp: unsupported version")
+	ErrLength     = errors.New("pfcp: length field does not match data")
+	ErrSequence   = errors.New("pfcp: sequence number exceeds 24 bits")
+	ErrIELength   = errors.New("pfcp: IE length exceeds data")
+	ErrIEContents = errors.New("pfcp: malformed IE contents")
+)
+
+// NewMessage builds a message of the given type
+func NewMessage(t MessageType, seid uint64, seq uint32, ies ...*IE) *Message {
+	return &Message{Header: Header{Type: t, SEID: seid, Sequence: seq}, IEs: ies}
+}
+
+// Marshal encodes the message into its wire format
+func (m *Message) Marshal() ([]byte, error) {
+	if m.Sequence > 0xffffff {
+		return nil, ErrSequence
+	}
+
+	var body []byte
+	for _, ie := range m.IEs {
+		b, err := ie.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, b...)
+	}
+
+	flags := byte(Version << 5)
+	hdrLen := 4 + 4 // mandatory part plus sequence number and spare/priority
+	if m.Type.HasSEID() {
+		flags |= flagS
+		hdrLen += 8
+	}
+	if m.MP {
+		flags |= flagMP
+	}
+
+	b := make([]byte, hdrLen, hdrLen+len(body))
+	b[0] = flags
+	b[1] = byte(m.Type)
+	binary.BigEndian.PutUint16(b[2:4], uint16(hdrLen-4+len(body)))
+	off := 4
+	if m.Type.HasSEID() {
+		binary.BigEndian.PutUint64(b[4:12], m.SEID)
+		off = 12
+	}
+	b[off] = byte(m.Sequence >> 16)
+	b[off+1] = byte(m.Sequence >> 8)
+	b[off+2] = byte(m.Sequence)
+	if m.MP {
+		b[off+3] = m.Priority << 4
+	}
+	return append(b, body...), nil
+}
+
+// Parse decodes a single PFCP message. Trailing data after the message, as
+// announced by the FO flag, is ignored; use ParseAll to decode every message.
+func Parse(b []byte) (*Message, error) {
+	m, _, err := parseOne(b)
+	return m, err
+}
+
+// ParseAll decodes every message of a datagram that uses the FO flag
+func ParseAll(b []byte) ([]*Message, error) {
+	var msgs []*Message
+	for len(b) > 0 {
+		m, n, err := parseOne(b)
+		if err != nil {
+			return msgs, err
+		}
+		msgs = append(msgs, m)
+		if b[0]&flagFO == 0 {
+			break
+		}
+		b = b[n:]
+	}
+	return msgs, nil
+}
+
+// parseOne decodes the message at the start of b and returns its total length
+func parseOne(b []byte) (*Message, int, error) {
+	if len(b) < 8 {
+		return nil, 0, ErrShort
+	}
+	if b[0]>>5 != Version {
+		return nil, 0, ErrVersion
+	}
+
+	length := int(binary.BigEndian.Uint16(b[2:4])) + 4
+	if length > len(b) {
+		return nil, 0, ErrLength
+	}
+
+	m := &Message{Header: Header{Type: MessageType(b[1])}}
+	off := 4
+	if b[0]&flagS != 0 {
+		if length < 16 {
+			return nil, 0, ErrShort
+		}
+		m.SEID = binary.BigEndian.Uint64(b[4:12])
+		off = 12
+	} else if length < 8 {
+		return nil, 0, ErrShort
+	}
+	m.Sequence = uint32(b[off])<<16 | uint32(b[off+1])<<8 | uint32(b[off+2])
+	if b[0]&flagMP != 0 {
+		m.MP = true
+		m.Priority = b[off+3] >> 4
+	}
+
+	ies, err := parseIEs(b[off+4 : length])
+	if err != nil {
+		return nil, 0, err
+	}
+	m.IEs = ies
+	return m, length, nil
+}
+
+// VersionNotSupported returns the Version Not Supported Response to a request
+// whose header carries a version other than Version, or nil if b does not
+// start with one. Only the type and sequence number of such a header are read.
+func VersionNotSupported(b []byte) *Message {
+	if len(b) < 8 || b[0]>>5 == Version || !MessageType(b[1]).IsRequest() {
+		return nil
+	}
+	off := 4
+	if b[0]&flagS != 0 {
+		if len(b) < 16 {
+			return nil
+		}
+		off = 12
+	}
+	seq := uint32(b[off])<<16 | uint32(b[off+1])<<8 | uint32(b[off+2])
+	return NewMessage(MsgVersionNotSupportedResponse, 0, seq)
+}
+
+// Find returns the first top level IE of the given type, or nil
+func (m *Message) Find(t IEType) *IE {
+	return find(m.IEs, t)
+}
+
+// FindAll returns every top level IE of the given type
+func (m *Message) FindAll(t IEType) []*IE {
+	return findAll(m.IEs, t)
+}
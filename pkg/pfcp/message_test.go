@@ -0,0 +1,428 @@
+package pfcp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// n4Exchange holds the messages of an N4 exchange between an SMF (Node ID
+// 10.100.200.3) and a UPF (10.100.200.101) in their wire format: heartbeat,
+// association setup and the establishment, modification and deletion of a
+// session with an uplink and a downlink PDR.
+var n4Exchange = []struct {
+	name  string
+	hex   string
+	check func(t *testing.T, m *Message)
+}{
+	{"HeartbeatRequest", "2001000c0000010000600004e98c25a0", func(t *testing.T, m *Message) {
+		wantTime(t, m.Find(IERecoveryTimeStamp), recoveryTime)
+	}},
+	{"HeartbeatResponse", "2002000c0000010000600004e98c25a0", func(t *testing.T, m *Message) {
+		wantTime(t, m.Find(IERecoveryTimeStamp), recoveryTime)
+	}},
+	{"AssociationSetupRequest", "2005001500000200003c0005000a64c80300600004e98c25a0", func(t *testing.T, m *Message) {
+		wantNodeID(t, m, "10.100.200.3")
+		wantTime(t, m.Find(IERecoveryTimeStamp), recoveryTime)
+	}},
+	{"AssociationSetupResponse", "2006002000000200003c0005000a64c865001300010100600004e98c25a0002b00020100", func(t *testing.T, m *Message) {
+		wantNodeID(t, m, "10.100.200.101")
+		wantCause(t, m, CauseRequestAccepted)
+	}},
+	{"SessionEstablishmentRequest", "2132014e000000000000000000000300003c0005000a64c8030039000d0200000000000000010a64c80300010047003800020001001d0004000000ff00020020001400010000150001050016000908696e7465726e6574005d0005020a2d0002005f000100006c000400000001006d0004000000010001003d003800020002001d0004000000ff0002001b00140001010016000908696e7465726e6574005d0005060a2d0002006c000400000002006d00040000000100030023006c000400000001002c00010200040012002a0001010016000908696e7465726e65740003000d006c000400000002002c00010400070020006d0004000000010019000100001a000a00000186a00000030d40007c000109000600210051000400000001003e00010300250003000000001f00090100000000000f4240008d000a010800010100000000f1009f000908696e7465726e6574", func(t *testing.T, m *Message) {
+		wantNodeID(t, m, "10.100.200.3")
+		if f, err := m.Find(IEFSEID).FSEID(); err != nil || f.SEID != 1 || !f.IPv4.Equal(net.IPv4(10, 100, 200, 3)) {
+			t.Errorf("F-SEID %+v (%v)", f, err)
+		}
+		pdrs := m.FindAll(IECreatePDR)
+		if len(pdrs) != 2 {
+			t.Fatalf("%d Create PDR IEs, want 2", len(pdrs))
+		}
+		if f, err := pdrs[0].Find(IEPDI).Find(IEFTEID).FTEID(); err != nil || !f.Choose {
+			t.Errorf("uplink PDI F-TEID %+v (%v), want CHOOSE", f, err)
+		}
+		if ue, err := pdrs[1].Find(IEPDI).Find(IEUEIPAddress).UEIPAddress(); err != nil || !ue.IPv4.Equal(net.IPv4(10, 45, 0, 2)) {
+			t.Errorf("downlink PDI UE IP %+v (%v)", ue, err)
+		}
+		if r, err := m.Find(IECreateQER).Find(IEMBR).BitRate(); err != nil || r != (BitRate{UL: 100000, DL: 200000}) {
+			t.Errorf("MBR %+v (%v)", r, err)
+		}
+		if v, err := m.Find(IECreateURR).Find(IEVolumeThreshold).Volume(); err != nil || v.Total != 1000000 {
+			t.Errorf("volume threshold %+v (%v)", v, err)
+		}
+		if imsi, err := m.Find(IEUserID).UserIDIMSI(); err != nil || imsi != "001010000000001" {
+			t.Errorf("IMSI %q (%v)", imsi, err)
+		}
+		if dnn, err := m.Find(IEAPNDNN).APNDNN(); err != nil || dnn != "internet" {
+			t.Errorf("DNN %q (%v)", dnn, err)
+		}
+	}},
+	{"SessionEstablishmentResponse", "21330042000000000000000100000300003c0005000a64c86500130001010039000d0200000000000000010a64c865000800130038000200010015000901000000010a64c865", func(t *testing.T, m *Message) {
+		wantCause(t, m, CauseRequestAccepted)
+		created := m.Find(IECreatedPDR)
+		if f, err := created.Find(IEFTEID).FTEID(); err != nil || f.TEID != 1 || !f.IPv4.Equal(net.IPv4(10, 100, 200, 101)) {
+			t.Errorf("created F-TEID %+v (%v)", f, err)
+		}
+	}},
+	{"SessionModificationRequest", "21340041000000000000000100000400000a0031006c000400000002002c000102000b0020002a0001000016000908696e7465726e65740054000a0100000000010a64c801", func(t *testing.T, m *Message) {
+		params := m.Find(IEUpdateFAR).Find(IEUpdateForwardingParameters)
+		if o, err := params.Find(IEOuterHeaderCreation).OuterHeaderCreation(); err != nil || o.TEID != 1 || !o.IPv4.Equal(net.IPv4(10, 100, 200, 1)) {
+			t.Errorf("outer header creation %+v (%v)", o, err)
+		}
+	}},
+	{"SessionModificationResponse", "213500110000000000000001000004000013000101", func(t *testing.T, m *Message) {
+		wantCause(t, m, CauseRequestAccepted)
+	}},
+	{"SessionDeletionRequest", "2136000c000000000000000100000500", func(t *testing.T, m *Message) {
+		if len(m.IEs) != 0 {
+			t.Errorf("%d IEs, want none", len(m.IEs))
+		}
+	}},
+	{"SessionDeletionResponse", "2137002c0000000000000001000005000013000101004f001700510004000000000068000400000000003f0003000000", func(t *testing.T, m *Message) {
+		wantCause(t, m, CauseRequestAccepted)
+		if _, err := m.Find(IEUsageReportDeletion).UsageReport(); err != nil {
+			t.Errorf("usage report: %v", err)
+		}
+	}},
+}
+
+// recoveryTime is the recovery time stamp announced in n4Exchange
+var recoveryTime = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func wantTime(t *testing.T, ie *IE, want time.Time) {
+	t.Helper()
+	if got, err := ie.RecoveryTimeStamp(); err != nil || !got.Equal(want) {
+		t.Errorf("time stamp %s (%v), want %s", got, err, want)
+	}
+}
+
+func wantNodeID(t *testing.T, m *Message, want string) {
+	t.Helper()
+	if id, err := m.Find(IENodeID).NodeID(); err != nil || id.String() != want {
+		t.Errorf("Node ID %v (%v), want %s", id, err, want)
+	}
+}
+
+func wantCause(t *testing.T, m *Message, want Cause) {
+	t.Helper()
+	if c, err := m.Find(IECause).Cause(); err != nil || c != want {
+		t.Errorf("cause %v (%v), want %s", c, err, want)
+	}
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	for i, tc := range n4Exchange {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tc.hex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := Parse(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Type.String() != tc.name {
+				t.Fatalf("type %s, want %s", m.Type, tc.name)
+			}
+			if want := uint32(i/2 + 1); m.Sequence != want {
+				t.Errorf("sequence %d, want %d", m.Sequence, want)
+			}
+			if m.Type.HasSEID() && m.SEID != 1 && m.Type != MsgSessionEstablishmentRequest {
+				t.Errorf("SEID %#x, want 1", m.SEID)
+			}
+			tc.check(t, m)
+
+			out, err := m.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out, b) {
+				t.Errorf("re-encoded as\n%x\nwant\n%x", out, b)
+			}
+		})
+	}
+}
+
+// establishmentVector is a Session Establishment Request and its Response
+// assembled octet by octet from TS 29.244 rather than by this encoder. They
+// carry encodings the encoder never produces: a header with message priority,
+// an F-TEID with CHOOSE ID, a FAR that buffers with a BAR, a User ID with an
+// IMSI and an MSISDN, IEs the codec has no constants for (Create BAR, PDN
+// Type, S-NSSAI) and a vendor specific IE.
+var establishmentVector = struct {
+	request, response string
+}{
+	request:  "2332017f000000000000000000004f30003c0005000a0a00020039000d0200000000000000a10a0a00020001004c003800020001001d0004000000ff0002001d0014000100001500020d050016000908696e7465726e6574007c000109005f000100006c0004000000010051000400000001006d00040000000100010045003800020002001d0004000000ff0002001b00140001010016000908696e7465726e6574005d0005060a3c0003006c0004000000020051000400000001006d00040000000100030023006c000400000001002c00010200040012002a0001010016000908696e7465726e657400030012006c000400000002002c00010c00580001010006001b0051000400000001003e000103002500020100004000040000003c00070020006d0004000000010019000100001a000a00000186a00000030d40007c0001090055000500580001010071000101008d0011050802980300000000f3063316325476f8009f000908696e7465726e657401010004010000018001000448f90102",
+	response: "2133004200000000000000a100004f00003c0005000a0a000300130001010039000d020000000000000b010a0a00030008001300380002000100150009010000002a0a0a0003",
+}
+
+func TestEstablishmentVector(t *testing.T) {
+	b, _ := hex.DecodeString(establishmentVector.request)
+	req, err := Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Type != MsgSessionEstablishmentRequest || req.Sequence != 0x4f || !req.MP || req.Priority != 3 {
+		t.Errorf("header %+v", req.Header)
+	}
+	wantNodeID(t, req, "10.10.0.2")
+	if f, err := req.Find(IEFSEID).FSEID(); err != nil || f.SEID != 0xa1 || !f.IPv4.Equal(net.IPv4(10, 10, 0, 2)) {
+		t.Errorf("F-SEID %+v (%v)", f, err)
+	}
+	pdrs := req.FindAll(IECreatePDR)
+	if len(pdrs) != 2 {
+		t.Fatalf("%d Create PDR IEs, want 2", len(pdrs))
+	}
+	if f, err := pdrs[0].Find(IEPDI).Find(IEFTEID).FTEID(); err != nil || !f.Choose || !f.HasID || f.ChooseID != 5 {
+		t.Errorf("uplink F-TEID %+v (%v), want CHOOSE with ID 5", f, err)
+	}
+	if ni, err := pdrs[0].Find(IEPDI).Find(IENetworkInstance).NetworkInstance(); err != nil || ni != "internet" {
+		t.Errorf("network instance %q (%v)", ni, err)
+	}
+	if ue, err := pdrs[1].Find(IEPDI).Find(IEUEIPAddress).UEIPAddress(); err != nil || !ue.Destination || !ue.IPv4.Equal(net.IPv4(10, 60, 0, 3)) {
+		t.Errorf("downlink UE IP %+v (%v)", ue, err)
+	}
+	fars := req.FindAll(IECreateFAR)
+	if len(fars) != 2 {
+		t.Fatalf("%d Create FAR IEs, want 2", len(fars))
+	}
+	if a, err := fars[1].Find(IEApplyAction).ApplyAction(); err != nil || a != ApplyBuffer|ApplyNotifyCP {
+		t.Errorf("apply action %#x (%v), want BUFF and NOCP", a, err)
+	}
+	if r, err := req.Find(IECreateQER).Find(IEMBR).BitRate(); err != nil || r != (BitRate{UL: 100000, DL: 200000}) {
+		t.Errorf("MBR %+v (%v)", r, err)
+	}
+	if imsi, err := req.Find(IEUserID).UserIDIMSI(); err != nil || imsi != "208930000000003" {
+		t.Errorf("IMSI %q (%v)", imsi, err)
+	}
+	if dnn, err := req.Find(IEAPNDNN).APNDNN(); err != nil || dnn != "internet" {
+		t.Errorf("DNN %q (%v)", dnn, err)
+	}
+	if v := req.Find(0x8001); v == nil || v.EnterpriseID != 18681 || !bytes.Equal(v.Payload, []byte{1, 2}) {
+		t.Errorf("vendor specific IE %+v", v)
+	}
+	if out, err := req.Marshal(); err != nil || !bytes.Equal(out, b) {
+		t.Errorf("request re-encoded as\n%x (%v)\nwant\n%x", out, err, b)
+	}
+
+	b, _ = hex.DecodeString(establishmentVector.response)
+	resp, err := Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Type != MsgSessionEstablishmentResponse || resp.SEID != 0xa1 || resp.Sequence != req.Sequence {
+		t.Errorf("header %+v, want the request's sequence and CP SEID", resp.Header)
+	}
+	wantCause(t, resp, CauseRequestAccepted)
+	if f, err := resp.Find(IEFSEID).FSEID(); err != nil || f.SEID != 0xb01 {
+		t.Errorf("UP F-SEID %+v (%v)", f, err)
+	}
+	if f, err := resp.Find(IECreatedPDR).Find(IEFTEID).FTEID(); err != nil || f.TEID != 0x2a || !f.IPv4.Equal(net.IPv4(10, 10, 0, 3)) {
+		t.Errorf("created F-TEID %+v (%v)", f, err)
+	}
+	if out, err := resp.Marshal(); err != nil || !bytes.Equal(out, b) {
+		t.Errorf("response re-encoded as\n%x (%v)\nwant\n%x", out, err, b)
+	}
+}
+
+func TestUserIDIMSI(t *testing.T) {
+	for _, tc := range []struct {
+		imsi string
+		hex  string // Value of the User ID IE, empty if rejected
+	}{
+		{"001010000000001", "010800010100000000f1"},
+		{"0010100000001", "0107000101000000f1"},
+		{"2089300000000031", ""}, // 16 digits
+		{"", ""},
+		{"00101000000000a", ""},
+		{"0010 0000000001", ""},
+	} {
+		ie, err := NewUserIDIMSI(tc.imsi)
+		if tc.hex == "" {
+			if err == nil {
+				t.Errorf("%q: encoded as %x, want an error", tc.imsi, ie.Payload)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.imsi, err)
+			continue
+		}
+		if got := hex.EncodeToString(ie.Payload); got != tc.hex {
+			t.Errorf("%q: encoded as %s, want %s", tc.imsi, got, tc.hex)
+		}
+		if imsi, err := ie.UserIDIMSI(); err != nil || imsi != tc.imsi {
+			t.Errorf("%q: decoded as %q (%v)", tc.imsi, imsi, err)
+		}
+	}
+
+	// A nibble above 9 is only allowed as the filler of the last octet
+	for _, v := range []string{"0102f110", "01021a10", "0102f1f1"} {
+		b, _ := hex.DecodeString(v)
+		if imsi, err := NewIE(IEUserID, b).UserIDIMSI(); err == nil {
+			t.Errorf("%s: decoded as %q, want an error", v, imsi)
+		}
+	}
+}
+
+func TestParseAllFollowOn(t *testing.T) {
+	req, _ := hex.DecodeString(n4Exchange[0].hex)
+	resp, _ := hex.DecodeString(n4Exchange[2].hex)
+	datagram := append(append([]byte(nil), req...), resp...)
+	datagram[0] |= flagFO
+
+	msgs, err := ParseAll(datagram)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 || msgs[0].Type != MsgHeartbeatRequest || msgs[1].Type != MsgAssociationSetupRequest {
+		t.Fatalf("decoded %v", msgs)
+	}
+	if m, err := Parse(datagram); err != nil || m.Type != MsgHeartbeatRequest {
+		t.Fatalf("Parse of a follow-on datagram: %v, %v", m, err)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		hex  string
+		want error
+	}{
+		{"short header", "200100", ErrShort},
+		{"version 2", "4001000c0000010000600004e98c25a0", ErrVersion},
+		{"length beyond data", "2001000d0000010000600004e98c25a0", ErrLength},
+		{"session header without SEID", "213600080000000500000000", ErrShort},
+		{"IE beyond data", "2001000c0000010000600005e98c25a0", ErrIELength},
+	} {
+		b, _ := hex.DecodeString(tc.hex)
+		if _, err := Parse(b); err != tc.want {
+			t.Errorf("%s: %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestMessageTypeIsRequest(t *testing.T) {
+	requests := map[MessageType]MessageType{
+		1: 2, 3: 4, 5: 6, 7: 8, 9: 10, 12: 13, 14: 15, 16: 17,
+		50: 51, 52: 53, 54: 55, 56: 57,
+	}
+	for typ := MessageType(1); typ <= 57; typ++ {
+		resp, want := requests[typ]
+		if got := typ.IsRequest(); got != want {
+			t.Errorf("%s.IsRequest() = %v, want %v", typ, got, want)
+		}
+		if got := typ.ResponseType(); got != resp {
+			t.Errorf("%s.ResponseType() = %s, want %s", typ, got, resp)
+		}
+	}
+}
+
+// decodeAll runs every decoder on the IE and its members. Decoders must
+// return an error, not panic, on IEs of the wrong type or length.
+func decodeAll(ie *IE) {
+	ie.Cause()
+	ie.NodeID()
+	ie.FSEID()
+	ie.FTEID()
+	ie.UEIPAddress()
+	ie.Interface()
+	ie.NetworkInstance()
+	ie.APNDNN()
+	ie.ApplyAction()
+	ie.OuterHeaderCreation()
+	ie.RecoveryTimeStamp()
+	ie.PDRID()
+	ie.Uint32()
+	ie.QFI()
+	ie.BitRate()
+	ie.UserIDIMSI()
+	ie.SDFFilter()
+	ie.OuterHeaderRemoval()
+	ie.GateStatus()
+	ie.MeasurementMethod()
+	ie.Flags()
+	ie.ReportType()
+	ie.Volume()
+	ie.Seconds()
+	ie.Time()
+	ie.UsageReport()
+	for _, c := range ie.Children {
+		decodeAll(c)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, tc := range n4Exchange {
+		b, _ := hex.DecodeString(tc.hex)
+		f.Add(b)
+	}
+	for _, v := range []string{establishmentVector.request, establishmentVector.response} {
+		b, _ := hex.DecodeString(v)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		m, err := Parse(b)
+		if err != nil {
+			return
+		}
+		for _, ie := range m.IEs {
+			decodeAll(ie)
+		}
+
+		// Whatever decodes must encode, and encode the same once normalized
+		out, err := m.Marshal()
+		if err != nil {
+			t.Fatalf("Marshal of a parsed message: %v", err)
+		}
+		again, err := Parse(out)
+		if err != nil {
+			t.Fatalf("Parse of a marshalled message: %v", err)
+		}
+		out2, err := again.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out, out2) {
+			t.Fatalf("encoding not stable:\n%x\n%x", out, out2)
+		}
+	})
+}
+
+func FuzzDecodeIE(f *testing.F) {
+	for _, tc := range n4Exchange {
+		b, _ := hex.DecodeString(tc.hex)
+		if m, err := Parse(b); err == nil {
+			for _, ie := range m.IEs {
+				if raw, err := ie.Marshal(); err == nil {
+					f.Add(raw)
+				}
+			}
+		}
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		ies, err := parseIEs(b)
+		if err != nil {
+			return
+		}
+		for _, ie := range ies {
+			decodeAll(ie)
+
+			// A decodable usage report survives re-encoding
+			if ie.Type != IEUsageReportDeletion {
+				continue
+			}
+			r, err := ie.UsageReport()
+			if err != nil {
+				continue
+			}
+			again, err := NewUsageReport(ie.Type, r).UsageReport()
+			if err != nil || !reflect.DeepEqual(again, r) {
+				t.Fatalf("usage report %+v re-decoded as %+v (%v)", r, again, err)
+			}
+		}
+	})
+}
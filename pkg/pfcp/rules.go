@@ -0,0 +1,175 @@
+package pfcp
+
+import "encoding/binary"
+
+// SDF Filter flags
+const (
+	SDFFlowDescription = 0x01 // FD: Flow Description present
+	SDFToSTrafficClass = 0x02 // TTC: ToS Traffic Class present
+	SDFSecurityParam   = 0x04 // SPI: Security Parameter Index present
+	SDFFlowLabel       = 0x08 // FL: Flow Label present
+	SDFFilterID        = 0x10 // BID: SDF Filter ID present
+)
+
+// SDFFilter is the value of the SDF Filter IE. Flags tells which of the
+// optional fields are present.
+type SDFFilter struct {
+	Flags           uint8  // SDF* flags
+	FlowDescription string // IPFilterRule, e.g. "permit out ip from any to assigned"
+	ToSTrafficClass uint16 // Type of Service / Traffic Class and its mask
+	SecurityParam   uint32 // IPsec Security Parameter Index
+	FlowLabel       uint32 // 20-bit IPv6 flow label
+	FilterID        uint32 // SDF Filter ID
+}
+
+// NewSDFFilter builds an SDF Filter IE, encoding the fields selected by f.Flags
+func NewSDFFilter(f SDFFilter) *IE {
+	b := []byte{f.Flags, 0}
+	if f.Flags&SDFFlowDescription != 0 {
+		b = binary.BigEndian.AppendUint16(b, uint16(len(f.FlowDescription)))
+		b = append(b, f.FlowDescription...)
+	}
+	if f.Flags&SDFToSTrafficClass != 0 {
+		b = binary.BigEndian.AppendUint16(b, f.ToSTrafficClass)
+	}
+	if f.Flags&SDFSecurityParam != 0 {
+		b = binary.BigEndian.AppendUint32(b, f.SecurityParam)
+	}
+	if f.Flags&SDFFlowLabel != 0 {
+		b = append(b, byte(f.FlowLabel>>16), byte(f.FlowLabel>>8), byte(f.FlowLabel))
+	}
+	if f.Flags&SDFFilterID != 0 {
+		b = binary.BigEndian.AppendUint32(b, f.FilterID)
+	}
+	return NewIE(IESDFFilter, b)
+}
+
+// SDFFilter returns the value of an SDF Filter IE
+func (ie *IE) SDFFilter() (SDFFilter, error) {
+	if err := ie.check(2); err != nil {
+		return SDFFilter{}, err
+	}
+	f := SDFFilter{Flags: ie.Payload[0]}
+	v := ie.Payload[2:]
+	take := func(n int) ([]byte, bool) {
+		if len(v) < n {
+			return nil, false
+		}
+		out := v[:n]
+		v = v[n:]
+		return out, true
+	}
+
+	if f.Flags&SDFFlowDescription != 0 {
+		l, ok := take(2)
+		if !ok {
+			return SDFFilter{}, ErrIEContents
+		}
+		d, ok := take(int(binary.BigEndian.Uint16(l)))
+		if !ok {
+			return SDFFilter{}, ErrIEContents
+		}
+		f.FlowDescription = string(d)
+	}
+	if f.Flags&SDFToSTrafficClass != 0 {
+		d, ok := take(2)
+		if !ok {
+			return SDFFilter{}, ErrIEContents
+		}
+		f.ToSTrafficClass = binary.BigEndian.Uint16(d)
+	}
+	if f.Flags&SDFSecurityParam != 0 {
+		d, ok := take(4)
+		if !ok {
+			return SDFFilter{}, ErrIEContents
+		}
+		f.SecurityParam = binary.BigEndian.Uint32(d)
+	}
+	if f.Flags&SDFFlowLabel != 0 {
+		d, ok := take(3)
+		if !ok {
+			return SDFFilter{}, ErrIEContents
+		}
+		f.FlowLabel = uint32(d[0]&0x0f)<<16 | uint32(d[1])<<8 | uint32(d[2])
+	}
+	if f.Flags&SDFFilterID != 0 {
+		d, ok := take(4)
+		if !ok {
+			return SDFFilter{}, ErrIEContents
+		}
+		f.FilterID = binary.BigEndian.Uint32(d)
+	}
+	return f, nil
+}
+
+// Outer Header Removal descriptions
+const (
+	OHRGTPUUDPIPv4 = 0
+	OHRGTPUUDPIPv6 = 1
+	OHRUDPIPv4     = 2
+	OHRUDPIPv6     = 3
+	OHRIPv4        = 4
+	OHRIPv6        = 5
+	OHRGTPUUDPIP   = 6
+)
+
+// OuterHeaderRemoval is the value of the Outer Header Removal IE
+type OuterHeaderRemoval struct {
+	Description     uint8 // OHR* value
+	ExtensionDelete uint8 // GTP-U extension header deletion flags, if HasExtension
+	HasExtension    bool
+}
+
+// NewOuterHeaderRemoval builds an Outer Header Removal IE
+func NewOuterHeaderRemoval(description uint8) *IE {
+	return NewIE(IEOuterHeaderRemoval, []byte{description})
+}
+
+// OuterHeaderRemoval returns the value of an Outer Header Removal IE
+func (ie *IE) OuterHeaderRemoval() (OuterHeaderRemoval, error) {
+	if err := ie.check(1); err != nil {
+		return OuterHeaderRemoval{}, err
+	}
+	o := OuterHeaderRemoval{Description: ie.Payload[0]}
+	if len(ie.Payload) > 1 {
+		o.ExtensionDelete, o.HasExtension = ie.Payload[1], true
+	}
+	return o, nil
+}
+
+// Gate states of the Gate Status IE
+const (
+	GateOpen   = 0
+	GateClosed = 1
+)
+
+// GateStatus is the value of the Gate Status IE
+type GateStatus struct {
+	UL uint8 // Uplink gate, GateOpen or GateClosed
+	DL uint8 // Downlink gate, GateOpen or GateClosed
+}
+
+// NewGateStatus builds a Gate Status IE
+func NewGateStatus(g GateStatus) *IE {
+	return NewIE(IEGateStatus, []byte{(g.UL&0x03)<<2 | g.DL&0x03})
+}
+
+// GateStatus returns the value of a Gate Status IE
+func (ie *IE) GateStatus() (GateStatus, error) {
+	if err := ie.check(1); err != nil {
+		return GateStatus{}, err
+	}
+	return GateStatus{UL: ie.Payload[0] >> 2 & 0x03, DL: ie.Payload[0] & 0x03}, nil
+}
+
+// NewRemovePDR builds a Remove PDR IE
+func NewRemovePDR(id uint16) *IE { return NewGrouped(IERemovePDR, NewPDRID(id)) }
+
+// NewRemoveFAR builds a Remove FAR IE
+func NewRemoveFAR(id uint32) *IE { return NewGrouped(IERemoveFAR, NewFARID(id)) }
+
+// NewRemoveQER builds a Remove QER IE
+func NewRemoveQER(id uint32) *IE { return NewGrouped(IERemoveQER, NewQERID(id)) }
+
+// NewRemoveURR builds a Remove URR IE
+func NewRemoveURR(id uint32) *IE { return NewGrouped(IERemoveURR, NewURRID(id)) }
@@ -0,0 +1,316 @@
+package pfcp
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Transport errors
+var (
+	ErrTimeout = errors.New("pfcp: request timed out")
+	ErrClosed  = errors.New("pfcp: transport closed")
+)
+
+// Default retransmission settings, matching the UPF configuration defaults
+const (
+	DefaultRetries = 5
+	DefaultTimeout = 2 * time.Second
+)
+
+// Handler answers a request received from a peer. Returning nil sends no response.
+type Handler func(m *Message, from *net.UDPAddr) *Message
+
+// Injector decides the fate of every datagram the transport sends: it may
+// drop it or hold it back for a while, which also reorders it relative to
+// later datagrams. It exists to exercise retransmission on loopback.
+type Injector func(b []byte, to *net.UDPAddr) (drop bool, delay time.Duration)
+
+// Faults builds an Injector that drops datagrams with probability loss,
+// delays every datagram by delay and holds back datagrams with probability
+// reorder by a further reorderDelay. The same seed gives the same faults.
+func Faults(seed int64, loss, reorder float64, delay, reorderDelay time.Duration) Injector {
+	var mu sync.Mutex
+	rng := rand.New(rand.NewSource(seed))
+	return func(b []byte, to *net.UDPAddr) (bool, time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		if rng.Float64() < loss {
+			return true, 0
+		}
+		if rng.Float64() < reorder {
+			return false, delay + reorderDelay
+		}
+		return false, delay
+	}
+}
+
+// TransportStats counts what a transport did to deliver messages
+type TransportStats struct {
+	Requests        uint64 // Requests sent, first attempts only
+	Retransmissions uint64 // Request retransmissions
+	Timeouts        uint64 // Requests abandoned after the last retry
+	Duplicates      uint64 // Peer requests answered from the response cache
+	Dropped         uint64 // Datagrams dropped by the injector
+}
+
+// requestKey identifies a request by its peer and sequence number
+type requestKey struct {
+	peer string
+	seq  uint32
+}
+
+// pendingRequest is a request waiting for its response
+type pendingRequest struct {
+	response MessageType   // Type the response must have
+	ch       chan *Message // Receives the response
+}
+
+// cachedResponse is an encoded response kept to answer retransmitted requests
+type cachedResponse struct {
+	key     requestKey
+	data    []byte
+	expires time.Time
+}
+
+// Transport carries PFCP over a UDP socket. Requests it sends are matched to
+// responses by peer, sequence number and response type and retransmitted
+// until answered; requests it
+// receives are passed to a Handler, and their responses are cached so that a
+// retransmitted request is answered again without being handled twice.
+type Transport struct {
+	Retries int           // Retransmissions before a request times out
+	Timeout time.Duration // Wait for a response before retransmitting
+	Inject  Injector      // Optional fault injection for outgoing datagrams
+
+	// OnMalformed, if set, is called for datagrams that fail to decode
+	OnMalformed func(from *net.UDPAddr, err error)
+
+	conn    *net.UDPConn
+	handler Handler
+	closed  chan struct{}
+	once    sync.Once
+
+	seq     atomic.Uint32
+	mu      sync.Mutex
+	pending map[requestKey]*pendingRequest // Outstanding requests
+	cache   map[requestKey]*cachedResponse
+	order   []*cachedResponse // Cache entries in expiry order
+
+	requests, retransmissions, timeouts, duplicates, dropped atomic.Uint64
+}
+
+// NewTransport creates a transport on conn that passes peer requests to handler
+func NewTransport(conn *net.UDPConn, handler Handler) *Transport {
+	return &Transport{
+		Retries: DefaultRetries,
+		Timeout: DefaultTimeout,
+		conn:    conn,
+		handler: handler,
+		closed:  make(chan struct{}),
+		pending: make(map[requestKey]*pendingRequest),
+		cache:   make(map[requestKey]*cachedResponse),
+	}
+}
+
+// LocalAddr returns the address the transport receives on
+func (t *Transport) LocalAddr() *net.UDPAddr {
+	return t.conn.LocalAddr().(*net.UDPAddr)
+}
+
+// Stats returns the transport counters
+func (t *Transport) Stats() TransportStats {
+	return TransportStats{
+		Requests:        t.requests.Load(),
+		Retransmissions: t.retransmissions.Load(),
+		Timeouts:        t.timeouts.Load(),
+		Duplicates:      t.duplicates.Load(),
+		Dropped:         t.dropped.Load(),
+	}
+}
+
+// Close stops the transport and fails outstanding requests
+func (t *Transport) Close() error {
+	t.once.Do(func() { close(t.closed) })
+	return t.conn.Close()
+}
+
+// send writes a datagram, subject to the injector
+func (t *Transport) send(b []byte, to *net.UDPAddr) error {
+	if t.Inject != nil {
+		drop, delay := t.Inject(b, to)
+		if drop {
+			t.dropped.Add(1)
+			return nil
+		}
+		if delay > 0 {
+			time.AfterFunc(delay, func() { t.conn.WriteToUDP(b, to) })
+			return nil
+		}
+	}
+	_, err := t.conn.WriteToUDP(b, to)
+	return err
+}
+
+// nextSequence returns the next 24-bit sequence number. 0 is skipped when
+// the counter wraps.
+func (t *Transport) nextSequence() uint32 {
+	for {
+		if seq := t.seq.Add(1) & 0xffffff; seq != 0 {
+			return seq
+		}
+	}
+}
+
+// Request sends m to a peer under a fresh sequence number and waits for the
+// response, retransmitting every Timeout up to Retries times. Only a message
+// of the response type paired with m's type is accepted as the response.
+func (t *Transport) Request(to *net.UDPAddr, m *Message) (*Message, error) {
+	if !m.Type.IsRequest() {
+		return nil, fmt.Errorf("pfcp: %s is not a request", m.Type)
+	}
+	m.Sequence = t.nextSequence()
+	b, err := m.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	key := requestKey{peer: to.String(), seq: m.Sequence}
+	ch := make(chan *Message, 1)
+	t.mu.Lock()
+	t.pending[key] = &pendingRequest{response: m.Type.ResponseType(), ch: ch}
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, key)
+		t.mu.Unlock()
+	}()
+
+	t.requests.Add(1)
+	timer := time.NewTimer(t.Timeout)
+	defer timer.Stop()
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			t.retransmissions.Add(1)
+		}
+		if err := t.send(b, to); err != nil {
+			return nil, err
+		}
+
+		select {
+		case resp := <-ch:
+			return resp, nil
+		case <-t.closed:
+			return nil, ErrClosed
+		case <-timer.C:
+		}
+		if attempt >= t.Retries {
+			t.timeouts.Add(1)
+			return nil, ErrTimeout
+		}
+		timer.Reset(t.Timeout)
+	}
+}
+
+// Serve reads datagrams until the transport is closed, delivering responses
+// to waiting requests and passing requests to the handler
+func (t *Transport) Serve() error {
+	buf := make([]byte, 65535)
+	for {
+		n, from, err := t.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-t.closed:
+				return ErrClosed
+			default:
+				return err
+			}
+		}
+
+		msgs, err := ParseAll(buf[:n])
+		if errors.Is(err, ErrVersion) && len(msgs) == 0 {
+			// TS 29.244 answers a request of another version with a
+			// Version Not Supported Response
+			if resp := VersionNotSupported(buf[:n]); resp != nil {
+				if b, err := resp.Marshal(); err == nil {
+					t.send(b, from)
+				}
+			}
+		}
+		if err != nil && t.OnMalformed != nil {
+			t.OnMalformed(from, err)
+		}
+		for _, m := range msgs {
+			key := requestKey{peer: from.String(), seq: m.Sequence}
+			if !m.Type.IsRequest() {
+				t.mu.Lock()
+				p, ok := t.pending[key]
+				t.mu.Unlock()
+				if ok && p.response == m.Type {
+					select {
+					case p.ch <- m:
+					default: // a duplicate response; the first one already won
+					}
+				}
+				continue
+			}
+			t.serveRequest(key, m, from)
+		}
+	}
+}
+
+// serveRequest answers a peer request, from the cache if it was seen before
+func (t *Transport) serveRequest(key requestKey, m *Message, from *net.UDPAddr) {
+	now := time.Now()
+	t.mu.Lock()
+	t.expireLocked(now)
+	cached, ok := t.cache[key]
+	t.mu.Unlock()
+	if ok {
+		t.duplicates.Add(1)
+		t.send(cached.data, from)
+		return
+	}
+
+	if t.handler == nil {
+		return
+	}
+	resp := t.handler(m, from)
+	if resp == nil {
+		return
+	}
+	resp.Sequence = m.Sequence
+	b, err := resp.Marshal()
+	if err != nil {
+		return
+	}
+
+	// Keep the response while the peer may still be retransmitting
+	entry := &cachedResponse{key: key, data: b, expires: now.Add(time.Duration(t.Retries+1) * t.Timeout)}
+	t.mu.Lock()
+	t.cache[key] = entry
+	t.order = append(t.order, entry)
+	t.mu.Unlock()
+	t.send(b, from)
+}
+
+// expireLocked drops cached responses whose lifetime has passed. Callers hold t.mu.
+func (t *Transport) expireLocked(now time.Time) {
+	i := 0
+	for ; i < len(t.order) && now.After(t.order[i].expires); i++ {
+		if t.cache[t.order[i].key] == t.order[i] {
+			delete(t.cache, t.order[i].key)
+		}
+	}
+	t.order = t.order[i:]
+}
+
+// String formats the counters for logs
+func (s TransportStats) String() string {
+	return fmt.Sprintf("requests=%d retransmissions=%d timeouts=%d duplicates=%d dropped=%d",
+		s.Requests, s.Retransmissions, s.Timeouts, s.Duplicates, s.Dropped)
+}
@@ -0,0 +1,264 @@
+package pfcp
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// loopback returns a transport serving handler on a loopback port. setup
+// runs before the transport starts serving.
+func loopback(t *testing.T, handler Handler, setup ...func(*Transport)) *Transport {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := NewTransport(conn, handler)
+	for _, fn := range setup {
+		fn(tr)
+	}
+	go tr.Serve()
+	t.Cleanup(func() { tr.Close() })
+	return tr
+}
+
+// heartbeats answers Heartbeat Requests and counts the requests it handled
+func heartbeats(handled *atomic.Int32) Handler {
+	return func(m *Message, from *net.UDPAddr) *Message {
+		handled.Add(1)
+		return NewMessage(MsgHeartbeatResponse, 0, m.Sequence, NewRecoveryTimeStamp(time.Now()))
+	}
+}
+
+// dropFirst builds an Injector that drops the first n datagrams
+func dropFirst(n int32) Injector {
+	var sent atomic.Int32
+	return func(b []byte, to *net.UDPAddr) (bool, time.Duration) {
+		return sent.Add(1) <= n, 0
+	}
+}
+
+func TestTransportRetransmits(t *testing.T) {
+	var handled atomic.Int32
+	server := loopback(t, heartbeats(&handled))
+	client := loopback(t, nil)
+	client.Retries, client.Timeout = 3, 20*time.Millisecond
+
+	// Two lost requests are retransmitted and the third attempt is answered
+	client.Inject = dropFirst(2)
+	if _, err := client.Request(server.LocalAddr(), NewMessage(MsgHeartbeatRequest, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if s := client.Stats(); s.Requests != 1 || s.Retransmissions != 2 || s.Dropped != 2 || s.Timeouts != 0 {
+		t.Fatalf("client stats %s", s)
+	}
+	if n := handled.Load(); n != 1 {
+		t.Fatalf("server handled %d requests, want 1", n)
+	}
+
+	// With every datagram lost the request gives up after max_req_retries
+	client.Inject = dropFirst(1 << 30)
+	start := time.Now()
+	_, err := client.Request(server.LocalAddr(), NewMessage(MsgHeartbeatRequest, 0, 0))
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("request without answer: %v, want %v", err, ErrTimeout)
+	}
+	if s := client.Stats(); s.Requests != 2 || s.Retransmissions != 2+3 || s.Dropped != 2+4 || s.Timeouts != 1 {
+		t.Fatalf("client stats %s", s)
+	}
+	if elapsed, want := time.Since(start), 4*client.Timeout; elapsed < want {
+		t.Fatalf("timed out after %s, want at least (retries+1) × resp_timeout = %s", elapsed, want)
+	}
+}
+
+func TestTransportTimeout(t *testing.T) {
+	var handled atomic.Int32
+	silent := loopback(t, func(m *Message, from *net.UDPAddr) *Message {
+		handled.Add(1)
+		return nil
+	})
+	client := loopback(t, nil)
+	client.Retries, client.Timeout = 2, 30*time.Millisecond
+
+	start := time.Now()
+	_, err := client.Request(silent.LocalAddr(), NewMessage(MsgHeartbeatRequest, 0, 0))
+	elapsed := time.Since(start)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("unanswered request: %v, want %v", err, ErrTimeout)
+	}
+	if want := 3 * client.Timeout; elapsed < want || elapsed > want+time.Second {
+		t.Fatalf("timed out after %s, want about %s", elapsed, want)
+	}
+	// Unanswered requests are not cached, so every attempt reaches the handler
+	if n := handled.Load(); n != 3 {
+		t.Fatalf("handler saw %d attempts, want 3", n)
+	}
+}
+
+func TestTransportDuplicateCache(t *testing.T) {
+	var handled atomic.Int32
+	// Lost responses make the client retransmit; the server answers the
+	// retransmissions from its cache instead of handling them again
+	server := loopback(t, heartbeats(&handled), func(tr *Transport) { tr.Inject = dropFirst(2) })
+	client := loopback(t, nil)
+	client.Retries, client.Timeout = 5, 20*time.Millisecond
+	if _, err := client.Request(server.LocalAddr(), NewMessage(MsgHeartbeatRequest, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if n := handled.Load(); n != 1 {
+		t.Fatalf("server handled %d requests, want 1", n)
+	}
+	if s := server.Stats(); s.Duplicates != 2 {
+		t.Fatalf("server stats %s, want 2 duplicates", s)
+	}
+}
+
+func TestTransportLossAndReorder(t *testing.T) {
+	seen := make(map[uint32]int)
+	var mu sync.Mutex
+	server := loopback(t, func(m *Message, from *net.UDPAddr) *Message {
+		mu.Lock()
+		seen[m.Sequence]++
+		mu.Unlock()
+		return NewMessage(MsgSessionDeletionResponse, m.SEID, m.Sequence, NewCause(CauseRequestAccepted))
+	}, func(tr *Transport) { tr.Inject = Faults(2, 0.2, 0.3, 0, 25*time.Millisecond) })
+	client := loopback(t, nil)
+	client.Retries, client.Timeout = 20, 15*time.Millisecond
+	client.Inject = Faults(1, 0.2, 0.3, 0, 25*time.Millisecond)
+
+	const requests = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(seid uint64) {
+			defer wg.Done()
+			resp, err := client.Request(server.LocalAddr(), NewMessage(MsgSessionDeletionRequest, seid, 0))
+			if err == nil && resp.SEID != seid {
+				err = errors.New("response of another session")
+			}
+			errs <- err
+		}(uint64(i + 1))
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != requests {
+		t.Fatalf("server handled %d distinct requests, want %d", len(seen), requests)
+	}
+	for seq, n := range seen {
+		if n != 1 {
+			t.Fatalf("request %d handled %d times, want once", seq, n)
+		}
+	}
+	if s := client.Stats(); s.Retransmissions == 0 || s.Dropped == 0 {
+		t.Fatalf("client stats %s, want losses and retransmissions", s)
+	}
+}
+
+func TestTransportSequenceSkipsZero(t *testing.T) {
+	client := loopback(t, nil)
+	client.seq.Store(0xfffffe)
+	for _, want := range []uint32{0xffffff, 1, 2} {
+		if got := client.nextSequence(); got != want {
+			t.Fatalf("sequence %#x, want %#x", got, want)
+		}
+	}
+}
+
+func TestTransportMatchesResponseType(t *testing.T) {
+	var handled atomic.Int32
+	wrong := loopback(t, func(m *Message, from *net.UDPAddr) *Message {
+		handled.Add(1)
+		return NewMessage(MsgAssociationSetupResponse, 0, m.Sequence, NewCause(CauseRequestAccepted))
+	})
+	client := loopback(t, nil)
+	client.Retries, client.Timeout = 1, 20*time.Millisecond
+
+	// A response of another type with the same sequence number is ignored
+	if _, err := client.Request(wrong.LocalAddr(), NewMessage(MsgHeartbeatRequest, 0, 0)); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("request answered with the wrong type: %v, want %v", err, ErrTimeout)
+	}
+	if n := handled.Load(); n != 1 {
+		t.Fatalf("server handled %d requests, want 1", n)
+	}
+	if _, err := client.Request(wrong.LocalAddr(), NewMessage(MsgAssociationSetupRequest, 0, 0)); err != nil {
+		t.Fatalf("request answered with its response type: %v", err)
+	}
+	if _, err := client.Request(wrong.LocalAddr(), NewMessage(MsgHeartbeatResponse, 0, 0)); err == nil {
+		t.Fatal("a response was sent as a request")
+	}
+}
+
+// TestTransportVersionNotSupported checks that requests of another PFCP
+// version are answered with a Version Not Supported Response carrying their
+// sequence number, and are not handled
+func TestTransportVersionNotSupported(t *testing.T) {
+	var handled, malformed atomic.Int32
+	server := loopback(t, heartbeats(&handled), func(tr *Transport) {
+		tr.OnMalformed = func(*net.UDPAddr, error) { malformed.Add(1) }
+	})
+	conn, err := net.DialUDP("udp", nil, server.LocalAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for _, tc := range []struct {
+		name string
+		m    *Message
+		seq  uint32
+	}{
+		{"node request", NewMessage(MsgHeartbeatRequest, 0, 0x123456), 0x123456},
+		{"session request", NewMessage(MsgSessionEstablishmentRequest, 0x1, 0x42), 0x42},
+	} {
+		b, err := tc.m.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b[0] = b[0]&0x1f | 2<<5
+		if _, err := conn.Write(b); err != nil {
+			t.Fatal(err)
+		}
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		buf := make([]byte, 1500)
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		resp, err := Parse(buf[:n])
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if resp.Type != MsgVersionNotSupportedResponse || resp.Sequence != tc.seq || len(resp.IEs) != 0 {
+			t.Errorf("%s: answered %s with sequence %#x, want %s with %#x",
+				tc.name, resp.Type, resp.Sequence, MsgVersionNotSupportedResponse, tc.seq)
+		}
+	}
+
+	// Responses of another version are dropped without an answer
+	b, _ := NewMessage(MsgHeartbeatResponse, 0, 7).Marshal()
+	b[0] = b[0]&0x1f | 2<<5
+	conn.Write(b)
+	conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if n, err := conn.Read(make([]byte, 1500)); err == nil {
+		t.Errorf("answered a response of another version with %d bytes", n)
+	}
+	if n := handled.Load(); n != 0 {
+		t.Errorf("handled %d requests of another version", n)
+	}
+	if n := malformed.Load(); n != 3 {
+		t.Errorf("%d datagrams reported malformed, want 3", n)
+	}
+}
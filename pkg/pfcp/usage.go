@@ -0,0 +1,344 @@
+package pfcp
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// Measurement Method flags
+const (
+	MeasureDuration = 0x01 // DURAT
+	MeasureVolume   = 0x02 // VOLUM
+	MeasureEvent    = 0x04 // EVENT
+)
+
+// NewMeasurementMethod builds a Measurement Method IE
+func NewMeasurementMethod(flags uint8) *IE {
+	return NewIE(IEMeasurementMethod, []byte{flags})
+}
+
+// MeasurementMethod returns the flags of a Measurement Method IE
+func (ie *IE) MeasurementMethod() (uint8, error) {
+	if err := ie.check(1); err != nil {
+		return 0, err
+	}
+	return ie.Payload[0], nil
+}
+
+// Reporting Triggers flags, with the first octet of the IE in bits 16-23
+const (
+	TriggerPeriodic            = 0x010000 // PERIO
+	TriggerVolumeThreshold     = 0x020000 // VOLTH
+	TriggerTimeThreshold       = 0x040000 // TIMTH
+	TriggerQuotaHoldingTime    = 0x080000 // QUHTI
+	TriggerStartOfTraffic      = 0x100000 // START
+	TriggerStopOfTraffic       = 0x200000 // STOPT
+	TriggerDroppedDLThreshold  = 0x400000 // DROTH
+	TriggerLinkedUsage         = 0x800000 // LIUSA
+	TriggerVolumeQuota         = 0x000100 // VOLQU
+	TriggerTimeQuota           = 0x000200 // TIMQU
+	TriggerEnvelopeClosure     = 0x000400 // ENVCL
+	TriggerMACAddressReporting = 0x000800 // MACAR
+	TriggerEventThreshold      = 0x001000 // EVETH
+	TriggerEventQuota          = 0x002000 // EVEQU
+)
+
+// Usage Report Trigger flags, with the first octet of the IE in bits 16-23
+const (
+	ReportPeriodic           = 0x010000 // PERIO
+	ReportVolumeThreshold    = 0x020000 // VOLTH
+	ReportTimeThreshold      = 0x040000 // TIMTH
+	ReportQuotaHoldingTime   = 0x080000 // QUHTI
+	ReportStartOfTraffic     = 0x100000 // START
+	ReportStopOfTraffic      = 0x200000 // STOPT
+	ReportDroppedDLThreshold = 0x400000 // DROTH
+	ReportImmediate          = 0x800000 // IMMER
+	ReportVolumeQuota        = 0x000100 // VOLQU
+	ReportTimeQuota          = 0x000200 // TIMQU
+	ReportLinkedUsage        = 0x000400 // LIUSA
+	ReportTermination        = 0x000800 // TERMR
+	ReportMonitoringTime     = 0x001000 // MONIT
+	ReportEnvelopeClosure    = 0x002000 // ENVCL
+)
+
+// putFlags24 encodes up to three flag octets, first octet in bits 16-23
+func putFlags24(v uint32) []byte {
+	return []byte{byte(v >> 16), byte(v >> 8), byte(v)}
+}
+
+// getFlags24 decodes flag octets written by putFlags24. Peers of earlier
+// releases send fewer octets; the missing ones read as zero.
+func getFlags24(b []byte) uint32 {
+	var v uint32
+	for i := 0; i < 3; i++ {
+		v <<= 8
+		if i < len(b) {
+			v |= uint32(b[i])
+		}
+	}
+	return v
+}
+
+// NewReportingTriggers builds a Reporting Triggers IE
+func NewReportingTriggers(flags uint32) *IE {
+	return NewIE(IEReportingTriggers, putFlags24(flags))
+}
+
+// NewUsageReportTrigger builds a Usage Report Trigger IE
+func NewUsageReportTrigger(flags uint32) *IE {
+	return NewIE(IEUsageReportTrigger, putFlags24(flags))
+}
+
+// Flags returns the value of a Reporting Triggers or Usage Report Trigger IE
+func (ie *IE) Flags() (uint32, error) {
+	if err := ie.check(1); err != nil {
+		return 0, err
+	}
+	return getFlags24(ie.Payload), nil
+}
+
+// Report Type flags of the Session Report Request
+const (
+	ReportTypeDownlinkData = 0x01 // DLDR
+	ReportTypeUsage        = 0x02 // USAR
+	ReportTypeErrorInd     = 0x04 // ERIR
+	ReportTypeUserPlaneInd = 0x08 // UPIR
+)
+
+// NewReportType builds a Report Type IE
+func NewReportType(flags uint8) *IE {
+	return NewIE(IEReportType, []byte{flags})
+}
+
+// ReportType returns the flags of a Report Type IE
+func (ie *IE) ReportType() (uint8, error) {
+	if err := ie.check(1); err != nil {
+		return 0, err
+	}
+	return ie.Payload[0], nil
+}
+
+// Volume flags of the Volume Threshold, Volume Quota and Volume Measurement IEs
+const (
+	VolumeTotal           = 0x01 // TOVOL
+	VolumeUplink          = 0x02 // ULVOL
+	VolumeDownlink        = 0x04 // DLVOL
+	VolumeTotalPackets    = 0x08 // TONOP, Volume Measurement only
+	VolumeUplinkPackets   = 0x10 // ULNOP, Volume Measurement only
+	VolumeDownlinkPackets = 0x20 // DLNOP, Volume Measurement only
+)
+
+// Volume is the value of the Volume Threshold, Volume Quota and Volume
+// Measurement IEs.
+// Flags tells which counters are present.
+type Volume struct {
+	Flags           uint8  // Volume* flags
+	Total           uint64 // Total octets
+	Uplink          uint64 // Uplink octets
+	Downlink        uint64 // Downlink octets
+	TotalPackets    uint64 // Total packets
+	UplinkPackets   uint64 // Uplink packets
+	DownlinkPackets uint64 // Downlink packets
+}
+
+// fields pairs each volume flag with its counter in wire order
+func (v *Volume) fields() []struct {
+	flag uint8
+	val  *uint64
+} {
+	return []struct {
+		flag uint8
+		val  *uint64
+	}{
+		{VolumeTotal, &v.Total},
+		{VolumeUplink, &v.Uplink},
+		{VolumeDownlink, &v.Downlink},
+		{VolumeTotalPackets, &v.TotalPackets},
+		{VolumeUplinkPackets, &v.UplinkPackets},
+		{VolumeDownlinkPackets, &v.DownlinkPackets},
+	}
+}
+
+// newVolume encodes the counters selected by v.Flags
+func newVolume(t IEType, v Volume) *IE {
+	b := []byte{v.Flags}
+	for _, f := range v.fields() {
+		if v.Flags&f.flag != 0 {
+			b = binary.BigEndian.AppendUint64(b, *f.val)
+		}
+	}
+	return NewIE(t, b)
+}
+
+// NewVolumeThreshold builds a Volume Threshold IE
+func NewVolumeThreshold(v Volume) *IE { return newVolume(IEVolumeThreshold, v) }
+
+// NewVolumeQuota builds a Volume Quota IE
+func NewVolumeQuota(v Volume) *IE { return newVolume(IEVolumeQuota, v) }
+
+// NewVolumeMeasurement builds a Volume Measurement IE
+func NewVolumeMeasurement(v Volume) *IE { return newVolume(IEVolumeMeasurement, v) }
+
+// Volume returns the value of a Volume Threshold, Volume Quota or Volume
+// Measurement IE
+func (ie *IE) Volume() (Volume, error) {
+	if err := ie.check(1); err != nil {
+		return Volume{}, err
+	}
+	v := Volume{Flags: ie.Payload[0]}
+	b := ie.Payload[1:]
+	for _, f := range v.fields() {
+		if v.Flags&f.flag == 0 {
+			continue
+		}
+		if len(b) < 8 {
+			return Volume{}, ErrIEContents
+		}
+		*f.val, b = binary.BigEndian.Uint64(b[:8]), b[8:]
+	}
+	return v, nil
+}
+
+// NewTimeThreshold builds a Time Threshold IE
+func NewTimeThreshold(d time.Duration) *IE {
+	return newUint(IETimeThreshold, uint64(d/time.Second), 4)
+}
+
+// NewTimeQuota builds a Time Quota IE
+func NewTimeQuota(d time.Duration) *IE {
+	return newUint(IETimeQuota, uint64(d/time.Second), 4)
+}
+
+// NewDurationMeasurement builds a Duration Measurement IE
+func NewDurationMeasurement(d time.Duration) *IE {
+	return newUint(IEDurationMeasurement, uint64(d/time.Second), 4)
+}
+
+// NewMeasurementPeriod builds a Measurement Period IE
+func NewMeasurementPeriod(d time.Duration) *IE {
+	return newUint(IEMeasurementPeriod, uint64(d/time.Second), 4)
+}
+
+// Seconds returns the value of a Time Threshold, Time Quota, Duration
+// Measurement or Measurement Period IE
+func (ie *IE) Seconds() (time.Duration, error) {
+	v, err := ie.uint(4)
+	return time.Duration(v) * time.Second, err
+}
+
+// NewStartTime builds a Start Time IE
+func NewStartTime(t time.Time) *IE { return NewIE(IEStartTime, putTime(t)) }
+
+// NewEndTime builds an End Time IE
+func NewEndTime(t time.Time) *IE { return NewIE(IEEndTime, putTime(t)) }
+
+// NewTimeOfFirstPacket builds a Time of First Packet IE
+func NewTimeOfFirstPacket(t time.Time) *IE { return NewIE(IETimeOfFirstPacket, putTime(t)) }
+
+// NewTimeOfLastPacket builds a Time of Last Packet IE
+func NewTimeOfLastPacket(t time.Time) *IE { return NewIE(IETimeOfLastPacket, putTime(t)) }
+
+// Time returns the value of a time stamp IE such as Start Time or End Time
+func (ie *IE) Time() (time.Time, error) {
+	if err := ie.check(4); err != nil {
+		return time.Time{}, err
+	}
+	return getTime(ie.Payload), nil
+}
+
+// NewURSEQN builds a UR-SEQN IE
+func NewURSEQN(seq uint32) *IE { return newUint(IEURSEQN, uint64(seq), 4) }
+
+// UsageReport is the content of the Usage Report IEs sent in Session
+// Modification Responses, Session Deletion Responses and Session Report Requests
+type UsageReport struct {
+	URRID       uint32    // URR the report belongs to
+	Sequence    uint32    // UR-SEQN, incremented for every report of the URR
+	Trigger     uint32    // Report* flags explaining why the report was sent
+	Start       time.Time // Start of the measurement, if not zero
+	End         time.Time // End of the measurement, if not zero
+	Volume      *Volume   // Volume measurement, if measured
+	Duration    time.Duration
+	HasDuration bool      // Whether Duration was measured
+	FirstPacket time.Time // Time of the first packet, if not zero
+	LastPacket  time.Time // Time of the last packet, if not zero
+}
+
+// NewUsageReport builds a Usage Report IE of type t, which is one of
+// IEUsageReportModification, IEUsageReportDeletion or IEUsageReportReport
+func NewUsageReport(t IEType, r UsageReport) *IE {
+	ies := []*IE{NewURRID(r.URRID), NewURSEQN(r.Sequence), NewUsageReportTrigger(r.Trigger)}
+	if !r.Start.IsZero() {
+		ies = append(ies, NewStartTime(r.Start))
+	}
+	if !r.End.IsZero() {
+		ies = append(ies, NewEndTime(r.End))
+	}
+	if r.Volume != nil {
+		ies = append(ies, NewVolumeMeasurement(*r.Volume))
+	}
+	if r.HasDuration {
+		ies = append(ies, NewDurationMeasurement(r.Duration))
+	}
+	if !r.FirstPacket.IsZero() {
+		ies = append(ies, NewTimeOfFirstPacket(r.FirstPacket))
+	}
+	if !r.LastPacket.IsZero() {
+		ies = append(ies, NewTimeOfLastPacket(r.LastPacket))
+	}
+	return NewGrouped(t, ies...)
+}
+
+// UsageReport decodes a Usage Report IE
+func (ie *IE) UsageReport() (UsageReport, error) {
+	if ie == nil {
+		return UsageReport{}, ErrIEMissing
+	}
+	var r UsageReport
+	var err error
+	if r.URRID, err = ie.Find(IEURRID).Uint32(); err != nil {
+		return UsageReport{}, err
+	}
+	if r.Sequence, err = ie.Find(IEURSEQN).Uint32(); err != nil {
+		return UsageReport{}, err
+	}
+	if r.Trigger, err = ie.Find(IEUsageReportTrigger).Flags(); err != nil {
+		return UsageReport{}, err
+	}
+
+	// Optional members are decoded when present
+	optionalTime := func(t IEType, dst *time.Time) error {
+		if m := ie.Find(t); m != nil {
+			v, err := m.Time()
+			if err != nil {
+				return err
+			}
+			*dst = v
+		}
+		return nil
+	}
+	for t, dst := range map[IEType]*time.Time{
+		IEStartTime:         &r.Start,
+		IEEndTime:           &r.End,
+		IETimeOfFirstPacket: &r.FirstPacket,
+		IETimeOfLastPacket:  &r.LastPacket,
+	} {
+		if err := optionalTime(t, dst); err != nil {
+			return UsageReport{}, err
+		}
+	}
+	if m := ie.Find(IEVolumeMeasurement); m != nil {
+		v, err := m.Volume()
+		if err != nil {
+			return UsageReport{}, err
+		}
+		r.Volume = &v
+	}
+	if m := ie.Find(IEDurationMeasurement); m != nil {
+		if r.Duration, err = m.Seconds(); err != nil {
+			return UsageReport{}, err
+		}
+		r.HasDuration = true
+	}
+	return r, nil
+}
@@ -23,12 +23,114 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// SnapshotFormat selects the encoding of a session snapshot
+type SnapshotFormat int32
+
+const (
+	SnapshotFormat_SNAPSHOT_JSON   SnapshotFormat = 0 // protobuf JSON encoding
+	SnapshotFormat_SNAPSHOT_BINARY SnapshotFormat = 1 // protobuf binary encoding
+)
+
+// Enum value maps for SnapshotFormat.
+var (
+	SnapshotFormat_name = map[int32]string{
+		0: "SNAPSHOT_JSON",
+		1: "SNAPSHOT_BINARY",
+	}
+	SnapshotFormat_value = map[string]int32{
+		"SNAPSHOT_JSON":   0,
+		"SNAPSHOT_BINARY": 1,
+	}
+)
+
+func (x SnapshotFormat) Enum() *SnapshotFormat {
+	p := new(SnapshotFormat)
+	*p = x
+	return p
+}
+
+func (x SnapshotFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SnapshotFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_request_proto_enumTypes[0].Descriptor()
+}
+
+func (SnapshotFormat) Type() protoreflect.EnumType {
+	return &file_request_proto_enumTypes[0]
+}
+
+func (x SnapshotFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SnapshotFormat.Descriptor instead.
+func (SnapshotFormat) EnumDescriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{0}
+}
+
+// ImportMode selects how a snapshot is combined with the stored sessions
+type ImportMode int32
+
+const (
+	ImportMode_IMPORT_MERGE   ImportMode = 0 // Keep stored sessions, report conflicting ones
+	ImportMode_IMPORT_REPLACE ImportMode = 1 // Remove all stored sessions before importing
+)
+
+// Enum value maps for ImportMode.
+var (
+	ImportMode_name = map[int32]string{
+		0: "IMPORT_MERGE",
+		1: "IMPORT_REPLACE",
+	}
+	ImportMode_value = map[string]int32{
+		"IMPORT_MERGE":   0,
+		"IMPORT_REPLACE": 1,
+	}
+)
+
+func (x ImportMode) Enum() *ImportMode {
+	p := new(ImportMode)
+	*p = x
+	return p
+}
+
+func (x ImportMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImportMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_request_proto_enumTypes[1].Descriptor()
+}
+
+func (ImportMode) Type() protoreflect.EnumType {
+	return &file_request_proto_enumTypes[1]
+}
+
+func (x ImportMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImportMode.Descriptor instead.
+func (ImportMode) EnumDescriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{1}
+}
+
 // FlowRequest represents a request for flow data using FSEID
 type FlowRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Fseid         string                 `protobuf:"bytes,1,opt,name=fseid,proto3" json:"fseid,omitempty"` // F-SEID (Fully Qualified SEID)
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Fseid          string                 `protobuf:"bytes,1,opt,name=fseid,proto3" json:"fseid,omitempty"`                                          // F-SEID (Fully Qualified SEID)
+	Fseids         []string               `protobuf:"bytes,2,rep,name=fseids,proto3" json:"fseids,omitempty"`                                        // Further F-SEIDs to watch in the same stream
+	Imsi           string                 `protobuf:"bytes,3,opt,name=imsi,proto3" json:"imsi,omitempty"`                                            // Watch every session of this IMSI
+	Dnn            string                 `protobuf:"bytes,4,opt,name=dnn,proto3" json:"dnn,omitempty"`                                              // Watch every session on this DNN
+	IntervalMs     uint32                 `protobuf:"varint,5,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`             // Update interval, 100 to 60000; 0 means 2000
+	OnChange       bool                   `protobuf:"varint,6,opt,name=on_change,json=onChange,proto3" json:"on_change,omitempty"`                   // Only send updates for flows whose counters moved
+	Fields         []string               `protobuf:"bytes,7,rep,name=fields,proto3" json:"fields,omitempty"`                                        // Counters to send: packets, bytes, speed, speed_avg, packet_rate, packet_rate_avg, qos; empty means all
+	SubscriptionId uint64                 `protobuf:"varint,8,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"` // Resume this subscription instead of starting one; the other fields are then ignored
+	ResumeFrom     uint64                 `protobuf:"varint,9,opt,name=resume_from,json=resumeFrom,proto3" json:"resume_from,omitempty"`             // Last count received on the subscription; later updates are replayed
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *FlowRequest) Reset() {
@@ -68,19 +170,95 @@ func (x *FlowRequest) GetFseid() string {
 	return ""
 }
 
+func (x *FlowRequest) GetFseids() []string {
+	if x != nil {
+		return x.Fseids
+	}
+	return nil
+}
+
+func (x *FlowRequest) GetImsi() string {
+	if x != nil {
+		return x.Imsi
+	}
+	return ""
+}
+
+func (x *FlowRequest) GetDnn() string {
+	if x != nil {
+		return x.Dnn
+	}
+	return ""
+}
+
+func (x *FlowRequest) GetIntervalMs() uint32 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+func (x *FlowRequest) GetOnChange() bool {
+	if x != nil {
+		return x.OnChange
+	}
+	return false
+}
+
+func (x *FlowRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *FlowRequest) GetSubscriptionId() uint64 {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return 0
+}
+
+func (x *FlowRequest) GetResumeFrom() uint64 {
+	if x != nil {
+		return x.ResumeFrom
+	}
+	return 0
+}
+
 // Reply contains flow statistics and IMSI information
 type Reply struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Total_Packets uint64                 `protobuf:"varint,1,opt,name=Total_Packets,json=TotalPackets,proto3" json:"Total_Packets,omitempty"` // Total number of packets
-	Rx_Packet     uint64                 `protobuf:"varint,2,opt,name=Rx_Packet,json=RxPacket,proto3" json:"Rx_Packet,omitempty"`             // Number of received packets
-	Tx_Packet     uint64                 `protobuf:"varint,3,opt,name=Tx_Packet,json=TxPacket,proto3" json:"Tx_Packet,omitempty"`             // Number of transmitted packets
-	Rx_Speed      uint64                 `protobuf:"varint,4,opt,name=Rx_Speed,json=RxSpeed,proto3" json:"Rx_Speed,omitempty"`                // Receive speed
-	Tx_Speed      uint64                 `protobuf:"varint,5,opt,name=Tx_Speed,json=TxSpeed,proto3" json:"Tx_Speed,omitempty"`                // Transmit speed
-	Total_Speed   uint64                 `protobuf:"varint,6,opt,name=Total_Speed,json=TotalSpeed,proto3" json:"Total_Speed,omitempty"`       // Total speed (Rx + Tx)
-	All_IMSI      []string               `protobuf:"bytes,7,rep,name=All_IMSI,json=AllIMSI,proto3" json:"All_IMSI,omitempty"`                 // List of all IMSIs
-	Count         uint64                 `protobuf:"varint,8,opt,name=count,proto3" json:"count,omitempty"`                                   // Packet counter
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Total_Packets      uint64                 `protobuf:"varint,1,opt,name=Total_Packets,json=TotalPackets,proto3" json:"Total_Packets,omitempty"`                // Total number of packets
+	Rx_Packet          uint64                 `protobuf:"varint,2,opt,name=Rx_Packet,json=RxPacket,proto3" json:"Rx_Packet,omitempty"`                            // Number of received packets
+	Tx_Packet          uint64                 `protobuf:"varint,3,opt,name=Tx_Packet,json=TxPacket,proto3" json:"Tx_Packet,omitempty"`                            // Number of transmitted packets
+	Rx_Speed           uint64                 `protobuf:"varint,4,opt,name=Rx_Speed,json=RxSpeed,proto3" json:"Rx_Speed,omitempty"`                               // Receive speed over the last interval, in Speed_Unit
+	Tx_Speed           uint64                 `protobuf:"varint,5,opt,name=Tx_Speed,json=TxSpeed,proto3" json:"Tx_Speed,omitempty"`                               // Transmit speed over the last interval, in Speed_Unit
+	Total_Speed        uint64                 `protobuf:"varint,6,opt,name=Total_Speed,json=TotalSpeed,proto3" json:"Total_Speed,omitempty"`                      // Total speed (Rx + Tx), in Speed_Unit
+	All_IMSI           []string               `protobuf:"bytes,7,rep,name=All_IMSI,json=AllIMSI,proto3" json:"All_IMSI,omitempty"`                                // List of all IMSIs
+	Count              uint64                 `protobuf:"varint,8,opt,name=count,proto3" json:"count,omitempty"`                                                  // Sequence number of the update within its subscription
+	Rx_Bytes           uint64                 `protobuf:"varint,9,opt,name=Rx_Bytes,json=RxBytes,proto3" json:"Rx_Bytes,omitempty"`                               // Cumulative received bytes
+	Tx_Bytes           uint64                 `protobuf:"varint,10,opt,name=Tx_Bytes,json=TxBytes,proto3" json:"Tx_Bytes,omitempty"`                              // Cumulative transmitted bytes
+	Rx_Packet_Rate     float64                `protobuf:"fixed64,11,opt,name=Rx_Packet_Rate,json=RxPacketRate,proto3" json:"Rx_Packet_Rate,omitempty"`            // Receive packet rate over the last interval, in Packet_Rate_Unit
+	Tx_Packet_Rate     float64                `protobuf:"fixed64,12,opt,name=Tx_Packet_Rate,json=TxPacketRate,proto3" json:"Tx_Packet_Rate,omitempty"`            // Transmit packet rate over the last interval, in Packet_Rate_Unit
+	Rx_Speed_Avg       float64                `protobuf:"fixed64,13,opt,name=Rx_Speed_Avg,json=RxSpeedAvg,proto3" json:"Rx_Speed_Avg,omitempty"`                  // EWMA-smoothed receive speed, in Speed_Unit
+	Tx_Speed_Avg       float64                `protobuf:"fixed64,14,opt,name=Tx_Speed_Avg,json=TxSpeedAvg,proto3" json:"Tx_Speed_Avg,omitempty"`                  // EWMA-smoothed transmit speed, in Speed_Unit
+	Rx_Packet_Rate_Avg float64                `protobuf:"fixed64,15,opt,name=Rx_Packet_Rate_Avg,json=RxPacketRateAvg,proto3" json:"Rx_Packet_Rate_Avg,omitempty"` // EWMA-smoothed receive packet rate, in Packet_Rate_Unit
+	Tx_Packet_Rate_Avg float64                `protobuf:"fixed64,16,opt,name=Tx_Packet_Rate_Avg,json=TxPacketRateAvg,proto3" json:"Tx_Packet_Rate_Avg,omitempty"` // EWMA-smoothed transmit packet rate, in Packet_Rate_Unit
+	Speed_Unit         string                 `protobuf:"bytes,17,opt,name=Speed_Unit,json=SpeedUnit,proto3" json:"Speed_Unit,omitempty"`                         // Unit of the speeds, "bit/s"
+	Packet_Rate_Unit   string                 `protobuf:"bytes,18,opt,name=Packet_Rate_Unit,json=PacketRateUnit,proto3" json:"Packet_Rate_Unit,omitempty"`        // Unit of the packet rates, "packet/s"
+	Measured_Unix_Nano int64                  `protobuf:"varint,19,opt,name=Measured_Unix_Nano,json=MeasuredUnixNano,proto3" json:"Measured_Unix_Nano,omitempty"` // When the counters were measured
+	Interval_Ms        int64                  `protobuf:"varint,20,opt,name=Interval_Ms,json=IntervalMs,proto3" json:"Interval_Ms,omitempty"`                     // Length of the interval the rates were derived over
+	Fseid              string                 `protobuf:"bytes,21,opt,name=fseid,proto3" json:"fseid,omitempty"`                                                  // F-SEID the update belongs to
+	Imsi               string                 `protobuf:"bytes,22,opt,name=imsi,proto3" json:"imsi,omitempty"`                                                    // IMSI owning the session, if known
+	SubscriptionId     uint64                 `protobuf:"varint,23,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`         // Subscription the update belongs to, for resuming
+	Gap                bool                   `protobuf:"varint,24,opt,name=gap,proto3" json:"gap,omitempty"`                                                     // Gap marker: updates were lost and carry no counters
+	GapFrom            uint64                 `protobuf:"varint,25,opt,name=gap_from,json=gapFrom,proto3" json:"gap_from,omitempty"`                              // First count that may be missing
+	GapTo              uint64                 `protobuf:"varint,26,opt,name=gap_to,json=gapTo,proto3" json:"gap_to,omitempty"`                                    // Last count that may be missing; 0 if the subscription itself was lost
+	Rx_QoS             *QosCounters           `protobuf:"bytes,27,opt,name=Rx_QoS,json=RxQoS,proto3" json:"Rx_QoS,omitempty"`                                     // Received packets by policer color, simulated sessions only
+	Tx_QoS             *QosCounters           `protobuf:"bytes,28,opt,name=Tx_QoS,json=TxQoS,proto3" json:"Tx_QoS,omitempty"`                                     // Transmitted packets by policer color, simulated sessions only
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *Reply) Reset() {
@@ -169,156 +347,176 @@ func (x *Reply) GetCount() uint64 {
 	return 0
 }
 
-// ConfigRequest is empty as it doesn't need parameters
-type ConfigRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *Reply) GetRx_Bytes() uint64 {
+	if x != nil {
+		return x.Rx_Bytes
+	}
+	return 0
 }
 
-func (x *ConfigRequest) Reset() {
-	*x = ConfigRequest{}
-	mi := &file_request_proto_msgTypes[2]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *Reply) GetTx_Bytes() uint64 {
+	if x != nil {
+		return x.Tx_Bytes
+	}
+	return 0
 }
 
-func (x *ConfigRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Reply) GetRx_Packet_Rate() float64 {
+	if x != nil {
+		return x.Rx_Packet_Rate
+	}
+	return 0
 }
 
-func (*ConfigRequest) ProtoMessage() {}
+func (x *Reply) GetTx_Packet_Rate() float64 {
+	if x != nil {
+		return x.Tx_Packet_Rate
+	}
+	return 0
+}
 
-func (x *ConfigRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[2]
+func (x *Reply) GetRx_Speed_Avg() float64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Rx_Speed_Avg
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use ConfigRequest.ProtoReflect.Descriptor instead.
-func (*ConfigRequest) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{2}
+func (x *Reply) GetTx_Speed_Avg() float64 {
+	if x != nil {
+		return x.Tx_Speed_Avg
+	}
+	return 0
 }
 
-// ConfigReply contains the complete UPF configuration
-type ConfigReply struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Config        *UPFConfig             `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"` // UPF configuration details
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *Reply) GetRx_Packet_Rate_Avg() float64 {
+	if x != nil {
+		return x.Rx_Packet_Rate_Avg
+	}
+	return 0
 }
 
-func (x *ConfigReply) Reset() {
-	*x = ConfigReply{}
-	mi := &file_request_proto_msgTypes[3]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *Reply) GetTx_Packet_Rate_Avg() float64 {
+	if x != nil {
+		return x.Tx_Packet_Rate_Avg
+	}
+	return 0
 }
 
-func (x *ConfigReply) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Reply) GetSpeed_Unit() string {
+	if x != nil {
+		return x.Speed_Unit
+	}
+	return ""
 }
 
-func (*ConfigReply) ProtoMessage() {}
+func (x *Reply) GetPacket_Rate_Unit() string {
+	if x != nil {
+		return x.Packet_Rate_Unit
+	}
+	return ""
+}
 
-func (x *ConfigReply) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[3]
+func (x *Reply) GetMeasured_Unix_Nano() int64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Measured_Unix_Nano
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use ConfigReply.ProtoReflect.Descriptor instead.
-func (*ConfigReply) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{3}
+func (x *Reply) GetInterval_Ms() int64 {
+	if x != nil {
+		return x.Interval_Ms
+	}
+	return 0
 }
 
-func (x *ConfigReply) GetConfig() *UPFConfig {
+func (x *Reply) GetFseid() string {
 	if x != nil {
-		return x.Config
+		return x.Fseid
 	}
-	return nil
+	return ""
 }
 
-// IMSIRequest contains the IMSI to query
-type IMSIRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Imsi          string                 `protobuf:"bytes,1,opt,name=imsi,proto3" json:"imsi,omitempty"` // International Mobile Subscriber Identity
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *Reply) GetImsi() string {
+	if x != nil {
+		return x.Imsi
+	}
+	return ""
 }
 
-func (x *IMSIRequest) Reset() {
-	*x = IMSIRequest{}
-	mi := &file_request_proto_msgTypes[4]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *Reply) GetSubscriptionId() uint64 {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return 0
 }
 
-func (x *IMSIRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Reply) GetGap() bool {
+	if x != nil {
+		return x.Gap
+	}
+	return false
 }
 
-func (*IMSIRequest) ProtoMessage() {}
+func (x *Reply) GetGapFrom() uint64 {
+	if x != nil {
+		return x.GapFrom
+	}
+	return 0
+}
 
-func (x *IMSIRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[4]
+func (x *Reply) GetGapTo() uint64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.GapTo
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use IMSIRequest.ProtoReflect.Descriptor instead.
-func (*IMSIRequest) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{4}
+func (x *Reply) GetRx_QoS() *QosCounters {
+	if x != nil {
+		return x.Rx_QoS
+	}
+	return nil
 }
 
-func (x *IMSIRequest) GetImsi() string {
+func (x *Reply) GetTx_QoS() *QosCounters {
 	if x != nil {
-		return x.Imsi
+		return x.Tx_QoS
 	}
-	return ""
+	return nil
 }
 
-// IMSIReply contains IMSI-related information
-type IMSIReply struct {
+// QosCounters count the packets of one direction of a session by the color the
+// QER and AMBR policers gave them. Green and yellow packets are forwarded and
+// make up the packet and byte counters; red packets are dropped.
+type QosCounters struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Imsi          []*IMSIStruct          `protobuf:"bytes,1,rep,name=imsi,proto3" json:"imsi,omitempty"` // List of IMSI structures
+	GreenPackets  uint64                 `protobuf:"varint,1,opt,name=green_packets,json=greenPackets,proto3" json:"green_packets,omitempty"` // Forwarded within the committed rate (GBR)
+	GreenBytes    uint64                 `protobuf:"varint,2,opt,name=green_bytes,json=greenBytes,proto3" json:"green_bytes,omitempty"`
+	YellowPackets uint64                 `protobuf:"varint,3,opt,name=yellow_packets,json=yellowPackets,proto3" json:"yellow_packets,omitempty"` // Forwarded, marked as exceeding the committed rate
+	YellowBytes   uint64                 `protobuf:"varint,4,opt,name=yellow_bytes,json=yellowBytes,proto3" json:"yellow_bytes,omitempty"`
+	RedPackets    uint64                 `protobuf:"varint,5,opt,name=red_packets,json=redPackets,proto3" json:"red_packets,omitempty"` // Dropped for exceeding the peak rate (MBR) or the session AMBR
+	RedBytes      uint64                 `protobuf:"varint,6,opt,name=red_bytes,json=redBytes,proto3" json:"red_bytes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *IMSIReply) Reset() {
-	*x = IMSIReply{}
-	mi := &file_request_proto_msgTypes[5]
+func (x *QosCounters) Reset() {
+	*x = QosCounters{}
+	mi := &file_request_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *IMSIReply) String() string {
+func (x *QosCounters) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*IMSIReply) ProtoMessage() {}
+func (*QosCounters) ProtoMessage() {}
 
-func (x *IMSIReply) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[5]
+func (x *QosCounters) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -329,31 +527,238 @@ func (x *IMSIReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use IMSIReply.ProtoReflect.Descriptor instead.
-func (*IMSIReply) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use QosCounters.ProtoReflect.Descriptor instead.
+func (*QosCounters) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *IMSIReply) GetImsi() []*IMSIStruct {
+func (x *QosCounters) GetGreenPackets() uint64 {
 	if x != nil {
-		return x.Imsi
+		return x.GreenPackets
 	}
-	return nil
+	return 0
 }
 
-// ValidatePDRRequest contains the parameters to validate a PDR
-type ValidatePDRRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Imsi          string                 `protobuf:"bytes,1,opt,name=imsi,proto3" json:"imsi,omitempty"`                // IMSI of the subscriber
-	PdrId         string                 `protobuf:"bytes,2,opt,name=pdr_id,json=pdrId,proto3" json:"pdr_id,omitempty"` // PDR ID to validate
-	Dnn           string                 `protobuf:"bytes,3,opt,name=dnn,proto3" json:"dnn,omitempty"`                  // Data Network Name
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
+func (x *QosCounters) GetGreenBytes() uint64 {
+	if x != nil {
+		return x.GreenBytes
+	}
+	return 0
+}
+
+func (x *QosCounters) GetYellowPackets() uint64 {
+	if x != nil {
+		return x.YellowPackets
+	}
+	return 0
+}
+
+func (x *QosCounters) GetYellowBytes() uint64 {
+	if x != nil {
+		return x.YellowBytes
+	}
+	return 0
+}
+
+func (x *QosCounters) GetRedPackets() uint64 {
+	if x != nil {
+		return x.RedPackets
+	}
+	return 0
+}
+
+func (x *QosCounters) GetRedBytes() uint64 {
+	if x != nil {
+		return x.RedBytes
+	}
+	return 0
+}
+
+// ConfigRequest is empty as it doesn't need parameters
+type ConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigRequest) Reset() {
+	*x = ConfigRequest{}
+	mi := &file_request_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigRequest) ProtoMessage() {}
+
+func (x *ConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigRequest.ProtoReflect.Descriptor instead.
+func (*ConfigRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{3}
+}
+
+// ConfigReply contains the complete UPF configuration
+type ConfigReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Config        *UPFConfig             `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"` // UPF configuration details
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigReply) Reset() {
+	*x = ConfigReply{}
+	mi := &file_request_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigReply) ProtoMessage() {}
+
+func (x *ConfigReply) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigReply.ProtoReflect.Descriptor instead.
+func (*ConfigReply) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ConfigReply) GetConfig() *UPFConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+// IMSIRequest contains the IMSI to query
+type IMSIRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Imsi          string                 `protobuf:"bytes,1,opt,name=imsi,proto3" json:"imsi,omitempty"` // International Mobile Subscriber Identity
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IMSIRequest) Reset() {
+	*x = IMSIRequest{}
+	mi := &file_request_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IMSIRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IMSIRequest) ProtoMessage() {}
+
+func (x *IMSIRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IMSIRequest.ProtoReflect.Descriptor instead.
+func (*IMSIRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *IMSIRequest) GetImsi() string {
+	if x != nil {
+		return x.Imsi
+	}
+	return ""
+}
+
+// IMSIReply contains IMSI-related information
+type IMSIReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Imsi          []*IMSIStruct          `protobuf:"bytes,1,rep,name=imsi,proto3" json:"imsi,omitempty"` // List of IMSI structures
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IMSIReply) Reset() {
+	*x = IMSIReply{}
+	mi := &file_request_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IMSIReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IMSIReply) ProtoMessage() {}
+
+func (x *IMSIReply) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IMSIReply.ProtoReflect.Descriptor instead.
+func (*IMSIReply) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *IMSIReply) GetImsi() []*IMSIStruct {
+	if x != nil {
+		return x.Imsi
+	}
+	return nil
+}
+
+// ValidatePDRRequest contains the parameters to validate a PDR
+type ValidatePDRRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Imsi          string                 `protobuf:"bytes,1,opt,name=imsi,proto3" json:"imsi,omitempty"`                // IMSI of the subscriber
+	PdrId         string                 `protobuf:"bytes,2,opt,name=pdr_id,json=pdrId,proto3" json:"pdr_id,omitempty"` // PDR ID to validate
+	Dnn           string                 `protobuf:"bytes,3,opt,name=dnn,proto3" json:"dnn,omitempty"`                  // Data Network Name
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
 func (x *ValidatePDRRequest) Reset() {
 	*x = ValidatePDRRequest{}
-	mi := &file_request_proto_msgTypes[6]
+	mi := &file_request_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -365,7 +770,7 @@ func (x *ValidatePDRRequest) String() string {
 func (*ValidatePDRRequest) ProtoMessage() {}
 
 func (x *ValidatePDRRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[6]
+	mi := &file_request_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -378,7 +783,7 @@ func (x *ValidatePDRRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ValidatePDRRequest.ProtoReflect.Descriptor instead.
 func (*ValidatePDRRequest) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{6}
+	return file_request_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ValidatePDRRequest) GetImsi() string {
@@ -413,7 +818,7 @@ type ValidatePDRReply struct {
 
 func (x *ValidatePDRReply) Reset() {
 	*x = ValidatePDRReply{}
-	mi := &file_request_proto_msgTypes[7]
+	mi := &file_request_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -425,7 +830,7 @@ func (x *ValidatePDRReply) String() string {
 func (*ValidatePDRReply) ProtoMessage() {}
 
 func (x *ValidatePDRReply) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[7]
+	mi := &file_request_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -438,7 +843,7 @@ func (x *ValidatePDRReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ValidatePDRReply.ProtoReflect.Descriptor instead.
 func (*ValidatePDRReply) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{7}
+	return file_request_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ValidatePDRReply) GetValid() bool {
@@ -465,7 +870,7 @@ type RuleRequest struct {
 
 func (x *RuleRequest) Reset() {
 	*x = RuleRequest{}
-	mi := &file_request_proto_msgTypes[8]
+	mi := &file_request_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -477,7 +882,7 @@ func (x *RuleRequest) String() string {
 func (*RuleRequest) ProtoMessage() {}
 
 func (x *RuleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[8]
+	mi := &file_request_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -490,7 +895,7 @@ func (x *RuleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RuleRequest.ProtoReflect.Descriptor instead.
 func (*RuleRequest) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{8}
+	return file_request_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *RuleRequest) GetFsied() string {
@@ -500,29 +905,29 @@ func (x *RuleRequest) GetFsied() string {
 	return ""
 }
 
-// RuleReply contains the complete session rules
-type RuleReply struct {
+// PutRuleRequest carries the session rules to store
+type PutRuleRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Session       *Rulestruct            `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"` // Session rule structure
+	Session       *Rulestruct            `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"` // Session rule structure, keyed by pdr.fsied
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RuleReply) Reset() {
-	*x = RuleReply{}
-	mi := &file_request_proto_msgTypes[9]
+func (x *PutRuleRequest) Reset() {
+	*x = PutRuleRequest{}
+	mi := &file_request_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RuleReply) String() string {
+func (x *PutRuleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RuleReply) ProtoMessage() {}
+func (*PutRuleRequest) ProtoMessage() {}
 
-func (x *RuleReply) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[9]
+func (x *PutRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -533,44 +938,91 @@ func (x *RuleReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RuleReply.ProtoReflect.Descriptor instead.
-func (*RuleReply) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use PutRuleRequest.ProtoReflect.Descriptor instead.
+func (*PutRuleRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *RuleReply) GetSession() *Rulestruct {
+func (x *PutRuleRequest) GetSession() *Rulestruct {
 	if x != nil {
 		return x.Session
 	}
 	return nil
 }
 
-// rulestruct contains all rule components for a session
-type Rulestruct struct {
+// RuleReply contains the complete session rules
+type RuleReply struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Pdr           *Pdrstruct             `protobuf:"bytes,1,opt,name=pdr,proto3" json:"pdr,omitempty"` // Packet Detection Rule
-	Far           *Farstruct             `protobuf:"bytes,2,opt,name=far,proto3" json:"far,omitempty"` // Forwarding Action Rule
-	Qer           *Qerstruct             `protobuf:"bytes,3,opt,name=qer,proto3" json:"qer,omitempty"` // QoS Enforcement Rule
-	Urr           *Urrstruct             `protobuf:"bytes,4,opt,name=urr,proto3" json:"urr,omitempty"` // Usage Reporting Rule
+	Session       *Rulestruct            `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"` // Session rule structure
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Rulestruct) Reset() {
-	*x = Rulestruct{}
-	mi := &file_request_proto_msgTypes[10]
+func (x *RuleReply) Reset() {
+	*x = RuleReply{}
+	mi := &file_request_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Rulestruct) String() string {
+func (x *RuleReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Rulestruct) ProtoMessage() {}
+func (*RuleReply) ProtoMessage() {}
+
+func (x *RuleReply) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleReply.ProtoReflect.Descriptor instead.
+func (*RuleReply) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RuleReply) GetSession() *Rulestruct {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+// rulestruct contains all rule components for a session
+type Rulestruct struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pdr           *Pdrstruct             `protobuf:"bytes,1,opt,name=pdr,proto3" json:"pdr,omitempty"`   // Packet Detection Rule
+	Far           *Farstruct             `protobuf:"bytes,2,opt,name=far,proto3" json:"far,omitempty"`   // Forwarding Action Rule
+	Qer           *Qerstruct             `protobuf:"bytes,3,opt,name=qer,proto3" json:"qer,omitempty"`   // QoS Enforcement Rule
+	Urr           *Urrstruct             `protobuf:"bytes,4,opt,name=urr,proto3" json:"urr,omitempty"`   // Usage Reporting Rule
+	Imsi          string                 `protobuf:"bytes,5,opt,name=imsi,proto3" json:"imsi,omitempty"` // IMSI of the subscriber owning the session
+	Dnn           string                 `protobuf:"bytes,6,opt,name=dnn,proto3" json:"dnn,omitempty"`   // Data Network Name of the session
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Rulestruct) Reset() {
+	*x = Rulestruct{}
+	mi := &file_request_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rulestruct) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rulestruct) ProtoMessage() {}
 
 func (x *Rulestruct) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[10]
+	mi := &file_request_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -583,7 +1035,7 @@ func (x *Rulestruct) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Rulestruct.ProtoReflect.Descriptor instead.
 func (*Rulestruct) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{10}
+	return file_request_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *Rulestruct) GetPdr() *Pdrstruct {
@@ -614,18 +1066,34 @@ func (x *Rulestruct) GetUrr() *Urrstruct {
 	return nil
 }
 
+func (x *Rulestruct) GetImsi() string {
+	if x != nil {
+		return x.Imsi
+	}
+	return ""
+}
+
+func (x *Rulestruct) GetDnn() string {
+	if x != nil {
+		return x.Dnn
+	}
+	return ""
+}
+
 // pdrstruct defines Packet Detection Rule structure
 type Pdrstruct struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PdrId         []string               `protobuf:"bytes,1,rep,name=pdr_id,json=pdrId,proto3" json:"pdr_id,omitempty"` // List of PDR IDs
 	Fsied         string                 `protobuf:"bytes,2,opt,name=fsied,proto3" json:"fsied,omitempty"`              // Associated F-SEID
+	UeIp          string                 `protobuf:"bytes,3,opt,name=ue_ip,json=ueIp,proto3" json:"ue_ip,omitempty"`    // UE IP address matched by the PDRs
+	Teid          string                 `protobuf:"bytes,4,opt,name=teid,proto3" json:"teid,omitempty"`                // N3 TEID matched by the uplink PDRs
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Pdrstruct) Reset() {
 	*x = Pdrstruct{}
-	mi := &file_request_proto_msgTypes[11]
+	mi := &file_request_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -637,7 +1105,7 @@ func (x *Pdrstruct) String() string {
 func (*Pdrstruct) ProtoMessage() {}
 
 func (x *Pdrstruct) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[11]
+	mi := &file_request_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -650,7 +1118,7 @@ func (x *Pdrstruct) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Pdrstruct.ProtoReflect.Descriptor instead.
 func (*Pdrstruct) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{11}
+	return file_request_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *Pdrstruct) GetPdrId() []string {
@@ -667,18 +1135,35 @@ func (x *Pdrstruct) GetFsied() string {
 	return ""
 }
 
+func (x *Pdrstruct) GetUeIp() string {
+	if x != nil {
+		return x.UeIp
+	}
+	return ""
+}
+
+func (x *Pdrstruct) GetTeid() string {
+	if x != nil {
+		return x.Teid
+	}
+	return ""
+}
+
 // farstruct defines Forwarding Action Rule structure
 type Farstruct struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	FarId         string                 `protobuf:"bytes,1,opt,name=far_id,json=farId,proto3" json:"far_id,omitempty"` // FAR ID
-	Fsied         string                 `protobuf:"bytes,2,opt,name=fsied,proto3" json:"fsied,omitempty"`              // Associated F-SEID
+	FarId         string                 `protobuf:"bytes,1,opt,name=far_id,json=farId,proto3" json:"far_id,omitempty"`                   // FAR ID
+	Fsied         string                 `protobuf:"bytes,2,opt,name=fsied,proto3" json:"fsied,omitempty"`                                // Associated F-SEID
+	ApplyAction   string                 `protobuf:"bytes,3,opt,name=apply_action,json=applyAction,proto3" json:"apply_action,omitempty"` // forward, drop or buffer; empty means forward
+	OuterIp       string                 `protobuf:"bytes,4,opt,name=outer_ip,json=outerIp,proto3" json:"outer_ip,omitempty"`             // Outer header creation peer (eNB/gNB) address for downlink
+	OuterTeid     string                 `protobuf:"bytes,5,opt,name=outer_teid,json=outerTeid,proto3" json:"outer_teid,omitempty"`       // Outer header creation TEID for downlink
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Farstruct) Reset() {
 	*x = Farstruct{}
-	mi := &file_request_proto_msgTypes[12]
+	mi := &file_request_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -690,7 +1175,7 @@ func (x *Farstruct) String() string {
 func (*Farstruct) ProtoMessage() {}
 
 func (x *Farstruct) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[12]
+	mi := &file_request_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -703,7 +1188,7 @@ func (x *Farstruct) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Farstruct.ProtoReflect.Descriptor instead.
 func (*Farstruct) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{12}
+	return file_request_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *Farstruct) GetFarId() string {
@@ -720,18 +1205,44 @@ func (x *Farstruct) GetFsied() string {
 	return ""
 }
 
+func (x *Farstruct) GetApplyAction() string {
+	if x != nil {
+		return x.ApplyAction
+	}
+	return ""
+}
+
+func (x *Farstruct) GetOuterIp() string {
+	if x != nil {
+		return x.OuterIp
+	}
+	return ""
+}
+
+func (x *Farstruct) GetOuterTeid() string {
+	if x != nil {
+		return x.OuterTeid
+	}
+	return ""
+}
+
 // qerstruct defines QoS Enforcement Rule structure
 type Qerstruct struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	QerId         string                 `protobuf:"bytes,1,opt,name=qer_id,json=qerId,proto3" json:"qer_id,omitempty"` // QER ID
-	Fsied         string                 `protobuf:"bytes,2,opt,name=fsied,proto3" json:"fsied,omitempty"`              // Associated F-SEID
+	QerId         string                 `protobuf:"bytes,1,opt,name=qer_id,json=qerId,proto3" json:"qer_id,omitempty"`  // QER ID
+	Fsied         string                 `protobuf:"bytes,2,opt,name=fsied,proto3" json:"fsied,omitempty"`               // Associated F-SEID
+	Qfi           uint32                 `protobuf:"varint,3,opt,name=qfi,proto3" json:"qfi,omitempty"`                  // QoS Flow Identifier
+	UlMbr         uint64                 `protobuf:"varint,4,opt,name=ul_mbr,json=ulMbr,proto3" json:"ul_mbr,omitempty"` // Uplink maximum bit rate in bits per second
+	DlMbr         uint64                 `protobuf:"varint,5,opt,name=dl_mbr,json=dlMbr,proto3" json:"dl_mbr,omitempty"` // Downlink maximum bit rate in bits per second
+	UlGbr         uint64                 `protobuf:"varint,6,opt,name=ul_gbr,json=ulGbr,proto3" json:"ul_gbr,omitempty"` // Uplink guaranteed bit rate in bits per second
+	DlGbr         uint64                 `protobuf:"varint,7,opt,name=dl_gbr,json=dlGbr,proto3" json:"dl_gbr,omitempty"` // Downlink guaranteed bit rate in bits per second
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Qerstruct) Reset() {
 	*x = Qerstruct{}
-	mi := &file_request_proto_msgTypes[13]
+	mi := &file_request_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -743,7 +1254,7 @@ func (x *Qerstruct) String() string {
 func (*Qerstruct) ProtoMessage() {}
 
 func (x *Qerstruct) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[13]
+	mi := &file_request_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -756,7 +1267,7 @@ func (x *Qerstruct) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Qerstruct.ProtoReflect.Descriptor instead.
 func (*Qerstruct) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{13}
+	return file_request_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *Qerstruct) GetQerId() string {
@@ -773,18 +1284,60 @@ func (x *Qerstruct) GetFsied() string {
 	return ""
 }
 
+func (x *Qerstruct) GetQfi() uint32 {
+	if x != nil {
+		return x.Qfi
+	}
+	return 0
+}
+
+func (x *Qerstruct) GetUlMbr() uint64 {
+	if x != nil {
+		return x.UlMbr
+	}
+	return 0
+}
+
+func (x *Qerstruct) GetDlMbr() uint64 {
+	if x != nil {
+		return x.DlMbr
+	}
+	return 0
+}
+
+func (x *Qerstruct) GetUlGbr() uint64 {
+	if x != nil {
+		return x.UlGbr
+	}
+	return 0
+}
+
+func (x *Qerstruct) GetDlGbr() uint64 {
+	if x != nil {
+		return x.DlGbr
+	}
+	return 0
+}
+
 // urrstruct defines Usage Reporting Rule structure
 type Urrstruct struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UrrId         string                 `protobuf:"bytes,1,opt,name=urr_id,json=urrId,proto3" json:"urr_id,omitempty"` // URR ID
-	Fsied         string                 `protobuf:"bytes,2,opt,name=fsied,proto3" json:"fsied,omitempty"`              // Associated F-SEID
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	UrrId             string                 `protobuf:"bytes,1,opt,name=urr_id,json=urrId,proto3" json:"urr_id,omitempty"`                                      // URR ID
+	Fsied             string                 `protobuf:"bytes,2,opt,name=fsied,proto3" json:"fsied,omitempty"`                                                   // Associated F-SEID
+	MeasurementMethod []string               `protobuf:"bytes,3,rep,name=measurement_method,json=measurementMethod,proto3" json:"measurement_method,omitempty"`  // volume and/or duration; empty means both
+	ReportingTriggers []string               `protobuf:"bytes,4,rep,name=reporting_triggers,json=reportingTriggers,proto3" json:"reporting_triggers,omitempty"`  // periodic, volume_threshold, time_threshold, volume_quota, time_quota, start_of_traffic
+	VolumeThreshold   uint64                 `protobuf:"varint,5,opt,name=volume_threshold,json=volumeThreshold,proto3" json:"volume_threshold,omitempty"`       // Total octets after which a report is sent, 0 if none
+	VolumeQuota       uint64                 `protobuf:"varint,6,opt,name=volume_quota,json=volumeQuota,proto3" json:"volume_quota,omitempty"`                   // Total octets granted to the session, 0 if none
+	TimeThreshold     uint32                 `protobuf:"varint,7,opt,name=time_threshold,json=timeThreshold,proto3" json:"time_threshold,omitempty"`             // Seconds of traffic after which a report is sent, 0 if none
+	TimeQuota         uint32                 `protobuf:"varint,8,opt,name=time_quota,json=timeQuota,proto3" json:"time_quota,omitempty"`                         // Seconds of traffic granted to the session, 0 if none
+	MeasurementPeriod uint32                 `protobuf:"varint,9,opt,name=measurement_period,json=measurementPeriod,proto3" json:"measurement_period,omitempty"` // Seconds between periodic reports, 0 if none
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *Urrstruct) Reset() {
 	*x = Urrstruct{}
-	mi := &file_request_proto_msgTypes[14]
+	mi := &file_request_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -796,7 +1349,7 @@ func (x *Urrstruct) String() string {
 func (*Urrstruct) ProtoMessage() {}
 
 func (x *Urrstruct) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[14]
+	mi := &file_request_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -809,7 +1362,7 @@ func (x *Urrstruct) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Urrstruct.ProtoReflect.Descriptor instead.
 func (*Urrstruct) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{14}
+	return file_request_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *Urrstruct) GetUrrId() string {
@@ -826,30 +1379,77 @@ func (x *Urrstruct) GetFsied() string {
 	return ""
 }
 
-// IMSIStruct contains network type associations for an IMSI
-type IMSIStruct struct {
+func (x *Urrstruct) GetMeasurementMethod() []string {
+	if x != nil {
+		return x.MeasurementMethod
+	}
+	return nil
+}
+
+func (x *Urrstruct) GetReportingTriggers() []string {
+	if x != nil {
+		return x.ReportingTriggers
+	}
+	return nil
+}
+
+func (x *Urrstruct) GetVolumeThreshold() uint64 {
+	if x != nil {
+		return x.VolumeThreshold
+	}
+	return 0
+}
+
+func (x *Urrstruct) GetVolumeQuota() uint64 {
+	if x != nil {
+		return x.VolumeQuota
+	}
+	return 0
+}
+
+func (x *Urrstruct) GetTimeThreshold() uint32 {
+	if x != nil {
+		return x.TimeThreshold
+	}
+	return 0
+}
+
+func (x *Urrstruct) GetTimeQuota() uint32 {
+	if x != nil {
+		return x.TimeQuota
+	}
+	return 0
+}
+
+func (x *Urrstruct) GetMeasurementPeriod() uint32 {
+	if x != nil {
+		return x.MeasurementPeriod
+	}
+	return 0
+}
+
+// TableUsageRequest is empty as it doesn't need parameters
+type TableUsageRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Internet      string                 `protobuf:"bytes,1,opt,name=Internet,proto3" json:"Internet,omitempty"` // Internet service F-SEID
-	IMS           string                 `protobuf:"bytes,2,opt,name=IMS,proto3" json:"IMS,omitempty"`           // IMS service F-SEID
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *IMSIStruct) Reset() {
-	*x = IMSIStruct{}
-	mi := &file_request_proto_msgTypes[15]
+func (x *TableUsageRequest) Reset() {
+	*x = TableUsageRequest{}
+	mi := &file_request_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *IMSIStruct) String() string {
+func (x *TableUsageRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*IMSIStruct) ProtoMessage() {}
+func (*TableUsageRequest) ProtoMessage() {}
 
-func (x *IMSIStruct) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[15]
+func (x *TableUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -860,69 +1460,37 @@ func (x *IMSIStruct) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use IMSIStruct.ProtoReflect.Descriptor instead.
-func (*IMSIStruct) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{15}
-}
-
-func (x *IMSIStruct) GetInternet() string {
-	if x != nil {
-		return x.Internet
-	}
-	return ""
-}
-
-func (x *IMSIStruct) GetIMS() string {
-	if x != nil {
-		return x.IMS
-	}
-	return ""
+// Deprecated: Use TableUsageRequest.ProtoReflect.Descriptor instead.
+func (*TableUsageRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{17}
 }
 
-// UPFConfig defines the complete configuration for UPF
-type UPFConfig struct {
-	state                    protoimpl.MessageState `protogen:"open.v1"`
-	Mode                     string                 `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"`                                                                               // Operation mode
-	TableSizes               *TableSizes            `protobuf:"bytes,2,opt,name=table_sizes,json=tableSizes,proto3" json:"table_sizes,omitempty"`                                                 // Size configurations for various tables
-	LogLevel                 string                 `protobuf:"bytes,3,opt,name=log_level,json=logLevel,proto3" json:"log_level,omitempty"`                                                       // Logging level
-	Sim                      *SimConfig             `protobuf:"bytes,4,opt,name=sim,proto3" json:"sim,omitempty"`                                                                                 // Simulation configuration
-	Hwcksum                  bool                   `protobuf:"varint,5,opt,name=hwcksum,proto3" json:"hwcksum,omitempty"`                                                                        // Hardware checksum flag
-	Gtppsc                   bool                   `protobuf:"varint,6,opt,name=gtppsc,proto3" json:"gtppsc,omitempty"`                                                                          // GTPP service control flag
-	Ddp                      bool                   `protobuf:"varint,7,opt,name=ddp,proto3" json:"ddp,omitempty"`                                                                                // Dynamic Data Path flag
-	MeasureUpf               bool                   `protobuf:"varint,8,opt,name=measure_upf,json=measureUpf,proto3" json:"measure_upf,omitempty"`                                                // UPF measurement flag
-	MeasureFlow              bool                   `protobuf:"varint,9,opt,name=measure_flow,json=measureFlow,proto3" json:"measure_flow,omitempty"`                                             // Flow measurement flag
-	Access                   *Interface             `protobuf:"bytes,10,opt,name=access,proto3" json:"access,omitempty"`                                                                          // Access interface configuration
-	Core                     *Interface             `protobuf:"bytes,11,opt,name=core,proto3" json:"core,omitempty"`                                                                              // Core interface configuration
-	Workers                  int32                  `protobuf:"varint,12,opt,name=workers,proto3" json:"workers,omitempty"`                                                                       // Number of worker threads
-	MaxReqRetries            int32                  `protobuf:"varint,13,opt,name=max_req_retries,json=maxReqRetries,proto3" json:"max_req_retries,omitempty"`                                    // Maximum request retries
-	RespTimeout              string                 `protobuf:"bytes,14,opt,name=resp_timeout,json=respTimeout,proto3" json:"resp_timeout,omitempty"`                                             // Response timeout duration
-	EnableNtf                bool                   `protobuf:"varint,15,opt,name=enable_ntf,json=enableNtf,proto3" json:"enable_ntf,omitempty"`                                                  // Notification enable flag
-	EnableP4Rt               bool                   `protobuf:"varint,16,opt,name=enable_p4rt,json=enableP4rt,proto3" json:"enable_p4rt,omitempty"`                                               // P4 Runtime enable flag
-	EnableHbTimer            bool                   `protobuf:"varint,17,opt,name=enable_hbTimer,json=enableHbTimer,proto3" json:"enable_hbTimer,omitempty"`                                      // Heartbeat timer enable flag
-	EnableGtpuPathMonitoring bool                   `protobuf:"varint,18,opt,name=enable_gtpu_path_monitoring,json=enableGtpuPathMonitoring,proto3" json:"enable_gtpu_path_monitoring,omitempty"` // GTPU path monitoring flag
-	QciQosConfig             []*QoSConfig           `protobuf:"bytes,19,rep,name=qci_qos_config,json=qciQosConfig,proto3" json:"qci_qos_config,omitempty"`                                        // QoS configurations per QCI
-	SliceRateLimitConfig     *SliceRateLimit        `protobuf:"bytes,20,opt,name=slice_rate_limit_config,json=sliceRateLimitConfig,proto3" json:"slice_rate_limit_config,omitempty"`              // Slice rate limit configurations
-	Cpiface                  *CPInterface           `protobuf:"bytes,21,opt,name=cpiface,proto3" json:"cpiface,omitempty"`                                                                        // Control Plane interface configuration
-	P4Rtciface               *P4RTCInterface        `protobuf:"bytes,22,opt,name=p4rtciface,proto3" json:"p4rtciface,omitempty"`                                                                  // P4 Runtime Traffic Control interface configuration
-	unknownFields            protoimpl.UnknownFields
-	sizeCache                protoimpl.SizeCache
+// TableUsage reports the occupancy of a single lookup table
+type TableUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Table         string                 `protobuf:"bytes,1,opt,name=table,proto3" json:"table,omitempty"`        // Table name as used in table_sizes
+	Capacity      int64                  `protobuf:"varint,2,opt,name=capacity,proto3" json:"capacity,omitempty"` // Configured number of entries, 0 if unlimited
+	Used          int64                  `protobuf:"varint,3,opt,name=used,proto3" json:"used,omitempty"`         // Entries currently in use
+	Free          int64                  `protobuf:"varint,4,opt,name=free,proto3" json:"free,omitempty"`         // Entries still available
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UPFConfig) Reset() {
-	*x = UPFConfig{}
-	mi := &file_request_proto_msgTypes[16]
+func (x *TableUsage) Reset() {
+	*x = TableUsage{}
+	mi := &file_request_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UPFConfig) String() string {
+func (x *TableUsage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UPFConfig) ProtoMessage() {}
+func (*TableUsage) ProtoMessage() {}
 
-func (x *UPFConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[16]
+func (x *TableUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -933,192 +1501,3474 @@ func (x *UPFConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UPFConfig.ProtoReflect.Descriptor instead.
-func (*UPFConfig) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use TableUsage.ProtoReflect.Descriptor instead.
+func (*TableUsage) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *UPFConfig) GetMode() string {
+func (x *TableUsage) GetTable() string {
 	if x != nil {
-		return x.Mode
+		return x.Table
 	}
 	return ""
 }
 
-func (x *UPFConfig) GetTableSizes() *TableSizes {
+func (x *TableUsage) GetCapacity() int64 {
 	if x != nil {
-		return x.TableSizes
+		return x.Capacity
 	}
-	return nil
+	return 0
 }
 
-func (x *UPFConfig) GetLogLevel() string {
+func (x *TableUsage) GetUsed() int64 {
 	if x != nil {
-		return x.LogLevel
+		return x.Used
+	}
+	return 0
+}
+
+func (x *TableUsage) GetFree() int64 {
+	if x != nil {
+		return x.Free
+	}
+	return 0
+}
+
+// TableUsageReply contains the occupancy of every lookup table
+type TableUsageReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tables        []*TableUsage          `protobuf:"bytes,1,rep,name=tables,proto3" json:"tables,omitempty"` // Usage per table
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TableUsageReply) Reset() {
+	*x = TableUsageReply{}
+	mi := &file_request_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TableUsageReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TableUsageReply) ProtoMessage() {}
+
+func (x *TableUsageReply) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TableUsageReply.ProtoReflect.Descriptor instead.
+func (*TableUsageReply) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *TableUsageReply) GetTables() []*TableUsage {
+	if x != nil {
+		return x.Tables
+	}
+	return nil
+}
+
+// SessionFilter restricts an export; empty lists match everything
+type SessionFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fseid         []string               `protobuf:"bytes,1,rep,name=fseid,proto3" json:"fseid,omitempty"` // Only these F-SEIDs
+	Imsi          []string               `protobuf:"bytes,2,rep,name=imsi,proto3" json:"imsi,omitempty"`   // Only sessions of these IMSIs
+	Dnn           []string               `protobuf:"bytes,3,rep,name=dnn,proto3" json:"dnn,omitempty"`     // Only sessions of these DNNs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionFilter) Reset() {
+	*x = SessionFilter{}
+	mi := &file_request_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionFilter) ProtoMessage() {}
+
+func (x *SessionFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionFilter.ProtoReflect.Descriptor instead.
+func (*SessionFilter) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SessionFilter) GetFseid() []string {
+	if x != nil {
+		return x.Fseid
+	}
+	return nil
+}
+
+func (x *SessionFilter) GetImsi() []string {
+	if x != nil {
+		return x.Imsi
+	}
+	return nil
+}
+
+func (x *SessionFilter) GetDnn() []string {
+	if x != nil {
+		return x.Dnn
+	}
+	return nil
+}
+
+// SessionSnapshot is the serialized form of a set of sessions
+type SessionSnapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CreatedUnix   int64                  `protobuf:"varint,1,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"` // Export time in seconds since the epoch
+	Sessions      []*Rulestruct          `protobuf:"bytes,2,rep,name=sessions,proto3" json:"sessions,omitempty"`                           // Exported sessions
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionSnapshot) Reset() {
+	*x = SessionSnapshot{}
+	mi := &file_request_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionSnapshot) ProtoMessage() {}
+
+func (x *SessionSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionSnapshot.ProtoReflect.Descriptor instead.
+func (*SessionSnapshot) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SessionSnapshot) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+func (x *SessionSnapshot) GetSessions() []*Rulestruct {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+// ExportSessionsRequest selects the sessions and format of a snapshot
+type ExportSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *SessionFilter         `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`                             // Sessions to export
+	Format        SnapshotFormat         `protobuf:"varint,2,opt,name=format,proto3,enum=client.SnapshotFormat" json:"format,omitempty"` // Snapshot encoding
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportSessionsRequest) Reset() {
+	*x = ExportSessionsRequest{}
+	mi := &file_request_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportSessionsRequest) ProtoMessage() {}
+
+func (x *ExportSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ExportSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ExportSessionsRequest) GetFilter() *SessionFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ExportSessionsRequest) GetFormat() SnapshotFormat {
+	if x != nil {
+		return x.Format
+	}
+	return SnapshotFormat_SNAPSHOT_JSON
+}
+
+// ExportSessionsReply carries the encoded snapshot
+type ExportSessionsReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Snapshot      []byte                 `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`                         // Encoded SessionSnapshot
+	Format        SnapshotFormat         `protobuf:"varint,2,opt,name=format,proto3,enum=client.SnapshotFormat" json:"format,omitempty"` // Snapshot encoding
+	Count         int32                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`                              // Number of sessions in the snapshot
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportSessionsReply) Reset() {
+	*x = ExportSessionsReply{}
+	mi := &file_request_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportSessionsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportSessionsReply) ProtoMessage() {}
+
+func (x *ExportSessionsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportSessionsReply.ProtoReflect.Descriptor instead.
+func (*ExportSessionsReply) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ExportSessionsReply) GetSnapshot() []byte {
+	if x != nil {
+		return x.Snapshot
+	}
+	return nil
+}
+
+func (x *ExportSessionsReply) GetFormat() SnapshotFormat {
+	if x != nil {
+		return x.Format
+	}
+	return SnapshotFormat_SNAPSHOT_JSON
+}
+
+func (x *ExportSessionsReply) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// ImportSessionsRequest carries an encoded snapshot to load
+type ImportSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Snapshot      []byte                 `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`                         // Encoded SessionSnapshot
+	Format        SnapshotFormat         `protobuf:"varint,2,opt,name=format,proto3,enum=client.SnapshotFormat" json:"format,omitempty"` // Snapshot encoding
+	Mode          ImportMode             `protobuf:"varint,3,opt,name=mode,proto3,enum=client.ImportMode" json:"mode,omitempty"`         // How to combine with stored sessions
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportSessionsRequest) Reset() {
+	*x = ImportSessionsRequest{}
+	mi := &file_request_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportSessionsRequest) ProtoMessage() {}
+
+func (x *ImportSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ImportSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ImportSessionsRequest) GetSnapshot() []byte {
+	if x != nil {
+		return x.Snapshot
+	}
+	return nil
+}
+
+func (x *ImportSessionsRequest) GetFormat() SnapshotFormat {
+	if x != nil {
+		return x.Format
+	}
+	return SnapshotFormat_SNAPSHOT_JSON
+}
+
+func (x *ImportSessionsRequest) GetMode() ImportMode {
+	if x != nil {
+		return x.Mode
+	}
+	return ImportMode_IMPORT_MERGE
+}
+
+// ImportConflict describes a session that could not be imported
+type ImportConflict struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fseid         string                 `protobuf:"bytes,1,opt,name=fseid,proto3" json:"fseid,omitempty"`   // F-SEID of the session
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"` // Why it was not imported
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportConflict) Reset() {
+	*x = ImportConflict{}
+	mi := &file_request_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportConflict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportConflict) ProtoMessage() {}
+
+func (x *ImportConflict) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportConflict.ProtoReflect.Descriptor instead.
+func (*ImportConflict) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ImportConflict) GetFseid() string {
+	if x != nil {
+		return x.Fseid
+	}
+	return ""
+}
+
+func (x *ImportConflict) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// ImportSessionsReply summarizes an import
+type ImportSessionsReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Imported      int32                  `protobuf:"varint,1,opt,name=imported,proto3" json:"imported,omitempty"`   // Sessions written to the store
+	Unchanged     int32                  `protobuf:"varint,2,opt,name=unchanged,proto3" json:"unchanged,omitempty"` // Sessions already stored with identical rules
+	Removed       int32                  `protobuf:"varint,3,opt,name=removed,proto3" json:"removed,omitempty"`     // Sessions removed by a replace import
+	Conflicts     []*ImportConflict      `protobuf:"bytes,4,rep,name=conflicts,proto3" json:"conflicts,omitempty"`  // Sessions that were not imported
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportSessionsReply) Reset() {
+	*x = ImportSessionsReply{}
+	mi := &file_request_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportSessionsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportSessionsReply) ProtoMessage() {}
+
+func (x *ImportSessionsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportSessionsReply.ProtoReflect.Descriptor instead.
+func (*ImportSessionsReply) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ImportSessionsReply) GetImported() int32 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
+}
+
+func (x *ImportSessionsReply) GetUnchanged() int32 {
+	if x != nil {
+		return x.Unchanged
+	}
+	return 0
+}
+
+func (x *ImportSessionsReply) GetRemoved() int32 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+func (x *ImportSessionsReply) GetConflicts() []*ImportConflict {
+	if x != nil {
+		return x.Conflicts
+	}
+	return nil
+}
+
+// AuditEvent records a single mutation of the session rules
+type AuditEvent struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Seq               uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`                                                        // Position in the audit log, starting at 1
+	TimestampUnixNano int64                  `protobuf:"varint,2,opt,name=timestamp_unix_nano,json=timestampUnixNano,proto3" json:"timestamp_unix_nano,omitempty"` // Time of the change
+	Caller            string                 `protobuf:"bytes,3,opt,name=caller,proto3" json:"caller,omitempty"`                                                   // Caller identity taken from the gRPC metadata
+	Operation         string                 `protobuf:"bytes,4,opt,name=operation,proto3" json:"operation,omitempty"`                                             // RPC or procedure that made the change
+	Action            string                 `protobuf:"bytes,5,opt,name=action,proto3" json:"action,omitempty"`                                                   // create, update or delete
+	Fseid             string                 `protobuf:"bytes,6,opt,name=fseid,proto3" json:"fseid,omitempty"`                                                     // F-SEID of the changed session
+	Before            *Rulestruct            `protobuf:"bytes,7,opt,name=before,proto3" json:"before,omitempty"`                                                   // Rules before the change, unset on create
+	After             *Rulestruct            `protobuf:"bytes,8,opt,name=after,proto3" json:"after,omitempty"`                                                     // Rules after the change, unset on delete
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *AuditEvent) Reset() {
+	*x = AuditEvent{}
+	mi := &file_request_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEvent) ProtoMessage() {}
+
+func (x *AuditEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEvent.ProtoReflect.Descriptor instead.
+func (*AuditEvent) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *AuditEvent) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetTimestampUnixNano() int64 {
+	if x != nil {
+		return x.TimestampUnixNano
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetCaller() string {
+	if x != nil {
+		return x.Caller
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetFseid() string {
+	if x != nil {
+		return x.Fseid
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetBefore() *Rulestruct {
+	if x != nil {
+		return x.Before
+	}
+	return nil
+}
+
+func (x *AuditEvent) GetAfter() *Rulestruct {
+	if x != nil {
+		return x.After
+	}
+	return nil
+}
+
+// SessionHistoryRequest selects the session whose history is returned
+type SessionHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fseid         string                 `protobuf:"bytes,1,opt,name=fseid,proto3" json:"fseid,omitempty"` // F-SEID of the session
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionHistoryRequest) Reset() {
+	*x = SessionHistoryRequest{}
+	mi := &file_request_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionHistoryRequest) ProtoMessage() {}
+
+func (x *SessionHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionHistoryRequest.ProtoReflect.Descriptor instead.
+func (*SessionHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *SessionHistoryRequest) GetFseid() string {
+	if x != nil {
+		return x.Fseid
+	}
+	return ""
+}
+
+// AuditFilter restricts the listed audit events; empty fields match everything
+type AuditFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fseid         string                 `protobuf:"bytes,1,opt,name=fseid,proto3" json:"fseid,omitempty"`         // Only events of this session
+	Imsi          string                 `protobuf:"bytes,2,opt,name=imsi,proto3" json:"imsi,omitempty"`           // Only events of sessions of this IMSI
+	Caller        string                 `protobuf:"bytes,3,opt,name=caller,proto3" json:"caller,omitempty"`       // Only events made by this caller
+	Operation     string                 `protobuf:"bytes,4,opt,name=operation,proto3" json:"operation,omitempty"` // Only events made by this operation
+	Action        string                 `protobuf:"bytes,5,opt,name=action,proto3" json:"action,omitempty"`       // Only create, update or delete events
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditFilter) Reset() {
+	*x = AuditFilter{}
+	mi := &file_request_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditFilter) ProtoMessage() {}
+
+func (x *AuditFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditFilter.ProtoReflect.Descriptor instead.
+func (*AuditFilter) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *AuditFilter) GetFseid() string {
+	if x != nil {
+		return x.Fseid
+	}
+	return ""
+}
+
+func (x *AuditFilter) GetImsi() string {
+	if x != nil {
+		return x.Imsi
+	}
+	return ""
+}
+
+func (x *AuditFilter) GetCaller() string {
+	if x != nil {
+		return x.Caller
+	}
+	return ""
+}
+
+func (x *AuditFilter) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *AuditFilter) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+// ListAuditEventsRequest selects audit events by filter and time range
+type ListAuditEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *AuditFilter           `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`                                       // Event filter
+	StartUnixNano int64                  `protobuf:"varint,2,opt,name=start_unix_nano,json=startUnixNano,proto3" json:"start_unix_nano,omitempty"` // Inclusive start of the range, 0 for no bound
+	EndUnixNano   int64                  `protobuf:"varint,3,opt,name=end_unix_nano,json=endUnixNano,proto3" json:"end_unix_nano,omitempty"`       // Exclusive end of the range, 0 for no bound
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`                                        // Maximum number of events, 0 for no limit
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEventsRequest) Reset() {
+	*x = ListAuditEventsRequest{}
+	mi := &file_request_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEventsRequest) ProtoMessage() {}
+
+func (x *ListAuditEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListAuditEventsRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListAuditEventsRequest) GetFilter() *AuditFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ListAuditEventsRequest) GetStartUnixNano() int64 {
+	if x != nil {
+		return x.StartUnixNano
+	}
+	return 0
+}
+
+func (x *ListAuditEventsRequest) GetEndUnixNano() int64 {
+	if x != nil {
+		return x.EndUnixNano
+	}
+	return 0
+}
+
+func (x *ListAuditEventsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// AuditEventsReply contains audit events in log order
+type AuditEventsReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*AuditEvent          `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"` // Matching events
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditEventsReply) Reset() {
+	*x = AuditEventsReply{}
+	mi := &file_request_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditEventsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEventsReply) ProtoMessage() {}
+
+func (x *AuditEventsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEventsReply.ProtoReflect.Descriptor instead.
+func (*AuditEventsReply) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *AuditEventsReply) GetEvents() []*AuditEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+// P4FieldMatch is a match field of a UP4 table entry
+type P4FieldMatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`                                      // Match field name from the UP4 P4Info
+	Exact         []byte                 `protobuf:"bytes,2,opt,name=exact,proto3" json:"exact,omitempty"`                                      // Exact match value
+	LpmValue      []byte                 `protobuf:"bytes,3,opt,name=lpm_value,json=lpmValue,proto3" json:"lpm_value,omitempty"`                // LPM match value
+	LpmPrefixLen  int32                  `protobuf:"varint,4,opt,name=lpm_prefix_len,json=lpmPrefixLen,proto3" json:"lpm_prefix_len,omitempty"` // LPM prefix length
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *P4FieldMatch) Reset() {
+	*x = P4FieldMatch{}
+	mi := &file_request_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *P4FieldMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*P4FieldMatch) ProtoMessage() {}
+
+func (x *P4FieldMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use P4FieldMatch.ProtoReflect.Descriptor instead.
+func (*P4FieldMatch) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *P4FieldMatch) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *P4FieldMatch) GetExact() []byte {
+	if x != nil {
+		return x.Exact
+	}
+	return nil
+}
+
+func (x *P4FieldMatch) GetLpmValue() []byte {
+	if x != nil {
+		return x.LpmValue
+	}
+	return nil
+}
+
+func (x *P4FieldMatch) GetLpmPrefixLen() int32 {
+	if x != nil {
+		return x.LpmPrefixLen
+	}
+	return 0
+}
+
+// P4ActionParam is a parameter of a UP4 action
+type P4ActionParam struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Param         string                 `protobuf:"bytes,1,opt,name=param,proto3" json:"param,omitempty"` // Parameter name from the UP4 P4Info
+	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"` // Canonical binary value
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *P4ActionParam) Reset() {
+	*x = P4ActionParam{}
+	mi := &file_request_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *P4ActionParam) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*P4ActionParam) ProtoMessage() {}
+
+func (x *P4ActionParam) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use P4ActionParam.ProtoReflect.Descriptor instead.
+func (*P4ActionParam) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *P4ActionParam) GetParam() string {
+	if x != nil {
+		return x.Param
+	}
+	return ""
+}
+
+func (x *P4ActionParam) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+// P4Action is the action of a UP4 table entry
+type P4Action struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"` // Action name from the UP4 P4Info
+	Params        []*P4ActionParam       `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty"` // Action parameters
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *P4Action) Reset() {
+	*x = P4Action{}
+	mi := &file_request_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *P4Action) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*P4Action) ProtoMessage() {}
+
+func (x *P4Action) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use P4Action.ProtoReflect.Descriptor instead.
+func (*P4Action) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *P4Action) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *P4Action) GetParams() []*P4ActionParam {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+// P4TableEntry mirrors a P4Runtime TableEntry with names instead of P4Info IDs.
+// It is a name-based preview: the names must be resolved to the IDs of the
+// switch's P4Info before the entry can be written with P4Runtime.
+type P4TableEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Table         string                 `protobuf:"bytes,1,opt,name=table,proto3" json:"table,omitempty"`   // Table name from the UP4 P4Info
+	Match         []*P4FieldMatch        `protobuf:"bytes,2,rep,name=match,proto3" json:"match,omitempty"`   // Match fields
+	Action        *P4Action              `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"` // Action to apply
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *P4TableEntry) Reset() {
+	*x = P4TableEntry{}
+	mi := &file_request_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *P4TableEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*P4TableEntry) ProtoMessage() {}
+
+func (x *P4TableEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use P4TableEntry.ProtoReflect.Descriptor instead.
+func (*P4TableEntry) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *P4TableEntry) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+func (x *P4TableEntry) GetMatch() []*P4FieldMatch {
+	if x != nil {
+		return x.Match
+	}
+	return nil
+}
+
+func (x *P4TableEntry) GetAction() *P4Action {
+	if x != nil {
+		return x.Action
+	}
+	return nil
+}
+
+// P4Update mirrors a P4Runtime Update of a table entry
+type P4Update struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                               // INSERT, MODIFY or DELETE
+	TableEntry    *P4TableEntry          `protobuf:"bytes,2,opt,name=table_entry,json=tableEntry,proto3" json:"table_entry,omitempty"` // Entry to write
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *P4Update) Reset() {
+	*x = P4Update{}
+	mi := &file_request_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *P4Update) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*P4Update) ProtoMessage() {}
+
+func (x *P4Update) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use P4Update.ProtoReflect.Descriptor instead.
+func (*P4Update) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *P4Update) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *P4Update) GetTableEntry() *P4TableEntry {
+	if x != nil {
+		return x.TableEntry
+	}
+	return nil
+}
+
+// P4WriteRequest mirrors a P4Runtime WriteRequest
+type P4WriteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Updates       []*P4Update            `protobuf:"bytes,1,rep,name=updates,proto3" json:"updates,omitempty"` // Updates in write order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *P4WriteRequest) Reset() {
+	*x = P4WriteRequest{}
+	mi := &file_request_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *P4WriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*P4WriteRequest) ProtoMessage() {}
+
+func (x *P4WriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use P4WriteRequest.ProtoReflect.Descriptor instead.
+func (*P4WriteRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *P4WriteRequest) GetUpdates() []*P4Update {
+	if x != nil {
+		return x.Updates
+	}
+	return nil
+}
+
+// CompileP4Request selects the sessions to compile
+type CompileP4Request struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fseid         []string               `protobuf:"bytes,1,rep,name=fseid,proto3" json:"fseid,omitempty"` // Sessions to compile, empty for all
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompileP4Request) Reset() {
+	*x = CompileP4Request{}
+	mi := &file_request_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompileP4Request) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompileP4Request) ProtoMessage() {}
+
+func (x *CompileP4Request) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompileP4Request.ProtoReflect.Descriptor instead.
+func (*CompileP4Request) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *CompileP4Request) GetFseid() []string {
+	if x != nil {
+		return x.Fseid
+	}
+	return nil
+}
+
+// CompileP4Reply contains the generated table entries
+type CompileP4Reply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Write         *P4WriteRequest        `protobuf:"bytes,1,opt,name=write,proto3" json:"write,omitempty"`         // Generated updates
+	Prototext     string                 `protobuf:"bytes,2,opt,name=prototext,proto3" json:"prototext,omitempty"` // Deterministic text rendering of write
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompileP4Reply) Reset() {
+	*x = CompileP4Reply{}
+	mi := &file_request_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompileP4Reply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompileP4Reply) ProtoMessage() {}
+
+func (x *CompileP4Reply) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompileP4Reply.ProtoReflect.Descriptor instead.
+func (*CompileP4Reply) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *CompileP4Reply) GetWrite() *P4WriteRequest {
+	if x != nil {
+		return x.Write
+	}
+	return nil
+}
+
+func (x *CompileP4Reply) GetPrototext() string {
+	if x != nil {
+		return x.Prototext
+	}
+	return ""
+}
+
+// IMSIStruct contains network type associations for an IMSI
+type IMSIStruct struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Internet      string                 `protobuf:"bytes,1,opt,name=Internet,proto3" json:"Internet,omitempty"` // Internet service F-SEID
+	IMS           string                 `protobuf:"bytes,2,opt,name=IMS,proto3" json:"IMS,omitempty"`           // IMS service F-SEID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IMSIStruct) Reset() {
+	*x = IMSIStruct{}
+	mi := &file_request_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IMSIStruct) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IMSIStruct) ProtoMessage() {}
+
+func (x *IMSIStruct) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IMSIStruct.ProtoReflect.Descriptor instead.
+func (*IMSIStruct) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *IMSIStruct) GetInternet() string {
+	if x != nil {
+		return x.Internet
+	}
+	return ""
+}
+
+func (x *IMSIStruct) GetIMS() string {
+	if x != nil {
+		return x.IMS
+	}
+	return ""
+}
+
+// UPFConfig defines the complete configuration for UPF
+type UPFConfig struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	Mode                     string                 `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"`                                                                               // Operation mode
+	TableSizes               *TableSizes            `protobuf:"bytes,2,opt,name=table_sizes,json=tableSizes,proto3" json:"table_sizes,omitempty"`                                                 // Size configurations for various tables
+	LogLevel                 string                 `protobuf:"bytes,3,opt,name=log_level,json=logLevel,proto3" json:"log_level,omitempty"`                                                       // Logging level
+	Sim                      *SimConfig             `protobuf:"bytes,4,opt,name=sim,proto3" json:"sim,omitempty"`                                                                                 // Simulation configuration
+	Hwcksum                  bool                   `protobuf:"varint,5,opt,name=hwcksum,proto3" json:"hwcksum,omitempty"`                                                                        // Hardware checksum flag
+	Gtppsc                   bool                   `protobuf:"varint,6,opt,name=gtppsc,proto3" json:"gtppsc,omitempty"`                                                                          // GTPP service control flag
+	Ddp                      bool                   `protobuf:"varint,7,opt,name=ddp,proto3" json:"ddp,omitempty"`                                                                                // Dynamic Data Path flag
+	MeasureUpf               bool                   `protobuf:"varint,8,opt,name=measure_upf,json=measureUpf,proto3" json:"measure_upf,omitempty"`                                                // UPF measurement flag
+	MeasureFlow              bool                   `protobuf:"varint,9,opt,name=measure_flow,json=measureFlow,proto3" json:"measure_flow,omitempty"`                                             // Flow measurement flag
+	Access                   *Interface             `protobuf:"bytes,10,opt,name=access,proto3" json:"access,omitempty"`                                                                          // Access interface configuration
+	Core                     *Interface             `protobuf:"bytes,11,opt,name=core,proto3" json:"core,omitempty"`                                                                              // Core interface configuration
+	Workers                  int32                  `protobuf:"varint,12,opt,name=workers,proto3" json:"workers,omitempty"`                                                                       // Number of worker threads
+	MaxReqRetries            int32                  `protobuf:"varint,13,opt,name=max_req_retries,json=maxReqRetries,proto3" json:"max_req_retries,omitempty"`                                    // Maximum request retries
+	RespTimeout              string                 `protobuf:"bytes,14,opt,name=resp_timeout,json=respTimeout,proto3" json:"resp_timeout,omitempty"`                                             // Response timeout duration
+	EnableNtf                bool                   `protobuf:"varint,15,opt,name=enable_ntf,json=enableNtf,proto3" json:"enable_ntf,omitempty"`                                                  // Notification enable flag
+	EnableP4Rt               bool                   `protobuf:"varint,16,opt,name=enable_p4rt,json=enableP4rt,proto3" json:"enable_p4rt,omitempty"`                                               // P4 Runtime enable flag; CompileP4Rules needs it
+	EnableHbTimer            bool                   `protobuf:"varint,17,opt,name=enable_hbTimer,json=enableHbTimer,proto3" json:"enable_hbTimer,omitempty"`                                      // Heartbeat timer enable flag
+	EnableGtpuPathMonitoring bool                   `protobuf:"varint,18,opt,name=enable_gtpu_path_monitoring,json=enableGtpuPathMonitoring,proto3" json:"enable_gtpu_path_monitoring,omitempty"` // GTPU path monitoring flag
+	QciQosConfig             []*QoSConfig           `protobuf:"bytes,19,rep,name=qci_qos_config,json=qciQosConfig,proto3" json:"qci_qos_config,omitempty"`                                        // QoS configurations per QCI
+	SliceRateLimitConfig     *SliceRateLimit        `protobuf:"bytes,20,opt,name=slice_rate_limit_config,json=sliceRateLimitConfig,proto3" json:"slice_rate_limit_config,omitempty"`              // Slice rate limit configurations
+	Cpiface                  *CPInterface           `protobuf:"bytes,21,opt,name=cpiface,proto3" json:"cpiface,omitempty"`                                                                        // Control Plane interface configuration
+	P4Rtciface               *P4RTCInterface        `protobuf:"bytes,22,opt,name=p4rtciface,proto3" json:"p4rtciface,omitempty"`                                                                  // P4 Runtime Traffic Control interface configuration
+	FlowHistory              *FlowHistoryConfig     `protobuf:"bytes,23,opt,name=flow_history,json=flowHistory,proto3" json:"flow_history,omitempty"`                                             // Flow history kept by the PFCP agent
+	Alerts                   *AlertsConfig          `protobuf:"bytes,24,opt,name=alerts,proto3" json:"alerts,omitempty"`                                                                          // Alert rules evaluated by the PFCP agent
+	Metrics                  *MetricsConfig         `protobuf:"bytes,25,opt,name=metrics,proto3" json:"metrics,omitempty"`                                                                        // Prometheus metrics endpoint
+	Ipfix                    *IPFIXConfig           `protobuf:"bytes,26,opt,name=ipfix,proto3" json:"ipfix,omitempty"`                                                                            // Export of flow records to an IPFIX collector
+	Recorder                 *RecorderConfig        `protobuf:"bytes,27,opt,name=recorder,proto3" json:"recorder,omitempty"`                                                                      // Files flow statistics are recorded to
+	DataDir                  string                 `protobuf:"bytes,28,opt,name=data_dir,json=dataDir,proto3" json:"data_dir,omitempty"`                                                         // Directory of the files the agents keep
+	HeartBeatInterval        string                 `protobuf:"bytes,29,opt,name=heart_beat_interval,json=heartBeatInterval,proto3" json:"heart_beat_interval,omitempty"`                         // Interval between heartbeats to peers
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *UPFConfig) Reset() {
+	*x = UPFConfig{}
+	mi := &file_request_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UPFConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UPFConfig) ProtoMessage() {}
+
+func (x *UPFConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UPFConfig.ProtoReflect.Descriptor instead.
+func (*UPFConfig) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *UPFConfig) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *UPFConfig) GetTableSizes() *TableSizes {
+	if x != nil {
+		return x.TableSizes
+	}
+	return nil
+}
+
+func (x *UPFConfig) GetLogLevel() string {
+	if x != nil {
+		return x.LogLevel
+	}
+	return ""
+}
+
+func (x *UPFConfig) GetSim() *SimConfig {
+	if x != nil {
+		return x.Sim
+	}
+	return nil
+}
+
+func (x *UPFConfig) GetHwcksum() bool {
+	if x != nil {
+		return x.Hwcksum
+	}
+	return false
+}
+
+func (x *UPFConfig) GetGtppsc() bool {
+	if x != nil {
+		return x.Gtppsc
+	}
+	return false
+}
+
+func (x *UPFConfig) GetDdp() bool {
+	if x != nil {
+		return x.Ddp
+	}
+	return false
+}
+
+func (x *UPFConfig) GetMeasureUpf() bool {
+	if x != nil {
+		return x.MeasureUpf
+	}
+	return false
+}
+
+func (x *UPFConfig) GetMeasureFlow() bool {
+	if x != nil {
+		return x.MeasureFlow
+	}
+	return false
+}
+
+func (x *UPFConfig) GetAccess() *Interface {
+	if x != nil {
+		return x.Access
+	}
+	return nil
+}
+
+func (x *UPFConfig) GetCore() *Interface {
+	if x != nil {
+		return x.Core
+	}
+	return nil
+}
+
+func (x *UPFConfig) GetWorkers() int32 {
+	if x != nil {
+		return x.Workers
+	}
+	return 0
+}
+
+func (x *UPFConfig) GetMaxReqRetries() int32 {
+	if x != nil {
+		return x.MaxReqRetries
+	}
+	return 0
+}
+
+func (x *UPFConfig) GetRespTimeout() string {
+	if x != nil {
+		return x.RespTimeout
+	}
+	return ""
+}
+
+func (x *UPFConfig) GetEnableNtf() bool {
+	if x != nil {
+		return x.EnableNtf
+	}
+	return false
+}
+
+func (x *UPFConfig) GetEnableP4Rt() bool {
+	if x != nil {
+		return x.EnableP4Rt
+	}
+	return false
+}
+
+func (x *UPFConfig) GetEnableHbTimer() bool {
+	if x != nil {
+		return x.EnableHbTimer
+	}
+	return false
+}
+
+func (x *UPFConfig) GetEnableGtpuPathMonitoring() bool {
+	if x != nil {
+		return x.EnableGtpuPathMonitoring
+	}
+	return false
+}
+
+func (x *UPFConfig) GetQciQosConfig() []*QoSConfig {
+	if x != nil {
+		return x.QciQosConfig
+	}
+	return nil
+}
+
+func (x *UPFConfig) GetSliceRateLimitConfig() *SliceRateLimit {
+	if x != nil {
+		return x.SliceRateLimitConfig
+	}
+	return nil
+}
+
+func (x *UPFConfig) GetCpiface() *CPInterface {
+	if x != nil {
+		return x.Cpiface
+	}
+	return nil
+}
+
+func (x *UPFConfig) GetP4Rtciface() *P4RTCInterface {
+	if x != nil {
+		return x.P4Rtciface
+	}
+	return nil
+}
+
+func (x *UPFConfig) GetFlowHistory() *FlowHistoryConfig {
+	if x != nil {
+		return x.FlowHistory
+	}
+	return nil
+}
+
+func (x *UPFConfig) GetAlerts() *AlertsConfig {
+	if x != nil {
+		return x.Alerts
+	}
+	return nil
+}
+
+func (x *UPFConfig) GetMetrics() *MetricsConfig {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *UPFConfig) GetIpfix() *IPFIXConfig {
+	if x != nil {
+		return x.Ipfix
+	}
+	return nil
+}
+
+func (x *UPFConfig) GetRecorder() *RecorderConfig {
+	if x != nil {
+		return x.Recorder
+	}
+	return nil
+}
+
+func (x *UPFConfig) GetDataDir() string {
+	if x != nil {
+		return x.DataDir
+	}
+	return ""
+}
+
+func (x *UPFConfig) GetHeartBeatInterval() string {
+	if x != nil {
+		return x.HeartBeatInterval
+	}
+	return ""
+}
+
+// TableSizes defines the size configuration for various lookup tables in the UPF
+type TableSizes struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	PdrLookup        int32                  `protobuf:"varint,1,opt,name=pdrLookup,proto3" json:"pdrLookup,omitempty"`               // Size of the PDR lookup table
+	FlowMeasure      int32                  `protobuf:"varint,2,opt,name=flowMeasure,proto3" json:"flowMeasure,omitempty"`           // Size of the flow measurement table
+	AppQERLookup     int32                  `protobuf:"varint,3,opt,name=appQERLookup,proto3" json:"appQERLookup,omitempty"`         // Size of the application QER lookup table
+	SessionQERLookup int32                  `protobuf:"varint,4,opt,name=sessionQERLookup,proto3" json:"sessionQERLookup,omitempty"` // Size of the session QER lookup table
+	FarLookup        int32                  `protobuf:"varint,5,opt,name=farLookup,proto3" json:"farLookup,omitempty"`               // Size of the FAR lookup table
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *TableSizes) Reset() {
+	*x = TableSizes{}
+	mi := &file_request_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TableSizes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TableSizes) ProtoMessage() {}
+
+func (x *TableSizes) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TableSizes.ProtoReflect.Descriptor instead.
+func (*TableSizes) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *TableSizes) GetPdrLookup() int32 {
+	if x != nil {
+		return x.PdrLookup
+	}
+	return 0
+}
+
+func (x *TableSizes) GetFlowMeasure() int32 {
+	if x != nil {
+		return x.FlowMeasure
+	}
+	return 0
+}
+
+func (x *TableSizes) GetAppQERLookup() int32 {
+	if x != nil {
+		return x.AppQERLookup
+	}
+	return 0
+}
+
+func (x *TableSizes) GetSessionQERLookup() int32 {
+	if x != nil {
+		return x.SessionQERLookup
+	}
+	return 0
+}
+
+func (x *TableSizes) GetFarLookup() int32 {
+	if x != nil {
+		return x.FarLookup
+	}
+	return 0
+}
+
+// SimConfig defines the simulation parameters for the UPF
+type SimConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Core          string                 `protobuf:"bytes,1,opt,name=core,proto3" json:"core,omitempty"`                                       // Core network IP address
+	MaxSessions   int32                  `protobuf:"varint,2,opt,name=max_sessions,json=maxSessions,proto3" json:"max_sessions,omitempty"`     // Maximum number of sessions
+	StartUeIp     string                 `protobuf:"bytes,3,opt,name=start_ue_ip,json=startUeIp,proto3" json:"start_ue_ip,omitempty"`          // Starting UE IP address
+	StartEnbIp    string                 `protobuf:"bytes,4,opt,name=start_enb_ip,json=startEnbIp,proto3" json:"start_enb_ip,omitempty"`       // Starting eNB IP address
+	StartAupfIp   string                 `protobuf:"bytes,5,opt,name=start_aupf_ip,json=startAupfIp,proto3" json:"start_aupf_ip,omitempty"`    // Starting AUPF IP address
+	N6AppIp       string                 `protobuf:"bytes,6,opt,name=n6_app_ip,json=n6AppIp,proto3" json:"n6_app_ip,omitempty"`                // N6 application IP address
+	N9AppIp       string                 `protobuf:"bytes,7,opt,name=n9_app_ip,json=n9AppIp,proto3" json:"n9_app_ip,omitempty"`                // N9 application IP address
+	StartN3Teid   string                 `protobuf:"bytes,8,opt,name=start_n3_teid,json=startN3Teid,proto3" json:"start_n3_teid,omitempty"`    // Starting N3 TEID
+	StartN9Teid   string                 `protobuf:"bytes,9,opt,name=start_n9_teid,json=startN9Teid,proto3" json:"start_n9_teid,omitempty"`    // Starting N9 TEID
+	UplinkMbr     int32                  `protobuf:"varint,10,opt,name=uplink_mbr,json=uplinkMbr,proto3" json:"uplink_mbr,omitempty"`          // Uplink maximum bit rate
+	UplinkGbr     int32                  `protobuf:"varint,11,opt,name=uplink_gbr,json=uplinkGbr,proto3" json:"uplink_gbr,omitempty"`          // Uplink guaranteed bit rate
+	DownlinkMbr   int32                  `protobuf:"varint,12,opt,name=downlink_mbr,json=downlinkMbr,proto3" json:"downlink_mbr,omitempty"`    // Downlink maximum bit rate
+	DownlinkGbr   int32                  `protobuf:"varint,13,opt,name=downlink_gbr,json=downlinkGbr,proto3" json:"downlink_gbr,omitempty"`    // Downlink guaranteed bit rate
+	PktSize       int32                  `protobuf:"varint,14,opt,name=pkt_size,json=pktSize,proto3" json:"pkt_size,omitempty"`                // Packet size
+	TotalFlows    int32                  `protobuf:"varint,15,opt,name=total_flows,json=totalFlows,proto3" json:"total_flows,omitempty"`       // Total number of flows
+	Seed          int64                  `protobuf:"varint,16,opt,name=seed,proto3" json:"seed,omitempty"`                                     // Seed of the traffic simulator
+	UplinkAmbr    int32                  `protobuf:"varint,17,opt,name=uplink_ambr,json=uplinkAmbr,proto3" json:"uplink_ambr,omitempty"`       // Uplink session AMBR, 0 for none
+	DownlinkAmbr  int32                  `protobuf:"varint,18,opt,name=downlink_ambr,json=downlinkAmbr,proto3" json:"downlink_ambr,omitempty"` // Downlink session AMBR, 0 for none
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimConfig) Reset() {
+	*x = SimConfig{}
+	mi := &file_request_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimConfig) ProtoMessage() {}
+
+func (x *SimConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimConfig.ProtoReflect.Descriptor instead.
+func (*SimConfig) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *SimConfig) GetCore() string {
+	if x != nil {
+		return x.Core
+	}
+	return ""
+}
+
+func (x *SimConfig) GetMaxSessions() int32 {
+	if x != nil {
+		return x.MaxSessions
+	}
+	return 0
+}
+
+func (x *SimConfig) GetStartUeIp() string {
+	if x != nil {
+		return x.StartUeIp
+	}
+	return ""
+}
+
+func (x *SimConfig) GetStartEnbIp() string {
+	if x != nil {
+		return x.StartEnbIp
+	}
+	return ""
+}
+
+func (x *SimConfig) GetStartAupfIp() string {
+	if x != nil {
+		return x.StartAupfIp
+	}
+	return ""
+}
+
+func (x *SimConfig) GetN6AppIp() string {
+	if x != nil {
+		return x.N6AppIp
+	}
+	return ""
+}
+
+func (x *SimConfig) GetN9AppIp() string {
+	if x != nil {
+		return x.N9AppIp
+	}
+	return ""
+}
+
+func (x *SimConfig) GetStartN3Teid() string {
+	if x != nil {
+		return x.StartN3Teid
+	}
+	return ""
+}
+
+func (x *SimConfig) GetStartN9Teid() string {
+	if x != nil {
+		return x.StartN9Teid
+	}
+	return ""
+}
+
+func (x *SimConfig) GetUplinkMbr() int32 {
+	if x != nil {
+		return x.UplinkMbr
+	}
+	return 0
+}
+
+func (x *SimConfig) GetUplinkGbr() int32 {
+	if x != nil {
+		return x.UplinkGbr
+	}
+	return 0
+}
+
+func (x *SimConfig) GetDownlinkMbr() int32 {
+	if x != nil {
+		return x.DownlinkMbr
+	}
+	return 0
+}
+
+func (x *SimConfig) GetDownlinkGbr() int32 {
+	if x != nil {
+		return x.DownlinkGbr
+	}
+	return 0
+}
+
+func (x *SimConfig) GetPktSize() int32 {
+	if x != nil {
+		return x.PktSize
+	}
+	return 0
+}
+
+func (x *SimConfig) GetTotalFlows() int32 {
+	if x != nil {
+		return x.TotalFlows
+	}
+	return 0
+}
+
+func (x *SimConfig) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *SimConfig) GetUplinkAmbr() int32 {
+	if x != nil {
+		return x.UplinkAmbr
+	}
+	return 0
+}
+
+func (x *SimConfig) GetDownlinkAmbr() int32 {
+	if x != nil {
+		return x.DownlinkAmbr
+	}
+	return 0
+}
+
+// Interface defines the configuration for a network interface
+type Interface struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ifname        string                 `protobuf:"bytes,1,opt,name=ifname,proto3" json:"ifname,omitempty"` // Interface name
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Interface) Reset() {
+	*x = Interface{}
+	mi := &file_request_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Interface) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Interface) ProtoMessage() {}
+
+func (x *Interface) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Interface.ProtoReflect.Descriptor instead.
+func (*Interface) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *Interface) GetIfname() string {
+	if x != nil {
+		return x.Ifname
+	}
+	return ""
+}
+
+// QoSConfig defines the Quality of Service parameters for a specific QCI
+type QoSConfig struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Qci             int32                  `protobuf:"varint,1,opt,name=qci,proto3" json:"qci,omitempty"`                                                  // QoS Class Identifier
+	Cbs             int32                  `protobuf:"varint,2,opt,name=cbs,proto3" json:"cbs,omitempty"`                                                  // Committed Burst Size
+	Ebs             int32                  `protobuf:"varint,3,opt,name=ebs,proto3" json:"ebs,omitempty"`                                                  // Excess Burst Size
+	Pbs             int32                  `protobuf:"varint,4,opt,name=pbs,proto3" json:"pbs,omitempty"`                                                  // Peak Data Rate
+	BurstDurationMs int32                  `protobuf:"varint,5,opt,name=burst_duration_ms,json=burstDurationMs,proto3" json:"burst_duration_ms,omitempty"` // Burst duration in milliseconds
+	Priority        int32                  `protobuf:"varint,6,opt,name=priority,proto3" json:"priority,omitempty"`                                        // Scheduling priority
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *QoSConfig) Reset() {
+	*x = QoSConfig{}
+	mi := &file_request_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QoSConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QoSConfig) ProtoMessage() {}
+
+func (x *QoSConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QoSConfig.ProtoReflect.Descriptor instead.
+func (*QoSConfig) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *QoSConfig) GetQci() int32 {
+	if x != nil {
+		return x.Qci
+	}
+	return 0
+}
+
+func (x *QoSConfig) GetCbs() int32 {
+	if x != nil {
+		return x.Cbs
+	}
+	return 0
+}
+
+func (x *QoSConfig) GetEbs() int32 {
+	if x != nil {
+		return x.Ebs
+	}
+	return 0
+}
+
+func (x *QoSConfig) GetPbs() int32 {
+	if x != nil {
+		return x.Pbs
+	}
+	return 0
+}
+
+func (x *QoSConfig) GetBurstDurationMs() int32 {
+	if x != nil {
+		return x.BurstDurationMs
+	}
+	return 0
+}
+
+func (x *QoSConfig) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+// SliceRateLimit defines the rate limiting parameters for a slice
+type SliceRateLimit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	N6Bps         int32                  `protobuf:"varint,1,opt,name=n6_bps,json=n6Bps,proto3" json:"n6_bps,omitempty"`                        // N6 (downlink) rate limit in bits per second, 0 for none
+	N6BurstBytes  int32                  `protobuf:"varint,2,opt,name=n6_burst_bytes,json=n6BurstBytes,proto3" json:"n6_burst_bytes,omitempty"` // N6 burst size in bytes
+	N3Bps         int32                  `protobuf:"varint,3,opt,name=n3_bps,json=n3Bps,proto3" json:"n3_bps,omitempty"`                        // N3 (uplink) rate limit in bits per second, 0 for none
+	N3BurstBytes  int32                  `protobuf:"varint,4,opt,name=n3_burst_bytes,json=n3BurstBytes,proto3" json:"n3_burst_bytes,omitempty"` // N3 burst size in bytes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SliceRateLimit) Reset() {
+	*x = SliceRateLimit{}
+	mi := &file_request_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SliceRateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SliceRateLimit) ProtoMessage() {}
+
+func (x *SliceRateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SliceRateLimit.ProtoReflect.Descriptor instead.
+func (*SliceRateLimit) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *SliceRateLimit) GetN6Bps() int32 {
+	if x != nil {
+		return x.N6Bps
+	}
+	return 0
+}
+
+func (x *SliceRateLimit) GetN6BurstBytes() int32 {
+	if x != nil {
+		return x.N6BurstBytes
+	}
+	return 0
+}
+
+func (x *SliceRateLimit) GetN3Bps() int32 {
+	if x != nil {
+		return x.N3Bps
+	}
+	return 0
+}
+
+func (x *SliceRateLimit) GetN3BurstBytes() int32 {
+	if x != nil {
+		return x.N3BurstBytes
+	}
+	return 0
+}
+
+// CPInterface defines the configuration for the Control Plane interface
+type CPInterface struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Peers           []string               `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`                                                 // List of peer addresses
+	Dnn             string                 `protobuf:"bytes,2,opt,name=dnn,proto3" json:"dnn,omitempty"`                                                     // Data Network Name
+	HttpPort        string                 `protobuf:"bytes,3,opt,name=http_port,json=httpPort,proto3" json:"http_port,omitempty"`                           // HTTP port for communication
+	EnableUeIpAlloc bool                   `protobuf:"varint,4,opt,name=enable_ue_ip_alloc,json=enableUeIpAlloc,proto3" json:"enable_ue_ip_alloc,omitempty"` // Flag to enable UE IP allocation
+	UeIpPool        string                 `protobuf:"bytes,5,opt,name=ue_ip_pool,json=ueIpPool,proto3" json:"ue_ip_pool,omitempty"`                         // UE IP address pool
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CPInterface) Reset() {
+	*x = CPInterface{}
+	mi := &file_request_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CPInterface) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CPInterface) ProtoMessage() {}
+
+func (x *CPInterface) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CPInterface.ProtoReflect.Descriptor instead.
+func (*CPInterface) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *CPInterface) GetPeers() []string {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+func (x *CPInterface) GetDnn() string {
+	if x != nil {
+		return x.Dnn
+	}
+	return ""
+}
+
+func (x *CPInterface) GetHttpPort() string {
+	if x != nil {
+		return x.HttpPort
+	}
+	return ""
+}
+
+func (x *CPInterface) GetEnableUeIpAlloc() bool {
+	if x != nil {
+		return x.EnableUeIpAlloc
+	}
+	return false
+}
+
+func (x *CPInterface) GetUeIpPool() string {
+	if x != nil {
+		return x.UeIpPool
+	}
+	return ""
+}
+
+// P4RTCInterface defines the configuration for the P4 Runtime Traffic Control interface
+type P4RTCInterface struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	AccessIp            string                 `protobuf:"bytes,1,opt,name=access_ip,json=accessIp,proto3" json:"access_ip,omitempty"`                                       // Access IP address
+	P4RtcServer         string                 `protobuf:"bytes,2,opt,name=p4rtc_server,json=p4rtcServer,proto3" json:"p4rtc_server,omitempty"`                              // P4 Runtime server address
+	P4RtcPort           string                 `protobuf:"bytes,3,opt,name=p4rtc_port,json=p4rtcPort,proto3" json:"p4rtc_port,omitempty"`                                    // P4 Runtime server port
+	SliceId             int32                  `protobuf:"varint,4,opt,name=slice_id,json=sliceId,proto3" json:"slice_id,omitempty"`                                         // Slice identifier
+	DefaultTc           int32                  `protobuf:"varint,5,opt,name=default_tc,json=defaultTc,proto3" json:"default_tc,omitempty"`                                   // Default traffic class
+	ClearStateOnRestart bool                   `protobuf:"varint,6,opt,name=clear_state_on_restart,json=clearStateOnRestart,proto3" json:"clear_state_on_restart,omitempty"` // Flag to clear state on restart
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *P4RTCInterface) Reset() {
+	*x = P4RTCInterface{}
+	mi := &file_request_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *P4RTCInterface) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*P4RTCInterface) ProtoMessage() {}
+
+func (x *P4RTCInterface) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use P4RTCInterface.ProtoReflect.Descriptor instead.
+func (*P4RTCInterface) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *P4RTCInterface) GetAccessIp() string {
+	if x != nil {
+		return x.AccessIp
+	}
+	return ""
+}
+
+func (x *P4RTCInterface) GetP4RtcServer() string {
+	if x != nil {
+		return x.P4RtcServer
+	}
+	return ""
+}
+
+func (x *P4RTCInterface) GetP4RtcPort() string {
+	if x != nil {
+		return x.P4RtcPort
+	}
+	return ""
+}
+
+func (x *P4RTCInterface) GetSliceId() int32 {
+	if x != nil {
+		return x.SliceId
+	}
+	return 0
+}
+
+func (x *P4RTCInterface) GetDefaultTc() int32 {
+	if x != nil {
+		return x.DefaultTc
+	}
+	return 0
+}
+
+func (x *P4RTCInterface) GetClearStateOnRestart() bool {
+	if x != nil {
+		return x.ClearStateOnRestart
+	}
+	return false
+}
+
+// FlowHistoryConfig configures the time series of flow counters kept per F-SEID
+type FlowHistoryConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaxFlows      int32                  `protobuf:"varint,1,opt,name=max_flows,json=maxFlows,proto3" json:"max_flows,omitempty"` // Sessions recorded at most; 0 means 256
+	Resolutions   []*HistoryResolution   `protobuf:"bytes,2,rep,name=resolutions,proto3" json:"resolutions,omitempty"`            // Resolutions kept, finest first; empty means the defaults
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlowHistoryConfig) Reset() {
+	*x = FlowHistoryConfig{}
+	mi := &file_request_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlowHistoryConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowHistoryConfig) ProtoMessage() {}
+
+func (x *FlowHistoryConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlowHistoryConfig.ProtoReflect.Descriptor instead.
+func (*FlowHistoryConfig) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *FlowHistoryConfig) GetMaxFlows() int32 {
+	if x != nil {
+		return x.MaxFlows
+	}
+	return 0
+}
+
+func (x *FlowHistoryConfig) GetResolutions() []*HistoryResolution {
+	if x != nil {
+		return x.Resolutions
+	}
+	return nil
+}
+
+// HistoryResolution is one resolution of the flow history
+type HistoryResolution struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Step          string                 `protobuf:"bytes,1,opt,name=step,proto3" json:"step,omitempty"`           // Time covered by one point, such as "1s"
+	Retention     string                 `protobuf:"bytes,2,opt,name=retention,proto3" json:"retention,omitempty"` // How far back points are kept, such as "10m"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryResolution) Reset() {
+	*x = HistoryResolution{}
+	mi := &file_request_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryResolution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryResolution) ProtoMessage() {}
+
+func (x *HistoryResolution) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryResolution.ProtoReflect.Descriptor instead.
+func (*HistoryResolution) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *HistoryResolution) GetStep() string {
+	if x != nil {
+		return x.Step
+	}
+	return ""
+}
+
+func (x *HistoryResolution) GetRetention() string {
+	if x != nil {
+		return x.Retention
+	}
+	return ""
+}
+
+// AlertsConfig names the alert rules file and the webhook alerts are posted to
+type AlertsConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RulesFile     string                 `protobuf:"bytes,1,opt,name=rules_file,json=rulesFile,proto3" json:"rules_file,omitempty"` // JSONC file holding the alert rules; empty disables alerting
+	Webhook       string                 `protobuf:"bytes,2,opt,name=webhook,proto3" json:"webhook,omitempty"`                      // URL firing and resolved alerts are posted to; empty disables it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertsConfig) Reset() {
+	*x = AlertsConfig{}
+	mi := &file_request_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertsConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertsConfig) ProtoMessage() {}
+
+func (x *AlertsConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertsConfig.ProtoReflect.Descriptor instead.
+func (*AlertsConfig) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *AlertsConfig) GetRulesFile() string {
+	if x != nil {
+		return x.RulesFile
+	}
+	return ""
+}
+
+func (x *AlertsConfig) GetWebhook() string {
+	if x != nil {
+		return x.Webhook
+	}
+	return ""
+}
+
+// MetricsConfig configures the HTTP endpoint serving Prometheus metrics
+type MetricsConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Port          string                 `protobuf:"bytes,1,opt,name=port,proto3" json:"port,omitempty"`                                // Port of the /metrics endpoint; empty means 9090
+	PerSession    bool                   `protobuf:"varint,2,opt,name=per_session,json=perSession,proto3" json:"per_session,omitempty"` // Also export series labelled by F-SEID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricsConfig) Reset() {
+	*x = MetricsConfig{}
+	mi := &file_request_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricsConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsConfig) ProtoMessage() {}
+
+func (x *MetricsConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsConfig.ProtoReflect.Descriptor instead.
+func (*MetricsConfig) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *MetricsConfig) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *MetricsConfig) GetPerSession() bool {
+	if x != nil {
+		return x.PerSession
+	}
+	return false
+}
+
+// IPFIXConfig configures the export of flow records to an IPFIX collector
+type IPFIXConfig struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Collector         string                 `protobuf:"bytes,1,opt,name=collector,proto3" json:"collector,omitempty"`                                           // host:port of the collector; empty disables export
+	Transport         string                 `protobuf:"bytes,2,opt,name=transport,proto3" json:"transport,omitempty"`                                           // udp or tcp; empty means udp
+	ActiveTimeout     string                 `protobuf:"bytes,3,opt,name=active_timeout,json=activeTimeout,proto3" json:"active_timeout,omitempty"`              // Interval active sessions are exported at; empty means 60s
+	TemplateRefresh   string                 `protobuf:"bytes,4,opt,name=template_refresh,json=templateRefresh,proto3" json:"template_refresh,omitempty"`        // Interval templates are resent at over UDP; empty means 10m
+	EnterpriseNumber  uint32                 `protobuf:"varint,5,opt,name=enterprise_number,json=enterpriseNumber,proto3" json:"enterprise_number,omitempty"`    // Enterprise number of the IMSI, F-SEID, DNN and TEID elements; 0 means 32473
+	ObservationDomain uint32                 `protobuf:"varint,6,opt,name=observation_domain,json=observationDomain,proto3" json:"observation_domain,omitempty"` // Observation domain ID of the records
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *IPFIXConfig) Reset() {
+	*x = IPFIXConfig{}
+	mi := &file_request_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IPFIXConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IPFIXConfig) ProtoMessage() {}
+
+func (x *IPFIXConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IPFIXConfig.ProtoReflect.Descriptor instead.
+func (*IPFIXConfig) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *IPFIXConfig) GetCollector() string {
+	if x != nil {
+		return x.Collector
+	}
+	return ""
+}
+
+func (x *IPFIXConfig) GetTransport() string {
+	if x != nil {
+		return x.Transport
+	}
+	return ""
+}
+
+func (x *IPFIXConfig) GetActiveTimeout() string {
+	if x != nil {
+		return x.ActiveTimeout
+	}
+	return ""
+}
+
+func (x *IPFIXConfig) GetTemplateRefresh() string {
+	if x != nil {
+		return x.TemplateRefresh
+	}
+	return ""
+}
+
+func (x *IPFIXConfig) GetEnterpriseNumber() uint32 {
+	if x != nil {
+		return x.EnterpriseNumber
+	}
+	return 0
+}
+
+func (x *IPFIXConfig) GetObservationDomain() uint32 {
+	if x != nil {
+		return x.ObservationDomain
+	}
+	return 0
+}
+
+// RecorderConfig configures the files the PFCP agent records flow statistics to
+type RecorderConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Dir           string                 `protobuf:"bytes,1,opt,name=dir,proto3" json:"dir,omitempty"`                                 // Directory of the files; empty disables recording
+	Format        string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`                           // influx or csv; empty means influx
+	Interval      string                 `protobuf:"bytes,3,opt,name=interval,proto3" json:"interval,omitempty"`                       // Time between points; empty means 10s
+	MaxSizeMb     int32                  `protobuf:"varint,4,opt,name=max_size_mb,json=maxSizeMb,proto3" json:"max_size_mb,omitempty"` // Rotate files at this size; 0 means no limit
+	MaxAge        string                 `protobuf:"bytes,5,opt,name=max_age,json=maxAge,proto3" json:"max_age,omitempty"`             // Rotate files at this age; empty means no limit
+	Gzip          bool                   `protobuf:"varint,6,opt,name=gzip,proto3" json:"gzip,omitempty"`                              // Compress rotated files with gzip
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecorderConfig) Reset() {
+	*x = RecorderConfig{}
+	mi := &file_request_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecorderConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecorderConfig) ProtoMessage() {}
+
+func (x *RecorderConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecorderConfig.ProtoReflect.Descriptor instead.
+func (*RecorderConfig) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *RecorderConfig) GetDir() string {
+	if x != nil {
+		return x.Dir
+	}
+	return ""
+}
+
+func (x *RecorderConfig) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *RecorderConfig) GetInterval() string {
+	if x != nil {
+		return x.Interval
+	}
+	return ""
+}
+
+func (x *RecorderConfig) GetMaxSizeMb() int32 {
+	if x != nil {
+		return x.MaxSizeMb
+	}
+	return 0
+}
+
+func (x *RecorderConfig) GetMaxAge() string {
+	if x != nil {
+		return x.MaxAge
+	}
+	return ""
+}
+
+func (x *RecorderConfig) GetGzip() bool {
+	if x != nil {
+		return x.Gzip
+	}
+	return false
+}
+
+// PFCPPeersRequest is empty as it doesn't need parameters
+type PFCPPeersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PFCPPeersRequest) Reset() {
+	*x = PFCPPeersRequest{}
+	mi := &file_request_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PFCPPeersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PFCPPeersRequest) ProtoMessage() {}
+
+func (x *PFCPPeersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PFCPPeersRequest.ProtoReflect.Descriptor instead.
+func (*PFCPPeersRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{55}
+}
+
+// PFCPPeer describes the N4 association with one control plane peer
+type PFCPPeer struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	NodeId            string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`                                     // Node ID of the peer
+	Address           string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`                                                 // UDP address of the peer
+	State             string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`                                                     // idle, associating, associated or lost
+	Configured        bool                   `protobuf:"varint,4,opt,name=configured,proto3" json:"configured,omitempty"`                                          // Whether the peer is listed in cpiface.peers
+	StateSinceUnix    int64                  `protobuf:"varint,5,opt,name=state_since_unix,json=stateSinceUnix,proto3" json:"state_since_unix,omitempty"`          // When the peer entered its current state
+	LastHeartbeatUnix int64                  `protobuf:"varint,6,opt,name=last_heartbeat_unix,json=lastHeartbeatUnix,proto3" json:"last_heartbeat_unix,omitempty"` // Last heartbeat exchanged with the peer, 0 if none
+	RecoveryUnix      int64                  `protobuf:"varint,7,opt,name=recovery_unix,json=recoveryUnix,proto3" json:"recovery_unix,omitempty"`                  // Recovery time stamp announced by the peer, 0 if unknown
+	Sessions          int32                  `protobuf:"varint,8,opt,name=sessions,proto3" json:"sessions,omitempty"`                                              // Number of PFCP sessions owned by the peer
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *PFCPPeer) Reset() {
+	*x = PFCPPeer{}
+	mi := &file_request_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PFCPPeer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PFCPPeer) ProtoMessage() {}
+
+func (x *PFCPPeer) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PFCPPeer.ProtoReflect.Descriptor instead.
+func (*PFCPPeer) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *PFCPPeer) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *PFCPPeer) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *PFCPPeer) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *PFCPPeer) GetConfigured() bool {
+	if x != nil {
+		return x.Configured
+	}
+	return false
+}
+
+func (x *PFCPPeer) GetStateSinceUnix() int64 {
+	if x != nil {
+		return x.StateSinceUnix
+	}
+	return 0
+}
+
+func (x *PFCPPeer) GetLastHeartbeatUnix() int64 {
+	if x != nil {
+		return x.LastHeartbeatUnix
+	}
+	return 0
+}
+
+func (x *PFCPPeer) GetRecoveryUnix() int64 {
+	if x != nil {
+		return x.RecoveryUnix
+	}
+	return 0
+}
+
+func (x *PFCPPeer) GetSessions() int32 {
+	if x != nil {
+		return x.Sessions
+	}
+	return 0
+}
+
+// PFCPPeersReply lists the PFCP peers of the UPF
+type PFCPPeersReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Peers         []*PFCPPeer            `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"` // Peers ordered by node ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PFCPPeersReply) Reset() {
+	*x = PFCPPeersReply{}
+	mi := &file_request_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PFCPPeersReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PFCPPeersReply) ProtoMessage() {}
+
+func (x *PFCPPeersReply) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PFCPPeersReply.ProtoReflect.Descriptor instead.
+func (*PFCPPeersReply) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *PFCPPeersReply) GetPeers() []*PFCPPeer {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+// UsageReportsRequest selects the usage reports to stream; empty fields match every session
+type UsageReportsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fseid         string                 `protobuf:"bytes,1,opt,name=fseid,proto3" json:"fseid,omitempty"` // Only reports of this session
+	Imsi          string                 `protobuf:"bytes,2,opt,name=imsi,proto3" json:"imsi,omitempty"`   // Only reports of sessions of this IMSI
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsageReportsRequest) Reset() {
+	*x = UsageReportsRequest{}
+	mi := &file_request_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageReportsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageReportsRequest) ProtoMessage() {}
+
+func (x *UsageReportsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageReportsRequest.ProtoReflect.Descriptor instead.
+func (*UsageReportsRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *UsageReportsRequest) GetFseid() string {
+	if x != nil {
+		return x.Fseid
+	}
+	return ""
+}
+
+func (x *UsageReportsRequest) GetImsi() string {
+	if x != nil {
+		return x.Imsi
+	}
+	return ""
+}
+
+// UsageReportEvent is one usage report of a URR
+type UsageReportEvent struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Fseid               string                 `protobuf:"bytes,1,opt,name=fseid,proto3" json:"fseid,omitempty"`                                                              // Session the URR belongs to
+	Imsi                string                 `protobuf:"bytes,2,opt,name=imsi,proto3" json:"imsi,omitempty"`                                                                // IMSI owning the session, if known
+	UrrId               string                 `protobuf:"bytes,3,opt,name=urr_id,json=urrId,proto3" json:"urr_id,omitempty"`                                                 // URR the report belongs to
+	Sequence            uint32                 `protobuf:"varint,4,opt,name=sequence,proto3" json:"sequence,omitempty"`                                                       // UR-SEQN, incremented for every report of the URR
+	Triggers            []string               `protobuf:"bytes,5,rep,name=triggers,proto3" json:"triggers,omitempty"`                                                        // Triggers that fired, named as in urrstruct, or termination
+	StartUnixNano       int64                  `protobuf:"varint,6,opt,name=start_unix_nano,json=startUnixNano,proto3" json:"start_unix_nano,omitempty"`                      // Start of the measurement
+	EndUnixNano         int64                  `protobuf:"varint,7,opt,name=end_unix_nano,json=endUnixNano,proto3" json:"end_unix_nano,omitempty"`                            // End of the measurement
+	UlBytes             uint64                 `protobuf:"varint,8,opt,name=ul_bytes,json=ulBytes,proto3" json:"ul_bytes,omitempty"`                                          // Uplink octets measured
+	DlBytes             uint64                 `protobuf:"varint,9,opt,name=dl_bytes,json=dlBytes,proto3" json:"dl_bytes,omitempty"`                                          // Downlink octets measured
+	TotalBytes          uint64                 `protobuf:"varint,10,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`                                // Uplink and downlink octets measured
+	UlPackets           uint64                 `protobuf:"varint,11,opt,name=ul_packets,json=ulPackets,proto3" json:"ul_packets,omitempty"`                                   // Uplink packets measured
+	DlPackets           uint64                 `protobuf:"varint,12,opt,name=dl_packets,json=dlPackets,proto3" json:"dl_packets,omitempty"`                                   // Downlink packets measured
+	TotalPackets        uint64                 `protobuf:"varint,13,opt,name=total_packets,json=totalPackets,proto3" json:"total_packets,omitempty"`                          // Uplink and downlink packets measured
+	DurationSeconds     uint32                 `protobuf:"varint,14,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`                 // Seconds with traffic during the measurement
+	FirstPacketUnixNano int64                  `protobuf:"varint,15,opt,name=first_packet_unix_nano,json=firstPacketUnixNano,proto3" json:"first_packet_unix_nano,omitempty"` // First packet of the measurement, 0 if none
+	LastPacketUnixNano  int64                  `protobuf:"varint,16,opt,name=last_packet_unix_nano,json=lastPacketUnixNano,proto3" json:"last_packet_unix_nano,omitempty"`    // Last packet of the measurement, 0 if none
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *UsageReportEvent) Reset() {
+	*x = UsageReportEvent{}
+	mi := &file_request_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageReportEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageReportEvent) ProtoMessage() {}
+
+func (x *UsageReportEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageReportEvent.ProtoReflect.Descriptor instead.
+func (*UsageReportEvent) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *UsageReportEvent) GetFseid() string {
+	if x != nil {
+		return x.Fseid
+	}
+	return ""
+}
+
+func (x *UsageReportEvent) GetImsi() string {
+	if x != nil {
+		return x.Imsi
+	}
+	return ""
+}
+
+func (x *UsageReportEvent) GetUrrId() string {
+	if x != nil {
+		return x.UrrId
+	}
+	return ""
+}
+
+func (x *UsageReportEvent) GetSequence() uint32 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *UsageReportEvent) GetTriggers() []string {
+	if x != nil {
+		return x.Triggers
+	}
+	return nil
+}
+
+func (x *UsageReportEvent) GetStartUnixNano() int64 {
+	if x != nil {
+		return x.StartUnixNano
+	}
+	return 0
+}
+
+func (x *UsageReportEvent) GetEndUnixNano() int64 {
+	if x != nil {
+		return x.EndUnixNano
+	}
+	return 0
+}
+
+func (x *UsageReportEvent) GetUlBytes() uint64 {
+	if x != nil {
+		return x.UlBytes
+	}
+	return 0
+}
+
+func (x *UsageReportEvent) GetDlBytes() uint64 {
+	if x != nil {
+		return x.DlBytes
+	}
+	return 0
+}
+
+func (x *UsageReportEvent) GetTotalBytes() uint64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *UsageReportEvent) GetUlPackets() uint64 {
+	if x != nil {
+		return x.UlPackets
+	}
+	return 0
+}
+
+func (x *UsageReportEvent) GetDlPackets() uint64 {
+	if x != nil {
+		return x.DlPackets
+	}
+	return 0
+}
+
+func (x *UsageReportEvent) GetTotalPackets() uint64 {
+	if x != nil {
+		return x.TotalPackets
+	}
+	return 0
+}
+
+func (x *UsageReportEvent) GetDurationSeconds() uint32 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+func (x *UsageReportEvent) GetFirstPacketUnixNano() int64 {
+	if x != nil {
+		return x.FirstPacketUnixNano
+	}
+	return 0
+}
+
+func (x *UsageReportEvent) GetLastPacketUnixNano() int64 {
+	if x != nil {
+		return x.LastPacketUnixNano
+	}
+	return 0
+}
+
+// FlowHistoryRequest selects a session and time range of the flow history
+type FlowHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fseid         string                 `protobuf:"bytes,1,opt,name=fseid,proto3" json:"fseid,omitempty"`                                 // Session to look up
+	StartUnix     int64                  `protobuf:"varint,2,opt,name=start_unix,json=startUnix,proto3" json:"start_unix,omitempty"`       // Start of the range; 0 means 10 minutes before end
+	EndUnix       int64                  `protobuf:"varint,3,opt,name=end_unix,json=endUnix,proto3" json:"end_unix,omitempty"`             // End of the range; 0 means now
+	StepSeconds   uint32                 `protobuf:"varint,4,opt,name=step_seconds,json=stepSeconds,proto3" json:"step_seconds,omitempty"` // Time covered by one point; 0 means the finest resolution holding start
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlowHistoryRequest) Reset() {
+	*x = FlowHistoryRequest{}
+	mi := &file_request_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlowHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowHistoryRequest) ProtoMessage() {}
+
+func (x *FlowHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlowHistoryRequest.ProtoReflect.Descriptor instead.
+func (*FlowHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *FlowHistoryRequest) GetFseid() string {
+	if x != nil {
+		return x.Fseid
 	}
 	return ""
 }
 
-func (x *UPFConfig) GetSim() *SimConfig {
+func (x *FlowHistoryRequest) GetStartUnix() int64 {
 	if x != nil {
-		return x.Sim
+		return x.StartUnix
 	}
-	return nil
+	return 0
 }
 
-func (x *UPFConfig) GetHwcksum() bool {
+func (x *FlowHistoryRequest) GetEndUnix() int64 {
 	if x != nil {
-		return x.Hwcksum
+		return x.EndUnix
 	}
-	return false
+	return 0
 }
 
-func (x *UPFConfig) GetGtppsc() bool {
+func (x *FlowHistoryRequest) GetStepSeconds() uint32 {
 	if x != nil {
-		return x.Gtppsc
+		return x.StepSeconds
 	}
-	return false
+	return 0
 }
 
-func (x *UPFConfig) GetDdp() bool {
+// FlowHistoryPoint holds the counters at the end of one step and the rates over it
+type FlowHistoryPoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Unix          int64                  `protobuf:"varint,1,opt,name=unix,proto3" json:"unix,omitempty"`                                        // End of the step, or its latest point while it is in progress
+	RxBytes       uint64                 `protobuf:"varint,2,opt,name=rx_bytes,json=rxBytes,proto3" json:"rx_bytes,omitempty"`                   // Cumulative received bytes
+	TxBytes       uint64                 `protobuf:"varint,3,opt,name=tx_bytes,json=txBytes,proto3" json:"tx_bytes,omitempty"`                   // Cumulative transmitted bytes
+	RxPackets     uint64                 `protobuf:"varint,4,opt,name=rx_packets,json=rxPackets,proto3" json:"rx_packets,omitempty"`             // Cumulative received packets
+	TxPackets     uint64                 `protobuf:"varint,5,opt,name=tx_packets,json=txPackets,proto3" json:"tx_packets,omitempty"`             // Cumulative transmitted packets
+	RxSpeed       float64                `protobuf:"fixed64,6,opt,name=rx_speed,json=rxSpeed,proto3" json:"rx_speed,omitempty"`                  // Average receive speed over the step, in speed_unit
+	TxSpeed       float64                `protobuf:"fixed64,7,opt,name=tx_speed,json=txSpeed,proto3" json:"tx_speed,omitempty"`                  // Average transmit speed over the step, in speed_unit
+	RxPacketRate  float64                `protobuf:"fixed64,8,opt,name=rx_packet_rate,json=rxPacketRate,proto3" json:"rx_packet_rate,omitempty"` // Average receive packet rate over the step, in packet_rate_unit
+	TxPacketRate  float64                `protobuf:"fixed64,9,opt,name=tx_packet_rate,json=txPacketRate,proto3" json:"tx_packet_rate,omitempty"` // Average transmit packet rate over the step, in packet_rate_unit
+	RxSpeedPeak   float64                `protobuf:"fixed64,10,opt,name=rx_speed_peak,json=rxSpeedPeak,proto3" json:"rx_speed_peak,omitempty"`   // Highest receive speed of a finest resolution step within the step
+	TxSpeedPeak   float64                `protobuf:"fixed64,11,opt,name=tx_speed_peak,json=txSpeedPeak,proto3" json:"tx_speed_peak,omitempty"`   // Highest transmit speed of a finest resolution step within the step
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlowHistoryPoint) Reset() {
+	*x = FlowHistoryPoint{}
+	mi := &file_request_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlowHistoryPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowHistoryPoint) ProtoMessage() {}
+
+func (x *FlowHistoryPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[61]
 	if x != nil {
-		return x.Ddp
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return false
+	return mi.MessageOf(x)
 }
 
-func (x *UPFConfig) GetMeasureUpf() bool {
+// Deprecated: Use FlowHistoryPoint.ProtoReflect.Descriptor instead.
+func (*FlowHistoryPoint) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *FlowHistoryPoint) GetUnix() int64 {
 	if x != nil {
-		return x.MeasureUpf
+		return x.Unix
 	}
-	return false
+	return 0
 }
 
-func (x *UPFConfig) GetMeasureFlow() bool {
+func (x *FlowHistoryPoint) GetRxBytes() uint64 {
 	if x != nil {
-		return x.MeasureFlow
+		return x.RxBytes
 	}
-	return false
+	return 0
 }
 
-func (x *UPFConfig) GetAccess() *Interface {
+func (x *FlowHistoryPoint) GetTxBytes() uint64 {
 	if x != nil {
-		return x.Access
+		return x.TxBytes
+	}
+	return 0
+}
+
+func (x *FlowHistoryPoint) GetRxPackets() uint64 {
+	if x != nil {
+		return x.RxPackets
+	}
+	return 0
+}
+
+func (x *FlowHistoryPoint) GetTxPackets() uint64 {
+	if x != nil {
+		return x.TxPackets
+	}
+	return 0
+}
+
+func (x *FlowHistoryPoint) GetRxSpeed() float64 {
+	if x != nil {
+		return x.RxSpeed
+	}
+	return 0
+}
+
+func (x *FlowHistoryPoint) GetTxSpeed() float64 {
+	if x != nil {
+		return x.TxSpeed
+	}
+	return 0
+}
+
+func (x *FlowHistoryPoint) GetRxPacketRate() float64 {
+	if x != nil {
+		return x.RxPacketRate
+	}
+	return 0
+}
+
+func (x *FlowHistoryPoint) GetTxPacketRate() float64 {
+	if x != nil {
+		return x.TxPacketRate
+	}
+	return 0
+}
+
+func (x *FlowHistoryPoint) GetRxSpeedPeak() float64 {
+	if x != nil {
+		return x.RxSpeedPeak
+	}
+	return 0
+}
+
+func (x *FlowHistoryPoint) GetTxSpeedPeak() float64 {
+	if x != nil {
+		return x.TxSpeedPeak
+	}
+	return 0
+}
+
+// FlowHistoryReply holds the points of the range; steps without data are left out
+type FlowHistoryReply struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Fseid             string                 `protobuf:"bytes,1,opt,name=fseid,proto3" json:"fseid,omitempty"`                                                   // Session the points belong to
+	StepSeconds       uint32                 `protobuf:"varint,2,opt,name=step_seconds,json=stepSeconds,proto3" json:"step_seconds,omitempty"`                   // Time covered by one point
+	ResolutionSeconds uint32                 `protobuf:"varint,3,opt,name=resolution_seconds,json=resolutionSeconds,proto3" json:"resolution_seconds,omitempty"` // Step of the recorded resolution the points were read from
+	Points            []*FlowHistoryPoint    `protobuf:"bytes,4,rep,name=points,proto3" json:"points,omitempty"`                                                 // Points in ascending time
+	SpeedUnit         string                 `protobuf:"bytes,5,opt,name=speed_unit,json=speedUnit,proto3" json:"speed_unit,omitempty"`                          // Unit of the speeds, "bit/s"
+	PacketRateUnit    string                 `protobuf:"bytes,6,opt,name=packet_rate_unit,json=packetRateUnit,proto3" json:"packet_rate_unit,omitempty"`         // Unit of the packet rates, "packet/s"
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *FlowHistoryReply) Reset() {
+	*x = FlowHistoryReply{}
+	mi := &file_request_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlowHistoryReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowHistoryReply) ProtoMessage() {}
+
+func (x *FlowHistoryReply) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlowHistoryReply.ProtoReflect.Descriptor instead.
+func (*FlowHistoryReply) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *FlowHistoryReply) GetFseid() string {
+	if x != nil {
+		return x.Fseid
+	}
+	return ""
+}
+
+func (x *FlowHistoryReply) GetStepSeconds() uint32 {
+	if x != nil {
+		return x.StepSeconds
+	}
+	return 0
+}
+
+func (x *FlowHistoryReply) GetResolutionSeconds() uint32 {
+	if x != nil {
+		return x.ResolutionSeconds
+	}
+	return 0
+}
+
+func (x *FlowHistoryReply) GetPoints() []*FlowHistoryPoint {
+	if x != nil {
+		return x.Points
 	}
 	return nil
 }
 
-func (x *UPFConfig) GetCore() *Interface {
+func (x *FlowHistoryReply) GetSpeedUnit() string {
 	if x != nil {
-		return x.Core
+		return x.SpeedUnit
+	}
+	return ""
+}
+
+func (x *FlowHistoryReply) GetPacketRateUnit() string {
+	if x != nil {
+		return x.PacketRateUnit
+	}
+	return ""
+}
+
+// AggregateStatsRequest selects how flow counters are rolled up and which groups are returned
+type AggregateStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupBy       string                 `protobuf:"bytes,1,opt,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`           // imsi, dnn, slice, upf, or flow (per F-SEID, kept only with measure_flow)
+	Filter        []string               `protobuf:"bytes,2,rep,name=filter,proto3" json:"filter,omitempty"`                            // Keys of the groups to return; empty means all
+	IntervalMs    uint32                 `protobuf:"varint,3,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"` // StreamAggregateStats update interval, 100 to 60000; 0 means 2000
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AggregateStatsRequest) Reset() {
+	*x = AggregateStatsRequest{}
+	mi := &file_request_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AggregateStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggregateStatsRequest) ProtoMessage() {}
+
+func (x *AggregateStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AggregateStatsRequest.ProtoReflect.Descriptor instead.
+func (*AggregateStatsRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *AggregateStatsRequest) GetGroupBy() string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return ""
+}
+
+func (x *AggregateStatsRequest) GetFilter() []string {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *AggregateStatsRequest) GetIntervalMs() uint32 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+// AggregateGroup holds the counters of the sessions of one group and the rates over the last second
+type AggregateGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`                                            // IMSI, DNN, slice ID, F-SEID, or "upf"
+	Sessions      uint32                 `protobuf:"varint,2,opt,name=sessions,proto3" json:"sessions,omitempty"`                                 // Sessions currently in the group
+	RxPackets     uint64                 `protobuf:"varint,3,opt,name=rx_packets,json=rxPackets,proto3" json:"rx_packets,omitempty"`              // Received packets since the group was first seen
+	TxPackets     uint64                 `protobuf:"varint,4,opt,name=tx_packets,json=txPackets,proto3" json:"tx_packets,omitempty"`              // Transmitted packets since the group was first seen
+	RxBytes       uint64                 `protobuf:"varint,5,opt,name=rx_bytes,json=rxBytes,proto3" json:"rx_bytes,omitempty"`                    // Received bytes since the group was first seen
+	TxBytes       uint64                 `protobuf:"varint,6,opt,name=tx_bytes,json=txBytes,proto3" json:"tx_bytes,omitempty"`                    // Transmitted bytes since the group was first seen
+	RxSpeed       float64                `protobuf:"fixed64,7,opt,name=rx_speed,json=rxSpeed,proto3" json:"rx_speed,omitempty"`                   // Receive speed over the last second, in speed_unit
+	TxSpeed       float64                `protobuf:"fixed64,8,opt,name=tx_speed,json=txSpeed,proto3" json:"tx_speed,omitempty"`                   // Transmit speed over the last second, in speed_unit
+	RxPacketRate  float64                `protobuf:"fixed64,9,opt,name=rx_packet_rate,json=rxPacketRate,proto3" json:"rx_packet_rate,omitempty"`  // Receive packet rate over the last second, in packet_rate_unit
+	TxPacketRate  float64                `protobuf:"fixed64,10,opt,name=tx_packet_rate,json=txPacketRate,proto3" json:"tx_packet_rate,omitempty"` // Transmit packet rate over the last second, in packet_rate_unit
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AggregateGroup) Reset() {
+	*x = AggregateGroup{}
+	mi := &file_request_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AggregateGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggregateGroup) ProtoMessage() {}
+
+func (x *AggregateGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *UPFConfig) GetWorkers() int32 {
-	if x != nil {
-		return x.Workers
-	}
-	return 0
+// Deprecated: Use AggregateGroup.ProtoReflect.Descriptor instead.
+func (*AggregateGroup) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{64}
 }
 
-func (x *UPFConfig) GetMaxReqRetries() int32 {
+func (x *AggregateGroup) GetKey() string {
 	if x != nil {
-		return x.MaxReqRetries
+		return x.Key
 	}
-	return 0
+	return ""
 }
 
-func (x *UPFConfig) GetRespTimeout() string {
+func (x *AggregateGroup) GetSessions() uint32 {
 	if x != nil {
-		return x.RespTimeout
+		return x.Sessions
 	}
-	return ""
+	return 0
 }
 
-func (x *UPFConfig) GetEnableNtf() bool {
+func (x *AggregateGroup) GetRxPackets() uint64 {
 	if x != nil {
-		return x.EnableNtf
+		return x.RxPackets
 	}
-	return false
+	return 0
 }
 
-func (x *UPFConfig) GetEnableP4Rt() bool {
+func (x *AggregateGroup) GetTxPackets() uint64 {
 	if x != nil {
-		return x.EnableP4Rt
+		return x.TxPackets
 	}
-	return false
+	return 0
 }
 
-func (x *UPFConfig) GetEnableHbTimer() bool {
+func (x *AggregateGroup) GetRxBytes() uint64 {
 	if x != nil {
-		return x.EnableHbTimer
+		return x.RxBytes
 	}
-	return false
+	return 0
 }
 
-func (x *UPFConfig) GetEnableGtpuPathMonitoring() bool {
+func (x *AggregateGroup) GetTxBytes() uint64 {
 	if x != nil {
-		return x.EnableGtpuPathMonitoring
+		return x.TxBytes
 	}
-	return false
+	return 0
 }
 
-func (x *UPFConfig) GetQciQosConfig() []*QoSConfig {
+func (x *AggregateGroup) GetRxSpeed() float64 {
 	if x != nil {
-		return x.QciQosConfig
+		return x.RxSpeed
 	}
-	return nil
+	return 0
 }
 
-func (x *UPFConfig) GetSliceRateLimitConfig() *SliceRateLimit {
+func (x *AggregateGroup) GetTxSpeed() float64 {
 	if x != nil {
-		return x.SliceRateLimitConfig
+		return x.TxSpeed
 	}
-	return nil
+	return 0
 }
 
-func (x *UPFConfig) GetCpiface() *CPInterface {
+func (x *AggregateGroup) GetRxPacketRate() float64 {
 	if x != nil {
-		return x.Cpiface
+		return x.RxPacketRate
 	}
-	return nil
+	return 0
 }
 
-func (x *UPFConfig) GetP4Rtciface() *P4RTCInterface {
+func (x *AggregateGroup) GetTxPacketRate() float64 {
 	if x != nil {
-		return x.P4Rtciface
+		return x.TxPacketRate
 	}
-	return nil
+	return 0
 }
 
-// TableSizes defines the size configuration for various lookup tables in the UPF
-type TableSizes struct {
+// AggregateStatsReply holds the groups of one roll-up
+type AggregateStatsReply struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
-	PdrLookup        int32                  `protobuf:"varint,1,opt,name=pdrLookup,proto3" json:"pdrLookup,omitempty"`               // Size of the PDR lookup table
-	FlowMeasure      int32                  `protobuf:"varint,2,opt,name=flowMeasure,proto3" json:"flowMeasure,omitempty"`           // Size of the flow measurement table
-	AppQERLookup     int32                  `protobuf:"varint,3,opt,name=appQERLookup,proto3" json:"appQERLookup,omitempty"`         // Size of the application QER lookup table
-	SessionQERLookup int32                  `protobuf:"varint,4,opt,name=sessionQERLookup,proto3" json:"sessionQERLookup,omitempty"` // Size of the session QER lookup table
-	FarLookup        int32                  `protobuf:"varint,5,opt,name=farLookup,proto3" json:"farLookup,omitempty"`               // Size of the FAR lookup table
+	GroupBy          string                 `protobuf:"bytes,1,opt,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`                               // Grouping of the request
+	Groups           []*AggregateGroup      `protobuf:"bytes,2,rep,name=groups,proto3" json:"groups,omitempty"`                                                // Groups ordered by key
+	MeasuredUnixNano int64                  `protobuf:"varint,3,opt,name=measured_unix_nano,json=measuredUnixNano,proto3" json:"measured_unix_nano,omitempty"` // When the counters were last measured
+	SpeedUnit        string                 `protobuf:"bytes,4,opt,name=speed_unit,json=speedUnit,proto3" json:"speed_unit,omitempty"`                         // Unit of the speeds, "bit/s"
+	PacketRateUnit   string                 `protobuf:"bytes,5,opt,name=packet_rate_unit,json=packetRateUnit,proto3" json:"packet_rate_unit,omitempty"`        // Unit of the packet rates, "packet/s"
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
 
-func (x *TableSizes) Reset() {
-	*x = TableSizes{}
-	mi := &file_request_proto_msgTypes[17]
+func (x *AggregateStatsReply) Reset() {
+	*x = AggregateStatsReply{}
+	mi := &file_request_proto_msgTypes[65]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TableSizes) String() string {
+func (x *AggregateStatsReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TableSizes) ProtoMessage() {}
+func (*AggregateStatsReply) ProtoMessage() {}
 
-func (x *TableSizes) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[17]
+func (x *AggregateStatsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[65]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1129,83 +4979,71 @@ func (x *TableSizes) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TableSizes.ProtoReflect.Descriptor instead.
-func (*TableSizes) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use AggregateStatsReply.ProtoReflect.Descriptor instead.
+func (*AggregateStatsReply) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{65}
 }
 
-func (x *TableSizes) GetPdrLookup() int32 {
+func (x *AggregateStatsReply) GetGroupBy() string {
 	if x != nil {
-		return x.PdrLookup
+		return x.GroupBy
 	}
-	return 0
+	return ""
 }
 
-func (x *TableSizes) GetFlowMeasure() int32 {
+func (x *AggregateStatsReply) GetGroups() []*AggregateGroup {
 	if x != nil {
-		return x.FlowMeasure
+		return x.Groups
 	}
-	return 0
+	return nil
 }
 
-func (x *TableSizes) GetAppQERLookup() int32 {
+func (x *AggregateStatsReply) GetMeasuredUnixNano() int64 {
 	if x != nil {
-		return x.AppQERLookup
+		return x.MeasuredUnixNano
 	}
 	return 0
 }
 
-func (x *TableSizes) GetSessionQERLookup() int32 {
+func (x *AggregateStatsReply) GetSpeedUnit() string {
 	if x != nil {
-		return x.SessionQERLookup
+		return x.SpeedUnit
 	}
-	return 0
+	return ""
 }
 
-func (x *TableSizes) GetFarLookup() int32 {
+func (x *AggregateStatsReply) GetPacketRateUnit() string {
 	if x != nil {
-		return x.FarLookup
+		return x.PacketRateUnit
 	}
-	return 0
+	return ""
 }
 
-// SimConfig defines the simulation parameters for the UPF
-type SimConfig struct {
+// TopSessionsRequest selects the ranking of TopSessions
+type TopSessionsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Core          string                 `protobuf:"bytes,1,opt,name=core,proto3" json:"core,omitempty"`                                    // Core network IP address
-	MaxSessions   int32                  `protobuf:"varint,2,opt,name=max_sessions,json=maxSessions,proto3" json:"max_sessions,omitempty"`  // Maximum number of sessions
-	StartUeIp     string                 `protobuf:"bytes,3,opt,name=start_ue_ip,json=startUeIp,proto3" json:"start_ue_ip,omitempty"`       // Starting UE IP address
-	StartEnbIp    string                 `protobuf:"bytes,4,opt,name=start_enb_ip,json=startEnbIp,proto3" json:"start_enb_ip,omitempty"`    // Starting eNB IP address
-	StartAupfIp   string                 `protobuf:"bytes,5,opt,name=start_aupf_ip,json=startAupfIp,proto3" json:"start_aupf_ip,omitempty"` // Starting AUPF IP address
-	N6AppIp       string                 `protobuf:"bytes,6,opt,name=n6_app_ip,json=n6AppIp,proto3" json:"n6_app_ip,omitempty"`             // N6 application IP address
-	N9AppIp       string                 `protobuf:"bytes,7,opt,name=n9_app_ip,json=n9AppIp,proto3" json:"n9_app_ip,omitempty"`             // N9 application IP address
-	StartN3Teid   string                 `protobuf:"bytes,8,opt,name=start_n3_teid,json=startN3Teid,proto3" json:"start_n3_teid,omitempty"` // Starting N3 TEID
-	StartN9Teid   string                 `protobuf:"bytes,9,opt,name=start_n9_teid,json=startN9Teid,proto3" json:"start_n9_teid,omitempty"` // Starting N9 TEID
-	UplinkMbr     int32                  `protobuf:"varint,10,opt,name=uplink_mbr,json=uplinkMbr,proto3" json:"uplink_mbr,omitempty"`       // Uplink maximum bit rate
-	UplinkGbr     int32                  `protobuf:"varint,11,opt,name=uplink_gbr,json=uplinkGbr,proto3" json:"uplink_gbr,omitempty"`       // Uplink guaranteed bit rate
-	DownlinkMbr   int32                  `protobuf:"varint,12,opt,name=downlink_mbr,json=downlinkMbr,proto3" json:"downlink_mbr,omitempty"` // Downlink maximum bit rate
-	DownlinkGbr   int32                  `protobuf:"varint,13,opt,name=downlink_gbr,json=downlinkGbr,proto3" json:"downlink_gbr,omitempty"` // Downlink guaranteed bit rate
-	PktSize       int32                  `protobuf:"varint,14,opt,name=pkt_size,json=pktSize,proto3" json:"pkt_size,omitempty"`             // Packet size
-	TotalFlows    int32                  `protobuf:"varint,15,opt,name=total_flows,json=totalFlows,proto3" json:"total_flows,omitempty"`    // Total number of flows
+	Metric        string                 `protobuf:"bytes,1,opt,name=metric,proto3" json:"metric,omitempty"`                                     // bytes, packets or rate; empty means bytes
+	N             uint32                 `protobuf:"varint,2,opt,name=n,proto3" json:"n,omitempty"`                                              // Sessions to return, 1 to 100; 0 means 10
+	WindowSeconds uint32                 `protobuf:"varint,3,opt,name=window_seconds,json=windowSeconds,proto3" json:"window_seconds,omitempty"` // Window the traffic is counted over, 1 to 300; 0 means 60. 10, 60 and 300 are ranked ahead, others on request
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SimConfig) Reset() {
-	*x = SimConfig{}
-	mi := &file_request_proto_msgTypes[18]
+func (x *TopSessionsRequest) Reset() {
+	*x = TopSessionsRequest{}
+	mi := &file_request_proto_msgTypes[66]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SimConfig) String() string {
+func (x *TopSessionsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SimConfig) ProtoMessage() {}
+func (*TopSessionsRequest) ProtoMessage() {}
 
-func (x *SimConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[18]
+func (x *TopSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[66]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1216,139 +5054,234 @@ func (x *SimConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SimConfig.ProtoReflect.Descriptor instead.
-func (*SimConfig) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use TopSessionsRequest.ProtoReflect.Descriptor instead.
+func (*TopSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{66}
 }
 
-func (x *SimConfig) GetCore() string {
+func (x *TopSessionsRequest) GetMetric() string {
 	if x != nil {
-		return x.Core
+		return x.Metric
 	}
 	return ""
 }
 
-func (x *SimConfig) GetMaxSessions() int32 {
+func (x *TopSessionsRequest) GetN() uint32 {
 	if x != nil {
-		return x.MaxSessions
+		return x.N
 	}
 	return 0
 }
 
-func (x *SimConfig) GetStartUeIp() string {
+func (x *TopSessionsRequest) GetWindowSeconds() uint32 {
 	if x != nil {
-		return x.StartUeIp
+		return x.WindowSeconds
 	}
-	return ""
+	return 0
 }
 
-func (x *SimConfig) GetStartEnbIp() string {
+// TopSession is one ranked session and its traffic over the window
+type TopSession struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fseid         string                 `protobuf:"bytes,1,opt,name=fseid,proto3" json:"fseid,omitempty"`       // Session
+	Imsi          string                 `protobuf:"bytes,2,opt,name=imsi,proto3" json:"imsi,omitempty"`         // IMSI owning the session, if known
+	Dnn           string                 `protobuf:"bytes,3,opt,name=dnn,proto3" json:"dnn,omitempty"`           // DNN of the session, if known
+	Bytes         uint64                 `protobuf:"varint,4,opt,name=bytes,proto3" json:"bytes,omitempty"`      // Received and transmitted bytes over the window
+	Packets       uint64                 `protobuf:"varint,5,opt,name=packets,proto3" json:"packets,omitempty"`  // Received and transmitted packets over the window
+	Rate          float64                `protobuf:"fixed64,6,opt,name=rate,proto3" json:"rate,omitempty"`       // Average speed over the window, in speed_unit
+	Seconds       float64                `protobuf:"fixed64,7,opt,name=seconds,proto3" json:"seconds,omitempty"` // Time the counters cover, the window or up to one sample more
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopSession) Reset() {
+	*x = TopSession{}
+	mi := &file_request_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopSession) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopSession) ProtoMessage() {}
+
+func (x *TopSession) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[67]
 	if x != nil {
-		return x.StartEnbIp
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *SimConfig) GetStartAupfIp() string {
+// Deprecated: Use TopSession.ProtoReflect.Descriptor instead.
+func (*TopSession) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *TopSession) GetFseid() string {
 	if x != nil {
-		return x.StartAupfIp
+		return x.Fseid
 	}
 	return ""
 }
 
-func (x *SimConfig) GetN6AppIp() string {
+func (x *TopSession) GetImsi() string {
 	if x != nil {
-		return x.N6AppIp
+		return x.Imsi
 	}
 	return ""
 }
 
-func (x *SimConfig) GetN9AppIp() string {
+func (x *TopSession) GetDnn() string {
 	if x != nil {
-		return x.N9AppIp
+		return x.Dnn
 	}
 	return ""
 }
 
-func (x *SimConfig) GetStartN3Teid() string {
+func (x *TopSession) GetBytes() uint64 {
 	if x != nil {
-		return x.StartN3Teid
+		return x.Bytes
 	}
-	return ""
+	return 0
 }
 
-func (x *SimConfig) GetStartN9Teid() string {
+func (x *TopSession) GetPackets() uint64 {
 	if x != nil {
-		return x.StartN9Teid
+		return x.Packets
 	}
-	return ""
+	return 0
 }
 
-func (x *SimConfig) GetUplinkMbr() int32 {
+func (x *TopSession) GetRate() float64 {
 	if x != nil {
-		return x.UplinkMbr
+		return x.Rate
 	}
 	return 0
 }
 
-func (x *SimConfig) GetUplinkGbr() int32 {
+func (x *TopSession) GetSeconds() float64 {
 	if x != nil {
-		return x.UplinkGbr
+		return x.Seconds
 	}
 	return 0
 }
 
-func (x *SimConfig) GetDownlinkMbr() int32 {
+// TopSessionsReply holds the heaviest sessions, heaviest first
+type TopSessionsReply struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Metric           string                 `protobuf:"bytes,1,opt,name=metric,proto3" json:"metric,omitempty"`                                                // Metric the sessions are ranked by
+	WindowSeconds    uint32                 `protobuf:"varint,2,opt,name=window_seconds,json=windowSeconds,proto3" json:"window_seconds,omitempty"`            // Window of the ranking
+	Sessions         []*TopSession          `protobuf:"bytes,3,rep,name=sessions,proto3" json:"sessions,omitempty"`                                            // Ranked sessions
+	Ranked           uint32                 `protobuf:"varint,4,opt,name=ranked,proto3" json:"ranked,omitempty"`                                               // Sessions considered in the ranking
+	MeasuredUnixNano int64                  `protobuf:"varint,5,opt,name=measured_unix_nano,json=measuredUnixNano,proto3" json:"measured_unix_nano,omitempty"` // When the ranking was computed
+	SpeedUnit        string                 `protobuf:"bytes,6,opt,name=speed_unit,json=speedUnit,proto3" json:"speed_unit,omitempty"`                         // Unit of the rates, "bit/s"
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *TopSessionsReply) Reset() {
+	*x = TopSessionsReply{}
+	mi := &file_request_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopSessionsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopSessionsReply) ProtoMessage() {}
+
+func (x *TopSessionsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[68]
 	if x != nil {
-		return x.DownlinkMbr
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *SimConfig) GetDownlinkGbr() int32 {
+// Deprecated: Use TopSessionsReply.ProtoReflect.Descriptor instead.
+func (*TopSessionsReply) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *TopSessionsReply) GetMetric() string {
 	if x != nil {
-		return x.DownlinkGbr
+		return x.Metric
+	}
+	return ""
+}
+
+func (x *TopSessionsReply) GetWindowSeconds() uint32 {
+	if x != nil {
+		return x.WindowSeconds
 	}
 	return 0
 }
 
-func (x *SimConfig) GetPktSize() int32 {
+func (x *TopSessionsReply) GetSessions() []*TopSession {
 	if x != nil {
-		return x.PktSize
+		return x.Sessions
+	}
+	return nil
+}
+
+func (x *TopSessionsReply) GetRanked() uint32 {
+	if x != nil {
+		return x.Ranked
 	}
 	return 0
 }
 
-func (x *SimConfig) GetTotalFlows() int32 {
+func (x *TopSessionsReply) GetMeasuredUnixNano() int64 {
 	if x != nil {
-		return x.TotalFlows
+		return x.MeasuredUnixNano
 	}
 	return 0
 }
 
-// Interface defines the configuration for a network interface
-type Interface struct {
+func (x *TopSessionsReply) GetSpeedUnit() string {
+	if x != nil {
+		return x.SpeedUnit
+	}
+	return ""
+}
+
+// AlertsRequest selects the alerts to stream; empty fields match every alert
+type AlertsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ifname        string                 `protobuf:"bytes,1,opt,name=ifname,proto3" json:"ifname,omitempty"` // Interface name
+	Rule          string                 `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`   // Only alerts of this rule
+	Fseid         string                 `protobuf:"bytes,2,opt,name=fseid,proto3" json:"fseid,omitempty"` // Only alerts of this session
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Interface) Reset() {
-	*x = Interface{}
-	mi := &file_request_proto_msgTypes[19]
+func (x *AlertsRequest) Reset() {
+	*x = AlertsRequest{}
+	mi := &file_request_proto_msgTypes[69]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Interface) String() string {
+func (x *AlertsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Interface) ProtoMessage() {}
+func (*AlertsRequest) ProtoMessage() {}
 
-func (x *Interface) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[19]
+func (x *AlertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[69]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1359,46 +5292,59 @@ func (x *Interface) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Interface.ProtoReflect.Descriptor instead.
-func (*Interface) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use AlertsRequest.ProtoReflect.Descriptor instead.
+func (*AlertsRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{69}
 }
 
-func (x *Interface) GetIfname() string {
+func (x *AlertsRequest) GetRule() string {
 	if x != nil {
-		return x.Ifname
+		return x.Rule
 	}
 	return ""
 }
 
-// QoSConfig defines the Quality of Service parameters for a specific QCI
-type QoSConfig struct {
+func (x *AlertsRequest) GetFseid() string {
+	if x != nil {
+		return x.Fseid
+	}
+	return ""
+}
+
+// AlertEvent is a change of state of an alert
+type AlertEvent struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
-	Qci             int32                  `protobuf:"varint,1,opt,name=qci,proto3" json:"qci,omitempty"`                                                  // QoS Class Identifier
-	Cbs             int32                  `protobuf:"varint,2,opt,name=cbs,proto3" json:"cbs,omitempty"`                                                  // Committed Burst Size
-	Ebs             int32                  `protobuf:"varint,3,opt,name=ebs,proto3" json:"ebs,omitempty"`                                                  // Excess Burst Size
-	Pbs             int32                  `protobuf:"varint,4,opt,name=pbs,proto3" json:"pbs,omitempty"`                                                  // Peak Data Rate
-	BurstDurationMs int32                  `protobuf:"varint,5,opt,name=burst_duration_ms,json=burstDurationMs,proto3" json:"burst_duration_ms,omitempty"` // Burst duration in milliseconds
-	Priority        int32                  `protobuf:"varint,6,opt,name=priority,proto3" json:"priority,omitempty"`                                        // Scheduling priority
+	Rule            string                 `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`                                                  // Rule that raised the alert
+	Severity        string                 `protobuf:"bytes,2,opt,name=severity,proto3" json:"severity,omitempty"`                                          // Severity of the rule
+	State           string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`                                                // pending, firing, resolved, or inactive for a pending alert that never fired
+	Target          string                 `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`                                              // F-SEID the alert is about, or "upf"
+	Imsi            string                 `protobuf:"bytes,5,opt,name=imsi,proto3" json:"imsi,omitempty"`                                                  // IMSI owning the session, if known
+	Dnn             string                 `protobuf:"bytes,6,opt,name=dnn,proto3" json:"dnn,omitempty"`                                                    // DNN of the session, if known
+	Metric          string                 `protobuf:"bytes,7,opt,name=metric,proto3" json:"metric,omitempty"`                                              // Metric the rule watches
+	Value           float64                `protobuf:"fixed64,8,opt,name=value,proto3" json:"value,omitempty"`                                              // Latest value of the metric
+	Threshold       float64                `protobuf:"fixed64,9,opt,name=threshold,proto3" json:"threshold,omitempty"`                                      // Threshold of the rule
+	Summary         string                 `protobuf:"bytes,10,opt,name=summary,proto3" json:"summary,omitempty"`                                           // Description of the alert
+	StartedUnixNano int64                  `protobuf:"varint,11,opt,name=started_unix_nano,json=startedUnixNano,proto3" json:"started_unix_nano,omitempty"` // When the threshold was first crossed
+	AtUnixNano      int64                  `protobuf:"varint,12,opt,name=at_unix_nano,json=atUnixNano,proto3" json:"at_unix_nano,omitempty"`                // When the alert entered this state
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
 
-func (x *QoSConfig) Reset() {
-	*x = QoSConfig{}
-	mi := &file_request_proto_msgTypes[20]
+func (x *AlertEvent) Reset() {
+	*x = AlertEvent{}
+	mi := &file_request_proto_msgTypes[70]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *QoSConfig) String() string {
+func (x *AlertEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QoSConfig) ProtoMessage() {}
+func (*AlertEvent) ProtoMessage() {}
 
-func (x *QoSConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[20]
+func (x *AlertEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[70]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1406,82 +5352,123 @@ func (x *QoSConfig) ProtoReflect() protoreflect.Message {
 		}
 		return ms
 	}
-	return mi.MessageOf(x)
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertEvent.ProtoReflect.Descriptor instead.
+func (*AlertEvent) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *AlertEvent) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *AlertEvent) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *AlertEvent) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *AlertEvent) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *AlertEvent) GetImsi() string {
+	if x != nil {
+		return x.Imsi
+	}
+	return ""
 }
 
-// Deprecated: Use QoSConfig.ProtoReflect.Descriptor instead.
-func (*QoSConfig) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{20}
+func (x *AlertEvent) GetDnn() string {
+	if x != nil {
+		return x.Dnn
+	}
+	return ""
 }
 
-func (x *QoSConfig) GetQci() int32 {
+func (x *AlertEvent) GetMetric() string {
 	if x != nil {
-		return x.Qci
+		return x.Metric
 	}
-	return 0
+	return ""
 }
 
-func (x *QoSConfig) GetCbs() int32 {
+func (x *AlertEvent) GetValue() float64 {
 	if x != nil {
-		return x.Cbs
+		return x.Value
 	}
 	return 0
 }
 
-func (x *QoSConfig) GetEbs() int32 {
+func (x *AlertEvent) GetThreshold() float64 {
 	if x != nil {
-		return x.Ebs
+		return x.Threshold
 	}
 	return 0
 }
 
-func (x *QoSConfig) GetPbs() int32 {
+func (x *AlertEvent) GetSummary() string {
 	if x != nil {
-		return x.Pbs
+		return x.Summary
 	}
-	return 0
+	return ""
 }
 
-func (x *QoSConfig) GetBurstDurationMs() int32 {
+func (x *AlertEvent) GetStartedUnixNano() int64 {
 	if x != nil {
-		return x.BurstDurationMs
+		return x.StartedUnixNano
 	}
 	return 0
 }
 
-func (x *QoSConfig) GetPriority() int32 {
+func (x *AlertEvent) GetAtUnixNano() int64 {
 	if x != nil {
-		return x.Priority
+		return x.AtUnixNano
 	}
 	return 0
 }
 
-// SliceRateLimit defines the rate limiting parameters for a slice
-type SliceRateLimit struct {
+// AnomaliesRequest selects the anomalies to stream; empty fields match every session
+type AnomaliesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	N6Bps         int32                  `protobuf:"varint,1,opt,name=n6_bps,json=n6Bps,proto3" json:"n6_bps,omitempty"`                        // N6 rate limit in bits per second
-	N6BurstBytes  int32                  `protobuf:"varint,2,opt,name=n6_burst_bytes,json=n6BurstBytes,proto3" json:"n6_burst_bytes,omitempty"` // N6 burst size in bytes
-	N3Bps         int32                  `protobuf:"varint,3,opt,name=n3_bps,json=n3Bps,proto3" json:"n3_bps,omitempty"`                        // N3 rate limit in bits per second
-	N3BurstBytes  int32                  `protobuf:"varint,4,opt,name=n3_burst_bytes,json=n3BurstBytes,proto3" json:"n3_burst_bytes,omitempty"` // N3 burst size in bytes
+	Fseid         string                 `protobuf:"bytes,1,opt,name=fseid,proto3" json:"fseid,omitempty"`                         // Only anomalies of this session
+	Imsi          string                 `protobuf:"bytes,2,opt,name=imsi,proto3" json:"imsi,omitempty"`                           // Only anomalies of sessions of this IMSI
+	MinScore      float64                `protobuf:"fixed64,3,opt,name=min_score,json=minScore,proto3" json:"min_score,omitempty"` // Only anomalies scoring at least this much
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SliceRateLimit) Reset() {
-	*x = SliceRateLimit{}
-	mi := &file_request_proto_msgTypes[21]
+func (x *AnomaliesRequest) Reset() {
+	*x = AnomaliesRequest{}
+	mi := &file_request_proto_msgTypes[71]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SliceRateLimit) String() string {
+func (x *AnomaliesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SliceRateLimit) ProtoMessage() {}
+func (*AnomaliesRequest) ProtoMessage() {}
 
-func (x *SliceRateLimit) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[21]
+func (x *AnomaliesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[71]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1492,66 +5479,67 @@ func (x *SliceRateLimit) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SliceRateLimit.ProtoReflect.Descriptor instead.
-func (*SliceRateLimit) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{21}
-}
-
-func (x *SliceRateLimit) GetN6Bps() int32 {
-	if x != nil {
-		return x.N6Bps
-	}
-	return 0
+// Deprecated: Use AnomaliesRequest.ProtoReflect.Descriptor instead.
+func (*AnomaliesRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{71}
 }
 
-func (x *SliceRateLimit) GetN6BurstBytes() int32 {
+func (x *AnomaliesRequest) GetFseid() string {
 	if x != nil {
-		return x.N6BurstBytes
+		return x.Fseid
 	}
-	return 0
+	return ""
 }
 
-func (x *SliceRateLimit) GetN3Bps() int32 {
+func (x *AnomaliesRequest) GetImsi() string {
 	if x != nil {
-		return x.N3Bps
+		return x.Imsi
 	}
-	return 0
+	return ""
 }
 
-func (x *SliceRateLimit) GetN3BurstBytes() int32 {
+func (x *AnomaliesRequest) GetMinScore() float64 {
 	if x != nil {
-		return x.N3BurstBytes
+		return x.MinScore
 	}
 	return 0
 }
 
-// CPInterface defines the configuration for the Control Plane interface
-type CPInterface struct {
+// AnomalyEvent is the start or end of a deviation of a session from its baseline
+type AnomalyEvent struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
-	Peers           []string               `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`                                                 // List of peer addresses
-	Dnn             string                 `protobuf:"bytes,2,opt,name=dnn,proto3" json:"dnn,omitempty"`                                                     // Data Network Name
-	HttpPort        string                 `protobuf:"bytes,3,opt,name=http_port,json=httpPort,proto3" json:"http_port,omitempty"`                           // HTTP port for communication
-	EnableUeIpAlloc bool                   `protobuf:"varint,4,opt,name=enable_ue_ip_alloc,json=enableUeIpAlloc,proto3" json:"enable_ue_ip_alloc,omitempty"` // Flag to enable UE IP allocation
-	UeIpPool        string                 `protobuf:"bytes,5,opt,name=ue_ip_pool,json=ueIpPool,proto3" json:"ue_ip_pool,omitempty"`                         // UE IP address pool
+	Fseid           string                 `protobuf:"bytes,1,opt,name=fseid,proto3" json:"fseid,omitempty"`                                                // Session the anomaly was detected on
+	Imsi            string                 `protobuf:"bytes,2,opt,name=imsi,proto3" json:"imsi,omitempty"`                                                  // IMSI owning the session, if known
+	Dnn             string                 `protobuf:"bytes,3,opt,name=dnn,proto3" json:"dnn,omitempty"`                                                    // DNN of the session, if known
+	Kind            string                 `protobuf:"bytes,4,opt,name=kind,proto3" json:"kind,omitempty"`                                                  // spike, stall or asymmetry
+	Direction       string                 `protobuf:"bytes,5,opt,name=direction,proto3" json:"direction,omitempty"`                                        // ul or dl for spikes and stalls, empty for asymmetry
+	State           string                 `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`                                                // started or ended
+	Score           float64                `protobuf:"fixed64,7,opt,name=score,proto3" json:"score,omitempty"`                                              // Deviation from the baseline in standard deviations; the peak once ended
+	Value           float64                `protobuf:"fixed64,8,opt,name=value,proto3" json:"value,omitempty"`                                              // Value at the start, in unit
+	BaselineMean    float64                `protobuf:"fixed64,9,opt,name=baseline_mean,json=baselineMean,proto3" json:"baseline_mean,omitempty"`            // Baseline mean when the anomaly started, in unit
+	BaselineStddev  float64                `protobuf:"fixed64,10,opt,name=baseline_stddev,json=baselineStddev,proto3" json:"baseline_stddev,omitempty"`     // Baseline standard deviation when the anomaly started, in unit
+	Unit            string                 `protobuf:"bytes,11,opt,name=unit,proto3" json:"unit,omitempty"`                                                 // "bit/s", or "ln(ul/dl)", the natural log of the uplink to downlink ratio, for asymmetry
+	StartedUnixNano int64                  `protobuf:"varint,12,opt,name=started_unix_nano,json=startedUnixNano,proto3" json:"started_unix_nano,omitempty"` // When the anomaly started
+	AtUnixNano      int64                  `protobuf:"varint,13,opt,name=at_unix_nano,json=atUnixNano,proto3" json:"at_unix_nano,omitempty"`                // When the anomaly entered this state
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
 
-func (x *CPInterface) Reset() {
-	*x = CPInterface{}
-	mi := &file_request_proto_msgTypes[22]
+func (x *AnomalyEvent) Reset() {
+	*x = AnomalyEvent{}
+	mi := &file_request_proto_msgTypes[72]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CPInterface) String() string {
+func (x *AnomalyEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CPInterface) ProtoMessage() {}
+func (*AnomalyEvent) ProtoMessage() {}
 
-func (x *CPInterface) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[22]
+func (x *AnomalyEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[72]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1562,74 +5550,124 @@ func (x *CPInterface) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CPInterface.ProtoReflect.Descriptor instead.
-func (*CPInterface) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use AnomalyEvent.ProtoReflect.Descriptor instead.
+func (*AnomalyEvent) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{72}
 }
 
-func (x *CPInterface) GetPeers() []string {
+func (x *AnomalyEvent) GetFseid() string {
 	if x != nil {
-		return x.Peers
+		return x.Fseid
 	}
-	return nil
+	return ""
 }
 
-func (x *CPInterface) GetDnn() string {
+func (x *AnomalyEvent) GetImsi() string {
+	if x != nil {
+		return x.Imsi
+	}
+	return ""
+}
+
+func (x *AnomalyEvent) GetDnn() string {
 	if x != nil {
 		return x.Dnn
 	}
 	return ""
 }
 
-func (x *CPInterface) GetHttpPort() string {
+func (x *AnomalyEvent) GetKind() string {
 	if x != nil {
-		return x.HttpPort
+		return x.Kind
 	}
 	return ""
 }
 
-func (x *CPInterface) GetEnableUeIpAlloc() bool {
+func (x *AnomalyEvent) GetDirection() string {
 	if x != nil {
-		return x.EnableUeIpAlloc
+		return x.Direction
 	}
-	return false
+	return ""
 }
 
-func (x *CPInterface) GetUeIpPool() string {
+func (x *AnomalyEvent) GetState() string {
 	if x != nil {
-		return x.UeIpPool
+		return x.State
 	}
 	return ""
 }
 
-// P4RTCInterface defines the configuration for the P4 Runtime Traffic Control interface
-type P4RTCInterface struct {
-	state               protoimpl.MessageState `protogen:"open.v1"`
-	AccessIp            string                 `protobuf:"bytes,1,opt,name=access_ip,json=accessIp,proto3" json:"access_ip,omitempty"`                                       // Access IP address
-	P4RtcServer         string                 `protobuf:"bytes,2,opt,name=p4rtc_server,json=p4rtcServer,proto3" json:"p4rtc_server,omitempty"`                              // P4 Runtime server address
-	P4RtcPort           string                 `protobuf:"bytes,3,opt,name=p4rtc_port,json=p4rtcPort,proto3" json:"p4rtc_port,omitempty"`                                    // P4 Runtime server port
-	SliceId             int32                  `protobuf:"varint,4,opt,name=slice_id,json=sliceId,proto3" json:"slice_id,omitempty"`                                         // Slice identifier
-	DefaultTc           int32                  `protobuf:"varint,5,opt,name=default_tc,json=defaultTc,proto3" json:"default_tc,omitempty"`                                   // Default traffic class
-	ClearStateOnRestart bool                   `protobuf:"varint,6,opt,name=clear_state_on_restart,json=clearStateOnRestart,proto3" json:"clear_state_on_restart,omitempty"` // Flag to clear state on restart
-	unknownFields       protoimpl.UnknownFields
-	sizeCache           protoimpl.SizeCache
+func (x *AnomalyEvent) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
 }
 
-func (x *P4RTCInterface) Reset() {
-	*x = P4RTCInterface{}
-	mi := &file_request_proto_msgTypes[23]
+func (x *AnomalyEvent) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *AnomalyEvent) GetBaselineMean() float64 {
+	if x != nil {
+		return x.BaselineMean
+	}
+	return 0
+}
+
+func (x *AnomalyEvent) GetBaselineStddev() float64 {
+	if x != nil {
+		return x.BaselineStddev
+	}
+	return 0
+}
+
+func (x *AnomalyEvent) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *AnomalyEvent) GetStartedUnixNano() int64 {
+	if x != nil {
+		return x.StartedUnixNano
+	}
+	return 0
+}
+
+func (x *AnomalyEvent) GetAtUnixNano() int64 {
+	if x != nil {
+		return x.AtUnixNano
+	}
+	return 0
+}
+
+// SlicePolicersRequest is empty as it doesn't need parameters
+type SlicePolicersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SlicePolicersRequest) Reset() {
+	*x = SlicePolicersRequest{}
+	mi := &file_request_proto_msgTypes[73]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *P4RTCInterface) String() string {
+func (x *SlicePolicersRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*P4RTCInterface) ProtoMessage() {}
+func (*SlicePolicersRequest) ProtoMessage() {}
 
-func (x *P4RTCInterface) ProtoReflect() protoreflect.Message {
-	mi := &file_request_proto_msgTypes[23]
+func (x *SlicePolicersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[73]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1640,60 +5678,174 @@ func (x *P4RTCInterface) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use P4RTCInterface.ProtoReflect.Descriptor instead.
-func (*P4RTCInterface) Descriptor() ([]byte, []int) {
-	return file_request_proto_rawDescGZIP(), []int{23}
+// Deprecated: Use SlicePolicersRequest.ProtoReflect.Descriptor instead.
+func (*SlicePolicersRequest) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{73}
 }
 
-func (x *P4RTCInterface) GetAccessIp() string {
+// SlicePolicer reports one direction of the policer every simulated session of a slice passes
+type SlicePolicer struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Slice          string                 `protobuf:"bytes,1,opt,name=slice,proto3" json:"slice,omitempty"`                                          // Slice ID
+	Direction      string                 `protobuf:"bytes,2,opt,name=direction,proto3" json:"direction,omitempty"`                                  // uplink (N3) or downlink (N6)
+	RateBps        uint64                 `protobuf:"varint,3,opt,name=rate_bps,json=rateBps,proto3" json:"rate_bps,omitempty"`                      // Rate limit in bits per second, 0 for none
+	BurstBytes     uint64                 `protobuf:"varint,4,opt,name=burst_bytes,json=burstBytes,proto3" json:"burst_bytes,omitempty"`             // Burst size in bytes
+	PassedPackets  uint64                 `protobuf:"varint,5,opt,name=passed_packets,json=passedPackets,proto3" json:"passed_packets,omitempty"`    // Packets forwarded since the UPF started
+	PassedBytes    uint64                 `protobuf:"varint,6,opt,name=passed_bytes,json=passedBytes,proto3" json:"passed_bytes,omitempty"`          // Bytes forwarded since the UPF started
+	DroppedPackets uint64                 `protobuf:"varint,7,opt,name=dropped_packets,json=droppedPackets,proto3" json:"dropped_packets,omitempty"` // Packets dropped for exceeding the rate limit
+	DroppedBytes   uint64                 `protobuf:"varint,8,opt,name=dropped_bytes,json=droppedBytes,proto3" json:"dropped_bytes,omitempty"`       // Bytes dropped for exceeding the rate limit
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SlicePolicer) Reset() {
+	*x = SlicePolicer{}
+	mi := &file_request_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SlicePolicer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SlicePolicer) ProtoMessage() {}
+
+func (x *SlicePolicer) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[74]
 	if x != nil {
-		return x.AccessIp
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *P4RTCInterface) GetP4RtcServer() string {
+// Deprecated: Use SlicePolicer.ProtoReflect.Descriptor instead.
+func (*SlicePolicer) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *SlicePolicer) GetSlice() string {
 	if x != nil {
-		return x.P4RtcServer
+		return x.Slice
 	}
 	return ""
 }
 
-func (x *P4RTCInterface) GetP4RtcPort() string {
+func (x *SlicePolicer) GetDirection() string {
 	if x != nil {
-		return x.P4RtcPort
+		return x.Direction
 	}
 	return ""
 }
 
-func (x *P4RTCInterface) GetSliceId() int32 {
+func (x *SlicePolicer) GetRateBps() uint64 {
 	if x != nil {
-		return x.SliceId
+		return x.RateBps
 	}
 	return 0
 }
 
-func (x *P4RTCInterface) GetDefaultTc() int32 {
+func (x *SlicePolicer) GetBurstBytes() uint64 {
 	if x != nil {
-		return x.DefaultTc
+		return x.BurstBytes
 	}
 	return 0
 }
 
-func (x *P4RTCInterface) GetClearStateOnRestart() bool {
+func (x *SlicePolicer) GetPassedPackets() uint64 {
 	if x != nil {
-		return x.ClearStateOnRestart
+		return x.PassedPackets
 	}
-	return false
+	return 0
+}
+
+func (x *SlicePolicer) GetPassedBytes() uint64 {
+	if x != nil {
+		return x.PassedBytes
+	}
+	return 0
+}
+
+func (x *SlicePolicer) GetDroppedPackets() uint64 {
+	if x != nil {
+		return x.DroppedPackets
+	}
+	return 0
+}
+
+func (x *SlicePolicer) GetDroppedBytes() uint64 {
+	if x != nil {
+		return x.DroppedBytes
+	}
+	return 0
+}
+
+// SlicePolicersReply lists the slice policers
+type SlicePolicersReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Policers      []*SlicePolicer        `protobuf:"bytes,1,rep,name=policers,proto3" json:"policers,omitempty"` // Policers ordered by slice and direction
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SlicePolicersReply) Reset() {
+	*x = SlicePolicersReply{}
+	mi := &file_request_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SlicePolicersReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SlicePolicersReply) ProtoMessage() {}
+
+func (x *SlicePolicersReply) ProtoReflect() protoreflect.Message {
+	mi := &file_request_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SlicePolicersReply.ProtoReflect.Descriptor instead.
+func (*SlicePolicersReply) Descriptor() ([]byte, []int) {
+	return file_request_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *SlicePolicersReply) GetPolicers() []*SlicePolicer {
+	if x != nil {
+		return x.Policers
+	}
+	return nil
 }
 
 var File_request_proto protoreflect.FileDescriptor
 
 const file_request_proto_rawDesc = "" +
 	"\n" +
-	"\rrequest.proto\x12\x06client\"#\n" +
+	"\rrequest.proto\x12\x06client\"\x81\x02\n" +
 	"\vFlowRequest\x12\x14\n" +
-	"\x05fseid\x18\x01 \x01(\tR\x05fseid\"\xee\x01\n" +
+	"\x05fseid\x18\x01 \x01(\tR\x05fseid\x12\x16\n" +
+	"\x06fseids\x18\x02 \x03(\tR\x06fseids\x12\x12\n" +
+	"\x04imsi\x18\x03 \x01(\tR\x04imsi\x12\x10\n" +
+	"\x03dnn\x18\x04 \x01(\tR\x03dnn\x12\x1f\n" +
+	"\vinterval_ms\x18\x05 \x01(\rR\n" +
+	"intervalMs\x12\x1b\n" +
+	"\ton_change\x18\x06 \x01(\bR\bonChange\x12\x16\n" +
+	"\x06fields\x18\a \x03(\tR\x06fields\x12'\n" +
+	"\x0fsubscription_id\x18\b \x01(\x04R\x0esubscriptionId\x12\x1f\n" +
+	"\vresume_from\x18\t \x01(\x04R\n" +
+	"resumeFrom\"\x95\a\n" +
 	"\x05Reply\x12#\n" +
 	"\rTotal_Packets\x18\x01 \x01(\x04R\fTotalPackets\x12\x1b\n" +
 	"\tRx_Packet\x18\x02 \x01(\x04R\bRxPacket\x12\x1b\n" +
@@ -1703,7 +5855,41 @@ const file_request_proto_rawDesc = "" +
 	"\vTotal_Speed\x18\x06 \x01(\x04R\n" +
 	"TotalSpeed\x12\x19\n" +
 	"\bAll_IMSI\x18\a \x03(\tR\aAllIMSI\x12\x14\n" +
-	"\x05count\x18\b \x01(\x04R\x05count\"\x0f\n" +
+	"\x05count\x18\b \x01(\x04R\x05count\x12\x19\n" +
+	"\bRx_Bytes\x18\t \x01(\x04R\aRxBytes\x12\x19\n" +
+	"\bTx_Bytes\x18\n" +
+	" \x01(\x04R\aTxBytes\x12$\n" +
+	"\x0eRx_Packet_Rate\x18\v \x01(\x01R\fRxPacketRate\x12$\n" +
+	"\x0eTx_Packet_Rate\x18\f \x01(\x01R\fTxPacketRate\x12 \n" +
+	"\fRx_Speed_Avg\x18\r \x01(\x01R\n" +
+	"RxSpeedAvg\x12 \n" +
+	"\fTx_Speed_Avg\x18\x0e \x01(\x01R\n" +
+	"TxSpeedAvg\x12+\n" +
+	"\x12Rx_Packet_Rate_Avg\x18\x0f \x01(\x01R\x0fRxPacketRateAvg\x12+\n" +
+	"\x12Tx_Packet_Rate_Avg\x18\x10 \x01(\x01R\x0fTxPacketRateAvg\x12\x1d\n" +
+	"\n" +
+	"Speed_Unit\x18\x11 \x01(\tR\tSpeedUnit\x12(\n" +
+	"\x10Packet_Rate_Unit\x18\x12 \x01(\tR\x0ePacketRateUnit\x12,\n" +
+	"\x12Measured_Unix_Nano\x18\x13 \x01(\x03R\x10MeasuredUnixNano\x12\x1f\n" +
+	"\vInterval_Ms\x18\x14 \x01(\x03R\n" +
+	"IntervalMs\x12\x14\n" +
+	"\x05fseid\x18\x15 \x01(\tR\x05fseid\x12\x12\n" +
+	"\x04imsi\x18\x16 \x01(\tR\x04imsi\x12'\n" +
+	"\x0fsubscription_id\x18\x17 \x01(\x04R\x0esubscriptionId\x12\x10\n" +
+	"\x03gap\x18\x18 \x01(\bR\x03gap\x12\x19\n" +
+	"\bgap_from\x18\x19 \x01(\x04R\agapFrom\x12\x15\n" +
+	"\x06gap_to\x18\x1a \x01(\x04R\x05gapTo\x12*\n" +
+	"\x06Rx_QoS\x18\x1b \x01(\v2\x13.client.QosCountersR\x05RxQoS\x12*\n" +
+	"\x06Tx_QoS\x18\x1c \x01(\v2\x13.client.QosCountersR\x05TxQoS\"\xdb\x01\n" +
+	"\vQosCounters\x12#\n" +
+	"\rgreen_packets\x18\x01 \x01(\x04R\fgreenPackets\x12\x1f\n" +
+	"\vgreen_bytes\x18\x02 \x01(\x04R\n" +
+	"greenBytes\x12%\n" +
+	"\x0eyellow_packets\x18\x03 \x01(\x04R\ryellowPackets\x12!\n" +
+	"\fyellow_bytes\x18\x04 \x01(\x04R\vyellowBytes\x12\x1f\n" +
+	"\vred_packets\x18\x05 \x01(\x04R\n" +
+	"redPackets\x12\x1b\n" +
+	"\tred_bytes\x18\x06 \x01(\x04R\bredBytes\"\x0f\n" +
 	"\rConfigRequest\"8\n" +
 	"\vConfigReply\x12)\n" +
 	"\x06config\x18\x01 \x01(\v2\x11.client.UPFConfigR\x06config\"!\n" +
@@ -1719,31 +5905,140 @@ const file_request_proto_rawDesc = "" +
 	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\"#\n" +
 	"\vRuleRequest\x12\x14\n" +
-	"\x05fsied\x18\x01 \x01(\tR\x05fsied\"9\n" +
+	"\x05fsied\x18\x01 \x01(\tR\x05fsied\">\n" +
+	"\x0ePutRuleRequest\x12,\n" +
+	"\asession\x18\x01 \x01(\v2\x12.client.rulestructR\asession\"9\n" +
 	"\tRuleReply\x12,\n" +
-	"\asession\x18\x01 \x01(\v2\x12.client.rulestructR\asession\"\xa0\x01\n" +
+	"\asession\x18\x01 \x01(\v2\x12.client.rulestructR\asession\"\xc6\x01\n" +
 	"\n" +
 	"rulestruct\x12#\n" +
 	"\x03pdr\x18\x01 \x01(\v2\x11.client.pdrstructR\x03pdr\x12#\n" +
 	"\x03far\x18\x02 \x01(\v2\x11.client.farstructR\x03far\x12#\n" +
 	"\x03qer\x18\x03 \x01(\v2\x11.client.qerstructR\x03qer\x12#\n" +
-	"\x03urr\x18\x04 \x01(\v2\x11.client.urrstructR\x03urr\"8\n" +
+	"\x03urr\x18\x04 \x01(\v2\x11.client.urrstructR\x03urr\x12\x12\n" +
+	"\x04imsi\x18\x05 \x01(\tR\x04imsi\x12\x10\n" +
+	"\x03dnn\x18\x06 \x01(\tR\x03dnn\"a\n" +
 	"\tpdrstruct\x12\x15\n" +
 	"\x06pdr_id\x18\x01 \x03(\tR\x05pdrId\x12\x14\n" +
-	"\x05fsied\x18\x02 \x01(\tR\x05fsied\"8\n" +
+	"\x05fsied\x18\x02 \x01(\tR\x05fsied\x12\x13\n" +
+	"\x05ue_ip\x18\x03 \x01(\tR\x04ueIp\x12\x12\n" +
+	"\x04teid\x18\x04 \x01(\tR\x04teid\"\x95\x01\n" +
 	"\tfarstruct\x12\x15\n" +
 	"\x06far_id\x18\x01 \x01(\tR\x05farId\x12\x14\n" +
-	"\x05fsied\x18\x02 \x01(\tR\x05fsied\"8\n" +
+	"\x05fsied\x18\x02 \x01(\tR\x05fsied\x12!\n" +
+	"\fapply_action\x18\x03 \x01(\tR\vapplyAction\x12\x19\n" +
+	"\bouter_ip\x18\x04 \x01(\tR\aouterIp\x12\x1d\n" +
+	"\n" +
+	"outer_teid\x18\x05 \x01(\tR\touterTeid\"\xa6\x01\n" +
 	"\tqerstruct\x12\x15\n" +
 	"\x06qer_id\x18\x01 \x01(\tR\x05qerId\x12\x14\n" +
-	"\x05fsied\x18\x02 \x01(\tR\x05fsied\"8\n" +
+	"\x05fsied\x18\x02 \x01(\tR\x05fsied\x12\x10\n" +
+	"\x03qfi\x18\x03 \x01(\rR\x03qfi\x12\x15\n" +
+	"\x06ul_mbr\x18\x04 \x01(\x04R\x05ulMbr\x12\x15\n" +
+	"\x06dl_mbr\x18\x05 \x01(\x04R\x05dlMbr\x12\x15\n" +
+	"\x06ul_gbr\x18\x06 \x01(\x04R\x05ulGbr\x12\x15\n" +
+	"\x06dl_gbr\x18\a \x01(\x04R\x05dlGbr\"\xd9\x02\n" +
 	"\turrstruct\x12\x15\n" +
 	"\x06urr_id\x18\x01 \x01(\tR\x05urrId\x12\x14\n" +
-	"\x05fsied\x18\x02 \x01(\tR\x05fsied\":\n" +
+	"\x05fsied\x18\x02 \x01(\tR\x05fsied\x12-\n" +
+	"\x12measurement_method\x18\x03 \x03(\tR\x11measurementMethod\x12-\n" +
+	"\x12reporting_triggers\x18\x04 \x03(\tR\x11reportingTriggers\x12)\n" +
+	"\x10volume_threshold\x18\x05 \x01(\x04R\x0fvolumeThreshold\x12!\n" +
+	"\fvolume_quota\x18\x06 \x01(\x04R\vvolumeQuota\x12%\n" +
+	"\x0etime_threshold\x18\a \x01(\rR\rtimeThreshold\x12\x1d\n" +
+	"\n" +
+	"time_quota\x18\b \x01(\rR\ttimeQuota\x12-\n" +
+	"\x12measurement_period\x18\t \x01(\rR\x11measurementPeriod\"\x13\n" +
+	"\x11TableUsageRequest\"f\n" +
+	"\n" +
+	"TableUsage\x12\x14\n" +
+	"\x05table\x18\x01 \x01(\tR\x05table\x12\x1a\n" +
+	"\bcapacity\x18\x02 \x01(\x03R\bcapacity\x12\x12\n" +
+	"\x04used\x18\x03 \x01(\x03R\x04used\x12\x12\n" +
+	"\x04free\x18\x04 \x01(\x03R\x04free\"=\n" +
+	"\x0fTableUsageReply\x12*\n" +
+	"\x06tables\x18\x01 \x03(\v2\x12.client.TableUsageR\x06tables\"K\n" +
+	"\rSessionFilter\x12\x14\n" +
+	"\x05fseid\x18\x01 \x03(\tR\x05fseid\x12\x12\n" +
+	"\x04imsi\x18\x02 \x03(\tR\x04imsi\x12\x10\n" +
+	"\x03dnn\x18\x03 \x03(\tR\x03dnn\"d\n" +
+	"\x0fSessionSnapshot\x12!\n" +
+	"\fcreated_unix\x18\x01 \x01(\x03R\vcreatedUnix\x12.\n" +
+	"\bsessions\x18\x02 \x03(\v2\x12.client.rulestructR\bsessions\"v\n" +
+	"\x15ExportSessionsRequest\x12-\n" +
+	"\x06filter\x18\x01 \x01(\v2\x15.client.SessionFilterR\x06filter\x12.\n" +
+	"\x06format\x18\x02 \x01(\x0e2\x16.client.SnapshotFormatR\x06format\"w\n" +
+	"\x13ExportSessionsReply\x12\x1a\n" +
+	"\bsnapshot\x18\x01 \x01(\fR\bsnapshot\x12.\n" +
+	"\x06format\x18\x02 \x01(\x0e2\x16.client.SnapshotFormatR\x06format\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x05R\x05count\"\x8b\x01\n" +
+	"\x15ImportSessionsRequest\x12\x1a\n" +
+	"\bsnapshot\x18\x01 \x01(\fR\bsnapshot\x12.\n" +
+	"\x06format\x18\x02 \x01(\x0e2\x16.client.SnapshotFormatR\x06format\x12&\n" +
+	"\x04mode\x18\x03 \x01(\x0e2\x12.client.ImportModeR\x04mode\">\n" +
+	"\x0eImportConflict\x12\x14\n" +
+	"\x05fseid\x18\x01 \x01(\tR\x05fseid\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\x9f\x01\n" +
+	"\x13ImportSessionsReply\x12\x1a\n" +
+	"\bimported\x18\x01 \x01(\x05R\bimported\x12\x1c\n" +
+	"\tunchanged\x18\x02 \x01(\x05R\tunchanged\x12\x18\n" +
+	"\aremoved\x18\x03 \x01(\x05R\aremoved\x124\n" +
+	"\tconflicts\x18\x04 \x03(\v2\x16.client.ImportConflictR\tconflicts\"\x88\x02\n" +
+	"\n" +
+	"AuditEvent\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12.\n" +
+	"\x13timestamp_unix_nano\x18\x02 \x01(\x03R\x11timestampUnixNano\x12\x16\n" +
+	"\x06caller\x18\x03 \x01(\tR\x06caller\x12\x1c\n" +
+	"\toperation\x18\x04 \x01(\tR\toperation\x12\x16\n" +
+	"\x06action\x18\x05 \x01(\tR\x06action\x12\x14\n" +
+	"\x05fseid\x18\x06 \x01(\tR\x05fseid\x12*\n" +
+	"\x06before\x18\a \x01(\v2\x12.client.rulestructR\x06before\x12(\n" +
+	"\x05after\x18\b \x01(\v2\x12.client.rulestructR\x05after\"-\n" +
+	"\x15SessionHistoryRequest\x12\x14\n" +
+	"\x05fseid\x18\x01 \x01(\tR\x05fseid\"\x85\x01\n" +
+	"\vAuditFilter\x12\x14\n" +
+	"\x05fseid\x18\x01 \x01(\tR\x05fseid\x12\x12\n" +
+	"\x04imsi\x18\x02 \x01(\tR\x04imsi\x12\x16\n" +
+	"\x06caller\x18\x03 \x01(\tR\x06caller\x12\x1c\n" +
+	"\toperation\x18\x04 \x01(\tR\toperation\x12\x16\n" +
+	"\x06action\x18\x05 \x01(\tR\x06action\"\xa7\x01\n" +
+	"\x16ListAuditEventsRequest\x12+\n" +
+	"\x06filter\x18\x01 \x01(\v2\x13.client.AuditFilterR\x06filter\x12&\n" +
+	"\x0fstart_unix_nano\x18\x02 \x01(\x03R\rstartUnixNano\x12\"\n" +
+	"\rend_unix_nano\x18\x03 \x01(\x03R\vendUnixNano\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\">\n" +
+	"\x10AuditEventsReply\x12*\n" +
+	"\x06events\x18\x01 \x03(\v2\x12.client.AuditEventR\x06events\"}\n" +
+	"\fP4FieldMatch\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x14\n" +
+	"\x05exact\x18\x02 \x01(\fR\x05exact\x12\x1b\n" +
+	"\tlpm_value\x18\x03 \x01(\fR\blpmValue\x12$\n" +
+	"\x0elpm_prefix_len\x18\x04 \x01(\x05R\flpmPrefixLen\";\n" +
+	"\rP4ActionParam\x12\x14\n" +
+	"\x05param\x18\x01 \x01(\tR\x05param\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\"Q\n" +
+	"\bP4Action\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\x12-\n" +
+	"\x06params\x18\x02 \x03(\v2\x15.client.P4ActionParamR\x06params\"z\n" +
+	"\fP4TableEntry\x12\x14\n" +
+	"\x05table\x18\x01 \x01(\tR\x05table\x12*\n" +
+	"\x05match\x18\x02 \x03(\v2\x14.client.P4FieldMatchR\x05match\x12(\n" +
+	"\x06action\x18\x03 \x01(\v2\x10.client.P4ActionR\x06action\"U\n" +
+	"\bP4Update\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x125\n" +
+	"\vtable_entry\x18\x02 \x01(\v2\x14.client.P4TableEntryR\n" +
+	"tableEntry\"<\n" +
+	"\x0eP4WriteRequest\x12*\n" +
+	"\aupdates\x18\x01 \x03(\v2\x10.client.P4UpdateR\aupdates\"(\n" +
+	"\x10CompileP4Request\x12\x14\n" +
+	"\x05fseid\x18\x01 \x03(\tR\x05fseid\"\\\n" +
+	"\x0eCompileP4Reply\x12,\n" +
+	"\x05write\x18\x01 \x01(\v2\x16.client.P4WriteRequestR\x05write\x12\x1c\n" +
+	"\tprototext\x18\x02 \x01(\tR\tprototext\":\n" +
 	"\n" +
 	"IMSIStruct\x12\x1a\n" +
 	"\bInternet\x18\x01 \x01(\tR\bInternet\x12\x10\n" +
-	"\x03IMS\x18\x02 \x01(\tR\x03IMS\"\xea\x06\n" +
+	"\x03IMS\x18\x02 \x01(\tR\x03IMS\"\xb1\t\n" +
 	"\tUPFConfig\x12\x12\n" +
 	"\x04mode\x18\x01 \x01(\tR\x04mode\x123\n" +
 	"\vtable_sizes\x18\x02 \x01(\v2\x12.client.TableSizesR\n" +
@@ -1773,14 +6068,21 @@ const file_request_proto_rawDesc = "" +
 	"\acpiface\x18\x15 \x01(\v2\x13.client.CPInterfaceR\acpiface\x126\n" +
 	"\n" +
 	"p4rtciface\x18\x16 \x01(\v2\x16.client.P4RTCInterfaceR\n" +
-	"p4rtciface\"\xba\x01\n" +
+	"p4rtciface\x12<\n" +
+	"\fflow_history\x18\x17 \x01(\v2\x19.client.FlowHistoryConfigR\vflowHistory\x12,\n" +
+	"\x06alerts\x18\x18 \x01(\v2\x14.client.AlertsConfigR\x06alerts\x12/\n" +
+	"\ametrics\x18\x19 \x01(\v2\x15.client.MetricsConfigR\ametrics\x12)\n" +
+	"\x05ipfix\x18\x1a \x01(\v2\x13.client.IPFIXConfigR\x05ipfix\x122\n" +
+	"\brecorder\x18\x1b \x01(\v2\x16.client.RecorderConfigR\brecorder\x12\x19\n" +
+	"\bdata_dir\x18\x1c \x01(\tR\adataDir\x12.\n" +
+	"\x13heart_beat_interval\x18\x1d \x01(\tR\x11heartBeatInterval\"\xba\x01\n" +
 	"\n" +
 	"TableSizes\x12\x1c\n" +
 	"\tpdrLookup\x18\x01 \x01(\x05R\tpdrLookup\x12 \n" +
 	"\vflowMeasure\x18\x02 \x01(\x05R\vflowMeasure\x12\"\n" +
 	"\fappQERLookup\x18\x03 \x01(\x05R\fappQERLookup\x12*\n" +
 	"\x10sessionQERLookup\x18\x04 \x01(\x05R\x10sessionQERLookup\x12\x1c\n" +
-	"\tfarLookup\x18\x05 \x01(\x05R\tfarLookup\"\xe8\x03\n" +
+	"\tfarLookup\x18\x05 \x01(\x05R\tfarLookup\"\xc2\x04\n" +
 	"\tSimConfig\x12\x12\n" +
 	"\x04core\x18\x01 \x01(\tR\x04core\x12!\n" +
 	"\fmax_sessions\x18\x02 \x01(\x05R\vmaxSessions\x12\x1e\n" +
@@ -1801,7 +6103,11 @@ const file_request_proto_rawDesc = "" +
 	"\fdownlink_gbr\x18\r \x01(\x05R\vdownlinkGbr\x12\x19\n" +
 	"\bpkt_size\x18\x0e \x01(\x05R\apktSize\x12\x1f\n" +
 	"\vtotal_flows\x18\x0f \x01(\x05R\n" +
-	"totalFlows\"#\n" +
+	"totalFlows\x12\x12\n" +
+	"\x04seed\x18\x10 \x01(\x03R\x04seed\x12\x1f\n" +
+	"\vuplink_ambr\x18\x11 \x01(\x05R\n" +
+	"uplinkAmbr\x12#\n" +
+	"\rdownlink_ambr\x18\x12 \x01(\x05R\fdownlinkAmbr\"#\n" +
 	"\tInterface\x12\x16\n" +
 	"\x06ifname\x18\x01 \x01(\tR\x06ifname\"\x9b\x01\n" +
 	"\tQoSConfig\x12\x10\n" +
@@ -1831,14 +6137,234 @@ const file_request_proto_rawDesc = "" +
 	"\bslice_id\x18\x04 \x01(\x05R\asliceId\x12\x1d\n" +
 	"\n" +
 	"default_tc\x18\x05 \x01(\x05R\tdefaultTc\x123\n" +
-	"\x16clear_state_on_restart\x18\x06 \x01(\bR\x13clearStateOnRestart2\xa1\x02\n" +
+	"\x16clear_state_on_restart\x18\x06 \x01(\bR\x13clearStateOnRestart\"m\n" +
+	"\x11FlowHistoryConfig\x12\x1b\n" +
+	"\tmax_flows\x18\x01 \x01(\x05R\bmaxFlows\x12;\n" +
+	"\vresolutions\x18\x02 \x03(\v2\x19.client.HistoryResolutionR\vresolutions\"E\n" +
+	"\x11HistoryResolution\x12\x12\n" +
+	"\x04step\x18\x01 \x01(\tR\x04step\x12\x1c\n" +
+	"\tretention\x18\x02 \x01(\tR\tretention\"G\n" +
+	"\fAlertsConfig\x12\x1d\n" +
+	"\n" +
+	"rules_file\x18\x01 \x01(\tR\trulesFile\x12\x18\n" +
+	"\awebhook\x18\x02 \x01(\tR\awebhook\"D\n" +
+	"\rMetricsConfig\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\tR\x04port\x12\x1f\n" +
+	"\vper_session\x18\x02 \x01(\bR\n" +
+	"perSession\"\xf7\x01\n" +
+	"\vIPFIXConfig\x12\x1c\n" +
+	"\tcollector\x18\x01 \x01(\tR\tcollector\x12\x1c\n" +
+	"\ttransport\x18\x02 \x01(\tR\ttransport\x12%\n" +
+	"\x0eactive_timeout\x18\x03 \x01(\tR\ractiveTimeout\x12)\n" +
+	"\x10template_refresh\x18\x04 \x01(\tR\x0ftemplateRefresh\x12+\n" +
+	"\x11enterprise_number\x18\x05 \x01(\rR\x10enterpriseNumber\x12-\n" +
+	"\x12observation_domain\x18\x06 \x01(\rR\x11observationDomain\"\xa3\x01\n" +
+	"\x0eRecorderConfig\x12\x10\n" +
+	"\x03dir\x18\x01 \x01(\tR\x03dir\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12\x1a\n" +
+	"\binterval\x18\x03 \x01(\tR\binterval\x12\x1e\n" +
+	"\vmax_size_mb\x18\x04 \x01(\x05R\tmaxSizeMb\x12\x17\n" +
+	"\amax_age\x18\x05 \x01(\tR\x06maxAge\x12\x12\n" +
+	"\x04gzip\x18\x06 \x01(\bR\x04gzip\"\x12\n" +
+	"\x10PFCPPeersRequest\"\x8e\x02\n" +
+	"\bPFCPPeer\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x1e\n" +
+	"\n" +
+	"configured\x18\x04 \x01(\bR\n" +
+	"configured\x12(\n" +
+	"\x10state_since_unix\x18\x05 \x01(\x03R\x0estateSinceUnix\x12.\n" +
+	"\x13last_heartbeat_unix\x18\x06 \x01(\x03R\x11lastHeartbeatUnix\x12#\n" +
+	"\rrecovery_unix\x18\a \x01(\x03R\frecoveryUnix\x12\x1a\n" +
+	"\bsessions\x18\b \x01(\x05R\bsessions\"8\n" +
+	"\x0ePFCPPeersReply\x12&\n" +
+	"\x05peers\x18\x01 \x03(\v2\x10.client.PFCPPeerR\x05peers\"?\n" +
+	"\x13UsageReportsRequest\x12\x14\n" +
+	"\x05fseid\x18\x01 \x01(\tR\x05fseid\x12\x12\n" +
+	"\x04imsi\x18\x02 \x01(\tR\x04imsi\"\xa4\x04\n" +
+	"\x10UsageReportEvent\x12\x14\n" +
+	"\x05fseid\x18\x01 \x01(\tR\x05fseid\x12\x12\n" +
+	"\x04imsi\x18\x02 \x01(\tR\x04imsi\x12\x15\n" +
+	"\x06urr_id\x18\x03 \x01(\tR\x05urrId\x12\x1a\n" +
+	"\bsequence\x18\x04 \x01(\rR\bsequence\x12\x1a\n" +
+	"\btriggers\x18\x05 \x03(\tR\btriggers\x12&\n" +
+	"\x0fstart_unix_nano\x18\x06 \x01(\x03R\rstartUnixNano\x12\"\n" +
+	"\rend_unix_nano\x18\a \x01(\x03R\vendUnixNano\x12\x19\n" +
+	"\bul_bytes\x18\b \x01(\x04R\aulBytes\x12\x19\n" +
+	"\bdl_bytes\x18\t \x01(\x04R\adlBytes\x12\x1f\n" +
+	"\vtotal_bytes\x18\n" +
+	" \x01(\x04R\n" +
+	"totalBytes\x12\x1d\n" +
+	"\n" +
+	"ul_packets\x18\v \x01(\x04R\tulPackets\x12\x1d\n" +
+	"\n" +
+	"dl_packets\x18\f \x01(\x04R\tdlPackets\x12#\n" +
+	"\rtotal_packets\x18\r \x01(\x04R\ftotalPackets\x12)\n" +
+	"\x10duration_seconds\x18\x0e \x01(\rR\x0fdurationSeconds\x123\n" +
+	"\x16first_packet_unix_nano\x18\x0f \x01(\x03R\x13firstPacketUnixNano\x121\n" +
+	"\x15last_packet_unix_nano\x18\x10 \x01(\x03R\x12lastPacketUnixNano\"\x87\x01\n" +
+	"\x12FlowHistoryRequest\x12\x14\n" +
+	"\x05fseid\x18\x01 \x01(\tR\x05fseid\x12\x1d\n" +
+	"\n" +
+	"start_unix\x18\x02 \x01(\x03R\tstartUnix\x12\x19\n" +
+	"\bend_unix\x18\x03 \x01(\x03R\aendUnix\x12!\n" +
+	"\fstep_seconds\x18\x04 \x01(\rR\vstepSeconds\"\xe4\x02\n" +
+	"\x10FlowHistoryPoint\x12\x12\n" +
+	"\x04unix\x18\x01 \x01(\x03R\x04unix\x12\x19\n" +
+	"\brx_bytes\x18\x02 \x01(\x04R\arxBytes\x12\x19\n" +
+	"\btx_bytes\x18\x03 \x01(\x04R\atxBytes\x12\x1d\n" +
+	"\n" +
+	"rx_packets\x18\x04 \x01(\x04R\trxPackets\x12\x1d\n" +
+	"\n" +
+	"tx_packets\x18\x05 \x01(\x04R\ttxPackets\x12\x19\n" +
+	"\brx_speed\x18\x06 \x01(\x01R\arxSpeed\x12\x19\n" +
+	"\btx_speed\x18\a \x01(\x01R\atxSpeed\x12$\n" +
+	"\x0erx_packet_rate\x18\b \x01(\x01R\frxPacketRate\x12$\n" +
+	"\x0etx_packet_rate\x18\t \x01(\x01R\ftxPacketRate\x12\"\n" +
+	"\rrx_speed_peak\x18\n" +
+	" \x01(\x01R\vrxSpeedPeak\x12\"\n" +
+	"\rtx_speed_peak\x18\v \x01(\x01R\vtxSpeedPeak\"\xf5\x01\n" +
+	"\x10FlowHistoryReply\x12\x14\n" +
+	"\x05fseid\x18\x01 \x01(\tR\x05fseid\x12!\n" +
+	"\fstep_seconds\x18\x02 \x01(\rR\vstepSeconds\x12-\n" +
+	"\x12resolution_seconds\x18\x03 \x01(\rR\x11resolutionSeconds\x120\n" +
+	"\x06points\x18\x04 \x03(\v2\x18.client.FlowHistoryPointR\x06points\x12\x1d\n" +
+	"\n" +
+	"speed_unit\x18\x05 \x01(\tR\tspeedUnit\x12(\n" +
+	"\x10packet_rate_unit\x18\x06 \x01(\tR\x0epacketRateUnit\"k\n" +
+	"\x15AggregateStatsRequest\x12\x19\n" +
+	"\bgroup_by\x18\x01 \x01(\tR\agroupBy\x12\x16\n" +
+	"\x06filter\x18\x02 \x03(\tR\x06filter\x12\x1f\n" +
+	"\vinterval_ms\x18\x03 \x01(\rR\n" +
+	"intervalMs\"\xb4\x02\n" +
+	"\x0eAggregateGroup\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1a\n" +
+	"\bsessions\x18\x02 \x01(\rR\bsessions\x12\x1d\n" +
+	"\n" +
+	"rx_packets\x18\x03 \x01(\x04R\trxPackets\x12\x1d\n" +
+	"\n" +
+	"tx_packets\x18\x04 \x01(\x04R\ttxPackets\x12\x19\n" +
+	"\brx_bytes\x18\x05 \x01(\x04R\arxBytes\x12\x19\n" +
+	"\btx_bytes\x18\x06 \x01(\x04R\atxBytes\x12\x19\n" +
+	"\brx_speed\x18\a \x01(\x01R\arxSpeed\x12\x19\n" +
+	"\btx_speed\x18\b \x01(\x01R\atxSpeed\x12$\n" +
+	"\x0erx_packet_rate\x18\t \x01(\x01R\frxPacketRate\x12$\n" +
+	"\x0etx_packet_rate\x18\n" +
+	" \x01(\x01R\ftxPacketRate\"\xd7\x01\n" +
+	"\x13AggregateStatsReply\x12\x19\n" +
+	"\bgroup_by\x18\x01 \x01(\tR\agroupBy\x12.\n" +
+	"\x06groups\x18\x02 \x03(\v2\x16.client.AggregateGroupR\x06groups\x12,\n" +
+	"\x12measured_unix_nano\x18\x03 \x01(\x03R\x10measuredUnixNano\x12\x1d\n" +
+	"\n" +
+	"speed_unit\x18\x04 \x01(\tR\tspeedUnit\x12(\n" +
+	"\x10packet_rate_unit\x18\x05 \x01(\tR\x0epacketRateUnit\"a\n" +
+	"\x12TopSessionsRequest\x12\x16\n" +
+	"\x06metric\x18\x01 \x01(\tR\x06metric\x12\f\n" +
+	"\x01n\x18\x02 \x01(\rR\x01n\x12%\n" +
+	"\x0ewindow_seconds\x18\x03 \x01(\rR\rwindowSeconds\"\xa6\x01\n" +
+	"\n" +
+	"TopSession\x12\x14\n" +
+	"\x05fseid\x18\x01 \x01(\tR\x05fseid\x12\x12\n" +
+	"\x04imsi\x18\x02 \x01(\tR\x04imsi\x12\x10\n" +
+	"\x03dnn\x18\x03 \x01(\tR\x03dnn\x12\x14\n" +
+	"\x05bytes\x18\x04 \x01(\x04R\x05bytes\x12\x18\n" +
+	"\apackets\x18\x05 \x01(\x04R\apackets\x12\x12\n" +
+	"\x04rate\x18\x06 \x01(\x01R\x04rate\x12\x18\n" +
+	"\aseconds\x18\a \x01(\x01R\aseconds\"\xe6\x01\n" +
+	"\x10TopSessionsReply\x12\x16\n" +
+	"\x06metric\x18\x01 \x01(\tR\x06metric\x12%\n" +
+	"\x0ewindow_seconds\x18\x02 \x01(\rR\rwindowSeconds\x12.\n" +
+	"\bsessions\x18\x03 \x03(\v2\x12.client.TopSessionR\bsessions\x12\x16\n" +
+	"\x06ranked\x18\x04 \x01(\rR\x06ranked\x12,\n" +
+	"\x12measured_unix_nano\x18\x05 \x01(\x03R\x10measuredUnixNano\x12\x1d\n" +
+	"\n" +
+	"speed_unit\x18\x06 \x01(\tR\tspeedUnit\"9\n" +
+	"\rAlertsRequest\x12\x12\n" +
+	"\x04rule\x18\x01 \x01(\tR\x04rule\x12\x14\n" +
+	"\x05fseid\x18\x02 \x01(\tR\x05fseid\"\xc4\x02\n" +
+	"\n" +
+	"AlertEvent\x12\x12\n" +
+	"\x04rule\x18\x01 \x01(\tR\x04rule\x12\x1a\n" +
+	"\bseverity\x18\x02 \x01(\tR\bseverity\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x16\n" +
+	"\x06target\x18\x04 \x01(\tR\x06target\x12\x12\n" +
+	"\x04imsi\x18\x05 \x01(\tR\x04imsi\x12\x10\n" +
+	"\x03dnn\x18\x06 \x01(\tR\x03dnn\x12\x16\n" +
+	"\x06metric\x18\a \x01(\tR\x06metric\x12\x14\n" +
+	"\x05value\x18\b \x01(\x01R\x05value\x12\x1c\n" +
+	"\tthreshold\x18\t \x01(\x01R\tthreshold\x12\x18\n" +
+	"\asummary\x18\n" +
+	" \x01(\tR\asummary\x12*\n" +
+	"\x11started_unix_nano\x18\v \x01(\x03R\x0fstartedUnixNano\x12 \n" +
+	"\fat_unix_nano\x18\f \x01(\x03R\n" +
+	"atUnixNano\"Y\n" +
+	"\x10AnomaliesRequest\x12\x14\n" +
+	"\x05fseid\x18\x01 \x01(\tR\x05fseid\x12\x12\n" +
+	"\x04imsi\x18\x02 \x01(\tR\x04imsi\x12\x1b\n" +
+	"\tmin_score\x18\x03 \x01(\x01R\bminScore\"\xee\x02\n" +
+	"\fAnomalyEvent\x12\x14\n" +
+	"\x05fseid\x18\x01 \x01(\tR\x05fseid\x12\x12\n" +
+	"\x04imsi\x18\x02 \x01(\tR\x04imsi\x12\x10\n" +
+	"\x03dnn\x18\x03 \x01(\tR\x03dnn\x12\x12\n" +
+	"\x04kind\x18\x04 \x01(\tR\x04kind\x12\x1c\n" +
+	"\tdirection\x18\x05 \x01(\tR\tdirection\x12\x14\n" +
+	"\x05state\x18\x06 \x01(\tR\x05state\x12\x14\n" +
+	"\x05score\x18\a \x01(\x01R\x05score\x12\x14\n" +
+	"\x05value\x18\b \x01(\x01R\x05value\x12#\n" +
+	"\rbaseline_mean\x18\t \x01(\x01R\fbaselineMean\x12'\n" +
+	"\x0fbaseline_stddev\x18\n" +
+	" \x01(\x01R\x0ebaselineStddev\x12\x12\n" +
+	"\x04unit\x18\v \x01(\tR\x04unit\x12*\n" +
+	"\x11started_unix_nano\x18\f \x01(\x03R\x0fstartedUnixNano\x12 \n" +
+	"\fat_unix_nano\x18\r \x01(\x03R\n" +
+	"atUnixNano\"\x16\n" +
+	"\x14SlicePolicersRequest\"\x96\x02\n" +
+	"\fSlicePolicer\x12\x14\n" +
+	"\x05slice\x18\x01 \x01(\tR\x05slice\x12\x1c\n" +
+	"\tdirection\x18\x02 \x01(\tR\tdirection\x12\x19\n" +
+	"\brate_bps\x18\x03 \x01(\x04R\arateBps\x12\x1f\n" +
+	"\vburst_bytes\x18\x04 \x01(\x04R\n" +
+	"burstBytes\x12%\n" +
+	"\x0epassed_packets\x18\x05 \x01(\x04R\rpassedPackets\x12!\n" +
+	"\fpassed_bytes\x18\x06 \x01(\x04R\vpassedBytes\x12'\n" +
+	"\x0fdropped_packets\x18\a \x01(\x04R\x0edroppedPackets\x12#\n" +
+	"\rdropped_bytes\x18\b \x01(\x04R\fdroppedBytes\"F\n" +
+	"\x12SlicePolicersReply\x120\n" +
+	"\bpolicers\x18\x01 \x03(\v2\x14.client.SlicePolicerR\bpolicers*8\n" +
+	"\x0eSnapshotFormat\x12\x11\n" +
+	"\rSNAPSHOT_JSON\x10\x00\x12\x13\n" +
+	"\x0fSNAPSHOT_BINARY\x10\x01*2\n" +
+	"\n" +
+	"ImportMode\x12\x10\n" +
+	"\fIMPORT_MERGE\x10\x00\x12\x12\n" +
+	"\x0eIMPORT_REPLACE\x10\x012\xa8\f\n" +
 	"\aRequest\x122\n" +
 	"\n" +
 	"PutRequest\x12\x13.client.FlowRequest\x1a\r.client.Reply0\x01\x127\n" +
 	"\tGetConfig\x12\x15.client.ConfigRequest\x1a\x13.client.ConfigReply\x121\n" +
 	"\aGetIMSI\x12\x13.client.IMSIRequest\x1a\x11.client.IMSIReply\x121\n" +
 	"\aGetRule\x12\x13.client.RuleRequest\x1a\x11.client.RuleReply\x12C\n" +
-	"\vValidatePDR\x12\x1a.client.ValidatePDRRequest\x1a\x18.client.ValidatePDRReplyB\x13Z\x11pkg/proto;requestb\x06proto3"
+	"\vValidatePDR\x12\x1a.client.ValidatePDRRequest\x1a\x18.client.ValidatePDRReply\x124\n" +
+	"\aPutRule\x12\x16.client.PutRuleRequest\x1a\x11.client.RuleReply\x124\n" +
+	"\n" +
+	"DeleteRule\x12\x13.client.RuleRequest\x1a\x11.client.RuleReply\x12C\n" +
+	"\rGetTableUsage\x12\x19.client.TableUsageRequest\x1a\x17.client.TableUsageReply\x12L\n" +
+	"\x0eExportSessions\x12\x1d.client.ExportSessionsRequest\x1a\x1b.client.ExportSessionsReply\x12L\n" +
+	"\x0eImportSessions\x12\x1d.client.ImportSessionsRequest\x1a\x1b.client.ImportSessionsReply\x12L\n" +
+	"\x11GetSessionHistory\x12\x1d.client.SessionHistoryRequest\x1a\x18.client.AuditEventsReply\x12K\n" +
+	"\x0fListAuditEvents\x12\x1e.client.ListAuditEventsRequest\x1a\x18.client.AuditEventsReply\x12B\n" +
+	"\x0eCompileP4Rules\x12\x18.client.CompileP4Request\x1a\x16.client.CompileP4Reply\x12A\n" +
+	"\rListPFCPPeers\x12\x18.client.PFCPPeersRequest\x1a\x16.client.PFCPPeersReply\x12M\n" +
+	"\x12StreamUsageReports\x12\x1b.client.UsageReportsRequest\x1a\x18.client.UsageReportEvent0\x01\x12F\n" +
+	"\x0eGetFlowHistory\x12\x1a.client.FlowHistoryRequest\x1a\x18.client.FlowHistoryReply\x12O\n" +
+	"\x11GetAggregateStats\x12\x1d.client.AggregateStatsRequest\x1a\x1b.client.AggregateStatsReply\x12T\n" +
+	"\x14StreamAggregateStats\x12\x1d.client.AggregateStatsRequest\x1a\x1b.client.AggregateStatsReply0\x01\x12C\n" +
+	"\vTopSessions\x12\x1a.client.TopSessionsRequest\x1a\x18.client.TopSessionsReply\x12;\n" +
+	"\fStreamAlerts\x12\x15.client.AlertsRequest\x1a\x12.client.AlertEvent0\x01\x12C\n" +
+	"\x0fStreamAnomalies\x12\x18.client.AnomaliesRequest\x1a\x14.client.AnomalyEvent0\x01\x12C\n" +
+	"\x11SetSliceRateLimit\x12\x16.client.SliceRateLimit\x1a\x16.client.SliceRateLimit\x12L\n" +
+	"\x10GetSlicePolicers\x12\x1c.client.SlicePolicersRequest\x1a\x1a.client.SlicePolicersReplyB\x13Z\x11pkg/proto;requestb\x06proto3"
 
 var (
 	file_request_proto_rawDescOnce sync.Once
@@ -1852,64 +6378,187 @@ func file_request_proto_rawDescGZIP() []byte {
 	return file_request_proto_rawDescData
 }
 
-var file_request_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_request_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_request_proto_msgTypes = make([]protoimpl.MessageInfo, 76)
 var file_request_proto_goTypes = []any{
-	(*FlowRequest)(nil),        // 0: client.FlowRequest
-	(*Reply)(nil),              // 1: client.Reply
-	(*ConfigRequest)(nil),      // 2: client.ConfigRequest
-	(*ConfigReply)(nil),        // 3: client.ConfigReply
-	(*IMSIRequest)(nil),        // 4: client.IMSIRequest
-	(*IMSIReply)(nil),          // 5: client.IMSIReply
-	(*ValidatePDRRequest)(nil), // 6: client.ValidatePDRRequest
-	(*ValidatePDRReply)(nil),   // 7: client.ValidatePDRReply
-	(*RuleRequest)(nil),        // 8: client.RuleRequest
-	(*RuleReply)(nil),          // 9: client.RuleReply
-	(*Rulestruct)(nil),         // 10: client.rulestruct
-	(*Pdrstruct)(nil),          // 11: client.pdrstruct
-	(*Farstruct)(nil),          // 12: client.farstruct
-	(*Qerstruct)(nil),          // 13: client.qerstruct
-	(*Urrstruct)(nil),          // 14: client.urrstruct
-	(*IMSIStruct)(nil),         // 15: client.IMSIStruct
-	(*UPFConfig)(nil),          // 16: client.UPFConfig
-	(*TableSizes)(nil),         // 17: client.TableSizes
-	(*SimConfig)(nil),          // 18: client.SimConfig
-	(*Interface)(nil),          // 19: client.Interface
-	(*QoSConfig)(nil),          // 20: client.QoSConfig
-	(*SliceRateLimit)(nil),     // 21: client.SliceRateLimit
-	(*CPInterface)(nil),        // 22: client.CPInterface
-	(*P4RTCInterface)(nil),     // 23: client.P4RTCInterface
+	(SnapshotFormat)(0),            // 0: client.SnapshotFormat
+	(ImportMode)(0),                // 1: client.ImportMode
+	(*FlowRequest)(nil),            // 2: client.FlowRequest
+	(*Reply)(nil),                  // 3: client.Reply
+	(*QosCounters)(nil),            // 4: client.QosCounters
+	(*ConfigRequest)(nil),          // 5: client.ConfigRequest
+	(*ConfigReply)(nil),            // 6: client.ConfigReply
+	(*IMSIRequest)(nil),            // 7: client.IMSIRequest
+	(*IMSIReply)(nil),              // 8: client.IMSIReply
+	(*ValidatePDRRequest)(nil),     // 9: client.ValidatePDRRequest
+	(*ValidatePDRReply)(nil),       // 10: client.ValidatePDRReply
+	(*RuleRequest)(nil),            // 11: client.RuleRequest
+	(*PutRuleRequest)(nil),         // 12: client.PutRuleRequest
+	(*RuleReply)(nil),              // 13: client.RuleReply
+	(*Rulestruct)(nil),             // 14: client.rulestruct
+	(*Pdrstruct)(nil),              // 15: client.pdrstruct
+	(*Farstruct)(nil),              // 16: client.farstruct
+	(*Qerstruct)(nil),              // 17: client.qerstruct
+	(*Urrstruct)(nil),              // 18: client.urrstruct
+	(*TableUsageRequest)(nil),      // 19: client.TableUsageRequest
+	(*TableUsage)(nil),             // 20: client.TableUsage
+	(*TableUsageReply)(nil),        // 21: client.TableUsageReply
+	(*SessionFilter)(nil),          // 22: client.SessionFilter
+	(*SessionSnapshot)(nil),        // 23: client.SessionSnapshot
+	(*ExportSessionsRequest)(nil),  // 24: client.ExportSessionsRequest
+	(*ExportSessionsReply)(nil),    // 25: client.ExportSessionsReply
+	(*ImportSessionsRequest)(nil),  // 26: client.ImportSessionsRequest
+	(*ImportConflict)(nil),         // 27: client.ImportConflict
+	(*ImportSessionsReply)(nil),    // 28: client.ImportSessionsReply
+	(*AuditEvent)(nil),             // 29: client.AuditEvent
+	(*SessionHistoryRequest)(nil),  // 30: client.SessionHistoryRequest
+	(*AuditFilter)(nil),            // 31: client.AuditFilter
+	(*ListAuditEventsRequest)(nil), // 32: client.ListAuditEventsRequest
+	(*AuditEventsReply)(nil),       // 33: client.AuditEventsReply
+	(*P4FieldMatch)(nil),           // 34: client.P4FieldMatch
+	(*P4ActionParam)(nil),          // 35: client.P4ActionParam
+	(*P4Action)(nil),               // 36: client.P4Action
+	(*P4TableEntry)(nil),           // 37: client.P4TableEntry
+	(*P4Update)(nil),               // 38: client.P4Update
+	(*P4WriteRequest)(nil),         // 39: client.P4WriteRequest
+	(*CompileP4Request)(nil),       // 40: client.CompileP4Request
+	(*CompileP4Reply)(nil),         // 41: client.CompileP4Reply
+	(*IMSIStruct)(nil),             // 42: client.IMSIStruct
+	(*UPFConfig)(nil),              // 43: client.UPFConfig
+	(*TableSizes)(nil),             // 44: client.TableSizes
+	(*SimConfig)(nil),              // 45: client.SimConfig
+	(*Interface)(nil),              // 46: client.Interface
+	(*QoSConfig)(nil),              // 47: client.QoSConfig
+	(*SliceRateLimit)(nil),         // 48: client.SliceRateLimit
+	(*CPInterface)(nil),            // 49: client.CPInterface
+	(*P4RTCInterface)(nil),         // 50: client.P4RTCInterface
+	(*FlowHistoryConfig)(nil),      // 51: client.FlowHistoryConfig
+	(*HistoryResolution)(nil),      // 52: client.HistoryResolution
+	(*AlertsConfig)(nil),           // 53: client.AlertsConfig
+	(*MetricsConfig)(nil),          // 54: client.MetricsConfig
+	(*IPFIXConfig)(nil),            // 55: client.IPFIXConfig
+	(*RecorderConfig)(nil),         // 56: client.RecorderConfig
+	(*PFCPPeersRequest)(nil),       // 57: client.PFCPPeersRequest
+	(*PFCPPeer)(nil),               // 58: client.PFCPPeer
+	(*PFCPPeersReply)(nil),         // 59: client.PFCPPeersReply
+	(*UsageReportsRequest)(nil),    // 60: client.UsageReportsRequest
+	(*UsageReportEvent)(nil),       // 61: client.UsageReportEvent
+	(*FlowHistoryRequest)(nil),     // 62: client.FlowHistoryRequest
+	(*FlowHistoryPoint)(nil),       // 63: client.FlowHistoryPoint
+	(*FlowHistoryReply)(nil),       // 64: client.FlowHistoryReply
+	(*AggregateStatsRequest)(nil),  // 65: client.AggregateStatsRequest
+	(*AggregateGroup)(nil),         // 66: client.AggregateGroup
+	(*AggregateStatsReply)(nil),    // 67: client.AggregateStatsReply
+	(*TopSessionsRequest)(nil),     // 68: client.TopSessionsRequest
+	(*TopSession)(nil),             // 69: client.TopSession
+	(*TopSessionsReply)(nil),       // 70: client.TopSessionsReply
+	(*AlertsRequest)(nil),          // 71: client.AlertsRequest
+	(*AlertEvent)(nil),             // 72: client.AlertEvent
+	(*AnomaliesRequest)(nil),       // 73: client.AnomaliesRequest
+	(*AnomalyEvent)(nil),           // 74: client.AnomalyEvent
+	(*SlicePolicersRequest)(nil),   // 75: client.SlicePolicersRequest
+	(*SlicePolicer)(nil),           // 76: client.SlicePolicer
+	(*SlicePolicersReply)(nil),     // 77: client.SlicePolicersReply
 }
 var file_request_proto_depIdxs = []int32{
-	16, // 0: client.ConfigReply.config:type_name -> client.UPFConfig
-	15, // 1: client.IMSIReply.imsi:type_name -> client.IMSIStruct
-	10, // 2: client.RuleReply.session:type_name -> client.rulestruct
-	11, // 3: client.rulestruct.pdr:type_name -> client.pdrstruct
-	12, // 4: client.rulestruct.far:type_name -> client.farstruct
-	13, // 5: client.rulestruct.qer:type_name -> client.qerstruct
-	14, // 6: client.rulestruct.urr:type_name -> client.urrstruct
-	17, // 7: client.UPFConfig.table_sizes:type_name -> client.TableSizes
-	18, // 8: client.UPFConfig.sim:type_name -> client.SimConfig
-	19, // 9: client.UPFConfig.access:type_name -> client.Interface
-	19, // 10: client.UPFConfig.core:type_name -> client.Interface
-	20, // 11: client.UPFConfig.qci_qos_config:type_name -> client.QoSConfig
-	21, // 12: client.UPFConfig.slice_rate_limit_config:type_name -> client.SliceRateLimit
-	22, // 13: client.UPFConfig.cpiface:type_name -> client.CPInterface
-	23, // 14: client.UPFConfig.p4rtciface:type_name -> client.P4RTCInterface
-	0,  // 15: client.Request.PutRequest:input_type -> client.FlowRequest
-	2,  // 16: client.Request.GetConfig:input_type -> client.ConfigRequest
-	4,  // 17: client.Request.GetIMSI:input_type -> client.IMSIRequest
-	8,  // 18: client.Request.GetRule:input_type -> client.RuleRequest
-	6,  // 19: client.Request.ValidatePDR:input_type -> client.ValidatePDRRequest
-	1,  // 20: client.Request.PutRequest:output_type -> client.Reply
-	3,  // 21: client.Request.GetConfig:output_type -> client.ConfigReply
-	5,  // 22: client.Request.GetIMSI:output_type -> client.IMSIReply
-	9,  // 23: client.Request.GetRule:output_type -> client.RuleReply
-	7,  // 24: client.Request.ValidatePDR:output_type -> client.ValidatePDRReply
-	20, // [20:25] is the sub-list for method output_type
-	15, // [15:20] is the sub-list for method input_type
-	15, // [15:15] is the sub-list for extension type_name
-	15, // [15:15] is the sub-list for extension extendee
-	0,  // [0:15] is the sub-list for field type_name
+	4,  // 0: client.Reply.Rx_QoS:type_name -> client.QosCounters
+	4,  // 1: client.Reply.Tx_QoS:type_name -> client.QosCounters
+	43, // 2: client.ConfigReply.config:type_name -> client.UPFConfig
+	42, // 3: client.IMSIReply.imsi:type_name -> client.IMSIStruct
+	14, // 4: client.PutRuleRequest.session:type_name -> client.rulestruct
+	14, // 5: client.RuleReply.session:type_name -> client.rulestruct
+	15, // 6: client.rulestruct.pdr:type_name -> client.pdrstruct
+	16, // 7: client.rulestruct.far:type_name -> client.farstruct
+	17, // 8: client.rulestruct.qer:type_name -> client.qerstruct
+	18, // 9: client.rulestruct.urr:type_name -> client.urrstruct
+	20, // 10: client.TableUsageReply.tables:type_name -> client.TableUsage
+	14, // 11: client.SessionSnapshot.sessions:type_name -> client.rulestruct
+	22, // 12: client.ExportSessionsRequest.filter:type_name -> client.SessionFilter
+	0,  // 13: client.ExportSessionsRequest.format:type_name -> client.SnapshotFormat
+	0,  // 14: client.ExportSessionsReply.format:type_name -> client.SnapshotFormat
+	0,  // 15: client.ImportSessionsRequest.format:type_name -> client.SnapshotFormat
+	1,  // 16: client.ImportSessionsRequest.mode:type_name -> client.ImportMode
+	27, // 17: client.ImportSessionsReply.conflicts:type_name -> client.ImportConflict
+	14, // 18: client.AuditEvent.before:type_name -> client.rulestruct
+	14, // 19: client.AuditEvent.after:type_name -> client.rulestruct
+	31, // 20: client.ListAuditEventsRequest.filter:type_name -> client.AuditFilter
+	29, // 21: client.AuditEventsReply.events:type_name -> client.AuditEvent
+	35, // 22: client.P4Action.params:type_name -> client.P4ActionParam
+	34, // 23: client.P4TableEntry.match:type_name -> client.P4FieldMatch
+	36, // 24: client.P4TableEntry.action:type_name -> client.P4Action
+	37, // 25: client.P4Update.table_entry:type_name -> client.P4TableEntry
+	38, // 26: client.P4WriteRequest.updates:type_name -> client.P4Update
+	39, // 27: client.CompileP4Reply.write:type_name -> client.P4WriteRequest
+	44, // 28: client.UPFConfig.table_sizes:type_name -> client.TableSizes
+	45, // 29: client.UPFConfig.sim:type_name -> client.SimConfig
+	46, // 30: client.UPFConfig.access:type_name -> client.Interface
+	46, // 31: client.UPFConfig.core:type_name -> client.Interface
+	47, // 32: client.UPFConfig.qci_qos_config:type_name -> client.QoSConfig
+	48, // 33: client.UPFConfig.slice_rate_limit_config:type_name -> client.SliceRateLimit
+	49, // 34: client.UPFConfig.cpiface:type_name -> client.CPInterface
+	50, // 35: client.UPFConfig.p4rtciface:type_name -> client.P4RTCInterface
+	51, // 36: client.UPFConfig.flow_history:type_name -> client.FlowHistoryConfig
+	53, // 37: client.UPFConfig.alerts:type_name -> client.AlertsConfig
+	54, // 38: client.UPFConfig.metrics:type_name -> client.MetricsConfig
+	55, // 39: client.UPFConfig.ipfix:type_name -> client.IPFIXConfig
+	56, // 40: client.UPFConfig.recorder:type_name -> client.RecorderConfig
+	52, // 41: client.FlowHistoryConfig.resolutions:type_name -> client.HistoryResolution
+	58, // 42: client.PFCPPeersReply.peers:type_name -> client.PFCPPeer
+	63, // 43: client.FlowHistoryReply.points:type_name -> client.FlowHistoryPoint
+	66, // 44: client.AggregateStatsReply.groups:type_name -> client.AggregateGroup
+	69, // 45: client.TopSessionsReply.sessions:type_name -> client.TopSession
+	76, // 46: client.SlicePolicersReply.policers:type_name -> client.SlicePolicer
+	2,  // 47: client.Request.PutRequest:input_type -> client.FlowRequest
+	5,  // 48: client.Request.GetConfig:input_type -> client.ConfigRequest
+	7,  // 49: client.Request.GetIMSI:input_type -> client.IMSIRequest
+	11, // 50: client.Request.GetRule:input_type -> client.RuleRequest
+	9,  // 51: client.Request.ValidatePDR:input_type -> client.ValidatePDRRequest
+	12, // 52: client.Request.PutRule:input_type -> client.PutRuleRequest
+	11, // 53: client.Request.DeleteRule:input_type -> client.RuleRequest
+	19, // 54: client.Request.GetTableUsage:input_type -> client.TableUsageRequest
+	24, // 55: client.Request.ExportSessions:input_type -> client.ExportSessionsRequest
+	26, // 56: client.Request.ImportSessions:input_type -> client.ImportSessionsRequest
+	30, // 57: client.Request.GetSessionHistory:input_type -> client.SessionHistoryRequest
+	32, // 58: client.Request.ListAuditEvents:input_type -> client.ListAuditEventsRequest
+	40, // 59: client.Request.CompileP4Rules:input_type -> client.CompileP4Request
+	57, // 60: client.Request.ListPFCPPeers:input_type -> client.PFCPPeersRequest
+	60, // 61: client.Request.StreamUsageReports:input_type -> client.UsageReportsRequest
+	62, // 62: client.Request.GetFlowHistory:input_type -> client.FlowHistoryRequest
+	65, // 63: client.Request.GetAggregateStats:input_type -> client.AggregateStatsRequest
+	65, // 64: client.Request.StreamAggregateStats:input_type -> client.AggregateStatsRequest
+	68, // 65: client.Request.TopSessions:input_type -> client.TopSessionsRequest
+	71, // 66: client.Request.StreamAlerts:input_type -> client.AlertsRequest
+	73, // 67: client.Request.StreamAnomalies:input_type -> client.AnomaliesRequest
+	48, // 68: client.Request.SetSliceRateLimit:input_type -> client.SliceRateLimit
+	75, // 69: client.Request.GetSlicePolicers:input_type -> client.SlicePolicersRequest
+	3,  // 70: client.Request.PutRequest:output_type -> client.Reply
+	6,  // 71: client.Request.GetConfig:output_type -> client.ConfigReply
+	8,  // 72: client.Request.GetIMSI:output_type -> client.IMSIReply
+	13, // 73: client.Request.GetRule:output_type -> client.RuleReply
+	10, // 74: client.Request.ValidatePDR:output_type -> client.ValidatePDRReply
+	13, // 75: client.Request.PutRule:output_type -> client.RuleReply
+	13, // 76: client.Request.DeleteRule:output_type -> client.RuleReply
+	21, // 77: client.Request.GetTableUsage:output_type -> client.TableUsageReply
+	25, // 78: client.Request.ExportSessions:output_type -> client.ExportSessionsReply
+	28, // 79: client.Request.ImportSessions:output_type -> client.ImportSessionsReply
+	33, // 80: client.Request.GetSessionHistory:output_type -> client.AuditEventsReply
+	33, // 81: client.Request.ListAuditEvents:output_type -> client.AuditEventsReply
+	41, // 82: client.Request.CompileP4Rules:output_type -> client.CompileP4Reply
+	59, // 83: client.Request.ListPFCPPeers:output_type -> client.PFCPPeersReply
+	61, // 84: client.Request.StreamUsageReports:output_type -> client.UsageReportEvent
+	64, // 85: client.Request.GetFlowHistory:output_type -> client.FlowHistoryReply
+	67, // 86: client.Request.GetAggregateStats:output_type -> client.AggregateStatsReply
+	67, // 87: client.Request.StreamAggregateStats:output_type -> client.AggregateStatsReply
+	70, // 88: client.Request.TopSessions:output_type -> client.TopSessionsReply
+	72, // 89: client.Request.StreamAlerts:output_type -> client.AlertEvent
+	74, // 90: client.Request.StreamAnomalies:output_type -> client.AnomalyEvent
+	48, // 91: client.Request.SetSliceRateLimit:output_type -> client.SliceRateLimit
+	77, // 92: client.Request.GetSlicePolicers:output_type -> client.SlicePolicersReply
+	70, // [70:93] is the sub-list for method output_type
+	47, // [47:70] is the sub-list for method input_type
+	47, // [47:47] is the sub-list for extension type_name
+	47, // [47:47] is the sub-list for extension extendee
+	0,  // [0:47] is the sub-list for field type_name
 }
 
 func init() { file_request_proto_init() }
@@ -1922,13 +6571,14 @@ func file_request_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_request_proto_rawDesc), len(file_request_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   24,
+			NumEnums:      2,
+			NumMessages:   76,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_request_proto_goTypes,
 		DependencyIndexes: file_request_proto_depIdxs,
+		EnumInfos:         file_request_proto_enumTypes,
 		MessageInfos:      file_request_proto_msgTypes,
 	}.Build()
 	File_request_proto = out.File
@@ -21,11 +21,29 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Request_PutRequest_FullMethodName  = "/client.Request/PutRequest"
-	Request_GetConfig_FullMethodName   = "/client.Request/GetConfig"
-	Request_GetIMSI_FullMethodName     = "/client.Request/GetIMSI"
-	Request_GetRule_FullMethodName     = "/client.Request/GetRule"
-	Request_ValidatePDR_FullMethodName = "/client.Request/ValidatePDR"
+	Request_PutRequest_FullMethodName           = "/client.Request/PutRequest"
+	Request_GetConfig_FullMethodName            = "/client.Request/GetConfig"
+	Request_GetIMSI_FullMethodName              = "/client.Request/GetIMSI"
+	Request_GetRule_FullMethodName              = "/client.Request/GetRule"
+	Request_ValidatePDR_FullMethodName          = "/client.Request/ValidatePDR"
+	Request_PutRule_FullMethodName              = "/client.Request/PutRule"
+	Request_DeleteRule_FullMethodName           = "/client.Request/DeleteRule"
+	Request_GetTableUsage_FullMethodName        = "/client.Request/GetTableUsage"
+	Request_ExportSessions_FullMethodName       = "/client.Request/ExportSessions"
+	Request_ImportSessions_FullMethodName       = "/client.Request/ImportSessions"
+	Request_GetSessionHistory_FullMethodName    = "/client.Request/GetSessionHistory"
+	Request_ListAuditEvents_FullMethodName      = "/client.Request/ListAuditEvents"
+	Request_CompileP4Rules_FullMethodName       = "/client.Request/CompileP4Rules"
+	Request_ListPFCPPeers_FullMethodName        = "/client.Request/ListPFCPPeers"
+	Request_StreamUsageReports_FullMethodName   = "/client.Request/StreamUsageReports"
+	Request_GetFlowHistory_FullMethodName       = "/client.Request/GetFlowHistory"
+	Request_GetAggregateStats_FullMethodName    = "/client.Request/GetAggregateStats"
+	Request_StreamAggregateStats_FullMethodName = "/client.Request/StreamAggregateStats"
+	Request_TopSessions_FullMethodName          = "/client.Request/TopSessions"
+	Request_StreamAlerts_FullMethodName         = "/client.Request/StreamAlerts"
+	Request_StreamAnomalies_FullMethodName      = "/client.Request/StreamAnomalies"
+	Request_SetSliceRateLimit_FullMethodName    = "/client.Request/SetSliceRateLimit"
+	Request_GetSlicePolicers_FullMethodName     = "/client.Request/GetSlicePolicers"
 )
 
 // RequestClient is the client API for Request service.
@@ -44,6 +62,42 @@ type RequestClient interface {
 	GetRule(ctx context.Context, in *RuleRequest, opts ...grpc.CallOption) (*RuleReply, error)
 	// ValidatePDR validates a PDR for a given IMSI and DNN
 	ValidatePDR(ctx context.Context, in *ValidatePDRRequest, opts ...grpc.CallOption) (*ValidatePDRReply, error)
+	// PutRule creates or replaces the rules of a session
+	PutRule(ctx context.Context, in *PutRuleRequest, opts ...grpc.CallOption) (*RuleReply, error)
+	// DeleteRule removes the rules of a session
+	DeleteRule(ctx context.Context, in *RuleRequest, opts ...grpc.CallOption) (*RuleReply, error)
+	// GetTableUsage reports used and free capacity of the UPF lookup tables
+	GetTableUsage(ctx context.Context, in *TableUsageRequest, opts ...grpc.CallOption) (*TableUsageReply, error)
+	// ExportSessions serializes the stored sessions into a snapshot
+	ExportSessions(ctx context.Context, in *ExportSessionsRequest, opts ...grpc.CallOption) (*ExportSessionsReply, error)
+	// ImportSessions loads sessions from a snapshot
+	ImportSessions(ctx context.Context, in *ImportSessionsRequest, opts ...grpc.CallOption) (*ImportSessionsReply, error)
+	// GetSessionHistory returns the audit events of a single session
+	GetSessionHistory(ctx context.Context, in *SessionHistoryRequest, opts ...grpc.CallOption) (*AuditEventsReply, error)
+	// ListAuditEvents returns audit events matching a filter and time range
+	ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*AuditEventsReply, error)
+	// CompileP4Rules translates session rules into UP4 table entries, named as in the P4Info, without writing them. Needs enable_p4rt
+	CompileP4Rules(ctx context.Context, in *CompileP4Request, opts ...grpc.CallOption) (*CompileP4Reply, error)
+	// ListPFCPPeers reports the N4 association state of every PFCP peer
+	ListPFCPPeers(ctx context.Context, in *PFCPPeersRequest, opts ...grpc.CallOption) (*PFCPPeersReply, error)
+	// StreamUsageReports streams the usage reports URRs emit when their reporting triggers fire
+	StreamUsageReports(ctx context.Context, in *UsageReportsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[UsageReportEvent], error)
+	// GetFlowHistory returns the recorded counters and rates of a session over a time range
+	GetFlowHistory(ctx context.Context, in *FlowHistoryRequest, opts ...grpc.CallOption) (*FlowHistoryReply, error)
+	// GetAggregateStats returns flow counters rolled up per IMSI, DNN, slice or for the whole UPF
+	GetAggregateStats(ctx context.Context, in *AggregateStatsRequest, opts ...grpc.CallOption) (*AggregateStatsReply, error)
+	// StreamAggregateStats streams the aggregates GetAggregateStats returns at an interval
+	StreamAggregateStats(ctx context.Context, in *AggregateStatsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AggregateStatsReply], error)
+	// TopSessions returns the sessions that moved the most traffic over a recent window
+	TopSessions(ctx context.Context, in *TopSessionsRequest, opts ...grpc.CallOption) (*TopSessionsReply, error)
+	// StreamAlerts streams the alerts raised by the alert rules as they change state
+	StreamAlerts(ctx context.Context, in *AlertsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AlertEvent], error)
+	// StreamAnomalies streams the traffic anomalies detected against the baselines of the sessions
+	StreamAnomalies(ctx context.Context, in *AnomaliesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AnomalyEvent], error)
+	// SetSliceRateLimit changes the slice policers of the running UPF without a restart
+	SetSliceRateLimit(ctx context.Context, in *SliceRateLimit, opts ...grpc.CallOption) (*SliceRateLimit, error)
+	// GetSlicePolicers reports the rate limits and drop counters of the slice policers
+	GetSlicePolicers(ctx context.Context, in *SlicePolicersRequest, opts ...grpc.CallOption) (*SlicePolicersReply, error)
 }
 
 type requestClient struct {
@@ -113,6 +167,222 @@ func (c *requestClient) ValidatePDR(ctx context.Context, in *ValidatePDRRequest,
 	return out, nil
 }
 
+func (c *requestClient) PutRule(ctx context.Context, in *PutRuleRequest, opts ...grpc.CallOption) (*RuleReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RuleReply)
+	err := c.cc.Invoke(ctx, Request_PutRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *requestClient) DeleteRule(ctx context.Context, in *RuleRequest, opts ...grpc.CallOption) (*RuleReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RuleReply)
+	err := c.cc.Invoke(ctx, Request_DeleteRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *requestClient) GetTableUsage(ctx context.Context, in *TableUsageRequest, opts ...grpc.CallOption) (*TableUsageReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TableUsageReply)
+	err := c.cc.Invoke(ctx, Request_GetTableUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *requestClient) ExportSessions(ctx context.Context, in *ExportSessionsRequest, opts ...grpc.CallOption) (*ExportSessionsReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportSessionsReply)
+	err := c.cc.Invoke(ctx, Request_ExportSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *requestClient) ImportSessions(ctx context.Context, in *ImportSessionsRequest, opts ...grpc.CallOption) (*ImportSessionsReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportSessionsReply)
+	err := c.cc.Invoke(ctx, Request_ImportSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *requestClient) GetSessionHistory(ctx context.Context, in *SessionHistoryRequest, opts ...grpc.CallOption) (*AuditEventsReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuditEventsReply)
+	err := c.cc.Invoke(ctx, Request_GetSessionHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *requestClient) ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*AuditEventsReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuditEventsReply)
+	err := c.cc.Invoke(ctx, Request_ListAuditEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *requestClient) CompileP4Rules(ctx context.Context, in *CompileP4Request, opts ...grpc.CallOption) (*CompileP4Reply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompileP4Reply)
+	err := c.cc.Invoke(ctx, Request_CompileP4Rules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *requestClient) ListPFCPPeers(ctx context.Context, in *PFCPPeersRequest, opts ...grpc.CallOption) (*PFCPPeersReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PFCPPeersReply)
+	err := c.cc.Invoke(ctx, Request_ListPFCPPeers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *requestClient) StreamUsageReports(ctx context.Context, in *UsageReportsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[UsageReportEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Request_ServiceDesc.Streams[1], Request_StreamUsageReports_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UsageReportsRequest, UsageReportEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Request_StreamUsageReportsClient = grpc.ServerStreamingClient[UsageReportEvent]
+
+func (c *requestClient) GetFlowHistory(ctx context.Context, in *FlowHistoryRequest, opts ...grpc.CallOption) (*FlowHistoryReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FlowHistoryReply)
+	err := c.cc.Invoke(ctx, Request_GetFlowHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *requestClient) GetAggregateStats(ctx context.Context, in *AggregateStatsRequest, opts ...grpc.CallOption) (*AggregateStatsReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AggregateStatsReply)
+	err := c.cc.Invoke(ctx, Request_GetAggregateStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *requestClient) StreamAggregateStats(ctx context.Context, in *AggregateStatsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AggregateStatsReply], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Request_ServiceDesc.Streams[2], Request_StreamAggregateStats_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AggregateStatsRequest, AggregateStatsReply]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Request_StreamAggregateStatsClient = grpc.ServerStreamingClient[AggregateStatsReply]
+
+func (c *requestClient) TopSessions(ctx context.Context, in *TopSessionsRequest, opts ...grpc.CallOption) (*TopSessionsReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TopSessionsReply)
+	err := c.cc.Invoke(ctx, Request_TopSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *requestClient) StreamAlerts(ctx context.Context, in *AlertsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AlertEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Request_ServiceDesc.Streams[3], Request_StreamAlerts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AlertsRequest, AlertEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Request_StreamAlertsClient = grpc.ServerStreamingClient[AlertEvent]
+
+func (c *requestClient) StreamAnomalies(ctx context.Context, in *AnomaliesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AnomalyEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Request_ServiceDesc.Streams[4], Request_StreamAnomalies_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AnomaliesRequest, AnomalyEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Request_StreamAnomaliesClient = grpc.ServerStreamingClient[AnomalyEvent]
+
+func (c *requestClient) SetSliceRateLimit(ctx context.Context, in *SliceRateLimit, opts ...grpc.CallOption) (*SliceRateLimit, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SliceRateLimit)
+	err := c.cc.Invoke(ctx, Request_SetSliceRateLimit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *requestClient) GetSlicePolicers(ctx context.Context, in *SlicePolicersRequest, opts ...grpc.CallOption) (*SlicePolicersReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SlicePolicersReply)
+	err := c.cc.Invoke(ctx, Request_GetSlicePolicers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RequestServer is the server API for Request service.
 // All implementations must embed UnimplementedRequestServer
 // for forward compatibility.
@@ -129,6 +399,42 @@ type RequestServer interface {
 	GetRule(context.Context, *RuleRequest) (*RuleReply, error)
 	// ValidatePDR validates a PDR for a given IMSI and DNN
 	ValidatePDR(context.Context, *ValidatePDRRequest) (*ValidatePDRReply, error)
+	// PutRule creates or replaces the rules of a session
+	PutRule(context.Context, *PutRuleRequest) (*RuleReply, error)
+	// DeleteRule removes the rules of a session
+	DeleteRule(context.Context, *RuleRequest) (*RuleReply, error)
+	// GetTableUsage reports used and free capacity of the UPF lookup tables
+	GetTableUsage(context.Context, *TableUsageRequest) (*TableUsageReply, error)
+	// ExportSessions serializes the stored sessions into a snapshot
+	ExportSessions(context.Context, *ExportSessionsRequest) (*ExportSessionsReply, error)
+	// ImportSessions loads sessions from a snapshot
+	ImportSessions(context.Context, *ImportSessionsRequest) (*ImportSessionsReply, error)
+	// GetSessionHistory returns the audit events of a single session
+	GetSessionHistory(context.Context, *SessionHistoryRequest) (*AuditEventsReply, error)
+	// ListAuditEvents returns audit events matching a filter and time range
+	ListAuditEvents(context.Context, *ListAuditEventsRequest) (*AuditEventsReply, error)
+	// CompileP4Rules translates session rules into UP4 table entries, named as in the P4Info, without writing them. Needs enable_p4rt
+	CompileP4Rules(context.Context, *CompileP4Request) (*CompileP4Reply, error)
+	// ListPFCPPeers reports the N4 association state of every PFCP peer
+	ListPFCPPeers(context.Context, *PFCPPeersRequest) (*PFCPPeersReply, error)
+	// StreamUsageReports streams the usage reports URRs emit when their reporting triggers fire
+	StreamUsageReports(*UsageReportsRequest, grpc.ServerStreamingServer[UsageReportEvent]) error
+	// GetFlowHistory returns the recorded counters and rates of a session over a time range
+	GetFlowHistory(context.Context, *FlowHistoryRequest) (*FlowHistoryReply, error)
+	// GetAggregateStats returns flow counters rolled up per IMSI, DNN, slice or for the whole UPF
+	GetAggregateStats(context.Context, *AggregateStatsRequest) (*AggregateStatsReply, error)
+	// StreamAggregateStats streams the aggregates GetAggregateStats returns at an interval
+	StreamAggregateStats(*AggregateStatsRequest, grpc.ServerStreamingServer[AggregateStatsReply]) error
+	// TopSessions returns the sessions that moved the most traffic over a recent window
+	TopSessions(context.Context, *TopSessionsRequest) (*TopSessionsReply, error)
+	// StreamAlerts streams the alerts raised by the alert rules as they change state
+	StreamAlerts(*AlertsRequest, grpc.ServerStreamingServer[AlertEvent]) error
+	// StreamAnomalies streams the traffic anomalies detected against the baselines of the sessions
+	StreamAnomalies(*AnomaliesRequest, grpc.ServerStreamingServer[AnomalyEvent]) error
+	// SetSliceRateLimit changes the slice policers of the running UPF without a restart
+	SetSliceRateLimit(context.Context, *SliceRateLimit) (*SliceRateLimit, error)
+	// GetSlicePolicers reports the rate limits and drop counters of the slice policers
+	GetSlicePolicers(context.Context, *SlicePolicersRequest) (*SlicePolicersReply, error)
 	mustEmbedUnimplementedRequestServer()
 }
 
@@ -154,6 +460,60 @@ func (UnimplementedRequestServer) GetRule(context.Context, *RuleRequest) (*RuleR
 func (UnimplementedRequestServer) ValidatePDR(context.Context, *ValidatePDRRequest) (*ValidatePDRReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ValidatePDR not implemented")
 }
+func (UnimplementedRequestServer) PutRule(context.Context, *PutRuleRequest) (*RuleReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutRule not implemented")
+}
+func (UnimplementedRequestServer) DeleteRule(context.Context, *RuleRequest) (*RuleReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRule not implemented")
+}
+func (UnimplementedRequestServer) GetTableUsage(context.Context, *TableUsageRequest) (*TableUsageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTableUsage not implemented")
+}
+func (UnimplementedRequestServer) ExportSessions(context.Context, *ExportSessionsRequest) (*ExportSessionsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportSessions not implemented")
+}
+func (UnimplementedRequestServer) ImportSessions(context.Context, *ImportSessionsRequest) (*ImportSessionsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportSessions not implemented")
+}
+func (UnimplementedRequestServer) GetSessionHistory(context.Context, *SessionHistoryRequest) (*AuditEventsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSessionHistory not implemented")
+}
+func (UnimplementedRequestServer) ListAuditEvents(context.Context, *ListAuditEventsRequest) (*AuditEventsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEvents not implemented")
+}
+func (UnimplementedRequestServer) CompileP4Rules(context.Context, *CompileP4Request) (*CompileP4Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompileP4Rules not implemented")
+}
+func (UnimplementedRequestServer) ListPFCPPeers(context.Context, *PFCPPeersRequest) (*PFCPPeersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPFCPPeers not implemented")
+}
+func (UnimplementedRequestServer) StreamUsageReports(*UsageReportsRequest, grpc.ServerStreamingServer[UsageReportEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamUsageReports not implemented")
+}
+func (UnimplementedRequestServer) GetFlowHistory(context.Context, *FlowHistoryRequest) (*FlowHistoryReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFlowHistory not implemented")
+}
+func (UnimplementedRequestServer) GetAggregateStats(context.Context, *AggregateStatsRequest) (*AggregateStatsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAggregateStats not implemented")
+}
+func (UnimplementedRequestServer) StreamAggregateStats(*AggregateStatsRequest, grpc.ServerStreamingServer[AggregateStatsReply]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamAggregateStats not implemented")
+}
+func (UnimplementedRequestServer) TopSessions(context.Context, *TopSessionsRequest) (*TopSessionsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TopSessions not implemented")
+}
+func (UnimplementedRequestServer) StreamAlerts(*AlertsRequest, grpc.ServerStreamingServer[AlertEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamAlerts not implemented")
+}
+func (UnimplementedRequestServer) StreamAnomalies(*AnomaliesRequest, grpc.ServerStreamingServer[AnomalyEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamAnomalies not implemented")
+}
+func (UnimplementedRequestServer) SetSliceRateLimit(context.Context, *SliceRateLimit) (*SliceRateLimit, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSliceRateLimit not implemented")
+}
+func (UnimplementedRequestServer) GetSlicePolicers(context.Context, *SlicePolicersRequest) (*SlicePolicersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSlicePolicers not implemented")
+}
 func (UnimplementedRequestServer) mustEmbedUnimplementedRequestServer() {}
 func (UnimplementedRequestServer) testEmbeddedByValue()                 {}
 
@@ -258,6 +618,302 @@ func _Request_ValidatePDR_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Request_PutRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RequestServer).PutRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Request_PutRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RequestServer).PutRule(ctx, req.(*PutRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Request_DeleteRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RequestServer).DeleteRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Request_DeleteRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RequestServer).DeleteRule(ctx, req.(*RuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Request_GetTableUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TableUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RequestServer).GetTableUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Request_GetTableUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RequestServer).GetTableUsage(ctx, req.(*TableUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Request_ExportSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RequestServer).ExportSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Request_ExportSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RequestServer).ExportSessions(ctx, req.(*ExportSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Request_ImportSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RequestServer).ImportSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Request_ImportSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RequestServer).ImportSessions(ctx, req.(*ImportSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Request_GetSessionHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RequestServer).GetSessionHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Request_GetSessionHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RequestServer).GetSessionHistory(ctx, req.(*SessionHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Request_ListAuditEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RequestServer).ListAuditEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Request_ListAuditEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RequestServer).ListAuditEvents(ctx, req.(*ListAuditEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Request_CompileP4Rules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompileP4Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RequestServer).CompileP4Rules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Request_CompileP4Rules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RequestServer).CompileP4Rules(ctx, req.(*CompileP4Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Request_ListPFCPPeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PFCPPeersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RequestServer).ListPFCPPeers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Request_ListPFCPPeers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RequestServer).ListPFCPPeers(ctx, req.(*PFCPPeersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Request_StreamUsageReports_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(UsageReportsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RequestServer).StreamUsageReports(m, &grpc.GenericServerStream[UsageReportsRequest, UsageReportEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Request_StreamUsageReportsServer = grpc.ServerStreamingServer[UsageReportEvent]
+
+func _Request_GetFlowHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FlowHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RequestServer).GetFlowHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Request_GetFlowHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RequestServer).GetFlowHistory(ctx, req.(*FlowHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Request_GetAggregateStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AggregateStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RequestServer).GetAggregateStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Request_GetAggregateStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RequestServer).GetAggregateStats(ctx, req.(*AggregateStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Request_StreamAggregateStats_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AggregateStatsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RequestServer).StreamAggregateStats(m, &grpc.GenericServerStream[AggregateStatsRequest, AggregateStatsReply]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Request_StreamAggregateStatsServer = grpc.ServerStreamingServer[AggregateStatsReply]
+
+func _Request_TopSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TopSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RequestServer).TopSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Request_TopSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RequestServer).TopSessions(ctx, req.(*TopSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Request_StreamAlerts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AlertsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RequestServer).StreamAlerts(m, &grpc.GenericServerStream[AlertsRequest, AlertEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Request_StreamAlertsServer = grpc.ServerStreamingServer[AlertEvent]
+
+func _Request_StreamAnomalies_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AnomaliesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RequestServer).StreamAnomalies(m, &grpc.GenericServerStream[AnomaliesRequest, AnomalyEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Request_StreamAnomaliesServer = grpc.ServerStreamingServer[AnomalyEvent]
+
+func _Request_SetSliceRateLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SliceRateLimit)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RequestServer).SetSliceRateLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Request_SetSliceRateLimit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RequestServer).SetSliceRateLimit(ctx, req.(*SliceRateLimit))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Request_GetSlicePolicers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SlicePolicersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RequestServer).GetSlicePolicers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Request_GetSlicePolicers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RequestServer).GetSlicePolicers(ctx, req.(*SlicePolicersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Request_ServiceDesc is the grpc.ServiceDesc for Request service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -281,6 +937,62 @@ var Request_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ValidatePDR",
 			Handler:    _Request_ValidatePDR_Handler,
 		},
+		{
+			MethodName: "PutRule",
+			Handler:    _Request_PutRule_Handler,
+		},
+		{
+			MethodName: "DeleteRule",
+			Handler:    _Request_DeleteRule_Handler,
+		},
+		{
+			MethodName: "GetTableUsage",
+			Handler:    _Request_GetTableUsage_Handler,
+		},
+		{
+			MethodName: "ExportSessions",
+			Handler:    _Request_ExportSessions_Handler,
+		},
+		{
+			MethodName: "ImportSessions",
+			Handler:    _Request_ImportSessions_Handler,
+		},
+		{
+			MethodName: "GetSessionHistory",
+			Handler:    _Request_GetSessionHistory_Handler,
+		},
+		{
+			MethodName: "ListAuditEvents",
+			Handler:    _Request_ListAuditEvents_Handler,
+		},
+		{
+			MethodName: "CompileP4Rules",
+			Handler:    _Request_CompileP4Rules_Handler,
+		},
+		{
+			MethodName: "ListPFCPPeers",
+			Handler:    _Request_ListPFCPPeers_Handler,
+		},
+		{
+			MethodName: "GetFlowHistory",
+			Handler:    _Request_GetFlowHistory_Handler,
+		},
+		{
+			MethodName: "GetAggregateStats",
+			Handler:    _Request_GetAggregateStats_Handler,
+		},
+		{
+			MethodName: "TopSessions",
+			Handler:    _Request_TopSessions_Handler,
+		},
+		{
+			MethodName: "SetSliceRateLimit",
+			Handler:    _Request_SetSliceRateLimit_Handler,
+		},
+		{
+			MethodName: "GetSlicePolicers",
+			Handler:    _Request_GetSlicePolicers_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -288,6 +1000,26 @@ var Request_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Request_PutRequest_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StreamUsageReports",
+			Handler:       _Request_StreamUsageReports_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamAggregateStats",
+			Handler:       _Request_StreamAggregateStats_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamAlerts",
+			Handler:       _Request_StreamAlerts_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamAnomalies",
+			Handler:       _Request_StreamAnomalies_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "request.proto",
 }
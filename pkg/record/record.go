@@ -0,0 +1,348 @@
+/*
+Package record writes time-series points to files for offline analysis, in
+InfluxDB line protocol or CSV. Files are rotated once they reach a size or an
+age, and rotated files can be compressed with gzip. Every file holds a single
+measurement, so CSV files have one fixed set of columns.
+*/
+package record
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of record files
+const (
+	FormatInflux = "influx" // InfluxDB line protocol, *.lp
+	FormatCSV    = "csv"    // Comma separated values with a header row, *.csv
+)
+
+// Tag is an indexed string attribute of a point. Tags with empty values are left out.
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// Field is a value of a point, either an unsigned integer or a float
+type Field struct {
+	Key    string
+	Uint   uint64
+	Float  float64
+	IsUint bool // Uint holds the value, else Float
+}
+
+// UintField returns an unsigned integer field, such as a counter
+func UintField(key string, v uint64) Field { return Field{Key: key, Uint: v, IsUint: true} }
+
+// FloatField returns a float field. Line protocol has no NaN or infinity:
+// such values are left out of lines and written as empty CSV cells.
+func FloatField(key string, v float64) Field { return Field{Key: key, Float: v} }
+
+// finite reports whether the field has a value both formats can carry
+func (f Field) finite() bool {
+	return f.IsUint || !math.IsNaN(f.Float) && !math.IsInf(f.Float, 0)
+}
+
+// Point is one sample of a measurement. All points written to a Writer must
+// carry the same tag and field keys in the same order.
+type Point struct {
+	Time   time.Time
+	Tags   []Tag
+	Fields []Field
+}
+
+// Options configure a Writer
+type Options struct {
+	Dir         string        // Directory the files are created in
+	Measurement string        // Name of the measurement, also the prefix of the files
+	Format      string        // FormatInflux or FormatCSV; empty means FormatInflux
+	MaxSize     int64         // Rotate once a file holds this many bytes, 0 for no limit
+	MaxAge      time.Duration // Rotate once a file is this old, 0 for no limit
+	Gzip        bool          // Compress rotated files
+}
+
+var (
+	// ErrFormat reports an unknown file format
+	ErrFormat = errors.New("record: format must be influx or csv")
+	// ErrNoFields reports a point none of whose fields line protocol can carry
+	ErrNoFields = errors.New("record: point has no finite field")
+)
+
+// Writer writes the points of one measurement to a series of files
+type Writer struct {
+	opts    Options
+	file    *os.File
+	buf     *bufio.Writer
+	csv     *csv.Writer
+	path    string    // Path of the current file
+	size    int64     // Bytes written to the current file
+	opened  time.Time // When the current file was created
+	columns int       // Columns of the CSV header, 0 before it is written
+}
+
+// counter counts the bytes written through it
+type counter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c counter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	*c.n += int64(n)
+	return n, err
+}
+
+// Create makes the directory if needed and returns a Writer, which opens its
+// first file on the first point
+func Create(opts Options) (*Writer, error) {
+	if opts.Format == "" {
+		opts.Format = FormatInflux
+	}
+	if opts.Format != FormatInflux && opts.Format != FormatCSV {
+		return nil, ErrFormat
+	}
+	if opts.Measurement == "" {
+		return nil, errors.New("record: measurement is empty")
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("record: %w", err)
+	}
+	return &Writer{opts: opts}, nil
+}
+
+// ext returns the extension of the files of the format
+func (w *Writer) ext() string {
+	if w.opts.Format == FormatCSV {
+		return ".csv"
+	}
+	return ".lp"
+}
+
+// open starts a new file named after the measurement and the time of its first point
+func (w *Writer) open(at time.Time) error {
+	base := fmt.Sprintf("%s-%s", w.opts.Measurement, at.UTC().Format("20060102T150405.000Z"))
+	path := filepath.Join(w.opts.Dir, base+w.ext())
+	for i := 1; ; i++ {
+		if !exists(path) && !exists(path+".gz") {
+			break
+		}
+		path = filepath.Join(w.opts.Dir, fmt.Sprintf("%s-%d%s", base, i, w.ext()))
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("record: %w", err)
+	}
+	w.file, w.path, w.size, w.opened, w.columns = f, path, 0, time.Now(), 0
+	w.buf = bufio.NewWriter(counter{w: f, n: &w.size})
+	if w.opts.Format == FormatCSV {
+		w.csv = csv.NewWriter(w.buf)
+	}
+	return nil
+}
+
+// exists reports whether a file exists at path
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
+
+// due reports whether the current file has reached its size or age
+func (w *Writer) due() bool {
+	return (w.opts.MaxSize > 0 && w.size+int64(w.buf.Buffered()) >= w.opts.MaxSize) ||
+		(w.opts.MaxAge > 0 && time.Since(w.opened) >= w.opts.MaxAge)
+}
+
+// Write appends a point, rotating the file first when it is due
+func (w *Writer) Write(p Point) error {
+	if w.file != nil && w.due() {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	if w.file == nil {
+		if err := w.open(p.Time); err != nil {
+			return err
+		}
+	}
+	if w.opts.Format == FormatCSV {
+		return w.writeCSV(p)
+	}
+	line, err := lineProtocol(w.opts.Measurement, p)
+	if err != nil {
+		return err
+	}
+	_, err = w.buf.WriteString(line)
+	return err
+}
+
+// writeCSV appends a row, preceded by the header in a new file
+func (w *Writer) writeCSV(p Point) error {
+	if w.columns == 0 {
+		header := []string{"time"}
+		for _, t := range p.Tags {
+			header = append(header, t.Key)
+		}
+		for _, f := range p.Fields {
+			header = append(header, f.Key)
+		}
+		if err := w.csv.Write(header); err != nil {
+			return err
+		}
+		w.columns = len(header)
+	}
+	if 1+len(p.Tags)+len(p.Fields) != w.columns {
+		return fmt.Errorf("record: point of %s has %d columns, its file %d", w.opts.Measurement, 1+len(p.Tags)+len(p.Fields), w.columns)
+	}
+	row := []string{p.Time.UTC().Format(time.RFC3339Nano)}
+	for _, t := range p.Tags {
+		row = append(row, t.Value)
+	}
+	for _, f := range p.Fields {
+		row = append(row, formatField(f))
+	}
+	if err := w.csv.Write(row); err != nil {
+		return err
+	}
+	// Hand the row to buf, which the size of the file is measured by
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// Flush writes buffered points to the current file
+func (w *Writer) Flush() error {
+	if w.file == nil {
+		return nil
+	}
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return w.buf.Flush()
+}
+
+// rotate closes the current file and compresses it if asked to
+func (w *Writer) rotate() error {
+	path := w.path
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+	if w.opts.Gzip {
+		return compress(path)
+	}
+	return nil
+}
+
+// closeFile flushes and closes the current file
+func (w *Writer) closeFile() error {
+	err := w.Flush()
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	w.file, w.buf, w.csv = nil, nil, nil
+	return err
+}
+
+// Close flushes and closes the current file, compressing it like a rotated one
+func (w *Writer) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	return w.rotate()
+}
+
+// compress replaces a file with its gzip compressed copy
+func compress(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("record: %w", err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("record: %w", err)
+	}
+	zw := gzip.NewWriter(out)
+	zw.Name = filepath.Base(path)
+	_, err = io.Copy(zw, in)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path + ".gz")
+		return fmt.Errorf("record: compressing %s: %w", path, err)
+	}
+	return os.Remove(path)
+}
+
+// Escaping of line protocol names and tag values
+var (
+	measurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `, "\n", `\n`)
+	keyEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\n`)
+)
+
+// lineProtocol renders a point as one line of InfluxDB line protocol with a
+// nanosecond timestamp. Unsigned fields take the u suffix.
+func lineProtocol(measurement string, p Point) (string, error) {
+	var b strings.Builder
+	b.WriteString(measurementEscaper.Replace(measurement))
+	for _, t := range p.Tags {
+		if t.Value == "" {
+			continue
+		}
+		b.WriteByte(',')
+		b.WriteString(keyEscaper.Replace(t.Key))
+		b.WriteByte('=')
+		b.WriteString(keyEscaper.Replace(t.Value))
+	}
+	written := 0
+	for _, f := range p.Fields {
+		if !f.finite() {
+			continue
+		}
+		if written == 0 {
+			b.WriteByte(' ')
+		} else {
+			b.WriteByte(',')
+		}
+		written++
+		b.WriteString(keyEscaper.Replace(f.Key))
+		b.WriteByte('=')
+		b.WriteString(formatField(f))
+		if f.IsUint {
+			b.WriteByte('u')
+		}
+	}
+	if written == 0 {
+		return "", ErrNoFields
+	}
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatInt(p.Time.UnixNano(), 10))
+	b.WriteByte('\n')
+	return b.String(), nil
+}
+
+// formatField renders the value of a field, empty for NaN and infinities
+func formatField(f Field) string {
+	switch {
+	case f.IsUint:
+		return strconv.FormatUint(f.Uint, 10)
+	case !f.finite():
+		return ""
+	}
+	return strconv.FormatFloat(f.Float, 'g', -1, 64)
+}
@@ -0,0 +1,234 @@
+package record
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testTime is the time of the test points, 2023-11-14T22:13:20Z
+var testTime = time.Unix(1_700_000_000, 0)
+
+func TestLineProtocol(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		measurement string
+		point       Point
+		want        string
+	}{
+		{
+			name:        "escaped",
+			measurement: "upf session,v2",
+			point: Point{
+				Time: testTime,
+				Tags: []Tag{{Key: "dnn name", Value: "a=b,c d"}, {Key: "imsi", Value: ""}, {Key: "line", Value: "x\ny"}},
+				Fields: []Field{
+					UintField("rx bytes", 10),
+					FloatField("a=b,c", 1.5),
+				},
+			},
+			want: `upf\ session\,v2,dnn\ name=a\=b\,c\ d,line=x\ny rx\ bytes=10u,a\=b\,c=1.5 1700000000000000000` + "\n",
+		},
+		{
+			name:        "counters past int64",
+			measurement: "m",
+			point:       Point{Time: testTime, Fields: []Field{UintField("c", math.MaxUint64)}},
+			want:        "m c=18446744073709551615u 1700000000000000000\n",
+		},
+		{
+			name:        "not finite left out",
+			measurement: "m",
+			point: Point{Time: testTime, Fields: []Field{
+				FloatField("nan", math.NaN()), FloatField("inf", math.Inf(1)), FloatField("rate", 2), FloatField("ninf", math.Inf(-1)),
+			}},
+			want: "m rate=2 1700000000000000000\n",
+		},
+	} {
+		got, err := lineProtocol(tc.measurement, tc.point)
+		if err != nil || got != tc.want {
+			t.Errorf("%s: got %q (%v), want %q", tc.name, got, err, tc.want)
+		}
+	}
+
+	if _, err := lineProtocol("m", Point{Time: testTime, Fields: []Field{FloatField("nan", math.NaN())}}); !errors.Is(err, ErrNoFields) {
+		t.Errorf("point without a finite field: %v, want ErrNoFields", err)
+	}
+}
+
+// readRecords returns the contents of the files in dir by name, decompressing
+// gzip files
+func readRecords(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, e := range entries {
+		f, err := os.Open(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var r io.Reader = f
+		if strings.HasSuffix(e.Name(), ".gz") {
+			zr, err := gzip.NewReader(f)
+			if err != nil {
+				t.Fatalf("%s: %v", e.Name(), err)
+			}
+			if zr.Name != strings.TrimSuffix(e.Name(), ".gz") {
+				t.Errorf("%s holds %s", e.Name(), zr.Name)
+			}
+			r = zr
+		}
+		data, err := io.ReadAll(r)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", e.Name(), err)
+		}
+		files[e.Name()] = string(data)
+	}
+	return files
+}
+
+// counterPoint returns a point with a single counter, whose lines are all of
+// the same length for counters of one digit
+func counterPoint(at time.Time, v uint64) Point {
+	return Point{Time: at, Fields: []Field{UintField("c", v)}}
+}
+
+// TestWriterRotateSize checks that files are rotated before the point that
+// would take them past MaxSize, and compressed
+func TestWriterRotateSize(t *testing.T) {
+	line, _ := lineProtocol("m", counterPoint(testTime, 0))
+	dir := t.TempDir()
+	// Room for 3 lines: the 4th starts a new file
+	w, err := Create(Options{Dir: dir, Measurement: "m", MaxSize: int64(2*len(line) + 1), Gzip: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// All points at the same time: the later files are numbered
+	for v := range uint64(7) {
+		if err := w.Write(counterPoint(testTime, v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := func(values ...int) string {
+		var b strings.Builder
+		for _, v := range values {
+			l, _ := lineProtocol("m", counterPoint(testTime, uint64(v)))
+			b.WriteString(l)
+		}
+		return b.String()
+	}
+	want := map[string]string{
+		"m-20231114T221320.000Z.lp.gz":   lines(0, 1, 2),
+		"m-20231114T221320.000Z-1.lp.gz": lines(3, 4, 5),
+		"m-20231114T221320.000Z-2.lp.gz": lines(6),
+	}
+	got := readRecords(t, dir)
+	if len(got) != len(want) {
+		names := make([]string, 0, len(got))
+		for name := range got {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		t.Fatalf("files %v, want %d", names, len(want))
+	}
+	for name, data := range want {
+		if got[name] != data {
+			t.Errorf("%s holds %q, want %q", name, got[name], data)
+		}
+	}
+}
+
+// TestWriterRotateAge checks that a file is rotated once it is MaxAge old,
+// and left uncompressed without Gzip
+func TestWriterRotateAge(t *testing.T) {
+	dir := t.TempDir()
+	w, err := Create(Options{Dir: dir, Measurement: "m", MaxAge: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, at := range []time.Time{testTime, testTime.Add(time.Second), testTime.Add(2 * time.Second)} {
+		if i == 2 {
+			time.Sleep(150 * time.Millisecond)
+		}
+		if err := w.Write(counterPoint(at, uint64(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got := readRecords(t, dir)
+	if len(got) != 2 || strings.Count(got["m-20231114T221320.000Z.lp"], "\n") != 2 || strings.Count(got["m-20231114T221322.000Z.lp"], "\n") != 1 {
+		t.Errorf("files %v, want 2 points in the first and 1 in the second", got)
+	}
+}
+
+// TestWriterCSV checks the fixed header of a CSV file and that a point of
+// other columns is refused
+func TestWriterCSV(t *testing.T) {
+	dir := t.TempDir()
+	w, err := Create(Options{Dir: dir, Measurement: "m", Format: FormatCSV})
+	if err != nil {
+		t.Fatal(err)
+	}
+	point := func(rate float64, fields ...Field) Point {
+		return Point{
+			Time:   testTime,
+			Tags:   []Tag{{Key: "fseid", Value: "0x1"}, {Key: "imsi", Value: "a,b"}},
+			Fields: append([]Field{UintField("rx_bytes", math.MaxUint64), FloatField("rx_speed", rate)}, fields...),
+		}
+	}
+	for _, p := range []Point{point(1.5), point(math.NaN())} {
+		if err := w.Write(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Write(point(1, UintField("tx_bytes", 1))); err == nil {
+		t.Error("wrote a point of 5 columns to a file of 4")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "time,fseid,imsi,rx_bytes,rx_speed\n" +
+		"2023-11-14T22:13:20Z,0x1,\"a,b\",18446744073709551615,1.5\n" +
+		"2023-11-14T22:13:20Z,0x1,\"a,b\",18446744073709551615,\n"
+	if got := readRecords(t, dir)["m-20231114T221320.000Z.csv"]; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	if _, err := Create(Options{Dir: t.TempDir(), Measurement: "m", Format: "json"}); !errors.Is(err, ErrFormat) {
+		t.Errorf("json format: %v", err)
+	}
+	if _, err := Create(Options{Dir: t.TempDir()}); err == nil {
+		t.Error("created a writer without a measurement")
+	}
+	// A writer that never got a point leaves no file
+	dir := filepath.Join(t.TempDir(), "records")
+	w, err := Create(Options{Dir: dir, Measurement: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if files := readRecords(t, dir); len(files) != 0 {
+		t.Errorf("files %v", files)
+	}
+}